@@ -5,9 +5,11 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strings"
 
 	"sancaksoft/internal/api/handler"
 	"sancaksoft/internal/api/middleware"
+	"sancaksoft/internal/einvoice"
 	"sancaksoft/internal/repository"
 	"sancaksoft/internal/service"
 
@@ -28,6 +30,11 @@ func main() {
 	if port == "" {
 		port = "8080"
 	}
+	// Proxies whose X-Forwarded-For is trusted for the client IP in audit logs (comma separated)
+	trustedProxies := []string{"127.0.0.1", "::1"}
+	if v := os.Getenv("TRUSTED_PROXIES"); v != "" {
+		trustedProxies = strings.Split(v, ",")
+	}
 
 	// 2. Database Connection
 	ctx := context.Background()
@@ -51,21 +58,101 @@ func main() {
 
 	invoiceHandler := handler.NewInvoiceHandler(invoiceService, invoiceListService)
 
+	salesOrderRepo := repository.NewSalesOrderRepository(dbPool)
+	salesOrderService := service.NewSalesOrderService(dbPool, salesOrderRepo, invoiceService)
+	salesOrderHandler := handler.NewSalesOrderHandler(salesOrderService)
+
+	quotationRepo := repository.NewQuotationRepository(dbPool)
+	quotationService := service.NewQuotationService(dbPool, quotationRepo, salesOrderService, invoiceService)
+	quotationHandler := handler.NewQuotationHandler(quotationService)
+
+	deliveryNoteRepo := repository.NewDeliveryNoteRepository(dbPool)
+	deliveryNoteService := service.NewDeliveryNoteService(dbPool, deliveryNoteRepo, invoiceService)
+	deliveryNoteHandler := handler.NewDeliveryNoteHandler(deliveryNoteService)
+
+	supplierRepo := repository.NewSupplierRepository(dbPool)
+	supplierService := service.NewSupplierService(dbPool, supplierRepo)
+	supplierHandler := handler.NewSupplierHandler(supplierService)
+
+	purchaseOrderRepo := repository.NewPurchaseOrderRepository(dbPool)
+	purchaseOrderService := service.NewPurchaseOrderService(dbPool, purchaseOrderRepo)
+	purchaseOrderHandler := handler.NewPurchaseOrderHandler(purchaseOrderService)
+
+	replenishmentRepo := repository.NewReplenishmentRepository(dbPool)
+	replenishmentService := service.NewReplenishmentService(dbPool, replenishmentRepo, purchaseOrderService)
+	replenishmentHandler := handler.NewReplenishmentHandler(replenishmentService)
+
+	costingRepo := repository.NewCostingRepository(dbPool)
+	costingService := service.NewCostingService(dbPool, costingRepo)
+	costingHandler := handler.NewCostingHandler(costingService)
+
+	reportRepo := repository.NewReportRepository(dbPool)
+	reportService := service.NewReportService(reportRepo)
+	reportHandler := handler.NewReportHandler(reportService)
+
 	productRepo := repository.NewProductRepository(dbPool)
-	productService := service.NewProductService(productRepo)
+	productService := service.NewProductService(dbPool, productRepo)
 	productHandler := handler.NewProductHandler(productService)
 
+	tenantRepo := repository.NewTenantRepository(dbPool)
+	tenantService := service.NewTenantService(dbPool, tenantRepo)
+	tenantHandler := handler.NewTenantHandler(tenantService)
+
+	// The mock provider stands in until an integrator client is configured
+	eInvoiceRepo := repository.NewEInvoiceRepository(dbPool)
+	eInvoiceService := service.NewEInvoiceService(dbPool, eInvoiceRepo, einvoice.NewMockProvider())
+	eInvoiceHandler := handler.NewEInvoiceHandler(eInvoiceService)
+
+	importRepo := repository.NewImportRepository(dbPool)
+	importService := service.NewImportService(dbPool, importRepo)
+	importHandler := handler.NewImportHandler(importService)
+
 	customerRepo := repository.NewCustomerRepository(dbPool)
-	customerService := service.NewCustomerService(customerRepo)
+	customerService := service.NewCustomerService(dbPool, customerRepo)
 	customerHandler := handler.NewCustomerHandler(customerService)
 
+	exportRepo := repository.NewExportRepository(dbPool)
+	exportService := service.NewExportService(exportRepo, customerRepo)
+	exportHandler := handler.NewExportHandler(exportService)
+
 	warehouseRepo := repository.NewWarehouseRepository(dbPool)
-	warehouseService := service.NewWarehouseService(warehouseRepo)
+	warehouseService := service.NewWarehouseService(dbPool, warehouseRepo)
 	warehouseHandler := handler.NewWarehouseHandler(warehouseService)
 
 	stockRepo := repository.NewStockRepository(dbPool)
-	stockService := service.NewStockService(stockRepo)
+	stockService := service.NewStockService(dbPool, stockRepo)
 	stockHandler := handler.NewStockHandler(stockService)
+	stockBalanceService := service.NewStockBalanceService(dbPool, stockRepo)
+	if err := stockBalanceService.EnsureBalanceTables(ctx); err != nil {
+		log.Fatalf("Unable to prepare stock balance tables: %v\n", err)
+	}
+	stockBalanceHandler := handler.NewStockBalanceHandler(stockBalanceService)
+
+	lotRepo := repository.NewLotRepository(dbPool)
+	lotService := service.NewLotService(lotRepo)
+	lotHandler := handler.NewLotHandler(lotService)
+
+	serialRepo := repository.NewSerialRepository(dbPool)
+	serialService := service.NewSerialService(serialRepo)
+	serialHandler := handler.NewSerialHandler(serialService)
+
+	locationRepo := repository.NewLocationRepository(dbPool)
+	locationService := service.NewLocationService(dbPool, locationRepo, purchaseOrderService, salesOrderService)
+	locationHandler := handler.NewLocationHandler(locationService)
+
+	categoryRepo := repository.NewCategoryRepository(dbPool)
+	categoryService := service.NewCategoryService(dbPool, categoryRepo)
+	categoryHandler := handler.NewCategoryHandler(categoryService)
+
+	variantRepo := repository.NewVariantRepository(dbPool)
+	variantService := service.NewVariantService(dbPool, variantRepo)
+	variantHandler := handler.NewVariantHandler(variantService)
+	kitRepo := repository.NewKitRepository(dbPool)
+	kitService := service.NewKitService(dbPool, kitRepo)
+	kitHandler := handler.NewKitHandler(kitService)
+	assemblyRepo := repository.NewAssemblyRepository(dbPool)
+	assemblyService := service.NewAssemblyService(dbPool, assemblyRepo, invoiceService)
+	assemblyHandler := handler.NewAssemblyHandler(assemblyService)
 
 	returnRepo := repository.NewReturnRepository(dbPool)
 	returnService := service.NewReturnService(dbPool, returnRepo)
@@ -75,8 +162,17 @@ func main() {
 	dashboardService := service.NewDashboardService(dashboardRepo)
 	dashboardHandler := handler.NewDashboardHandler(dashboardService)
 
+	auditRepo := repository.NewAuditRepository(dbPool)
+	auditService := service.NewAuditService(auditRepo)
+	auditHandler := handler.NewAuditHandler(auditService)
+
 	// 4. Fiber App Setup
-	app := fiber.New()
+	app := fiber.New(fiber.Config{
+		ProxyHeader:             fiber.HeaderXForwardedFor,
+		EnableTrustedProxyCheck: true,
+		TrustedProxies:          trustedProxies,
+		EnableIPValidation:      true,
+	})
 
 	// Middlewares
 	// CORS - Allow Frontend to access Backend (local + production)
@@ -93,7 +189,8 @@ func main() {
 			c.Set("Access-Control-Allow-Origin", "http://localhost:3000")
 		}
 		c.Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Set("Access-Control-Allow-Headers", "Content-Type, X-Tenant-ID, X-User-ID")
+		c.Set("Access-Control-Allow-Headers", "Content-Type, X-Tenant-ID, X-User-ID, X-Request-ID")
+		c.Set("Access-Control-Expose-Headers", "X-Request-ID")
 		if c.Method() == "OPTIONS" {
 			return c.SendStatus(204)
 		}
@@ -109,26 +206,137 @@ func main() {
 	v1Direct := app.Group("/v1") // Nginx proxy /api'yi sildiğinde /v1/... gelir
 
 	// Tenant & Auth Middleware applied to sensitive routes
-	protected := v1.Group("/", middleware.TenantMiddleware(), middleware.AuthMiddleware())
-	protectedDirect := v1Direct.Group("/", middleware.TenantMiddleware(), middleware.AuthMiddleware())
+	protected := v1.Group("/", middleware.TenantMiddleware(), middleware.AuthMiddleware(), middleware.AuditMiddleware())
+	protectedDirect := v1Direct.Group("/", middleware.TenantMiddleware(), middleware.AuthMiddleware(), middleware.AuditMiddleware())
 
 	// Invoice Routes
 	protected.Post("/invoices", invoiceHandler.CreateInvoice)
 	protected.Get("/invoices", invoiceHandler.ListInvoices)
 	protected.Get("/invoices/:id", invoiceHandler.GetInvoiceDetail)
+	protected.Get("/invoices/:id/pdf", invoiceHandler.GetInvoicePDF)
+	protected.Post("/invoices/:id/e-invoice", eInvoiceHandler.GenerateEInvoice)
+	protected.Get("/invoices/:id/e-invoice", eInvoiceHandler.GetEInvoice)
+	protected.Get("/invoices/:id/e-invoice/xml", eInvoiceHandler.GetEInvoiceXML)
+	protected.Post("/invoices/:id/e-invoice/send", eInvoiceHandler.SendEInvoice)
+	protected.Get("/invoices/:id/pick-list", locationHandler.GetInvoicePickList)
+
+	// Sales Order Routes
+	protected.Post("/sales-orders", salesOrderHandler.CreateSalesOrder)
+	protected.Get("/sales-orders", salesOrderHandler.ListSalesOrders)
+	protected.Get("/sales-orders/:id", salesOrderHandler.GetSalesOrder)
+	protected.Post("/sales-orders/:id/confirm", salesOrderHandler.ConfirmSalesOrder)
+	protected.Post("/sales-orders/:id/invoice", salesOrderHandler.InvoiceSalesOrder)
+	protected.Post("/sales-orders/:id/close", salesOrderHandler.CloseSalesOrder)
+	protected.Post("/sales-orders/:id/cancel", salesOrderHandler.CancelSalesOrder)
+	protected.Get("/sales-orders/:id/pick-list", locationHandler.GetSalesOrderPickList)
+
+	protected.Post("/quotations", quotationHandler.CreateQuotation)
+	protected.Get("/quotations", quotationHandler.ListQuotations)
+	protected.Get("/quotations/:id", quotationHandler.GetQuotation)
+	protected.Post("/quotations/:id/revise", quotationHandler.ReviseQuotation)
+	protected.Post("/quotations/:id/accept", quotationHandler.AcceptQuotation)
+	protected.Post("/quotations/:id/reject", quotationHandler.RejectQuotation)
+	protected.Post("/quotations/:id/convert-to-order", quotationHandler.ConvertToSalesOrder)
+	protected.Post("/quotations/:id/convert-to-invoice", quotationHandler.ConvertToInvoice)
+
+	protected.Post("/delivery-notes", deliveryNoteHandler.CreateDeliveryNote)
+	protected.Get("/delivery-notes", deliveryNoteHandler.ListDeliveryNotes)
+	protected.Get("/delivery-notes/uninvoiced", deliveryNoteHandler.GetUninvoicedReport)
+	protected.Post("/delivery-notes/invoice", deliveryNoteHandler.InvoiceDeliveryNotes)
+	protected.Get("/delivery-notes/:id", deliveryNoteHandler.GetDeliveryNote)
+	protected.Post("/delivery-notes/:id/cancel", deliveryNoteHandler.CancelDeliveryNote)
+
+	protected.Post("/suppliers", supplierHandler.CreateSupplier)
+	protected.Get("/suppliers", supplierHandler.ListSuppliers)
+	protected.Get("/suppliers/:id", supplierHandler.GetSupplier)
+
+	protected.Post("/purchase-orders", purchaseOrderHandler.CreatePurchaseOrder)
+	protected.Get("/purchase-orders", purchaseOrderHandler.ListPurchaseOrders)
+	protected.Get("/purchase-orders/open-lines", purchaseOrderHandler.ListOpenPurchaseLines)
+	protected.Get("/purchase-orders/discrepancies", purchaseOrderHandler.ListPurchaseDiscrepancies)
+	protected.Get("/purchase-orders/:id", purchaseOrderHandler.GetPurchaseOrder)
+	protected.Post("/purchase-orders/:id/confirm", purchaseOrderHandler.ConfirmPurchaseOrder)
+	protected.Post("/purchase-orders/:id/receipts", purchaseOrderHandler.ReceiveGoods)
+	protected.Post("/purchase-orders/:id/invoices", purchaseOrderHandler.RecordPurchaseInvoice)
+	protected.Get("/purchase-orders/:id/match", purchaseOrderHandler.GetPurchaseOrderMatch)
+	protected.Get("/purchase-orders/:id/putaway", locationHandler.SuggestPutaway)
+	protected.Post("/purchase-orders/:id/close", purchaseOrderHandler.ClosePurchaseOrder)
+	protected.Post("/purchase-orders/:id/cancel", purchaseOrderHandler.CancelPurchaseOrder)
+
+	protected.Get("/stock-levels", replenishmentHandler.ListStockLevels)
+	protected.Put("/stock-levels", replenishmentHandler.SetStockLevel)
+	protected.Delete("/stock-levels/:id", replenishmentHandler.DeleteStockLevel)
+	protected.Get("/stock/low-stock", replenishmentHandler.GetLowStockReport)
+	protected.Get("/replenishment/suggestions", replenishmentHandler.GetReplenishmentSuggestions)
+	protected.Post("/replenishment/purchase-orders", replenishmentHandler.CreateReplenishmentOrders)
+
+	protected.Get("/company-profile", tenantHandler.GetCompanyProfile)
+	protected.Put("/company-profile", tenantHandler.UpdateCompanyProfile)
+	protected.Get("/invoice-template", tenantHandler.GetInvoiceTemplate)
+	protected.Put("/invoice-template", tenantHandler.SaveInvoiceTemplate)
+
+	protected.Get("/costing/method", costingHandler.GetCostingMethod)
+	protected.Put("/costing/method", costingHandler.SetCostingMethod)
+	protected.Post("/costing/recalculate", costingHandler.Recalculate)
+	protected.Get("/costing/cogs", costingHandler.GetCostOfGoods)
+	protected.Get("/stock/valuation", costingHandler.GetStockValuation)
+	protected.Get("/invoices/:id/profit", costingHandler.GetInvoiceProfit)
+	protected.Get("/reports/gross-profit", reportHandler.GetGrossProfit)
+	protected.Get("/reports/receivables-aging", reportHandler.GetReceivablesAging)
 
 	// Product Routes
 	protected.Post("/products", productHandler.CreateProduct)
 	protected.Get("/products", productHandler.ListProducts)
+	protected.Put("/products/:id/standard-cost", productHandler.SetStandardCost)
+	protected.Put("/products/:id/lot-tracking", productHandler.SetLotTracking)
+	protected.Put("/products/:id/serial-tracking", productHandler.SetSerialTracking)
+	protected.Get("/products/:id/serials", serialHandler.ListProductSerials)
+	protected.Get("/products/:id/movements", stockHandler.GetProductMovements)
+	protected.Put("/products/:id/classification", categoryHandler.SetProductClassification)
+	protected.Put("/products/:id/attributes", categoryHandler.SetProductAttributes)
+	protected.Get("/products/:id/variant-dimensions", variantHandler.ListDimensions)
+	protected.Put("/products/:id/variant-dimensions", variantHandler.SetDimensions)
+	protected.Get("/products/:id/variants", variantHandler.GetVariantSummary)
+	protected.Post("/products/:id/variants", variantHandler.GenerateVariants)
+	protected.Put("/products/:id/variant-price", variantHandler.SetVariantPrice)
+	protected.Get("/products/:id/kit", kitHandler.GetComponents)
+	protected.Put("/products/:id/kit", kitHandler.SetComponents)
+	protected.Get("/products/:id/kit-availability", kitHandler.GetAvailability)
+	protected.Get("/products/:id/bom", assemblyHandler.GetBOM)
+	protected.Put("/products/:id/bom", assemblyHandler.SetBOM)
+	protected.Delete("/products/:id/bom", assemblyHandler.DeleteBOM)
+	protected.Post("/assembly-orders", assemblyHandler.CreateAssemblyOrder)
+	protected.Get("/assembly-orders", assemblyHandler.ListAssemblyOrders)
+	protected.Get("/assembly-orders/:id", assemblyHandler.GetAssemblyOrder)
+	protected.Post("/assembly-orders/:id/complete", assemblyHandler.CompleteAssemblyOrder)
+	protected.Post("/assembly-orders/:id/cancel", assemblyHandler.CancelAssemblyOrder)
+	protected.Get("/categories", categoryHandler.ListCategories)
+	protected.Post("/categories", categoryHandler.CreateCategory)
+	protected.Put("/categories/:id", categoryHandler.UpdateCategory)
+	protected.Delete("/categories/:id", categoryHandler.DeleteCategory)
+	protected.Get("/categories/:id/attributes", categoryHandler.ListCategoryAttributes)
+	protected.Post("/categories/:id/attributes", categoryHandler.CreateAttribute)
+	protected.Get("/brands", categoryHandler.ListBrands)
+	protected.Post("/brands", categoryHandler.CreateBrand)
 
 	// Customer Routes
 	protected.Post("/customers", customerHandler.CreateCustomer)
 	protected.Get("/customers", customerHandler.ListCustomers)
 	protected.Get("/customers/:customerId/ledger", customerHandler.GetCustomerLedger)
+	protected.Get("/customers/:customerId/credit", customerHandler.GetCustomerCredit)
+	protected.Put("/customers/:customerId/credit", customerHandler.SetCustomerCredit)
+	protected.Post("/customers/:customerId/payments", customerHandler.CreateCustomerPayment)
+	protected.Get("/customers/:customerId/payments", customerHandler.ListCustomerPayments)
+	protected.Get("/customers/:customerId/statement", customerHandler.GetCustomerStatement)
 
 	// Warehouse Routes
 	protected.Post("/warehouses", warehouseHandler.CreateWarehouse)
 	protected.Get("/warehouses", warehouseHandler.ListWarehouses)
+	protected.Get("/warehouses/:id/locations", locationHandler.ListLocations)
+	protected.Post("/warehouses/:id/locations", locationHandler.CreateLocation)
+	protected.Get("/warehouses/:id/location-stock", locationHandler.ListLocationBalances)
+	protected.Post("/warehouses/:id/location-transfers", locationHandler.TransferStock)
+	protected.Put("/locations/:id", locationHandler.UpdateLocation)
 
 	// Stock Routes
 	protected.Get("/stock-movements", stockHandler.ListStockMovements)
@@ -136,35 +344,192 @@ func main() {
 	protected.Get("/stock-balance", stockHandler.GetStockBalance)
 	protected.Get("/stock-balance-total", stockHandler.GetTotalStockBalance)
 	protected.Get("/stock-balance-by-warehouse", stockHandler.GetStockBalanceByWarehouse)
+	protected.Get("/stock-balances/check", stockBalanceHandler.CheckBalances)
+	protected.Post("/stock-balances/repair", stockBalanceHandler.RepairBalances)
+	protected.Get("/lots", lotHandler.ListLotBalances)
+	protected.Get("/lots/expiry", lotHandler.GetExpiryReport)
+	protected.Get("/lots/:id/trace", lotHandler.TraceLot)
+	protected.Get("/serials/:serial", serialHandler.GetSerialHistory)
 
 	// Return Routes
 	protected.Post("/returns", returnHandler.CreateCustomerReturn)
 	protected.Get("/returns", returnHandler.ListCustomerReturns)
 	protected.Get("/returns/customer-purchases/:customerId", returnHandler.ListCustomerPurchases)
+	protected.Get("/returns/reasons-report", returnHandler.GetReturnReasonReport)
+	protected.Post("/returns/:id/receive", returnHandler.ReceiveCustomerReturn)
+	protected.Post("/returns/:id/inspect", returnHandler.InspectCustomerReturn)
+	protected.Post("/returns/:id/close", returnHandler.CloseCustomerReturn)
+	protected.Post("/returns/:id/cancel", returnHandler.CancelCustomerReturn)
+	protected.Post("/returns/:id/reject", returnHandler.RejectCustomerReturn)
+	protected.Get("/return-reasons", returnHandler.ListReturnReasons)
+	protected.Post("/return-reasons", returnHandler.CreateReturnReason)
+	protected.Put("/return-reasons/:id", returnHandler.UpdateReturnReason)
 
 	// Dashboard Routes
 	protected.Get("/dashboard/stats", dashboardHandler.GetStats)
 
+	// Import Routes
+	protected.Post("/imports/:kind", importHandler.Import)
+
+	// Audit Log Routes
+	protected.Get("/audit-logs", auditHandler.ListAuditLogs)
+
+	// Export Routes
+	protected.Get("/exports/invoices", exportHandler.ExportInvoices)
+	protected.Get("/exports/stock-movements", exportHandler.ExportStockMovements)
+	protected.Get("/exports/stock", exportHandler.ExportStock)
+	protected.Get("/exports/customer-ledger", exportHandler.ExportCustomerLedger)
+
 	// Aynı route'lar /v1/... altında (Nginx /api keserse)
 	protectedDirect.Post("/invoices", invoiceHandler.CreateInvoice)
 	protectedDirect.Get("/invoices", invoiceHandler.ListInvoices)
 	protectedDirect.Get("/invoices/:id", invoiceHandler.GetInvoiceDetail)
+	protectedDirect.Get("/invoices/:id/pdf", invoiceHandler.GetInvoicePDF)
+	protectedDirect.Post("/invoices/:id/e-invoice", eInvoiceHandler.GenerateEInvoice)
+	protectedDirect.Get("/invoices/:id/e-invoice", eInvoiceHandler.GetEInvoice)
+	protectedDirect.Get("/invoices/:id/e-invoice/xml", eInvoiceHandler.GetEInvoiceXML)
+	protectedDirect.Post("/invoices/:id/e-invoice/send", eInvoiceHandler.SendEInvoice)
+	protectedDirect.Get("/invoices/:id/pick-list", locationHandler.GetInvoicePickList)
+	protectedDirect.Post("/sales-orders", salesOrderHandler.CreateSalesOrder)
+	protectedDirect.Get("/sales-orders", salesOrderHandler.ListSalesOrders)
+	protectedDirect.Get("/sales-orders/:id", salesOrderHandler.GetSalesOrder)
+	protectedDirect.Post("/sales-orders/:id/confirm", salesOrderHandler.ConfirmSalesOrder)
+	protectedDirect.Post("/sales-orders/:id/invoice", salesOrderHandler.InvoiceSalesOrder)
+	protectedDirect.Post("/sales-orders/:id/close", salesOrderHandler.CloseSalesOrder)
+	protectedDirect.Post("/sales-orders/:id/cancel", salesOrderHandler.CancelSalesOrder)
+	protectedDirect.Get("/sales-orders/:id/pick-list", locationHandler.GetSalesOrderPickList)
+
+	protectedDirect.Post("/quotations", quotationHandler.CreateQuotation)
+	protectedDirect.Get("/quotations", quotationHandler.ListQuotations)
+	protectedDirect.Get("/quotations/:id", quotationHandler.GetQuotation)
+	protectedDirect.Post("/quotations/:id/revise", quotationHandler.ReviseQuotation)
+	protectedDirect.Post("/quotations/:id/accept", quotationHandler.AcceptQuotation)
+	protectedDirect.Post("/quotations/:id/reject", quotationHandler.RejectQuotation)
+	protectedDirect.Post("/quotations/:id/convert-to-order", quotationHandler.ConvertToSalesOrder)
+	protectedDirect.Post("/quotations/:id/convert-to-invoice", quotationHandler.ConvertToInvoice)
+
+	protectedDirect.Post("/delivery-notes", deliveryNoteHandler.CreateDeliveryNote)
+	protectedDirect.Get("/delivery-notes", deliveryNoteHandler.ListDeliveryNotes)
+	protectedDirect.Get("/delivery-notes/uninvoiced", deliveryNoteHandler.GetUninvoicedReport)
+	protectedDirect.Post("/delivery-notes/invoice", deliveryNoteHandler.InvoiceDeliveryNotes)
+	protectedDirect.Get("/delivery-notes/:id", deliveryNoteHandler.GetDeliveryNote)
+	protectedDirect.Post("/delivery-notes/:id/cancel", deliveryNoteHandler.CancelDeliveryNote)
+
+	protectedDirect.Post("/suppliers", supplierHandler.CreateSupplier)
+	protectedDirect.Get("/suppliers", supplierHandler.ListSuppliers)
+	protectedDirect.Get("/suppliers/:id", supplierHandler.GetSupplier)
+
+	protectedDirect.Post("/purchase-orders", purchaseOrderHandler.CreatePurchaseOrder)
+	protectedDirect.Get("/purchase-orders", purchaseOrderHandler.ListPurchaseOrders)
+	protectedDirect.Get("/purchase-orders/open-lines", purchaseOrderHandler.ListOpenPurchaseLines)
+	protectedDirect.Get("/purchase-orders/discrepancies", purchaseOrderHandler.ListPurchaseDiscrepancies)
+	protectedDirect.Get("/purchase-orders/:id", purchaseOrderHandler.GetPurchaseOrder)
+	protectedDirect.Post("/purchase-orders/:id/confirm", purchaseOrderHandler.ConfirmPurchaseOrder)
+	protectedDirect.Post("/purchase-orders/:id/receipts", purchaseOrderHandler.ReceiveGoods)
+	protectedDirect.Post("/purchase-orders/:id/invoices", purchaseOrderHandler.RecordPurchaseInvoice)
+	protectedDirect.Get("/purchase-orders/:id/match", purchaseOrderHandler.GetPurchaseOrderMatch)
+	protectedDirect.Get("/purchase-orders/:id/putaway", locationHandler.SuggestPutaway)
+	protectedDirect.Post("/purchase-orders/:id/close", purchaseOrderHandler.ClosePurchaseOrder)
+	protectedDirect.Post("/purchase-orders/:id/cancel", purchaseOrderHandler.CancelPurchaseOrder)
+
+	protectedDirect.Get("/stock-levels", replenishmentHandler.ListStockLevels)
+	protectedDirect.Put("/stock-levels", replenishmentHandler.SetStockLevel)
+	protectedDirect.Delete("/stock-levels/:id", replenishmentHandler.DeleteStockLevel)
+	protectedDirect.Get("/stock/low-stock", replenishmentHandler.GetLowStockReport)
+	protectedDirect.Get("/replenishment/suggestions", replenishmentHandler.GetReplenishmentSuggestions)
+	protectedDirect.Post("/replenishment/purchase-orders", replenishmentHandler.CreateReplenishmentOrders)
+
+	protectedDirect.Get("/company-profile", tenantHandler.GetCompanyProfile)
+	protectedDirect.Put("/company-profile", tenantHandler.UpdateCompanyProfile)
+	protectedDirect.Get("/invoice-template", tenantHandler.GetInvoiceTemplate)
+	protectedDirect.Put("/invoice-template", tenantHandler.SaveInvoiceTemplate)
+
+	protectedDirect.Get("/costing/method", costingHandler.GetCostingMethod)
+	protectedDirect.Put("/costing/method", costingHandler.SetCostingMethod)
+	protectedDirect.Post("/costing/recalculate", costingHandler.Recalculate)
+	protectedDirect.Get("/costing/cogs", costingHandler.GetCostOfGoods)
+	protectedDirect.Get("/stock/valuation", costingHandler.GetStockValuation)
+	protectedDirect.Get("/invoices/:id/profit", costingHandler.GetInvoiceProfit)
+	protectedDirect.Get("/reports/gross-profit", reportHandler.GetGrossProfit)
+	protectedDirect.Get("/reports/receivables-aging", reportHandler.GetReceivablesAging)
 	protectedDirect.Post("/products", productHandler.CreateProduct)
 	protectedDirect.Get("/products", productHandler.ListProducts)
+	protectedDirect.Put("/products/:id/standard-cost", productHandler.SetStandardCost)
+	protectedDirect.Put("/products/:id/lot-tracking", productHandler.SetLotTracking)
+	protectedDirect.Put("/products/:id/serial-tracking", productHandler.SetSerialTracking)
+	protectedDirect.Get("/products/:id/serials", serialHandler.ListProductSerials)
+	protectedDirect.Get("/products/:id/movements", stockHandler.GetProductMovements)
+	protectedDirect.Put("/products/:id/classification", categoryHandler.SetProductClassification)
+	protectedDirect.Put("/products/:id/attributes", categoryHandler.SetProductAttributes)
+	protectedDirect.Get("/products/:id/variant-dimensions", variantHandler.ListDimensions)
+	protectedDirect.Put("/products/:id/variant-dimensions", variantHandler.SetDimensions)
+	protectedDirect.Get("/products/:id/variants", variantHandler.GetVariantSummary)
+	protectedDirect.Post("/products/:id/variants", variantHandler.GenerateVariants)
+	protectedDirect.Put("/products/:id/variant-price", variantHandler.SetVariantPrice)
+	protectedDirect.Get("/products/:id/kit", kitHandler.GetComponents)
+	protectedDirect.Put("/products/:id/kit", kitHandler.SetComponents)
+	protectedDirect.Get("/products/:id/kit-availability", kitHandler.GetAvailability)
+	protectedDirect.Get("/products/:id/bom", assemblyHandler.GetBOM)
+	protectedDirect.Put("/products/:id/bom", assemblyHandler.SetBOM)
+	protectedDirect.Delete("/products/:id/bom", assemblyHandler.DeleteBOM)
+	protectedDirect.Post("/assembly-orders", assemblyHandler.CreateAssemblyOrder)
+	protectedDirect.Get("/assembly-orders", assemblyHandler.ListAssemblyOrders)
+	protectedDirect.Get("/assembly-orders/:id", assemblyHandler.GetAssemblyOrder)
+	protectedDirect.Post("/assembly-orders/:id/complete", assemblyHandler.CompleteAssemblyOrder)
+	protectedDirect.Post("/assembly-orders/:id/cancel", assemblyHandler.CancelAssemblyOrder)
+	protectedDirect.Get("/categories", categoryHandler.ListCategories)
+	protectedDirect.Post("/categories", categoryHandler.CreateCategory)
+	protectedDirect.Put("/categories/:id", categoryHandler.UpdateCategory)
+	protectedDirect.Delete("/categories/:id", categoryHandler.DeleteCategory)
+	protectedDirect.Get("/categories/:id/attributes", categoryHandler.ListCategoryAttributes)
+	protectedDirect.Post("/categories/:id/attributes", categoryHandler.CreateAttribute)
+	protectedDirect.Get("/brands", categoryHandler.ListBrands)
+	protectedDirect.Post("/brands", categoryHandler.CreateBrand)
 	protectedDirect.Post("/customers", customerHandler.CreateCustomer)
 	protectedDirect.Get("/customers", customerHandler.ListCustomers)
 	protectedDirect.Get("/customers/:customerId/ledger", customerHandler.GetCustomerLedger)
+	protectedDirect.Get("/customers/:customerId/credit", customerHandler.GetCustomerCredit)
+	protectedDirect.Put("/customers/:customerId/credit", customerHandler.SetCustomerCredit)
+	protectedDirect.Post("/customers/:customerId/payments", customerHandler.CreateCustomerPayment)
+	protectedDirect.Get("/customers/:customerId/payments", customerHandler.ListCustomerPayments)
+	protectedDirect.Get("/customers/:customerId/statement", customerHandler.GetCustomerStatement)
 	protectedDirect.Post("/warehouses", warehouseHandler.CreateWarehouse)
 	protectedDirect.Get("/warehouses", warehouseHandler.ListWarehouses)
+	protectedDirect.Get("/warehouses/:id/locations", locationHandler.ListLocations)
+	protectedDirect.Post("/warehouses/:id/locations", locationHandler.CreateLocation)
+	protectedDirect.Get("/warehouses/:id/location-stock", locationHandler.ListLocationBalances)
+	protectedDirect.Post("/warehouses/:id/location-transfers", locationHandler.TransferStock)
+	protectedDirect.Put("/locations/:id", locationHandler.UpdateLocation)
 	protectedDirect.Get("/stock-movements", stockHandler.ListStockMovements)
 	protectedDirect.Post("/stock-movements", stockHandler.CreateStockMovement)
 	protectedDirect.Get("/stock-balance", stockHandler.GetStockBalance)
 	protectedDirect.Get("/stock-balance-total", stockHandler.GetTotalStockBalance)
 	protectedDirect.Get("/stock-balance-by-warehouse", stockHandler.GetStockBalanceByWarehouse)
+	protectedDirect.Get("/stock-balances/check", stockBalanceHandler.CheckBalances)
+	protectedDirect.Post("/stock-balances/repair", stockBalanceHandler.RepairBalances)
+	protectedDirect.Get("/lots", lotHandler.ListLotBalances)
+	protectedDirect.Get("/lots/expiry", lotHandler.GetExpiryReport)
+	protectedDirect.Get("/lots/:id/trace", lotHandler.TraceLot)
+	protectedDirect.Get("/serials/:serial", serialHandler.GetSerialHistory)
 	protectedDirect.Post("/returns", returnHandler.CreateCustomerReturn)
 	protectedDirect.Get("/returns", returnHandler.ListCustomerReturns)
 	protectedDirect.Get("/returns/customer-purchases/:customerId", returnHandler.ListCustomerPurchases)
+	protectedDirect.Get("/returns/reasons-report", returnHandler.GetReturnReasonReport)
+	protectedDirect.Post("/returns/:id/receive", returnHandler.ReceiveCustomerReturn)
+	protectedDirect.Post("/returns/:id/inspect", returnHandler.InspectCustomerReturn)
+	protectedDirect.Post("/returns/:id/close", returnHandler.CloseCustomerReturn)
+	protectedDirect.Post("/returns/:id/cancel", returnHandler.CancelCustomerReturn)
+	protectedDirect.Post("/returns/:id/reject", returnHandler.RejectCustomerReturn)
+	protectedDirect.Get("/return-reasons", returnHandler.ListReturnReasons)
+	protectedDirect.Post("/return-reasons", returnHandler.CreateReturnReason)
+	protectedDirect.Put("/return-reasons/:id", returnHandler.UpdateReturnReason)
 	protectedDirect.Get("/dashboard/stats", dashboardHandler.GetStats)
+	protectedDirect.Post("/imports/:kind", importHandler.Import)
+	protectedDirect.Get("/audit-logs", auditHandler.ListAuditLogs)
+	protectedDirect.Get("/exports/invoices", exportHandler.ExportInvoices)
+	protectedDirect.Get("/exports/stock-movements", exportHandler.ExportStockMovements)
+	protectedDirect.Get("/exports/stock", exportHandler.ExportStock)
+	protectedDirect.Get("/exports/customer-ledger", exportHandler.ExportCustomerLedger)
 
 	// Health Check
 	app.Get("/health", func(c *fiber.Ctx) error {
@@ -12,6 +12,14 @@ END $$;
 CREATE TABLE tenants (
     id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
     name VARCHAR(255) NOT NULL,
+    costing_method VARCHAR(10) NOT NULL DEFAULT 'AVERAGE' CHECK (costing_method IN ('AVERAGE', 'FIFO')),
+    address TEXT, -- Letterhead details printed on statements and invoices
+    district VARCHAR(100), -- İlçe
+    city VARCHAR(100), -- İl
+    phone VARCHAR(50),
+    email VARCHAR(255),
+    tax_office VARCHAR(100),
+    tax_number VARCHAR(20),
     created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
     -- deleted_at TIMESTAMP NULL -- Optional
@@ -37,13 +45,39 @@ CREATE TABLE customers (
     phone VARCHAR(50),
     email VARCHAR(255),
     address TEXT,
-    tax_number VARCHAR(50),
+    district VARCHAR(100), -- İlçe
+    city VARCHAR(100), -- İl
+    tax_number VARCHAR(50), -- VKN for companies, TCKN for individuals
+    tax_office VARCHAR(100),
+    credit_limit DECIMAL(15, 2) CHECK (credit_limit >= 0), -- NULL: no limit
+    payment_term_days INTEGER NOT NULL DEFAULT 0 CHECK (payment_term_days >= 0), -- Invoice due date = invoice date + term
     created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
     deleted_at TIMESTAMP NULL,
     UNIQUE(tenant_id, email)
 );
 
+-- 3.1 Product Categories (Tree; a product belongs to one category)
+CREATE TABLE product_categories (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    parent_id UUID REFERENCES product_categories(id) ON DELETE RESTRICT,
+    name VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK (parent_id IS DISTINCT FROM id)
+);
+
+-- 3.2 Brands
+CREATE TABLE brands (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(tenant_id, name)
+);
+
 -- 4. Products
 CREATE TABLE products (
     id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
@@ -54,23 +88,101 @@ CREATE TABLE products (
     unit VARCHAR(10) NOT NULL DEFAULT 'adet' CHECK (unit IN ('adet', 'kg')),
     price DECIMAL(10, 2) NOT NULL DEFAULT 0.00 CHECK (price >= 0),
     vat_rate DECIMAL(5, 2) NOT NULL DEFAULT 18.00 CHECK (vat_rate >= 0),
+    standard_cost DECIMAL(15, 4) CHECK (standard_cost >= 0), -- Optional fixed cost captured on sales instead of the last purchase cost
+    track_lots BOOLEAN NOT NULL DEFAULT FALSE, -- Inbound movements need a lot number; sales are allocated per lot
+    track_serials BOOLEAN NOT NULL DEFAULT FALSE, -- Every movement names one serial number per unit
+    category_id UUID REFERENCES product_categories(id) ON DELETE RESTRICT,
+    brand_id UUID REFERENCES brands(id) ON DELETE RESTRICT,
+    parent_id UUID REFERENCES products(id) ON DELETE RESTRICT, -- Set on variants; a product with variant dimensions holds no stock itself
+    variant_key VARCHAR(255) NOT NULL DEFAULT '', -- Variant's dimension values in dimension order, e.g. 'M / Red'
     created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
     deleted_at TIMESTAMP NULL,
+    CHECK (NOT (track_lots AND track_serials)),
     UNIQUE(tenant_id, sku)
 );
 
+-- 4.1 Category Attributes (Typed attributes; a category's products also get its ancestors' attributes)
+CREATE TABLE category_attributes (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    category_id UUID NOT NULL REFERENCES product_categories(id) ON DELETE CASCADE,
+    code VARCHAR(50) NOT NULL, -- Used as the filter key, e.g. ?attr.color=red
+    name VARCHAR(255) NOT NULL,
+    data_type VARCHAR(10) NOT NULL CHECK (data_type IN ('TEXT', 'NUMBER', 'BOOLEAN', 'OPTION')),
+    options TEXT[] NOT NULL DEFAULT '{}', -- Allowed values of OPTION attributes
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(category_id, code)
+);
+
+-- 4.2 Product Attribute Values
+-- Values are stored as normalized text: numbers without trailing zeros, booleans as true/false.
+CREATE TABLE product_attribute_values (
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    attribute_id UUID NOT NULL REFERENCES category_attributes(id) ON DELETE CASCADE,
+    value TEXT NOT NULL,
+    PRIMARY KEY (product_id, attribute_id)
+);
+
+-- 4.3 Product Variant Dimensions (e.g. Size: S, M, L on a parent product)
+CREATE TABLE product_variant_dimensions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE, -- The parent product
+    name VARCHAR(50) NOT NULL,
+    position INTEGER NOT NULL, -- Order of the dimension in variant names, SKUs and keys
+    "values" TEXT[] NOT NULL,
+    UNIQUE(product_id, name),
+    UNIQUE(product_id, position)
+);
+
+-- 4.4 Product Variant Values (The dimension values of each variant)
+CREATE TABLE product_variant_values (
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE, -- The variant
+    dimension_id UUID NOT NULL REFERENCES product_variant_dimensions(id) ON DELETE CASCADE,
+    value TEXT NOT NULL,
+    PRIMARY KEY (product_id, dimension_id)
+);
+
+-- 4.5 Kit Components (A kit is sold as one product but ships its components; it holds no stock)
+CREATE TABLE kit_components (
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    kit_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    component_id UUID NOT NULL REFERENCES products(id) ON DELETE RESTRICT,
+    quantity INTEGER NOT NULL CHECK (quantity > 0), -- Units of the component per kit
+    PRIMARY KEY (kit_id, component_id),
+    CHECK (kit_id <> component_id)
+);
+
 -- 5. Warehouses
 CREATE TABLE warehouses (
     id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
     tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
     name VARCHAR(255) NOT NULL,
     location TEXT,
+    is_quarantine BOOLEAN NOT NULL DEFAULT FALSE, -- Scrap/quarantine stock, never sold from
     created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
     deleted_at TIMESTAMP NULL
 );
 
+-- 5.1 Warehouse Locations (Optional aisles, shelves and bins inside a warehouse)
+-- path joins the codes from the top level down with '/' and is the picking order.
+CREATE TABLE warehouse_locations (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    warehouse_id UUID NOT NULL REFERENCES warehouses(id) ON DELETE CASCADE,
+    parent_id UUID REFERENCES warehouse_locations(id) ON DELETE RESTRICT,
+    code VARCHAR(50) NOT NULL CHECK (code <> '' AND position('/' in code) = 0),
+    path VARCHAR(255) NOT NULL,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE, -- Inactive locations take no new stock
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(tenant_id, warehouse_id, path)
+);
+
 -- 6. Stock Movements (The Core Logic)
 CREATE TABLE stock_movements (
     id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
@@ -81,15 +193,196 @@ CREATE TABLE stock_movements (
     type stock_movement_type NOT NULL, -- ENUM ('IN', 'OUT', 'SALE', 'TRANSFER', 'ADJUSTMENT')
     reference_id UUID, -- Links to invoice_id, adjustment_id, etc.
     reference_type VARCHAR(50), -- 'INVOICE', 'ADJUSTMENT', 'PURCHASE_ORDER'
+    unit_cost DECIMAL(15, 4) CHECK (unit_cost >= 0), -- Entered purchase cost of inbound movements
+    assigned_cost DECIMAL(15, 4), -- Per-unit cost set by the costing engine; NULL until costed
+    lot_id UUID, -- Lot of a lot-tracked product; NULL for untracked stock (FK added after lots)
+    location_id UUID REFERENCES warehouse_locations(id) ON DELETE RESTRICT, -- Bin in warehouse_id; NULL for unlocated stock
     created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP 
 );
 
+-- 6.1 Stock Balances (Materialized SUM of stock_movements per product and warehouse)
+-- Maintained by trg_stock_movements_balance in the same transaction as each movement.
+-- Drift can be detected and repaired from the ledger via /stock-balances/check and /stock-balances/repair.
+-- Databases created before these tables get them, their triggers and a backfill from the
+-- ledger when the API starts (StockBalanceService.EnsureBalanceTables).
+CREATE TABLE stock_balances (
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    warehouse_id UUID NOT NULL REFERENCES warehouses(id) ON DELETE CASCADE,
+    quantity INTEGER NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (tenant_id, product_id, warehouse_id)
+);
+
+-- 6.2 Lots (Batches of lot-tracked products, created on first receipt)
+CREATE TABLE lots (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    lot_number VARCHAR(100) NOT NULL,
+    expiry_date DATE, -- Optional; lots without one are allocated last
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(tenant_id, product_id, lot_number)
+);
+
+ALTER TABLE stock_movements ADD CONSTRAINT fk_stock_movements_lot FOREIGN KEY (lot_id) REFERENCES lots(id) ON DELETE RESTRICT;
+
+-- 6.3 Stock Lot Balances (Materialized SUM of stock_movements per lot and warehouse)
+-- Movements without a lot only count towards stock_balances.
+CREATE TABLE stock_lot_balances (
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    lot_id UUID NOT NULL REFERENCES lots(id) ON DELETE CASCADE,
+    warehouse_id UUID NOT NULL REFERENCES warehouses(id) ON DELETE CASCADE,
+    quantity INTEGER NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (tenant_id, lot_id, warehouse_id)
+);
+
+-- 6.31 Location Transfers (Moves between locations of one warehouse; warehouse stock is unchanged)
+-- A NULL location is the warehouse's unlocated stock (e.g. the receiving area).
+CREATE TABLE location_transfers (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    warehouse_id UUID NOT NULL REFERENCES warehouses(id) ON DELETE RESTRICT,
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE RESTRICT,
+    from_location_id UUID REFERENCES warehouse_locations(id) ON DELETE RESTRICT,
+    to_location_id UUID REFERENCES warehouse_locations(id) ON DELETE RESTRICT,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    user_id UUID,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK (from_location_id IS DISTINCT FROM to_location_id)
+);
+
+-- 6.32 Stock Location Balances (Materialized stock per location and product)
+-- SUM of stock_movements by location_id plus location_transfers. Lots are not split by
+-- location; unlocated stock is the warehouse balance minus its locations.
+CREATE TABLE stock_location_balances (
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    location_id UUID NOT NULL REFERENCES warehouse_locations(id) ON DELETE CASCADE,
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    quantity INTEGER NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (tenant_id, location_id, product_id)
+);
+
+-- Movement and transfer triggers hold this shared per-tenant lock until their transaction
+-- ends; /stock-balances/repair takes it exclusively, so it only waits for that tenant.
+CREATE OR REPLACE FUNCTION lock_stock_balances(p_tenant_id UUID) RETURNS VOID AS $$
+BEGIN
+    PERFORM pg_advisory_xact_lock_shared(hashtext('stock_balances'), hashtext(p_tenant_id::text));
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION add_location_balance(p_tenant_id UUID, p_location_id UUID, p_product_id UUID, p_quantity INTEGER) RETURNS VOID AS $$
+BEGIN
+    IF p_location_id IS NULL THEN
+        RETURN;
+    END IF;
+    INSERT INTO stock_location_balances (tenant_id, location_id, product_id, quantity, updated_at)
+    VALUES (p_tenant_id, p_location_id, p_product_id, p_quantity, NOW())
+    ON CONFLICT (tenant_id, location_id, product_id) DO UPDATE
+    SET quantity = stock_location_balances.quantity + EXCLUDED.quantity, updated_at = NOW();
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION apply_location_transfer_balance() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        PERFORM lock_stock_balances(OLD.tenant_id);
+        UPDATE stock_location_balances
+        SET quantity = quantity + OLD.quantity, updated_at = NOW()
+        WHERE tenant_id = OLD.tenant_id AND location_id = OLD.from_location_id AND product_id = OLD.product_id;
+        UPDATE stock_location_balances
+        SET quantity = quantity - OLD.quantity, updated_at = NOW()
+        WHERE tenant_id = OLD.tenant_id AND location_id = OLD.to_location_id AND product_id = OLD.product_id;
+        RETURN NULL;
+    END IF;
+    PERFORM lock_stock_balances(NEW.tenant_id);
+    PERFORM add_location_balance(NEW.tenant_id, NEW.from_location_id, NEW.product_id, -NEW.quantity);
+    PERFORM add_location_balance(NEW.tenant_id, NEW.to_location_id, NEW.product_id, NEW.quantity);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_location_transfers_balance
+AFTER INSERT OR DELETE ON location_transfers
+FOR EACH ROW EXECUTE FUNCTION apply_location_transfer_balance();
+
+CREATE OR REPLACE FUNCTION apply_stock_movement_balance() RETURNS TRIGGER AS $$
+BEGIN
+    -- Removing a movement only ever decrements an existing row (tenant cascades may already be deleting it)
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        PERFORM lock_stock_balances(OLD.tenant_id);
+        UPDATE stock_balances
+        SET quantity = quantity - OLD.quantity, updated_at = NOW()
+        WHERE tenant_id = OLD.tenant_id AND product_id = OLD.product_id AND warehouse_id = OLD.warehouse_id;
+        IF OLD.lot_id IS NOT NULL THEN
+            UPDATE stock_lot_balances
+            SET quantity = quantity - OLD.quantity, updated_at = NOW()
+            WHERE tenant_id = OLD.tenant_id AND lot_id = OLD.lot_id AND warehouse_id = OLD.warehouse_id;
+        END IF;
+        IF OLD.location_id IS NOT NULL THEN
+            UPDATE stock_location_balances
+            SET quantity = quantity - OLD.quantity, updated_at = NOW()
+            WHERE tenant_id = OLD.tenant_id AND location_id = OLD.location_id AND product_id = OLD.product_id;
+        END IF;
+    END IF;
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        PERFORM lock_stock_balances(NEW.tenant_id);
+        INSERT INTO stock_balances (tenant_id, product_id, warehouse_id, quantity, updated_at)
+        VALUES (NEW.tenant_id, NEW.product_id, NEW.warehouse_id, NEW.quantity, NOW())
+        ON CONFLICT (tenant_id, product_id, warehouse_id) DO UPDATE
+        SET quantity = stock_balances.quantity + EXCLUDED.quantity, updated_at = NOW();
+        IF NEW.lot_id IS NOT NULL THEN
+            INSERT INTO stock_lot_balances (tenant_id, lot_id, warehouse_id, quantity, updated_at)
+            VALUES (NEW.tenant_id, NEW.lot_id, NEW.warehouse_id, NEW.quantity, NOW())
+            ON CONFLICT (tenant_id, lot_id, warehouse_id) DO UPDATE
+            SET quantity = stock_lot_balances.quantity + EXCLUDED.quantity, updated_at = NOW();
+        END IF;
+        PERFORM add_location_balance(NEW.tenant_id, NEW.location_id, NEW.product_id, NEW.quantity);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_stock_movements_balance
+AFTER INSERT OR DELETE OR UPDATE OF tenant_id, product_id, warehouse_id, quantity, lot_id, location_id ON stock_movements
+FOR EACH ROW EXECUTE FUNCTION apply_stock_movement_balance();
+
+-- 6.4 Serial Numbers (Units of serialized products, created on first receipt)
+CREATE TABLE serial_numbers (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    serial_number VARCHAR(100) NOT NULL,
+    warehouse_id UUID REFERENCES warehouses(id), -- Where the unit is; NULL while it is out of stock (sold, issued)
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(tenant_id, product_id, serial_number)
+);
+
+-- 6.41 Stock Movement Serials (Which units each movement of a serialized product moved)
+CREATE TABLE stock_movement_serials (
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    movement_id UUID NOT NULL REFERENCES stock_movements(id) ON DELETE CASCADE,
+    serial_id UUID NOT NULL REFERENCES serial_numbers(id) ON DELETE CASCADE,
+    PRIMARY KEY (movement_id, serial_id)
+);
+
 -- 6.5 Invoice Sequences (Atomic Numbering)
 CREATE TABLE invoice_sequences (
     tenant_id UUID PRIMARY KEY REFERENCES tenants(id) ON DELETE CASCADE,
     last_number INTEGER NOT NULL DEFAULT 0
 );
 
+-- 6.55 Document Sequences (Atomic numbering for non-invoice documents)
+CREATE TABLE document_sequences (
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    document_type VARCHAR(50) NOT NULL, -- 'SALES_ORDER', ...
+    last_number INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (tenant_id, document_type)
+);
+
 -- 6.6 Audit Logs (Enterprise Traceability)
 CREATE TABLE audit_logs (
     id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
@@ -98,7 +391,76 @@ CREATE TABLE audit_logs (
     entity_type VARCHAR(50) NOT NULL, -- 'INVOICE', 'PRODUCT', etc.
     entity_id UUID NOT NULL,
     action VARCHAR(50) NOT NULL, -- 'CREATE', 'UPDATE', 'DELETE'
-    details JSONB, -- Optional: Store snapshot or changes
+    details JSONB, -- {"changes": {"field": {"from": ..., "to": ...}}, ...}
+    ip_address VARCHAR(45),
+    request_id VARCHAR(100),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 6.8 Sales Orders (Reserve stock until invoiced)
+-- Lifecycle: DRAFT -> CONFIRMED -> PARTIALLY_DELIVERED -> CLOSED (or CANCELLED)
+CREATE TABLE sales_orders (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    customer_id UUID NOT NULL REFERENCES customers(id) ON DELETE RESTRICT,
+    warehouse_id UUID NOT NULL REFERENCES warehouses(id) ON DELETE RESTRICT,
+    order_number VARCHAR(50) NOT NULL,
+    status VARCHAR(30) NOT NULL DEFAULT 'DRAFT' CHECK (status IN ('DRAFT', 'CONFIRMED', 'PARTIALLY_DELIVERED', 'CLOSED', 'CANCELLED')),
+    note TEXT,
+    total_amount DECIMAL(15, 2) NOT NULL DEFAULT 0.00 CHECK (total_amount >= 0),
+    confirmed_at TIMESTAMP NULL,
+    closed_at TIMESTAMP NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP NULL,
+    UNIQUE(tenant_id, order_number)
+);
+
+-- 6.9 Sales Order Items
+-- Reserved quantity = quantity - delivered_qty while the order is CONFIRMED or PARTIALLY_DELIVERED.
+CREATE TABLE sales_order_items (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    order_id UUID NOT NULL REFERENCES sales_orders(id) ON DELETE CASCADE,
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE RESTRICT,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    delivered_qty INTEGER NOT NULL DEFAULT 0 CHECK (delivered_qty >= 0),
+    unit_price DECIMAL(10, 2) NOT NULL CHECK (unit_price >= 0),
+    total DECIMAL(15, 2) NOT NULL CHECK (total >= 0),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK (delivered_qty <= quantity)
+);
+
+-- 6.10 Quotations (Teklif)
+-- Revisions share quote_number; the previous revision becomes SUPERSEDED.
+CREATE TABLE quotations (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    customer_id UUID NOT NULL REFERENCES customers(id) ON DELETE RESTRICT,
+    warehouse_id UUID NOT NULL REFERENCES warehouses(id) ON DELETE RESTRICT,
+    quote_number VARCHAR(50) NOT NULL,
+    revision INTEGER NOT NULL DEFAULT 1 CHECK (revision > 0),
+    status VARCHAR(20) NOT NULL DEFAULT 'SENT' CHECK (status IN ('SENT', 'ACCEPTED', 'REJECTED', 'EXPIRED', 'SUPERSEDED')),
+    valid_until DATE NOT NULL,
+    note TEXT,
+    total_amount DECIMAL(15, 2) NOT NULL DEFAULT 0.00 CHECK (total_amount >= 0),
+    sales_order_id UUID REFERENCES sales_orders(id) ON DELETE RESTRICT, -- Converted order
+    invoice_id UUID, -- Converted invoice (FK added after invoices table)
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP NULL,
+    UNIQUE(tenant_id, quote_number, revision)
+);
+
+-- 6.11 Quotation Items
+CREATE TABLE quotation_items (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    quotation_id UUID NOT NULL REFERENCES quotations(id) ON DELETE CASCADE,
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE RESTRICT,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    unit_price DECIMAL(10, 2) NOT NULL CHECK (unit_price >= 0),
+    total DECIMAL(15, 2) NOT NULL CHECK (total >= 0),
     created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
 );
 
@@ -111,6 +473,9 @@ CREATE TABLE invoices (
     invoice_number VARCHAR(50) NOT NULL, 
     total_amount DECIMAL(15, 2) NOT NULL DEFAULT 0.00 CHECK (total_amount >= 0),
     idempotency_key UUID, -- Prevent duplicate requests
+    sales_order_id UUID REFERENCES sales_orders(id) ON DELETE RESTRICT, -- Source order when converted
+    salesperson_id UUID, -- User who issued the invoice
+    due_date DATE NOT NULL DEFAULT CURRENT_DATE, -- From the customer's payment term at issue
     created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
     deleted_at TIMESTAMP NULL,
@@ -118,6 +483,39 @@ CREATE TABLE invoices (
     UNIQUE(tenant_id, idempotency_key) -- Scoped to tenant
 );
 
+ALTER TABLE quotations ADD CONSTRAINT fk_quotations_invoice FOREIGN KEY (invoice_id) REFERENCES invoices(id) ON DELETE RESTRICT;
+
+-- 7.1 Delivery Notes (İrsaliye)
+-- Stock leaves the warehouse when the note is SHIPPED; the invoice that bills it later moves no stock.
+CREATE TABLE delivery_notes (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    customer_id UUID NOT NULL REFERENCES customers(id) ON DELETE RESTRICT,
+    warehouse_id UUID NOT NULL REFERENCES warehouses(id) ON DELETE RESTRICT,
+    note_number VARCHAR(50) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'SHIPPED' CHECK (status IN ('SHIPPED', 'INVOICED', 'CANCELLED')),
+    note TEXT,
+    total_amount DECIMAL(15, 2) NOT NULL DEFAULT 0.00 CHECK (total_amount >= 0),
+    invoice_id UUID REFERENCES invoices(id) ON DELETE RESTRICT, -- Set once billed
+    shipped_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP NULL,
+    UNIQUE(tenant_id, note_number)
+);
+
+-- 7.2 Delivery Note Items
+CREATE TABLE delivery_note_items (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    delivery_note_id UUID NOT NULL REFERENCES delivery_notes(id) ON DELETE CASCADE,
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE RESTRICT,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    unit_price DECIMAL(10, 2) NOT NULL CHECK (unit_price >= 0),
+    total DECIMAL(15, 2) NOT NULL CHECK (total >= 0),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
 -- 8. Invoice Items
 CREATE TABLE invoice_items (
     id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
@@ -127,10 +525,42 @@ CREATE TABLE invoice_items (
     quantity INTEGER NOT NULL CHECK (quantity > 0),
     unit_price DECIMAL(10, 2) NOT NULL CHECK (unit_price >= 0),
     total DECIMAL(15, 2) NOT NULL CHECK (total >= 0),
+    unit_cost DECIMAL(15, 4) NOT NULL DEFAULT 0, -- Product cost captured at sale time (standard or last purchase cost)
+    category_id UUID REFERENCES product_categories(id) ON DELETE RESTRICT, -- Product category at sale time, so recategorizing keeps past reports
+    brand_id UUID REFERENCES brands(id) ON DELETE RESTRICT, -- Product brand at sale time
+    vat_rate DECIMAL(5, 2) CHECK (vat_rate >= 0), -- Product VAT rate at sale time; prices include VAT
     created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
 );
 
+-- 8.35 Invoice Item Components (Components shipped for a kit line, with the line revenue
+-- allocated by component list price)
+CREATE TABLE invoice_item_components (
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    invoice_item_id UUID NOT NULL REFERENCES invoice_items(id) ON DELETE CASCADE,
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE RESTRICT,
+    quantity INTEGER NOT NULL CHECK (quantity > 0), -- Units shipped for the whole line
+    revenue DECIMAL(15, 2) NOT NULL CHECK (revenue >= 0),
+    unit_cost DECIMAL(15, 4) NOT NULL DEFAULT 0, -- Component cost captured at sale time
+    category_id UUID REFERENCES product_categories(id) ON DELETE RESTRICT,
+    brand_id UUID REFERENCES brands(id) ON DELETE RESTRICT,
+    PRIMARY KEY (invoice_item_id, product_id)
+);
+
+-- 8.4 Return Reasons (Tenant-managed code list)
+CREATE TABLE return_reasons (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    code VARCHAR(50) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(tenant_id, code)
+);
+
 -- 8.5 Customer Returns
+-- Lifecycle: REQUESTED -> RECEIVED -> INSPECTED -> CLOSED.
+-- Stock moves only at inspection: restock_qty back to warehouse_id, scrap_qty to scrap_warehouse_id.
 CREATE TABLE customer_returns (
     id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
     tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
@@ -141,19 +571,249 @@ CREATE TABLE customer_returns (
     unit_price DECIMAL(10, 2) NOT NULL CHECK (unit_price >= 0),
     total DECIMAL(15, 2) NOT NULL CHECK (total >= 0),
     reason TEXT,
+    reason_id UUID REFERENCES return_reasons(id) ON DELETE RESTRICT,
+    status VARCHAR(20) NOT NULL DEFAULT 'REQUESTED' CHECK (status IN ('REQUESTED', 'RECEIVED', 'INSPECTED', 'CLOSED', 'REJECTED', 'CANCELLED')),
+    restock_qty INTEGER NOT NULL DEFAULT 0 CHECK (restock_qty >= 0),
+    scrap_qty INTEGER NOT NULL DEFAULT 0 CHECK (scrap_qty >= 0),
+    supplier_qty INTEGER NOT NULL DEFAULT 0 CHECK (supplier_qty >= 0),
+    scrap_warehouse_id UUID REFERENCES warehouses(id) ON DELETE RESTRICT,
+    inspection_note TEXT,
+    received_at TIMESTAMP NULL,
+    inspected_at TIMESTAMP NULL,
+    closed_at TIMESTAMP NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 8.52 Invoice Templates (Fatura şablonu; one per tenant, defaults apply without a row)
+CREATE TABLE invoice_templates (
+    tenant_id UUID PRIMARY KEY REFERENCES tenants(id) ON DELETE CASCADE,
+    title VARCHAR(50) NOT NULL DEFAULT 'FATURA',
+    accent_color CHAR(7) NOT NULL DEFAULT '#1F3A5F' CHECK (accent_color ~ '^#[0-9A-Fa-f]{6}$'),
+    show_sku BOOLEAN NOT NULL DEFAULT TRUE,
+    show_due_date BOOLEAN NOT NULL DEFAULT TRUE,
+    bank_details TEXT, -- IBANs printed under the totals
+    footer_note TEXT,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 8.53 e-Invoices (UBL-TR e-Fatura / e-Arşiv document of an invoice)
+-- The ETTN stays the same when a draft or rejected document is regenerated.
+CREATE TABLE e_invoices (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    invoice_id UUID NOT NULL UNIQUE REFERENCES invoices(id) ON DELETE RESTRICT,
+    ettn UUID NOT NULL UNIQUE,
+    profile VARCHAR(20) NOT NULL CHECK (profile IN ('TEMELFATURA', 'TICARIFATURA', 'EARSIVFATURA')),
+    document_number CHAR(16) NOT NULL, -- EFT2026000000001
+    xml TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'DRAFT' CHECK (status IN ('DRAFT', 'SENDING', 'SENT', 'ACCEPTED', 'REJECTED')),
+    provider_reference VARCHAR(100),
+    status_message TEXT,
+    sent_at TIMESTAMP NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (tenant_id, document_number)
+);
+
+-- 8.55 Customer Payments (Tahsilat)
+-- Payments are not tied to invoices; they settle the customer's oldest due invoices first.
+CREATE TABLE customer_payments (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    customer_id UUID NOT NULL REFERENCES customers(id) ON DELETE RESTRICT,
+    amount DECIMAL(15, 2) NOT NULL CHECK (amount > 0),
+    payment_date DATE NOT NULL DEFAULT CURRENT_DATE,
+    method VARCHAR(20) NOT NULL CHECK (method IN ('CASH', 'BANK_TRANSFER', 'CARD', 'CHEQUE')),
+    reference VARCHAR(100),
+    note TEXT,
+    created_by UUID,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 8.6 Suppliers
+CREATE TABLE suppliers (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    phone VARCHAR(50),
+    email VARCHAR(255),
+    address TEXT,
+    tax_number VARCHAR(50),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP NULL
+);
+
+-- 8.7 Purchase Orders
+CREATE TABLE purchase_orders (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    supplier_id UUID NOT NULL REFERENCES suppliers(id) ON DELETE RESTRICT,
+    warehouse_id UUID NOT NULL REFERENCES warehouses(id) ON DELETE RESTRICT,
+    order_number VARCHAR(50) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'DRAFT' CHECK (status IN ('DRAFT', 'ORDERED', 'PARTIALLY_RECEIVED', 'RECEIVED', 'CLOSED', 'CANCELLED')),
+    expected_date DATE,
+    note TEXT,
+    total_amount DECIMAL(15, 2) NOT NULL DEFAULT 0.00 CHECK (total_amount >= 0),
+    ordered_at TIMESTAMP NULL,
+    closed_at TIMESTAMP NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP NULL,
+    UNIQUE(tenant_id, order_number)
+);
+
+-- 8.8 Purchase Order Items
+CREATE TABLE purchase_order_items (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    order_id UUID NOT NULL REFERENCES purchase_orders(id) ON DELETE CASCADE,
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE RESTRICT,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    received_qty INTEGER NOT NULL DEFAULT 0 CHECK (received_qty >= 0), -- May exceed quantity (over-delivery is reported)
+    unit_price DECIMAL(10, 2) NOT NULL CHECK (unit_price >= 0),
+    total DECIMAL(15, 2) NOT NULL CHECK (total >= 0),
     created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
 );
 
+-- 8.9 Goods Receipts (Mal Kabul)
+CREATE TABLE goods_receipts (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    order_id UUID NOT NULL REFERENCES purchase_orders(id) ON DELETE RESTRICT,
+    warehouse_id UUID NOT NULL REFERENCES warehouses(id) ON DELETE RESTRICT,
+    receipt_number VARCHAR(50) NOT NULL,
+    note TEXT,
+    received_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(tenant_id, receipt_number)
+);
+
+-- 8.10 Goods Receipt Items
+CREATE TABLE goods_receipt_items (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    receipt_id UUID NOT NULL REFERENCES goods_receipts(id) ON DELETE CASCADE,
+    order_item_id UUID NOT NULL REFERENCES purchase_order_items(id) ON DELETE RESTRICT,
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE RESTRICT,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    unit_cost DECIMAL(10, 2) NOT NULL CHECK (unit_cost >= 0)
+);
+
+-- 8.11 Purchase Invoices (Supplier invoices, used for three-way matching)
+CREATE TABLE purchase_invoices (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    order_id UUID NOT NULL REFERENCES purchase_orders(id) ON DELETE RESTRICT,
+    supplier_id UUID NOT NULL REFERENCES suppliers(id) ON DELETE RESTRICT,
+    invoice_number VARCHAR(50) NOT NULL, -- Supplier's document number
+    invoice_date DATE NOT NULL,
+    total_amount DECIMAL(15, 2) NOT NULL CHECK (total_amount >= 0),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(tenant_id, supplier_id, invoice_number)
+);
+
+-- 8.12 Purchase Invoice Items
+CREATE TABLE purchase_invoice_items (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    invoice_id UUID NOT NULL REFERENCES purchase_invoices(id) ON DELETE CASCADE,
+    order_item_id UUID NOT NULL REFERENCES purchase_order_items(id) ON DELETE RESTRICT,
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE RESTRICT,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    unit_price DECIMAL(10, 2) NOT NULL CHECK (unit_price >= 0),
+    total DECIMAL(15, 2) NOT NULL CHECK (total >= 0)
+);
+
+-- 8.13 Stock Levels (Reorder points per product and warehouse)
+CREATE TABLE stock_levels (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    warehouse_id UUID NOT NULL REFERENCES warehouses(id) ON DELETE CASCADE,
+    min_level INTEGER NOT NULL DEFAULT 0 CHECK (min_level >= 0),
+    reorder_level INTEGER NOT NULL DEFAULT 0 CHECK (reorder_level >= min_level),
+    max_level INTEGER NOT NULL DEFAULT 0 CHECK (max_level >= 0),
+    safety_stock INTEGER NOT NULL DEFAULT 0 CHECK (safety_stock >= 0),
+    lead_time_days INTEGER NOT NULL DEFAULT 0 CHECK (lead_time_days >= 0),
+    supplier_id UUID REFERENCES suppliers(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(tenant_id, product_id, warehouse_id)
+);
+
+-- 8.14 Product Costs (Costing engine result per product)
+-- Rebuilt by replaying the product's movements whenever one of them has no assigned_cost yet.
+CREATE TABLE product_costs (
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    costing_method VARCHAR(10) NOT NULL,
+    on_hand INTEGER NOT NULL DEFAULT 0,
+    unit_cost DECIMAL(15, 4) NOT NULL DEFAULT 0,
+    stock_value DECIMAL(15, 2) NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (tenant_id, product_id)
+);
+
+-- 8.15 Bills of Materials (Components consumed to produce output_quantity units of a product)
+CREATE TABLE bills_of_materials (
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    product_id UUID PRIMARY KEY REFERENCES products(id) ON DELETE CASCADE,
+    output_quantity INTEGER NOT NULL DEFAULT 1 CHECK (output_quantity > 0), -- e.g. 25 bags from one sack
+    note TEXT,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 8.16 BOM Components
+CREATE TABLE bom_components (
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    product_id UUID NOT NULL REFERENCES bills_of_materials(product_id) ON DELETE CASCADE,
+    component_id UUID NOT NULL REFERENCES products(id) ON DELETE RESTRICT,
+    quantity INTEGER NOT NULL CHECK (quantity > 0), -- Per output_quantity units of the product
+    PRIMARY KEY (product_id, component_id),
+    CHECK (product_id <> component_id)
+);
+
+-- 8.17 Assembly Orders (Consume components and produce a product in one warehouse)
+CREATE TABLE assembly_orders (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE RESTRICT,
+    warehouse_id UUID NOT NULL REFERENCES warehouses(id) ON DELETE RESTRICT,
+    order_number VARCHAR(50) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'PLANNED' CHECK (status IN ('PLANNED', 'COMPLETED', 'CANCELLED')),
+    quantity INTEGER NOT NULL CHECK (quantity > 0), -- Planned output
+    produced_qty INTEGER NOT NULL DEFAULT 0 CHECK (produced_qty >= 0),
+    scrap_qty INTEGER NOT NULL DEFAULT 0 CHECK (scrap_qty >= 0), -- Planned output lost in production
+    lot_id UUID REFERENCES lots(id) ON DELETE RESTRICT, -- Lot of the produced goods
+    total_cost DECIMAL(15, 2) NOT NULL DEFAULT 0, -- Cost of the consumed components
+    unit_cost DECIMAL(15, 4) NOT NULL DEFAULT 0, -- total_cost / produced_qty; scrap raises it
+    note TEXT,
+    completed_at TIMESTAMP NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(tenant_id, order_number)
+);
+
+-- 8.18 Assembly Order Components (The bill of materials scaled to the order when it was planned)
+CREATE TABLE assembly_order_components (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    order_id UUID NOT NULL REFERENCES assembly_orders(id) ON DELETE CASCADE,
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE RESTRICT,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    unit_cost DECIMAL(15, 4) NOT NULL DEFAULT 0, -- Captured on completion
+    UNIQUE(order_id, product_id)
+);
+
 -- 9. Current Stock View (Performance Optimization)
--- NOTE: For extreme scale, consider converting this to a MATERIALIZED VIEW with refresh strategies.
+-- Reads the trigger-maintained stock_balances instead of summing the movement ledger.
 CREATE OR REPLACE VIEW current_stock AS
 SELECT
     tenant_id,
     product_id,
     warehouse_id,
-    SUM(quantity) as quantity
-FROM stock_movements
-GROUP BY tenant_id, product_id, warehouse_id;
+    quantity
+FROM stock_balances;
 
 -- Indexing for performance
 -- Tenants
@@ -165,20 +825,41 @@ CREATE INDEX idx_users_tenant_email ON users(tenant_id, email);
 -- Products
 CREATE INDEX idx_products_tenant_sku ON products(tenant_id, sku);
 CREATE INDEX idx_products_tenant_name ON products(tenant_id, name);
+CREATE INDEX idx_products_category ON products(category_id);
+CREATE INDEX idx_products_brand ON products(brand_id);
+CREATE INDEX idx_product_categories_parent ON product_categories(parent_id);
+CREATE UNIQUE INDEX idx_product_categories_unique_name ON product_categories(tenant_id, COALESCE(parent_id, '00000000-0000-0000-0000-000000000000'::uuid), name);
+CREATE INDEX idx_category_attributes_category ON category_attributes(category_id);
+CREATE INDEX idx_product_attribute_values_attribute ON product_attribute_values(attribute_id, value);
+CREATE UNIQUE INDEX idx_products_variant_key ON products(parent_id, variant_key) WHERE parent_id IS NOT NULL;
+CREATE INDEX idx_kit_components_component ON kit_components(component_id);
 
 -- Customers
 CREATE INDEX idx_customers_tenant_name ON customers(tenant_id, name);
 CREATE INDEX idx_customers_tenant_email ON customers(tenant_id, email);
 
+-- Stock Balances
+CREATE INDEX idx_stock_balances_tenant_warehouse ON stock_balances(tenant_id, warehouse_id);
+
 -- Stock Movements
 CREATE INDEX idx_stock_movements_tenant_product ON stock_movements(tenant_id, product_id);
 CREATE INDEX idx_stock_movements_tenant_warehouse ON stock_movements(tenant_id, warehouse_id);
 CREATE INDEX idx_stock_movements_reference ON stock_movements(reference_id, reference_type);
 CREATE INDEX idx_stock_movements_created_at ON stock_movements(created_at);
+CREATE INDEX idx_stock_movements_uncosted ON stock_movements(tenant_id, product_id) WHERE assigned_cost IS NULL;
+CREATE INDEX idx_stock_movements_lot ON stock_movements(tenant_id, lot_id) WHERE lot_id IS NOT NULL;
+CREATE INDEX idx_stock_movements_location ON stock_movements(tenant_id, location_id) WHERE location_id IS NOT NULL;
+CREATE INDEX idx_warehouse_locations_parent ON warehouse_locations(parent_id);
+CREATE INDEX idx_location_transfers_tenant_warehouse ON location_transfers(tenant_id, warehouse_id, created_at);
+CREATE INDEX idx_lots_tenant_expiry ON lots(tenant_id, expiry_date);
+CREATE INDEX idx_serial_numbers_tenant_serial ON serial_numbers(tenant_id, serial_number);
+CREATE INDEX idx_stock_movement_serials_serial ON stock_movement_serials(serial_id);
 
 -- Audit Logs
 CREATE INDEX idx_audit_logs_tenant_entity ON audit_logs(tenant_id, entity_type, entity_id);
 CREATE INDEX idx_audit_logs_created_at ON audit_logs(created_at);
+CREATE INDEX idx_audit_logs_tenant_created_at ON audit_logs(tenant_id, created_at);
+CREATE INDEX idx_audit_logs_tenant_user ON audit_logs(tenant_id, user_id, created_at);
 
 -- Invoices
 CREATE INDEX idx_invoices_tenant_customer ON invoices(tenant_id, customer_id);
@@ -186,6 +867,47 @@ CREATE INDEX idx_invoices_tenant_warehouse ON invoices(tenant_id, warehouse_id);
 CREATE INDEX idx_invoices_tenant_number ON invoices(tenant_id, invoice_number);
 CREATE INDEX idx_invoices_tenant_idempotency ON invoices(tenant_id, idempotency_key); -- For fast checks
 CREATE INDEX idx_invoices_created_at ON invoices(created_at);
+CREATE INDEX idx_invoices_tenant_created_at ON invoices(tenant_id, created_at);
+CREATE INDEX idx_invoices_tenant_customer_due ON invoices(tenant_id, customer_id, due_date);
+
+-- Customer Returns
+CREATE INDEX idx_customer_returns_tenant_customer ON customer_returns(tenant_id, customer_id);
+CREATE INDEX idx_customer_returns_tenant_status ON customer_returns(tenant_id, status);
+
+-- Customer Payments
+CREATE INDEX idx_customer_payments_tenant_customer ON customer_payments(tenant_id, customer_id, payment_date);
+
+-- Sales Orders
+CREATE INDEX idx_sales_orders_tenant_status ON sales_orders(tenant_id, status);
+CREATE INDEX idx_sales_orders_tenant_customer ON sales_orders(tenant_id, customer_id);
+CREATE INDEX idx_sales_order_items_tenant_order ON sales_order_items(tenant_id, order_id);
+CREATE INDEX idx_sales_order_items_product ON sales_order_items(product_id);
+
+-- Quotations
+CREATE INDEX idx_quotations_tenant_status ON quotations(tenant_id, status, valid_until);
+CREATE INDEX idx_quotations_tenant_customer ON quotations(tenant_id, customer_id);
+CREATE INDEX idx_quotation_items_tenant_quotation ON quotation_items(tenant_id, quotation_id);
+
+-- Delivery Notes
+CREATE INDEX idx_delivery_notes_tenant_customer_status ON delivery_notes(tenant_id, customer_id, status);
+CREATE INDEX idx_delivery_notes_tenant_invoice ON delivery_notes(tenant_id, invoice_id);
+CREATE INDEX idx_delivery_note_items_tenant_note ON delivery_note_items(tenant_id, delivery_note_id);
+
+-- Purchasing
+CREATE INDEX idx_suppliers_tenant_name ON suppliers(tenant_id, name);
+CREATE INDEX idx_purchase_orders_tenant_status ON purchase_orders(tenant_id, status);
+CREATE INDEX idx_purchase_order_items_tenant_product ON purchase_order_items(tenant_id, product_id);
+CREATE INDEX idx_purchase_order_items_tenant_order ON purchase_order_items(tenant_id, order_id);
+CREATE INDEX idx_goods_receipt_items_tenant_order_item ON goods_receipt_items(tenant_id, order_item_id);
+CREATE INDEX idx_purchase_invoice_items_tenant_order_item ON purchase_invoice_items(tenant_id, order_item_id);
+
+-- Assembly
+CREATE INDEX idx_bom_components_component ON bom_components(component_id);
+CREATE INDEX idx_assembly_orders_tenant_status ON assembly_orders(tenant_id, status);
+CREATE INDEX idx_assembly_order_components_tenant_order ON assembly_order_components(tenant_id, order_id);
+
+-- e-Invoices
+CREATE INDEX idx_e_invoices_tenant_status ON e_invoices(tenant_id, status);
 
 -- Invoice Items
 CREATE INDEX idx_invoice_items_tenant_invoice ON invoice_items(tenant_id, invoice_id);
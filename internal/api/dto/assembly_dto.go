@@ -0,0 +1,76 @@
+package dto
+
+import (
+	"time"
+
+	"sancaksoft/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// SetBOMRequestDTO replaces a product's bill of materials: the components consumed to make
+// output_quantity units (default 1), e.g. 1 sack for 25 bags.
+type SetBOMRequestDTO struct {
+	OutputQuantity int               `json:"output_quantity"`
+	Note           string            `json:"note"`
+	Components     []BOMComponentDTO `json:"components" validate:"required,min=1,dive"`
+}
+
+type BOMComponentDTO struct {
+	ComponentID   uuid.UUID `json:"component_id" validate:"required"`
+	ComponentName string    `json:"component_name"`
+	SKU           string    `json:"sku"`
+	Quantity      int       `json:"quantity" validate:"required,min=1"`
+}
+
+type BOMResponseDTO struct {
+	ProductID      uuid.UUID         `json:"product_id"`
+	OutputQuantity int               `json:"output_quantity"`
+	Note           string            `json:"note"`
+	UpdatedAt      time.Time         `json:"updated_at"`
+	Components     []BOMComponentDTO `json:"components"`
+}
+
+type CreateAssemblyOrderRequestDTO struct {
+	ProductID   uuid.UUID `json:"product_id" validate:"required"`
+	WarehouseID uuid.UUID `json:"warehouse_id" validate:"required"`
+	Quantity    int       `json:"quantity" validate:"required,min=1"`
+	Note        string    `json:"note"`
+}
+
+// CompleteAssemblyOrderRequestDTO records the outcome of an order. produced_qty + scrap_qty
+// must equal the planned quantity; lot_number and expiry_date (YYYY-MM-DD) are for
+// lot-tracked products.
+type CompleteAssemblyOrderRequestDTO struct {
+	ProducedQty int    `json:"produced_qty" validate:"required,min=1"`
+	ScrapQty    int    `json:"scrap_qty"`
+	LotNumber   string `json:"lot_number"`
+	ExpiryDate  string `json:"expiry_date"`
+}
+
+type AssemblyOrderResponseDTO struct {
+	ID          uuid.UUID                   `json:"id"`
+	OrderNumber string                      `json:"order_number"`
+	ProductID   uuid.UUID                   `json:"product_id"`
+	WarehouseID uuid.UUID                   `json:"warehouse_id"`
+	Status      domain.AssemblyOrderStatus  `json:"status"`
+	Quantity    int                         `json:"quantity"`
+	ProducedQty int                         `json:"produced_qty"`
+	ScrapQty    int                         `json:"scrap_qty"`
+	YieldPct    *decimal.Decimal            `json:"yield_pct"` // Set once completed
+	LotID       *uuid.UUID                  `json:"lot_id"`
+	TotalCost   decimal.Decimal             `json:"total_cost"`
+	UnitCost    decimal.Decimal             `json:"unit_cost"`
+	Note        string                      `json:"note"`
+	CompletedAt *time.Time                  `json:"completed_at"`
+	CreatedAt   time.Time                   `json:"created_at"`
+	UpdatedAt   time.Time                   `json:"updated_at"`
+	Components  []AssemblyOrderComponentDTO `json:"components,omitempty"`
+}
+
+type AssemblyOrderComponentDTO struct {
+	ProductID uuid.UUID       `json:"product_id"`
+	Quantity  int             `json:"quantity"`
+	UnitCost  decimal.Decimal `json:"unit_cost"`
+}
@@ -0,0 +1,63 @@
+package dto
+
+import (
+	"time"
+
+	"sancaksoft/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// CategoryRequestDTO creates or updates a category; a nil ParentID is the top level.
+type CategoryRequestDTO struct {
+	Name     string     `json:"name" validate:"required"`
+	ParentID *uuid.UUID `json:"parent_id"`
+}
+
+type CategoryResponseDTO struct {
+	ID        uuid.UUID  `json:"id"`
+	ParentID  *uuid.UUID `json:"parent_id"`
+	Name      string     `json:"name"`
+	Path      string     `json:"path"`
+	Depth     int        `json:"depth"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+type CreateBrandRequestDTO struct {
+	Name string `json:"name" validate:"required"`
+}
+
+type BrandResponseDTO struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CreateAttributeRequestDTO defines an attribute; Options are required for OPTION attributes.
+type CreateAttributeRequestDTO struct {
+	Code     string               `json:"code" validate:"required"`
+	Name     string               `json:"name" validate:"required"`
+	DataType domain.AttributeType `json:"data_type" validate:"required"`
+	Options  []string             `json:"options"`
+}
+
+type AttributeResponseDTO struct {
+	ID         uuid.UUID            `json:"id"`
+	CategoryID uuid.UUID            `json:"category_id"`
+	Code       string               `json:"code"`
+	Name       string               `json:"name"`
+	DataType   domain.AttributeType `json:"data_type"`
+	Options    []string             `json:"options"`
+}
+
+// ProductClassificationRequestDTO sets a product's category and brand; null clears them.
+type ProductClassificationRequestDTO struct {
+	CategoryID *uuid.UUID `json:"category_id"`
+	BrandID    *uuid.UUID `json:"brand_id"`
+}
+
+// ProductAttributesRequestDTO replaces a product's attribute values, keyed by attribute code.
+type ProductAttributesRequestDTO struct {
+	Attributes map[string]string `json:"attributes"`
+}
@@ -0,0 +1,78 @@
+package dto
+
+import (
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+type CostingMethodDTO struct {
+	CostingMethod string `json:"costing_method" validate:"required,oneof=AVERAGE FIFO"`
+}
+
+// RecalculateCostsRequestDTO re-costs one product, or every product when product_id is omitted.
+type RecalculateCostsRequestDTO struct {
+	ProductID *uuid.UUID `json:"product_id"`
+}
+
+type StockValuationLineDTO struct {
+	ProductID    uuid.UUID       `json:"product_id"`
+	ProductName  string          `json:"product_name"`
+	SKU          string          `json:"sku"`
+	CategoryID   *uuid.UUID      `json:"category_id"`
+	CategoryName *string         `json:"category_name"`
+	BrandID      *uuid.UUID      `json:"brand_id"`
+	BrandName    *string         `json:"brand_name"`
+	Quantity     int             `json:"quantity"`
+	UnitCost     decimal.Decimal `json:"unit_cost"`
+	Value        decimal.Decimal `json:"value"`
+}
+
+type StockValuationGroupDTO struct {
+	Key      string          `json:"key"`
+	Label    string          `json:"label"`
+	Quantity int             `json:"quantity"`
+	Value    decimal.Decimal `json:"value"`
+}
+
+// StockValuationResponseDTO carries Groups when the valuation is grouped by category or brand.
+type StockValuationResponseDTO struct {
+	CostingMethod string                   `json:"costing_method"`
+	TotalValue    decimal.Decimal          `json:"total_value"`
+	GroupBy       string                   `json:"group_by,omitempty"`
+	Groups        []StockValuationGroupDTO `json:"groups,omitempty"`
+	Lines         []StockValuationLineDTO  `json:"lines"`
+}
+
+type CostOfGoodsLineDTO struct {
+	ProductID   uuid.UUID       `json:"product_id"`
+	ProductName string          `json:"product_name"`
+	Quantity    int             `json:"quantity"`
+	Cost        decimal.Decimal `json:"cost"`
+}
+
+type CostOfGoodsResponseDTO struct {
+	TotalCost decimal.Decimal      `json:"total_cost"`
+	Lines     []CostOfGoodsLineDTO `json:"lines"`
+}
+
+type InvoiceLineProfitDTO struct {
+	ItemID      uuid.UUID       `json:"item_id"`
+	ProductID   uuid.UUID       `json:"product_id"`
+	ProductName string          `json:"product_name"`
+	Quantity    int             `json:"quantity"`
+	Revenue     decimal.Decimal `json:"revenue"`
+	UnitCost    decimal.Decimal `json:"unit_cost"`
+	Cost        decimal.Decimal `json:"cost"`
+	GrossProfit decimal.Decimal `json:"gross_profit"`
+	MarginPct   decimal.Decimal `json:"margin_pct"`
+}
+
+type InvoiceProfitResponseDTO struct {
+	InvoiceID     uuid.UUID              `json:"invoice_id"`
+	InvoiceNumber string                 `json:"invoice_number"`
+	Revenue       decimal.Decimal        `json:"revenue"`
+	Cost          decimal.Decimal        `json:"cost"`
+	GrossProfit   decimal.Decimal        `json:"gross_profit"`
+	MarginPct     decimal.Decimal        `json:"margin_pct"`
+	Lines         []InvoiceLineProfitDTO `json:"lines"`
+}
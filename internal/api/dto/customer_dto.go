@@ -8,25 +8,98 @@ import (
 )
 
 type CreateCustomerRequestDTO struct {
-	Name    string `json:"name" validate:"required"`
-	Email   string `json:"email" validate:"email"`
-	Phone   string `json:"phone"`
-	Address string `json:"address"`
+	Name            string           `json:"name" validate:"required"`
+	Email           string           `json:"email" validate:"email"`
+	Phone           string           `json:"phone"`
+	Address         string           `json:"address"`
+	District        string           `json:"district"`
+	City            string           `json:"city"`
+	TaxNumber       string           `json:"tax_number"` // VKN (10 digits) or TCKN (11 digits)
+	TaxOffice       string           `json:"tax_office"`
+	CreditLimit     *decimal.Decimal `json:"credit_limit"`      // Omit for no limit
+	PaymentTermDays int              `json:"payment_term_days"` // 0: due on issue
 }
 
 type CustomerResponseDTO struct {
-	ID        uuid.UUID `json:"id"`
-	Name      string    `json:"name"`
-	Email     string    `json:"email"`
-	Phone     string    `json:"phone"`
-	Address   string    `json:"address"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID              uuid.UUID        `json:"id"`
+	Name            string           `json:"name"`
+	Email           string           `json:"email"`
+	Phone           string           `json:"phone"`
+	Address         string           `json:"address"`
+	District        string           `json:"district"`
+	City            string           `json:"city"`
+	TaxNumber       string           `json:"tax_number"`
+	TaxOffice       string           `json:"tax_office"`
+	CreditLimit     *decimal.Decimal `json:"credit_limit"`
+	PaymentTermDays int              `json:"payment_term_days"`
+	CreatedAt       time.Time        `json:"created_at"`
+	UpdatedAt       time.Time        `json:"updated_at"`
+}
+
+// SetCreditTermsRequestDTO replaces a customer's credit terms; a null limit removes it.
+type SetCreditTermsRequestDTO struct {
+	CreditLimit     *decimal.Decimal `json:"credit_limit"`
+	PaymentTermDays int              `json:"payment_term_days" validate:"min=0"`
+}
+
+type CustomerCreditResponseDTO struct {
+	CustomerID      uuid.UUID        `json:"customer_id"`
+	AsOf            time.Time        `json:"as_of"`
+	CreditLimit     *decimal.Decimal `json:"credit_limit"`
+	PaymentTermDays int              `json:"payment_term_days"`
+	OpenBalance     decimal.Decimal  `json:"open_balance"`
+	OverdueAmount   decimal.Decimal  `json:"overdue_amount"`
+	Available       *decimal.Decimal `json:"available"` // Null without a limit
+}
+
+type CreateCustomerPaymentRequestDTO struct {
+	Amount      decimal.Decimal `json:"amount" validate:"required"`
+	PaymentDate string          `json:"payment_date"` // YYYY-MM-DD, defaults to today
+	Method      string          `json:"method" validate:"required,oneof=CASH BANK_TRANSFER CARD CHEQUE"`
+	Reference   string          `json:"reference"` // Bank reference, cheque number, ...
+	Note        string          `json:"note"`
+}
+
+type CustomerPaymentResponseDTO struct {
+	ID          uuid.UUID       `json:"id"`
+	CustomerID  uuid.UUID       `json:"customer_id"`
+	Amount      decimal.Decimal `json:"amount"`
+	PaymentDate time.Time       `json:"payment_date"`
+	Method      string          `json:"method"`
+	Reference   string          `json:"reference"`
+	Note        string          `json:"note"`
+	CreatedBy   *uuid.UUID      `json:"created_by"`
+	CreatedAt   time.Time       `json:"created_at"`
 }
 
 type CustomerLedgerEntryDTO struct {
-	PeriodStart  time.Time       `json:"period_start"`
-	SalesAmount  decimal.Decimal `json:"sales_amount"`
-	ReturnAmount decimal.Decimal `json:"return_amount"`
-	NetAmount    decimal.Decimal `json:"net_amount"`
+	PeriodStart   time.Time       `json:"period_start"`
+	SalesAmount   decimal.Decimal `json:"sales_amount"`
+	ReturnAmount  decimal.Decimal `json:"return_amount"`
+	PaymentAmount decimal.Decimal `json:"payment_amount"`
+	NetAmount     decimal.Decimal `json:"net_amount"`
+}
+
+type StatementLineDTO struct {
+	Date           time.Time       `json:"date"`
+	Type           string          `json:"type"` // SALE, RETURN, PAYMENT
+	DocumentID     uuid.UUID       `json:"document_id"`
+	DocumentNumber string          `json:"document_number"`
+	Description    string          `json:"description"`
+	DueDate        *time.Time      `json:"due_date"`
+	Debit          decimal.Decimal `json:"debit"`
+	Credit         decimal.Decimal `json:"credit"`
+	Balance        decimal.Decimal `json:"balance"`
+}
+
+type CustomerStatementResponseDTO struct {
+	Company        CompanyProfileResponseDTO `json:"company"`
+	Customer       CustomerResponseDTO       `json:"customer"`
+	From           *time.Time                `json:"from"`
+	To             *time.Time                `json:"to"` // Exclusive
+	OpeningBalance decimal.Decimal           `json:"opening_balance"`
+	Lines          []StatementLineDTO        `json:"lines"`
+	TotalDebit     decimal.Decimal           `json:"total_debit"`
+	TotalCredit    decimal.Decimal           `json:"total_credit"`
+	ClosingBalance decimal.Decimal           `json:"closing_balance"`
 }
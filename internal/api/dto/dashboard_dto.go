@@ -9,9 +9,13 @@ import (
 
 type DashboardStatsDTO struct {
 	TotalRevenue   decimal.Decimal    `json:"total_revenue"`
+	NetRevenue     decimal.Decimal    `json:"net_revenue"` // Invoiced amount excluding VAT
+	TotalCost      decimal.Decimal    `json:"total_cost"`  // Cost captured on invoice lines at sale time
+	GrossProfit    decimal.Decimal    `json:"gross_profit"`
+	GrossMarginPct decimal.Decimal    `json:"gross_margin_pct"`
 	TotalInvoices  int64              `json:"total_invoices"`
 	TotalProducts  int64              `json:"total_products"`
-	LowStockCount  int64              `json:"low_stock_count"` // Products with stock < 10 (example threshold)
+	LowStockCount  int64              `json:"low_stock_count"` // Stock levels at or below their reorder level
 	RecentInvoices []RecentInvoiceDTO `json:"recent_invoices"`
 }
 
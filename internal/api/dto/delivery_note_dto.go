@@ -0,0 +1,72 @@
+package dto
+
+import (
+	"time"
+
+	"sancaksoft/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+type CreateDeliveryNoteRequestDTO struct {
+	CustomerID  uuid.UUID             `json:"customer_id" validate:"required"`
+	WarehouseID uuid.UUID             `json:"warehouse_id" validate:"required"`
+	Note        string                `json:"note"`
+	Items       []DeliveryNoteItemDTO `json:"items" validate:"required,min=1,dive"`
+	// CreditOverrideReason lets a manager ship to a customer on credit hold (over its limit
+	// or with overdue invoices); recorded in the audit log.
+	CreditOverrideReason string `json:"credit_override_reason"`
+}
+
+type DeliveryNoteItemDTO struct {
+	ProductID     uuid.UUID       `json:"product_id" validate:"required"`
+	Quantity      int             `json:"quantity" validate:"required,min=1"`
+	UnitPrice     decimal.Decimal `json:"unit_price" validate:"required"`
+	LotNumber     string          `json:"lot_number"`     // Optional for lot-tracked products; FEFO allocation when empty
+	SerialNumbers []string        `json:"serial_numbers"` // Required for serialized products, one per unit
+}
+
+// InvoiceDeliveryNotesRequestDTO bills several shipped delivery notes of one customer on one invoice.
+type InvoiceDeliveryNotesRequestDTO struct {
+	CustomerID      uuid.UUID   `json:"customer_id" validate:"required"`
+	DeliveryNoteIDs []uuid.UUID `json:"delivery_note_ids" validate:"required,min=1"`
+	IdempotencyKey  uuid.UUID   `json:"idempotency_key" validate:"required"`
+}
+
+type DeliveryNoteResponseDTO struct {
+	ID          uuid.UUID                     `json:"id"`
+	NoteNumber  string                        `json:"note_number"`
+	CustomerID  uuid.UUID                     `json:"customer_id"`
+	WarehouseID uuid.UUID                     `json:"warehouse_id"`
+	Status      domain.DeliveryNoteStatus     `json:"status"`
+	Note        string                        `json:"note"`
+	TotalAmount decimal.Decimal               `json:"total_amount"`
+	InvoiceID   *uuid.UUID                    `json:"invoice_id"`
+	ShippedAt   time.Time                     `json:"shipped_at"`
+	CreatedAt   time.Time                     `json:"created_at"`
+	UpdatedAt   time.Time                     `json:"updated_at"`
+	Items       []DeliveryNoteItemResponseDTO `json:"items,omitempty"`
+}
+
+type DeliveryNoteItemResponseDTO struct {
+	ID        uuid.UUID       `json:"id"`
+	ProductID uuid.UUID       `json:"product_id"`
+	Quantity  int             `json:"quantity"`
+	UnitPrice decimal.Decimal `json:"unit_price"`
+	Total     decimal.Decimal `json:"total"`
+}
+
+type UninvoicedDeliveryLineDTO struct {
+	DeliveryNoteID uuid.UUID       `json:"delivery_note_id"`
+	NoteNumber     string          `json:"note_number"`
+	ShippedAt      time.Time       `json:"shipped_at"`
+	CustomerID     uuid.UUID       `json:"customer_id"`
+	CustomerName   string          `json:"customer_name"`
+	WarehouseID    uuid.UUID       `json:"warehouse_id"`
+	ProductID      uuid.UUID       `json:"product_id"`
+	ProductName    string          `json:"product_name"`
+	Quantity       int             `json:"quantity"`
+	UnitPrice      decimal.Decimal `json:"unit_price"`
+	Total          decimal.Decimal `json:"total"`
+}
@@ -0,0 +1,27 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// GenerateEInvoiceRequestDTO generates the UBL-TR document of an invoice. Without a
+// profile it is chosen by the customer's e-Fatura registration.
+type GenerateEInvoiceRequestDTO struct {
+	Profile string `json:"profile"` // TEMELFATURA, TICARIFATURA or EARSIVFATURA
+}
+
+type EInvoiceResponseDTO struct {
+	ID                uuid.UUID  `json:"id"`
+	InvoiceID         uuid.UUID  `json:"invoice_id"`
+	ETTN              uuid.UUID  `json:"ettn"`
+	Profile           string     `json:"profile"`
+	DocumentNumber    string     `json:"document_number"`
+	Status            string     `json:"status"`
+	ProviderReference string     `json:"provider_reference"`
+	StatusMessage     string     `json:"status_message"`
+	SentAt            *time.Time `json:"sent_at"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+}
@@ -14,12 +14,17 @@ type CreateInvoiceRequestDTO struct {
 	WarehouseID    uuid.UUID        `json:"warehouse_id" validate:"required"`
 	Items          []InvoiceItemDTO `json:"items" validate:"required,min=1,dive"`
 	IdempotencyKey uuid.UUID        `json:"idempotency_key" validate:"required"`
+	// CreditOverrideReason lets a manager invoice a customer on credit hold (over its limit
+	// or with overdue invoices); the override is audited.
+	CreditOverrideReason string `json:"credit_override_reason"`
 }
 
 type InvoiceItemDTO struct {
-	ProductID uuid.UUID       `json:"product_id" validate:"required"`
-	Quantity  int             `json:"quantity" validate:"required,min=1"`
-	UnitPrice decimal.Decimal `json:"unit_price" validate:"required"` // In real implementation, price might be fetched from DB
+	ProductID     uuid.UUID       `json:"product_id" validate:"required"`
+	Quantity      int             `json:"quantity" validate:"required,min=1"`
+	UnitPrice     decimal.Decimal `json:"unit_price" validate:"required"` // In real implementation, price might be fetched from DB
+	LotNumber     string          `json:"lot_number"`                     // Optional for lot-tracked products; FEFO allocation when empty
+	SerialNumbers []string        `json:"serial_numbers"`                 // Required for serialized products, one per unit
 }
 
 // InvoiceResponseDTO represents the outgoing JSON structure.
@@ -27,6 +32,7 @@ type InvoiceResponseDTO struct {
 	ID            uuid.UUID       `json:"id"`
 	InvoiceNumber string          `json:"invoice_number"`
 	TotalAmount   decimal.Decimal `json:"total_amount"`
+	DueDate       time.Time       `json:"due_date"`
 	CreatedAt     time.Time       `json:"created_at"`
 	Status        string          `json:"status"` // e.g., "created"
 }
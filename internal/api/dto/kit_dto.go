@@ -0,0 +1,35 @@
+package dto
+
+import (
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+type KitComponentDTO struct {
+	ComponentID   uuid.UUID       `json:"component_id" validate:"required"`
+	ComponentName string          `json:"component_name"`
+	SKU           string          `json:"sku"`
+	Price         decimal.Decimal `json:"price"`
+	Quantity      int             `json:"quantity" validate:"required,gt=0"`
+}
+
+// SetKitComponentsRequestDTO replaces a kit's components, e.g.
+// [{"component_id": "...", "quantity": 2}]. An empty list clears the kit.
+type SetKitComponentsRequestDTO struct {
+	Components []KitComponentDTO `json:"components"`
+}
+
+type KitComponentAvailabilityDTO struct {
+	ComponentID    uuid.UUID `json:"component_id"`
+	ComponentName  string    `json:"component_name"`
+	QuantityPerKit int       `json:"quantity_per_kit"`
+	Available      int       `json:"available"`
+	KitsPossible   int       `json:"kits_possible"`
+}
+
+type KitAvailabilityDTO struct {
+	KitID       uuid.UUID                     `json:"kit_id"`
+	WarehouseID uuid.UUID                     `json:"warehouse_id"`
+	Available   int                           `json:"available"`
+	Components  []KitComponentAvailabilityDTO `json:"components"`
+}
@@ -0,0 +1,88 @@
+package dto
+
+import (
+	"time"
+
+	"sancaksoft/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// CreateLocationRequestDTO adds a location; Code is one path segment (e.g. "A", "01").
+type CreateLocationRequestDTO struct {
+	Code     string     `json:"code" validate:"required"`
+	ParentID *uuid.UUID `json:"parent_id"`
+}
+
+type UpdateLocationRequestDTO struct {
+	IsActive bool `json:"is_active"`
+}
+
+type LocationResponseDTO struct {
+	ID          uuid.UUID  `json:"id"`
+	WarehouseID uuid.UUID  `json:"warehouse_id"`
+	ParentID    *uuid.UUID `json:"parent_id"`
+	Code        string     `json:"code"`
+	Path        string     `json:"path"`
+	IsActive    bool       `json:"is_active"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// LocationBalanceDTO is stock of a product in one location; a null location is unlocated stock.
+type LocationBalanceDTO struct {
+	LocationID   *uuid.UUID `json:"location_id"`
+	LocationPath *string    `json:"location_path"`
+	ProductID    uuid.UUID  `json:"product_id"`
+	ProductName  string     `json:"product_name"`
+	SKU          string     `json:"sku"`
+	Quantity     int        `json:"quantity"`
+}
+
+// LocationTransferRequestDTO moves stock inside a warehouse; leave one side empty to move
+// from or to unlocated stock.
+type LocationTransferRequestDTO struct {
+	ProductID      uuid.UUID  `json:"product_id" validate:"required"`
+	FromLocationID *uuid.UUID `json:"from_location_id"`
+	ToLocationID   *uuid.UUID `json:"to_location_id"`
+	Quantity       int        `json:"quantity" validate:"required,gt=0"`
+}
+
+type LocationTransferResponseDTO struct {
+	ID             uuid.UUID  `json:"id"`
+	WarehouseID    uuid.UUID  `json:"warehouse_id"`
+	ProductID      uuid.UUID  `json:"product_id"`
+	FromLocationID *uuid.UUID `json:"from_location_id"`
+	ToLocationID   *uuid.UUID `json:"to_location_id"`
+	Quantity       int        `json:"quantity"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// PutawaySuggestionDTO proposes a location for the open quantity of a purchase order line.
+// Location fields are null when the warehouse has no suitable location.
+type PutawaySuggestionDTO struct {
+	OrderItemID  uuid.UUID            `json:"order_item_id"`
+	ProductID    uuid.UUID            `json:"product_id"`
+	Quantity     int                  `json:"quantity"`
+	LocationID   *uuid.UUID           `json:"location_id"`
+	LocationPath *string              `json:"location_path"`
+	Reason       domain.PutawayReason `json:"reason,omitempty"`
+}
+
+type PickListLineDTO struct {
+	LocationID   *uuid.UUID `json:"location_id"`
+	LocationPath *string    `json:"location_path"`
+	ProductID    uuid.UUID  `json:"product_id"`
+	ProductName  string     `json:"product_name"`
+	SKU          string     `json:"sku"`
+	LotNumber    *string    `json:"lot_number"`
+	Quantity     int        `json:"quantity"`
+}
+
+// PickListDTO lists what to take from which location, in location path order.
+type PickListDTO struct {
+	DocumentType   string            `json:"document_type"`
+	DocumentID     uuid.UUID         `json:"document_id"`
+	DocumentNumber string            `json:"document_number"`
+	WarehouseID    uuid.UUID         `json:"warehouse_id"`
+	Lines          []PickListLineDTO `json:"lines"`
+}
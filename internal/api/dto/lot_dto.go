@@ -0,0 +1,56 @@
+package dto
+
+import (
+	"time"
+
+	"sancaksoft/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// LotBalanceDTO is on-hand stock of one lot in one warehouse. ExpiryDate is YYYY-MM-DD.
+type LotBalanceDTO struct {
+	LotID         uuid.UUID `json:"lot_id"`
+	LotNumber     string    `json:"lot_number"`
+	ExpiryDate    *string   `json:"expiry_date"`
+	DaysToExpiry  *int      `json:"days_to_expiry"`
+	ProductID     uuid.UUID `json:"product_id"`
+	ProductName   string    `json:"product_name"`
+	SKU           string    `json:"sku"`
+	WarehouseID   uuid.UUID `json:"warehouse_id"`
+	WarehouseName string    `json:"warehouse_name"`
+	Quantity      int       `json:"quantity"`
+}
+
+type LotExpiryLineDTO struct {
+	LotBalanceDTO
+	Status domain.LotExpiryStatus `json:"status"`
+}
+
+type LotExpiryReportDTO struct {
+	Days          int                `json:"days"`
+	TotalQuantity int                `json:"total_quantity"`
+	Lines         []LotExpiryLineDTO `json:"lines"`
+}
+
+type LotShipmentDTO struct {
+	CustomerID     uuid.UUID `json:"customer_id"`
+	CustomerName   string    `json:"customer_name"`
+	DocumentType   string    `json:"document_type"`
+	DocumentID     uuid.UUID `json:"document_id"`
+	DocumentNumber string    `json:"document_number"`
+	WarehouseID    uuid.UUID `json:"warehouse_id"`
+	Quantity       int       `json:"quantity"`
+	ShippedAt      time.Time `json:"shipped_at"`
+}
+
+type LotTraceDTO struct {
+	LotID        uuid.UUID        `json:"lot_id"`
+	LotNumber    string           `json:"lot_number"`
+	ExpiryDate   *string          `json:"expiry_date"`
+	ProductID    uuid.UUID        `json:"product_id"`
+	ProductName  string           `json:"product_name"`
+	SKU          string           `json:"sku"`
+	TotalShipped int              `json:"total_shipped"`
+	Shipments    []LotShipmentDTO `json:"shipments"`
+}
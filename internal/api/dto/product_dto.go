@@ -9,22 +9,50 @@ import (
 )
 
 type CreateProductRequestDTO struct {
-	Name    string          `json:"name" validate:"required"`
-	SKU     string          `json:"sku" validate:"required"`
-	Barcode string          `json:"barcode"`
-	Unit    domain.ProductUnit `json:"unit"`
-	Price   decimal.Decimal `json:"price" validate:"required,min=0"`
-	VATRate decimal.Decimal `json:"vat_rate" validate:"required,min=0"`
+	Name         string             `json:"name" validate:"required"`
+	SKU          string             `json:"sku" validate:"required"`
+	Barcode      string             `json:"barcode"`
+	Unit         domain.ProductUnit `json:"unit"`
+	Price        decimal.Decimal    `json:"price" validate:"required,min=0"`
+	VATRate      decimal.Decimal    `json:"vat_rate" validate:"required,min=0"`
+	StandardCost *decimal.Decimal   `json:"standard_cost"`
+	TrackLots    bool               `json:"track_lots"`
+	TrackSerials bool               `json:"track_serials"`
+	CategoryID   *uuid.UUID         `json:"category_id"`
+	BrandID      *uuid.UUID         `json:"brand_id"`
+}
+
+// StandardCostRequestDTO sets (or clears with null) a product's standard cost.
+type StandardCostRequestDTO struct {
+	StandardCost *decimal.Decimal `json:"standard_cost"`
+}
+
+// LotTrackingRequestDTO turns lot tracking of a product on or off.
+type LotTrackingRequestDTO struct {
+	TrackLots bool `json:"track_lots"`
+}
+
+// SerialTrackingRequestDTO turns serial number tracking of a product on or off.
+type SerialTrackingRequestDTO struct {
+	TrackSerials bool `json:"track_serials"`
 }
 
 type ProductResponseDTO struct {
-	ID        uuid.UUID       `json:"id"`
-	Name      string          `json:"name"`
-	SKU       string          `json:"sku"`
-	Barcode   string          `json:"barcode"`
-	Unit      domain.ProductUnit `json:"unit"`
-	Price     decimal.Decimal `json:"price"`
-	VATRate   decimal.Decimal `json:"vat_rate"`
-	CreatedAt time.Time       `json:"created_at"`
-	UpdatedAt time.Time       `json:"updated_at"`
+	ID           uuid.UUID          `json:"id"`
+	Name         string             `json:"name"`
+	SKU          string             `json:"sku"`
+	Barcode      string             `json:"barcode"`
+	Unit         domain.ProductUnit `json:"unit"`
+	Price        decimal.Decimal    `json:"price"`
+	VATRate      decimal.Decimal    `json:"vat_rate"`
+	StandardCost *decimal.Decimal   `json:"standard_cost"`
+	TrackLots    bool               `json:"track_lots"`
+	TrackSerials bool               `json:"track_serials"`
+	CategoryID   *uuid.UUID         `json:"category_id"`
+	BrandID      *uuid.UUID         `json:"brand_id"`
+	Attributes   map[string]string  `json:"attributes"`
+	ParentID     *uuid.UUID         `json:"parent_id"`
+	VariantKey   string             `json:"variant_key"`
+	CreatedAt    time.Time          `json:"created_at"`
+	UpdatedAt    time.Time          `json:"updated_at"`
 }
@@ -0,0 +1,146 @@
+package dto
+
+import (
+	"time"
+
+	"sancaksoft/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// CreatePurchaseOrderRequestDTO creates a DRAFT purchase order. ExpectedDate is YYYY-MM-DD.
+type CreatePurchaseOrderRequestDTO struct {
+	SupplierID   uuid.UUID              `json:"supplier_id" validate:"required"`
+	WarehouseID  uuid.UUID              `json:"warehouse_id" validate:"required"`
+	ExpectedDate string                 `json:"expected_date"`
+	Note         string                 `json:"note"`
+	Items        []PurchaseOrderItemDTO `json:"items" validate:"required,min=1,dive"`
+}
+
+type PurchaseOrderItemDTO struct {
+	ProductID uuid.UUID       `json:"product_id" validate:"required"`
+	Quantity  int             `json:"quantity" validate:"required,min=1"`
+	UnitPrice decimal.Decimal `json:"unit_price" validate:"required"`
+}
+
+// GoodsReceiptRequestDTO receives goods against a purchase order. WarehouseID defaults to the order's warehouse.
+type GoodsReceiptRequestDTO struct {
+	WarehouseID *uuid.UUID             `json:"warehouse_id"`
+	Note        string                 `json:"note"`
+	Lines       []PurchaseOrderLineDTO `json:"lines" validate:"required,min=1,dive"`
+}
+
+// PurchaseInvoiceRequestDTO records the supplier's invoice. InvoiceDate is YYYY-MM-DD.
+type PurchaseInvoiceRequestDTO struct {
+	InvoiceNumber string                 `json:"invoice_number" validate:"required"`
+	InvoiceDate   string                 `json:"invoice_date"`
+	Lines         []PurchaseOrderLineDTO `json:"lines" validate:"required,min=1,dive"`
+}
+
+// PurchaseOrderLineDTO refers to a purchase order line; UnitPrice is only read on supplier invoices,
+// LotNumber, ExpiryDate (YYYY-MM-DD), SerialNumbers and LocationID only on goods receipts.
+type PurchaseOrderLineDTO struct {
+	OrderItemID   uuid.UUID       `json:"order_item_id" validate:"required"`
+	Quantity      int             `json:"quantity" validate:"required,min=1"`
+	UnitPrice     decimal.Decimal `json:"unit_price"`
+	LotNumber     string          `json:"lot_number"`
+	ExpiryDate    string          `json:"expiry_date"`
+	SerialNumbers []string        `json:"serial_numbers"`
+	LocationID    *uuid.UUID      `json:"location_id"`
+}
+
+type PurchaseOrderResponseDTO struct {
+	ID           uuid.UUID                      `json:"id"`
+	OrderNumber  string                         `json:"order_number"`
+	SupplierID   uuid.UUID                      `json:"supplier_id"`
+	WarehouseID  uuid.UUID                      `json:"warehouse_id"`
+	Status       domain.PurchaseOrderStatus     `json:"status"`
+	ExpectedDate *string                        `json:"expected_date"`
+	Note         string                         `json:"note"`
+	TotalAmount  decimal.Decimal                `json:"total_amount"`
+	OrderedAt    *time.Time                     `json:"ordered_at"`
+	ClosedAt     *time.Time                     `json:"closed_at"`
+	CreatedAt    time.Time                      `json:"created_at"`
+	UpdatedAt    time.Time                      `json:"updated_at"`
+	Items        []PurchaseOrderItemResponseDTO `json:"items,omitempty"`
+}
+
+type PurchaseOrderItemResponseDTO struct {
+	ID           uuid.UUID       `json:"id"`
+	ProductID    uuid.UUID       `json:"product_id"`
+	Quantity     int             `json:"quantity"`
+	ReceivedQty  int             `json:"received_qty"`
+	RemainingQty int             `json:"remaining_qty"`
+	UnitPrice    decimal.Decimal `json:"unit_price"`
+	Total        decimal.Decimal `json:"total"`
+}
+
+type GoodsReceiptResponseDTO struct {
+	ID            uuid.UUID                     `json:"id"`
+	OrderID       uuid.UUID                     `json:"order_id"`
+	WarehouseID   uuid.UUID                     `json:"warehouse_id"`
+	ReceiptNumber string                        `json:"receipt_number"`
+	Note          string                        `json:"note"`
+	ReceivedAt    time.Time                     `json:"received_at"`
+	Items         []GoodsReceiptItemResponseDTO `json:"items"`
+}
+
+type GoodsReceiptItemResponseDTO struct {
+	ID          uuid.UUID       `json:"id"`
+	OrderItemID uuid.UUID       `json:"order_item_id"`
+	ProductID   uuid.UUID       `json:"product_id"`
+	Quantity    int             `json:"quantity"`
+	UnitCost    decimal.Decimal `json:"unit_cost"`
+}
+
+type PurchaseInvoiceResponseDTO struct {
+	ID            uuid.UUID                        `json:"id"`
+	OrderID       uuid.UUID                        `json:"order_id"`
+	SupplierID    uuid.UUID                        `json:"supplier_id"`
+	InvoiceNumber string                           `json:"invoice_number"`
+	InvoiceDate   string                           `json:"invoice_date"`
+	TotalAmount   decimal.Decimal                  `json:"total_amount"`
+	CreatedAt     time.Time                        `json:"created_at"`
+	Items         []PurchaseInvoiceItemResponseDTO `json:"items"`
+}
+
+type PurchaseInvoiceItemResponseDTO struct {
+	ID          uuid.UUID       `json:"id"`
+	OrderItemID uuid.UUID       `json:"order_item_id"`
+	ProductID   uuid.UUID       `json:"product_id"`
+	Quantity    int             `json:"quantity"`
+	UnitPrice   decimal.Decimal `json:"unit_price"`
+	Total       decimal.Decimal `json:"total"`
+}
+
+type PurchaseMatchLineDTO struct {
+	OrderID          uuid.UUID       `json:"order_id"`
+	OrderNumber      string          `json:"order_number"`
+	OrderItemID      uuid.UUID       `json:"order_item_id"`
+	ProductID        uuid.UUID       `json:"product_id"`
+	ProductName      string          `json:"product_name"`
+	OrderedQty       int             `json:"ordered_qty"`
+	OrderedPrice     decimal.Decimal `json:"ordered_price"`
+	ReceivedQty      int             `json:"received_qty"`
+	InvoicedQty      int             `json:"invoiced_qty"`
+	InvoicedAmount   decimal.Decimal `json:"invoiced_amount"`
+	InvoicedMinPrice decimal.Decimal `json:"invoiced_min_price"`
+	InvoicedMaxPrice decimal.Decimal `json:"invoiced_max_price"`
+	Discrepancies    []string        `json:"discrepancies"`
+}
+
+type OpenPurchaseLineDTO struct {
+	OrderID      uuid.UUID       `json:"order_id"`
+	OrderNumber  string          `json:"order_number"`
+	SupplierID   uuid.UUID       `json:"supplier_id"`
+	SupplierName string          `json:"supplier_name"`
+	WarehouseID  uuid.UUID       `json:"warehouse_id"`
+	ProductID    uuid.UUID       `json:"product_id"`
+	ProductName  string          `json:"product_name"`
+	OrderedQty   int             `json:"ordered_qty"`
+	ReceivedQty  int             `json:"received_qty"`
+	RemainingQty int             `json:"remaining_qty"`
+	UnitPrice    decimal.Decimal `json:"unit_price"`
+	ExpectedDate *string         `json:"expected_date"`
+}
@@ -0,0 +1,56 @@
+package dto
+
+import (
+	"time"
+
+	"sancaksoft/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// QuotationRequestDTO is used both to create a quotation and to revise it.
+// ValidUntil is a date in YYYY-MM-DD format.
+type QuotationRequestDTO struct {
+	CustomerID  uuid.UUID          `json:"customer_id"`
+	WarehouseID uuid.UUID          `json:"warehouse_id"`
+	ValidUntil  string             `json:"valid_until" validate:"required"`
+	Note        string             `json:"note"`
+	Items       []QuotationItemDTO `json:"items" validate:"required,min=1,dive"`
+}
+
+type QuotationItemDTO struct {
+	ProductID uuid.UUID       `json:"product_id" validate:"required"`
+	Quantity  int             `json:"quantity" validate:"required,min=1"`
+	UnitPrice decimal.Decimal `json:"unit_price" validate:"required"`
+}
+
+type ConvertQuotationRequestDTO struct {
+	IdempotencyKey       uuid.UUID `json:"idempotency_key"`        // Required for invoice conversion
+	CreditOverrideReason string    `json:"credit_override_reason"` // Managers only; invoices a customer on credit hold
+}
+
+type QuotationResponseDTO struct {
+	ID           uuid.UUID                  `json:"id"`
+	QuoteNumber  string                     `json:"quote_number"`
+	Revision     int                        `json:"revision"`
+	CustomerID   uuid.UUID                  `json:"customer_id"`
+	WarehouseID  uuid.UUID                  `json:"warehouse_id"`
+	Status       domain.QuotationStatus     `json:"status"`
+	ValidUntil   string                     `json:"valid_until"`
+	Note         string                     `json:"note"`
+	TotalAmount  decimal.Decimal            `json:"total_amount"`
+	SalesOrderID *uuid.UUID                 `json:"sales_order_id"`
+	InvoiceID    *uuid.UUID                 `json:"invoice_id"`
+	CreatedAt    time.Time                  `json:"created_at"`
+	UpdatedAt    time.Time                  `json:"updated_at"`
+	Items        []QuotationItemResponseDTO `json:"items,omitempty"`
+}
+
+type QuotationItemResponseDTO struct {
+	ID        uuid.UUID       `json:"id"`
+	ProductID uuid.UUID       `json:"product_id"`
+	Quantity  int             `json:"quantity"`
+	UnitPrice decimal.Decimal `json:"unit_price"`
+	Total     decimal.Decimal `json:"total"`
+}
@@ -0,0 +1,70 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+type StockLevelRequestDTO struct {
+	ProductID    uuid.UUID  `json:"product_id" validate:"required"`
+	WarehouseID  uuid.UUID  `json:"warehouse_id" validate:"required"`
+	MinLevel     int        `json:"min_level" validate:"min=0"`
+	ReorderLevel int        `json:"reorder_level" validate:"min=0"`
+	MaxLevel     int        `json:"max_level" validate:"min=0"`
+	SafetyStock  int        `json:"safety_stock" validate:"min=0"`
+	LeadTimeDays int        `json:"lead_time_days" validate:"min=0"`
+	SupplierID   *uuid.UUID `json:"supplier_id"`
+}
+
+// StockLevelResponseDTO is a stock level with current figures. Severity is only set on the low-stock report.
+type StockLevelResponseDTO struct {
+	ID            uuid.UUID  `json:"id"`
+	ProductID     uuid.UUID  `json:"product_id"`
+	ProductName   string     `json:"product_name"`
+	WarehouseID   uuid.UUID  `json:"warehouse_id"`
+	WarehouseName string     `json:"warehouse_name"`
+	MinLevel      int        `json:"min_level"`
+	ReorderLevel  int        `json:"reorder_level"`
+	MaxLevel      int        `json:"max_level"`
+	SafetyStock   int        `json:"safety_stock"`
+	LeadTimeDays  int        `json:"lead_time_days"`
+	SupplierID    *uuid.UUID `json:"supplier_id"`
+	OnHand        int        `json:"on_hand"`
+	Reserved      int        `json:"reserved"`
+	Available     int        `json:"available"`
+	OnOrder       int        `json:"on_order"`
+	Severity      string     `json:"severity,omitempty"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// ReplenishmentOrdersRequestDTO turns replenishment suggestions into draft purchase orders.
+// Omit product_ids to order every suggestion.
+type ReplenishmentOrdersRequestDTO struct {
+	WarehouseID *uuid.UUID  `json:"warehouse_id"`
+	WindowDays  int         `json:"window_days"`
+	ProductIDs  []uuid.UUID `json:"product_ids"`
+}
+
+type ReplenishmentSuggestionDTO struct {
+	ProductID     uuid.UUID       `json:"product_id"`
+	ProductName   string          `json:"product_name"`
+	WarehouseID   uuid.UUID       `json:"warehouse_id"`
+	WarehouseName string          `json:"warehouse_name"`
+	SupplierID    *uuid.UUID      `json:"supplier_id"`
+	Available     int             `json:"available"`
+	OnOrder       int             `json:"on_order"`
+	AvgDailySales decimal.Decimal `json:"avg_daily_sales"`
+	LeadTimeDays  int             `json:"lead_time_days"`
+	SafetyStock   int             `json:"safety_stock"`
+	ReorderPoint  int             `json:"reorder_point"`
+	TargetLevel   int             `json:"target_level"`
+	SuggestedQty  int             `json:"suggested_qty"`
+	UnitPrice     decimal.Decimal `json:"unit_price"`
+}
+
+type ReplenishmentOrdersResponseDTO struct {
+	Orders  []PurchaseOrderResponseDTO   `json:"orders"`
+	Skipped []ReplenishmentSuggestionDTO `json:"skipped"`
+}
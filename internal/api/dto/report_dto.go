@@ -0,0 +1,58 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+type GrossProfitLineDTO struct {
+	Key         string          `json:"key"`
+	Label       string          `json:"label"`
+	Quantity    int             `json:"quantity"`
+	Revenue     decimal.Decimal `json:"revenue"`
+	Cost        decimal.Decimal `json:"cost"`
+	GrossProfit decimal.Decimal `json:"gross_profit"`
+	MarginPct   decimal.Decimal `json:"margin_pct"`
+}
+
+type GrossProfitReportDTO struct {
+	GroupBy string               `json:"group_by"`
+	Period  string               `json:"period,omitempty"`
+	Lines   []GrossProfitLineDTO `json:"lines"`
+	Total   GrossProfitLineDTO   `json:"total"`
+}
+
+type ReceivablesAgingLineDTO struct {
+	CustomerID      uuid.UUID       `json:"customer_id"`
+	CustomerName    string          `json:"customer_name"`
+	Current         decimal.Decimal `json:"current"`
+	Days1To30       decimal.Decimal `json:"days_1_30"`
+	Days31To60      decimal.Decimal `json:"days_31_60"`
+	Days61To90      decimal.Decimal `json:"days_61_90"`
+	Over90          decimal.Decimal `json:"over_90"`
+	Total           decimal.Decimal `json:"total"`
+	UnappliedCredit decimal.Decimal `json:"unapplied_credit"`
+	Balance         decimal.Decimal `json:"balance"`
+}
+
+type AgingInvoiceDTO struct {
+	InvoiceID     uuid.UUID       `json:"invoice_id"`
+	CustomerID    uuid.UUID       `json:"customer_id"`
+	CustomerName  string          `json:"customer_name"`
+	InvoiceNumber string          `json:"invoice_number"`
+	InvoiceDate   time.Time       `json:"invoice_date"`
+	DueDate       string          `json:"due_date"` // YYYY-MM-DD
+	TotalAmount   decimal.Decimal `json:"total_amount"`
+	OpenAmount    decimal.Decimal `json:"open_amount"`
+	DaysOverdue   int             `json:"days_overdue"`
+	Bucket        string          `json:"bucket"`
+}
+
+type ReceivablesAgingReportDTO struct {
+	AsOf     string                    `json:"as_of"` // YYYY-MM-DD
+	Lines    []ReceivablesAgingLineDTO `json:"lines"`
+	Total    ReceivablesAgingLineDTO   `json:"total"`
+	Invoices []AgingInvoiceDTO         `json:"invoices"`
+}
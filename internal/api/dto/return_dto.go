@@ -3,6 +3,8 @@ package dto
 import (
 	"time"
 
+	"sancaksoft/internal/domain"
+
 	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
 )
@@ -14,18 +16,39 @@ type CreateCustomerReturnRequestDTO struct {
 	Quantity    int             `json:"quantity" validate:"required,min=1"`
 	UnitPrice   decimal.Decimal `json:"unit_price" validate:"required,min=0"`
 	Reason      string          `json:"reason"`
+	ReasonID    *uuid.UUID      `json:"reason_id"`
+}
+
+type InspectCustomerReturnRequestDTO struct {
+	RestockQty       int        `json:"restock_qty" validate:"min=0"`
+	ScrapQty         int        `json:"scrap_qty" validate:"min=0"`
+	SupplierQty      int        `json:"supplier_qty" validate:"min=0"`
+	ScrapWarehouseID *uuid.UUID `json:"scrap_warehouse_id"`
+	RestockSerials   []string   `json:"restock_serials"` // Serialized products only, one per restocked unit
+	ScrapSerials     []string   `json:"scrap_serials"`   // Serialized products only, one per scrapped unit
+	Note             string     `json:"note"`
 }
 
 type CustomerReturnResponseDTO struct {
-	ID          uuid.UUID       `json:"id"`
-	CustomerID  uuid.UUID       `json:"customer_id"`
-	ProductID   uuid.UUID       `json:"product_id"`
-	WarehouseID uuid.UUID       `json:"warehouse_id"`
-	Quantity    int             `json:"quantity"`
-	UnitPrice   decimal.Decimal `json:"unit_price"`
-	Total       decimal.Decimal `json:"total"`
-	Reason      string          `json:"reason"`
-	CreatedAt   time.Time       `json:"created_at"`
+	ID               uuid.UUID           `json:"id"`
+	CustomerID       uuid.UUID           `json:"customer_id"`
+	ProductID        uuid.UUID           `json:"product_id"`
+	WarehouseID      uuid.UUID           `json:"warehouse_id"`
+	Quantity         int                 `json:"quantity"`
+	UnitPrice        decimal.Decimal     `json:"unit_price"`
+	Total            decimal.Decimal     `json:"total"`
+	Reason           string              `json:"reason"`
+	ReasonID         *uuid.UUID          `json:"reason_id"`
+	Status           domain.ReturnStatus `json:"status"`
+	RestockQty       int                 `json:"restock_qty"`
+	ScrapQty         int                 `json:"scrap_qty"`
+	SupplierQty      int                 `json:"supplier_qty"`
+	ScrapWarehouseID *uuid.UUID          `json:"scrap_warehouse_id"`
+	InspectionNote   string              `json:"inspection_note"`
+	ReceivedAt       *time.Time          `json:"received_at"`
+	InspectedAt      *time.Time          `json:"inspected_at"`
+	ClosedAt         *time.Time          `json:"closed_at"`
+	CreatedAt        time.Time           `json:"created_at"`
 }
 
 type CustomerPurchaseSummaryDTO struct {
@@ -40,3 +63,30 @@ type CustomerPurchaseSummaryDTO struct {
 	ReturnableQty int             `json:"returnable_qty"`
 	LastUnitPrice decimal.Decimal `json:"last_unit_price"`
 }
+
+type ReturnReasonRequestDTO struct {
+	Code     string `json:"code" validate:"required"`
+	Name     string `json:"name" validate:"required"`
+	IsActive *bool  `json:"is_active"`
+}
+
+type ReturnReasonResponseDTO struct {
+	ID        uuid.UUID `json:"id"`
+	Code      string    `json:"code"`
+	Name      string    `json:"name"`
+	IsActive  bool      `json:"is_active"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type ReturnReasonReportRowDTO struct {
+	ReasonID    *uuid.UUID      `json:"reason_id"`
+	Code        string          `json:"code"`
+	Name        string          `json:"name"`
+	ReturnCount int             `json:"return_count"`
+	Quantity    int             `json:"quantity"`
+	TotalAmount decimal.Decimal `json:"total_amount"`
+	RestockQty  int             `json:"restock_qty"`
+	ScrapQty    int             `json:"scrap_qty"`
+	SupplierQty int             `json:"supplier_qty"`
+}
@@ -0,0 +1,61 @@
+package dto
+
+import (
+	"time"
+
+	"sancaksoft/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+type CreateSalesOrderRequestDTO struct {
+	CustomerID  uuid.UUID           `json:"customer_id" validate:"required"`
+	WarehouseID uuid.UUID           `json:"warehouse_id" validate:"required"`
+	Note        string              `json:"note"`
+	Items       []SalesOrderItemDTO `json:"items" validate:"required,min=1,dive"`
+}
+
+type SalesOrderItemDTO struct {
+	ProductID uuid.UUID       `json:"product_id" validate:"required"`
+	Quantity  int             `json:"quantity" validate:"required,min=1"`
+	UnitPrice decimal.Decimal `json:"unit_price" validate:"required"`
+}
+
+// InvoiceSalesOrderRequestDTO converts an order into an invoice. Omit lines to invoice everything remaining.
+type InvoiceSalesOrderRequestDTO struct {
+	IdempotencyKey       uuid.UUID                   `json:"idempotency_key" validate:"required"`
+	Lines                []SalesOrderDeliveryLineDTO `json:"lines" validate:"dive"`
+	CreditOverrideReason string                      `json:"credit_override_reason"` // Managers only; invoices a customer on credit hold
+}
+
+type SalesOrderDeliveryLineDTO struct {
+	OrderItemID   uuid.UUID `json:"order_item_id" validate:"required"`
+	Quantity      int       `json:"quantity" validate:"required,min=1"`
+	SerialNumbers []string  `json:"serial_numbers,omitempty"`
+}
+
+type SalesOrderResponseDTO struct {
+	ID          uuid.UUID                   `json:"id"`
+	OrderNumber string                      `json:"order_number"`
+	CustomerID  uuid.UUID                   `json:"customer_id"`
+	WarehouseID uuid.UUID                   `json:"warehouse_id"`
+	Status      domain.SalesOrderStatus     `json:"status"`
+	Note        string                      `json:"note"`
+	TotalAmount decimal.Decimal             `json:"total_amount"`
+	ConfirmedAt *time.Time                  `json:"confirmed_at"`
+	ClosedAt    *time.Time                  `json:"closed_at"`
+	CreatedAt   time.Time                   `json:"created_at"`
+	UpdatedAt   time.Time                   `json:"updated_at"`
+	Items       []SalesOrderItemResponseDTO `json:"items,omitempty"`
+}
+
+type SalesOrderItemResponseDTO struct {
+	ID           uuid.UUID       `json:"id"`
+	ProductID    uuid.UUID       `json:"product_id"`
+	Quantity     int             `json:"quantity"`
+	DeliveredQty int             `json:"delivered_qty"`
+	RemainingQty int             `json:"remaining_qty"`
+	UnitPrice    decimal.Decimal `json:"unit_price"`
+	Total        decimal.Decimal `json:"total"`
+}
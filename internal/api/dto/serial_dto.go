@@ -0,0 +1,43 @@
+package dto
+
+import (
+	"time"
+
+	"sancaksoft/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+type SerialNumberDTO struct {
+	ID           uuid.UUID `json:"id"`
+	SerialNumber string    `json:"serial_number"`
+	ProductID    uuid.UUID `json:"product_id"`
+	WarehouseID  uuid.UUID `json:"warehouse_id"`
+	ReceivedAt   time.Time `json:"received_at"`
+}
+
+type SerialEventDTO struct {
+	Event          domain.SerialEventType   `json:"event"`
+	MovementID     uuid.UUID                `json:"movement_id"`
+	MovementType   domain.StockMovementType `json:"movement_type"`
+	WarehouseID    uuid.UUID                `json:"warehouse_id"`
+	WarehouseName  string                   `json:"warehouse_name"`
+	DocumentType   *string                  `json:"document_type"`
+	DocumentID     *uuid.UUID               `json:"document_id"`
+	DocumentNumber *string                  `json:"document_number"`
+	PartyName      *string                  `json:"party_name"`
+	CreatedAt      time.Time                `json:"created_at"`
+}
+
+// SerialHistoryDTO is one unit with its movements. WarehouseID is null while the unit is
+// out of stock.
+type SerialHistoryDTO struct {
+	SerialNumber  string           `json:"serial_number"`
+	ProductID     uuid.UUID        `json:"product_id"`
+	ProductName   string           `json:"product_name"`
+	SKU           string           `json:"sku"`
+	InStock       bool             `json:"in_stock"`
+	WarehouseID   *uuid.UUID       `json:"warehouse_id"`
+	WarehouseName *string          `json:"warehouse_name"`
+	Events        []SerialEventDTO `json:"events"`
+}
@@ -6,6 +6,7 @@ import (
 	"sancaksoft/internal/domain"
 
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 )
 
 type StockMovementResponseDTO struct {
@@ -16,18 +17,54 @@ type StockMovementResponseDTO struct {
 	Type          domain.StockMovementType `json:"type"`
 	ReferenceID   *uuid.UUID               `json:"reference_id"`
 	ReferenceType *string                  `json:"reference_type"`
+	UnitCost      *decimal.Decimal         `json:"unit_cost"`
+	AssignedCost  *decimal.Decimal         `json:"assigned_cost"`
+	LotID         *uuid.UUID               `json:"lot_id"`
+	LotNumber     *string                  `json:"lot_number"`
+	ExpiryDate    *string                  `json:"expiry_date"`
+	SerialNumbers []string                 `json:"serial_numbers,omitempty"`
+	LocationID    *uuid.UUID               `json:"location_id"`
 	CreatedAt     time.Time                `json:"created_at"`
 }
 
 type CreateStockMovementRequestDTO struct {
-	ProductID   uuid.UUID                `json:"product_id" validate:"required"`
-	WarehouseID uuid.UUID                `json:"warehouse_id" validate:"required"`
-	Quantity    int                      `json:"quantity" validate:"required,gt=0"`
-	Type        domain.StockMovementType `json:"type" validate:"required"`
+	ProductID     uuid.UUID                `json:"product_id" validate:"required"`
+	WarehouseID   uuid.UUID                `json:"warehouse_id" validate:"required"`
+	Quantity      int                      `json:"quantity" validate:"required,gt=0"`
+	Type          domain.StockMovementType `json:"type" validate:"required"`
+	UnitCost      *decimal.Decimal         `json:"unit_cost"`      // IN only; purchase cost per unit
+	OccurredAt    string                   `json:"occurred_at"`    // Optional YYYY-MM-DD to back-date the movement
+	LotNumber     string                   `json:"lot_number"`     // Required for lot-tracked products
+	ExpiryDate    string                   `json:"expiry_date"`    // IN only; YYYY-MM-DD, recorded on the lot's first receipt
+	SerialNumbers []string                 `json:"serial_numbers"` // Required for serialized products, one per unit
+	LocationID    *uuid.UUID               `json:"location_id"`    // Optional bin; OUT without one takes unlocated stock
 }
 
 type WarehouseStockDTO struct {
 	WarehouseID   uuid.UUID `json:"warehouse_id"`
 	WarehouseName string    `json:"warehouse_name"`
 	Quantity      int       `json:"quantity"`
-}
\ No newline at end of file
+}
+
+type ProductMovementLineDTO struct {
+	ID             uuid.UUID                `json:"id"`
+	WarehouseID    uuid.UUID                `json:"warehouse_id"`
+	WarehouseName  string                   `json:"warehouse_name"`
+	Type           domain.StockMovementType `json:"type"`
+	Quantity       int                      `json:"quantity"`
+	RunningBalance int                      `json:"running_balance"`
+	ReferenceType  *string                  `json:"reference_type"`
+	ReferenceID    *uuid.UUID               `json:"reference_id"`
+	DocumentNumber *string                  `json:"document_number"`
+	PartyName      *string                  `json:"party_name"`
+	CreatedAt      time.Time                `json:"created_at"`
+}
+
+// ProductMovementCardDTO is the movement card (stok kartı) of a product.
+type ProductMovementCardDTO struct {
+	ProductID      uuid.UUID                `json:"product_id"`
+	WarehouseID    *uuid.UUID               `json:"warehouse_id"`
+	OpeningBalance int                      `json:"opening_balance"`
+	ClosingBalance int                      `json:"closing_balance"`
+	Lines          []ProductMovementLineDTO `json:"lines"`
+}
@@ -0,0 +1,52 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UpdateCompanyProfileRequestDTO replaces the company details printed on documents.
+type UpdateCompanyProfileRequestDTO struct {
+	Name      string `json:"name" validate:"required"`
+	Address   string `json:"address"`
+	District  string `json:"district"` // İlçe
+	City      string `json:"city"`     // İl
+	Phone     string `json:"phone"`
+	Email     string `json:"email"`
+	TaxOffice string `json:"tax_office"` // Vergi dairesi
+	TaxNumber string `json:"tax_number"` // VKN
+}
+
+type CompanyProfileResponseDTO struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	Address   string    `json:"address"`
+	District  string    `json:"district"`
+	City      string    `json:"city"`
+	Phone     string    `json:"phone"`
+	Email     string    `json:"email"`
+	TaxOffice string    `json:"tax_office"`
+	TaxNumber string    `json:"tax_number"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// SaveInvoiceTemplateRequestDTO replaces the invoice layout; empty title and color use the defaults.
+type SaveInvoiceTemplateRequestDTO struct {
+	Title       string `json:"title"`
+	AccentColor string `json:"accent_color"` // #RRGGBB
+	ShowSKU     bool   `json:"show_sku"`
+	ShowDueDate bool   `json:"show_due_date"`
+	BankDetails string `json:"bank_details"`
+	FooterNote  string `json:"footer_note"`
+}
+
+type InvoiceTemplateResponseDTO struct {
+	Title       string    `json:"title"`
+	AccentColor string    `json:"accent_color"`
+	ShowSKU     bool      `json:"show_sku"`
+	ShowDueDate bool      `json:"show_due_date"`
+	BankDetails string    `json:"bank_details"`
+	FooterNote  string    `json:"footer_note"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
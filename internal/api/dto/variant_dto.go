@@ -0,0 +1,46 @@
+package dto
+
+import (
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+type VariantDimensionDTO struct {
+	ID     uuid.UUID `json:"id"`
+	Name   string    `json:"name" validate:"required"`
+	Values []string  `json:"values" validate:"required"`
+}
+
+// SetVariantDimensionsRequestDTO defines a product's variant dimensions in order, e.g.
+// [{"name": "Size", "values": ["S", "M"]}, {"name": "Colour", "values": ["Red"]}].
+type SetVariantDimensionsRequestDTO struct {
+	Dimensions []VariantDimensionDTO `json:"dimensions" validate:"required"`
+}
+
+// VariantPriceRequestDTO overrides a variant's price; null resets it to the parent's price.
+type VariantPriceRequestDTO struct {
+	Price *decimal.Decimal `json:"price"`
+}
+
+type VariantSummaryLineDTO struct {
+	ProductID       uuid.UUID         `json:"product_id"`
+	Name            string            `json:"name"`
+	SKU             string            `json:"sku"`
+	Barcode         string            `json:"barcode"`
+	VariantKey      string            `json:"variant_key"`
+	Options         map[string]string `json:"options"`
+	Price           decimal.Decimal   `json:"price"`
+	PriceOverridden bool              `json:"price_overridden"`
+	Stock           int               `json:"stock"`
+	SoldQuantity    int               `json:"sold_quantity"`
+	Revenue         decimal.Decimal   `json:"revenue"`
+}
+
+type VariantSummaryDTO struct {
+	ParentID     uuid.UUID               `json:"parent_id"`
+	Dimensions   []VariantDimensionDTO   `json:"dimensions"`
+	Variants     []VariantSummaryLineDTO `json:"variants"`
+	TotalStock   int                     `json:"total_stock"`
+	TotalSold    int                     `json:"total_sold"`
+	TotalRevenue decimal.Decimal         `json:"total_revenue"`
+}
@@ -7,14 +7,16 @@ import (
 )
 
 type CreateWarehouseRequestDTO struct {
-	Name     string `json:"name" validate:"required"`
-	Location string `json:"location"`
+	Name         string `json:"name" validate:"required"`
+	Location     string `json:"location"`
+	IsQuarantine bool   `json:"is_quarantine"`
 }
 
 type WarehouseResponseDTO struct {
-	ID        uuid.UUID `json:"id"`
-	Name      string    `json:"name"`
-	Location  string    `json:"location"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID           uuid.UUID `json:"id"`
+	Name         string    `json:"name"`
+	Location     string    `json:"location"`
+	IsQuarantine bool      `json:"is_quarantine"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
 }
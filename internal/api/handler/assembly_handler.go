@@ -0,0 +1,227 @@
+package handler
+
+import (
+	"context"
+	"time"
+
+	"sancaksoft/internal/api/dto"
+	"sancaksoft/internal/api/middleware"
+	"sancaksoft/internal/domain"
+	"sancaksoft/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type AssemblyHandler struct {
+	service *service.AssemblyService
+}
+
+func NewAssemblyHandler(s *service.AssemblyService) *AssemblyHandler {
+	return &AssemblyHandler{service: s}
+}
+
+func toBOMDTO(bom *domain.BillOfMaterials) dto.BOMResponseDTO {
+	resp := dto.BOMResponseDTO{
+		ProductID:      bom.ProductID,
+		OutputQuantity: bom.OutputQuantity,
+		Note:           bom.Note,
+		UpdatedAt:      bom.UpdatedAt,
+		Components:     make([]dto.BOMComponentDTO, len(bom.Components)),
+	}
+	for i, c := range bom.Components {
+		resp.Components[i] = dto.BOMComponentDTO(c)
+	}
+	return resp
+}
+
+func toAssemblyOrderDTO(o *domain.AssemblyOrder) dto.AssemblyOrderResponseDTO {
+	resp := dto.AssemblyOrderResponseDTO{
+		ID:          o.ID,
+		OrderNumber: o.OrderNumber,
+		ProductID:   o.ProductID,
+		WarehouseID: o.WarehouseID,
+		Status:      o.Status,
+		Quantity:    o.Quantity,
+		ProducedQty: o.ProducedQty,
+		ScrapQty:    o.ScrapQty,
+		LotID:       o.LotID,
+		TotalCost:   o.TotalCost,
+		UnitCost:    o.UnitCost,
+		Note:        o.Note,
+		CompletedAt: o.CompletedAt,
+		CreatedAt:   o.CreatedAt,
+		UpdatedAt:   o.UpdatedAt,
+	}
+	if o.Status == domain.AssemblyOrderStatusCompleted {
+		yield := o.YieldPct()
+		resp.YieldPct = &yield
+	}
+	for _, c := range o.Components {
+		resp.Components = append(resp.Components, dto.AssemblyOrderComponentDTO{
+			ProductID: c.ProductID,
+			Quantity:  c.Quantity,
+			UnitCost:  c.UnitCost,
+		})
+	}
+	return resp
+}
+
+// GetBOM handles GET /products/:id/bom
+func (h *AssemblyHandler) GetBOM(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+	productID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid product id"})
+	}
+
+	bom, err := h.service.GetBOM(c.Context(), tenantID, productID)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(toBOMDTO(bom))
+}
+
+// SetBOM handles PUT /products/:id/bom
+func (h *AssemblyHandler) SetBOM(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+	productID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid product id"})
+	}
+
+	var reqDTO dto.SetBOMRequestDTO
+	if err := c.BodyParser(&reqDTO); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body", "details": err.Error()})
+	}
+
+	bom := &domain.BillOfMaterials{
+		TenantID:       tenantID,
+		ProductID:      productID,
+		OutputQuantity: reqDTO.OutputQuantity,
+		Note:           reqDTO.Note,
+		Components:     make([]domain.BOMComponent, len(reqDTO.Components)),
+	}
+	for i, comp := range reqDTO.Components {
+		bom.Components[i] = domain.BOMComponent{ComponentID: comp.ComponentID, Quantity: comp.Quantity}
+	}
+	bom, err = h.service.SetBOM(c.Context(), bom)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(toBOMDTO(bom))
+}
+
+// DeleteBOM handles DELETE /products/:id/bom
+func (h *AssemblyHandler) DeleteBOM(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+	productID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid product id"})
+	}
+
+	if err := h.service.DeleteBOM(c.Context(), tenantID, productID); err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// CreateAssemblyOrder handles POST /assembly-orders
+func (h *AssemblyHandler) CreateAssemblyOrder(c *fiber.Ctx) error {
+	var reqDTO dto.CreateAssemblyOrderRequestDTO
+	if err := c.BodyParser(&reqDTO); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body", "details": err.Error()})
+	}
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+
+	order, err := h.service.CreateAssemblyOrder(c.Context(), domain.CreateAssemblyOrderRequest{
+		TenantID:    tenantID,
+		ProductID:   reqDTO.ProductID,
+		WarehouseID: reqDTO.WarehouseID,
+		Quantity:    reqDTO.Quantity,
+		Note:        reqDTO.Note,
+	})
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(fiber.StatusCreated).JSON(toAssemblyOrderDTO(order))
+}
+
+// ListAssemblyOrders handles GET /assembly-orders?product_id=&status=
+func (h *AssemblyHandler) ListAssemblyOrders(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+	productID, err := parseOptionalUUID(c, "product_id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	orders, err := h.service.ListAssemblyOrders(c.Context(), tenantID, productID, domain.AssemblyOrderStatus(c.Query("status")))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	resp := make([]dto.AssemblyOrderResponseDTO, len(orders))
+	for i := range orders {
+		resp[i] = toAssemblyOrderDTO(&orders[i])
+	}
+	return c.JSON(resp)
+}
+
+// GetAssemblyOrder handles GET /assembly-orders/:id
+func (h *AssemblyHandler) GetAssemblyOrder(c *fiber.Ctx) error {
+	return h.transition(c, h.service.GetAssemblyOrder)
+}
+
+// CancelAssemblyOrder handles POST /assembly-orders/:id/cancel
+func (h *AssemblyHandler) CancelAssemblyOrder(c *fiber.Ctx) error {
+	return h.transition(c, h.service.CancelAssemblyOrder)
+}
+
+// CompleteAssemblyOrder handles POST /assembly-orders/:id/complete
+func (h *AssemblyHandler) CompleteAssemblyOrder(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+	orderID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid assembly order id"})
+	}
+
+	var reqDTO dto.CompleteAssemblyOrderRequestDTO
+	if err := c.BodyParser(&reqDTO); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body", "details": err.Error()})
+	}
+	var expiryDate *time.Time
+	if reqDTO.ExpiryDate != "" {
+		t, err := time.Parse(dateLayout, reqDTO.ExpiryDate)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid expiry_date, expected YYYY-MM-DD"})
+		}
+		expiryDate = &t
+	}
+
+	order, err := h.service.CompleteAssemblyOrder(c.Context(), domain.CompleteAssemblyOrderRequest{
+		TenantID:    tenantID,
+		OrderID:     orderID,
+		ProducedQty: reqDTO.ProducedQty,
+		ScrapQty:    reqDTO.ScrapQty,
+		LotNumber:   reqDTO.LotNumber,
+		ExpiryDate:  expiryDate,
+	})
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(toAssemblyOrderDTO(order))
+}
+
+func (h *AssemblyHandler) transition(c *fiber.Ctx, fn func(ctx context.Context, tenantID, orderID uuid.UUID) (*domain.AssemblyOrder, error)) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+	orderID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid assembly order id"})
+	}
+
+	order, err := fn(c.Context(), tenantID, orderID)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(toAssemblyOrderDTO(order))
+}
@@ -0,0 +1,41 @@
+package handler
+
+import (
+	"sancaksoft/internal/api/middleware"
+	"sancaksoft/internal/domain"
+	"sancaksoft/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type AuditHandler struct {
+	service *service.AuditService
+}
+
+func NewAuditHandler(s *service.AuditService) *AuditHandler {
+	return &AuditHandler{service: s}
+}
+
+// ListAuditLogs handles GET /audit-logs?entity_type=&entity_id=&user_id=&action=&from=&to=
+func (h *AuditHandler) ListAuditLogs(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+
+	filter := domain.AuditLogFilter{TenantID: tenantID, EntityType: c.Query("entity_type"), Action: c.Query("action")}
+	var err error
+	if filter.EntityID, err = parseOptionalUUID(c, "entity_id"); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	if filter.UserID, err = parseOptionalUUID(c, "user_id"); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	if filter.From, filter.To, err = parseDateRange(c); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	logs, err := h.service.ListAuditLogs(c.Context(), filter)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(logs)
+}
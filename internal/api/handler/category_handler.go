@@ -0,0 +1,232 @@
+package handler
+
+import (
+	"sancaksoft/internal/api/dto"
+	"sancaksoft/internal/api/middleware"
+	"sancaksoft/internal/domain"
+	"sancaksoft/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type CategoryHandler struct {
+	service *service.CategoryService
+}
+
+func NewCategoryHandler(s *service.CategoryService) *CategoryHandler {
+	return &CategoryHandler{service: s}
+}
+
+func toCategoryDTO(c domain.ProductCategory) dto.CategoryResponseDTO {
+	return dto.CategoryResponseDTO{
+		ID:        c.ID,
+		ParentID:  c.ParentID,
+		Name:      c.Name,
+		Path:      c.Path,
+		Depth:     c.Depth,
+		CreatedAt: c.CreatedAt,
+		UpdatedAt: c.UpdatedAt,
+	}
+}
+
+func toAttributeDTO(a domain.CategoryAttribute) dto.AttributeResponseDTO {
+	return dto.AttributeResponseDTO{
+		ID:         a.ID,
+		CategoryID: a.CategoryID,
+		Code:       a.Code,
+		Name:       a.Name,
+		DataType:   a.DataType,
+		Options:    a.Options,
+	}
+}
+
+// ListCategories handles GET /categories
+func (h *CategoryHandler) ListCategories(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+
+	categories, err := h.service.ListCategories(c.Context(), tenantID)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	resp := make([]dto.CategoryResponseDTO, len(categories))
+	for i, cat := range categories {
+		resp[i] = toCategoryDTO(cat)
+	}
+	return c.JSON(resp)
+}
+
+// CreateCategory handles POST /categories
+func (h *CategoryHandler) CreateCategory(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+
+	var reqDTO dto.CategoryRequestDTO
+	if err := c.BodyParser(&reqDTO); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body", "details": err.Error()})
+	}
+
+	category := &domain.ProductCategory{
+		TenantID: tenantID,
+		ParentID: reqDTO.ParentID,
+		Name:     reqDTO.Name,
+	}
+	if err := h.service.CreateCategory(c.Context(), category); err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(fiber.StatusCreated).JSON(toCategoryDTO(*category))
+}
+
+// UpdateCategory handles PUT /categories/:id
+func (h *CategoryHandler) UpdateCategory(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+	categoryID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid category id"})
+	}
+
+	var reqDTO dto.CategoryRequestDTO
+	if err := c.BodyParser(&reqDTO); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body", "details": err.Error()})
+	}
+
+	category := &domain.ProductCategory{
+		ID:       categoryID,
+		TenantID: tenantID,
+		ParentID: reqDTO.ParentID,
+		Name:     reqDTO.Name,
+	}
+	if err := h.service.UpdateCategory(c.Context(), category); err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(toCategoryDTO(*category))
+}
+
+// DeleteCategory handles DELETE /categories/:id
+func (h *CategoryHandler) DeleteCategory(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+	categoryID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid category id"})
+	}
+
+	if err := h.service.DeleteCategory(c.Context(), tenantID, categoryID); err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// ListCategoryAttributes handles GET /categories/:id/attributes
+func (h *CategoryHandler) ListCategoryAttributes(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+	categoryID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid category id"})
+	}
+
+	attributes, err := h.service.ListCategoryAttributes(c.Context(), tenantID, categoryID)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	resp := make([]dto.AttributeResponseDTO, len(attributes))
+	for i, a := range attributes {
+		resp[i] = toAttributeDTO(a)
+	}
+	return c.JSON(resp)
+}
+
+// CreateAttribute handles POST /categories/:id/attributes
+func (h *CategoryHandler) CreateAttribute(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+	categoryID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid category id"})
+	}
+
+	var reqDTO dto.CreateAttributeRequestDTO
+	if err := c.BodyParser(&reqDTO); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body", "details": err.Error()})
+	}
+
+	attribute := &domain.CategoryAttribute{
+		TenantID:   tenantID,
+		CategoryID: categoryID,
+		Code:       reqDTO.Code,
+		Name:       reqDTO.Name,
+		DataType:   reqDTO.DataType,
+		Options:    reqDTO.Options,
+	}
+	if err := h.service.CreateAttribute(c.Context(), attribute); err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(fiber.StatusCreated).JSON(toAttributeDTO(*attribute))
+}
+
+// ListBrands handles GET /brands
+func (h *CategoryHandler) ListBrands(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+
+	brands, err := h.service.ListBrands(c.Context(), tenantID)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	resp := make([]dto.BrandResponseDTO, len(brands))
+	for i, b := range brands {
+		resp[i] = dto.BrandResponseDTO{ID: b.ID, Name: b.Name, CreatedAt: b.CreatedAt}
+	}
+	return c.JSON(resp)
+}
+
+// CreateBrand handles POST /brands
+func (h *CategoryHandler) CreateBrand(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+
+	var reqDTO dto.CreateBrandRequestDTO
+	if err := c.BodyParser(&reqDTO); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body", "details": err.Error()})
+	}
+
+	brand := &domain.Brand{TenantID: tenantID, Name: reqDTO.Name}
+	if err := h.service.CreateBrand(c.Context(), brand); err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(fiber.StatusCreated).JSON(dto.BrandResponseDTO{ID: brand.ID, Name: brand.Name, CreatedAt: brand.CreatedAt})
+}
+
+// SetProductClassification handles PUT /products/:id/classification
+func (h *CategoryHandler) SetProductClassification(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+	productID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid product id"})
+	}
+
+	var reqDTO dto.ProductClassificationRequestDTO
+	if err := c.BodyParser(&reqDTO); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body", "details": err.Error()})
+	}
+
+	if err := h.service.SetProductClassification(c.Context(), tenantID, productID, reqDTO.CategoryID, reqDTO.BrandID); err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"product_id": productID, "category_id": reqDTO.CategoryID, "brand_id": reqDTO.BrandID})
+}
+
+// SetProductAttributes handles PUT /products/:id/attributes
+func (h *CategoryHandler) SetProductAttributes(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+	productID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid product id"})
+	}
+
+	var reqDTO dto.ProductAttributesRequestDTO
+	if err := c.BodyParser(&reqDTO); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body", "details": err.Error()})
+	}
+
+	attributes, err := h.service.SetProductAttributes(c.Context(), tenantID, productID, reqDTO.Attributes)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"product_id": productID, "attributes": attributes})
+}
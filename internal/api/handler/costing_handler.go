@@ -0,0 +1,156 @@
+package handler
+
+import (
+	"sancaksoft/internal/api/dto"
+	"sancaksoft/internal/api/middleware"
+	"sancaksoft/internal/domain"
+	"sancaksoft/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+type CostingHandler struct {
+	service *service.CostingService
+}
+
+func NewCostingHandler(s *service.CostingService) *CostingHandler {
+	return &CostingHandler{service: s}
+}
+
+// GetCostingMethod handles GET /costing/method
+func (h *CostingHandler) GetCostingMethod(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+
+	method, err := h.service.GetCostingMethod(c.Context(), tenantID)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(dto.CostingMethodDTO{CostingMethod: string(method)})
+}
+
+// SetCostingMethod handles PUT /costing/method
+func (h *CostingHandler) SetCostingMethod(c *fiber.Ctx) error {
+	var reqDTO dto.CostingMethodDTO
+	if err := c.BodyParser(&reqDTO); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body", "details": err.Error()})
+	}
+
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+
+	if err := h.service.SetCostingMethod(c.Context(), tenantID, domain.CostingMethod(reqDTO.CostingMethod)); err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(reqDTO)
+}
+
+// Recalculate handles POST /costing/recalculate
+func (h *CostingHandler) Recalculate(c *fiber.Ctx) error {
+	var reqDTO dto.RecalculateCostsRequestDTO
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&reqDTO); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body", "details": err.Error()})
+		}
+	}
+
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+
+	count, err := h.service.Recalculate(c.Context(), tenantID, reqDTO.ProductID)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"recalculated_products": count})
+}
+
+// GetStockValuation handles GET /stock/valuation?warehouse_id=&group_by=category|brand
+func (h *CostingHandler) GetStockValuation(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+	warehouseID, err := parseOptionalUUID(c, "warehouse_id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	method, err := h.service.GetCostingMethod(c.Context(), tenantID)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	lines, err := h.service.GetStockValuation(c.Context(), tenantID, warehouseID)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	resp := dto.StockValuationResponseDTO{
+		CostingMethod: string(method),
+		TotalValue:    decimal.Zero,
+		Lines:         make([]dto.StockValuationLineDTO, len(lines)),
+	}
+	for i, l := range lines {
+		resp.Lines[i] = dto.StockValuationLineDTO(l)
+		resp.TotalValue = resp.TotalValue.Add(l.Value)
+	}
+	if groupBy := c.Query("group_by"); groupBy != "" {
+		groups, err := h.service.GroupStockValuation(lines, domain.StockGroupBy(groupBy))
+		if err != nil {
+			return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+		}
+		resp.GroupBy = groupBy
+		resp.Groups = make([]dto.StockValuationGroupDTO, len(groups))
+		for i, g := range groups {
+			resp.Groups[i] = dto.StockValuationGroupDTO(g)
+		}
+	}
+	return c.JSON(resp)
+}
+
+// GetCostOfGoods handles GET /costing/cogs?from=&to=
+func (h *CostingHandler) GetCostOfGoods(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+	from, to, err := parseDateRange(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	lines, err := h.service.GetCostOfGoods(c.Context(), tenantID, from, to)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	resp := dto.CostOfGoodsResponseDTO{
+		TotalCost: decimal.Zero,
+		Lines:     make([]dto.CostOfGoodsLineDTO, len(lines)),
+	}
+	for i, l := range lines {
+		resp.Lines[i] = dto.CostOfGoodsLineDTO(l)
+		resp.TotalCost = resp.TotalCost.Add(l.Cost)
+	}
+	return c.JSON(resp)
+}
+
+// GetInvoiceProfit handles GET /invoices/:id/profit
+func (h *CostingHandler) GetInvoiceProfit(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+	invoiceID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid invoice id"})
+	}
+
+	profit, err := h.service.GetInvoiceProfit(c.Context(), tenantID, invoiceID)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	resp := dto.InvoiceProfitResponseDTO{
+		InvoiceID:     profit.InvoiceID,
+		InvoiceNumber: profit.InvoiceNumber,
+		Revenue:       profit.Revenue,
+		Cost:          profit.Cost,
+		GrossProfit:   profit.GrossProfit,
+		MarginPct:     profit.MarginPct,
+		Lines:         make([]dto.InvoiceLineProfitDTO, len(profit.Lines)),
+	}
+	for i, l := range profit.Lines {
+		resp.Lines[i] = dto.InvoiceLineProfitDTO(l)
+	}
+	return c.JSON(resp)
+}
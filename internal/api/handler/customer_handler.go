@@ -1,6 +1,11 @@
 package handler
 
 import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"time"
+
 	"sancaksoft/internal/api/dto"
 	"sancaksoft/internal/api/middleware"
 	"sancaksoft/internal/domain"
@@ -28,28 +33,42 @@ func (h *CustomerHandler) CreateCustomer(c *fiber.Ctx) error {
 	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
 
 	customer := &domain.Customer{
-		TenantID: tenantID,
-		Name:     reqDTO.Name,
-		Email:    reqDTO.Email,
-		Phone:    reqDTO.Phone,
-		Address:  reqDTO.Address,
+		TenantID:        tenantID,
+		Name:            reqDTO.Name,
+		Email:           reqDTO.Email,
+		Phone:           reqDTO.Phone,
+		Address:         reqDTO.Address,
+		District:        reqDTO.District,
+		City:            reqDTO.City,
+		TaxNumber:       reqDTO.TaxNumber,
+		TaxOffice:       reqDTO.TaxOffice,
+		CreditLimit:     reqDTO.CreditLimit,
+		PaymentTermDays: reqDTO.PaymentTermDays,
 	}
 
 	if err := h.service.CreateCustomer(c.Context(), customer); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
 	}
 
-	respDTO := dto.CustomerResponseDTO{
-		ID:        customer.ID,
-		Name:      customer.Name,
-		Email:     customer.Email,
-		Phone:     customer.Phone,
-		Address:   customer.Address,
-		CreatedAt: customer.CreatedAt,
-		UpdatedAt: customer.UpdatedAt,
-	}
+	return c.Status(fiber.StatusCreated).JSON(toCustomerDTO(customer))
+}
 
-	return c.Status(fiber.StatusCreated).JSON(respDTO)
+func toCustomerDTO(cust *domain.Customer) dto.CustomerResponseDTO {
+	return dto.CustomerResponseDTO{
+		ID:              cust.ID,
+		Name:            cust.Name,
+		Email:           cust.Email,
+		Phone:           cust.Phone,
+		Address:         cust.Address,
+		District:        cust.District,
+		City:            cust.City,
+		TaxNumber:       cust.TaxNumber,
+		TaxOffice:       cust.TaxOffice,
+		CreditLimit:     cust.CreditLimit,
+		PaymentTermDays: cust.PaymentTermDays,
+		CreatedAt:       cust.CreatedAt,
+		UpdatedAt:       cust.UpdatedAt,
+	}
 }
 
 // ListCustomers handles GET /customers
@@ -62,16 +81,8 @@ func (h *CustomerHandler) ListCustomers(c *fiber.Ctx) error {
 	}
 
 	respDTOs := make([]dto.CustomerResponseDTO, len(customers))
-	for i, cust := range customers {
-		respDTOs[i] = dto.CustomerResponseDTO{
-			ID:        cust.ID,
-			Name:      cust.Name,
-			Email:     cust.Email,
-			Phone:     cust.Phone,
-			Address:   cust.Address,
-			CreatedAt: cust.CreatedAt,
-			UpdatedAt: cust.UpdatedAt,
-		}
+	for i := range customers {
+		respDTOs[i] = toCustomerDTO(&customers[i])
 	}
 
 	return c.JSON(respDTOs)
@@ -94,11 +105,217 @@ func (h *CustomerHandler) GetCustomerLedger(c *fiber.Ctx) error {
 	resp := make([]dto.CustomerLedgerEntryDTO, len(entries))
 	for i, e := range entries {
 		resp[i] = dto.CustomerLedgerEntryDTO{
-			PeriodStart:  e.PeriodStart,
-			SalesAmount:  e.SalesAmount,
-			ReturnAmount: e.ReturnAmount,
-			NetAmount:    e.NetAmount,
+			PeriodStart:   e.PeriodStart,
+			SalesAmount:   e.SalesAmount,
+			ReturnAmount:  e.ReturnAmount,
+			PaymentAmount: e.PaymentAmount,
+			NetAmount:     e.NetAmount,
 		}
 	}
 	return c.JSON(resp)
 }
+
+// GetCustomerCredit handles GET /customers/:customerId/credit
+func (h *CustomerHandler) GetCustomerCredit(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+	customerID, err := uuid.Parse(c.Params("customerId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid customer id"})
+	}
+
+	credit, err := h.service.GetCredit(c.Context(), tenantID, customerID)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(dto.CustomerCreditResponseDTO{
+		CustomerID:      credit.CustomerID,
+		AsOf:            credit.AsOf,
+		CreditLimit:     credit.CreditLimit,
+		PaymentTermDays: credit.PaymentTermDays,
+		OpenBalance:     credit.OpenBalance,
+		OverdueAmount:   credit.OverdueAmount,
+		Available:       credit.Available,
+	})
+}
+
+// SetCustomerCredit handles PUT /customers/:customerId/credit
+func (h *CustomerHandler) SetCustomerCredit(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+	customerID, err := uuid.Parse(c.Params("customerId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid customer id"})
+	}
+
+	var reqDTO dto.SetCreditTermsRequestDTO
+	if err := c.BodyParser(&reqDTO); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body", "details": err.Error()})
+	}
+
+	customer, err := h.service.SetCreditTerms(c.Context(), tenantID, customerID, reqDTO.CreditLimit, reqDTO.PaymentTermDays)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(toCustomerDTO(customer))
+}
+
+// CreateCustomerPayment handles POST /customers/:customerId/payments
+func (h *CustomerHandler) CreateCustomerPayment(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+	userID, _ := c.Locals(middleware.LocalsUserID).(uuid.UUID)
+	customerID, err := uuid.Parse(c.Params("customerId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid customer id"})
+	}
+
+	var reqDTO dto.CreateCustomerPaymentRequestDTO
+	if err := c.BodyParser(&reqDTO); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body", "details": err.Error()})
+	}
+
+	payment := &domain.CustomerPayment{
+		TenantID:   tenantID,
+		CustomerID: customerID,
+		Amount:     reqDTO.Amount,
+		Method:     domain.PaymentMethod(reqDTO.Method),
+		Reference:  reqDTO.Reference,
+		Note:       reqDTO.Note,
+	}
+	if reqDTO.PaymentDate != "" {
+		if payment.PaymentDate, err = time.Parse(dateLayout, reqDTO.PaymentDate); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid payment_date, expected YYYY-MM-DD"})
+		}
+	}
+	if userID != uuid.Nil {
+		payment.CreatedBy = &userID
+	}
+
+	if err := h.service.RecordPayment(c.Context(), payment); err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(fiber.StatusCreated).JSON(toCustomerPaymentDTO(payment))
+}
+
+// ListCustomerPayments handles GET /customers/:customerId/payments
+func (h *CustomerHandler) ListCustomerPayments(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+	customerID, err := uuid.Parse(c.Params("customerId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid customer id"})
+	}
+
+	payments, err := h.service.ListPayments(c.Context(), tenantID, customerID)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	resp := make([]dto.CustomerPaymentResponseDTO, len(payments))
+	for i := range payments {
+		resp[i] = toCustomerPaymentDTO(&payments[i])
+	}
+	return c.JSON(resp)
+}
+
+func toCustomerPaymentDTO(p *domain.CustomerPayment) dto.CustomerPaymentResponseDTO {
+	return dto.CustomerPaymentResponseDTO{
+		ID:          p.ID,
+		CustomerID:  p.CustomerID,
+		Amount:      p.Amount,
+		PaymentDate: p.PaymentDate,
+		Method:      string(p.Method),
+		Reference:   p.Reference,
+		Note:        p.Note,
+		CreatedBy:   p.CreatedBy,
+		CreatedAt:   p.CreatedAt,
+	}
+}
+
+// GetCustomerStatement handles GET /customers/:customerId/statement?from=&to=&format=pdf|csv
+func (h *CustomerHandler) GetCustomerStatement(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+	customerID, err := uuid.Parse(c.Params("customerId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid customer id"})
+	}
+	from, to, err := parseDateRange(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	statement, err := h.service.GetStatement(c.Context(), domain.CustomerStatementRequest{
+		TenantID:   tenantID,
+		CustomerID: customerID,
+		From:       from,
+		To:         to,
+	})
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	switch c.Query("format") {
+	case "pdf":
+		out, err := renderStatementPDF(statement)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		}
+		c.Set(fiber.HeaderContentType, "application/pdf")
+		c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s"`, statementFilename(statement, "pdf")))
+		return c.Send(out)
+	case "csv":
+		return sendStatementCSV(c, statement)
+	}
+
+	resp := dto.CustomerStatementResponseDTO{
+		Company:        toCompanyProfileDTO(&statement.Company),
+		Customer:       toCustomerDTO(&statement.Customer),
+		From:           statement.From,
+		To:             statement.To,
+		OpeningBalance: statement.OpeningBalance,
+		Lines:          make([]dto.StatementLineDTO, len(statement.Lines)),
+		TotalDebit:     statement.TotalDebit,
+		TotalCredit:    statement.TotalCredit,
+		ClosingBalance: statement.ClosingBalance,
+	}
+	for i, l := range statement.Lines {
+		resp.Lines[i] = dto.StatementLineDTO(l)
+	}
+	return c.JSON(resp)
+}
+
+func statementFilename(st *domain.CustomerStatement, ext string) string {
+	date := time.Now()
+	if st.To != nil {
+		date = st.To.AddDate(0, 0, -1)
+	}
+	return fmt.Sprintf("statement-%s-%s.%s", st.Customer.ID, date.Format(dateLayout), ext)
+}
+
+func sendStatementCSV(c *fiber.Ctx, st *domain.CustomerStatement) error {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	_ = w.Write([]string{"date", "type", "document_number", "description", "due_date", "debit", "credit", "balance"})
+	_ = w.Write([]string{"", "OPENING", "", "", "", "", "", st.OpeningBalance.StringFixed(2)})
+	for _, l := range st.Lines {
+		dueDate := ""
+		if l.DueDate != nil {
+			dueDate = l.DueDate.Format(dateLayout)
+		}
+		_ = w.Write([]string{
+			l.Date.Format(dateLayout),
+			l.Type,
+			l.DocumentNumber,
+			l.Description,
+			dueDate,
+			l.Debit.StringFixed(2),
+			l.Credit.StringFixed(2),
+			l.Balance.StringFixed(2),
+		})
+	}
+	_ = w.Write([]string{"", "CLOSING", "", "", "", st.TotalDebit.StringFixed(2), st.TotalCredit.StringFixed(2), st.ClosingBalance.StringFixed(2)})
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s"`, statementFilename(st, "csv")))
+	return c.Send(buf.Bytes())
+}
@@ -0,0 +1,190 @@
+package handler
+
+import (
+	"sancaksoft/internal/api/dto"
+	"sancaksoft/internal/api/middleware"
+	"sancaksoft/internal/domain"
+	"sancaksoft/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type DeliveryNoteHandler struct {
+	service *service.DeliveryNoteService
+}
+
+func NewDeliveryNoteHandler(s *service.DeliveryNoteService) *DeliveryNoteHandler {
+	return &DeliveryNoteHandler{service: s}
+}
+
+func toDeliveryNoteDTO(n *domain.DeliveryNote) dto.DeliveryNoteResponseDTO {
+	resp := dto.DeliveryNoteResponseDTO{
+		ID:          n.ID,
+		NoteNumber:  n.NoteNumber,
+		CustomerID:  n.CustomerID,
+		WarehouseID: n.WarehouseID,
+		Status:      n.Status,
+		Note:        n.Note,
+		TotalAmount: n.TotalAmount,
+		InvoiceID:   n.InvoiceID,
+		ShippedAt:   n.ShippedAt,
+		CreatedAt:   n.CreatedAt,
+		UpdatedAt:   n.UpdatedAt,
+	}
+	for _, it := range n.Items {
+		resp.Items = append(resp.Items, dto.DeliveryNoteItemResponseDTO{
+			ID:        it.ID,
+			ProductID: it.ProductID,
+			Quantity:  it.Quantity,
+			UnitPrice: it.UnitPrice,
+			Total:     it.Total,
+		})
+	}
+	return resp
+}
+
+// CreateDeliveryNote handles POST /delivery-notes
+func (h *DeliveryNoteHandler) CreateDeliveryNote(c *fiber.Ctx) error {
+	var reqDTO dto.CreateDeliveryNoteRequestDTO
+	if err := c.BodyParser(&reqDTO); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body", "details": err.Error()})
+	}
+
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+	userID, _ := c.Locals(middleware.LocalsUserID).(uuid.UUID)
+
+	items := make([]domain.DeliveryNoteItemRequest, len(reqDTO.Items))
+	for i, item := range reqDTO.Items {
+		items[i] = domain.DeliveryNoteItemRequest{
+			ProductID:     item.ProductID,
+			Quantity:      item.Quantity,
+			UnitPrice:     item.UnitPrice,
+			LotNumber:     item.LotNumber,
+			SerialNumbers: item.SerialNumbers,
+		}
+	}
+
+	note, err := h.service.CreateDeliveryNote(c.Context(), domain.CreateDeliveryNoteRequest{
+		TenantID:    tenantID,
+		UserID:      userID,
+		CustomerID:  reqDTO.CustomerID,
+		WarehouseID: reqDTO.WarehouseID,
+		Note:        reqDTO.Note,
+		Items:       items,
+
+		CreditOverrideReason: reqDTO.CreditOverrideReason,
+	})
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(fiber.StatusCreated).JSON(toDeliveryNoteDTO(note))
+}
+
+// ListDeliveryNotes handles GET /delivery-notes?customer_id=&status=
+func (h *DeliveryNoteHandler) ListDeliveryNotes(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+	customerID, err := parseOptionalUUID(c, "customer_id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	notes, err := h.service.ListDeliveryNotes(c.Context(), tenantID, customerID, domain.DeliveryNoteStatus(c.Query("status")))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	resp := make([]dto.DeliveryNoteResponseDTO, len(notes))
+	for i := range notes {
+		resp[i] = toDeliveryNoteDTO(&notes[i])
+	}
+	return c.JSON(resp)
+}
+
+// GetDeliveryNote handles GET /delivery-notes/:id
+func (h *DeliveryNoteHandler) GetDeliveryNote(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+	noteID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid delivery note id"})
+	}
+
+	note, err := h.service.GetDeliveryNote(c.Context(), tenantID, noteID)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(toDeliveryNoteDTO(note))
+}
+
+// CancelDeliveryNote handles POST /delivery-notes/:id/cancel
+func (h *DeliveryNoteHandler) CancelDeliveryNote(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+	noteID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid delivery note id"})
+	}
+
+	note, err := h.service.CancelDeliveryNote(c.Context(), tenantID, noteID)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(toDeliveryNoteDTO(note))
+}
+
+// InvoiceDeliveryNotes handles POST /delivery-notes/invoice
+func (h *DeliveryNoteHandler) InvoiceDeliveryNotes(c *fiber.Ctx) error {
+	var reqDTO dto.InvoiceDeliveryNotesRequestDTO
+	if err := c.BodyParser(&reqDTO); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body", "details": err.Error()})
+	}
+	if reqDTO.CustomerID == uuid.Nil || reqDTO.IdempotencyKey == uuid.Nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "customer_id and idempotency_key are required"})
+	}
+
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+	userID, _ := c.Locals(middleware.LocalsUserID).(uuid.UUID)
+
+	invoice, err := h.service.InvoiceDeliveryNotes(c.Context(), domain.InvoiceDeliveryNotesRequest{
+		TenantID:        tenantID,
+		UserID:          userID,
+		CustomerID:      reqDTO.CustomerID,
+		DeliveryNoteIDs: reqDTO.DeliveryNoteIDs,
+		IdempotencyKey:  reqDTO.IdempotencyKey,
+	})
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(dto.InvoiceResponseDTO{
+		ID:            invoice.ID,
+		InvoiceNumber: invoice.InvoiceNumber,
+		TotalAmount:   invoice.TotalAmount,
+		DueDate:       invoice.DueDate,
+		CreatedAt:     invoice.CreatedAt,
+		Status:        "created",
+	})
+}
+
+// GetUninvoicedReport handles GET /delivery-notes/uninvoiced?customer_id=&from=&to=
+func (h *DeliveryNoteHandler) GetUninvoicedReport(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+	customerID, err := parseOptionalUUID(c, "customer_id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	from, to, err := parseDateRange(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	lines, err := h.service.GetUninvoicedReport(c.Context(), tenantID, customerID, from, to)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	resp := make([]dto.UninvoicedDeliveryLineDTO, len(lines))
+	for i, l := range lines {
+		resp[i] = dto.UninvoicedDeliveryLineDTO(l)
+	}
+	return c.JSON(resp)
+}
@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"fmt"
+
+	"sancaksoft/internal/api/dto"
+	"sancaksoft/internal/api/middleware"
+	"sancaksoft/internal/domain"
+	"sancaksoft/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type EInvoiceHandler struct {
+	service *service.EInvoiceService
+}
+
+func NewEInvoiceHandler(s *service.EInvoiceService) *EInvoiceHandler {
+	return &EInvoiceHandler{service: s}
+}
+
+// GenerateEInvoice handles POST /invoices/:id/e-invoice
+func (h *EInvoiceHandler) GenerateEInvoice(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+
+	invoiceID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid invoice id"})
+	}
+
+	var reqDTO dto.GenerateEInvoiceRequestDTO
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&reqDTO); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body", "details": err.Error()})
+		}
+	}
+
+	e, err := h.service.Generate(c.Context(), domain.GenerateEInvoiceRequest{
+		TenantID:  tenantID,
+		InvoiceID: invoiceID,
+		Profile:   domain.EInvoiceProfile(reqDTO.Profile),
+	})
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(fiber.StatusCreated).JSON(toEInvoiceDTO(e))
+}
+
+// GetEInvoice handles GET /invoices/:id/e-invoice
+func (h *EInvoiceHandler) GetEInvoice(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+
+	invoiceID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid invoice id"})
+	}
+
+	e, err := h.service.Get(c.Context(), tenantID, invoiceID)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(toEInvoiceDTO(e))
+}
+
+// GetEInvoiceXML handles GET /invoices/:id/e-invoice/xml
+func (h *EInvoiceHandler) GetEInvoiceXML(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+
+	invoiceID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid invoice id"})
+	}
+
+	e, err := h.service.Get(c.Context(), tenantID, invoiceID)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	c.Set(fiber.HeaderContentType, "application/xml; charset=utf-8")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s.xml"`, e.DocumentNumber))
+	return c.Send(e.XML)
+}
+
+// SendEInvoice handles POST /invoices/:id/e-invoice/send
+func (h *EInvoiceHandler) SendEInvoice(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+
+	invoiceID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid invoice id"})
+	}
+
+	e, err := h.service.Send(c.Context(), tenantID, invoiceID)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(toEInvoiceDTO(e))
+}
+
+func toEInvoiceDTO(e *domain.EInvoice) dto.EInvoiceResponseDTO {
+	return dto.EInvoiceResponseDTO{
+		ID:                e.ID,
+		InvoiceID:         e.InvoiceID,
+		ETTN:              e.ETTN,
+		Profile:           string(e.Profile),
+		DocumentNumber:    e.DocumentNumber,
+		Status:            string(e.Status),
+		ProviderReference: e.ProviderReference,
+		StatusMessage:     e.StatusMessage,
+		SentAt:            e.SentAt,
+		CreatedAt:         e.CreatedAt,
+		UpdatedAt:         e.UpdatedAt,
+	}
+}
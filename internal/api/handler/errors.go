@@ -0,0 +1,23 @@
+package handler
+
+import (
+	"errors"
+
+	"sancaksoft/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// errorStatus maps service sentinel errors to HTTP status codes.
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, service.ErrInvalidInput), errors.Is(err, service.ErrInvalidState):
+		return fiber.StatusBadRequest
+	case errors.Is(err, service.ErrForbidden):
+		return fiber.StatusForbidden
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
@@ -0,0 +1,194 @@
+package handler
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"sancaksoft/internal/api/middleware"
+	"sancaksoft/internal/domain"
+	"sancaksoft/internal/service"
+	"sancaksoft/internal/spreadsheet"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+type ExportHandler struct {
+	service *service.ExportService
+}
+
+func NewExportHandler(s *service.ExportService) *ExportHandler {
+	return &ExportHandler{service: s}
+}
+
+// ExportInvoices handles GET /exports/invoices?customer_id=&warehouse_id=&from=&to=&format=csv|xlsx
+// with one row per invoice line.
+func (h *ExportHandler) ExportInvoices(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+	format, err := exportFormat(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	filter, err := parseInvoiceFilter(c, tenantID)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	export, err := h.service.InvoiceLines(filter)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	header := []any{"invoice_number", "date", "due_date", "customer", "tax_number", "warehouse", "sku", "product", "unit",
+		"quantity", "unit_price", "vat_rate", "base", "vat", "line_total", "invoice_total"}
+	return sendExport(c, format, "invoices", header, func(ctx context.Context, w spreadsheet.Writer) error {
+		return export(ctx, func(l domain.InvoiceExportLine) error {
+			return w.WriteRow(l.InvoiceNumber, l.CreatedAt.Format(dateLayout), l.DueDate.Format(dateLayout), l.CustomerName,
+				l.CustomerTaxNumber, l.WarehouseName, l.SKU, l.ProductName, l.Unit, l.Quantity, amount(l.UnitPrice),
+				amount(l.VATRate), amount(l.LineBase), amount(l.LineVAT), amount(l.LineTotal), amount(l.InvoiceTotal))
+		})
+	})
+}
+
+// ExportStockMovements handles GET /exports/stock-movements?product_id=&warehouse_id=&type=&from=&to=&format=csv|xlsx
+func (h *ExportHandler) ExportStockMovements(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+	format, err := exportFormat(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	filter, err := parseStockMovementFilter(c, tenantID)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	export, err := h.service.StockMovements(filter)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	header := []any{"date", "type", "sku", "product", "warehouse", "quantity", "unit_cost", "assigned_cost", "lot_number",
+		"reference_type", "document_number", "party"}
+	return sendExport(c, format, "stock-movements", header, func(ctx context.Context, w spreadsheet.Writer) error {
+		return export(ctx, func(m domain.StockMovementExportLine) error {
+			return w.WriteRow(m.CreatedAt.Format(dateLayout), string(m.Type), m.SKU, m.ProductName, m.WarehouseName, m.Quantity,
+				optionalAmount(m.UnitCost), optionalAmount(m.AssignedCost), optionalText(m.LotNumber),
+				optionalText(m.ReferenceType), optionalText(m.DocumentNumber), optionalText(m.PartyName))
+		})
+	})
+}
+
+// ExportStock handles GET /exports/stock?product_id=&warehouse_id=&format=csv|xlsx with the
+// current on-hand quantity of every product in every warehouse that holds it.
+func (h *ExportHandler) ExportStock(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+	format, err := exportFormat(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	filter := domain.WarehouseStockFilter{TenantID: tenantID}
+	if filter.ProductID, err = parseOptionalUUID(c, "product_id"); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	if filter.WarehouseID, err = parseOptionalUUID(c, "warehouse_id"); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	export, err := h.service.WarehouseStock(filter)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	header := []any{"warehouse", "sku", "product", "unit", "quantity"}
+	return sendExport(c, format, "stock", header, func(ctx context.Context, w spreadsheet.Writer) error {
+		return export(ctx, func(s domain.WarehouseStock) error {
+			return w.WriteRow(s.WarehouseName, s.SKU, s.ProductName, s.Unit, s.Quantity)
+		})
+	})
+}
+
+// ExportCustomerLedger handles GET /exports/customer-ledger?customer_id=&from=&to=&format=csv|xlsx
+// with the statement lines of one or all customers.
+func (h *ExportHandler) ExportCustomerLedger(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+	format, err := exportFormat(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	filter := domain.CustomerLedgerFilter{TenantID: tenantID}
+	if filter.CustomerID, err = parseOptionalUUID(c, "customer_id"); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	if filter.From, filter.To, err = parseDateRange(c); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	export, err := h.service.CustomerLedger(c.Context(), filter)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	header := []any{"customer", "tax_number", "date", "type", "document_number", "description", "due_date", "debit", "credit", "balance"}
+	return sendExport(c, format, "customer-ledger", header, func(ctx context.Context, w spreadsheet.Writer) error {
+		return export(ctx, func(l domain.CustomerLedgerLine) error {
+			dueDate := ""
+			if l.DueDate != nil {
+				dueDate = l.DueDate.Format(dateLayout)
+			}
+			return w.WriteRow(l.CustomerName, l.TaxNumber, l.Date.Format(dateLayout), l.Type, l.DocumentNumber, l.Description,
+				dueDate, amount(l.Debit), amount(l.Credit), amount(l.Balance))
+		})
+	})
+}
+
+// exportFormat reads ?format=csv|xlsx; CSV is the default.
+func exportFormat(c *fiber.Ctx) (string, error) {
+	switch format := c.Query("format", spreadsheet.FormatCSV); format {
+	case spreadsheet.FormatCSV, spreadsheet.FormatXLSX:
+		return format, nil
+	}
+	return "", fmt.Errorf("invalid format, expected csv or xlsx")
+}
+
+// sendExport streams a header row and the rows written by write as an attachment. The
+// response is committed before the first row, so a failure midway can only cut the file
+// short; it is logged. write runs after the handler has returned, when the request context
+// is gone, and gets a fresh one.
+func sendExport(c *fiber.Ctx, format, name string, header []any, write func(ctx context.Context, w spreadsheet.Writer) error) error {
+	c.Set(fiber.HeaderContentType, spreadsheet.ContentType(format))
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s-%s.%s"`, name, time.Now().Format(dateLayout), format))
+	c.Context().SetBodyStreamWriter(func(bw *bufio.Writer) {
+		w, err := spreadsheet.NewWriter(format, bw, name)
+		if err == nil {
+			err = w.WriteRow(header...)
+		}
+		if err == nil {
+			err = write(context.Background(), w)
+		}
+		if err == nil {
+			err = w.Close()
+		}
+		if err != nil {
+			log.Printf("export %s failed: %v", name, err)
+		}
+	})
+	return nil
+}
+
+func amount(d decimal.Decimal) spreadsheet.Number {
+	return spreadsheet.Number(d.StringFixed(2))
+}
+
+func optionalAmount(d *decimal.Decimal) any {
+	if d == nil {
+		return ""
+	}
+	return spreadsheet.Number(d.StringFixed(4))
+}
+
+func optionalText(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"io"
+
+	"sancaksoft/internal/api/middleware"
+	"sancaksoft/internal/domain"
+	"sancaksoft/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type ImportHandler struct {
+	service *service.ImportService
+}
+
+func NewImportHandler(s *service.ImportService) *ImportHandler {
+	return &ImportHandler{service: s}
+}
+
+// Import handles POST /imports/:kind with a multipart "file" field. Row errors return 422
+// with the report unless it is a dry run.
+func (h *ImportHandler) Import(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "file is required", "details": err.Error()})
+	}
+	f, err := header.Open()
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "failed to read file", "details": err.Error()})
+	}
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "failed to read file", "details": err.Error()})
+	}
+
+	report, err := h.service.Import(c.Context(), domain.ImportRequest{
+		TenantID:  tenantID,
+		Kind:      domain.ImportKind(c.Params("kind")),
+		FileName:  header.Filename,
+		Data:      data,
+		DryRun:    c.QueryBool("dry_run"),
+		ChunkSize: c.QueryInt("chunk_size"),
+		StartRow:  c.QueryInt("start_row"),
+	})
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	if !report.DryRun && len(report.Errors) > 0 {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(report)
+	}
+	return c.JSON(report)
+}
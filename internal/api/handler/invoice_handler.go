@@ -2,6 +2,7 @@ package handler
 
 import (
 	"errors"
+	"fmt"
 
 	"sancaksoft/internal/api/dto"
 	"sancaksoft/internal/api/middleware"
@@ -29,10 +30,14 @@ func NewInvoiceHandler(s *service.InvoiceService, ls *service.InvoiceListService
 // ListInvoices handles GET /invoices
 func (h *InvoiceHandler) ListInvoices(c *fiber.Ctx) error {
 	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+	filter, err := parseInvoiceFilter(c, tenantID)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
 
-	invoices, err := h.listService.ListInvoices(c.Context(), tenantID)
+	invoices, err := h.listService.ListInvoices(c.Context(), filter)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
 	}
 
 	return c.JSON(invoices)
@@ -58,9 +63,11 @@ func (h *InvoiceHandler) CreateInvoice(c *fiber.Ctx) error {
 	domainItems := make([]domain.InvoiceItemRequest, len(reqDTO.Items))
 	for i, item := range reqDTO.Items {
 		domainItems[i] = domain.InvoiceItemRequest{
-			ProductID: item.ProductID,
-			Quantity:  item.Quantity,
-			UnitPrice: item.UnitPrice,
+			ProductID:     item.ProductID,
+			Quantity:      item.Quantity,
+			UnitPrice:     item.UnitPrice,
+			LotNumber:     item.LotNumber,
+			SerialNumbers: item.SerialNumbers,
 		}
 	}
 
@@ -71,14 +78,15 @@ func (h *InvoiceHandler) CreateInvoice(c *fiber.Ctx) error {
 		CustomerID:     reqDTO.CustomerID,
 		IdempotencyKey: reqDTO.IdempotencyKey,
 		Items:          domainItems,
+
+		CreditOverrideReason: reqDTO.CreditOverrideReason,
 	}
 
 	// 4. Call Service
 	invoice, err := h.service.CreateInvoice(c.Context(), domainReq)
 	if err != nil {
-		// Differentiate errors (e.g. insufficient stock vs internal error)
-		// For MVP, generic 500 or 400 based on message check (fragile but works for now)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		// Credit holds and overrides are mapped by sentinel; other failures are still 500
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
 			"error": err.Error(),
 		})
 	}
@@ -88,6 +96,7 @@ func (h *InvoiceHandler) CreateInvoice(c *fiber.Ctx) error {
 		ID:            invoice.ID,
 		InvoiceNumber: invoice.InvoiceNumber,
 		TotalAmount:   invoice.TotalAmount,
+		DueDate:       invoice.DueDate,
 		CreatedAt:     invoice.CreatedAt,
 		Status:        "created",
 	}
@@ -135,3 +144,30 @@ func (h *InvoiceHandler) GetInvoiceDetail(c *fiber.Ctx) error {
 
 	return c.JSON(resp)
 }
+
+// GetInvoicePDF handles GET /invoices/:id/pdf
+func (h *InvoiceHandler) GetInvoicePDF(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+
+	invoiceID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid invoice id"})
+	}
+
+	doc, err := h.listService.GetInvoiceDocument(c.Context(), tenantID, invoiceID)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	out, err := renderInvoicePDF(doc)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	disposition := "inline"
+	if c.QueryBool("download") {
+		disposition = "attachment"
+	}
+	c.Set(fiber.HeaderContentType, "application/pdf")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`%s; filename="%s.pdf"`, disposition, doc.Invoice.InvoiceNumber))
+	return c.Send(out)
+}
@@ -0,0 +1,230 @@
+package handler
+
+import (
+	"fmt"
+	"strings"
+
+	"sancaksoft/internal/domain"
+	"sancaksoft/internal/pdf"
+
+	"github.com/shopspring/decimal"
+)
+
+var white = pdf.Color{R: 1, G: 1, B: 1}
+
+var unitLabels = map[string]string{
+	"adet": "Adet",
+	"kg":   "Kg",
+}
+
+// invoiceColumns are the x positions of the line table: left edges for text, right
+// edges for numbers. Without the SKU column the description takes its place.
+type invoiceColumns struct {
+	no, sku, name, quantity, unit, price, vat, total float64
+}
+
+func newInvoiceColumns(showSKU bool) invoiceColumns {
+	cols := invoiceColumns{no: pdfMargin + 4, sku: 66, name: 140, quantity: 340, unit: 346, price: 430, vat: 468, total: pdf.PageWidth - pdfMargin - 4}
+	if !showSKU {
+		cols.name = cols.sku
+	}
+	return cols
+}
+
+func drawInvoiceTableHeader(doc *pdf.Document, cols invoiceColumns, showSKU bool, accent pdf.Color, y float64) float64 {
+	doc.FillRect(pdfMargin, y, pdf.PageWidth-2*pdfMargin, 16, accent)
+	doc.SetTextColor(white)
+	baseline := y + 11
+	doc.Text(cols.no, baseline, pdf.Bold, 8, "Sıra")
+	if showSKU {
+		doc.Text(cols.sku, baseline, pdf.Bold, 8, "Stok Kodu")
+	}
+	doc.Text(cols.name, baseline, pdf.Bold, 8, "Mal / Hizmet")
+	doc.TextRight(cols.quantity, baseline, pdf.Bold, 8, "Miktar")
+	doc.Text(cols.unit, baseline, pdf.Bold, 8, "Birim")
+	doc.TextRight(cols.price, baseline, pdf.Bold, 8, "Birim Fiyat")
+	doc.TextRight(cols.vat, baseline, pdf.Bold, 8, "KDV %")
+	doc.TextRight(cols.total, baseline, pdf.Bold, 8, "Tutar")
+	doc.SetTextColor(pdf.Black)
+	return y + 16
+}
+
+// formatRateTR formats a percentage without trailing zeros: 20, 0,5
+func formatRateTR(rate decimal.Decimal) string {
+	return strings.Replace(rate.String(), ".", ",", 1)
+}
+
+// taxIDLabel names a tax number: VKN for companies (10 digits), TCKN for individuals.
+func taxIDLabel(taxNumber string) string {
+	if len(taxNumber) == 11 {
+		return "TCKN"
+	}
+	return "VKN"
+}
+
+// renderInvoicePDF lays out an invoice on A4 pages using the tenant's template: letterhead
+// and invoice details, the customer with tax details, the lines, the VAT breakdown and
+// the total in words.
+func renderInvoicePDF(inv *domain.InvoiceDocument) ([]byte, error) {
+	tpl := inv.Template
+	accent := parseHexColor(tpl.AccentColor)
+	cols := newInvoiceColumns(tpl.ShowSKU)
+	right := pdf.PageWidth - pdfMargin
+
+	doc := pdf.New()
+	drawPageNumber := func() {
+		doc.TextRight(right, pdf.PageHeight-30, pdf.Regular, 7, fmt.Sprintf("Sayfa %d", doc.PageCount()))
+	}
+	newPage := func() float64 {
+		drawPageNumber()
+		doc.AddPage()
+		doc.Text(pdfMargin, 40, pdf.Bold, 8, inv.Company.Name)
+		doc.TextRight(right, 40, pdf.Regular, 8, tpl.Title+" "+inv.Invoice.InvoiceNumber)
+		return 50
+	}
+
+	// Letterhead and invoice details
+	y := drawLetterhead(doc, &inv.Company)
+	doc.SetTextColor(accent)
+	doc.TextRight(right, 52, pdf.Bold, 16, tpl.Title)
+	doc.SetTextColor(pdf.Black)
+	details := [][2]string{
+		{"Fatura No:", inv.Invoice.InvoiceNumber},
+		{"Fatura Tarihi:", inv.Invoice.CreatedAt.Format(pdfDateLayout)},
+	}
+	if tpl.ShowDueDate {
+		details = append(details, [2]string{"Vade Tarihi:", inv.Invoice.DueDate.Format(pdfDateLayout)})
+	}
+	dy := 68.0
+	for _, d := range details {
+		doc.TextRight(right-90, dy, pdf.Bold, 8, d[0])
+		doc.TextRight(right, dy, pdf.Regular, 8, d[1])
+		dy += 11
+	}
+	if y < dy {
+		y = dy
+	}
+	y += 8
+
+	// Customer
+	customer := []string{}
+	customer = append(customer, pdf.Wrap(pdf.Regular, 8, 250, inv.Customer.Address)...)
+	if place := placeName(inv.Customer.District, inv.Customer.City); place != "" {
+		customer = append(customer, place)
+	}
+	if inv.Customer.TaxOffice != "" || inv.Customer.TaxNumber != "" {
+		customer = append(customer, fmt.Sprintf("V.D.: %s  %s: %s", inv.Customer.TaxOffice, taxIDLabel(inv.Customer.TaxNumber), inv.Customer.TaxNumber))
+	}
+	var contact []string
+	if inv.Customer.Phone != "" {
+		contact = append(contact, "Tel: "+inv.Customer.Phone)
+	}
+	if inv.Customer.Email != "" {
+		contact = append(contact, inv.Customer.Email)
+	}
+	if len(contact) > 0 {
+		customer = append(customer, strings.Join(contact, "  "))
+	}
+	boxHeight := 32 + 10*float64(len(customer))
+	doc.StrokeRect(pdfMargin, y, 270, boxHeight, 0.5)
+	doc.Text(pdfMargin+8, y+12, pdf.Bold, 7, "SAYIN")
+	doc.Text(pdfMargin+8, y+24, pdf.Bold, 9, inv.Customer.Name)
+	for i, line := range customer {
+		doc.Text(pdfMargin+8, y+36+10*float64(i), pdf.Regular, 8, line)
+	}
+	y += boxHeight + 16
+
+	// Lines
+	y = drawInvoiceTableHeader(doc, cols, tpl.ShowSKU, accent, y)
+	for i, l := range inv.Lines {
+		name := pdf.Wrap(pdf.Regular, 8, cols.quantity-cols.name-40, l.ProductName)
+		height := 6 + 10*float64(len(name))
+		if y+height > pdfBottom {
+			y = drawInvoiceTableHeader(doc, cols, tpl.ShowSKU, accent, newPage())
+		}
+		b := y + 10
+		doc.Text(cols.no, b, pdf.Regular, 8, fmt.Sprint(i+1))
+		if tpl.ShowSKU {
+			doc.Text(cols.sku, b, pdf.Regular, 8, l.SKU)
+		}
+		for j, line := range name {
+			doc.Text(cols.name, b+10*float64(j), pdf.Regular, 8, line)
+		}
+		doc.TextRight(cols.quantity, b, pdf.Regular, 8, fmt.Sprint(l.Quantity))
+		unit := unitLabels[l.Unit]
+		if unit == "" {
+			unit = l.Unit
+		}
+		doc.Text(cols.unit, b, pdf.Regular, 8, unit)
+		// Prices exclude VAT so the lines add up to Mal / Hizmet Toplamı, as in the UBL
+		unitBase := l.Base.Div(decimal.NewFromInt(int64(max(l.Quantity, 1))))
+		doc.TextRight(cols.price, b, pdf.Regular, 8, formatAmountTR(unitBase))
+		doc.TextRight(cols.vat, b, pdf.Regular, 8, formatRateTR(l.VATRate))
+		doc.TextRight(cols.total, b, pdf.Regular, 8, formatAmountTR(l.Base))
+		y += height
+		doc.Line(pdfMargin, y, right, y, 0.2)
+	}
+
+	// Totals, with the amount in words beside them
+	totals := [][2]string{{"Mal / Hizmet Toplamı", formatAmountTR(inv.Subtotal)}}
+	for _, v := range inv.VAT {
+		totals = append(totals, [2]string{fmt.Sprintf("Hesaplanan KDV (%%%s)", formatRateTR(v.Rate)), formatAmountTR(v.Amount)})
+	}
+	totals = append(totals, [2]string{"Vergiler Dahil Toplam", formatAmountTR(inv.Total)})
+	words := pdf.Wrap(pdf.Bold, 8, 250, amountInWordsTR(inv.Total))
+	bank := pdf.Wrap(pdf.Regular, 8, 250, tpl.BankDetails)
+	if tpl.BankDetails == "" {
+		bank = nil
+	}
+	needed := 12 + 14*float64(len(totals)+1)
+	if side := 12 + 10*float64(len(words)+len(bank)+1); side > needed {
+		needed = side
+	}
+	if y+needed > pdfBottom {
+		y = newPage()
+	}
+	y += 12
+
+	labelX := 360.0
+	ty := y
+	for _, t := range totals {
+		doc.Text(labelX, ty+10, pdf.Regular, 8, t[0])
+		doc.TextRight(cols.total, ty+10, pdf.Regular, 8, t[1])
+		ty += 14
+	}
+	doc.FillRect(labelX-4, ty, right-labelX+4, 16, accent)
+	doc.SetTextColor(white)
+	doc.Text(labelX, ty+11, pdf.Bold, 9, "Ödenecek Tutar")
+	doc.TextRight(cols.total, ty+11, pdf.Bold, 9, formatAmountTR(inv.Total)+" TL")
+	doc.SetTextColor(pdf.Black)
+
+	wy := y + 10
+	for _, line := range words {
+		doc.Text(pdfMargin, wy, pdf.Bold, 8, line)
+		wy += 10
+	}
+	if len(bank) > 0 {
+		wy += 6
+		doc.Text(pdfMargin, wy, pdf.Bold, 8, "Banka Bilgileri")
+		wy += 10
+		for _, line := range bank {
+			doc.Text(pdfMargin, wy, pdf.Regular, 8, line)
+			wy += 10
+		}
+	}
+
+	if tpl.FooterNote != "" {
+		lines := pdf.Wrap(pdf.Regular, 7, right-pdfMargin, tpl.FooterNote)
+		// The note sits at the bottom of the last page
+		fy := pdfBottom - 9*float64(len(lines)-1)
+		if max(ty+16, wy) > fy-10 {
+			newPage()
+		}
+		for _, line := range lines {
+			doc.Text(pdfMargin, fy, pdf.Regular, 7, line)
+			fy += 9
+		}
+	}
+	drawPageNumber()
+	return doc.Bytes()
+}
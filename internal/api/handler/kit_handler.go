@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"sancaksoft/internal/api/dto"
+	"sancaksoft/internal/api/middleware"
+	"sancaksoft/internal/domain"
+	"sancaksoft/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type KitHandler struct {
+	service *service.KitService
+}
+
+func NewKitHandler(s *service.KitService) *KitHandler {
+	return &KitHandler{service: s}
+}
+
+func toKitComponentDTOs(components []domain.KitComponent) []dto.KitComponentDTO {
+	resp := make([]dto.KitComponentDTO, len(components))
+	for i, c := range components {
+		resp[i] = dto.KitComponentDTO{
+			ComponentID:   c.ComponentID,
+			ComponentName: c.ComponentName,
+			SKU:           c.SKU,
+			Price:         c.Price,
+			Quantity:      c.Quantity,
+		}
+	}
+	return resp
+}
+
+// GetComponents handles GET /products/:id/kit
+func (h *KitHandler) GetComponents(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+	kitID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid product id"})
+	}
+
+	components, err := h.service.GetComponents(c.Context(), tenantID, kitID)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(toKitComponentDTOs(components))
+}
+
+// SetComponents handles PUT /products/:id/kit
+func (h *KitHandler) SetComponents(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+	kitID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid product id"})
+	}
+
+	var reqDTO dto.SetKitComponentsRequestDTO
+	if err := c.BodyParser(&reqDTO); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body", "details": err.Error()})
+	}
+
+	components := make([]domain.KitComponent, len(reqDTO.Components))
+	for i, comp := range reqDTO.Components {
+		components[i] = domain.KitComponent{KitID: kitID, ComponentID: comp.ComponentID, Quantity: comp.Quantity}
+	}
+	components, err = h.service.SetComponents(c.Context(), tenantID, kitID, components)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(toKitComponentDTOs(components))
+}
+
+// GetAvailability handles GET /products/:id/kit-availability?warehouse_id=
+func (h *KitHandler) GetAvailability(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+	kitID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid product id"})
+	}
+	warehouseID, err := parseOptionalUUID(c, "warehouse_id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	if warehouseID == nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "warehouse_id query parameter is required"})
+	}
+
+	availability, err := h.service.GetAvailability(c.Context(), tenantID, kitID, *warehouseID)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	resp := dto.KitAvailabilityDTO{
+		KitID:       availability.KitID,
+		WarehouseID: availability.WarehouseID,
+		Available:   availability.Available,
+		Components:  make([]dto.KitComponentAvailabilityDTO, len(availability.Components)),
+	}
+	for i, comp := range availability.Components {
+		resp.Components[i] = dto.KitComponentAvailabilityDTO(comp)
+	}
+	return c.JSON(resp)
+}
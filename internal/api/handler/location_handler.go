@@ -0,0 +1,243 @@
+package handler
+
+import (
+	"sancaksoft/internal/api/dto"
+	"sancaksoft/internal/api/middleware"
+	"sancaksoft/internal/domain"
+	"sancaksoft/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type LocationHandler struct {
+	service *service.LocationService
+}
+
+func NewLocationHandler(s *service.LocationService) *LocationHandler {
+	return &LocationHandler{service: s}
+}
+
+func toLocationDTO(loc domain.WarehouseLocation) dto.LocationResponseDTO {
+	return dto.LocationResponseDTO{
+		ID:          loc.ID,
+		WarehouseID: loc.WarehouseID,
+		ParentID:    loc.ParentID,
+		Code:        loc.Code,
+		Path:        loc.Path,
+		IsActive:    loc.IsActive,
+		CreatedAt:   loc.CreatedAt,
+	}
+}
+
+func toPickListDTO(list *domain.PickList) dto.PickListDTO {
+	resp := dto.PickListDTO{
+		DocumentType:   list.DocumentType,
+		DocumentID:     list.DocumentID,
+		DocumentNumber: list.DocumentNumber,
+		WarehouseID:    list.WarehouseID,
+		Lines:          make([]dto.PickListLineDTO, len(list.Lines)),
+	}
+	for i, l := range list.Lines {
+		resp.Lines[i] = dto.PickListLineDTO{
+			LocationID:   l.LocationID,
+			LocationPath: l.LocationPath,
+			ProductID:    l.ProductID,
+			ProductName:  l.ProductName,
+			SKU:          l.SKU,
+			LotNumber:    l.LotNumber,
+			Quantity:     l.Quantity,
+		}
+	}
+	return resp
+}
+
+// ListLocations handles GET /warehouses/:id/locations
+func (h *LocationHandler) ListLocations(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+	warehouseID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid warehouse id"})
+	}
+
+	locations, err := h.service.ListLocations(c.Context(), tenantID, warehouseID)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	resp := make([]dto.LocationResponseDTO, len(locations))
+	for i, loc := range locations {
+		resp[i] = toLocationDTO(loc)
+	}
+	return c.JSON(resp)
+}
+
+// CreateLocation handles POST /warehouses/:id/locations
+func (h *LocationHandler) CreateLocation(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+	warehouseID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid warehouse id"})
+	}
+
+	var reqDTO dto.CreateLocationRequestDTO
+	if err := c.BodyParser(&reqDTO); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body", "details": err.Error()})
+	}
+
+	loc := &domain.WarehouseLocation{
+		TenantID:    tenantID,
+		WarehouseID: warehouseID,
+		ParentID:    reqDTO.ParentID,
+		Code:        reqDTO.Code,
+	}
+	if err := h.service.CreateLocation(c.Context(), loc); err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(fiber.StatusCreated).JSON(toLocationDTO(*loc))
+}
+
+// UpdateLocation handles PUT /locations/:id
+func (h *LocationHandler) UpdateLocation(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+	locationID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid location id"})
+	}
+
+	var reqDTO dto.UpdateLocationRequestDTO
+	if err := c.BodyParser(&reqDTO); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body", "details": err.Error()})
+	}
+
+	if err := h.service.SetLocationActive(c.Context(), tenantID, locationID, reqDTO.IsActive); err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"location_id": locationID, "is_active": reqDTO.IsActive})
+}
+
+// ListLocationBalances handles GET /warehouses/:id/location-stock?product_id=
+func (h *LocationHandler) ListLocationBalances(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+	warehouseID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid warehouse id"})
+	}
+	productID, err := parseOptionalUUID(c, "product_id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	balances, err := h.service.ListLocationBalances(c.Context(), tenantID, warehouseID, productID)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	resp := make([]dto.LocationBalanceDTO, len(balances))
+	for i, b := range balances {
+		resp[i] = dto.LocationBalanceDTO{
+			LocationID:   b.LocationID,
+			LocationPath: b.LocationPath,
+			ProductID:    b.ProductID,
+			ProductName:  b.ProductName,
+			SKU:          b.SKU,
+			Quantity:     b.Quantity,
+		}
+	}
+	return c.JSON(resp)
+}
+
+// TransferStock handles POST /warehouses/:id/location-transfers
+func (h *LocationHandler) TransferStock(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+	userID, _ := c.Locals(middleware.LocalsUserID).(uuid.UUID)
+	warehouseID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid warehouse id"})
+	}
+
+	var reqDTO dto.LocationTransferRequestDTO
+	if err := c.BodyParser(&reqDTO); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body", "details": err.Error()})
+	}
+
+	transfer := &domain.LocationTransfer{
+		TenantID:       tenantID,
+		WarehouseID:    warehouseID,
+		ProductID:      reqDTO.ProductID,
+		FromLocationID: reqDTO.FromLocationID,
+		ToLocationID:   reqDTO.ToLocationID,
+		Quantity:       reqDTO.Quantity,
+		UserID:         userID,
+	}
+	if err := h.service.TransferStock(c.Context(), transfer); err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(fiber.StatusCreated).JSON(dto.LocationTransferResponseDTO{
+		ID:             transfer.ID,
+		WarehouseID:    transfer.WarehouseID,
+		ProductID:      transfer.ProductID,
+		FromLocationID: transfer.FromLocationID,
+		ToLocationID:   transfer.ToLocationID,
+		Quantity:       transfer.Quantity,
+		CreatedAt:      transfer.CreatedAt,
+	})
+}
+
+// SuggestPutaway handles GET /purchase-orders/:id/putaway?warehouse_id=
+func (h *LocationHandler) SuggestPutaway(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+	orderID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid purchase order id"})
+	}
+	warehouseID, err := parseOptionalUUID(c, "warehouse_id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	suggestions, err := h.service.SuggestPutaway(c.Context(), tenantID, orderID, warehouseID)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	resp := make([]dto.PutawaySuggestionDTO, len(suggestions))
+	for i, s := range suggestions {
+		resp[i] = dto.PutawaySuggestionDTO{
+			OrderItemID:  s.OrderItemID,
+			ProductID:    s.ProductID,
+			Quantity:     s.Quantity,
+			LocationID:   s.LocationID,
+			LocationPath: s.LocationPath,
+			Reason:       s.Reason,
+		}
+	}
+	return c.JSON(resp)
+}
+
+// GetInvoicePickList handles GET /invoices/:id/pick-list
+func (h *LocationHandler) GetInvoicePickList(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+	invoiceID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid invoice id"})
+	}
+
+	list, err := h.service.GetInvoicePickList(c.Context(), tenantID, invoiceID)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(toPickListDTO(list))
+}
+
+// GetSalesOrderPickList handles GET /sales-orders/:id/pick-list
+func (h *LocationHandler) GetSalesOrderPickList(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+	orderID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid sales order id"})
+	}
+
+	list, err := h.service.GetSalesOrderPickList(c.Context(), tenantID, orderID)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(toPickListDTO(list))
+}
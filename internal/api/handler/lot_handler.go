@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"strconv"
+
+	"sancaksoft/internal/api/dto"
+	"sancaksoft/internal/api/middleware"
+	"sancaksoft/internal/domain"
+	"sancaksoft/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// defaultExpiryDays is the near-expiry window when the request does not set days.
+const defaultExpiryDays = 30
+
+type LotHandler struct {
+	service *service.LotService
+}
+
+func NewLotHandler(s *service.LotService) *LotHandler {
+	return &LotHandler{service: s}
+}
+
+func toLotBalanceDTO(b domain.LotBalance) dto.LotBalanceDTO {
+	return dto.LotBalanceDTO{
+		LotID:         b.LotID,
+		LotNumber:     b.LotNumber,
+		ExpiryDate:    formatOptionalDate(b.ExpiryDate),
+		DaysToExpiry:  b.DaysToExpiry,
+		ProductID:     b.ProductID,
+		ProductName:   b.ProductName,
+		SKU:           b.SKU,
+		WarehouseID:   b.WarehouseID,
+		WarehouseName: b.WarehouseName,
+		Quantity:      b.Quantity,
+	}
+}
+
+// ListLotBalances handles GET /lots?product_id=&warehouse_id=
+func (h *LotHandler) ListLotBalances(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+
+	productID, err := parseOptionalUUID(c, "product_id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	warehouseID, err := parseOptionalUUID(c, "warehouse_id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	balances, err := h.service.ListLotBalances(c.Context(), tenantID, productID, warehouseID)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	resp := make([]dto.LotBalanceDTO, len(balances))
+	for i, b := range balances {
+		resp[i] = toLotBalanceDTO(b)
+	}
+	return c.JSON(resp)
+}
+
+// GetExpiryReport handles GET /lots/expiry?days=30&warehouse_id=&status=
+func (h *LotHandler) GetExpiryReport(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+
+	days := defaultExpiryDays
+	if v := c.Query("days"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "days must be a whole number"})
+		}
+		days = n
+	}
+	warehouseID, err := parseOptionalUUID(c, "warehouse_id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	lines, err := h.service.GetExpiryReport(c.Context(), tenantID, warehouseID, days, domain.LotExpiryStatus(c.Query("status")))
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	resp := dto.LotExpiryReportDTO{Days: days, Lines: make([]dto.LotExpiryLineDTO, len(lines))}
+	for i, l := range lines {
+		resp.Lines[i] = dto.LotExpiryLineDTO{LotBalanceDTO: toLotBalanceDTO(l.LotBalance), Status: l.Status}
+		resp.TotalQuantity += l.Quantity
+	}
+	return c.JSON(resp)
+}
+
+// TraceLot handles GET /lots/:id/trace
+func (h *LotHandler) TraceLot(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+	lotID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid lot id"})
+	}
+
+	trace, err := h.service.TraceLot(c.Context(), tenantID, lotID)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	resp := dto.LotTraceDTO{
+		LotID:        trace.Lot.ID,
+		LotNumber:    trace.Lot.LotNumber,
+		ExpiryDate:   formatOptionalDate(trace.Lot.ExpiryDate),
+		ProductID:    trace.Lot.ProductID,
+		ProductName:  trace.ProductName,
+		SKU:          trace.SKU,
+		TotalShipped: trace.TotalShipped,
+		Shipments:    make([]dto.LotShipmentDTO, len(trace.Shipments)),
+	}
+	for i, s := range trace.Shipments {
+		resp.Shipments[i] = dto.LotShipmentDTO(s)
+	}
+	return c.JSON(resp)
+}
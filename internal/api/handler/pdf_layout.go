@@ -0,0 +1,145 @@
+package handler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"sancaksoft/internal/domain"
+	"sancaksoft/internal/pdf"
+
+	"github.com/shopspring/decimal"
+)
+
+// Layout shared by the documents rendered for customers (statements, invoices).
+const (
+	pdfMargin     = 40.0
+	pdfBottom     = pdf.PageHeight - 50
+	pdfDateLayout = "02.01.2006"
+)
+
+var pdfHeaderFill = pdf.Color{R: 0.9, G: 0.9, B: 0.9}
+
+// formatAmountTR formats an amount the Turkish way: 1.234.567,89
+func formatAmountTR(d decimal.Decimal) string {
+	s := d.Abs().StringFixed(2)
+	whole, frac := s[:len(s)-3], s[len(s)-2:]
+	var sb strings.Builder
+	if d.IsNegative() {
+		sb.WriteByte('-')
+	}
+	for i, r := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			sb.WriteByte('.')
+		}
+		sb.WriteRune(r)
+	}
+	sb.WriteByte(',')
+	sb.WriteString(frac)
+	return sb.String()
+}
+
+// drawLetterhead prints the tenant's company details at the top-left of the page and
+// returns the y position below them.
+func drawLetterhead(doc *pdf.Document, company *domain.TenantProfile) float64 {
+	y := 50.0
+	doc.Text(pdfMargin, y, pdf.Bold, 13, company.Name)
+	y += 14
+	for _, line := range pdf.Wrap(pdf.Regular, 8, 260, company.Address) {
+		doc.Text(pdfMargin, y, pdf.Regular, 8, line)
+		y += 10
+	}
+	if place := placeName(company.District, company.City); place != "" {
+		doc.Text(pdfMargin, y, pdf.Regular, 8, place)
+		y += 10
+	}
+	var contact []string
+	if company.Phone != "" {
+		contact = append(contact, "Tel: "+company.Phone)
+	}
+	if company.Email != "" {
+		contact = append(contact, company.Email)
+	}
+	if len(contact) > 0 {
+		doc.Text(pdfMargin, y, pdf.Regular, 8, strings.Join(contact, "  "))
+		y += 10
+	}
+	if company.TaxOffice != "" || company.TaxNumber != "" {
+		doc.Text(pdfMargin, y, pdf.Regular, 8, fmt.Sprintf("V.D.: %s  VKN: %s", company.TaxOffice, company.TaxNumber))
+		y += 10
+	}
+	return y
+}
+
+// placeName joins a district and city as printed on addresses: Kadıköy / İstanbul
+func placeName(district, city string) string {
+	switch {
+	case district == "":
+		return city
+	case city == "":
+		return district
+	}
+	return district + " / " + city
+}
+
+// parseHexColor reads a #RRGGBB color; anything else is black.
+func parseHexColor(hex string) pdf.Color {
+	v, err := strconv.ParseUint(strings.TrimPrefix(hex, "#"), 16, 32)
+	if err != nil || len(hex) != 7 {
+		return pdf.Black
+	}
+	return pdf.Color{R: float64(v>>16&0xFF) / 255, G: float64(v>>8&0xFF) / 255, B: float64(v&0xFF) / 255}
+}
+
+var (
+	wordOnes   = []string{"", "bir", "iki", "üç", "dört", "beş", "altı", "yedi", "sekiz", "dokuz"}
+	wordTens   = []string{"", "on", "yirmi", "otuz", "kırk", "elli", "altmış", "yetmiş", "seksen", "doksan"}
+	wordGroups = []string{"", "bin", "milyon", "milyar", "trilyon"}
+)
+
+// numberInWordsTR spells out a non-negative integer in Turkish: 1250 is "bin iki yüz elli".
+func numberInWordsTR(n int64) string {
+	if n == 0 {
+		return "sıfır"
+	}
+	var groups []string
+	for g := 0; n > 0 && g < len(wordGroups); g++ {
+		part := n % 1000
+		n /= 1000
+		if part == 0 {
+			continue
+		}
+		var words []string
+		switch h := part / 100; {
+		case h == 1:
+			words = append(words, "yüz")
+		case h > 1:
+			words = append(words, wordOnes[h], "yüz")
+		}
+		if t := part / 10 % 10; t > 0 {
+			words = append(words, wordTens[t])
+		}
+		// One thousand is "bin", not "bir bin"
+		if o := part % 10; o > 0 && !(g == 1 && part == 1) {
+			words = append(words, wordOnes[o])
+		}
+		if g > 0 {
+			words = append(words, wordGroups[g])
+		}
+		groups = append([]string{strings.Join(words, " ")}, groups...)
+	}
+	return strings.Join(groups, " ")
+}
+
+// amountInWordsTR writes an amount the way it is printed under invoice totals:
+// "Yalnız bin iki yüz elli TL otuz Kr".
+func amountInWordsTR(d decimal.Decimal) string {
+	d = d.Abs().Round(2)
+	lira := d.IntPart()
+	kurus := d.Sub(decimal.NewFromInt(lira)).Mul(decimal.NewFromInt(100)).IntPart()
+	s := "Yalnız " + numberInWordsTR(lira) + " TL"
+	if kurus > 0 {
+		s += " " + numberInWordsTR(kurus) + " Kr"
+	}
+	return s
+}
@@ -0,0 +1,37 @@
+package handler
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatAmountTR(t *testing.T) {
+	assert.Equal(t, "0,00", formatAmountTR(decimal.Zero))
+	assert.Equal(t, "999,50", formatAmountTR(decimal.RequireFromString("999.5")))
+	assert.Equal(t, "1.234.567,89", formatAmountTR(decimal.RequireFromString("1234567.891")))
+	assert.Equal(t, "-1.000,00", formatAmountTR(decimal.NewFromInt(-1000)))
+}
+
+func TestAmountInWordsTR(t *testing.T) {
+	for n, words := range map[int64]string{
+		0:          "sıfır",
+		1:          "bir",
+		19:         "on dokuz",
+		100:        "yüz",
+		1000:       "bin",
+		1001:       "bin bir",
+		2500:       "iki bin beş yüz",
+		11000:      "on bir bin",
+		101001:     "yüz bir bin bir",
+		1000000:    "bir milyon",
+		1250340090: "bir milyar iki yüz elli milyon üç yüz kırk bin doksan",
+	} {
+		assert.Equal(t, words, numberInWordsTR(n), "%d", n)
+	}
+
+	assert.Equal(t, "Yalnız bin iki yüz otuz dört TL elli altı Kr", amountInWordsTR(decimal.RequireFromString("1234.56")))
+	assert.Equal(t, "Yalnız yüz TL", amountInWordsTR(decimal.NewFromInt(100)))
+	assert.Equal(t, "Yalnız sıfır TL beş Kr", amountInWordsTR(decimal.RequireFromString("0.05")))
+}
@@ -1,6 +1,8 @@
 package handler
 
 import (
+	"strings"
+
 	"sancaksoft/internal/api/dto"
 	"sancaksoft/internal/api/middleware"
 	"sancaksoft/internal/domain"
@@ -28,57 +30,136 @@ func (h *ProductHandler) CreateProduct(c *fiber.Ctx) error {
 	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
 
 	product := &domain.Product{
-		TenantID: tenantID,
-		Name:     reqDTO.Name,
-		SKU:      reqDTO.SKU,
-		Barcode:  reqDTO.Barcode,
-		Unit:     reqDTO.Unit,
-		Price:    reqDTO.Price,
-		VATRate:  reqDTO.VATRate,
+		TenantID:     tenantID,
+		Name:         reqDTO.Name,
+		SKU:          reqDTO.SKU,
+		Barcode:      reqDTO.Barcode,
+		Unit:         reqDTO.Unit,
+		Price:        reqDTO.Price,
+		VATRate:      reqDTO.VATRate,
+		StandardCost: reqDTO.StandardCost,
+		TrackLots:    reqDTO.TrackLots,
+		TrackSerials: reqDTO.TrackSerials,
+		CategoryID:   reqDTO.CategoryID,
+		BrandID:      reqDTO.BrandID,
 	}
 
 	if err := h.service.CreateProduct(c.Context(), product); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
-	}
-
-	respDTO := dto.ProductResponseDTO{
-		ID:        product.ID,
-		Name:      product.Name,
-		SKU:       product.SKU,
-		Barcode:   product.Barcode,
-		Unit:      product.Unit,
-		Price:     product.Price,
-		VATRate:   product.VATRate,
-		CreatedAt: product.CreatedAt,
-		UpdatedAt: product.UpdatedAt,
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
 	}
 
-	return c.Status(fiber.StatusCreated).JSON(respDTO)
+	return c.Status(fiber.StatusCreated).JSON(toProductDTO(*product))
 }
 
-// ListProducts handles GET /products
+// ListProducts handles GET /products?category_id=&brand_id=&parent_id=&attr.<code>=
 func (h *ProductHandler) ListProducts(c *fiber.Ctx) error {
-	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+	filter := domain.ProductFilter{Attributes: map[string]string{}}
+	filter.TenantID, _ = c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+
+	var err error
+	if filter.CategoryID, err = parseOptionalUUID(c, "category_id"); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	if filter.BrandID, err = parseOptionalUUID(c, "brand_id"); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	if filter.ParentID, err = parseOptionalUUID(c, "parent_id"); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	c.Context().QueryArgs().VisitAll(func(key, value []byte) {
+		if code, ok := strings.CutPrefix(string(key), "attr."); ok && code != "" {
+			filter.Attributes[code] = string(value)
+		}
+	})
 
-	products, err := h.service.ListProducts(c.Context(), tenantID)
+	products, err := h.service.ListProducts(c.Context(), filter)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
 	}
 
 	respDTOs := make([]dto.ProductResponseDTO, len(products))
 	for i, p := range products {
-		respDTOs[i] = dto.ProductResponseDTO{
-			ID:        p.ID,
-			Name:      p.Name,
-			SKU:       p.SKU,
-			Barcode:   p.Barcode,
-			Unit:      p.Unit,
-			Price:     p.Price,
-			VATRate:   p.VATRate,
-			CreatedAt: p.CreatedAt,
-			UpdatedAt: p.UpdatedAt,
-		}
+		respDTOs[i] = toProductDTO(p)
 	}
 
 	return c.JSON(respDTOs)
 }
+
+func toProductDTO(p domain.Product) dto.ProductResponseDTO {
+	return dto.ProductResponseDTO{
+		ID:           p.ID,
+		Name:         p.Name,
+		SKU:          p.SKU,
+		Barcode:      p.Barcode,
+		Unit:         p.Unit,
+		Price:        p.Price,
+		VATRate:      p.VATRate,
+		StandardCost: p.StandardCost,
+		TrackLots:    p.TrackLots,
+		TrackSerials: p.TrackSerials,
+		CategoryID:   p.CategoryID,
+		BrandID:      p.BrandID,
+		Attributes:   p.Attributes,
+		ParentID:     p.ParentID,
+		VariantKey:   p.VariantKey,
+		CreatedAt:    p.CreatedAt,
+		UpdatedAt:    p.UpdatedAt,
+	}
+}
+
+// SetStandardCost handles PUT /products/:id/standard-cost
+func (h *ProductHandler) SetStandardCost(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+	productID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid product id"})
+	}
+
+	var reqDTO dto.StandardCostRequestDTO
+	if err := c.BodyParser(&reqDTO); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body", "details": err.Error()})
+	}
+
+	if err := h.service.SetStandardCost(c.Context(), tenantID, productID, reqDTO.StandardCost); err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"product_id": productID, "standard_cost": reqDTO.StandardCost})
+}
+
+// SetLotTracking handles PUT /products/:id/lot-tracking
+func (h *ProductHandler) SetLotTracking(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+	productID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid product id"})
+	}
+
+	var reqDTO dto.LotTrackingRequestDTO
+	if err := c.BodyParser(&reqDTO); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body", "details": err.Error()})
+	}
+
+	if err := h.service.SetLotTracking(c.Context(), tenantID, productID, reqDTO.TrackLots); err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"product_id": productID, "track_lots": reqDTO.TrackLots})
+}
+
+// SetSerialTracking handles PUT /products/:id/serial-tracking
+func (h *ProductHandler) SetSerialTracking(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+	productID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid product id"})
+	}
+
+	var reqDTO dto.SerialTrackingRequestDTO
+	if err := c.BodyParser(&reqDTO); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body", "details": err.Error()})
+	}
+
+	if err := h.service.SetSerialTracking(c.Context(), tenantID, productID, reqDTO.TrackSerials); err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"product_id": productID, "track_serials": reqDTO.TrackSerials})
+}
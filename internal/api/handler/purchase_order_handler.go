@@ -0,0 +1,352 @@
+package handler
+
+import (
+	"context"
+	"time"
+
+	"sancaksoft/internal/api/dto"
+	"sancaksoft/internal/api/middleware"
+	"sancaksoft/internal/domain"
+	"sancaksoft/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type PurchaseOrderHandler struct {
+	service *service.PurchaseOrderService
+}
+
+func NewPurchaseOrderHandler(s *service.PurchaseOrderService) *PurchaseOrderHandler {
+	return &PurchaseOrderHandler{service: s}
+}
+
+// formatOptionalDate renders a nullable DATE column as YYYY-MM-DD.
+func formatOptionalDate(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	s := t.Format(dateLayout)
+	return &s
+}
+
+func toPurchaseOrderDTO(o *domain.PurchaseOrder) dto.PurchaseOrderResponseDTO {
+	resp := dto.PurchaseOrderResponseDTO{
+		ID:           o.ID,
+		OrderNumber:  o.OrderNumber,
+		SupplierID:   o.SupplierID,
+		WarehouseID:  o.WarehouseID,
+		Status:       o.Status,
+		ExpectedDate: formatOptionalDate(o.ExpectedDate),
+		Note:         o.Note,
+		TotalAmount:  o.TotalAmount,
+		OrderedAt:    o.OrderedAt,
+		ClosedAt:     o.ClosedAt,
+		CreatedAt:    o.CreatedAt,
+		UpdatedAt:    o.UpdatedAt,
+	}
+	for _, it := range o.Items {
+		resp.Items = append(resp.Items, dto.PurchaseOrderItemResponseDTO{
+			ID:           it.ID,
+			ProductID:    it.ProductID,
+			Quantity:     it.Quantity,
+			ReceivedQty:  it.ReceivedQty,
+			RemainingQty: it.RemainingQty(),
+			UnitPrice:    it.UnitPrice,
+			Total:        it.Total,
+		})
+	}
+	return resp
+}
+
+func toPurchaseLines(lines []dto.PurchaseOrderLineDTO) ([]domain.PurchaseOrderQuantityLine, error) {
+	result := make([]domain.PurchaseOrderQuantityLine, len(lines))
+	for i, l := range lines {
+		result[i] = domain.PurchaseOrderQuantityLine{OrderItemID: l.OrderItemID, Quantity: l.Quantity, UnitPrice: l.UnitPrice, LotNumber: l.LotNumber, SerialNumbers: l.SerialNumbers, LocationID: l.LocationID}
+		if l.ExpiryDate != "" {
+			expiryDate, err := time.Parse(dateLayout, l.ExpiryDate)
+			if err != nil {
+				return nil, err
+			}
+			result[i].ExpiryDate = &expiryDate
+		}
+	}
+	return result, nil
+}
+
+// CreatePurchaseOrder handles POST /purchase-orders
+func (h *PurchaseOrderHandler) CreatePurchaseOrder(c *fiber.Ctx) error {
+	var reqDTO dto.CreatePurchaseOrderRequestDTO
+	if err := c.BodyParser(&reqDTO); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body", "details": err.Error()})
+	}
+
+	var expectedDate *time.Time
+	if reqDTO.ExpectedDate != "" {
+		t, err := time.Parse(dateLayout, reqDTO.ExpectedDate)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid expected_date, expected YYYY-MM-DD"})
+		}
+		expectedDate = &t
+	}
+
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+	userID, _ := c.Locals(middleware.LocalsUserID).(uuid.UUID)
+
+	items := make([]domain.PurchaseOrderItemRequest, len(reqDTO.Items))
+	for i, item := range reqDTO.Items {
+		items[i] = domain.PurchaseOrderItemRequest{
+			ProductID: item.ProductID,
+			Quantity:  item.Quantity,
+			UnitPrice: item.UnitPrice,
+		}
+	}
+
+	order, err := h.service.CreatePurchaseOrder(c.Context(), domain.CreatePurchaseOrderRequest{
+		TenantID:     tenantID,
+		UserID:       userID,
+		SupplierID:   reqDTO.SupplierID,
+		WarehouseID:  reqDTO.WarehouseID,
+		ExpectedDate: expectedDate,
+		Note:         reqDTO.Note,
+		Items:        items,
+	})
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(fiber.StatusCreated).JSON(toPurchaseOrderDTO(order))
+}
+
+// ListPurchaseOrders handles GET /purchase-orders?supplier_id=&status=
+func (h *PurchaseOrderHandler) ListPurchaseOrders(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+	supplierID, err := parseOptionalUUID(c, "supplier_id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	orders, err := h.service.ListPurchaseOrders(c.Context(), tenantID, supplierID, domain.PurchaseOrderStatus(c.Query("status")))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	resp := make([]dto.PurchaseOrderResponseDTO, len(orders))
+	for i := range orders {
+		resp[i] = toPurchaseOrderDTO(&orders[i])
+	}
+	return c.JSON(resp)
+}
+
+// GetPurchaseOrder handles GET /purchase-orders/:id
+func (h *PurchaseOrderHandler) GetPurchaseOrder(c *fiber.Ctx) error {
+	return h.transition(c, h.service.GetPurchaseOrder)
+}
+
+// ConfirmPurchaseOrder handles POST /purchase-orders/:id/confirm
+func (h *PurchaseOrderHandler) ConfirmPurchaseOrder(c *fiber.Ctx) error {
+	return h.transition(c, h.service.ConfirmPurchaseOrder)
+}
+
+// ClosePurchaseOrder handles POST /purchase-orders/:id/close
+func (h *PurchaseOrderHandler) ClosePurchaseOrder(c *fiber.Ctx) error {
+	return h.transition(c, h.service.ClosePurchaseOrder)
+}
+
+// CancelPurchaseOrder handles POST /purchase-orders/:id/cancel
+func (h *PurchaseOrderHandler) CancelPurchaseOrder(c *fiber.Ctx) error {
+	return h.transition(c, h.service.CancelPurchaseOrder)
+}
+
+func (h *PurchaseOrderHandler) transition(c *fiber.Ctx, fn func(ctx context.Context, tenantID, orderID uuid.UUID) (*domain.PurchaseOrder, error)) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+	orderID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid purchase order id"})
+	}
+
+	order, err := fn(c.Context(), tenantID, orderID)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(toPurchaseOrderDTO(order))
+}
+
+// ReceiveGoods handles POST /purchase-orders/:id/receipts
+func (h *PurchaseOrderHandler) ReceiveGoods(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+	userID, _ := c.Locals(middleware.LocalsUserID).(uuid.UUID)
+	orderID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid purchase order id"})
+	}
+
+	var reqDTO dto.GoodsReceiptRequestDTO
+	if err := c.BodyParser(&reqDTO); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body", "details": err.Error()})
+	}
+	lines, err := toPurchaseLines(reqDTO.Lines)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid expiry_date, expected YYYY-MM-DD"})
+	}
+
+	gr, err := h.service.ReceiveGoods(c.Context(), domain.CreateGoodsReceiptRequest{
+		TenantID:    tenantID,
+		UserID:      userID,
+		OrderID:     orderID,
+		WarehouseID: reqDTO.WarehouseID,
+		Note:        reqDTO.Note,
+		Lines:       lines,
+	})
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	resp := dto.GoodsReceiptResponseDTO{
+		ID:            gr.ID,
+		OrderID:       gr.OrderID,
+		WarehouseID:   gr.WarehouseID,
+		ReceiptNumber: gr.ReceiptNumber,
+		Note:          gr.Note,
+		ReceivedAt:    gr.ReceivedAt,
+	}
+	for _, it := range gr.Items {
+		resp.Items = append(resp.Items, dto.GoodsReceiptItemResponseDTO{
+			ID:          it.ID,
+			OrderItemID: it.OrderItemID,
+			ProductID:   it.ProductID,
+			Quantity:    it.Quantity,
+			UnitCost:    it.UnitCost,
+		})
+	}
+	return c.Status(fiber.StatusCreated).JSON(resp)
+}
+
+// RecordPurchaseInvoice handles POST /purchase-orders/:id/invoices
+func (h *PurchaseOrderHandler) RecordPurchaseInvoice(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+	userID, _ := c.Locals(middleware.LocalsUserID).(uuid.UUID)
+	orderID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid purchase order id"})
+	}
+
+	var reqDTO dto.PurchaseInvoiceRequestDTO
+	if err := c.BodyParser(&reqDTO); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body", "details": err.Error()})
+	}
+	var invoiceDate time.Time
+	if reqDTO.InvoiceDate != "" {
+		invoiceDate, err = time.Parse(dateLayout, reqDTO.InvoiceDate)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid invoice_date, expected YYYY-MM-DD"})
+		}
+	}
+	lines, err := toPurchaseLines(reqDTO.Lines)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid expiry_date, expected YYYY-MM-DD"})
+	}
+
+	inv, err := h.service.RecordPurchaseInvoice(c.Context(), domain.CreatePurchaseInvoiceRequest{
+		TenantID:      tenantID,
+		UserID:        userID,
+		OrderID:       orderID,
+		InvoiceNumber: reqDTO.InvoiceNumber,
+		InvoiceDate:   invoiceDate,
+		Lines:         lines,
+	})
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	resp := dto.PurchaseInvoiceResponseDTO{
+		ID:            inv.ID,
+		OrderID:       inv.OrderID,
+		SupplierID:    inv.SupplierID,
+		InvoiceNumber: inv.InvoiceNumber,
+		InvoiceDate:   inv.InvoiceDate.Format(dateLayout),
+		TotalAmount:   inv.TotalAmount,
+		CreatedAt:     inv.CreatedAt,
+	}
+	for _, it := range inv.Items {
+		resp.Items = append(resp.Items, dto.PurchaseInvoiceItemResponseDTO{
+			ID:          it.ID,
+			OrderItemID: it.OrderItemID,
+			ProductID:   it.ProductID,
+			Quantity:    it.Quantity,
+			UnitPrice:   it.UnitPrice,
+			Total:       it.Total,
+		})
+	}
+	return c.Status(fiber.StatusCreated).JSON(resp)
+}
+
+// GetPurchaseOrderMatch handles GET /purchase-orders/:id/match
+func (h *PurchaseOrderHandler) GetPurchaseOrderMatch(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+	orderID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid purchase order id"})
+	}
+
+	lines, err := h.service.GetPurchaseOrderMatch(c.Context(), tenantID, orderID)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(toPurchaseMatchDTOs(lines))
+}
+
+// ListPurchaseDiscrepancies handles GET /purchase-orders/discrepancies
+func (h *PurchaseOrderHandler) ListPurchaseDiscrepancies(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+
+	lines, err := h.service.ListPurchaseDiscrepancies(c.Context(), tenantID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(toPurchaseMatchDTOs(lines))
+}
+
+func toPurchaseMatchDTOs(lines []domain.PurchaseMatchLine) []dto.PurchaseMatchLineDTO {
+	resp := make([]dto.PurchaseMatchLineDTO, len(lines))
+	for i, l := range lines {
+		resp[i] = dto.PurchaseMatchLineDTO(l)
+	}
+	return resp
+}
+
+// ListOpenPurchaseLines handles GET /purchase-orders/open-lines?product_id=&warehouse_id=
+func (h *PurchaseOrderHandler) ListOpenPurchaseLines(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+	productID, err := parseOptionalUUID(c, "product_id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	warehouseID, err := parseOptionalUUID(c, "warehouse_id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	lines, err := h.service.ListOpenPurchaseLines(c.Context(), tenantID, productID, warehouseID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	resp := make([]dto.OpenPurchaseLineDTO, len(lines))
+	for i, l := range lines {
+		resp[i] = dto.OpenPurchaseLineDTO{
+			OrderID:      l.OrderID,
+			OrderNumber:  l.OrderNumber,
+			SupplierID:   l.SupplierID,
+			SupplierName: l.SupplierName,
+			WarehouseID:  l.WarehouseID,
+			ProductID:    l.ProductID,
+			ProductName:  l.ProductName,
+			OrderedQty:   l.OrderedQty,
+			ReceivedQty:  l.ReceivedQty,
+			RemainingQty: l.RemainingQty,
+			UnitPrice:    l.UnitPrice,
+			ExpectedDate: formatOptionalDate(l.ExpectedDate),
+		}
+	}
+	return c.JSON(resp)
+}
@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"sancaksoft/internal/domain"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+const dateLayout = "2006-01-02"
+
+// parseDateRange reads optional ?from=YYYY-MM-DD&to=YYYY-MM-DD query parameters.
+// The returned upper bound is exclusive (the day after `to`) so whole days are included.
+func parseDateRange(c *fiber.Ctx) (from, to *time.Time, err error) {
+	if v := c.Query("from"); v != "" {
+		t, err := time.Parse(dateLayout, v)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid from date, expected YYYY-MM-DD")
+		}
+		from = &t
+	}
+	if v := c.Query("to"); v != "" {
+		t, err := time.Parse(dateLayout, v)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid to date, expected YYYY-MM-DD")
+		}
+		t = t.AddDate(0, 0, 1)
+		to = &t
+	}
+	return from, to, nil
+}
+
+// parseOptionalUUID reads an optional UUID query parameter; nil means "not filtered".
+func parseOptionalUUID(c *fiber.Ctx, name string) (*uuid.UUID, error) {
+	v := c.Query(name)
+	if v == "" {
+		return nil, nil
+	}
+	id, err := uuid.Parse(v)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s", name)
+	}
+	return &id, nil
+}
+
+// parseInvoiceFilter reads ?customer_id=&warehouse_id=&from=&to= for invoice lists and exports.
+func parseInvoiceFilter(c *fiber.Ctx, tenantID uuid.UUID) (domain.InvoiceFilter, error) {
+	f := domain.InvoiceFilter{TenantID: tenantID}
+	var err error
+	if f.CustomerID, err = parseOptionalUUID(c, "customer_id"); err != nil {
+		return f, err
+	}
+	if f.WarehouseID, err = parseOptionalUUID(c, "warehouse_id"); err != nil {
+		return f, err
+	}
+	f.From, f.To, err = parseDateRange(c)
+	return f, err
+}
+
+// parseStockMovementFilter reads ?product_id=&warehouse_id=&type=&from=&to= for stock
+// movement lists and exports.
+func parseStockMovementFilter(c *fiber.Ctx, tenantID uuid.UUID) (domain.StockMovementFilter, error) {
+	f := domain.StockMovementFilter{TenantID: tenantID, Type: domain.StockMovementType(strings.ToUpper(c.Query("type")))}
+	var err error
+	if f.ProductID, err = parseOptionalUUID(c, "product_id"); err != nil {
+		return f, err
+	}
+	if f.WarehouseID, err = parseOptionalUUID(c, "warehouse_id"); err != nil {
+		return f, err
+	}
+	f.From, f.To, err = parseDateRange(c)
+	return f, err
+}
@@ -0,0 +1,231 @@
+package handler
+
+import (
+	"context"
+	"time"
+
+	"sancaksoft/internal/api/dto"
+	"sancaksoft/internal/api/middleware"
+	"sancaksoft/internal/domain"
+	"sancaksoft/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type QuotationHandler struct {
+	service *service.QuotationService
+}
+
+func NewQuotationHandler(s *service.QuotationService) *QuotationHandler {
+	return &QuotationHandler{service: s}
+}
+
+func toQuotationDTO(q *domain.Quotation) dto.QuotationResponseDTO {
+	resp := dto.QuotationResponseDTO{
+		ID:           q.ID,
+		QuoteNumber:  q.QuoteNumber,
+		Revision:     q.Revision,
+		CustomerID:   q.CustomerID,
+		WarehouseID:  q.WarehouseID,
+		Status:       q.Status,
+		ValidUntil:   q.ValidUntil.Format(dateLayout),
+		Note:         q.Note,
+		TotalAmount:  q.TotalAmount,
+		SalesOrderID: q.SalesOrderID,
+		InvoiceID:    q.InvoiceID,
+		CreatedAt:    q.CreatedAt,
+		UpdatedAt:    q.UpdatedAt,
+	}
+	for _, it := range q.Items {
+		resp.Items = append(resp.Items, dto.QuotationItemResponseDTO{
+			ID:        it.ID,
+			ProductID: it.ProductID,
+			Quantity:  it.Quantity,
+			UnitPrice: it.UnitPrice,
+			Total:     it.Total,
+		})
+	}
+	return resp
+}
+
+// parseQuotationBody reads the shared create/revise body.
+func parseQuotationBody(c *fiber.Ctx) (dto.QuotationRequestDTO, time.Time, []domain.QuotationItemRequest, error) {
+	var reqDTO dto.QuotationRequestDTO
+	if err := c.BodyParser(&reqDTO); err != nil {
+		return reqDTO, time.Time{}, nil, err
+	}
+	validUntil, err := time.Parse(dateLayout, reqDTO.ValidUntil)
+	if err != nil {
+		return reqDTO, time.Time{}, nil, err
+	}
+	items := make([]domain.QuotationItemRequest, len(reqDTO.Items))
+	for i, item := range reqDTO.Items {
+		items[i] = domain.QuotationItemRequest{
+			ProductID: item.ProductID,
+			Quantity:  item.Quantity,
+			UnitPrice: item.UnitPrice,
+		}
+	}
+	return reqDTO, validUntil, items, nil
+}
+
+// CreateQuotation handles POST /quotations
+func (h *QuotationHandler) CreateQuotation(c *fiber.Ctx) error {
+	reqDTO, validUntil, items, err := parseQuotationBody(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body", "details": err.Error()})
+	}
+	if reqDTO.CustomerID == uuid.Nil || reqDTO.WarehouseID == uuid.Nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "customer_id and warehouse_id are required"})
+	}
+
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+	userID, _ := c.Locals(middleware.LocalsUserID).(uuid.UUID)
+
+	quote, err := h.service.CreateQuotation(c.Context(), domain.CreateQuotationRequest{
+		TenantID:    tenantID,
+		UserID:      userID,
+		CustomerID:  reqDTO.CustomerID,
+		WarehouseID: reqDTO.WarehouseID,
+		ValidUntil:  validUntil,
+		Note:        reqDTO.Note,
+		Items:       items,
+	})
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(fiber.StatusCreated).JSON(toQuotationDTO(quote))
+}
+
+// ReviseQuotation handles POST /quotations/:id/revise
+func (h *QuotationHandler) ReviseQuotation(c *fiber.Ctx) error {
+	quotationID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid quotation id"})
+	}
+	reqDTO, validUntil, items, err := parseQuotationBody(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body", "details": err.Error()})
+	}
+
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+	userID, _ := c.Locals(middleware.LocalsUserID).(uuid.UUID)
+
+	quote, err := h.service.ReviseQuotation(c.Context(), domain.ReviseQuotationRequest{
+		TenantID:    tenantID,
+		UserID:      userID,
+		QuotationID: quotationID,
+		ValidUntil:  validUntil,
+		Note:        reqDTO.Note,
+		Items:       items,
+	})
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(fiber.StatusCreated).JSON(toQuotationDTO(quote))
+}
+
+// ListQuotations handles GET /quotations?status=
+func (h *QuotationHandler) ListQuotations(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+
+	quotes, err := h.service.ListQuotations(c.Context(), tenantID, domain.QuotationStatus(c.Query("status")))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	resp := make([]dto.QuotationResponseDTO, len(quotes))
+	for i := range quotes {
+		resp[i] = toQuotationDTO(&quotes[i])
+	}
+	return c.JSON(resp)
+}
+
+// GetQuotation handles GET /quotations/:id
+func (h *QuotationHandler) GetQuotation(c *fiber.Ctx) error {
+	return h.transition(c, h.service.GetQuotation)
+}
+
+// AcceptQuotation handles POST /quotations/:id/accept
+func (h *QuotationHandler) AcceptQuotation(c *fiber.Ctx) error {
+	return h.transition(c, h.service.AcceptQuotation)
+}
+
+// RejectQuotation handles POST /quotations/:id/reject
+func (h *QuotationHandler) RejectQuotation(c *fiber.Ctx) error {
+	return h.transition(c, h.service.RejectQuotation)
+}
+
+func (h *QuotationHandler) transition(c *fiber.Ctx, fn func(ctx context.Context, tenantID, quotationID uuid.UUID) (*domain.Quotation, error)) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+	quotationID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid quotation id"})
+	}
+
+	quote, err := fn(c.Context(), tenantID, quotationID)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(toQuotationDTO(quote))
+}
+
+// ConvertToSalesOrder handles POST /quotations/:id/convert-to-order
+func (h *QuotationHandler) ConvertToSalesOrder(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+	userID, _ := c.Locals(middleware.LocalsUserID).(uuid.UUID)
+	quotationID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid quotation id"})
+	}
+
+	order, err := h.service.ConvertToSalesOrder(c.Context(), domain.ConvertQuotationRequest{
+		TenantID:    tenantID,
+		UserID:      userID,
+		QuotationID: quotationID,
+	})
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(fiber.StatusCreated).JSON(toSalesOrderDTO(order))
+}
+
+// ConvertToInvoice handles POST /quotations/:id/convert-to-invoice
+func (h *QuotationHandler) ConvertToInvoice(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+	userID, _ := c.Locals(middleware.LocalsUserID).(uuid.UUID)
+	quotationID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid quotation id"})
+	}
+
+	var reqDTO dto.ConvertQuotationRequestDTO
+	if err := c.BodyParser(&reqDTO); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body", "details": err.Error()})
+	}
+	if reqDTO.IdempotencyKey == uuid.Nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "idempotency_key is required"})
+	}
+
+	invoice, err := h.service.ConvertToInvoice(c.Context(), domain.ConvertQuotationRequest{
+		TenantID:       tenantID,
+		UserID:         userID,
+		QuotationID:    quotationID,
+		IdempotencyKey: reqDTO.IdempotencyKey,
+
+		CreditOverrideReason: reqDTO.CreditOverrideReason,
+	})
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(dto.InvoiceResponseDTO{
+		ID:            invoice.ID,
+		InvoiceNumber: invoice.InvoiceNumber,
+		TotalAmount:   invoice.TotalAmount,
+		DueDate:       invoice.DueDate,
+		CreatedAt:     invoice.CreatedAt,
+		Status:        "created",
+	})
+}
@@ -0,0 +1,174 @@
+package handler
+
+import (
+	"sancaksoft/internal/api/dto"
+	"sancaksoft/internal/api/middleware"
+	"sancaksoft/internal/domain"
+	"sancaksoft/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type ReplenishmentHandler struct {
+	service *service.ReplenishmentService
+}
+
+func NewReplenishmentHandler(s *service.ReplenishmentService) *ReplenishmentHandler {
+	return &ReplenishmentHandler{service: s}
+}
+
+func toStockLevelDTO(st *domain.StockLevelStatus, severity string) dto.StockLevelResponseDTO {
+	return dto.StockLevelResponseDTO{
+		ID:            st.ID,
+		ProductID:     st.ProductID,
+		ProductName:   st.ProductName,
+		WarehouseID:   st.WarehouseID,
+		WarehouseName: st.WarehouseName,
+		MinLevel:      st.MinLevel,
+		ReorderLevel:  st.ReorderLevel,
+		MaxLevel:      st.MaxLevel,
+		SafetyStock:   st.SafetyStock,
+		LeadTimeDays:  st.LeadTimeDays,
+		SupplierID:    st.SupplierID,
+		OnHand:        st.OnHand,
+		Reserved:      st.Reserved,
+		Available:     st.Available,
+		OnOrder:       st.OnOrder,
+		Severity:      severity,
+		UpdatedAt:     st.UpdatedAt,
+	}
+}
+
+// SetStockLevel handles PUT /stock-levels
+func (h *ReplenishmentHandler) SetStockLevel(c *fiber.Ctx) error {
+	var reqDTO dto.StockLevelRequestDTO
+	if err := c.BodyParser(&reqDTO); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body", "details": err.Error()})
+	}
+	if reqDTO.ProductID == uuid.Nil || reqDTO.WarehouseID == uuid.Nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "product_id and warehouse_id are required"})
+	}
+
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+
+	level := &domain.StockLevel{
+		TenantID:     tenantID,
+		ProductID:    reqDTO.ProductID,
+		WarehouseID:  reqDTO.WarehouseID,
+		MinLevel:     reqDTO.MinLevel,
+		ReorderLevel: reqDTO.ReorderLevel,
+		MaxLevel:     reqDTO.MaxLevel,
+		SafetyStock:  reqDTO.SafetyStock,
+		LeadTimeDays: reqDTO.LeadTimeDays,
+		SupplierID:   reqDTO.SupplierID,
+	}
+	if err := h.service.SetStockLevel(c.Context(), level); err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(toStockLevelDTO(&domain.StockLevelStatus{StockLevel: *level}, ""))
+}
+
+// DeleteStockLevel handles DELETE /stock-levels/:id
+func (h *ReplenishmentHandler) DeleteStockLevel(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+	levelID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid stock level id"})
+	}
+
+	if err := h.service.DeleteStockLevel(c.Context(), tenantID, levelID); err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// ListStockLevels handles GET /stock-levels?warehouse_id=
+func (h *ReplenishmentHandler) ListStockLevels(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+	warehouseID, err := parseOptionalUUID(c, "warehouse_id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	levels, err := h.service.ListStockLevels(c.Context(), tenantID, warehouseID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	resp := make([]dto.StockLevelResponseDTO, len(levels))
+	for i := range levels {
+		resp[i] = toStockLevelDTO(&levels[i], "")
+	}
+	return c.JSON(resp)
+}
+
+// GetLowStockReport handles GET /stock/low-stock?warehouse_id=
+func (h *ReplenishmentHandler) GetLowStockReport(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+	warehouseID, err := parseOptionalUUID(c, "warehouse_id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	items, err := h.service.GetLowStockReport(c.Context(), tenantID, warehouseID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	resp := make([]dto.StockLevelResponseDTO, len(items))
+	for i := range items {
+		resp[i] = toStockLevelDTO(&items[i].StockLevelStatus, items[i].Severity)
+	}
+	return c.JSON(resp)
+}
+
+// GetReplenishmentSuggestions handles GET /replenishment/suggestions?warehouse_id=&window_days=
+func (h *ReplenishmentHandler) GetReplenishmentSuggestions(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+	warehouseID, err := parseOptionalUUID(c, "warehouse_id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	suggestions, err := h.service.GetReplenishmentSuggestions(c.Context(), tenantID, warehouseID, c.QueryInt("window_days", 0))
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(toSuggestionDTOs(suggestions))
+}
+
+// CreateReplenishmentOrders handles POST /replenishment/purchase-orders
+func (h *ReplenishmentHandler) CreateReplenishmentOrders(c *fiber.Ctx) error {
+	var reqDTO dto.ReplenishmentOrdersRequestDTO
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&reqDTO); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body", "details": err.Error()})
+		}
+	}
+
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+	userID, _ := c.Locals(middleware.LocalsUserID).(uuid.UUID)
+
+	result, err := h.service.CreateReplenishmentOrders(c.Context(), tenantID, userID, reqDTO.WarehouseID, reqDTO.WindowDays, reqDTO.ProductIDs)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	resp := dto.ReplenishmentOrdersResponseDTO{
+		Orders:  make([]dto.PurchaseOrderResponseDTO, len(result.Orders)),
+		Skipped: toSuggestionDTOs(result.Skipped),
+	}
+	for i := range result.Orders {
+		resp.Orders[i] = toPurchaseOrderDTO(&result.Orders[i])
+	}
+	return c.Status(fiber.StatusCreated).JSON(resp)
+}
+
+func toSuggestionDTOs(suggestions []domain.ReplenishmentSuggestion) []dto.ReplenishmentSuggestionDTO {
+	resp := make([]dto.ReplenishmentSuggestionDTO, len(suggestions))
+	for i, sg := range suggestions {
+		resp[i] = dto.ReplenishmentSuggestionDTO(sg)
+	}
+	return resp
+}
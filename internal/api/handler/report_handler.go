@@ -0,0 +1,182 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"time"
+
+	"sancaksoft/internal/api/dto"
+	"sancaksoft/internal/api/middleware"
+	"sancaksoft/internal/domain"
+	"sancaksoft/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type ReportHandler struct {
+	service *service.ReportService
+}
+
+func NewReportHandler(s *service.ReportService) *ReportHandler {
+	return &ReportHandler{service: s}
+}
+
+// GetGrossProfit handles GET /reports/gross-profit?group_by=&period=&from=&to=&customer_id=&warehouse_id=&explode_kits=&format=csv
+func (h *ReportHandler) GetGrossProfit(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+
+	from, to, err := parseDateRange(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	customerID, err := parseOptionalUUID(c, "customer_id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	warehouseID, err := parseOptionalUUID(c, "warehouse_id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	report, err := h.service.GetGrossProfit(c.Context(), domain.GrossProfitReportRequest{
+		TenantID:    tenantID,
+		GroupBy:     domain.ProfitGroupBy(c.Query("group_by")),
+		Period:      domain.ReportPeriod(c.Query("period")),
+		From:        from,
+		To:          to,
+		CustomerID:  customerID,
+		WarehouseID: warehouseID,
+		ExplodeKits: c.QueryBool("explode_kits"),
+	})
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	if c.Query("format") == "csv" {
+		return sendGrossProfitCSV(c, report)
+	}
+
+	resp := dto.GrossProfitReportDTO{
+		GroupBy: string(report.GroupBy),
+		Period:  string(report.Period),
+		Lines:   make([]dto.GrossProfitLineDTO, len(report.Lines)),
+		Total:   dto.GrossProfitLineDTO(report.Total),
+	}
+	for i, l := range report.Lines {
+		resp.Lines[i] = dto.GrossProfitLineDTO(l)
+	}
+	return c.JSON(resp)
+}
+
+func sendGrossProfitCSV(c *fiber.Ctx, report *domain.GrossProfitReport) error {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	_ = w.Write([]string{"key", "label", "quantity", "revenue", "cost", "gross_profit", "margin_pct"})
+	for _, l := range append(report.Lines, report.Total) {
+		_ = w.Write([]string{
+			l.Key,
+			l.Label,
+			strconv.Itoa(l.Quantity),
+			l.Revenue.StringFixed(2),
+			l.Cost.StringFixed(2),
+			l.GrossProfit.StringFixed(2),
+			l.MarginPct.StringFixed(2),
+		})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="gross-profit-%s.csv"`, report.GroupBy))
+	return c.Send(buf.Bytes())
+}
+
+// GetReceivablesAging handles GET /reports/receivables-aging?as_of=&customer_id=&format=csv|json
+func (h *ReportHandler) GetReceivablesAging(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+
+	req := domain.ReceivablesAgingRequest{TenantID: tenantID}
+	if v := c.Query("as_of"); v != "" {
+		asOf, err := time.Parse(dateLayout, v)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid as_of date, expected YYYY-MM-DD"})
+		}
+		req.AsOf = asOf
+	}
+	customerID, err := parseOptionalUUID(c, "customer_id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	req.CustomerID = customerID
+
+	report, err := h.service.GetReceivablesAging(c.Context(), req)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	if c.Query("format") == "csv" {
+		return sendReceivablesAgingCSV(c, report)
+	}
+
+	resp := dto.ReceivablesAgingReportDTO{
+		AsOf:     report.AsOf.Format(dateLayout),
+		Lines:    make([]dto.ReceivablesAgingLineDTO, len(report.Lines)),
+		Total:    dto.ReceivablesAgingLineDTO(report.Total),
+		Invoices: make([]dto.AgingInvoiceDTO, len(report.Invoices)),
+	}
+	for i, l := range report.Lines {
+		resp.Lines[i] = dto.ReceivablesAgingLineDTO(l)
+	}
+	for i, inv := range report.Invoices {
+		resp.Invoices[i] = dto.AgingInvoiceDTO{
+			InvoiceID:     inv.InvoiceID,
+			CustomerID:    inv.CustomerID,
+			CustomerName:  inv.CustomerName,
+			InvoiceNumber: inv.InvoiceNumber,
+			InvoiceDate:   inv.InvoiceDate,
+			DueDate:       inv.DueDate.Format(dateLayout),
+			TotalAmount:   inv.TotalAmount,
+			OpenAmount:    inv.OpenAmount,
+			DaysOverdue:   inv.DaysOverdue,
+			Bucket:        string(inv.Bucket),
+		}
+	}
+	return c.JSON(resp)
+}
+
+func sendReceivablesAgingCSV(c *fiber.Ctx, report *domain.ReceivablesAgingReport) error {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	_ = w.Write([]string{"customer_id", "customer_name", "current", "days_1_30", "days_31_60", "days_61_90", "over_90", "total", "unapplied_credit", "balance"})
+	for _, l := range append(report.Lines, report.Total) {
+		customerID := "TOTAL"
+		if l.CustomerID != uuid.Nil {
+			customerID = l.CustomerID.String()
+		}
+		_ = w.Write([]string{
+			customerID,
+			l.CustomerName,
+			l.Current.StringFixed(2),
+			l.Days1To30.StringFixed(2),
+			l.Days31To60.StringFixed(2),
+			l.Days61To90.StringFixed(2),
+			l.Over90.StringFixed(2),
+			l.Total.StringFixed(2),
+			l.UnappliedCredit.StringFixed(2),
+			l.Balance.StringFixed(2),
+		})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="receivables-aging-%s.csv"`, report.AsOf.Format(dateLayout)))
+	return c.Send(buf.Bytes())
+}
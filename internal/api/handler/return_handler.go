@@ -18,6 +18,42 @@ func NewReturnHandler(s *service.ReturnService) *ReturnHandler {
 	return &ReturnHandler{service: s}
 }
 
+func toCustomerReturnDTO(r *domain.CustomerReturn) dto.CustomerReturnResponseDTO {
+	return dto.CustomerReturnResponseDTO{
+		ID:               r.ID,
+		CustomerID:       r.CustomerID,
+		ProductID:        r.ProductID,
+		WarehouseID:      r.WarehouseID,
+		Quantity:         r.Quantity,
+		UnitPrice:        r.UnitPrice,
+		Total:            r.Total,
+		Reason:           r.Reason,
+		ReasonID:         r.ReasonID,
+		Status:           r.Status,
+		RestockQty:       r.RestockQty,
+		ScrapQty:         r.ScrapQty,
+		SupplierQty:      r.SupplierQty,
+		ScrapWarehouseID: r.ScrapWarehouseID,
+		InspectionNote:   r.InspectionNote,
+		ReceivedAt:       r.ReceivedAt,
+		InspectedAt:      r.InspectedAt,
+		ClosedAt:         r.ClosedAt,
+		CreatedAt:        r.CreatedAt,
+	}
+}
+
+func toReturnReasonDTO(r *domain.ReturnReason) dto.ReturnReasonResponseDTO {
+	return dto.ReturnReasonResponseDTO{
+		ID:        r.ID,
+		Code:      r.Code,
+		Name:      r.Name,
+		IsActive:  r.IsActive,
+		CreatedAt: r.CreatedAt,
+		UpdatedAt: r.UpdatedAt,
+	}
+}
+
+// CreateCustomerReturn handles POST /returns
 func (h *ReturnHandler) CreateCustomerReturn(c *fiber.Ctx) error {
 	var reqDTO dto.CreateCustomerReturnRequestDTO
 	if err := c.BodyParser(&reqDTO); err != nil {
@@ -39,51 +75,129 @@ func (h *ReturnHandler) CreateCustomerReturn(c *fiber.Ctx) error {
 		Quantity:    reqDTO.Quantity,
 		UnitPrice:   reqDTO.UnitPrice,
 		Reason:      reqDTO.Reason,
+		ReasonID:    reqDTO.ReasonID,
 	}
 
 	ret, err := h.service.CreateCustomerReturn(c.Context(), req)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
 	}
 
-	resp := dto.CustomerReturnResponseDTO{
-		ID:          ret.ID,
-		CustomerID:  ret.CustomerID,
-		ProductID:   ret.ProductID,
-		WarehouseID: ret.WarehouseID,
-		Quantity:    ret.Quantity,
-		UnitPrice:   ret.UnitPrice,
-		Total:       ret.Total,
-		Reason:      ret.Reason,
-		CreatedAt:   ret.CreatedAt,
-	}
-	return c.Status(fiber.StatusCreated).JSON(resp)
+	return c.Status(fiber.StatusCreated).JSON(toCustomerReturnDTO(ret))
 }
 
+// ListCustomerReturns handles GET /returns?status=
 func (h *ReturnHandler) ListCustomerReturns(c *fiber.Ctx) error {
 	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
-	returns, err := h.service.ListCustomerReturns(c.Context(), tenantID)
+	status := domain.ReturnStatus(c.Query("status"))
+
+	returns, err := h.service.ListCustomerReturns(c.Context(), tenantID, status)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
 	}
 
 	resp := make([]dto.CustomerReturnResponseDTO, len(returns))
-	for i, r := range returns {
-		resp[i] = dto.CustomerReturnResponseDTO{
-			ID:          r.ID,
-			CustomerID:  r.CustomerID,
-			ProductID:   r.ProductID,
-			WarehouseID: r.WarehouseID,
-			Quantity:    r.Quantity,
-			UnitPrice:   r.UnitPrice,
-			Total:       r.Total,
-			Reason:      r.Reason,
-			CreatedAt:   r.CreatedAt,
-		}
+	for i := range returns {
+		resp[i] = toCustomerReturnDTO(&returns[i])
 	}
 	return c.JSON(resp)
 }
 
+// ReceiveCustomerReturn handles POST /returns/:id/receive
+func (h *ReturnHandler) ReceiveCustomerReturn(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+	returnID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid return id"})
+	}
+
+	ret, err := h.service.ReceiveCustomerReturn(c.Context(), tenantID, returnID)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(toCustomerReturnDTO(ret))
+}
+
+// InspectCustomerReturn handles POST /returns/:id/inspect
+func (h *ReturnHandler) InspectCustomerReturn(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+	userID, _ := c.Locals(middleware.LocalsUserID).(uuid.UUID)
+	returnID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid return id"})
+	}
+
+	var reqDTO dto.InspectCustomerReturnRequestDTO
+	if err := c.BodyParser(&reqDTO); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+	}
+
+	ret, err := h.service.InspectCustomerReturn(c.Context(), domain.InspectCustomerReturnRequest{
+		TenantID:         tenantID,
+		UserID:           userID,
+		ReturnID:         returnID,
+		RestockQty:       reqDTO.RestockQty,
+		ScrapQty:         reqDTO.ScrapQty,
+		SupplierQty:      reqDTO.SupplierQty,
+		ScrapWarehouseID: reqDTO.ScrapWarehouseID,
+		RestockSerials:   reqDTO.RestockSerials,
+		ScrapSerials:     reqDTO.ScrapSerials,
+		Note:             reqDTO.Note,
+	})
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(toCustomerReturnDTO(ret))
+}
+
+// CloseCustomerReturn handles POST /returns/:id/close
+func (h *ReturnHandler) CloseCustomerReturn(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+	returnID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid return id"})
+	}
+
+	ret, err := h.service.CloseCustomerReturn(c.Context(), tenantID, returnID)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(toCustomerReturnDTO(ret))
+}
+
+// CancelCustomerReturn handles POST /returns/:id/cancel
+func (h *ReturnHandler) CancelCustomerReturn(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+	returnID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid return id"})
+	}
+
+	ret, err := h.service.CancelCustomerReturn(c.Context(), tenantID, returnID)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(toCustomerReturnDTO(ret))
+}
+
+// RejectCustomerReturn handles POST /returns/:id/reject
+func (h *ReturnHandler) RejectCustomerReturn(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+	returnID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid return id"})
+	}
+
+	ret, err := h.service.RejectCustomerReturn(c.Context(), tenantID, returnID)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(toCustomerReturnDTO(ret))
+}
+
 func (h *ReturnHandler) ListCustomerPurchases(c *fiber.Ctx) error {
 	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
 	customerIDStr := c.Params("customerId")
@@ -114,3 +228,93 @@ func (h *ReturnHandler) ListCustomerPurchases(c *fiber.Ctx) error {
 	}
 	return c.JSON(resp)
 }
+
+// CreateReturnReason handles POST /return-reasons
+func (h *ReturnHandler) CreateReturnReason(c *fiber.Ctx) error {
+	var reqDTO dto.ReturnReasonRequestDTO
+	if err := c.BodyParser(&reqDTO); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body", "details": err.Error()})
+	}
+
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+	reason := &domain.ReturnReason{
+		TenantID: tenantID,
+		Code:     reqDTO.Code,
+		Name:     reqDTO.Name,
+	}
+	if err := h.service.CreateReturnReason(c.Context(), reason); err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(fiber.StatusCreated).JSON(toReturnReasonDTO(reason))
+}
+
+// UpdateReturnReason handles PUT /return-reasons/:id
+func (h *ReturnHandler) UpdateReturnReason(c *fiber.Ctx) error {
+	reasonID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid return reason id"})
+	}
+
+	var reqDTO dto.ReturnReasonRequestDTO
+	if err := c.BodyParser(&reqDTO); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body", "details": err.Error()})
+	}
+
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+	reason := &domain.ReturnReason{
+		ID:       reasonID,
+		TenantID: tenantID,
+		Name:     reqDTO.Name,
+		IsActive: reqDTO.IsActive == nil || *reqDTO.IsActive,
+	}
+	if err := h.service.UpdateReturnReason(c.Context(), reason); err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(toReturnReasonDTO(reason))
+}
+
+// ListReturnReasons handles GET /return-reasons?active=true
+func (h *ReturnHandler) ListReturnReasons(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+
+	reasons, err := h.service.ListReturnReasons(c.Context(), tenantID, c.QueryBool("active"))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	resp := make([]dto.ReturnReasonResponseDTO, len(reasons))
+	for i := range reasons {
+		resp[i] = toReturnReasonDTO(&reasons[i])
+	}
+	return c.JSON(resp)
+}
+
+// GetReturnReasonReport handles GET /returns/reasons-report?from=&to=
+func (h *ReturnHandler) GetReturnReasonReport(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+	from, to, err := parseDateRange(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	rows, err := h.service.GetReturnReasonReport(c.Context(), tenantID, from, to)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	resp := make([]dto.ReturnReasonReportRowDTO, len(rows))
+	for i, r := range rows {
+		resp[i] = dto.ReturnReasonReportRowDTO{
+			ReasonID:    r.ReasonID,
+			Code:        r.Code,
+			Name:        r.Name,
+			ReturnCount: r.ReturnCount,
+			Quantity:    r.Quantity,
+			TotalAmount: r.TotalAmount,
+			RestockQty:  r.RestockQty,
+			ScrapQty:    r.ScrapQty,
+			SupplierQty: r.SupplierQty,
+		}
+	}
+	return c.JSON(resp)
+}
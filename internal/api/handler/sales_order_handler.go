@@ -0,0 +1,184 @@
+package handler
+
+import (
+	"context"
+
+	"sancaksoft/internal/api/dto"
+	"sancaksoft/internal/api/middleware"
+	"sancaksoft/internal/domain"
+	"sancaksoft/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type SalesOrderHandler struct {
+	service *service.SalesOrderService
+}
+
+func NewSalesOrderHandler(s *service.SalesOrderService) *SalesOrderHandler {
+	return &SalesOrderHandler{service: s}
+}
+
+func toSalesOrderDTO(o *domain.SalesOrder) dto.SalesOrderResponseDTO {
+	resp := dto.SalesOrderResponseDTO{
+		ID:          o.ID,
+		OrderNumber: o.OrderNumber,
+		CustomerID:  o.CustomerID,
+		WarehouseID: o.WarehouseID,
+		Status:      o.Status,
+		Note:        o.Note,
+		TotalAmount: o.TotalAmount,
+		ConfirmedAt: o.ConfirmedAt,
+		ClosedAt:    o.ClosedAt,
+		CreatedAt:   o.CreatedAt,
+		UpdatedAt:   o.UpdatedAt,
+	}
+	for _, it := range o.Items {
+		resp.Items = append(resp.Items, dto.SalesOrderItemResponseDTO{
+			ID:           it.ID,
+			ProductID:    it.ProductID,
+			Quantity:     it.Quantity,
+			DeliveredQty: it.DeliveredQty,
+			RemainingQty: it.RemainingQty(),
+			UnitPrice:    it.UnitPrice,
+			Total:        it.Total,
+		})
+	}
+	return resp
+}
+
+// CreateSalesOrder handles POST /sales-orders
+func (h *SalesOrderHandler) CreateSalesOrder(c *fiber.Ctx) error {
+	var reqDTO dto.CreateSalesOrderRequestDTO
+	if err := c.BodyParser(&reqDTO); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body", "details": err.Error()})
+	}
+
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+	userID, _ := c.Locals(middleware.LocalsUserID).(uuid.UUID)
+
+	items := make([]domain.SalesOrderItemRequest, len(reqDTO.Items))
+	for i, item := range reqDTO.Items {
+		items[i] = domain.SalesOrderItemRequest{
+			ProductID: item.ProductID,
+			Quantity:  item.Quantity,
+			UnitPrice: item.UnitPrice,
+		}
+	}
+
+	order, err := h.service.CreateSalesOrder(c.Context(), domain.CreateSalesOrderRequest{
+		TenantID:    tenantID,
+		UserID:      userID,
+		CustomerID:  reqDTO.CustomerID,
+		WarehouseID: reqDTO.WarehouseID,
+		Note:        reqDTO.Note,
+		Items:       items,
+	})
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(fiber.StatusCreated).JSON(toSalesOrderDTO(order))
+}
+
+// ListSalesOrders handles GET /sales-orders?status=
+func (h *SalesOrderHandler) ListSalesOrders(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+
+	orders, err := h.service.ListSalesOrders(c.Context(), tenantID, domain.SalesOrderStatus(c.Query("status")))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	resp := make([]dto.SalesOrderResponseDTO, len(orders))
+	for i := range orders {
+		resp[i] = toSalesOrderDTO(&orders[i])
+	}
+	return c.JSON(resp)
+}
+
+// GetSalesOrder handles GET /sales-orders/:id
+func (h *SalesOrderHandler) GetSalesOrder(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+	orderID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid sales order id"})
+	}
+
+	order, err := h.service.GetSalesOrder(c.Context(), tenantID, orderID)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(toSalesOrderDTO(order))
+}
+
+// ConfirmSalesOrder handles POST /sales-orders/:id/confirm
+func (h *SalesOrderHandler) ConfirmSalesOrder(c *fiber.Ctx) error {
+	return h.transition(c, h.service.ConfirmSalesOrder)
+}
+
+// CloseSalesOrder handles POST /sales-orders/:id/close
+func (h *SalesOrderHandler) CloseSalesOrder(c *fiber.Ctx) error {
+	return h.transition(c, h.service.CloseSalesOrder)
+}
+
+// CancelSalesOrder handles POST /sales-orders/:id/cancel
+func (h *SalesOrderHandler) CancelSalesOrder(c *fiber.Ctx) error {
+	return h.transition(c, h.service.CancelSalesOrder)
+}
+
+func (h *SalesOrderHandler) transition(c *fiber.Ctx, fn func(ctx context.Context, tenantID, orderID uuid.UUID) (*domain.SalesOrder, error)) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+	orderID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid sales order id"})
+	}
+
+	order, err := fn(c.Context(), tenantID, orderID)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(toSalesOrderDTO(order))
+}
+
+// InvoiceSalesOrder handles POST /sales-orders/:id/invoice
+func (h *SalesOrderHandler) InvoiceSalesOrder(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+	userID, _ := c.Locals(middleware.LocalsUserID).(uuid.UUID)
+	orderID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid sales order id"})
+	}
+
+	var reqDTO dto.InvoiceSalesOrderRequestDTO
+	if err := c.BodyParser(&reqDTO); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body", "details": err.Error()})
+	}
+
+	lines := make([]domain.SalesOrderDeliveryLine, len(reqDTO.Lines))
+	for i, l := range reqDTO.Lines {
+		lines[i] = domain.SalesOrderDeliveryLine{OrderItemID: l.OrderItemID, Quantity: l.Quantity, SerialNumbers: l.SerialNumbers}
+	}
+
+	invoice, err := h.service.InvoiceSalesOrder(c.Context(), domain.InvoiceSalesOrderRequest{
+		TenantID:       tenantID,
+		UserID:         userID,
+		OrderID:        orderID,
+		IdempotencyKey: reqDTO.IdempotencyKey,
+		Lines:          lines,
+
+		CreditOverrideReason: reqDTO.CreditOverrideReason,
+	})
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(dto.InvoiceResponseDTO{
+		ID:            invoice.ID,
+		InvoiceNumber: invoice.InvoiceNumber,
+		TotalAmount:   invoice.TotalAmount,
+		DueDate:       invoice.DueDate,
+		CreatedAt:     invoice.CreatedAt,
+		Status:        "created",
+	})
+}
@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"sancaksoft/internal/api/dto"
+	"sancaksoft/internal/api/middleware"
+	"sancaksoft/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type SerialHandler struct {
+	service *service.SerialService
+}
+
+func NewSerialHandler(s *service.SerialService) *SerialHandler {
+	return &SerialHandler{service: s}
+}
+
+// ListProductSerials handles GET /products/:id/serials?warehouse_id=
+func (h *SerialHandler) ListProductSerials(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+	productID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid product id"})
+	}
+	warehouseID, err := parseOptionalUUID(c, "warehouse_id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	serials, err := h.service.ListInStockSerials(c.Context(), tenantID, productID, warehouseID)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	resp := make([]dto.SerialNumberDTO, len(serials))
+	for i, sn := range serials {
+		resp[i] = dto.SerialNumberDTO{
+			ID:           sn.ID,
+			SerialNumber: sn.SerialNumber,
+			ProductID:    sn.ProductID,
+			WarehouseID:  *sn.WarehouseID,
+			ReceivedAt:   sn.UpdatedAt,
+		}
+	}
+	return c.JSON(resp)
+}
+
+// GetSerialHistory handles GET /serials/:serial?product_id=
+func (h *SerialHandler) GetSerialHistory(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+	productID, err := parseOptionalUUID(c, "product_id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	histories, err := h.service.GetSerialHistory(c.Context(), tenantID, c.Params("serial"), productID)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	resp := make([]dto.SerialHistoryDTO, len(histories))
+	for i, hist := range histories {
+		resp[i] = dto.SerialHistoryDTO{
+			SerialNumber:  hist.SerialNumber.SerialNumber,
+			ProductID:     hist.ProductID,
+			ProductName:   hist.ProductName,
+			SKU:           hist.SKU,
+			InStock:       hist.WarehouseID != nil,
+			WarehouseID:   hist.WarehouseID,
+			WarehouseName: hist.WarehouseName,
+			Events:        make([]dto.SerialEventDTO, len(hist.Events)),
+		}
+		for j, e := range hist.Events {
+			resp[i].Events[j] = dto.SerialEventDTO{
+				Event:          e.Event,
+				MovementID:     e.MovementID,
+				MovementType:   e.MovementType,
+				WarehouseID:    e.WarehouseID,
+				WarehouseName:  e.WarehouseName,
+				DocumentType:   e.ReferenceType,
+				DocumentID:     e.ReferenceID,
+				DocumentNumber: e.DocumentNumber,
+				PartyName:      e.PartyName,
+				CreatedAt:      e.CreatedAt,
+			}
+		}
+	}
+	return c.JSON(resp)
+}
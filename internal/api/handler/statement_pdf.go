@@ -0,0 +1,162 @@
+package handler
+
+import (
+	"fmt"
+	"time"
+
+	"sancaksoft/internal/domain"
+	"sancaksoft/internal/pdf"
+
+	"github.com/shopspring/decimal"
+)
+
+var statementTypeLabels = map[string]string{
+	domain.StatementLineSale:    "Satış Faturası",
+	domain.StatementLineReturn:  "İade",
+	domain.StatementLinePayment: "Tahsilat",
+}
+
+var paymentMethodLabels = map[string]string{
+	string(domain.PaymentMethodCash):         "Nakit",
+	string(domain.PaymentMethodBankTransfer): "Havale/EFT",
+	string(domain.PaymentMethodCard):         "Kredi Kartı",
+	string(domain.PaymentMethodCheque):       "Çek",
+}
+
+// balanceLabel shows a balance as an amount marked B (borç, owed by the customer)
+// or A (alacak, owed to the customer).
+func balanceLabel(d decimal.Decimal) string {
+	switch {
+	case d.IsPositive():
+		return formatAmountTR(d) + " B"
+	case d.IsNegative():
+		return formatAmountTR(d.Neg()) + " A"
+	}
+	return formatAmountTR(d)
+}
+
+// Statement table columns: left edges for text, right edges for amounts
+const (
+	colDate        = pdfMargin + 4
+	colDocument    = 95.0
+	colDescription = 170.0
+	colDue         = 318.0
+	colDebit       = 408.0
+	colCredit      = 478.0
+	colBalance     = pdf.PageWidth - pdfMargin - 4
+)
+
+func drawStatementTableHeader(doc *pdf.Document, y float64) float64 {
+	doc.FillRect(pdfMargin, y, pdf.PageWidth-2*pdfMargin, 16, pdfHeaderFill)
+	baseline := y + 11
+	doc.Text(colDate, baseline, pdf.Bold, 8, "Tarih")
+	doc.Text(colDocument, baseline, pdf.Bold, 8, "Belge No")
+	doc.Text(colDescription, baseline, pdf.Bold, 8, "Açıklama")
+	doc.Text(colDue, baseline, pdf.Bold, 8, "Vade")
+	doc.TextRight(colDebit, baseline, pdf.Bold, 8, "Borç")
+	doc.TextRight(colCredit, baseline, pdf.Bold, 8, "Alacak")
+	doc.TextRight(colBalance, baseline, pdf.Bold, 8, "Bakiye")
+	return y + 16
+}
+
+// renderStatementPDF lays out a customer statement on A4 pages: letterhead, customer
+// block, then the movements between the balance brought forward and the closing balance.
+func renderStatementPDF(st *domain.CustomerStatement) ([]byte, error) {
+	doc := pdf.New()
+	drawPageNumber := func() {
+		doc.TextRight(pdf.PageWidth-pdfMargin, pdf.PageHeight-30, pdf.Regular, 7, fmt.Sprintf("Sayfa %d", doc.PageCount()))
+	}
+
+	y := drawLetterhead(doc, &st.Company)
+	right := pdf.PageWidth - pdfMargin
+	doc.TextRight(right, 50, pdf.Bold, 14, "HESAP EKSTRESİ")
+	doc.TextRight(right, 66, pdf.Regular, 8, "Dönem: "+statementPeriod(st))
+	doc.TextRight(right, 76, pdf.Regular, 8, "Düzenleme Tarihi: "+time.Now().Format(pdfDateLayout))
+	if y < 90 {
+		y = 90
+	}
+	doc.Line(pdfMargin, y, right, y, 0.5)
+	y += 16
+
+	doc.Text(pdfMargin, y, pdf.Regular, 8, "Sayın")
+	y += 12
+	doc.Text(pdfMargin, y, pdf.Bold, 10, st.Customer.Name)
+	y += 12
+	for _, line := range pdf.Wrap(pdf.Regular, 8, 260, st.Customer.Address) {
+		doc.Text(pdfMargin, y, pdf.Regular, 8, line)
+		y += 10
+	}
+	if place := placeName(st.Customer.District, st.Customer.City); place != "" {
+		doc.Text(pdfMargin, y, pdf.Regular, 8, place)
+		y += 10
+	}
+	y += 10
+
+	y = drawStatementTableHeader(doc, y)
+	row := func(cells func(baseline float64)) {
+		if y+14 > pdfBottom {
+			drawPageNumber()
+			doc.AddPage()
+			y = drawStatementTableHeader(doc, 50)
+		}
+		cells(y + 10)
+		y += 14
+		doc.Line(pdfMargin, y, right, y, 0.2)
+	}
+
+	row(func(b float64) {
+		doc.Text(colDescription, b, pdf.Bold, 8, "Devir Bakiyesi")
+		doc.TextRight(colBalance, b, pdf.Bold, 8, balanceLabel(st.OpeningBalance))
+	})
+	for _, l := range st.Lines {
+		description := statementTypeLabels[l.Type]
+		if l.Type == domain.StatementLinePayment {
+			description += " - " + paymentMethodLabels[l.Description]
+		} else if l.Description != "" {
+			description += " - " + l.Description
+		}
+		lines := pdf.Wrap(pdf.Regular, 8, colDue-colDescription-6, description)
+		row(func(b float64) {
+			doc.Text(colDate, b, pdf.Regular, 8, l.Date.Format(pdfDateLayout))
+			doc.Text(colDocument, b, pdf.Regular, 8, l.DocumentNumber)
+			doc.Text(colDescription, b, pdf.Regular, 8, lines[0])
+			if l.DueDate != nil {
+				doc.Text(colDue, b, pdf.Regular, 8, l.DueDate.Format(pdfDateLayout))
+			}
+			if !l.Debit.IsZero() {
+				doc.TextRight(colDebit, b, pdf.Regular, 8, formatAmountTR(l.Debit))
+			}
+			if !l.Credit.IsZero() {
+				doc.TextRight(colCredit, b, pdf.Regular, 8, formatAmountTR(l.Credit))
+			}
+			doc.TextRight(colBalance, b, pdf.Regular, 8, balanceLabel(l.Balance))
+		})
+	}
+	row(func(b float64) {
+		doc.Text(colDescription, b, pdf.Bold, 8, "Dönem Toplamı")
+		doc.TextRight(colDebit, b, pdf.Bold, 8, formatAmountTR(st.TotalDebit))
+		doc.TextRight(colCredit, b, pdf.Bold, 8, formatAmountTR(st.TotalCredit))
+	})
+	row(func(b float64) {
+		doc.Text(colDescription, b, pdf.Bold, 9, "Kapanış Bakiyesi")
+		doc.TextRight(colBalance, b, pdf.Bold, 9, balanceLabel(st.ClosingBalance))
+	})
+
+	if y+30 <= pdfBottom {
+		doc.Text(pdfMargin, y+24, pdf.Regular, 7, "B: borç bakiyesi (müşteri borçlu), A: alacak bakiyesi. Mutabık değilseniz lütfen 15 gün içinde bildiriniz.")
+	}
+	drawPageNumber()
+	return doc.Bytes()
+}
+
+// statementPeriod formats the statement's date range; the stored upper bound is exclusive.
+func statementPeriod(st *domain.CustomerStatement) string {
+	from, to := "...", time.Now().Format(pdfDateLayout)
+	if st.From != nil {
+		from = st.From.Format(pdfDateLayout)
+	}
+	if st.To != nil {
+		to = st.To.AddDate(0, 0, -1).Format(pdfDateLayout)
+	}
+	return from + " - " + to
+}
@@ -0,0 +1,39 @@
+package handler
+
+import (
+	"sancaksoft/internal/api/middleware"
+	"sancaksoft/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type StockBalanceHandler struct {
+	service *service.StockBalanceService
+}
+
+func NewStockBalanceHandler(s *service.StockBalanceService) *StockBalanceHandler {
+	return &StockBalanceHandler{service: s}
+}
+
+// CheckBalances handles GET /stock-balances/check
+func (h *StockBalanceHandler) CheckBalances(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+
+	drift, err := h.service.CheckBalances(c.Context(), tenantID)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"consistent": len(drift) == 0, "drift": drift})
+}
+
+// RepairBalances handles POST /stock-balances/repair
+func (h *StockBalanceHandler) RepairBalances(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+
+	drift, err := h.service.RepairBalances(c.Context(), tenantID)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"repaired": len(drift), "drift": drift})
+}
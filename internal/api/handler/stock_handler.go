@@ -2,6 +2,7 @@ package handler
 
 import (
 	"strings"
+	"time"
 
 	"sancaksoft/internal/api/dto"
 	"sancaksoft/internal/api/middleware"
@@ -23,10 +24,14 @@ func NewStockHandler(s *service.StockService) *StockHandler {
 // ListStockMovements handles GET /stock-movements
 func (h *StockHandler) ListStockMovements(c *fiber.Ctx) error {
 	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+	filter, err := parseStockMovementFilter(c, tenantID)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
 
-	movements, err := h.service.ListStockMovements(c.Context(), tenantID)
+	movements, err := h.service.ListStockMovements(c.Context(), filter)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
 	}
 
 	resp := make([]dto.StockMovementResponseDTO, len(movements))
@@ -39,6 +44,12 @@ func (h *StockHandler) ListStockMovements(c *fiber.Ctx) error {
 			Type:          m.Type,
 			ReferenceID:   m.ReferenceID,
 			ReferenceType: m.ReferenceType,
+			UnitCost:      m.UnitCost,
+			AssignedCost:  m.AssignedCost,
+			LotID:         m.LotID,
+			LotNumber:     m.LotNumber,
+			ExpiryDate:    formatOptionalDate(m.ExpiryDate),
+			LocationID:    m.LocationID,
 			CreatedAt:     m.CreatedAt,
 		}
 	}
@@ -46,7 +57,7 @@ func (h *StockHandler) ListStockMovements(c *fiber.Ctx) error {
 	return c.JSON(resp)
 }
 
-// GetStockBalance handles GET /stock-balance?product_id=...&warehouse_id=...
+// GetStockBalance handles GET /stock-balance?product_id=...&warehouse_id=...[&as_of=YYYY-MM-DD]
 func (h *StockHandler) GetStockBalance(c *fiber.Ctx) error {
 	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
 
@@ -73,7 +84,28 @@ func (h *StockHandler) GetStockBalance(c *fiber.Ctx) error {
 		})
 	}
 
-	balance, err := h.service.GetStockBalance(c.Context(), tenantID, productID, warehouseID)
+	// Historical balance at the end of as_of, from the movement ledger. Reservations are
+	// a present-day concept, so only the on-hand figure is returned.
+	if asOfStr := c.Query("as_of"); asOfStr != "" {
+		asOf, err := time.Parse(dateLayout, asOfStr)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "invalid as_of date, expected YYYY-MM-DD",
+			})
+		}
+		stock, err := h.service.GetStockBalanceAsOf(c.Context(), tenantID, productID, &warehouseID, asOf)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.JSON(fiber.Map{
+			"product_id":   productID,
+			"warehouse_id": warehouseID,
+			"as_of":        asOfStr,
+			"stock":        stock,
+		})
+	}
+
+	availability, err := h.service.GetStockAvailability(c.Context(), tenantID, productID, warehouseID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
@@ -81,7 +113,9 @@ func (h *StockHandler) GetStockBalance(c *fiber.Ctx) error {
 	return c.JSON(fiber.Map{
 		"product_id":   productID,
 		"warehouse_id": warehouseID,
-		"stock":         balance,
+		"stock":        availability.OnHand,
+		"reserved":     availability.Reserved,
+		"available":    availability.Available,
 	})
 }
 
@@ -106,11 +140,31 @@ func (h *StockHandler) CreateStockMovement(c *fiber.Ctx) error {
 
 	// Create domain model
 	movement := &domain.StockMovement{
-		ID:          uuid.New(),
-		ProductID:   reqDTO.ProductID,
-		WarehouseID: reqDTO.WarehouseID,
-		Quantity:    reqDTO.Quantity,
-		Type:        reqDTO.Type,
+		ID:            uuid.New(),
+		ProductID:     reqDTO.ProductID,
+		WarehouseID:   reqDTO.WarehouseID,
+		Quantity:      reqDTO.Quantity,
+		Type:          reqDTO.Type,
+		UnitCost:      reqDTO.UnitCost,
+		SerialNumbers: reqDTO.SerialNumbers,
+		LocationID:    reqDTO.LocationID,
+	}
+	if reqDTO.OccurredAt != "" {
+		occurredAt, err := time.Parse(dateLayout, reqDTO.OccurredAt)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid occurred_at date, expected YYYY-MM-DD"})
+		}
+		movement.CreatedAt = occurredAt
+	}
+	if reqDTO.LotNumber != "" {
+		movement.LotNumber = &reqDTO.LotNumber
+	}
+	if reqDTO.ExpiryDate != "" {
+		expiryDate, err := time.Parse(dateLayout, reqDTO.ExpiryDate)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid expiry_date, expected YYYY-MM-DD"})
+		}
+		movement.ExpiryDate = &expiryDate
 	}
 
 	// For IN movements, quantity should be positive
@@ -123,7 +177,7 @@ func (h *StockHandler) CreateStockMovement(c *fiber.Ctx) error {
 		if strings.HasPrefix(err.Error(), "insufficient stock") {
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
 	}
 
 	respDTO := dto.StockMovementResponseDTO{
@@ -134,6 +188,12 @@ func (h *StockHandler) CreateStockMovement(c *fiber.Ctx) error {
 		Type:          movement.Type,
 		ReferenceID:   movement.ReferenceID,
 		ReferenceType: movement.ReferenceType,
+		UnitCost:      movement.UnitCost,
+		LotID:         movement.LotID,
+		LotNumber:     movement.LotNumber,
+		ExpiryDate:    formatOptionalDate(movement.ExpiryDate),
+		SerialNumbers: movement.SerialNumbers,
+		LocationID:    movement.LocationID,
 		CreatedAt:     movement.CreatedAt,
 	}
 
@@ -202,4 +262,39 @@ func (h *StockHandler) GetStockBalanceByWarehouse(c *fiber.Ctx) error {
 	}
 
 	return c.JSON(resp)
-}
\ No newline at end of file
+}
+
+// GetProductMovements handles GET /products/:id/movements?warehouse_id=&from=&to=
+func (h *StockHandler) GetProductMovements(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+
+	productID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid product id"})
+	}
+	warehouseID, err := parseOptionalUUID(c, "warehouse_id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	from, to, err := parseDateRange(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	card, err := h.service.GetProductMovementCard(c.Context(), tenantID, productID, warehouseID, from, to)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	resp := dto.ProductMovementCardDTO{
+		ProductID:      card.ProductID,
+		WarehouseID:    card.WarehouseID,
+		OpeningBalance: card.OpeningBalance,
+		ClosingBalance: card.ClosingBalance,
+		Lines:          make([]dto.ProductMovementLineDTO, len(card.Lines)),
+	}
+	for i, l := range card.Lines {
+		resp.Lines[i] = dto.ProductMovementLineDTO(l)
+	}
+	return c.JSON(resp)
+}
@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"sancaksoft/internal/api/dto"
+	"sancaksoft/internal/api/middleware"
+	"sancaksoft/internal/domain"
+	"sancaksoft/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type SupplierHandler struct {
+	service *service.SupplierService
+}
+
+func NewSupplierHandler(s *service.SupplierService) *SupplierHandler {
+	return &SupplierHandler{service: s}
+}
+
+func toSupplierDTO(s *domain.Supplier) dto.SupplierResponseDTO {
+	return dto.SupplierResponseDTO{
+		ID:        s.ID,
+		Name:      s.Name,
+		Email:     s.Email,
+		Phone:     s.Phone,
+		Address:   s.Address,
+		TaxNumber: s.TaxNumber,
+		CreatedAt: s.CreatedAt,
+		UpdatedAt: s.UpdatedAt,
+	}
+}
+
+// CreateSupplier handles POST /suppliers
+func (h *SupplierHandler) CreateSupplier(c *fiber.Ctx) error {
+	var reqDTO dto.CreateSupplierRequestDTO
+	if err := c.BodyParser(&reqDTO); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body", "details": err.Error()})
+	}
+
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+
+	supplier := &domain.Supplier{
+		TenantID:  tenantID,
+		Name:      reqDTO.Name,
+		Email:     reqDTO.Email,
+		Phone:     reqDTO.Phone,
+		Address:   reqDTO.Address,
+		TaxNumber: reqDTO.TaxNumber,
+	}
+	if err := h.service.CreateSupplier(c.Context(), supplier); err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(fiber.StatusCreated).JSON(toSupplierDTO(supplier))
+}
+
+// ListSuppliers handles GET /suppliers
+func (h *SupplierHandler) ListSuppliers(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+
+	suppliers, err := h.service.ListSuppliers(c.Context(), tenantID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	resp := make([]dto.SupplierResponseDTO, len(suppliers))
+	for i := range suppliers {
+		resp[i] = toSupplierDTO(&suppliers[i])
+	}
+	return c.JSON(resp)
+}
+
+// GetSupplier handles GET /suppliers/:id
+func (h *SupplierHandler) GetSupplier(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+	supplierID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid supplier id"})
+	}
+
+	supplier, err := h.service.GetSupplier(c.Context(), tenantID, supplierID)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(toSupplierDTO(supplier))
+}
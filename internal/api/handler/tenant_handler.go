@@ -0,0 +1,118 @@
+package handler
+
+import (
+	"sancaksoft/internal/api/dto"
+	"sancaksoft/internal/api/middleware"
+	"sancaksoft/internal/domain"
+	"sancaksoft/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type TenantHandler struct {
+	service *service.TenantService
+}
+
+func NewTenantHandler(s *service.TenantService) *TenantHandler {
+	return &TenantHandler{service: s}
+}
+
+// GetCompanyProfile handles GET /company-profile
+func (h *TenantHandler) GetCompanyProfile(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+
+	profile, err := h.service.GetProfile(c.Context(), tenantID)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(toCompanyProfileDTO(profile))
+}
+
+// UpdateCompanyProfile handles PUT /company-profile
+func (h *TenantHandler) UpdateCompanyProfile(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+
+	var reqDTO dto.UpdateCompanyProfileRequestDTO
+	if err := c.BodyParser(&reqDTO); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body", "details": err.Error()})
+	}
+
+	profile := &domain.TenantProfile{
+		ID:        tenantID,
+		Name:      reqDTO.Name,
+		Address:   reqDTO.Address,
+		District:  reqDTO.District,
+		City:      reqDTO.City,
+		Phone:     reqDTO.Phone,
+		Email:     reqDTO.Email,
+		TaxOffice: reqDTO.TaxOffice,
+		TaxNumber: reqDTO.TaxNumber,
+	}
+	if err := h.service.UpdateProfile(c.Context(), profile); err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(toCompanyProfileDTO(profile))
+}
+
+func toCompanyProfileDTO(p *domain.TenantProfile) dto.CompanyProfileResponseDTO {
+	return dto.CompanyProfileResponseDTO{
+		ID:        p.ID,
+		Name:      p.Name,
+		Address:   p.Address,
+		District:  p.District,
+		City:      p.City,
+		Phone:     p.Phone,
+		Email:     p.Email,
+		TaxOffice: p.TaxOffice,
+		TaxNumber: p.TaxNumber,
+		UpdatedAt: p.UpdatedAt,
+	}
+}
+
+// GetInvoiceTemplate handles GET /invoice-template
+func (h *TenantHandler) GetInvoiceTemplate(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+
+	template, err := h.service.GetInvoiceTemplate(c.Context(), tenantID)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(toInvoiceTemplateDTO(template))
+}
+
+// SaveInvoiceTemplate handles PUT /invoice-template
+func (h *TenantHandler) SaveInvoiceTemplate(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+
+	var reqDTO dto.SaveInvoiceTemplateRequestDTO
+	if err := c.BodyParser(&reqDTO); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body", "details": err.Error()})
+	}
+
+	template := &domain.InvoiceTemplate{
+		TenantID:    tenantID,
+		Title:       reqDTO.Title,
+		AccentColor: reqDTO.AccentColor,
+		ShowSKU:     reqDTO.ShowSKU,
+		ShowDueDate: reqDTO.ShowDueDate,
+		BankDetails: reqDTO.BankDetails,
+		FooterNote:  reqDTO.FooterNote,
+	}
+	if err := h.service.SaveInvoiceTemplate(c.Context(), template); err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(toInvoiceTemplateDTO(template))
+}
+
+func toInvoiceTemplateDTO(t *domain.InvoiceTemplate) dto.InvoiceTemplateResponseDTO {
+	return dto.InvoiceTemplateResponseDTO{
+		Title:       t.Title,
+		AccentColor: t.AccentColor,
+		ShowSKU:     t.ShowSKU,
+		ShowDueDate: t.ShowDueDate,
+		BankDetails: t.BankDetails,
+		FooterNote:  t.FooterNote,
+		UpdatedAt:   t.UpdatedAt,
+	}
+}
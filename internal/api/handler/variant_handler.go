@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"sancaksoft/internal/api/dto"
+	"sancaksoft/internal/api/middleware"
+	"sancaksoft/internal/domain"
+	"sancaksoft/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type VariantHandler struct {
+	service *service.VariantService
+}
+
+func NewVariantHandler(s *service.VariantService) *VariantHandler {
+	return &VariantHandler{service: s}
+}
+
+func toVariantDimensionDTOs(dimensions []domain.VariantDimension) []dto.VariantDimensionDTO {
+	resp := make([]dto.VariantDimensionDTO, len(dimensions))
+	for i, d := range dimensions {
+		resp[i] = dto.VariantDimensionDTO{ID: d.ID, Name: d.Name, Values: d.Values}
+	}
+	return resp
+}
+
+// ListDimensions handles GET /products/:id/variant-dimensions
+func (h *VariantHandler) ListDimensions(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+	productID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid product id"})
+	}
+
+	dimensions, err := h.service.ListDimensions(c.Context(), tenantID, productID)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(toVariantDimensionDTOs(dimensions))
+}
+
+// SetDimensions handles PUT /products/:id/variant-dimensions
+func (h *VariantHandler) SetDimensions(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+	productID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid product id"})
+	}
+
+	var reqDTO dto.SetVariantDimensionsRequestDTO
+	if err := c.BodyParser(&reqDTO); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body", "details": err.Error()})
+	}
+
+	dimensions := make([]domain.VariantDimension, len(reqDTO.Dimensions))
+	for i, d := range reqDTO.Dimensions {
+		dimensions[i] = domain.VariantDimension{Name: d.Name, Values: d.Values}
+	}
+	dimensions, err = h.service.SetDimensions(c.Context(), tenantID, productID, dimensions)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(toVariantDimensionDTOs(dimensions))
+}
+
+// GenerateVariants handles POST /products/:id/variants
+func (h *VariantHandler) GenerateVariants(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+	productID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid product id"})
+	}
+
+	variants, err := h.service.GenerateVariants(c.Context(), tenantID, productID)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	resp := make([]dto.ProductResponseDTO, len(variants))
+	for i, v := range variants {
+		resp[i] = toProductDTO(v)
+	}
+	return c.Status(fiber.StatusCreated).JSON(resp)
+}
+
+// GetVariantSummary handles GET /products/:id/variants?warehouse_id=&from=&to=
+func (h *VariantHandler) GetVariantSummary(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+	productID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid product id"})
+	}
+	warehouseID, err := parseOptionalUUID(c, "warehouse_id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	from, to, err := parseDateRange(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	summary, err := h.service.GetVariantSummary(c.Context(), tenantID, productID, warehouseID, from, to)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	resp := dto.VariantSummaryDTO{
+		ParentID:     summary.ParentID,
+		Dimensions:   toVariantDimensionDTOs(summary.Dimensions),
+		Variants:     make([]dto.VariantSummaryLineDTO, len(summary.Variants)),
+		TotalStock:   summary.TotalStock,
+		TotalSold:    summary.TotalSold,
+		TotalRevenue: summary.TotalRevenue,
+	}
+	for i, v := range summary.Variants {
+		resp.Variants[i] = dto.VariantSummaryLineDTO(v)
+	}
+	return c.JSON(resp)
+}
+
+// SetVariantPrice handles PUT /products/:id/variant-price
+func (h *VariantHandler) SetVariantPrice(c *fiber.Ctx) error {
+	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
+	productID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid product id"})
+	}
+
+	var reqDTO dto.VariantPriceRequestDTO
+	if err := c.BodyParser(&reqDTO); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body", "details": err.Error()})
+	}
+
+	price, err := h.service.SetVariantPrice(c.Context(), tenantID, productID, reqDTO.Price)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"product_id": productID, "price": price, "price_overridden": reqDTO.Price != nil})
+}
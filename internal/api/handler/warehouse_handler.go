@@ -27,9 +27,10 @@ func (h *WarehouseHandler) CreateWarehouse(c *fiber.Ctx) error {
 	tenantID, _ := c.Locals(middleware.LocalsTenantID).(uuid.UUID)
 
 	w := &domain.Warehouse{
-		TenantID: tenantID,
-		Name:     reqDTO.Name,
-		Location: reqDTO.Location,
+		TenantID:     tenantID,
+		Name:         reqDTO.Name,
+		Location:     reqDTO.Location,
+		IsQuarantine: reqDTO.IsQuarantine,
 	}
 
 	if err := h.service.CreateWarehouse(c.Context(), w); err != nil {
@@ -37,11 +38,12 @@ func (h *WarehouseHandler) CreateWarehouse(c *fiber.Ctx) error {
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(dto.WarehouseResponseDTO{
-		ID:        w.ID,
-		Name:      w.Name,
-		Location:  w.Location,
-		CreatedAt: w.CreatedAt,
-		UpdatedAt: w.UpdatedAt,
+		ID:           w.ID,
+		Name:         w.Name,
+		Location:     w.Location,
+		IsQuarantine: w.IsQuarantine,
+		CreatedAt:    w.CreatedAt,
+		UpdatedAt:    w.UpdatedAt,
 	})
 }
 
@@ -56,11 +58,12 @@ func (h *WarehouseHandler) ListWarehouses(c *fiber.Ctx) error {
 	resp := make([]dto.WarehouseResponseDTO, len(warehouses))
 	for i, w := range warehouses {
 		resp[i] = dto.WarehouseResponseDTO{
-			ID:        w.ID,
-			Name:      w.Name,
-			Location:  w.Location,
-			CreatedAt: w.CreatedAt,
-			UpdatedAt: w.UpdatedAt,
+			ID:           w.ID,
+			Name:         w.Name,
+			Location:     w.Location,
+			IsQuarantine: w.IsQuarantine,
+			CreatedAt:    w.CreatedAt,
+			UpdatedAt:    w.UpdatedAt,
 		}
 	}
 
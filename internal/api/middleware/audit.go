@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"sancaksoft/internal/audit"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+const HeaderRequestID = "X-Request-ID"
+
+// AuditMiddleware tells the services who is making the request so their changes can be
+// audited: the user, the client IP and a request ID. The request ID is taken from the
+// X-Request-ID header or generated, and is echoed back. Runs after AuthMiddleware.
+func AuditMiddleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		requestID := c.Get(HeaderRequestID)
+		if requestID == "" || len(requestID) > 100 {
+			requestID = uuid.NewString()
+		}
+		c.Set(HeaderRequestID, requestID)
+
+		actor := audit.Actor{IP: c.IP(), RequestID: requestID}
+		if userID, ok := c.Locals(LocalsUserID).(uuid.UUID); ok {
+			actor.UserID = &userID
+		}
+		c.Locals(audit.ContextKey, actor)
+		return c.Next()
+	}
+}
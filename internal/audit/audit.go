@@ -0,0 +1,112 @@
+// Package audit carries the actor of a request to the services that record changes and
+// works out which fields a change touched.
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+
+	"github.com/google/uuid"
+)
+
+// Actor is who made a change and from where. UserID is nil for system actions.
+type Actor struct {
+	UserID    *uuid.UUID
+	IP        string
+	RequestID string
+}
+
+type contextKey struct{}
+
+// ContextKey is the key an Actor is stored under. HTTP middleware sets it as a request
+// local, which fasthttp exposes as a value of the request context handlers pass on.
+var ContextKey any = contextKey{}
+
+// WithActor returns a context carrying a.
+func WithActor(ctx context.Context, a Actor) context.Context {
+	return context.WithValue(ctx, ContextKey, a)
+}
+
+// FromContext returns the actor stored in ctx; the zero Actor for system actions.
+func FromContext(ctx context.Context) Actor {
+	a, _ := ctx.Value(ContextKey).(Actor)
+	return a
+}
+
+// Change is a field's value before and after a change, as JSON values.
+type Change struct {
+	From any `json:"from"`
+	To   any `json:"to"`
+}
+
+// Fields identifying a record or stamped by the database are left out of diffs.
+var ignoredFields = map[string]bool{"id": true, "tenant_id": true, "created_at": true, "updated_at": true}
+
+// Diff compares the JSON forms of two snapshots of an entity and returns the fields whose
+// values differ. A nil before is a create and a nil after a delete; fields that are empty
+// on the missing side are left out.
+func Diff(before, after any) (map[string]Change, error) {
+	from, err := fields(before)
+	if err != nil {
+		return nil, err
+	}
+	to, err := fields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := map[string]Change{}
+	for name, v := range to {
+		if ignoredFields[name] || reflect.DeepEqual(from[name], v) {
+			continue
+		}
+		if _, ok := from[name]; !ok && empty(v) {
+			continue
+		}
+		changes[name] = Change{From: from[name], To: v}
+	}
+	for name, v := range from {
+		if _, ok := to[name]; ok || ignoredFields[name] || empty(v) {
+			continue
+		}
+		changes[name] = Change{From: v}
+	}
+	return changes, nil
+}
+
+func fields(v any) (map[string]any, error) {
+	if v == nil {
+		return map[string]any{}, nil
+	}
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Pointer && rv.IsNil() {
+		return map[string]any{}, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	m := map[string]any{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func empty(v any) bool {
+	switch v := v.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case bool:
+		return !v
+	case float64:
+		return v == 0
+	case []any:
+		return len(v) == 0
+	case map[string]any:
+		return len(v) == 0
+	}
+	return false
+}
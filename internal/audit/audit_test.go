@@ -0,0 +1,68 @@
+package audit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type product struct {
+	ID        uuid.UUID       `json:"id"`
+	Name      string          `json:"name"`
+	Barcode   string          `json:"barcode"`
+	Price     decimal.Decimal `json:"price"`
+	TrackLots bool            `json:"track_lots"`
+	Tags      []string        `json:"tags"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+func TestDiff(t *testing.T) {
+	before := product{ID: uuid.New(), Name: "Çay", Price: decimal.RequireFromString("12.50"), Tags: []string{"içecek"}, UpdatedAt: time.Now()}
+	after := before
+	after.Price = decimal.NewFromInt(15)
+	after.TrackLots = true
+	after.UpdatedAt = time.Now().Add(time.Hour)
+
+	changes, err := Diff(before, &after)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]Change{
+		"price":      {From: "12.5", To: "15"},
+		"track_lots": {From: false, To: true},
+	}, changes)
+
+	// Decimals loaded with trailing zeros are not a change
+	after = before
+	after.Price = decimal.RequireFromString("12.5000")
+	changes, err = Diff(before, after)
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+
+	// Creates and deletes list the non-empty fields
+	changes, err = Diff(nil, before)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]Change{
+		"name":  {To: "Çay"},
+		"price": {To: "12.5"},
+		"tags":  {To: []any{"içecek"}},
+	}, changes)
+
+	var missing *product
+	changes, err = Diff(&before, missing)
+	require.NoError(t, err)
+	assert.Equal(t, Change{From: "Çay"}, changes["name"])
+	assert.Len(t, changes, 3)
+}
+
+func TestActorContext(t *testing.T) {
+	assert.Equal(t, Actor{}, FromContext(context.Background()))
+
+	userID := uuid.New()
+	ctx, cancel := context.WithTimeout(WithActor(context.Background(), Actor{UserID: &userID, IP: "10.0.0.1", RequestID: "req-1"}), time.Second)
+	defer cancel()
+	assert.Equal(t, Actor{UserID: &userID, IP: "10.0.0.1", RequestID: "req-1"}, FromContext(ctx))
+}
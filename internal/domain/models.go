@@ -9,48 +9,525 @@ import (
 
 // Product represents the product entity
 type Product struct {
-	ID        uuid.UUID       `json:"id"`
-	TenantID  uuid.UUID       `json:"tenant_id"`
-	Name      string          `json:"name"`
-	SKU       string          `json:"sku"`
-	Barcode   string          `json:"barcode"`
-	Unit      ProductUnit     `json:"unit"` // "adet" or "kg"
-	Price     decimal.Decimal `json:"price"`
-	VATRate   decimal.Decimal `json:"vat_rate"`
-	CreatedAt time.Time       `json:"created_at"`
-	UpdatedAt time.Time       `json:"updated_at"`
+	ID           uuid.UUID         `json:"id"`
+	TenantID     uuid.UUID         `json:"tenant_id"`
+	Name         string            `json:"name"`
+	SKU          string            `json:"sku"`
+	Barcode      string            `json:"barcode"`
+	Unit         ProductUnit       `json:"unit"` // "adet" or "kg"
+	Price        decimal.Decimal   `json:"price"`
+	VATRate      decimal.Decimal   `json:"vat_rate"`
+	StandardCost *decimal.Decimal  `json:"standard_cost"` // Captured on sales instead of the last purchase cost when set
+	TrackLots    bool              `json:"track_lots"`    // Receipts need a lot number; sales are allocated per lot
+	TrackSerials bool              `json:"track_serials"` // Receipts and sales name one serial number per unit
+	CategoryID   *uuid.UUID        `json:"category_id"`
+	BrandID      *uuid.UUID        `json:"brand_id"`
+	Attributes   map[string]string `json:"attributes"`  // Attribute code -> value
+	ParentID     *uuid.UUID        `json:"parent_id"`   // Set on variants
+	VariantKey   string            `json:"variant_key"` // Variant's dimension values, e.g. "M / Red"
+	CreatedAt    time.Time         `json:"created_at"`
+	UpdatedAt    time.Time         `json:"updated_at"`
 }
 
-// Customer represents the customer entity
-type Customer struct {
+// ProductFilter narrows a product list. CategoryID includes the category's subcategories;
+// Attributes match attribute codes to values.
+type ProductFilter struct {
+	TenantID   uuid.UUID
+	CategoryID *uuid.UUID
+	BrandID    *uuid.UUID
+	ParentID   *uuid.UUID // Only the variants of this product
+	Attributes map[string]string
+}
+
+// ProductCategory is a node of the category tree. Path joins the names from the root down.
+type ProductCategory struct {
+	ID        uuid.UUID  `json:"id"`
+	TenantID  uuid.UUID  `json:"tenant_id"`
+	ParentID  *uuid.UUID `json:"parent_id"`
+	Name      string     `json:"name"`
+	Path      string     `json:"path"`
+	Depth     int        `json:"depth"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+type Brand struct {
 	ID        uuid.UUID `json:"id"`
 	TenantID  uuid.UUID `json:"tenant_id"`
 	Name      string    `json:"name"`
-	Email     string    `json:"email"`
-	Phone     string    `json:"phone"`
-	Address   string    `json:"address"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+type AttributeType string
+
+const (
+	AttributeTypeText    AttributeType = "TEXT"
+	AttributeTypeNumber  AttributeType = "NUMBER"
+	AttributeTypeBoolean AttributeType = "BOOLEAN"
+	AttributeTypeOption  AttributeType = "OPTION" // One of Options
+)
+
+// CategoryAttribute is a typed attribute defined on a category. Products of the category and
+// of its subcategories can have a value for it.
+type CategoryAttribute struct {
+	ID         uuid.UUID     `json:"id"`
+	TenantID   uuid.UUID     `json:"tenant_id"`
+	CategoryID uuid.UUID     `json:"category_id"`
+	Code       string        `json:"code"`
+	Name       string        `json:"name"`
+	DataType   AttributeType `json:"data_type"`
+	Options    []string      `json:"options"`
+	CreatedAt  time.Time     `json:"created_at"`
+}
+
+// VariantDimension is an axis a parent product varies along, e.g. Size with S, M, L.
+// Each combination of dimension values is a variant: a product with its own SKU and stock.
+type VariantDimension struct {
+	ID        uuid.UUID `json:"id"`
+	TenantID  uuid.UUID `json:"tenant_id"`
+	ProductID uuid.UUID `json:"product_id"`
+	Name      string    `json:"name"`
+	Position  int       `json:"position"`
+	Values    []string  `json:"values"`
+}
+
+// VariantSummaryLine is a variant with its stock and invoiced sales.
+type VariantSummaryLine struct {
+	ProductID       uuid.UUID         `json:"product_id"`
+	Name            string            `json:"name"`
+	SKU             string            `json:"sku"`
+	Barcode         string            `json:"barcode"`
+	VariantKey      string            `json:"variant_key"`
+	Options         map[string]string `json:"options"` // Dimension name -> value
+	Price           decimal.Decimal   `json:"price"`
+	PriceOverridden bool              `json:"price_overridden"` // Price differs from the parent's
+	Stock           int               `json:"stock"`
+	SoldQuantity    int               `json:"sold_quantity"`
+	Revenue         decimal.Decimal   `json:"revenue"`
+}
+
+// VariantSummary rolls the variants of a parent product up to the parent level.
+type VariantSummary struct {
+	ParentID     uuid.UUID            `json:"parent_id"`
+	Dimensions   []VariantDimension   `json:"dimensions"`
+	Variants     []VariantSummaryLine `json:"variants"`
+	TotalStock   int                  `json:"total_stock"`
+	TotalSold    int                  `json:"total_sold"`
+	TotalRevenue decimal.Decimal      `json:"total_revenue"`
+}
+
+// KitComponent is a product shipped as part of a kit. Quantity is per kit.
+type KitComponent struct {
+	KitID         uuid.UUID       `json:"kit_id"`
+	ComponentID   uuid.UUID       `json:"component_id"`
+	ComponentName string          `json:"component_name"`
+	SKU           string          `json:"sku"`
+	Price         decimal.Decimal `json:"price"` // Component list price, the weight of its revenue share
+	Quantity      int             `json:"quantity"`
+}
+
+// KitAvailability is how many kits a warehouse can ship from the unreserved stock of their
+// components.
+type KitAvailability struct {
+	KitID       uuid.UUID                  `json:"kit_id"`
+	WarehouseID uuid.UUID                  `json:"warehouse_id"`
+	Available   int                        `json:"available"`
+	Components  []KitComponentAvailability `json:"components"`
+}
+
+type KitComponentAvailability struct {
+	ComponentID    uuid.UUID `json:"component_id"`
+	ComponentName  string    `json:"component_name"`
+	QuantityPerKit int       `json:"quantity_per_kit"`
+	Available      int       `json:"available"`     // On hand minus reserved
+	KitsPossible   int       `json:"kits_possible"` // Available / QuantityPerKit
+}
+
+// InvoiceItemComponent records what a kit line shipped and the part of the line revenue
+// allocated to each component.
+type InvoiceItemComponent struct {
+	TenantID      uuid.UUID       `json:"tenant_id"`
+	InvoiceItemID uuid.UUID       `json:"invoice_item_id"`
+	ProductID     uuid.UUID       `json:"product_id"`
+	Quantity      int             `json:"quantity"`
+	Revenue       decimal.Decimal `json:"revenue"`
+	UnitCost      decimal.Decimal `json:"unit_cost"`
+}
+
+// Customer represents the customer entity
+type Customer struct {
+	ID              uuid.UUID        `json:"id"`
+	TenantID        uuid.UUID        `json:"tenant_id"`
+	Name            string           `json:"name"`
+	Email           string           `json:"email"`
+	Phone           string           `json:"phone"`
+	Address         string           `json:"address"`
+	District        string           `json:"district"`
+	City            string           `json:"city"`
+	TaxNumber       string           `json:"tax_number"` // VKN or TCKN
+	TaxOffice       string           `json:"tax_office"`
+	CreditLimit     *decimal.Decimal `json:"credit_limit"`      // Nil: no limit
+	PaymentTermDays int              `json:"payment_term_days"` // Invoices fall due this many days after issue
+	CreatedAt       time.Time        `json:"created_at"`
+	UpdatedAt       time.Time        `json:"updated_at"`
+}
+
+// CustomerCredit is a customer's credit position as of a date: the open balance (invoices
+// less payments and returns) and the part of it past due. Available is nil without a limit.
+type CustomerCredit struct {
+	CustomerID      uuid.UUID        `json:"customer_id"`
+	AsOf            time.Time        `json:"as_of"`
+	CreditLimit     *decimal.Decimal `json:"credit_limit"`
+	PaymentTermDays int              `json:"payment_term_days"`
+	OpenBalance     decimal.Decimal  `json:"open_balance"`
+	OverdueAmount   decimal.Decimal  `json:"overdue_amount"`
+	Available       *decimal.Decimal `json:"available"`
+}
+
+type PaymentMethod string
+
+const (
+	PaymentMethodCash         PaymentMethod = "CASH"
+	PaymentMethodBankTransfer PaymentMethod = "BANK_TRANSFER"
+	PaymentMethodCard         PaymentMethod = "CARD"
+	PaymentMethodCheque       PaymentMethod = "CHEQUE"
+)
+
+// CustomerPayment is money received from a customer (tahsilat). It is not tied to an invoice:
+// payments settle the customer's invoices oldest due first.
+type CustomerPayment struct {
+	ID          uuid.UUID       `json:"id"`
+	TenantID    uuid.UUID       `json:"tenant_id"`
+	CustomerID  uuid.UUID       `json:"customer_id"`
+	Amount      decimal.Decimal `json:"amount"`
+	PaymentDate time.Time       `json:"payment_date"`
+	Method      PaymentMethod   `json:"method"`
+	Reference   string          `json:"reference"`
+	Note        string          `json:"note"`
+	CreatedBy   *uuid.UUID      `json:"created_by"`
+	CreatedAt   time.Time       `json:"created_at"`
+}
+
 // CustomerLedgerEntry represents aggregated customer movement by time bucket.
 type CustomerLedgerEntry struct {
-	PeriodStart  time.Time       `json:"period_start"`
-	SalesAmount  decimal.Decimal `json:"sales_amount"`
-	ReturnAmount decimal.Decimal `json:"return_amount"`
-	NetAmount    decimal.Decimal `json:"net_amount"`
+	PeriodStart   time.Time       `json:"period_start"`
+	SalesAmount   decimal.Decimal `json:"sales_amount"`
+	ReturnAmount  decimal.Decimal `json:"return_amount"`
+	PaymentAmount decimal.Decimal `json:"payment_amount"`
+	NetAmount     decimal.Decimal `json:"net_amount"` // Balance change: sales less returns and payments
 }
 
-// Warehouse represents the warehouse entity
-type Warehouse struct {
+// TenantProfile holds the company details printed on documents sent to customers.
+type TenantProfile struct {
 	ID        uuid.UUID `json:"id"`
-	TenantID  uuid.UUID `json:"tenant_id"`
 	Name      string    `json:"name"`
-	Location  string    `json:"location"`
-	CreatedAt time.Time `json:"created_at"`
+	Address   string    `json:"address"`
+	District  string    `json:"district"`
+	City      string    `json:"city"`
+	Phone     string    `json:"phone"`
+	Email     string    `json:"email"`
+	TaxOffice string    `json:"tax_office"`
+	TaxNumber string    `json:"tax_number"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+// InvoiceTemplate is the tenant's layout settings for printed invoices.
+type InvoiceTemplate struct {
+	TenantID    uuid.UUID `json:"tenant_id"`
+	Title       string    `json:"title"`
+	AccentColor string    `json:"accent_color"` // #RRGGBB
+	ShowSKU     bool      `json:"show_sku"`
+	ShowDueDate bool      `json:"show_due_date"`
+	BankDetails string    `json:"bank_details"`
+	FooterNote  string    `json:"footer_note"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// DefaultInvoiceTemplate is used until a tenant saves its own template.
+func DefaultInvoiceTemplate(tenantID uuid.UUID) InvoiceTemplate {
+	return InvoiceTemplate{
+		TenantID:    tenantID,
+		Title:       "FATURA",
+		AccentColor: "#1F3A5F",
+		ShowSKU:     true,
+		ShowDueDate: true,
+	}
+}
+
+// InvoiceDocumentLine is an invoice line as printed. Amounts include VAT.
+type InvoiceDocumentLine struct {
+	ProductName string          `json:"product_name"`
+	SKU         string          `json:"sku"`
+	Quantity    int             `json:"quantity"`
+	Unit        string          `json:"unit"`
+	UnitPrice   decimal.Decimal `json:"unit_price"`
+	VATRate     decimal.Decimal `json:"vat_rate"`
+	Total       decimal.Decimal `json:"total"`
+	Base        decimal.Decimal `json:"base"` // Total excluding VAT
+	VAT         decimal.Decimal `json:"vat"`
+}
+
+// VATSubtotal is the VAT-exclusive base and the VAT of an invoice's lines at one rate.
+type VATSubtotal struct {
+	Rate   decimal.Decimal `json:"rate"`
+	Base   decimal.Decimal `json:"base"`
+	Amount decimal.Decimal `json:"amount"`
+}
+
+// InvoiceDocument gathers everything printed on an invoice. Line prices include VAT, so
+// each line's VAT is taken out of its total, the breakdown sums the lines per rate and
+// Total equals the invoice amount.
+type InvoiceDocument struct {
+	Invoice  Invoice               `json:"invoice"`
+	Company  TenantProfile         `json:"company"`
+	Customer Customer              `json:"customer"`
+	Template InvoiceTemplate       `json:"template"`
+	Lines    []InvoiceDocumentLine `json:"lines"`
+	VAT      []VATSubtotal         `json:"vat"`
+	Subtotal decimal.Decimal       `json:"subtotal"` // Excluding VAT
+	VATTotal decimal.Decimal       `json:"vat_total"`
+	Total    decimal.Decimal       `json:"total"`
+}
+
+// EInvoiceProfile is the GİB scenario an e-document is issued under. e-Fatura profiles are
+// for customers registered with GİB; everyone else receives an e-Arşiv invoice.
+type EInvoiceProfile string
+
+const (
+	EInvoiceProfileBasic      EInvoiceProfile = "TEMELFATURA"
+	EInvoiceProfileCommercial EInvoiceProfile = "TICARIFATURA"
+	EInvoiceProfileArchive    EInvoiceProfile = "EARSIVFATURA"
+)
+
+type EInvoiceStatus string
+
+const (
+	EInvoiceStatusDraft    EInvoiceStatus = "DRAFT"    // Generated and validated, not sent
+	EInvoiceStatusSending  EInvoiceStatus = "SENDING"  // Handed to the integrator, answer not recorded yet
+	EInvoiceStatusSent     EInvoiceStatus = "SENT"     // Accepted by the integrator, awaiting GİB
+	EInvoiceStatusAccepted EInvoiceStatus = "ACCEPTED" // Delivered
+	EInvoiceStatusRejected EInvoiceStatus = "REJECTED" // Can be regenerated and sent again
+)
+
+// EInvoice is the UBL-TR document generated for an invoice. ETTN is the document UUID
+// and stays the same when the XML is regenerated.
+type EInvoice struct {
+	ID                uuid.UUID       `json:"id"`
+	TenantID          uuid.UUID       `json:"tenant_id"`
+	InvoiceID         uuid.UUID       `json:"invoice_id"`
+	ETTN              uuid.UUID       `json:"ettn"`
+	Profile           EInvoiceProfile `json:"profile"`
+	DocumentNumber    string          `json:"document_number"` // GİB number: series, year and 9-digit sequence
+	XML               []byte          `json:"-"`
+	Status            EInvoiceStatus  `json:"status"`
+	ProviderReference string          `json:"provider_reference"`
+	StatusMessage     string          `json:"status_message"`
+	SentAt            *time.Time      `json:"sent_at"`
+	CreatedAt         time.Time       `json:"created_at"`
+	UpdatedAt         time.Time       `json:"updated_at"`
+}
+
+// GenerateEInvoiceRequest generates the UBL-TR document of an invoice. Without a profile
+// it is TEMELFATURA for registered e-Fatura users and EARSIVFATURA otherwise.
+type GenerateEInvoiceRequest struct {
+	TenantID  uuid.UUID
+	InvoiceID uuid.UUID
+	Profile   EInvoiceProfile
+}
+
+// ImportKind is what a bulk import file holds.
+type ImportKind string
+
+const (
+	ImportKindProducts     ImportKind = "products"
+	ImportKindCustomers    ImportKind = "customers"
+	ImportKindOpeningStock ImportKind = "opening-stock"
+)
+
+// ImportRequest imports a CSV or XLSX file whose first row names the columns. Rows are
+// written in one transaction, or in transactions of ChunkSize rows so a large file that
+// stops part way can be resumed from StartRow.
+type ImportRequest struct {
+	TenantID  uuid.UUID
+	Kind      ImportKind
+	FileName  string
+	Data      []byte
+	DryRun    bool // Validate only
+	ChunkSize int  // 0: one transaction
+	StartRow  int  // Rows before this file row are skipped; 0 starts at the first data row
+}
+
+// ImportRowError is a problem with one row of an import file. Row is the row number the
+// spreadsheet shows.
+type ImportRowError struct {
+	Row     int    `json:"row"`
+	Column  string `json:"column,omitempty"`
+	Message string `json:"message"`
+}
+
+// ImportReport is the outcome of an import. Nothing is written while any row has an
+// error; NextRow is set when a chunked import stopped and is the StartRow to resume from.
+type ImportReport struct {
+	Kind      ImportKind       `json:"kind"`
+	DryRun    bool             `json:"dry_run"`
+	TotalRows int              `json:"total_rows"`
+	ValidRows int              `json:"valid_rows"`
+	Imported  int              `json:"imported"`
+	Errors    []ImportRowError `json:"errors"`
+	NextRow   int              `json:"next_row,omitempty"`
+}
+
+// Statement line types
+const (
+	StatementLineSale    = "SALE"
+	StatementLineReturn  = "RETURN"
+	StatementLinePayment = "PAYMENT"
+)
+
+// CustomerStatementRequest selects a customer's movements in [From, To); nil bounds are open.
+type CustomerStatementRequest struct {
+	TenantID   uuid.UUID
+	CustomerID uuid.UUID
+	From       *time.Time
+	To         *time.Time
+}
+
+// StatementLine is one movement on a customer statement. Invoices are debits; returns
+// and payments are credits. Balance is the running balance after the line.
+type StatementLine struct {
+	Date           time.Time       `json:"date"`
+	Type           string          `json:"type"`
+	DocumentID     uuid.UUID       `json:"document_id"`
+	DocumentNumber string          `json:"document_number"`
+	Description    string          `json:"description"`
+	DueDate        *time.Time      `json:"due_date"` // Invoices only
+	Debit          decimal.Decimal `json:"debit"`
+	Credit         decimal.Decimal `json:"credit"`
+	Balance        decimal.Decimal `json:"balance"`
+}
+
+// CustomerStatement (hesap ekstresi) lists a customer's movements in a period between the
+// balance brought forward and the closing balance. A positive balance is owed by the customer.
+type CustomerStatement struct {
+	Company        TenantProfile   `json:"company"`
+	Customer       Customer        `json:"customer"`
+	From           *time.Time      `json:"from"`
+	To             *time.Time      `json:"to"` // Exclusive
+	OpeningBalance decimal.Decimal `json:"opening_balance"`
+	Lines          []StatementLine `json:"lines"`
+	TotalDebit     decimal.Decimal `json:"total_debit"`
+	TotalCredit    decimal.Decimal `json:"total_credit"`
+	ClosingBalance decimal.Decimal `json:"closing_balance"`
+}
+
+// CustomerLedgerFilter narrows the customer ledger export; a nil CustomerID exports every
+// customer and nil dates are open. To is exclusive.
+type CustomerLedgerFilter struct {
+	TenantID   uuid.UUID
+	CustomerID *uuid.UUID
+	From       *time.Time
+	To         *time.Time
+}
+
+// CustomerLedgerLine is a statement line of one customer. Balance runs over all of the
+// customer's movements, including those before the exported period.
+type CustomerLedgerLine struct {
+	CustomerID   uuid.UUID `json:"customer_id"`
+	CustomerName string    `json:"customer_name"`
+	TaxNumber    string    `json:"tax_number"`
+	StatementLine
+}
+
+// Warehouse represents the warehouse entity
+type Warehouse struct {
+	ID           uuid.UUID `json:"id"`
+	TenantID     uuid.UUID `json:"tenant_id"`
+	Name         string    `json:"name"`
+	Location     string    `json:"location"`
+	IsQuarantine bool      `json:"is_quarantine"` // Scrap/quarantine stock, never sold from
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// WarehouseLocation is an aisle, shelf or bin inside a warehouse. Path joins the codes
+// from the top level down with '/' and is the order pickers walk the warehouse in.
+type WarehouseLocation struct {
+	ID          uuid.UUID  `json:"id"`
+	TenantID    uuid.UUID  `json:"tenant_id"`
+	WarehouseID uuid.UUID  `json:"warehouse_id"`
+	ParentID    *uuid.UUID `json:"parent_id"`
+	Code        string     `json:"code"`
+	Path        string     `json:"path"`
+	IsActive    bool       `json:"is_active"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// LocationBalance is on-hand stock of a product in one location. A nil LocationID is the
+// warehouse's unlocated stock.
+type LocationBalance struct {
+	LocationID   *uuid.UUID `json:"location_id"`
+	LocationPath *string    `json:"location_path"`
+	ProductID    uuid.UUID  `json:"product_id"`
+	ProductName  string     `json:"product_name"`
+	SKU          string     `json:"sku"`
+	Quantity     int        `json:"quantity"`
+}
+
+// LocationTransfer moves stock between locations of one warehouse; a nil location is the
+// unlocated stock. The warehouse balance does not change.
+type LocationTransfer struct {
+	ID             uuid.UUID  `json:"id"`
+	TenantID       uuid.UUID  `json:"tenant_id"`
+	WarehouseID    uuid.UUID  `json:"warehouse_id"`
+	ProductID      uuid.UUID  `json:"product_id"`
+	FromLocationID *uuid.UUID `json:"from_location_id"`
+	ToLocationID   *uuid.UUID `json:"to_location_id"`
+	Quantity       int        `json:"quantity"`
+	UserID         uuid.UUID  `json:"user_id"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+type PutawayReason string
+
+const (
+	PutawayReasonSameProduct   PutawayReason = "SAME_PRODUCT"   // The location already holds the product
+	PutawayReasonEmptyLocation PutawayReason = "EMPTY_LOCATION" // First empty location in path order
+)
+
+// PutawaySuggestion proposes where to store the open quantity of a purchase order line.
+// LocationID is nil when the warehouse has no suitable location.
+type PutawaySuggestion struct {
+	OrderItemID  uuid.UUID     `json:"order_item_id"`
+	ProductID    uuid.UUID     `json:"product_id"`
+	Quantity     int           `json:"quantity"`
+	LocationID   *uuid.UUID    `json:"location_id"`
+	LocationPath *string       `json:"location_path"`
+	Reason       PutawayReason `json:"reason,omitempty"`
+}
+
+// PickList tells a picker what to take from which location for a document, in path order.
+type PickList struct {
+	DocumentType   string         `json:"document_type"` // INVOICE or SALES_ORDER
+	DocumentID     uuid.UUID      `json:"document_id"`
+	DocumentNumber string         `json:"document_number"`
+	WarehouseID    uuid.UUID      `json:"warehouse_id"`
+	Lines          []PickListLine `json:"lines"`
+}
+
+// PickListLine is one stop of a pick list. A nil LocationID is unlocated stock; a nil
+// LotNumber leaves the lot to the picker.
+type PickListLine struct {
+	LocationID   *uuid.UUID `json:"location_id"`
+	LocationPath *string    `json:"location_path"`
+	ProductID    uuid.UUID  `json:"product_id"`
+	ProductName  string     `json:"product_name"`
+	SKU          string     `json:"sku"`
+	LotNumber    *string    `json:"lot_number"`
+	Quantity     int        `json:"quantity"`
+}
+
 // Invoice represents the invoice entity
 type Invoice struct {
 	ID            uuid.UUID       `json:"id"`
@@ -59,6 +536,9 @@ type Invoice struct {
 	CustomerID    uuid.UUID       `json:"customer_id"`
 	InvoiceNumber string          `json:"invoice_number"`
 	TotalAmount   decimal.Decimal `json:"total_amount"`
+	SalesOrderID  *uuid.UUID      `json:"sales_order_id"`
+	SalespersonID *uuid.UUID      `json:"salesperson_id"`
+	DueDate       time.Time       `json:"due_date"`
 	CreatedAt     time.Time       `json:"created_at"`
 	UpdatedAt     time.Time       `json:"updated_at"`
 }
@@ -72,9 +552,41 @@ type InvoiceItem struct {
 	Quantity  int             `json:"quantity"`
 	UnitPrice decimal.Decimal `json:"unit_price"`
 	Total     decimal.Decimal `json:"total"`
+	UnitCost  decimal.Decimal `json:"unit_cost"` // Cost captured at sale time
 	CreatedAt time.Time       `json:"created_at"`
 }
 
+// InvoiceFilter narrows invoice lists and exports; nil fields are not filtered. Dates bound
+// the issue time and To is exclusive.
+type InvoiceFilter struct {
+	TenantID    uuid.UUID
+	CustomerID  *uuid.UUID
+	WarehouseID *uuid.UUID
+	From        *time.Time
+	To          *time.Time
+}
+
+// InvoiceExportLine is an invoice line together with its invoice header.
+type InvoiceExportLine struct {
+	InvoiceID         uuid.UUID       `json:"invoice_id"`
+	InvoiceNumber     string          `json:"invoice_number"`
+	CreatedAt         time.Time       `json:"created_at"`
+	DueDate           time.Time       `json:"due_date"`
+	CustomerName      string          `json:"customer_name"`
+	CustomerTaxNumber string          `json:"customer_tax_number"`
+	WarehouseName     string          `json:"warehouse_name"`
+	SKU               string          `json:"sku"`
+	ProductName       string          `json:"product_name"`
+	Unit              string          `json:"unit"`
+	Quantity          int             `json:"quantity"`
+	UnitPrice         decimal.Decimal `json:"unit_price"`
+	VATRate           decimal.Decimal `json:"vat_rate"`
+	LineBase          decimal.Decimal `json:"line_base"`
+	LineVAT           decimal.Decimal `json:"line_vat"`
+	LineTotal         decimal.Decimal `json:"line_total"` // VAT inclusive
+	InvoiceTotal      decimal.Decimal `json:"invoice_total"`
+}
+
 // StockMovement represents a change in stock levels
 type StockMovement struct {
 	ID            uuid.UUID         `json:"id"`
@@ -85,18 +597,80 @@ type StockMovement struct {
 	Type          StockMovementType `json:"type"`
 	ReferenceID   *uuid.UUID        `json:"reference_id"`
 	ReferenceType *string           `json:"reference_type"`
+	UnitCost      *decimal.Decimal  `json:"unit_cost"`     // Entered cost of inbound movements
+	AssignedCost  *decimal.Decimal  `json:"assigned_cost"` // Set by the costing engine
+	LotID         *uuid.UUID        `json:"lot_id"`
+	LotNumber     *string           `json:"lot_number"`  // Names the lot of an inbound movement; created on first receipt
+	ExpiryDate    *time.Time        `json:"expiry_date"` // Recorded on the lot with its first receipt
+	SerialNumbers []string          `json:"serial_numbers,omitempty"`
+	LocationID    *uuid.UUID        `json:"location_id"` // Bin in the warehouse; nil for unlocated stock
 	CreatedAt     time.Time         `json:"created_at"`
 }
 
-// AuditLog represents an audit entry
+// StockMovementFilter narrows stock movement lists and exports; nil fields and an empty
+// Type are not filtered. To is exclusive.
+type StockMovementFilter struct {
+	TenantID    uuid.UUID
+	ProductID   *uuid.UUID
+	WarehouseID *uuid.UUID
+	Type        StockMovementType
+	From        *time.Time
+	To          *time.Time
+}
+
+// StockMovementExportLine is a stock movement with the names behind its IDs and the
+// document it references.
+type StockMovementExportLine struct {
+	StockMovement
+	SKU            string  `json:"sku"`
+	ProductName    string  `json:"product_name"`
+	WarehouseName  string  `json:"warehouse_name"`
+	DocumentNumber *string `json:"document_number"` // Invoice, delivery note or goods receipt number
+	PartyName      *string `json:"party_name"`      // Customer or supplier
+}
+
+// WarehouseStockFilter narrows the current stock export; nil fields are not filtered.
+type WarehouseStockFilter struct {
+	TenantID    uuid.UUID
+	ProductID   *uuid.UUID
+	WarehouseID *uuid.UUID
+}
+
+// WarehouseStock is a product's on-hand quantity in a warehouse.
+type WarehouseStock struct {
+	WarehouseID   uuid.UUID `json:"warehouse_id"`
+	WarehouseName string    `json:"warehouse_name"`
+	ProductID     uuid.UUID `json:"product_id"`
+	SKU           string    `json:"sku"`
+	ProductName   string    `json:"product_name"`
+	Unit          string    `json:"unit"`
+	Quantity      int       `json:"quantity"`
+}
+
+// AuditLog represents an audit entry. Details holds the changed fields under "changes"
+// as {"field": {"from": ..., "to": ...}} next to any action specific values.
 type AuditLog struct {
-	ID         uuid.UUID `json:"id"`
-	TenantID   uuid.UUID `json:"tenant_id"`
-	UserID     uuid.UUID `json:"user_id"`
-	EntityType string    `json:"entity_type"`
-	EntityID   uuid.UUID `json:"entity_id"`
-	Action     string    `json:"action"`
-	CreatedAt  time.Time `json:"created_at"`
+	ID         uuid.UUID      `json:"id"`
+	TenantID   uuid.UUID      `json:"tenant_id"`
+	UserID     *uuid.UUID     `json:"user_id"` // Nil for system actions
+	EntityType string         `json:"entity_type"`
+	EntityID   uuid.UUID      `json:"entity_id"`
+	Action     string         `json:"action"`
+	Details    map[string]any `json:"details"`
+	IPAddress  string         `json:"ip_address"`
+	RequestID  string         `json:"request_id"`
+	CreatedAt  time.Time      `json:"created_at"`
+}
+
+// AuditLogFilter narrows the audit log; empty fields are not filtered and To is exclusive.
+type AuditLogFilter struct {
+	TenantID   uuid.UUID
+	EntityType string
+	EntityID   *uuid.UUID
+	UserID     *uuid.UUID
+	Action     string
+	From       *time.Time
+	To         *time.Time
 }
 
 // CreateInvoiceRequest is the DTO for creating a new invoice
@@ -107,26 +681,47 @@ type CreateInvoiceRequest struct {
 	CustomerID     uuid.UUID            `json:"customer_id"`
 	IdempotencyKey uuid.UUID            `json:"idempotency_key"` // Critical for safety
 	Items          []InvoiceItemRequest `json:"items"`
+	SalesOrderID   *uuid.UUID           `json:"sales_order_id"` // Set when converting an order; its own reservation is not counted against it
+	// DeliveryNoteIDs is set when billing shipped delivery notes; their stock has already
+	// left the warehouse, so the invoice creates no stock movements.
+	DeliveryNoteIDs []uuid.UUID `json:"delivery_note_ids"`
+	// CreditOverrideReason lets a manager invoice a customer over its credit limit or with
+	// overdue invoices; the override is recorded in the audit log.
+	CreditOverrideReason string `json:"credit_override_reason"`
 }
 
 type InvoiceItemRequest struct {
-	ProductID uuid.UUID       `json:"product_id"`
-	Quantity  int             `json:"quantity"` // Must be > 0
-	UnitPrice decimal.Decimal `json:"unit_price"`
+	ProductID     uuid.UUID       `json:"product_id"`
+	Quantity      int             `json:"quantity"` // Must be > 0
+	UnitPrice     decimal.Decimal `json:"unit_price"`
+	LotNumber     string          `json:"lot_number"`     // Optional for lot-tracked products; FEFO allocation when empty
+	SerialNumbers []string        `json:"serial_numbers"` // One per unit for serialized products
 }
 
 // CustomerReturn represents a product return made by a customer.
+// Stock only moves when the return is inspected: restocked quantity goes back to
+// WarehouseID, scrapped quantity to a quarantine warehouse, supplier quantity nowhere.
 type CustomerReturn struct {
-	ID          uuid.UUID       `json:"id"`
-	TenantID    uuid.UUID       `json:"tenant_id"`
-	CustomerID  uuid.UUID       `json:"customer_id"`
-	ProductID   uuid.UUID       `json:"product_id"`
-	WarehouseID uuid.UUID       `json:"warehouse_id"`
-	Quantity    int             `json:"quantity"`
-	UnitPrice   decimal.Decimal `json:"unit_price"`
-	Total       decimal.Decimal `json:"total"`
-	Reason      string          `json:"reason"`
-	CreatedAt   time.Time       `json:"created_at"`
+	ID               uuid.UUID       `json:"id"`
+	TenantID         uuid.UUID       `json:"tenant_id"`
+	CustomerID       uuid.UUID       `json:"customer_id"`
+	ProductID        uuid.UUID       `json:"product_id"`
+	WarehouseID      uuid.UUID       `json:"warehouse_id"`
+	Quantity         int             `json:"quantity"`
+	UnitPrice        decimal.Decimal `json:"unit_price"`
+	Total            decimal.Decimal `json:"total"`
+	Reason           string          `json:"reason"`
+	ReasonID         *uuid.UUID      `json:"reason_id"`
+	Status           ReturnStatus    `json:"status"`
+	RestockQty       int             `json:"restock_qty"`
+	ScrapQty         int             `json:"scrap_qty"`
+	SupplierQty      int             `json:"supplier_qty"`
+	ScrapWarehouseID *uuid.UUID      `json:"scrap_warehouse_id"`
+	InspectionNote   string          `json:"inspection_note"`
+	ReceivedAt       *time.Time      `json:"received_at"`
+	InspectedAt      *time.Time      `json:"inspected_at"`
+	ClosedAt         *time.Time      `json:"closed_at"` // Also set when rejected or cancelled
+	CreatedAt        time.Time       `json:"created_at"`
 }
 
 type CreateCustomerReturnRequest struct {
@@ -138,6 +733,46 @@ type CreateCustomerReturnRequest struct {
 	Quantity    int             `json:"quantity"`
 	UnitPrice   decimal.Decimal `json:"unit_price"`
 	Reason      string          `json:"reason"`
+	ReasonID    *uuid.UUID      `json:"reason_id"`
+}
+
+// InspectCustomerReturnRequest splits a received return into its dispositions.
+// RestockQty + ScrapQty + SupplierQty must equal the returned quantity.
+type InspectCustomerReturnRequest struct {
+	TenantID         uuid.UUID  `json:"tenant_id"`
+	UserID           uuid.UUID  `json:"user_id"`
+	ReturnID         uuid.UUID  `json:"return_id"`
+	RestockQty       int        `json:"restock_qty"`
+	ScrapQty         int        `json:"scrap_qty"`
+	SupplierQty      int        `json:"supplier_qty"`
+	ScrapWarehouseID *uuid.UUID `json:"scrap_warehouse_id"` // Defaults to the tenant's first quarantine warehouse
+	Note             string     `json:"note"`
+	RestockSerials   []string   `json:"restock_serials"` // Serialized products: one per restocked unit
+	ScrapSerials     []string   `json:"scrap_serials"`   // Serialized products: one per scrapped unit
+}
+
+// ReturnReason is a tenant-managed return reason code used for reporting.
+type ReturnReason struct {
+	ID        uuid.UUID `json:"id"`
+	TenantID  uuid.UUID `json:"tenant_id"`
+	Code      string    `json:"code"`
+	Name      string    `json:"name"`
+	IsActive  bool      `json:"is_active"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ReturnReasonReportRow aggregates returns per reason code.
+type ReturnReasonReportRow struct {
+	ReasonID    *uuid.UUID      `json:"reason_id"`
+	Code        string          `json:"code"`
+	Name        string          `json:"name"`
+	ReturnCount int             `json:"return_count"`
+	Quantity    int             `json:"quantity"`
+	TotalAmount decimal.Decimal `json:"total_amount"`
+	RestockQty  int             `json:"restock_qty"`
+	ScrapQty    int             `json:"scrap_qty"`
+	SupplierQty int             `json:"supplier_qty"`
 }
 
 // CustomerPurchaseSummary represents what a customer bought and what is still returnable.
@@ -154,13 +789,1029 @@ type CustomerPurchaseSummary struct {
 	LastUnitPrice decimal.Decimal `json:"last_unit_price"`
 }
 
+// SalesOrder is a customer order taken before invoicing. Confirmed orders reserve
+// their undelivered quantities in WarehouseID until invoiced or closed.
+type SalesOrder struct {
+	ID          uuid.UUID        `json:"id"`
+	TenantID    uuid.UUID        `json:"tenant_id"`
+	CustomerID  uuid.UUID        `json:"customer_id"`
+	WarehouseID uuid.UUID        `json:"warehouse_id"`
+	OrderNumber string           `json:"order_number"`
+	Status      SalesOrderStatus `json:"status"`
+	Note        string           `json:"note"`
+	TotalAmount decimal.Decimal  `json:"total_amount"`
+	ConfirmedAt *time.Time       `json:"confirmed_at"`
+	ClosedAt    *time.Time       `json:"closed_at"`
+	CreatedAt   time.Time        `json:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at"`
+	Items       []SalesOrderItem `json:"items"`
+}
+
+// SalesOrderItem is an order line. Quantity - DeliveredQty is still reserved while
+// the order is CONFIRMED or PARTIALLY_DELIVERED.
+type SalesOrderItem struct {
+	ID           uuid.UUID       `json:"id"`
+	TenantID     uuid.UUID       `json:"tenant_id"`
+	OrderID      uuid.UUID       `json:"order_id"`
+	ProductID    uuid.UUID       `json:"product_id"`
+	Quantity     int             `json:"quantity"`
+	DeliveredQty int             `json:"delivered_qty"`
+	UnitPrice    decimal.Decimal `json:"unit_price"`
+	Total        decimal.Decimal `json:"total"`
+	CreatedAt    time.Time       `json:"created_at"`
+}
+
+// RemainingQty returns the undelivered quantity of the line.
+func (i SalesOrderItem) RemainingQty() int {
+	return i.Quantity - i.DeliveredQty
+}
+
+type CreateSalesOrderRequest struct {
+	TenantID    uuid.UUID               `json:"tenant_id"`
+	UserID      uuid.UUID               `json:"user_id"`
+	CustomerID  uuid.UUID               `json:"customer_id"`
+	WarehouseID uuid.UUID               `json:"warehouse_id"`
+	Note        string                  `json:"note"`
+	Items       []SalesOrderItemRequest `json:"items"`
+}
+
+type SalesOrderItemRequest struct {
+	ProductID uuid.UUID       `json:"product_id"`
+	Quantity  int             `json:"quantity"`
+	UnitPrice decimal.Decimal `json:"unit_price"`
+}
+
+// InvoiceSalesOrderRequest converts (part of) a confirmed order into an invoice.
+// An empty Lines slice invoices every remaining quantity.
+type InvoiceSalesOrderRequest struct {
+	TenantID       uuid.UUID                `json:"tenant_id"`
+	UserID         uuid.UUID                `json:"user_id"`
+	OrderID        uuid.UUID                `json:"order_id"`
+	IdempotencyKey uuid.UUID                `json:"idempotency_key"`
+	Lines          []SalesOrderDeliveryLine `json:"lines"`
+	// CreditOverrideReason is passed to the invoice; see CreateInvoiceRequest.
+	CreditOverrideReason string `json:"credit_override_reason"`
+}
+
+type SalesOrderDeliveryLine struct {
+	OrderItemID   uuid.UUID `json:"order_item_id"`
+	Quantity      int       `json:"quantity"`
+	SerialNumbers []string  `json:"serial_numbers"` // One per unit for serialized products
+}
+
+// StockAvailability is the available-to-promise figure of a product in a warehouse.
+type StockAvailability struct {
+	ProductID   uuid.UUID `json:"product_id"`
+	WarehouseID uuid.UUID `json:"warehouse_id"`
+	OnHand      int       `json:"on_hand"`
+	Reserved    int       `json:"reserved"`
+	Available   int       `json:"available"`
+}
+
+// ProductMovementLine is one row of a product's movement card (stok kartı): the movement,
+// the document it came from and the balance after it.
+type ProductMovementLine struct {
+	ID             uuid.UUID         `json:"id"`
+	WarehouseID    uuid.UUID         `json:"warehouse_id"`
+	WarehouseName  string            `json:"warehouse_name"`
+	Type           StockMovementType `json:"type"`
+	Quantity       int               `json:"quantity"`
+	RunningBalance int               `json:"running_balance"`
+	ReferenceType  *string           `json:"reference_type"`
+	ReferenceID    *uuid.UUID        `json:"reference_id"`
+	DocumentNumber *string           `json:"document_number"` // Invoice, delivery note or goods receipt number
+	PartyName      *string           `json:"party_name"`      // Customer or supplier
+	CreatedAt      time.Time         `json:"created_at"`
+}
+
+// ProductMovementCard lists a product's movements in a period with opening and closing balances.
+type ProductMovementCard struct {
+	ProductID      uuid.UUID             `json:"product_id"`
+	WarehouseID    *uuid.UUID            `json:"warehouse_id"`
+	OpeningBalance int                   `json:"opening_balance"`
+	ClosingBalance int                   `json:"closing_balance"`
+	Lines          []ProductMovementLine `json:"lines"`
+}
+
+// StockBalanceDrift is a product/warehouse whose materialized balance disagrees with
+// the sum of its stock movements. LotID is set for a stock_lot_balances row and
+// LocationID for a stock_location_balances row.
+type StockBalanceDrift struct {
+	ProductID   uuid.UUID  `json:"product_id"`
+	WarehouseID uuid.UUID  `json:"warehouse_id"`
+	LotID       *uuid.UUID `json:"lot_id"`
+	LocationID  *uuid.UUID `json:"location_id"`
+	BalanceQty  int        `json:"balance_qty"`
+	LedgerQty   int        `json:"ledger_qty"`
+}
+
+// Lot is a batch of a lot-tracked product, identified by its lot number.
+type Lot struct {
+	ID         uuid.UUID  `json:"id"`
+	TenantID   uuid.UUID  `json:"tenant_id"`
+	ProductID  uuid.UUID  `json:"product_id"`
+	LotNumber  string     `json:"lot_number"`
+	ExpiryDate *time.Time `json:"expiry_date"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// LotBalance is the on-hand quantity of one lot in one warehouse.
+type LotBalance struct {
+	LotID         uuid.UUID  `json:"lot_id"`
+	LotNumber     string     `json:"lot_number"`
+	ExpiryDate    *time.Time `json:"expiry_date"`
+	DaysToExpiry  *int       `json:"days_to_expiry"` // Negative once expired; nil without an expiry date
+	ProductID     uuid.UUID  `json:"product_id"`
+	ProductName   string     `json:"product_name"`
+	SKU           string     `json:"sku"`
+	WarehouseID   uuid.UUID  `json:"warehouse_id"`
+	WarehouseName string     `json:"warehouse_name"`
+	Quantity      int        `json:"quantity"`
+}
+
+// Expired reports whether the lot is past its expiry date.
+func (b LotBalance) Expired() bool {
+	return b.DaysToExpiry != nil && *b.DaysToExpiry < 0
+}
+
+type LotExpiryStatus string
+
+const (
+	LotExpiryStatusExpired    LotExpiryStatus = "EXPIRED"
+	LotExpiryStatusNearExpiry LotExpiryStatus = "NEAR_EXPIRY"
+)
+
+// LotExpiryLine is a lot balance that has expired or expires within the report window.
+type LotExpiryLine struct {
+	LotBalance
+	Status LotExpiryStatus `json:"status"`
+}
+
+// LotShipment is a sales document that shipped units of a lot to a customer.
+type LotShipment struct {
+	CustomerID     uuid.UUID `json:"customer_id"`
+	CustomerName   string    `json:"customer_name"`
+	DocumentType   string    `json:"document_type"` // INVOICE or DELIVERY_NOTE
+	DocumentID     uuid.UUID `json:"document_id"`
+	DocumentNumber string    `json:"document_number"`
+	WarehouseID    uuid.UUID `json:"warehouse_id"`
+	Quantity       int       `json:"quantity"` // Net of cancellations
+	ShippedAt      time.Time `json:"shipped_at"`
+}
+
+// LotTrace lists who received a lot, for recalls.
+type LotTrace struct {
+	Lot          Lot           `json:"lot"`
+	ProductName  string        `json:"product_name"`
+	SKU          string        `json:"sku"`
+	TotalShipped int           `json:"total_shipped"`
+	Shipments    []LotShipment `json:"shipments"`
+}
+
+// SerialNumber is one unit of a serialized product. WarehouseID is nil while the unit is
+// out of stock.
+type SerialNumber struct {
+	ID           uuid.UUID  `json:"id"`
+	TenantID     uuid.UUID  `json:"tenant_id"`
+	ProductID    uuid.UUID  `json:"product_id"`
+	SerialNumber string     `json:"serial_number"`
+	WarehouseID  *uuid.UUID `json:"warehouse_id"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+type SerialEventType string
+
+const (
+	SerialEventReceived          SerialEventType = "RECEIVED"
+	SerialEventSold              SerialEventType = "SOLD"
+	SerialEventReturned          SerialEventType = "RETURNED"
+	SerialEventShipmentCancelled SerialEventType = "SHIPMENT_CANCELLED"
+	SerialEventIssued            SerialEventType = "ISSUED"
+)
+
+// SerialEvent is one stock movement of a serial number with its document and party.
+type SerialEvent struct {
+	Event          SerialEventType   `json:"event"`
+	MovementID     uuid.UUID         `json:"movement_id"`
+	MovementType   StockMovementType `json:"movement_type"`
+	WarehouseID    uuid.UUID         `json:"warehouse_id"`
+	WarehouseName  string            `json:"warehouse_name"`
+	ReferenceType  *string           `json:"reference_type"`
+	ReferenceID    *uuid.UUID        `json:"reference_id"`
+	DocumentNumber *string           `json:"document_number"`
+	PartyName      *string           `json:"party_name"` // Customer or supplier
+	CreatedAt      time.Time         `json:"created_at"`
+}
+
+// SerialHistory is a serial number with every movement of the unit, oldest first.
+type SerialHistory struct {
+	SerialNumber
+	ProductName   string        `json:"product_name"`
+	SKU           string        `json:"sku"`
+	WarehouseName *string       `json:"warehouse_name"`
+	Events        []SerialEvent `json:"events"`
+}
+
+// Supplier is a vendor that purchase orders are placed with.
+type Supplier struct {
+	ID        uuid.UUID `json:"id"`
+	TenantID  uuid.UUID `json:"tenant_id"`
+	Name      string    `json:"name"`
+	Email     string    `json:"email"`
+	Phone     string    `json:"phone"`
+	Address   string    `json:"address"`
+	TaxNumber string    `json:"tax_number"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// PurchaseOrder is an order placed with a supplier. ORDERED lines count as "on order"
+// until received through goods receipts or short-closed.
+type PurchaseOrder struct {
+	ID           uuid.UUID           `json:"id"`
+	TenantID     uuid.UUID           `json:"tenant_id"`
+	SupplierID   uuid.UUID           `json:"supplier_id"`
+	WarehouseID  uuid.UUID           `json:"warehouse_id"`
+	OrderNumber  string              `json:"order_number"`
+	Status       PurchaseOrderStatus `json:"status"`
+	ExpectedDate *time.Time          `json:"expected_date"`
+	Note         string              `json:"note"`
+	TotalAmount  decimal.Decimal     `json:"total_amount"`
+	OrderedAt    *time.Time          `json:"ordered_at"`
+	ClosedAt     *time.Time          `json:"closed_at"`
+	CreatedAt    time.Time           `json:"created_at"`
+	UpdatedAt    time.Time           `json:"updated_at"`
+	Items        []PurchaseOrderItem `json:"items"`
+}
+
+type PurchaseOrderItem struct {
+	ID          uuid.UUID       `json:"id"`
+	TenantID    uuid.UUID       `json:"tenant_id"`
+	OrderID     uuid.UUID       `json:"order_id"`
+	ProductID   uuid.UUID       `json:"product_id"`
+	Quantity    int             `json:"quantity"`
+	ReceivedQty int             `json:"received_qty"`
+	UnitPrice   decimal.Decimal `json:"unit_price"`
+	Total       decimal.Decimal `json:"total"`
+	CreatedAt   time.Time       `json:"created_at"`
+}
+
+// RemainingQty is the quantity still expected from the supplier.
+func (i PurchaseOrderItem) RemainingQty() int {
+	if i.ReceivedQty >= i.Quantity {
+		return 0
+	}
+	return i.Quantity - i.ReceivedQty
+}
+
+// BillOfMaterials lists the components consumed to produce OutputQuantity units of a product.
+type BillOfMaterials struct {
+	TenantID       uuid.UUID      `json:"tenant_id"`
+	ProductID      uuid.UUID      `json:"product_id"`
+	OutputQuantity int            `json:"output_quantity"`
+	Note           string         `json:"note"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+	Components     []BOMComponent `json:"components"`
+}
+
+type BOMComponent struct {
+	ComponentID   uuid.UUID `json:"component_id"`
+	ComponentName string    `json:"component_name"`
+	SKU           string    `json:"sku"`
+	Quantity      int       `json:"quantity"`
+}
+
+// AssemblyOrder produces a product from its bill of materials. Completing it consumes the
+// components (OUT) and produces the goods (IN) in one transaction.
+type AssemblyOrder struct {
+	ID          uuid.UUID                `json:"id"`
+	TenantID    uuid.UUID                `json:"tenant_id"`
+	ProductID   uuid.UUID                `json:"product_id"`
+	WarehouseID uuid.UUID                `json:"warehouse_id"`
+	OrderNumber string                   `json:"order_number"`
+	Status      AssemblyOrderStatus      `json:"status"`
+	Quantity    int                      `json:"quantity"`
+	ProducedQty int                      `json:"produced_qty"`
+	ScrapQty    int                      `json:"scrap_qty"`
+	LotID       *uuid.UUID               `json:"lot_id"`
+	TotalCost   decimal.Decimal          `json:"total_cost"`
+	UnitCost    decimal.Decimal          `json:"unit_cost"`
+	Note        string                   `json:"note"`
+	CompletedAt *time.Time               `json:"completed_at"`
+	CreatedAt   time.Time                `json:"created_at"`
+	UpdatedAt   time.Time                `json:"updated_at"`
+	Components  []AssemblyOrderComponent `json:"components"`
+}
+
+// YieldPct is the produced share of the planned output, e.g. 96.00 for 24 of 25.
+func (o AssemblyOrder) YieldPct() decimal.Decimal {
+	return decimal.NewFromInt(int64(o.ProducedQty)).Mul(decimal.NewFromInt(100)).Div(decimal.NewFromInt(int64(o.Quantity))).Round(2)
+}
+
+type AssemblyOrderComponent struct {
+	ID        uuid.UUID       `json:"id"`
+	TenantID  uuid.UUID       `json:"tenant_id"`
+	OrderID   uuid.UUID       `json:"order_id"`
+	ProductID uuid.UUID       `json:"product_id"`
+	Quantity  int             `json:"quantity"`
+	UnitCost  decimal.Decimal `json:"unit_cost"`
+}
+
+type CreateAssemblyOrderRequest struct {
+	TenantID    uuid.UUID `json:"tenant_id"`
+	ProductID   uuid.UUID `json:"product_id"`
+	WarehouseID uuid.UUID `json:"warehouse_id"`
+	Quantity    int       `json:"quantity"`
+	Note        string    `json:"note"`
+}
+
+// CompleteAssemblyOrderRequest records the outcome: ProducedQty + ScrapQty must equal the
+// planned quantity. LotNumber (and optional ExpiryDate) is required for lot-tracked products.
+type CompleteAssemblyOrderRequest struct {
+	TenantID    uuid.UUID  `json:"tenant_id"`
+	OrderID     uuid.UUID  `json:"order_id"`
+	ProducedQty int        `json:"produced_qty"`
+	ScrapQty    int        `json:"scrap_qty"`
+	LotNumber   string     `json:"lot_number"`
+	ExpiryDate  *time.Time `json:"expiry_date"`
+}
+
+type CreatePurchaseOrderRequest struct {
+	TenantID     uuid.UUID                  `json:"tenant_id"`
+	UserID       uuid.UUID                  `json:"user_id"`
+	SupplierID   uuid.UUID                  `json:"supplier_id"`
+	WarehouseID  uuid.UUID                  `json:"warehouse_id"`
+	ExpectedDate *time.Time                 `json:"expected_date"`
+	Note         string                     `json:"note"`
+	Items        []PurchaseOrderItemRequest `json:"items"`
+}
+
+type PurchaseOrderItemRequest struct {
+	ProductID uuid.UUID       `json:"product_id"`
+	Quantity  int             `json:"quantity"`
+	UnitPrice decimal.Decimal `json:"unit_price"`
+}
+
+// GoodsReceipt records goods arriving against a purchase order; each line writes an
+// IN movement into WarehouseID.
+type GoodsReceipt struct {
+	ID            uuid.UUID          `json:"id"`
+	TenantID      uuid.UUID          `json:"tenant_id"`
+	OrderID       uuid.UUID          `json:"order_id"`
+	WarehouseID   uuid.UUID          `json:"warehouse_id"`
+	ReceiptNumber string             `json:"receipt_number"`
+	Note          string             `json:"note"`
+	ReceivedAt    time.Time          `json:"received_at"`
+	Items         []GoodsReceiptItem `json:"items"`
+}
+
+type GoodsReceiptItem struct {
+	ID          uuid.UUID       `json:"id"`
+	TenantID    uuid.UUID       `json:"tenant_id"`
+	ReceiptID   uuid.UUID       `json:"receipt_id"`
+	OrderItemID uuid.UUID       `json:"order_item_id"`
+	ProductID   uuid.UUID       `json:"product_id"`
+	Quantity    int             `json:"quantity"`
+	UnitCost    decimal.Decimal `json:"unit_cost"`
+}
+
+type CreateGoodsReceiptRequest struct {
+	TenantID    uuid.UUID                   `json:"tenant_id"`
+	UserID      uuid.UUID                   `json:"user_id"`
+	OrderID     uuid.UUID                   `json:"order_id"`
+	WarehouseID *uuid.UUID                  `json:"warehouse_id"` // Defaults to the order's warehouse
+	Note        string                      `json:"note"`
+	Lines       []PurchaseOrderQuantityLine `json:"lines"`
+}
+
+// PurchaseOrderQuantityLine refers to a purchase order line. UnitPrice is only used
+// on supplier invoices.
+type PurchaseOrderQuantityLine struct {
+	OrderItemID   uuid.UUID       `json:"order_item_id"`
+	Quantity      int             `json:"quantity"`
+	UnitPrice     decimal.Decimal `json:"unit_price"`
+	LotNumber     string          `json:"lot_number"`     // Goods receipts of lot-tracked products only
+	ExpiryDate    *time.Time      `json:"expiry_date"`    // Goods receipts of lot-tracked products only
+	SerialNumbers []string        `json:"serial_numbers"` // Goods receipts of serialized products only, one per unit
+	LocationID    *uuid.UUID      `json:"location_id"`    // Goods receipts only; putaway location in the receiving warehouse
+}
+
+// PurchaseInvoice is the supplier's invoice for a purchase order, used for matching.
+type PurchaseInvoice struct {
+	ID            uuid.UUID             `json:"id"`
+	TenantID      uuid.UUID             `json:"tenant_id"`
+	OrderID       uuid.UUID             `json:"order_id"`
+	SupplierID    uuid.UUID             `json:"supplier_id"`
+	InvoiceNumber string                `json:"invoice_number"`
+	InvoiceDate   time.Time             `json:"invoice_date"`
+	TotalAmount   decimal.Decimal       `json:"total_amount"`
+	CreatedAt     time.Time             `json:"created_at"`
+	Items         []PurchaseInvoiceItem `json:"items"`
+}
+
+type PurchaseInvoiceItem struct {
+	ID          uuid.UUID       `json:"id"`
+	TenantID    uuid.UUID       `json:"tenant_id"`
+	InvoiceID   uuid.UUID       `json:"invoice_id"`
+	OrderItemID uuid.UUID       `json:"order_item_id"`
+	ProductID   uuid.UUID       `json:"product_id"`
+	Quantity    int             `json:"quantity"`
+	UnitPrice   decimal.Decimal `json:"unit_price"`
+	Total       decimal.Decimal `json:"total"`
+}
+
+type CreatePurchaseInvoiceRequest struct {
+	TenantID      uuid.UUID                   `json:"tenant_id"`
+	UserID        uuid.UUID                   `json:"user_id"`
+	OrderID       uuid.UUID                   `json:"order_id"`
+	InvoiceNumber string                      `json:"invoice_number"`
+	InvoiceDate   time.Time                   `json:"invoice_date"`
+	Lines         []PurchaseOrderQuantityLine `json:"lines"`
+}
+
+// PurchaseMatchLine compares ordered, received and invoiced figures of one purchase
+// order line (three-way match). Discrepancies is empty when the line matches.
+type PurchaseMatchLine struct {
+	OrderID          uuid.UUID       `json:"order_id"`
+	OrderNumber      string          `json:"order_number"`
+	OrderItemID      uuid.UUID       `json:"order_item_id"`
+	ProductID        uuid.UUID       `json:"product_id"`
+	ProductName      string          `json:"product_name"`
+	OrderedQty       int             `json:"ordered_qty"`
+	OrderedPrice     decimal.Decimal `json:"ordered_price"`
+	ReceivedQty      int             `json:"received_qty"`
+	InvoicedQty      int             `json:"invoiced_qty"`
+	InvoicedAmount   decimal.Decimal `json:"invoiced_amount"`
+	InvoicedMinPrice decimal.Decimal `json:"invoiced_min_price"`
+	InvoicedMaxPrice decimal.Decimal `json:"invoiced_max_price"`
+	Discrepancies    []string        `json:"discrepancies"`
+}
+
+// Three-way match discrepancy codes.
+const (
+	MatchOverReceived = "OVER_RECEIVED" // Received more than ordered
+	MatchInvoicedQty  = "INVOICED_QTY"  // Invoiced quantity differs from received quantity
+	MatchPrice        = "PRICE"         // Invoiced unit price differs from the order price
+	MatchNotInvoiced  = "NOT_INVOICED"  // Goods received but no supplier invoice yet
+)
+
+// OpenPurchaseLine is a purchase order line with quantity still on order.
+type OpenPurchaseLine struct {
+	OrderID      uuid.UUID       `json:"order_id"`
+	OrderNumber  string          `json:"order_number"`
+	SupplierID   uuid.UUID       `json:"supplier_id"`
+	SupplierName string          `json:"supplier_name"`
+	WarehouseID  uuid.UUID       `json:"warehouse_id"`
+	ProductID    uuid.UUID       `json:"product_id"`
+	ProductName  string          `json:"product_name"`
+	OrderedQty   int             `json:"ordered_qty"`
+	ReceivedQty  int             `json:"received_qty"`
+	RemainingQty int             `json:"remaining_qty"`
+	UnitPrice    decimal.Decimal `json:"unit_price"`
+	ExpectedDate *time.Time      `json:"expected_date"`
+}
+
+// PurchaseOrderStatus defines the lifecycle of a purchase order:
+// DRAFT -> ORDERED -> PARTIALLY_RECEIVED -> RECEIVED, or CLOSED/CANCELLED.
+type PurchaseOrderStatus string
+
+const (
+	PurchaseOrderStatusDraft             PurchaseOrderStatus = "DRAFT"
+	PurchaseOrderStatusOrdered           PurchaseOrderStatus = "ORDERED"
+	PurchaseOrderStatusPartiallyReceived PurchaseOrderStatus = "PARTIALLY_RECEIVED"
+	PurchaseOrderStatusReceived          PurchaseOrderStatus = "RECEIVED"
+	PurchaseOrderStatusClosed            PurchaseOrderStatus = "CLOSED"
+	PurchaseOrderStatusCancelled         PurchaseOrderStatus = "CANCELLED"
+)
+
+// StockLevel holds the replenishment settings of a product in a warehouse. Stock is
+// low when available quantity falls to ReorderLevel and critical below MinLevel.
+type StockLevel struct {
+	ID           uuid.UUID  `json:"id"`
+	TenantID     uuid.UUID  `json:"tenant_id"`
+	ProductID    uuid.UUID  `json:"product_id"`
+	WarehouseID  uuid.UUID  `json:"warehouse_id"`
+	MinLevel     int        `json:"min_level"`
+	ReorderLevel int        `json:"reorder_level"`
+	MaxLevel     int        `json:"max_level"`
+	SafetyStock  int        `json:"safety_stock"`
+	LeadTimeDays int        `json:"lead_time_days"`
+	SupplierID   *uuid.UUID `json:"supplier_id"` // Preferred supplier for suggested purchase orders
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// StockLevelStatus is a configured stock level together with current figures.
+type StockLevelStatus struct {
+	StockLevel
+	ProductName       string          `json:"product_name"`
+	WarehouseName     string          `json:"warehouse_name"`
+	OnHand            int             `json:"on_hand"`
+	Reserved          int             `json:"reserved"`
+	Available         int             `json:"available"`
+	OnOrder           int             `json:"on_order"`
+	SoldInWindow      int             `json:"sold_in_window"`
+	LastSupplierID    *uuid.UUID      `json:"last_supplier_id"`
+	LastPurchasePrice decimal.Decimal `json:"last_purchase_price"`
+}
+
+// LowStockItem is a stock level whose available quantity reached its reorder level.
+type LowStockItem struct {
+	StockLevelStatus
+	Severity string `json:"severity"` // BELOW_MIN or AT_REORDER
+}
+
+const (
+	LowStockBelowMin  = "BELOW_MIN"
+	LowStockAtReorder = "AT_REORDER"
+)
+
+// ReplenishmentSuggestion proposes a purchase quantity for a product in a warehouse.
+// ReorderPoint = max(ReorderLevel, ceil(AvgDailySales*LeadTimeDays) + SafetyStock) and
+// the suggestion orders up to max(MaxLevel, ReorderPoint).
+type ReplenishmentSuggestion struct {
+	ProductID     uuid.UUID       `json:"product_id"`
+	ProductName   string          `json:"product_name"`
+	WarehouseID   uuid.UUID       `json:"warehouse_id"`
+	WarehouseName string          `json:"warehouse_name"`
+	SupplierID    *uuid.UUID      `json:"supplier_id"`
+	Available     int             `json:"available"`
+	OnOrder       int             `json:"on_order"`
+	AvgDailySales decimal.Decimal `json:"avg_daily_sales"`
+	LeadTimeDays  int             `json:"lead_time_days"`
+	SafetyStock   int             `json:"safety_stock"`
+	ReorderPoint  int             `json:"reorder_point"`
+	TargetLevel   int             `json:"target_level"`
+	SuggestedQty  int             `json:"suggested_qty"`
+	UnitPrice     decimal.Decimal `json:"unit_price"` // Last purchase price, zero if never bought
+}
+
+// ReplenishmentOrdersResult lists the draft purchase orders created from suggestions and
+// the suggestions skipped because no supplier is known.
+type ReplenishmentOrdersResult struct {
+	Orders  []PurchaseOrder           `json:"orders"`
+	Skipped []ReplenishmentSuggestion `json:"skipped"`
+}
+
+// ProductCost is the costing engine's current on-hand quantity and value for a product
+// across all warehouses.
+type ProductCost struct {
+	TenantID   uuid.UUID       `json:"tenant_id"`
+	ProductID  uuid.UUID       `json:"product_id"`
+	Method     CostingMethod   `json:"costing_method"`
+	OnHand     int             `json:"on_hand"`
+	UnitCost   decimal.Decimal `json:"unit_cost"`
+	StockValue decimal.Decimal `json:"stock_value"`
+	UpdatedAt  time.Time       `json:"updated_at"`
+}
+
+// StockValuationLine values a product's stock (optionally in one warehouse) at its current unit cost.
+type StockValuationLine struct {
+	ProductID    uuid.UUID       `json:"product_id"`
+	ProductName  string          `json:"product_name"`
+	SKU          string          `json:"sku"`
+	CategoryID   *uuid.UUID      `json:"category_id"`
+	CategoryName *string         `json:"category_name"`
+	BrandID      *uuid.UUID      `json:"brand_id"`
+	BrandName    *string         `json:"brand_name"`
+	Quantity     int             `json:"quantity"`
+	UnitCost     decimal.Decimal `json:"unit_cost"`
+	Value        decimal.Decimal `json:"value"`
+}
+
+// StockGroupBy is the dimension a stock valuation can be summed by.
+type StockGroupBy string
+
+const (
+	StockByCategory StockGroupBy = "category" // The product's current category
+	StockByBrand    StockGroupBy = "brand"
+)
+
+// StockValuationGroup sums the valuation lines of one category or brand. Key is its id, or
+// empty for products without one.
+type StockValuationGroup struct {
+	Key      string          `json:"key"`
+	Label    string          `json:"label"`
+	Quantity int             `json:"quantity"`
+	Value    decimal.Decimal `json:"value"`
+}
+
+// CostOfGoodsLine sums the cost of outbound (SALE/OUT) movements of a product.
+type CostOfGoodsLine struct {
+	ProductID   uuid.UUID       `json:"product_id"`
+	ProductName string          `json:"product_name"`
+	Quantity    int             `json:"quantity"`
+	Cost        decimal.Decimal `json:"cost"`
+}
+
+// InvoiceLineProfit is the gross profit of one invoice line, costed from the stock
+// movements that shipped it (the invoice itself or the delivery notes it billed).
+type InvoiceLineProfit struct {
+	ItemID      uuid.UUID       `json:"item_id"`
+	ProductID   uuid.UUID       `json:"product_id"`
+	ProductName string          `json:"product_name"`
+	Quantity    int             `json:"quantity"`
+	Revenue     decimal.Decimal `json:"revenue"`
+	UnitCost    decimal.Decimal `json:"unit_cost"`
+	Cost        decimal.Decimal `json:"cost"`
+	GrossProfit decimal.Decimal `json:"gross_profit"`
+	MarginPct   decimal.Decimal `json:"margin_pct"`
+}
+
+type InvoiceProfit struct {
+	InvoiceID     uuid.UUID           `json:"invoice_id"`
+	InvoiceNumber string              `json:"invoice_number"`
+	Revenue       decimal.Decimal     `json:"revenue"`
+	Cost          decimal.Decimal     `json:"cost"`
+	GrossProfit   decimal.Decimal     `json:"gross_profit"`
+	MarginPct     decimal.Decimal     `json:"margin_pct"`
+	Lines         []InvoiceLineProfit `json:"lines"`
+}
+
+// GrossProfitReportRequest groups invoiced sales by one dimension. Cost is the unit cost
+// captured on each invoice line when it was sold. From/To bound invoice dates, To exclusive.
+type GrossProfitReportRequest struct {
+	TenantID    uuid.UUID
+	GroupBy     ProfitGroupBy
+	Period      ReportPeriod // Bucket size when GroupBy is period
+	From        *time.Time
+	To          *time.Time
+	CustomerID  *uuid.UUID
+	WarehouseID *uuid.UUID
+	ExplodeKits bool // Report kit lines as their components with the allocated revenue
+}
+
+// GrossProfitLine is one group of a gross profit report. Key identifies the group
+// (an id, or the period start as YYYY-MM-DD); Label is its display name.
+type GrossProfitLine struct {
+	Key         string          `json:"key"`
+	Label       string          `json:"label"`
+	Quantity    int             `json:"quantity"`
+	Revenue     decimal.Decimal `json:"revenue"`
+	Cost        decimal.Decimal `json:"cost"`
+	GrossProfit decimal.Decimal `json:"gross_profit"`
+	MarginPct   decimal.Decimal `json:"margin_pct"`
+}
+
+type GrossProfitReport struct {
+	GroupBy ProfitGroupBy     `json:"group_by"`
+	Period  ReportPeriod      `json:"period,omitempty"`
+	Lines   []GrossProfitLine `json:"lines"`
+	Total   GrossProfitLine   `json:"total"`
+}
+
+// ReceivablesAgingRequest ages open invoices as of a date, for one customer or the tenant.
+type ReceivablesAgingRequest struct {
+	TenantID   uuid.UUID
+	AsOf       time.Time
+	CustomerID *uuid.UUID
+}
+
+// AgingBucket groups open amounts by days past the invoice due date.
+type AgingBucket string
+
+const (
+	AgingCurrent AgingBucket = "CURRENT" // Not yet due
+	Aging1To30   AgingBucket = "1-30"
+	Aging31To60  AgingBucket = "31-60"
+	Aging61To90  AgingBucket = "61-90"
+	AgingOver90  AgingBucket = "90+"
+)
+
+// AgingInvoice is the part of an invoice still open as of the report date, after the
+// customer's payments and returns have settled its invoices oldest due first.
+type AgingInvoice struct {
+	InvoiceID     uuid.UUID       `json:"invoice_id"`
+	CustomerID    uuid.UUID       `json:"customer_id"`
+	CustomerName  string          `json:"customer_name"`
+	InvoiceNumber string          `json:"invoice_number"`
+	InvoiceDate   time.Time       `json:"invoice_date"`
+	DueDate       time.Time       `json:"due_date"`
+	TotalAmount   decimal.Decimal `json:"total_amount"`
+	OpenAmount    decimal.Decimal `json:"open_amount"`
+	DaysOverdue   int             `json:"days_overdue"`
+	Bucket        AgingBucket     `json:"bucket"`
+}
+
+// ReceivablesAgingLine is one customer's open amounts per bucket. UnappliedCredit is what
+// its payments and returns exceed its invoices by; Balance is Total less that credit.
+type ReceivablesAgingLine struct {
+	CustomerID      uuid.UUID       `json:"customer_id"`
+	CustomerName    string          `json:"customer_name"`
+	Current         decimal.Decimal `json:"current"`
+	Days1To30       decimal.Decimal `json:"days_1_30"`
+	Days31To60      decimal.Decimal `json:"days_31_60"`
+	Days61To90      decimal.Decimal `json:"days_61_90"`
+	Over90          decimal.Decimal `json:"over_90"`
+	Total           decimal.Decimal `json:"total"`
+	UnappliedCredit decimal.Decimal `json:"unapplied_credit"`
+	Balance         decimal.Decimal `json:"balance"`
+}
+
+type ReceivablesAgingReport struct {
+	AsOf     time.Time              `json:"as_of"`
+	Lines    []ReceivablesAgingLine `json:"lines"`
+	Total    ReceivablesAgingLine   `json:"total"`
+	Invoices []AgingInvoice         `json:"invoices"`
+}
+
+// DeliveryNote (irsaliye) ships goods to a customer ahead of invoicing. Stock moves
+// when the note is created; several notes are later billed on one invoice.
+type DeliveryNote struct {
+	ID          uuid.UUID          `json:"id"`
+	TenantID    uuid.UUID          `json:"tenant_id"`
+	CustomerID  uuid.UUID          `json:"customer_id"`
+	WarehouseID uuid.UUID          `json:"warehouse_id"`
+	NoteNumber  string             `json:"note_number"`
+	Status      DeliveryNoteStatus `json:"status"`
+	Note        string             `json:"note"`
+	TotalAmount decimal.Decimal    `json:"total_amount"`
+	InvoiceID   *uuid.UUID         `json:"invoice_id"`
+	ShippedAt   time.Time          `json:"shipped_at"`
+	CreatedAt   time.Time          `json:"created_at"`
+	UpdatedAt   time.Time          `json:"updated_at"`
+	Items       []DeliveryNoteItem `json:"items"`
+}
+
+type DeliveryNoteItem struct {
+	ID             uuid.UUID       `json:"id"`
+	TenantID       uuid.UUID       `json:"tenant_id"`
+	DeliveryNoteID uuid.UUID       `json:"delivery_note_id"`
+	ProductID      uuid.UUID       `json:"product_id"`
+	Quantity       int             `json:"quantity"`
+	UnitPrice      decimal.Decimal `json:"unit_price"`
+	Total          decimal.Decimal `json:"total"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+type CreateDeliveryNoteRequest struct {
+	TenantID    uuid.UUID                 `json:"tenant_id"`
+	UserID      uuid.UUID                 `json:"user_id"`
+	CustomerID  uuid.UUID                 `json:"customer_id"`
+	WarehouseID uuid.UUID                 `json:"warehouse_id"`
+	Note        string                    `json:"note"`
+	Items       []DeliveryNoteItemRequest `json:"items"`
+	// CreditOverrideReason lets a manager ship to a customer on credit hold; see
+	// CreateInvoiceRequest.
+	CreditOverrideReason string `json:"credit_override_reason"`
+}
+
+type DeliveryNoteItemRequest struct {
+	ProductID     uuid.UUID       `json:"product_id"`
+	Quantity      int             `json:"quantity"`
+	UnitPrice     decimal.Decimal `json:"unit_price"`
+	LotNumber     string          `json:"lot_number"`     // Optional for lot-tracked products; FEFO allocation when empty
+	SerialNumbers []string        `json:"serial_numbers"` // One per unit for serialized products
+}
+
+// InvoiceDeliveryNotesRequest bills several shipped notes of one customer on a single invoice.
+type InvoiceDeliveryNotesRequest struct {
+	TenantID        uuid.UUID   `json:"tenant_id"`
+	UserID          uuid.UUID   `json:"user_id"`
+	CustomerID      uuid.UUID   `json:"customer_id"`
+	DeliveryNoteIDs []uuid.UUID `json:"delivery_note_ids"`
+	IdempotencyKey  uuid.UUID   `json:"idempotency_key"`
+}
+
+// UninvoicedDeliveryLine is a shipped-but-not-yet-invoiced delivery note line.
+type UninvoicedDeliveryLine struct {
+	DeliveryNoteID uuid.UUID       `json:"delivery_note_id"`
+	NoteNumber     string          `json:"note_number"`
+	ShippedAt      time.Time       `json:"shipped_at"`
+	CustomerID     uuid.UUID       `json:"customer_id"`
+	CustomerName   string          `json:"customer_name"`
+	WarehouseID    uuid.UUID       `json:"warehouse_id"`
+	ProductID      uuid.UUID       `json:"product_id"`
+	ProductName    string          `json:"product_name"`
+	Quantity       int             `json:"quantity"`
+	UnitPrice      decimal.Decimal `json:"unit_price"`
+	Total          decimal.Decimal `json:"total"`
+}
+
+type DeliveryNoteStatus string
+
+const (
+	DeliveryNoteStatusShipped   DeliveryNoteStatus = "SHIPPED"
+	DeliveryNoteStatusInvoiced  DeliveryNoteStatus = "INVOICED"
+	DeliveryNoteStatusCancelled DeliveryNoteStatus = "CANCELLED"
+)
+
+// Quotation (teklif) is a price quote sent to a customer. Revising a quotation creates
+// a new row with the same QuoteNumber and Revision+1 and supersedes the previous one.
+type Quotation struct {
+	ID           uuid.UUID       `json:"id"`
+	TenantID     uuid.UUID       `json:"tenant_id"`
+	CustomerID   uuid.UUID       `json:"customer_id"`
+	WarehouseID  uuid.UUID       `json:"warehouse_id"`
+	QuoteNumber  string          `json:"quote_number"`
+	Revision     int             `json:"revision"`
+	Status       QuotationStatus `json:"status"`
+	ValidUntil   time.Time       `json:"valid_until"`
+	Note         string          `json:"note"`
+	TotalAmount  decimal.Decimal `json:"total_amount"`
+	SalesOrderID *uuid.UUID      `json:"sales_order_id"` // Set once converted into an order
+	InvoiceID    *uuid.UUID      `json:"invoice_id"`     // Set once converted into an invoice
+	CreatedAt    time.Time       `json:"created_at"`
+	UpdatedAt    time.Time       `json:"updated_at"`
+	Items        []QuotationItem `json:"items"`
+}
+
+// IsConverted reports whether the quotation has already become an order or invoice.
+func (q Quotation) IsConverted() bool {
+	return q.SalesOrderID != nil || q.InvoiceID != nil
+}
+
+type QuotationItem struct {
+	ID          uuid.UUID       `json:"id"`
+	TenantID    uuid.UUID       `json:"tenant_id"`
+	QuotationID uuid.UUID       `json:"quotation_id"`
+	ProductID   uuid.UUID       `json:"product_id"`
+	Quantity    int             `json:"quantity"`
+	UnitPrice   decimal.Decimal `json:"unit_price"`
+	Total       decimal.Decimal `json:"total"`
+	CreatedAt   time.Time       `json:"created_at"`
+}
+
+type CreateQuotationRequest struct {
+	TenantID    uuid.UUID              `json:"tenant_id"`
+	UserID      uuid.UUID              `json:"user_id"`
+	CustomerID  uuid.UUID              `json:"customer_id"`
+	WarehouseID uuid.UUID              `json:"warehouse_id"`
+	ValidUntil  time.Time              `json:"valid_until"`
+	Note        string                 `json:"note"`
+	Items       []QuotationItemRequest `json:"items"`
+}
+
+// ReviseQuotationRequest replaces the lines and validity of a quotation as a new revision.
+type ReviseQuotationRequest struct {
+	TenantID    uuid.UUID              `json:"tenant_id"`
+	UserID      uuid.UUID              `json:"user_id"`
+	QuotationID uuid.UUID              `json:"quotation_id"`
+	ValidUntil  time.Time              `json:"valid_until"`
+	Note        string                 `json:"note"`
+	Items       []QuotationItemRequest `json:"items"`
+}
+
+// ConvertQuotationRequest turns a quotation into a sales order or an invoice.
+// IdempotencyKey and CreditOverrideReason are only used for invoice conversion.
+type ConvertQuotationRequest struct {
+	TenantID       uuid.UUID `json:"tenant_id"`
+	UserID         uuid.UUID `json:"user_id"`
+	QuotationID    uuid.UUID `json:"quotation_id"`
+	IdempotencyKey uuid.UUID `json:"idempotency_key"`
+	// CreditOverrideReason is passed to the invoice; see CreateInvoiceRequest.
+	CreditOverrideReason string `json:"credit_override_reason"`
+}
+
+type QuotationItemRequest struct {
+	ProductID uuid.UUID       `json:"product_id"`
+	Quantity  int             `json:"quantity"`
+	UnitPrice decimal.Decimal `json:"unit_price"`
+}
+
+// QuotationStatus defines the lifecycle of a quotation. SENT quotations past their
+// validity date expire automatically; SUPERSEDED marks a replaced revision.
+type QuotationStatus string
+
+const (
+	QuotationStatusSent       QuotationStatus = "SENT"
+	QuotationStatusAccepted   QuotationStatus = "ACCEPTED"
+	QuotationStatusRejected   QuotationStatus = "REJECTED"
+	QuotationStatusExpired    QuotationStatus = "EXPIRED"
+	QuotationStatusSuperseded QuotationStatus = "SUPERSEDED"
+)
+
+// AssemblyOrderStatus defines the lifecycle of an assembly order:
+// PLANNED -> COMPLETED (or CANCELLED before completion).
+type AssemblyOrderStatus string
+
+const (
+	AssemblyOrderStatusPlanned   AssemblyOrderStatus = "PLANNED"
+	AssemblyOrderStatusCompleted AssemblyOrderStatus = "COMPLETED"
+	AssemblyOrderStatusCancelled AssemblyOrderStatus = "CANCELLED"
+)
+
+// SalesOrderStatus defines the lifecycle of a sales order:
+// DRAFT -> CONFIRMED -> PARTIALLY_DELIVERED -> CLOSED (or CANCELLED before delivery).
+type SalesOrderStatus string
+
+const (
+	SalesOrderStatusDraft              SalesOrderStatus = "DRAFT"
+	SalesOrderStatusConfirmed          SalesOrderStatus = "CONFIRMED"
+	SalesOrderStatusPartiallyDelivered SalesOrderStatus = "PARTIALLY_DELIVERED"
+	SalesOrderStatusClosed             SalesOrderStatus = "CLOSED"
+	SalesOrderStatusCancelled          SalesOrderStatus = "CANCELLED"
+)
+
+// Audited entity types, stored in audit_logs.entity_type.
+const (
+	AuditEntityInvoice          = "INVOICE"
+	AuditEntityProduct          = "PRODUCT"
+	AuditEntityCustomer         = "CUSTOMER"
+	AuditEntityPayment          = "PAYMENT"
+	AuditEntitySupplier         = "SUPPLIER"
+	AuditEntityWarehouse        = "WAREHOUSE"
+	AuditEntityCategory         = "CATEGORY"
+	AuditEntityBrand            = "BRAND"
+	AuditEntityAttribute        = "ATTRIBUTE"
+	AuditEntityLocation         = "LOCATION"
+	AuditEntityLocationTransfer = "LOCATION_TRANSFER"
+	AuditEntityStockMovement    = "STOCK_MOVEMENT"
+	AuditEntityStockBalance     = "STOCK_BALANCE"
+	AuditEntityStockLevel       = "STOCK_LEVEL"
+	AuditEntityTenant           = "TENANT"
+	AuditEntityInvoiceTemplate  = "INVOICE_TEMPLATE"
+	AuditEntityEInvoice         = "E_INVOICE"
+	AuditEntityBOM              = "BOM"
+	AuditEntityAssemblyOrder    = "ASSEMBLY_ORDER"
+	AuditEntityDeliveryNote     = "DELIVERY_NOTE"
+	AuditEntityPurchaseOrder    = "PURCHASE_ORDER"
+	AuditEntityGoodsReceipt     = "GOODS_RECEIPT"
+	AuditEntityPurchaseInvoice  = "PURCHASE_INVOICE"
+	AuditEntityQuotation        = "QUOTATION"
+	AuditEntitySalesOrder       = "SALES_ORDER"
+	AuditEntityReturn           = "RETURN"
+	AuditEntityReturnReason     = "RETURN_REASON"
+)
+
+// Audit actions. Status changes are recorded under their own verb with the status in the
+// changed fields.
+const (
+	AuditActionCreate         = "CREATE"
+	AuditActionUpdate         = "UPDATE"
+	AuditActionDelete         = "DELETE"
+	AuditActionCreditOverride = "CREDIT_OVERRIDE"
+	AuditActionRepair         = "REPAIR"
+	AuditActionRecalculate    = "RECALCULATE"
+	AuditActionConfirm        = "CONFIRM"
+	AuditActionCancel         = "CANCEL"
+	AuditActionClose          = "CLOSE"
+	AuditActionComplete       = "COMPLETE"
+	AuditActionReceive        = "RECEIVE"
+	AuditActionInspect        = "INSPECT"
+	AuditActionInvoice        = "INVOICE"
+	AuditActionRevise         = "REVISE"
+	AuditActionAccept         = "ACCEPT"
+	AuditActionReject         = "REJECT"
+	AuditActionConvert        = "CONVERT"
+	AuditActionGenerate       = "GENERATE"
+	AuditActionSend           = "SEND"
+)
+
 // StockMovementType defines the type of movement
 type StockMovementType string
 
 const (
-	StockMovementTypeSale StockMovementType = "SALE"
-	StockMovementTypeIn   StockMovementType = "IN"
-	StockMovementTypeOut  StockMovementType = "OUT"
+	StockMovementTypeSale       StockMovementType = "SALE"
+	StockMovementTypeIn         StockMovementType = "IN"
+	StockMovementTypeOut        StockMovementType = "OUT"
+	StockMovementTypeTransfer   StockMovementType = "TRANSFER"
+	StockMovementTypeAdjustment StockMovementType = "ADJUSTMENT"
+)
+
+// ProfitGroupBy is the dimension a gross profit report is grouped by.
+type ProfitGroupBy string
+
+const (
+	ProfitByProduct     ProfitGroupBy = "product"
+	ProfitByCustomer    ProfitGroupBy = "customer"
+	ProfitByWarehouse   ProfitGroupBy = "warehouse"
+	ProfitBySalesperson ProfitGroupBy = "salesperson"
+	ProfitByPeriod      ProfitGroupBy = "period"
+	ProfitByCategory    ProfitGroupBy = "category" // Category the product had when sold
+	ProfitByBrand       ProfitGroupBy = "brand"    // Brand the product had when sold
+)
+
+type ReportPeriod string
+
+const (
+	ReportPeriodDay   ReportPeriod = "day"
+	ReportPeriodWeek  ReportPeriod = "week"
+	ReportPeriodMonth ReportPeriod = "month"
+)
+
+// CostingMethod is the tenant's inventory costing policy.
+type CostingMethod string
+
+const (
+	CostingMethodAverage CostingMethod = "AVERAGE" // Moving weighted average
+	CostingMethodFIFO    CostingMethod = "FIFO"    // First in, first out cost layers
+)
+
+// ReturnStatus defines the lifecycle of a customer return:
+// REQUESTED -> RECEIVED -> INSPECTED -> CLOSED. A request can be CANCELLED before the
+// goods arrive and received goods can be REJECTED and sent back. Only received returns
+// credit the customer.
+type ReturnStatus string
+
+const (
+	ReturnStatusRequested ReturnStatus = "REQUESTED"
+	ReturnStatusReceived  ReturnStatus = "RECEIVED"
+	ReturnStatusInspected ReturnStatus = "INSPECTED"
+	ReturnStatusClosed    ReturnStatus = "CLOSED"
+	ReturnStatusRejected  ReturnStatus = "REJECTED"
+	ReturnStatusCancelled ReturnStatus = "CANCELLED"
 )
 
 type ProductUnit string
@@ -0,0 +1,159 @@
+package einvoice
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"sancaksoft/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaxIDs(t *testing.T) {
+	for _, vkn := range []string{"9000068418", "1234567890", "3130557669"} {
+		assert.True(t, ValidVKN(vkn), vkn)
+		assert.Equal(t, "VKN", TaxIDScheme(vkn))
+	}
+	assert.False(t, ValidVKN("1234567891"))
+	assert.False(t, ValidVKN("12345678A0"))
+	assert.False(t, ValidVKN("123456789"))
+
+	assert.True(t, ValidTCKN("10000000146"))
+	assert.Equal(t, "TCKN", TaxIDScheme("10000000146"))
+	assert.False(t, ValidTCKN("10000000147"))
+	assert.False(t, ValidTCKN("01000000146"))
+	assert.False(t, ValidTCKN(ConsumerTCKN))
+	assert.Equal(t, "", TaxIDScheme("123"))
+}
+
+func testDocument() *domain.InvoiceDocument {
+	d := func(s string) decimal.Decimal { return decimal.RequireFromString(s) }
+	return &domain.InvoiceDocument{
+		Invoice: domain.Invoice{
+			InvoiceNumber: "INV-2026-000042",
+			CreatedAt:     time.Date(2026, 3, 5, 21, 30, 0, 0, time.UTC),
+			DueDate:       time.Date(2026, 4, 4, 0, 0, 0, 0, time.UTC),
+		},
+		Company: domain.TenantProfile{
+			Name: "Sancak Ticaret A.Ş.", Address: "Atatürk Bulvarı No: 12", District: "Çankaya", City: "Ankara",
+			TaxOffice: "Çankaya", TaxNumber: "9000068418",
+		},
+		Customer: domain.Customer{
+			Name: "Ayşe Yılmaz", Address: "Kızılay Mah.", District: "Çankaya", City: "Ankara",
+			TaxNumber: "10000000146",
+		},
+		Lines: []domain.InvoiceDocumentLine{
+			{ProductName: "Çay 1 kg", SKU: "CAY-1", Quantity: 3, Unit: "adet", VATRate: d("20"), Total: d("360.00"), Base: d("300.00"), VAT: d("60.00")},
+			{ProductName: "Ekmek", Quantity: 2, Unit: "kg", VATRate: d("1"), Total: d("20.20"), Base: d("20.00"), VAT: d("0.20")},
+			{ProductName: "Kitap", Quantity: 1, Unit: "adet", VATRate: d("0"), Total: d("50.00"), Base: d("50.00"), VAT: d("0")},
+		},
+		VAT: []domain.VATSubtotal{
+			{Rate: d("0"), Base: d("50.00"), Amount: d("0")},
+			{Rate: d("1"), Base: d("20.00"), Amount: d("0.20")},
+			{Rate: d("20"), Base: d("300.00"), Amount: d("60.00")},
+		},
+		Subtotal: d("370.00"),
+		VATTotal: d("60.20"),
+		Total:    d("430.20"),
+	}
+}
+
+func testEInvoice(profile domain.EInvoiceProfile) *domain.EInvoice {
+	return &domain.EInvoice{
+		ETTN:           uuid.MustParse("f47ac10b-58cc-4372-a567-0e02b2c3d479"),
+		Profile:        profile,
+		DocumentNumber: DocumentNumber(Series(profile), 2026, 7),
+	}
+}
+
+func TestBuildValidates(t *testing.T) {
+	out, err := Build(testDocument(), testEInvoice(domain.EInvoiceProfileArchive))
+	require.NoError(t, err)
+	require.NoError(t, Validate(out))
+
+	s := string(out)
+	assert.Contains(t, s, `<cbc:ProfileID>EARSIVFATURA</cbc:ProfileID>`)
+	assert.Contains(t, s, `<cbc:ID>EAR2026000000007</cbc:ID>`)
+	assert.Contains(t, s, `<cbc:UUID>F47AC10B-58CC-4372-A567-0E02B2C3D479</cbc:UUID>`)
+	// Issued at 00:30 in Turkey
+	assert.Contains(t, s, `<cbc:IssueDate>2026-03-06</cbc:IssueDate>`)
+	assert.Contains(t, s, `<cbc:ID schemeID="TCKN">10000000146</cbc:ID>`)
+	assert.Contains(t, s, `<cbc:FirstName>Ayşe</cbc:FirstName>`)
+	assert.Contains(t, s, `<cbc:TaxAmount currencyID="TRY">60.20</cbc:TaxAmount>`)
+	assert.Contains(t, s, `<cbc:TaxExemptionReasonCode>351</cbc:TaxExemptionReasonCode>`)
+	assert.Contains(t, s, `<cbc:InvoicedQuantity unitCode="KGM">2</cbc:InvoicedQuantity>`)
+	assert.Contains(t, s, `<cbc:PriceAmount currencyID="TRY">100.0000</cbc:PriceAmount>`)
+	assert.Contains(t, s, `<cbc:PayableAmount currencyID="TRY">430.20</cbc:PayableAmount>`)
+}
+
+func TestBuildConsumerOnlyOnEArchive(t *testing.T) {
+	doc := testDocument()
+	doc.Customer.TaxNumber = ""
+
+	out, err := Build(doc, testEInvoice(domain.EInvoiceProfileArchive))
+	require.NoError(t, err)
+	assert.Contains(t, string(out), ConsumerTCKN)
+	require.NoError(t, Validate(out))
+
+	out, err = Build(doc, testEInvoice(domain.EInvoiceProfileBasic))
+	require.NoError(t, err)
+	assert.Error(t, Validate(out))
+}
+
+func TestValidateReportsProblems(t *testing.T) {
+	out, err := Build(testDocument(), testEInvoice(domain.EInvoiceProfileBasic))
+	require.NoError(t, err)
+	require.NoError(t, Validate(out))
+
+	broken := bytes.Replace(out, []byte(`<cbc:PayableAmount currencyID="TRY">430.20`), []byte(`<cbc:PayableAmount currencyID="TRY">430.21`), 1)
+	broken = bytes.Replace(broken, []byte("9000068418"), []byte("9000068419"), -1)
+	broken = bytes.Replace(broken, []byte(`<cbc:LineExtensionAmount currencyID="TRY">300.00`), []byte(`<cbc:LineExtensionAmount currencyID="USD">300.00`), 1)
+
+	err = Validate(broken)
+	var verr *ValidationError
+	require.ErrorAs(t, err, &verr)
+	assert.Len(t, verr.Problems, 3)
+	assert.Contains(t, err.Error(), "PayableAmount 430.21 must equal TaxInclusiveAmount 430.20")
+	assert.Contains(t, err.Error(), `supplier VKN "9000068419" is not valid`)
+	assert.Contains(t, err.Error(), "LineExtensionAmount must have currencyID TRY")
+
+	err = Validate([]byte("<Invoice>"))
+	require.ErrorAs(t, err, &verr)
+	assert.Contains(t, verr.Problems[0], "not well-formed")
+}
+
+func TestMockProvider(t *testing.T) {
+	ctx := context.Background()
+	p := NewMockProvider("10000000146")
+
+	registered, alias, err := p.LookupRecipient(ctx, "10000000146")
+	require.NoError(t, err)
+	assert.True(t, registered)
+	assert.NotEmpty(t, alias)
+	registered, _, err = p.LookupRecipient(ctx, "9000068418")
+	require.NoError(t, err)
+	assert.False(t, registered)
+
+	e := testEInvoice(domain.EInvoiceProfileBasic)
+	out, err := Build(testDocument(), e)
+	require.NoError(t, err)
+	res, err := p.Send(ctx, Submission{ETTN: e.ETTN, Profile: e.Profile, DocumentNumber: e.DocumentNumber, ReceiverTaxID: "10000000146", XML: out})
+	require.NoError(t, err)
+	assert.Equal(t, domain.EInvoiceStatusSent, res.Status)
+	assert.NotEmpty(t, res.Reference)
+
+	// Resending an accepted ETTN returns the first answer
+	again, err := p.Send(ctx, Submission{ETTN: e.ETTN, Profile: e.Profile, DocumentNumber: e.DocumentNumber, ReceiverTaxID: "10000000146", XML: out})
+	require.NoError(t, err)
+	assert.Equal(t, res, again)
+
+	res, err = p.Send(ctx, Submission{ETTN: uuid.New(), Profile: e.Profile, ReceiverTaxID: "3130557669", XML: out})
+	require.NoError(t, err)
+	assert.Equal(t, domain.EInvoiceStatusRejected, res.Status)
+	assert.Len(t, p.Submissions(), 3)
+}
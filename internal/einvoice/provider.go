@@ -0,0 +1,103 @@
+package einvoice
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"sancaksoft/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// Submission is an invoice handed to the integrator.
+type Submission struct {
+	ETTN           uuid.UUID
+	Profile        domain.EInvoiceProfile
+	DocumentNumber string
+	ReceiverTaxID  string
+	XML            []byte
+}
+
+// Result is the integrator's answer to a submission.
+type Result struct {
+	Reference string
+	Status    domain.EInvoiceStatus
+	Message   string
+}
+
+// Provider is an integrator (özel entegratör) that delivers invoices to GİB. An error
+// means the integrator could not be reached; a rejected invoice is a Result with status
+// REJECTED. Integrators identify a document by its ETTN: sending an ETTN they already
+// accepted returns the earlier answer instead of delivering it twice.
+type Provider interface {
+	// LookupRecipient reports whether a VKN/TCKN is a registered e-Fatura user, and its
+	// inbox alias if so.
+	LookupRecipient(ctx context.Context, taxID string) (registered bool, alias string, err error)
+	Send(ctx context.Context, s Submission) (Result, error)
+}
+
+// MockProvider is an in-memory Provider for development and tests. It accepts every
+// invoice that passes Validate and remembers what it was sent.
+type MockProvider struct {
+	mu          sync.Mutex
+	registered  map[string]string
+	submissions []Submission
+	accepted    map[uuid.UUID]Result
+}
+
+// NewMockProvider returns a MockProvider with the given registered e-Fatura users.
+func NewMockProvider(registered ...string) *MockProvider {
+	p := &MockProvider{registered: map[string]string{}, accepted: map[uuid.UUID]Result{}}
+	for _, taxID := range registered {
+		p.Register(taxID)
+	}
+	return p
+}
+
+// Register adds a registered e-Fatura user.
+func (p *MockProvider) Register(taxID string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.registered[taxID] = "urn:mail:defaultpk@" + taxID
+}
+
+func (p *MockProvider) LookupRecipient(ctx context.Context, taxID string) (bool, string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	alias, ok := p.registered[taxID]
+	return ok, alias, nil
+}
+
+func (p *MockProvider) Send(ctx context.Context, s Submission) (Result, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.submissions = append(p.submissions, s)
+	if res, ok := p.accepted[s.ETTN]; ok {
+		return res, nil
+	}
+
+	if err := Validate(s.XML); err != nil {
+		return Result{Status: domain.EInvoiceStatusRejected, Message: err.Error()}, nil
+	}
+	if s.Profile != domain.EInvoiceProfileArchive {
+		if _, ok := p.registered[s.ReceiverTaxID]; !ok {
+			return Result{Status: domain.EInvoiceStatusRejected, Message: "receiver is not a registered e-Fatura user"}, nil
+		}
+	}
+	// e-Fatura waits for GİB and the receiver; e-Arşiv is reported and done
+	status := domain.EInvoiceStatusSent
+	if s.Profile == domain.EInvoiceProfileArchive {
+		status = domain.EInvoiceStatusAccepted
+	}
+	res := Result{Reference: fmt.Sprintf("MOCK-%s", s.ETTN), Status: status}
+	p.accepted[s.ETTN] = res
+	return res, nil
+}
+
+// Submissions returns the invoices sent so far.
+func (p *MockProvider) Submissions() []Submission {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]Submission(nil), p.submissions...)
+}
@@ -0,0 +1,64 @@
+package einvoice
+
+// ValidVKN reports whether s is a valid 10-digit tax number (vergi kimlik numarası),
+// checking the GİB check digit.
+func ValidVKN(s string) bool {
+	d, ok := digits(s, 10)
+	if !ok {
+		return false
+	}
+	sum := 0
+	for i := 0; i < 9; i++ {
+		t := (d[i] + 9 - i) % 10
+		v := t * (1 << (9 - i)) % 9
+		if t != 0 && v == 0 {
+			v = 9
+		}
+		sum += v
+	}
+	return (10-sum%10)%10 == d[9]
+}
+
+// ValidTCKN reports whether s is a valid 11-digit national identity number (T.C. kimlik
+// numarası), checking both check digits.
+func ValidTCKN(s string) bool {
+	d, ok := digits(s, 11)
+	if !ok || d[0] == 0 {
+		return false
+	}
+	odd := d[0] + d[2] + d[4] + d[6] + d[8]
+	even := d[1] + d[3] + d[5] + d[7]
+	if ((odd*7-even)%10+10)%10 != d[9] {
+		return false
+	}
+	sum := 0
+	for _, v := range d[:10] {
+		sum += v
+	}
+	return sum%10 == d[10]
+}
+
+// TaxIDScheme returns the UBL-TR scheme of a tax ID: "VKN" or "TCKN", or "" if it is neither.
+func TaxIDScheme(s string) string {
+	switch {
+	case ValidVKN(s):
+		return "VKN"
+	case ValidTCKN(s):
+		return "TCKN"
+	}
+	return ""
+}
+
+func digits(s string, n int) ([]int, bool) {
+	if len(s) != n {
+		return nil, false
+	}
+	d := make([]int, n)
+	for i, r := range s {
+		if r < '0' || r > '9' {
+			return nil, false
+		}
+		d[i] = int(r - '0')
+	}
+	return d, true
+}
@@ -0,0 +1,318 @@
+// Package einvoice builds and validates UBL-TR 1.2 invoices for e-Fatura and e-Arşiv and
+// sends them through an integrator (özel entegratör). The XML is left unsigned; the
+// integrator adds the XAdES signature before passing it to GİB.
+package einvoice
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+
+	"sancaksoft/internal/domain"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	nsInvoice = "urn:oasis:names:specification:ubl:schema:xsd:Invoice-2"
+	nsCAC     = "urn:oasis:names:specification:ubl:schema:xsd:CommonAggregateComponents-2"
+	nsCBC     = "urn:oasis:names:specification:ubl:schema:xsd:CommonBasicComponents-2"
+
+	currency = "TRY"
+
+	// ConsumerTCKN identifies an end consumer whose identity number is not known on
+	// e-Arşiv invoices.
+	ConsumerTCKN = "11111111111"
+
+	// vatTaxTypeCode is the GİB tax type code of KDV.
+	vatTaxTypeCode = "0015"
+	// vatNotExemptCode is the exemption code for VAT-free lines that are not under a
+	// specific exemption (351: istisna olmayan diğer).
+	vatNotExemptCode = "351"
+)
+
+// Turkey has been on UTC+3 all year since 2016.
+var turkeyTime = time.FixedZone("TRT", 3*60*60)
+
+// Series are the document number prefixes of the two document types; numbers are the
+// series, the year and a 9-digit sequence: EFT2026000000001.
+const (
+	SeriesEInvoice = "EFT"
+	SeriesEArchive = "EAR"
+)
+
+// Series returns the document number series used for a profile.
+func Series(profile domain.EInvoiceProfile) string {
+	if profile == domain.EInvoiceProfileArchive {
+		return SeriesEArchive
+	}
+	return SeriesEInvoice
+}
+
+// DocumentNumber formats a GİB document number.
+func DocumentNumber(series string, year, sequence int) string {
+	return fmt.Sprintf("%s%d%09d", series, year, sequence)
+}
+
+// IssueYear is the year an invoice is issued in, in Turkish time; document numbers
+// restart every year.
+func IssueYear(inv domain.Invoice) int {
+	return inv.CreatedAt.In(turkeyTime).Year()
+}
+
+type ublInvoice struct {
+	XMLName              xml.Name        `xml:"Invoice"`
+	Xmlns                string          `xml:"xmlns,attr"`
+	XmlnsCAC             string          `xml:"xmlns:cac,attr"`
+	XmlnsCBC             string          `xml:"xmlns:cbc,attr"`
+	UBLVersionID         string          `xml:"cbc:UBLVersionID"`
+	CustomizationID      string          `xml:"cbc:CustomizationID"`
+	ProfileID            string          `xml:"cbc:ProfileID"`
+	ID                   string          `xml:"cbc:ID"`
+	CopyIndicator        bool            `xml:"cbc:CopyIndicator"`
+	UUID                 string          `xml:"cbc:UUID"`
+	IssueDate            string          `xml:"cbc:IssueDate"`
+	IssueTime            string          `xml:"cbc:IssueTime"`
+	InvoiceTypeCode      string          `xml:"cbc:InvoiceTypeCode"`
+	Notes                []string        `xml:"cbc:Note"`
+	DocumentCurrencyCode string          `xml:"cbc:DocumentCurrencyCode"`
+	LineCountNumeric     int             `xml:"cbc:LineCountNumeric"`
+	Signature            ublSignature    `xml:"cac:Signature"`
+	Supplier             ublPartyWrapper `xml:"cac:AccountingSupplierParty"`
+	Customer             ublPartyWrapper `xml:"cac:AccountingCustomerParty"`
+	PaymentMeans         struct {
+		Code    string `xml:"cbc:PaymentMeansCode"`
+		DueDate string `xml:"cbc:PaymentDueDate"`
+	} `xml:"cac:PaymentMeans"`
+	TaxTotal           ublTaxTotal      `xml:"cac:TaxTotal"`
+	LegalMonetaryTotal ublMonetaryTotal `xml:"cac:LegalMonetaryTotal"`
+	Lines              []ublLine        `xml:"cac:InvoiceLine"`
+}
+
+type ublSignature struct {
+	ID             ublIdentifier `xml:"cbc:ID"`
+	SignatoryParty ublParty      `xml:"cac:SignatoryParty"`
+	Attachment     struct {
+		URI string `xml:"cac:ExternalReference>cbc:URI"`
+	} `xml:"cac:DigitalSignatureAttachment"`
+}
+
+type ublIdentifier struct {
+	SchemeID string `xml:"schemeID,attr"`
+	Value    string `xml:",chardata"`
+}
+
+type ublAmount struct {
+	CurrencyID string `xml:"currencyID,attr"`
+	Value      string `xml:",chardata"`
+}
+
+type ublQuantity struct {
+	UnitCode string `xml:"unitCode,attr"`
+	Value    int    `xml:",chardata"`
+}
+
+type ublPartyWrapper struct {
+	Party ublParty `xml:"cac:Party"`
+}
+
+type ublParty struct {
+	Identification ublIdentifier `xml:"cac:PartyIdentification>cbc:ID"`
+	Name           string        `xml:"cac:PartyName>cbc:Name,omitempty"`
+	Address        ublAddress    `xml:"cac:PostalAddress"`
+	TaxOffice      string        `xml:"cac:PartyTaxScheme>cac:TaxScheme>cbc:Name,omitempty"`
+	Contact        *ublContact   `xml:"cac:Contact,omitempty"`
+	Person         *ublPerson    `xml:"cac:Person,omitempty"`
+}
+
+type ublAddress struct {
+	StreetName          string `xml:"cbc:StreetName,omitempty"`
+	CitySubdivisionName string `xml:"cbc:CitySubdivisionName"`
+	CityName            string `xml:"cbc:CityName"`
+	Country             string `xml:"cac:Country>cbc:Name"`
+}
+
+type ublContact struct {
+	Telephone      string `xml:"cbc:Telephone,omitempty"`
+	ElectronicMail string `xml:"cbc:ElectronicMail,omitempty"`
+}
+
+type ublPerson struct {
+	FirstName  string `xml:"cbc:FirstName"`
+	FamilyName string `xml:"cbc:FamilyName"`
+}
+
+type ublTaxTotal struct {
+	TaxAmount ublAmount        `xml:"cbc:TaxAmount"`
+	Subtotals []ublTaxSubtotal `xml:"cac:TaxSubtotal"`
+}
+
+type ublTaxSubtotal struct {
+	TaxableAmount              ublAmount      `xml:"cbc:TaxableAmount"`
+	TaxAmount                  ublAmount      `xml:"cbc:TaxAmount"`
+	CalculationSequenceNumeric int            `xml:"cbc:CalculationSequenceNumeric"`
+	Percent                    string         `xml:"cbc:Percent"`
+	Category                   ublTaxCategory `xml:"cac:TaxCategory"`
+}
+
+type ublTaxCategory struct {
+	ExemptionReasonCode string       `xml:"cbc:TaxExemptionReasonCode,omitempty"`
+	ExemptionReason     string       `xml:"cbc:TaxExemptionReason,omitempty"`
+	Scheme              ublTaxScheme `xml:"cac:TaxScheme"`
+}
+
+type ublTaxScheme struct {
+	Name        string `xml:"cbc:Name"`
+	TaxTypeCode string `xml:"cbc:TaxTypeCode"`
+}
+
+type ublMonetaryTotal struct {
+	LineExtensionAmount ublAmount `xml:"cbc:LineExtensionAmount"`
+	TaxExclusiveAmount  ublAmount `xml:"cbc:TaxExclusiveAmount"`
+	TaxInclusiveAmount  ublAmount `xml:"cbc:TaxInclusiveAmount"`
+	PayableAmount       ublAmount `xml:"cbc:PayableAmount"`
+}
+
+type ublLine struct {
+	ID                  int         `xml:"cbc:ID"`
+	InvoicedQuantity    ublQuantity `xml:"cbc:InvoicedQuantity"`
+	LineExtensionAmount ublAmount   `xml:"cbc:LineExtensionAmount"`
+	TaxTotal            ublTaxTotal `xml:"cac:TaxTotal"`
+	Item                struct {
+		Name string `xml:"cbc:Name"`
+		SKU  string `xml:"cac:SellersItemIdentification>cbc:ID,omitempty"`
+	} `xml:"cac:Item"`
+	PriceAmount ublAmount `xml:"cac:Price>cbc:PriceAmount"`
+}
+
+// UN/ECE unit codes of the product units
+var unitCodes = map[string]string{
+	"adet": "C62",
+	"kg":   "KGM",
+}
+
+func amount(d decimal.Decimal) ublAmount {
+	return ublAmount{CurrencyID: currency, Value: d.StringFixed(2)}
+}
+
+func vatSubtotal(base, vat, rate decimal.Decimal) ublTaxSubtotal {
+	st := ublTaxSubtotal{
+		TaxableAmount:              amount(base),
+		TaxAmount:                  amount(vat),
+		CalculationSequenceNumeric: 1,
+		Percent:                    rate.String(),
+		Category:                   ublTaxCategory{Scheme: ublTaxScheme{Name: "KDV", TaxTypeCode: vatTaxTypeCode}},
+	}
+	if rate.IsZero() {
+		st.Category.ExemptionReasonCode = vatNotExemptCode
+		st.Category.ExemptionReason = "İstisna olmayan diğer"
+	}
+	return st
+}
+
+// party builds a UBL party; people (TCKN) are named by first and family name.
+func party(taxID, name, address, district, city, taxOffice, phone, email string) ublParty {
+	p := ublParty{
+		Identification: ublIdentifier{SchemeID: "VKN", Value: taxID},
+		Address: ublAddress{
+			StreetName:          address,
+			CitySubdivisionName: district,
+			CityName:            city,
+			Country:             "Türkiye",
+		},
+		TaxOffice: taxOffice,
+	}
+	if len(taxID) == 11 {
+		p.Identification.SchemeID = "TCKN"
+		words := strings.Fields(name)
+		if len(words) > 1 {
+			p.Person = &ublPerson{FirstName: strings.Join(words[:len(words)-1], " "), FamilyName: words[len(words)-1]}
+		} else {
+			p.Person = &ublPerson{FirstName: name}
+		}
+	} else {
+		p.Name = name
+	}
+	if phone != "" || email != "" {
+		p.Contact = &ublContact{Telephone: phone, ElectronicMail: email}
+	}
+	return p
+}
+
+// Build renders the UBL-TR invoice of an invoice document. The document's VAT breakdown
+// must already be worked out; e supplies the profile, ETTN and document number.
+func Build(doc *domain.InvoiceDocument, e *domain.EInvoice) ([]byte, error) {
+	issued := doc.Invoice.CreatedAt.In(turkeyTime)
+	co, cu := doc.Company, doc.Customer
+
+	customerID := cu.TaxNumber
+	if customerID == "" && e.Profile == domain.EInvoiceProfileArchive {
+		customerID = ConsumerTCKN
+	}
+
+	inv := ublInvoice{
+		Xmlns:                nsInvoice,
+		XmlnsCAC:             nsCAC,
+		XmlnsCBC:             nsCBC,
+		UBLVersionID:         "2.1",
+		CustomizationID:      "TR1.2",
+		ProfileID:            string(e.Profile),
+		ID:                   e.DocumentNumber,
+		UUID:                 strings.ToUpper(e.ETTN.String()),
+		IssueDate:            issued.Format("2006-01-02"),
+		IssueTime:            issued.Format("15:04:05"),
+		InvoiceTypeCode:      "SATIS",
+		Notes:                []string{"Fatura No: " + doc.Invoice.InvoiceNumber},
+		DocumentCurrencyCode: currency,
+		LineCountNumeric:     len(doc.Lines),
+		Supplier:             ublPartyWrapper{Party: party(co.TaxNumber, co.Name, co.Address, co.District, co.City, co.TaxOffice, co.Phone, co.Email)},
+		Customer:             ublPartyWrapper{Party: party(customerID, cu.Name, cu.Address, cu.District, cu.City, cu.TaxOffice, cu.Phone, cu.Email)},
+		TaxTotal:             ublTaxTotal{TaxAmount: amount(doc.VATTotal)},
+		LegalMonetaryTotal: ublMonetaryTotal{
+			LineExtensionAmount: amount(doc.Subtotal),
+			TaxExclusiveAmount:  amount(doc.Subtotal),
+			TaxInclusiveAmount:  amount(doc.Total),
+			PayableAmount:       amount(doc.Total),
+		},
+	}
+	inv.PaymentMeans.Code = "1" // Not specified; the due date is what matters
+	inv.PaymentMeans.DueDate = doc.Invoice.DueDate.Format("2006-01-02")
+
+	// The signature element names the signer; the signature itself is added by the integrator
+	inv.Signature.ID = ublIdentifier{SchemeID: "VKN_TCKN", Value: co.TaxNumber}
+	inv.Signature.SignatoryParty = ublParty{
+		Identification: inv.Supplier.Party.Identification,
+		Address:        inv.Supplier.Party.Address,
+	}
+	inv.Signature.Attachment.URI = "#Signature_" + inv.ID
+
+	for _, v := range doc.VAT {
+		inv.TaxTotal.Subtotals = append(inv.TaxTotal.Subtotals, vatSubtotal(v.Base, v.Amount, v.Rate))
+	}
+	for i, l := range doc.Lines {
+		unit := unitCodes[l.Unit]
+		if unit == "" {
+			unit = unitCodes["adet"]
+		}
+		line := ublLine{
+			ID:                  i + 1,
+			InvoicedQuantity:    ublQuantity{UnitCode: unit, Value: l.Quantity},
+			LineExtensionAmount: amount(l.Base),
+			TaxTotal: ublTaxTotal{
+				TaxAmount: amount(l.VAT),
+				Subtotals: []ublTaxSubtotal{vatSubtotal(l.Base, l.VAT, l.VATRate)},
+			},
+			PriceAmount: ublAmount{CurrencyID: currency, Value: l.Base.Div(decimal.NewFromInt(int64(max(l.Quantity, 1)))).StringFixed(4)},
+		}
+		line.Item.Name = l.ProductName
+		line.Item.SKU = l.SKU
+		inv.Lines = append(inv.Lines, line)
+	}
+
+	out, err := xml.MarshalIndent(inv, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to build UBL-TR invoice: %w", err)
+	}
+	return append([]byte(xml.Header), out...), nil
+}
@@ -0,0 +1,355 @@
+package einvoice
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// ValidationError lists every rule a document breaks.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid UBL-TR invoice: " + strings.Join(e.Problems, "; ")
+}
+
+// node is a parsed XML element; names carry the UBL prefix (cbc:ID) whatever prefix
+// the document used.
+type node struct {
+	name     string
+	attrs    map[string]string
+	text     string
+	children []*node
+}
+
+var prefixes = map[string]string{
+	nsInvoice: "",
+	nsCAC:     "cac:",
+	nsCBC:     "cbc:",
+}
+
+func parse(data []byte) (*node, error) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	var root *node
+	var stack []*node
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			prefix, ok := prefixes[t.Name.Space]
+			if !ok {
+				prefix = t.Name.Space + ":"
+			}
+			n := &node{name: prefix + t.Name.Local, attrs: map[string]string{}}
+			for _, a := range t.Attr {
+				if a.Name.Space == "" {
+					n.attrs[a.Name.Local] = a.Value
+				}
+			}
+			if len(stack) == 0 {
+				if root != nil {
+					return nil, fmt.Errorf("more than one root element")
+				}
+				root = n
+			} else {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, n)
+			}
+			stack = append(stack, n)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].text += string(t)
+			}
+		}
+	}
+	if root == nil {
+		return nil, fmt.Errorf("empty document")
+	}
+	return root, nil
+}
+
+// all returns the elements at a path of child names below n.
+func (n *node) all(path string) []*node {
+	nodes := []*node{n}
+	for _, name := range strings.Split(path, "/") {
+		var next []*node
+		for _, p := range nodes {
+			for _, c := range p.children {
+				if c.name == name {
+					next = append(next, c)
+				}
+			}
+		}
+		nodes = next
+	}
+	return nodes
+}
+
+func (n *node) first(path string) *node {
+	if nodes := n.all(path); len(nodes) > 0 {
+		return nodes[0]
+	}
+	return nil
+}
+
+// value returns the trimmed text at a path, or "" if the element is missing.
+func (n *node) value(path string) string {
+	if c := n.first(path); c != nil {
+		return strings.TrimSpace(c.text)
+	}
+	return ""
+}
+
+var (
+	documentNumberPattern = regexp.MustCompile(`^[A-Z0-9]{3}20[0-9]{2}[0-9]{9}$`)
+	uuidPattern           = regexp.MustCompile(`^[0-9A-Fa-f]{8}-[0-9A-Fa-f]{4}-[0-9A-Fa-f]{4}-[0-9A-Fa-f]{4}-[0-9A-Fa-f]{12}$`)
+)
+
+var profiles = map[string]bool{"TEMELFATURA": true, "TICARIFATURA": true, "EARSIVFATURA": true}
+
+// Validate checks a UBL-TR invoice against the rules of the GİB UBL-TR 1.2 schema and
+// main schematron that apply to the invoices this package builds: required elements,
+// code lists, identifiers and that the amounts add up. It returns a *ValidationError
+// listing every problem found.
+func Validate(data []byte) error {
+	root, err := parse(data)
+	if err != nil {
+		return &ValidationError{Problems: []string{"not well-formed XML: " + err.Error()}}
+	}
+	v := &validator{}
+	if root.name != "Invoice" {
+		v.fail("root element must be Invoice in the %s namespace", nsInvoice)
+		return v.result()
+	}
+
+	v.equal(root.value("cbc:UBLVersionID"), "2.1", "UBLVersionID")
+	v.equal(root.value("cbc:CustomizationID"), "TR1.2", "CustomizationID")
+	profile := root.value("cbc:ProfileID")
+	if !profiles[profile] {
+		v.fail("ProfileID %q is not a supported profile", profile)
+	}
+	if id := root.value("cbc:ID"); !documentNumberPattern.MatchString(id) {
+		v.fail("ID %q must be a 3-character series, the year and a 9-digit sequence", id)
+	}
+	if !uuidPattern.MatchString(root.value("cbc:UUID")) {
+		v.fail("UUID (ETTN) must be a UUID")
+	}
+	if root.value("cbc:CopyIndicator") != "false" {
+		v.fail("CopyIndicator must be false")
+	}
+	if _, err := time.Parse("2006-01-02", root.value("cbc:IssueDate")); err != nil {
+		v.fail("IssueDate must be a YYYY-MM-DD date")
+	}
+	if root.value("cbc:InvoiceTypeCode") != "SATIS" {
+		v.fail("InvoiceTypeCode must be SATIS")
+	}
+	v.equal(root.value("cbc:DocumentCurrencyCode"), currency, "DocumentCurrencyCode")
+
+	lines := root.all("cac:InvoiceLine")
+	if len(lines) == 0 {
+		v.fail("the invoice has no lines")
+	}
+	v.equal(root.value("cbc:LineCountNumeric"), fmt.Sprint(len(lines)), "LineCountNumeric")
+
+	supplier := root.first("cac:AccountingSupplierParty/cac:Party")
+	customer := root.first("cac:AccountingCustomerParty/cac:Party")
+	if supplier == nil || customer == nil {
+		v.fail("supplier and customer parties are required")
+	} else {
+		v.party(supplier, "supplier", false)
+		v.party(customer, "customer", profile == "EARSIVFATURA")
+		if supplier.value("cac:PartyTaxScheme/cac:TaxScheme/cbc:Name") == "" {
+			v.fail("supplier tax office is required")
+		}
+		signer := root.first("cac:Signature/cbc:ID")
+		if signer == nil || signer.attrs["schemeID"] != "VKN_TCKN" || strings.TrimSpace(signer.text) != supplier.value("cac:PartyIdentification/cbc:ID") {
+			v.fail("Signature ID must be the supplier's VKN/TCKN with schemeID VKN_TCKN")
+		}
+	}
+
+	// Every amount is in the document currency
+	var amounts func(n *node)
+	amounts = func(n *node) {
+		if _, ok := n.attrs["currencyID"]; ok || strings.HasSuffix(n.name, "Amount") {
+			if n.attrs["currencyID"] != currency {
+				v.fail("%s must have currencyID %s", n.name, currency)
+			}
+		}
+		for _, c := range n.children {
+			amounts(c)
+		}
+	}
+	amounts(root)
+
+	taxTotal := v.taxTotal(root, "invoice")
+	lineTotal := decimal.Zero
+	for i, line := range lines {
+		label := fmt.Sprintf("line %d", i+1)
+		if line.value("cbc:ID") == "" {
+			v.fail("%s has no ID", label)
+		}
+		quantity := line.first("cbc:InvoicedQuantity")
+		if quantity == nil || quantity.attrs["unitCode"] == "" {
+			v.fail("%s needs InvoicedQuantity with a unitCode", label)
+		}
+		if line.value("cac:Item/cbc:Name") == "" {
+			v.fail("%s needs an item name", label)
+		}
+		if line.value("cac:Price/cbc:PriceAmount") == "" {
+			v.fail("%s needs a price", label)
+		}
+		lineTotal = lineTotal.Add(v.amount(line, "cbc:LineExtensionAmount", label))
+		v.taxTotal(line, label)
+	}
+
+	const monetary = "cac:LegalMonetaryTotal/"
+	lineExtension := v.amount(root, monetary+"cbc:LineExtensionAmount", "invoice")
+	exclusive := v.amount(root, monetary+"cbc:TaxExclusiveAmount", "invoice")
+	inclusive := v.amount(root, monetary+"cbc:TaxInclusiveAmount", "invoice")
+	payable := v.amount(root, monetary+"cbc:PayableAmount", "invoice")
+	if !lineExtension.Equal(lineTotal) {
+		v.fail("LineExtensionAmount %s is not the sum of the lines %s", lineExtension.StringFixed(2), lineTotal.StringFixed(2))
+	}
+	if !inclusive.Equal(exclusive.Add(taxTotal)) {
+		v.fail("TaxInclusiveAmount %s is not TaxExclusiveAmount plus taxes %s", inclusive.StringFixed(2), exclusive.Add(taxTotal).StringFixed(2))
+	}
+	if !payable.Equal(inclusive) {
+		v.fail("PayableAmount %s must equal TaxInclusiveAmount %s", payable.StringFixed(2), inclusive.StringFixed(2))
+	}
+	return v.result()
+}
+
+type validator struct {
+	problems []string
+}
+
+func (v *validator) fail(format string, args ...any) {
+	v.problems = append(v.problems, fmt.Sprintf(format, args...))
+}
+
+func (v *validator) result() error {
+	if len(v.problems) == 0 {
+		return nil
+	}
+	return &ValidationError{Problems: v.problems}
+}
+
+func (v *validator) equal(got, want, name string) {
+	if got != want {
+		v.fail("%s must be %s, not %q", name, want, got)
+	}
+}
+
+func (v *validator) amount(n *node, path, label string) decimal.Decimal {
+	s := n.value(path)
+	d, err := decimal.NewFromString(s)
+	if err != nil {
+		v.fail("%s %s must be an amount, not %q", label, path, s)
+	}
+	return d
+}
+
+// party checks a party's identifier, name and address. Consumers on e-Arşiv invoices may
+// use the generic consumer TCKN.
+func (v *validator) party(p *node, label string, consumerAllowed bool) {
+	id := p.first("cac:PartyIdentification/cbc:ID")
+	if id == nil {
+		v.fail("%s has no VKN/TCKN", label)
+		return
+	}
+	value, scheme := strings.TrimSpace(id.text), id.attrs["schemeID"]
+	switch {
+	case value == "":
+		v.fail("%s VKN/TCKN is required", label)
+	case scheme == "VKN" && !ValidVKN(value):
+		v.fail("%s VKN %q is not valid", label, value)
+	case scheme == "TCKN" && !ValidTCKN(value) && !(consumerAllowed && value == ConsumerTCKN):
+		v.fail("%s TCKN %q is not valid", label, value)
+	case scheme != "VKN" && scheme != "TCKN":
+		v.fail("%s identifier schemeID must be VKN or TCKN", label)
+	}
+	if scheme == "TCKN" {
+		if p.value("cac:Person/cbc:FirstName") == "" || p.value("cac:Person/cbc:FamilyName") == "" {
+			v.fail("%s is a person (TCKN) and needs a first and family name", label)
+		}
+	} else if p.value("cac:PartyName/cbc:Name") == "" {
+		v.fail("%s name is required", label)
+	}
+	if p.value("cac:PostalAddress/cbc:CitySubdivisionName") == "" || p.value("cac:PostalAddress/cbc:CityName") == "" {
+		v.fail("%s address needs a district and city", label)
+	}
+	if p.value("cac:PostalAddress/cac:Country/cbc:Name") == "" {
+		v.fail("%s address needs a country", label)
+	}
+}
+
+// taxTotal checks that the subtotals of n's TaxTotal add up and are well-formed KDV
+// subtotals, and returns the total.
+func (v *validator) taxTotal(n *node, label string) decimal.Decimal {
+	total := n.first("cac:TaxTotal")
+	if total == nil {
+		v.fail("%s has no TaxTotal", label)
+		return decimal.Zero
+	}
+	amount := v.amount(total, "cbc:TaxAmount", label)
+	// Line taxes are rounded to kuruş one by one, so a subtotal may be off by a kuruş per line
+	tolerance := decimal.New(int64(max(len(n.all("cac:InvoiceLine")), 1)), -2)
+	sum := decimal.Zero
+	subtotals := total.all("cac:TaxSubtotal")
+	if len(subtotals) == 0 {
+		v.fail("%s TaxTotal has no TaxSubtotal", label)
+	}
+	for _, st := range subtotals {
+		base := v.amount(st, "cbc:TaxableAmount", label)
+		tax := v.amount(st, "cbc:TaxAmount", label)
+		sum = sum.Add(tax)
+		percent, err := decimal.NewFromString(st.value("cbc:Percent"))
+		if err != nil {
+			v.fail("%s tax subtotal needs a Percent", label)
+			continue
+		}
+		if st.value("cac:TaxCategory/cac:TaxScheme/cbc:TaxTypeCode") != vatTaxTypeCode {
+			v.fail("%s tax subtotal must be KDV (TaxTypeCode %s)", label, vatTaxTypeCode)
+		}
+		if percent.IsZero() && st.value("cac:TaxCategory/cbc:TaxExemptionReasonCode") == "" {
+			v.fail("%s has 0%% KDV without a TaxExemptionReasonCode", label)
+		}
+		expected := base.Mul(percent).Div(decimal.NewFromInt(100))
+		if tax.Sub(expected).Abs().GreaterThan(tolerance) {
+			v.fail("%s KDV %s is not %s%% of %s", label, tax.StringFixed(2), percent, base.StringFixed(2))
+		}
+	}
+	if !amount.Equal(sum) {
+		v.fail("%s TaxAmount %s is not the sum of its subtotals %s", label, amount.StringFixed(2), sum.StringFixed(2))
+	}
+	return amount
+}
+
+// ReceiverTaxID returns the customer VKN/TCKN of a UBL-TR invoice.
+func ReceiverTaxID(data []byte) (string, error) {
+	root, err := parse(data)
+	if err != nil {
+		return "", err
+	}
+	id := root.value("cac:AccountingCustomerParty/cac:Party/cac:PartyIdentification/cbc:ID")
+	if id == "" {
+		return "", fmt.Errorf("invoice has no customer VKN/TCKN")
+	}
+	return id, nil
+}
@@ -0,0 +1,96 @@
+package pdf
+
+// The fonts use WinAnsiEncoding with the six Windows-1254 differences, so the Turkish
+// letters take the places of Ð, Ý, Þ, ð, ý and þ.
+var turkish = map[rune]byte{
+	'Ğ': 0xD0, 'İ': 0xDD, 'Ş': 0xDE,
+	'ğ': 0xF0, 'ı': 0xFD, 'ş': 0xFE,
+}
+
+var winAnsiExtra = map[rune]byte{
+	'€': 0x80, '‚': 0x82, '„': 0x84, '…': 0x85, '‰': 0x89,
+	'‘': 0x91, '’': 0x92, '“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97, '™': 0x99,
+}
+
+// encode converts s to the fonts' encoding; characters they cannot show become '?'.
+func encode(s string) []byte {
+	out := make([]byte, 0, len(s))
+	for _, r := range s {
+		if b, ok := turkish[r]; ok {
+			out = append(out, b)
+			continue
+		}
+		if b, ok := winAnsiExtra[r]; ok {
+			out = append(out, b)
+			continue
+		}
+		switch {
+		case r == '\t':
+			out = append(out, ' ')
+		case r >= 32 && r <= 126:
+			out = append(out, byte(r))
+		case r >= 0xA0 && r <= 0xFF && r != 0xD0 && r != 0xDD && r != 0xDE && r != 0xF0 && r != 0xFD && r != 0xFE:
+			out = append(out, byte(r))
+		default:
+			out = append(out, '?')
+		}
+	}
+	return out
+}
+
+// TextWidth returns the width of s in points.
+func TextWidth(font Font, size float64, s string) float64 {
+	table := &widths[0]
+	if font == Bold {
+		table = &widths[1]
+	}
+	total := 0
+	for _, b := range encode(s) {
+		total += table[b]
+	}
+	return float64(total) * size / 1000
+}
+
+// Glyph widths per 1000 units of font size, from the Helvetica and Helvetica-Bold metrics.
+var widths [2][256]int
+
+func init() {
+	regular := []int{
+		278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278, // space - /
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556, // 0 - ?
+		1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778, // @ - O
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556, // P - _
+		333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556, // ` - o
+		556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584, // p - ~
+	}
+	bold := []int{
+		278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+		975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+		333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+		611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+	}
+	for f, ascii := range [][]int{regular, bold} {
+		for i := range widths[f] {
+			widths[f][i] = 556
+		}
+		copy(widths[f][32:], ascii)
+	}
+
+	// Upper-case letters and punctuation beyond ASCII are the same in both weights
+	for _, f := range []int{0, 1} {
+		for b, w := range map[byte]int{
+			0x85: 1000, 0x89: 1000, 0x96: 556, 0x97: 1000, 0x99: 1000, 0x95: 350, 0xA0: 278,
+			0xC7: 722, 0xD0: 778, 0xD6: 778, 0xDC: 722, 0xDD: 278, 0xDE: 667, 0xFD: 278,
+		} {
+			widths[f][b] = w
+		}
+	}
+	for b, w := range map[byte]int{0x82: 222, 0x84: 333, 0x91: 222, 0x92: 222, 0x93: 333, 0x94: 333, 0xE7: 500, 0xF0: 556, 0xF6: 556, 0xFC: 556, 0xFE: 500} {
+		widths[0][b] = w
+	}
+	for b, w := range map[byte]int{0x82: 278, 0x84: 500, 0x91: 278, 0x92: 278, 0x93: 500, 0x94: 500, 0xE7: 556, 0xF0: 611, 0xF6: 611, 0xFC: 611, 0xFE: 556} {
+		widths[1][b] = w
+	}
+}
@@ -0,0 +1,218 @@
+// Package pdf writes simple A4 documents (text, lines and filled boxes) with the standard
+// Helvetica fonts, so documents can be rendered server-side without external dependencies.
+// Text is encoded as Windows-1254, which covers Turkish.
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// A4 page size in points. Coordinates passed to Document are measured from the top-left
+// corner of the page.
+const (
+	PageWidth  = 595.28
+	PageHeight = 841.89
+)
+
+type Font int
+
+const (
+	Regular Font = iota
+	Bold
+)
+
+// Color is an RGB color with components between 0 and 1.
+type Color struct {
+	R, G, B float64
+}
+
+var Black = Color{}
+
+type Document struct {
+	pages     []*bytes.Buffer
+	textColor Color
+}
+
+// New returns a document with one empty page.
+func New() *Document {
+	d := &Document{}
+	d.AddPage()
+	return d
+}
+
+// AddPage starts a new page; later drawing goes to it.
+func (d *Document) AddPage() {
+	d.pages = append(d.pages, &bytes.Buffer{})
+	d.textColor = Black
+}
+
+// PageCount returns the number of pages so far.
+func (d *Document) PageCount() int {
+	return len(d.pages)
+}
+
+func (d *Document) page() *bytes.Buffer {
+	return d.pages[len(d.pages)-1]
+}
+
+// SetTextColor sets the color of text drawn after it on the current page.
+func (d *Document) SetTextColor(c Color) {
+	d.textColor = c
+}
+
+// Text draws s with its baseline starting at (x, y).
+func (d *Document) Text(x, y float64, font Font, size float64, s string) {
+	if s == "" {
+		return
+	}
+	fmt.Fprintf(d.page(), "BT %s rg /F%d %s Tf %s %s Td (%s) Tj ET\n",
+		d.textColor, font+1, num(size), num(x), num(PageHeight-y), escape(encode(s)))
+}
+
+// TextRight draws s so that it ends at x.
+func (d *Document) TextRight(x, y float64, font Font, size float64, s string) {
+	d.Text(x-TextWidth(font, size, s), y, font, size, s)
+}
+
+// TextCenter draws s centered on x.
+func (d *Document) TextCenter(x, y float64, font Font, size float64, s string) {
+	d.Text(x-TextWidth(font, size, s)/2, y, font, size, s)
+}
+
+// Line draws a black line of the given width.
+func (d *Document) Line(x1, y1, x2, y2, width float64) {
+	fmt.Fprintf(d.page(), "%s w 0 0 0 RG %s %s m %s %s l S\n",
+		num(width), num(x1), num(PageHeight-y1), num(x2), num(PageHeight-y2))
+}
+
+// StrokeRect outlines a box whose top-left corner is (x, y).
+func (d *Document) StrokeRect(x, y, w, h, width float64) {
+	fmt.Fprintf(d.page(), "%s w 0 0 0 RG %s %s %s %s re S\n",
+		num(width), num(x), num(PageHeight-y-h), num(w), num(h))
+}
+
+// FillRect fills a box whose top-left corner is (x, y).
+func (d *Document) FillRect(x, y, w, h float64, c Color) {
+	fmt.Fprintf(d.page(), "%s rg %s %s %s %s re f\n",
+		c, num(x), num(PageHeight-y-h), num(w), num(h))
+}
+
+func (c Color) String() string {
+	return fmt.Sprintf("%s %s %s", num(c.R), num(c.G), num(c.B))
+}
+
+// Write writes the document as a PDF file.
+func (d *Document) Write(w io.Writer) error {
+	var out bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// 1: catalog, 2: page tree, 3: encoding, 4-5: fonts, then a page and its content per page
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 6+2*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	object("<< /Type /Encoding /BaseEncoding /WinAnsiEncoding /Differences [208 /Gbreve 221 /Idotaccent 222 /Scedilla 240 /gbreve 253 /dotlessi 254 /scedilla] >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding 3 0 R >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding 3 0 R >>")
+
+	for i, content := range d.pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] /Resources << /Font << /F1 4 0 R /F2 5 0 R >> >> /Contents %d 0 R >>",
+			num(PageWidth), num(PageHeight), 7+2*i))
+
+		var compressed bytes.Buffer
+		zw := zlib.NewWriter(&compressed)
+		if _, err := zw.Write(content.Bytes()); err != nil {
+			return err
+		}
+		if err := zw.Close(); err != nil {
+			return err
+		}
+		object(fmt.Sprintf("<< /Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream", compressed.Len(), compressed.Bytes()))
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	_, err := w.Write(out.Bytes())
+	return err
+}
+
+// Bytes returns the document as a PDF file.
+func (d *Document) Bytes() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := d.Write(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Wrap breaks s into lines no wider than width, breaking at spaces where possible.
+func Wrap(font Font, size, width float64, s string) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(s, "\n") {
+		line := ""
+		for _, word := range strings.Fields(paragraph) {
+			candidate := word
+			if line != "" {
+				candidate = line + " " + word
+			}
+			if line != "" && TextWidth(font, size, candidate) > width {
+				lines = append(lines, line)
+				candidate = word
+			}
+			for TextWidth(font, size, candidate) > width && len([]rune(candidate)) > 1 {
+				runes := []rune(candidate)
+				cut := len(runes) - 1
+				for cut > 1 && TextWidth(font, size, string(runes[:cut])) > width {
+					cut--
+				}
+				lines = append(lines, string(runes[:cut]))
+				candidate = string(runes[cut:])
+			}
+			line = candidate
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func num(f float64) string {
+	s := fmt.Sprintf("%.2f", f)
+	s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	if s == "-0" || s == "" {
+		return "0"
+	}
+	return s
+}
+
+func escape(b []byte) string {
+	var sb strings.Builder
+	for _, c := range b {
+		switch {
+		case c == '\\' || c == '(' || c == ')':
+			sb.WriteByte('\\')
+			sb.WriteByte(c)
+		case c < 32 || c > 126:
+			fmt.Fprintf(&sb, "\\%03o", c)
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,54 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeTurkish(t *testing.T) {
+	assert.Equal(t, []byte{0xD0, 0xDC, 0xDE, 0xDD, 0xD6, 0xC7, 0xF0, 0xFC, 0xFE, 0xFD, 0xF6, 0xE7}, encode("ĞÜŞİÖÇğüşıöç"))
+	assert.Equal(t, []byte("a?b"), encode("a→b"))
+	assert.Equal(t, `\(x\) \\ \360`, escape(encode("(x) \\ ğ")))
+}
+
+func TestTextWidthAndWrap(t *testing.T) {
+	assert.InDelta(t, 5.56*3, TextWidth(Regular, 10, "123"), 0.001)
+	assert.Greater(t, TextWidth(Bold, 10, "Şirket"), TextWidth(Regular, 10, "Şirket"))
+
+	lines := Wrap(Regular, 10, 80, "Atatürk Bulvarı No: 12\nÇankaya Ankara")
+	assert.Equal(t, []string{"Atatürk Bulvarı", "No: 12", "Çankaya Ankara"}, lines)
+	for _, line := range Wrap(Bold, 10, 30, "Kızılırmakboyu Caddesi") {
+		assert.LessOrEqual(t, TextWidth(Bold, 10, line), 30.0)
+	}
+}
+
+func TestWriteCrossReference(t *testing.T) {
+	d := New()
+	d.Text(40, 40, Bold, 14, "Hesap Ekstresi")
+	d.AddPage()
+	d.TextRight(555, 40, Regular, 9, "Sayfa 2")
+	out, err := d.Bytes()
+	require.NoError(t, err)
+
+	assert.True(t, bytes.HasPrefix(out, []byte("%PDF-1.4")))
+	assert.True(t, bytes.HasSuffix(out, []byte("%%EOF\n")))
+	assert.Contains(t, string(out), "/Count 2")
+
+	// Every xref entry must point at the start of its object
+	startxref := regexp.MustCompile(`startxref\n(\d+)`).FindSubmatch(out)
+	require.NotNil(t, startxref)
+	xref, _ := strconv.Atoi(string(startxref[1]))
+	assert.True(t, bytes.HasPrefix(out[xref:], []byte("xref")))
+	entries := regexp.MustCompile(`(\d{10}) 00000 n`).FindAllSubmatch(out, -1)
+	require.Len(t, entries, 9)
+	for i, entry := range entries {
+		offset, _ := strconv.Atoi(string(entry[1]))
+		assert.True(t, bytes.HasPrefix(out[offset:], []byte(fmt.Sprintf("%d 0 obj", i+1))), "object %d", i+1)
+	}
+}
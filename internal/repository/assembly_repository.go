@@ -0,0 +1,316 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"sancaksoft/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shopspring/decimal"
+)
+
+const assemblyOrderColumns = `
+	id, tenant_id, product_id, warehouse_id, order_number, status, quantity, produced_qty, scrap_qty,
+	lot_id, total_cost, unit_cost, COALESCE(note, ''), completed_at, created_at, updated_at
+`
+
+type AssemblyRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewAssemblyRepository(db *pgxpool.Pool) *AssemblyRepository {
+	return &AssemblyRepository{db: db}
+}
+
+// CreateAuditLog records a change to a bill of materials or an assembly order.
+func (r *AssemblyRepository) CreateAuditLog(ctx context.Context, tx pgx.Tx, log *domain.AuditLog) error {
+	return createAuditLog(ctx, r.db, tx, log)
+}
+
+func scanAssemblyOrder(row pgx.Row, o *domain.AssemblyOrder) error {
+	return row.Scan(
+		&o.ID, &o.TenantID, &o.ProductID, &o.WarehouseID, &o.OrderNumber, &o.Status, &o.Quantity, &o.ProducedQty, &o.ScrapQty,
+		&o.LotID, &o.TotalCost, &o.UnitCost, &o.Note, &o.CompletedAt, &o.CreatedAt, &o.UpdatedAt,
+	)
+}
+
+// GetProduct returns a product, or nil if not found.
+func (r *AssemblyRepository) GetProduct(ctx context.Context, tx pgx.Tx, tenantID, productID uuid.UUID) (*domain.Product, error) {
+	return getProduct(ctx, tx, tenantID, productID, "")
+}
+
+// LockProduct locks a product row so its bill of materials is changed one request at a time.
+// It returns nil if the product is not found.
+func (r *AssemblyRepository) LockProduct(ctx context.Context, tx pgx.Tx, tenantID, productID uuid.UUID) (*domain.Product, error) {
+	return getProduct(ctx, tx, tenantID, productID, "FOR UPDATE")
+}
+
+// ProductIsKit reports whether the product is sold as a kit of other products.
+func (r *AssemblyRepository) ProductIsKit(ctx context.Context, tx pgx.Tx, tenantID, productID uuid.UUID) (bool, error) {
+	return productIsKit(ctx, tx, tenantID, productID)
+}
+
+// ProductIsVariantParent reports whether the product's stock is kept on its variants.
+func (r *AssemblyRepository) ProductIsVariantParent(ctx context.Context, tx pgx.Tx, tenantID, productID uuid.UUID) (bool, error) {
+	return productIsVariantParent(ctx, tx, tenantID, productID)
+}
+
+// ProductTracksLots reports whether receipts of the product need a lot number.
+func (r *AssemblyRepository) ProductTracksLots(ctx context.Context, tx pgx.Tx, tenantID, productID uuid.UUID) (bool, error) {
+	return productTracksLots(ctx, tx, tenantID, productID)
+}
+
+// ProductTracksSerials reports whether every unit of the product moves under a serial number.
+func (r *AssemblyRepository) ProductTracksSerials(ctx context.Context, tx pgx.Tx, tenantID, productID uuid.UUID) (bool, error) {
+	return productTracksSerials(ctx, tx, tenantID, productID)
+}
+
+// EnsureLot returns the product's lot with the given number, creating it on first receipt.
+func (r *AssemblyRepository) EnsureLot(ctx context.Context, tx pgx.Tx, tenantID, productID uuid.UUID, lotNumber string, expiryDate *time.Time) (*domain.Lot, error) {
+	return ensureLot(ctx, tx, tenantID, productID, lotNumber, expiryDate)
+}
+
+// BOMContains reports whether productID appears anywhere in the bill of materials tree of
+// rootID; adding rootID as a component of productID would then make a cycle.
+func (r *AssemblyRepository) BOMContains(ctx context.Context, tx pgx.Tx, tenantID, rootID, productID uuid.UUID) (bool, error) {
+	var contains bool
+	err := tx.QueryRow(ctx, `
+		WITH RECURSIVE tree AS (
+			SELECT component_id FROM bom_components WHERE tenant_id = $1 AND product_id = $2
+			UNION
+			SELECT bc.component_id
+			FROM bom_components bc
+			JOIN tree t ON bc.product_id = t.component_id
+			WHERE bc.tenant_id = $1
+		)
+		SELECT EXISTS (SELECT 1 FROM tree WHERE component_id = $3)
+	`, tenantID, rootID, productID).Scan(&contains)
+	if err != nil {
+		return false, fmt.Errorf("failed to check bill of materials of product %s: %w", rootID, err)
+	}
+	return contains, nil
+}
+
+// GetBOM returns the bill of materials of a product, or nil if it has none.
+func (r *AssemblyRepository) GetBOM(ctx context.Context, tenantID, productID uuid.UUID) (*domain.BillOfMaterials, error) {
+	return getBillOfMaterials(ctx, r.db, tenantID, productID, "")
+}
+
+// GetBOMForUpdate returns the bill of materials of a product and locks it while an order is
+// planned from it. It returns nil if the product has none.
+func (r *AssemblyRepository) GetBOMForUpdate(ctx context.Context, tx pgx.Tx, tenantID, productID uuid.UUID) (*domain.BillOfMaterials, error) {
+	return getBillOfMaterials(ctx, tx, tenantID, productID, "FOR UPDATE")
+}
+
+// SaveBOM creates or replaces the bill of materials of a product.
+func (r *AssemblyRepository) SaveBOM(ctx context.Context, tx pgx.Tx, bom *domain.BillOfMaterials) error {
+	err := tx.QueryRow(ctx, `
+		INSERT INTO bills_of_materials (tenant_id, product_id, output_quantity, note, updated_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		ON CONFLICT (product_id) DO UPDATE
+		SET output_quantity = EXCLUDED.output_quantity, note = EXCLUDED.note, updated_at = NOW()
+		RETURNING updated_at
+	`, bom.TenantID, bom.ProductID, bom.OutputQuantity, bom.Note).Scan(&bom.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save bill of materials: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `
+		DELETE FROM bom_components WHERE tenant_id = $1 AND product_id = $2
+	`, bom.TenantID, bom.ProductID); err != nil {
+		return fmt.Errorf("failed to clear bill of materials: %w", err)
+	}
+	for _, c := range bom.Components {
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO bom_components (tenant_id, product_id, component_id, quantity)
+			VALUES ($1, $2, $3, $4)
+		`, bom.TenantID, bom.ProductID, c.ComponentID, c.Quantity); err != nil {
+			return fmt.Errorf("failed to add bill of materials component %s: %w", c.ComponentID, err)
+		}
+	}
+	return nil
+}
+
+// DeleteBOM removes the bill of materials of a product. Orders already planned keep their
+// components.
+func (r *AssemblyRepository) DeleteBOM(ctx context.Context, tx pgx.Tx, tenantID, productID uuid.UUID) (bool, error) {
+	tag, err := tx.Exec(ctx, `
+		DELETE FROM bills_of_materials WHERE tenant_id = $1 AND product_id = $2
+	`, tenantID, productID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete bill of materials: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// GenerateNextOrderNumber generates the next sequential assembly order number atomically.
+func (r *AssemblyRepository) GenerateNextOrderNumber(ctx context.Context, tx pgx.Tx, tenantID uuid.UUID) (string, error) {
+	return nextDocumentNumber(ctx, tx, tenantID, "ASSEMBLY_ORDER", "AO")
+}
+
+// CreateAssemblyOrder inserts the order header.
+func (r *AssemblyRepository) CreateAssemblyOrder(ctx context.Context, tx pgx.Tx, o *domain.AssemblyOrder) error {
+	query := `
+		INSERT INTO assembly_orders (id, tenant_id, product_id, warehouse_id, order_number, status, quantity, note, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW(), NOW())
+		RETURNING created_at, updated_at
+	`
+	return tx.QueryRow(ctx, query,
+		o.ID,
+		o.TenantID,
+		o.ProductID,
+		o.WarehouseID,
+		o.OrderNumber,
+		o.Status,
+		o.Quantity,
+		o.Note,
+	).Scan(&o.CreatedAt, &o.UpdatedAt)
+}
+
+// CreateAssemblyOrderComponent inserts a component line of an order.
+func (r *AssemblyRepository) CreateAssemblyOrderComponent(ctx context.Context, tx pgx.Tx, c *domain.AssemblyOrderComponent) error {
+	_, err := tx.Exec(ctx, `
+		INSERT INTO assembly_order_components (id, tenant_id, order_id, product_id, quantity, unit_cost)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, c.ID, c.TenantID, c.OrderID, c.ProductID, c.Quantity, c.UnitCost)
+	return err
+}
+
+// SetComponentUnitCost records the cost a component was consumed at.
+func (r *AssemblyRepository) SetComponentUnitCost(ctx context.Context, tx pgx.Tx, tenantID, componentLineID uuid.UUID, unitCost decimal.Decimal) error {
+	_, err := tx.Exec(ctx, `
+		UPDATE assembly_order_components SET unit_cost = $3 WHERE tenant_id = $1 AND id = $2
+	`, tenantID, componentLineID, unitCost)
+	return err
+}
+
+// GetAssemblyOrder returns the order with its components, or nil if not found.
+func (r *AssemblyRepository) GetAssemblyOrder(ctx context.Context, tenantID, orderID uuid.UUID) (*domain.AssemblyOrder, error) {
+	return r.getAssemblyOrder(ctx, r.db, tenantID, orderID, false)
+}
+
+// GetAssemblyOrderForUpdate returns the order with its components and locks the header row.
+func (r *AssemblyRepository) GetAssemblyOrderForUpdate(ctx context.Context, tx pgx.Tx, tenantID, orderID uuid.UUID) (*domain.AssemblyOrder, error) {
+	return r.getAssemblyOrder(ctx, tx, tenantID, orderID, true)
+}
+
+func (r *AssemblyRepository) getAssemblyOrder(ctx context.Context, q dbtx, tenantID, orderID uuid.UUID, forUpdate bool) (*domain.AssemblyOrder, error) {
+	query := `SELECT ` + assemblyOrderColumns + `
+		FROM assembly_orders
+		WHERE tenant_id = $1 AND id = $2
+	`
+	if forUpdate {
+		query += ` FOR UPDATE`
+	}
+
+	var o domain.AssemblyOrder
+	if err := scanAssemblyOrder(q.QueryRow(ctx, query, tenantID, orderID), &o); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get assembly order: %w", err)
+	}
+
+	rows, err := q.Query(ctx, `
+		SELECT id, tenant_id, order_id, product_id, quantity, unit_cost
+		FROM assembly_order_components
+		WHERE tenant_id = $1 AND order_id = $2
+		ORDER BY product_id
+	`, tenantID, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get assembly order components: %w", err)
+	}
+	defer rows.Close()
+
+	o.Components = []domain.AssemblyOrderComponent{}
+	for rows.Next() {
+		var c domain.AssemblyOrderComponent
+		if err := rows.Scan(&c.ID, &c.TenantID, &c.OrderID, &c.ProductID, &c.Quantity, &c.UnitCost); err != nil {
+			return nil, fmt.Errorf("failed to scan assembly order component: %w", err)
+		}
+		o.Components = append(o.Components, c)
+	}
+	return &o, rows.Err()
+}
+
+// UpdateAssemblyOrder persists the status, outcome and costs of an order.
+func (r *AssemblyRepository) UpdateAssemblyOrder(ctx context.Context, tx pgx.Tx, o *domain.AssemblyOrder) error {
+	query := `
+		UPDATE assembly_orders
+		SET status = $3, produced_qty = $4, scrap_qty = $5, lot_id = $6, total_cost = $7, unit_cost = $8,
+			completed_at = $9, updated_at = NOW()
+		WHERE tenant_id = $1 AND id = $2
+		RETURNING updated_at
+	`
+	return tx.QueryRow(ctx, query,
+		o.TenantID, o.ID, o.Status, o.ProducedQty, o.ScrapQty, o.LotID, o.TotalCost, o.UnitCost, o.CompletedAt,
+	).Scan(&o.UpdatedAt)
+}
+
+// ListAssemblyOrders lists order headers, optionally filtered by product and status.
+func (r *AssemblyRepository) ListAssemblyOrders(ctx context.Context, tenantID uuid.UUID, productID *uuid.UUID, status domain.AssemblyOrderStatus) ([]domain.AssemblyOrder, error) {
+	query := `SELECT ` + assemblyOrderColumns + `
+		FROM assembly_orders
+		WHERE tenant_id = $1
+		  AND ($2::uuid IS NULL OR product_id = $2)
+		  AND ($3 = '' OR status = $3)
+		ORDER BY created_at DESC
+		LIMIT 100
+	`
+	rows, err := r.db.Query(ctx, query, tenantID, productID, string(status))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list assembly orders: %w", err)
+	}
+	defer rows.Close()
+
+	orders := []domain.AssemblyOrder{}
+	for rows.Next() {
+		var o domain.AssemblyOrder
+		if err := scanAssemblyOrder(rows, &o); err != nil {
+			return nil, fmt.Errorf("failed to scan assembly order: %w", err)
+		}
+		orders = append(orders, o)
+	}
+	return orders, rows.Err()
+}
+
+func getBillOfMaterials(ctx context.Context, q dbtx, tenantID, productID uuid.UUID, lock string) (*domain.BillOfMaterials, error) {
+	bom := domain.BillOfMaterials{TenantID: tenantID, ProductID: productID}
+	err := q.QueryRow(ctx, `
+		SELECT output_quantity, COALESCE(note, ''), updated_at
+		FROM bills_of_materials
+		WHERE tenant_id = $1 AND product_id = $2
+		`+lock, tenantID, productID).Scan(&bom.OutputQuantity, &bom.Note, &bom.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bill of materials: %w", err)
+	}
+
+	rows, err := q.Query(ctx, `
+		SELECT bc.component_id, p.name, p.sku, bc.quantity
+		FROM bom_components bc
+		JOIN products p ON p.id = bc.component_id
+		WHERE bc.tenant_id = $1 AND bc.product_id = $2
+		ORDER BY bc.component_id
+	`, tenantID, productID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list bill of materials components: %w", err)
+	}
+	defer rows.Close()
+
+	bom.Components = []domain.BOMComponent{}
+	for rows.Next() {
+		var c domain.BOMComponent
+		if err := rows.Scan(&c.ComponentID, &c.ComponentName, &c.SKU, &c.Quantity); err != nil {
+			return nil, fmt.Errorf("failed to scan bill of materials component: %w", err)
+		}
+		bom.Components = append(bom.Components, c)
+	}
+	return &bom, rows.Err()
+}
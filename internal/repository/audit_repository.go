@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"sancaksoft/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type AuditRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewAuditRepository(db *pgxpool.Pool) *AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+// createAuditLog inserts an audit log entry in tx, or on its own for changes made outside
+// a transaction. Repositories of audited services expose it as CreateAuditLog.
+func createAuditLog(ctx context.Context, db *pgxpool.Pool, tx pgx.Tx, log *domain.AuditLog) error {
+	var q dbtx = db
+	if tx != nil {
+		q = tx
+	}
+	var details any
+	if len(log.Details) > 0 {
+		details = log.Details
+	}
+	_, err := q.Exec(ctx, `
+		INSERT INTO audit_logs (id, tenant_id, user_id, entity_type, entity_id, action, details, ip_address, request_id, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, ''), NULLIF($9, ''), NOW())
+	`, log.ID, log.TenantID, log.UserID, log.EntityType, log.EntityID, log.Action, details, log.IPAddress, log.RequestID)
+	return err
+}
+
+// ListAuditLogs returns the latest 100 audit entries matching the filter.
+func (r *AuditRepository) ListAuditLogs(ctx context.Context, f domain.AuditLogFilter) ([]domain.AuditLog, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT id, tenant_id, user_id, entity_type, entity_id, action, details, COALESCE(ip_address, ''), COALESCE(request_id, ''), created_at
+		FROM audit_logs
+		WHERE tenant_id = $1
+			AND ($2::text = '' OR entity_type = $2)
+			AND ($3::uuid IS NULL OR entity_id = $3)
+			AND ($4::uuid IS NULL OR user_id = $4)
+			AND ($5::text = '' OR action = $5)
+			AND ($6::timestamp IS NULL OR created_at >= $6)
+			AND ($7::timestamp IS NULL OR created_at < $7)
+		ORDER BY created_at DESC, id
+		LIMIT 100
+	`, f.TenantID, f.EntityType, f.EntityID, f.UserID, f.Action, f.From, f.To)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit logs: %w", err)
+	}
+	defer rows.Close()
+
+	logs := []domain.AuditLog{}
+	for rows.Next() {
+		var l domain.AuditLog
+		var details []byte
+		if err := rows.Scan(&l.ID, &l.TenantID, &l.UserID, &l.EntityType, &l.EntityID, &l.Action, &details,
+			&l.IPAddress, &l.RequestID, &l.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan audit log: %w", err)
+		}
+		if len(details) > 0 {
+			if err := json.Unmarshal(details, &l.Details); err != nil {
+				return nil, fmt.Errorf("failed to decode audit log details: %w", err)
+			}
+		}
+		logs = append(logs, l)
+	}
+	return logs, rows.Err()
+}
@@ -0,0 +1,385 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"sancaksoft/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// categoryTree walks the tenant's categories from the roots down, building each path.
+const categoryTree = `
+	WITH RECURSIVE tree AS (
+		SELECT id, tenant_id, parent_id, name, name::text AS path, 0 AS depth, created_at, updated_at
+		FROM product_categories
+		WHERE tenant_id = $1 AND parent_id IS NULL
+		UNION ALL
+		SELECT c.id, c.tenant_id, c.parent_id, c.name, t.path || ' / ' || c.name, t.depth + 1, c.created_at, c.updated_at
+		FROM product_categories c
+		JOIN tree t ON c.parent_id = t.id
+	)
+	SELECT id, tenant_id, parent_id, name, path, depth, created_at, updated_at FROM tree`
+
+// categoryChain lists a category ($2) and its ancestors; depth 0 is the category itself.
+const categoryChain = `
+	WITH RECURSIVE chain AS (
+		SELECT id, parent_id, 0 AS depth FROM product_categories WHERE tenant_id = $1 AND id = $2
+		UNION ALL
+		SELECT c.id, c.parent_id, chain.depth + 1 FROM product_categories c JOIN chain ON c.id = chain.parent_id
+	)`
+
+type CategoryRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewCategoryRepository(db *pgxpool.Pool) *CategoryRepository {
+	return &CategoryRepository{db: db}
+}
+
+// CreateAuditLog records a change to a category, brand or attribute, or to a product's classification.
+func (r *CategoryRepository) CreateAuditLog(ctx context.Context, tx pgx.Tx, log *domain.AuditLog) error {
+	return createAuditLog(ctx, r.db, tx, log)
+}
+
+// CreateCategory inserts a category.
+func (r *CategoryRepository) CreateCategory(ctx context.Context, tx pgx.Tx, c *domain.ProductCategory) error {
+	err := tx.QueryRow(ctx, `
+		INSERT INTO product_categories (id, tenant_id, parent_id, name, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, NOW(), NOW())
+		RETURNING created_at, updated_at
+	`, c.ID, c.TenantID, c.ParentID, c.Name).Scan(&c.CreatedAt, &c.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create category: %w", err)
+	}
+	return nil
+}
+
+// GetCategory returns a category with its path, or nil if not found.
+func (r *CategoryRepository) GetCategory(ctx context.Context, tenantID, categoryID uuid.UUID) (*domain.ProductCategory, error) {
+	return getCategory(ctx, r.db, tenantID, categoryID)
+}
+
+// GetCategoryForUpdate locks a category row in tx and returns it with its path, or nil if
+// not found.
+func (r *CategoryRepository) GetCategoryForUpdate(ctx context.Context, tx pgx.Tx, tenantID, categoryID uuid.UUID) (*domain.ProductCategory, error) {
+	if _, err := tx.Exec(ctx, `SELECT 1 FROM product_categories WHERE tenant_id = $1 AND id = $2 FOR UPDATE`, tenantID, categoryID); err != nil {
+		return nil, fmt.Errorf("failed to lock category: %w", err)
+	}
+	return getCategory(ctx, tx, tenantID, categoryID)
+}
+
+func getCategory(ctx context.Context, q dbtx, tenantID, categoryID uuid.UUID) (*domain.ProductCategory, error) {
+	var c domain.ProductCategory
+	err := scanCategory(q.QueryRow(ctx, categoryTree+` WHERE id = $2`, tenantID, categoryID), &c)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get category: %w", err)
+	}
+	return &c, nil
+}
+
+// ListCategories returns the category tree flattened in path order.
+func (r *CategoryRepository) ListCategories(ctx context.Context, tenantID uuid.UUID) ([]domain.ProductCategory, error) {
+	rows, err := r.db.Query(ctx, categoryTree+` ORDER BY path`, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list categories: %w", err)
+	}
+	defer rows.Close()
+
+	categories := []domain.ProductCategory{}
+	for rows.Next() {
+		var c domain.ProductCategory
+		if err := scanCategory(rows, &c); err != nil {
+			return nil, fmt.Errorf("failed to scan category: %w", err)
+		}
+		categories = append(categories, c)
+	}
+	return categories, rows.Err()
+}
+
+// CategoryNameTaken reports whether another category under the same parent has the name.
+func (r *CategoryRepository) CategoryNameTaken(ctx context.Context, tenantID uuid.UUID, parentID *uuid.UUID, name string, excludeID *uuid.UUID) (bool, error) {
+	var taken bool
+	err := r.db.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM product_categories
+			WHERE tenant_id = $1 AND parent_id IS NOT DISTINCT FROM $2 AND name = $3
+			  AND ($4::uuid IS NULL OR id <> $4)
+		)
+	`, tenantID, parentID, name, excludeID).Scan(&taken)
+	if err != nil {
+		return false, fmt.Errorf("failed to check category name: %w", err)
+	}
+	return taken, nil
+}
+
+// IsInSubtree reports whether candidateID is categoryID or one of its descendants.
+func (r *CategoryRepository) IsInSubtree(ctx context.Context, tenantID, categoryID, candidateID uuid.UUID) (bool, error) {
+	var found bool
+	err := r.db.QueryRow(ctx, categoryChain+`
+		SELECT EXISTS (SELECT 1 FROM chain WHERE id = $3)
+	`, tenantID, candidateID, categoryID).Scan(&found)
+	if err != nil {
+		return false, fmt.Errorf("failed to check category tree: %w", err)
+	}
+	return found, nil
+}
+
+// UpdateCategory renames and moves a category. It reports whether the category exists.
+func (r *CategoryRepository) UpdateCategory(ctx context.Context, tx pgx.Tx, c *domain.ProductCategory) (bool, error) {
+	tag, err := tx.Exec(ctx, `
+		UPDATE product_categories SET name = $3, parent_id = $4, updated_at = NOW()
+		WHERE tenant_id = $1 AND id = $2
+	`, c.TenantID, c.ID, c.Name, c.ParentID)
+	if err != nil {
+		return false, fmt.Errorf("failed to update category: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// CategoryInUse reports whether a category has subcategories, products or invoiced sales.
+func (r *CategoryRepository) CategoryInUse(ctx context.Context, tx pgx.Tx, tenantID, categoryID uuid.UUID) (bool, error) {
+	var inUse bool
+	err := tx.QueryRow(ctx, `
+		SELECT EXISTS (SELECT 1 FROM product_categories WHERE tenant_id = $1 AND parent_id = $2)
+		    OR EXISTS (SELECT 1 FROM products WHERE tenant_id = $1 AND category_id = $2)
+		    OR EXISTS (SELECT 1 FROM invoice_items WHERE tenant_id = $1 AND category_id = $2)
+	`, tenantID, categoryID).Scan(&inUse)
+	if err != nil {
+		return false, fmt.Errorf("failed to check category usage: %w", err)
+	}
+	return inUse, nil
+}
+
+// DeleteCategory deletes a category and its attributes. It reports whether the category existed.
+func (r *CategoryRepository) DeleteCategory(ctx context.Context, tx pgx.Tx, tenantID, categoryID uuid.UUID) (bool, error) {
+	tag, err := tx.Exec(ctx, `DELETE FROM product_categories WHERE tenant_id = $1 AND id = $2`, tenantID, categoryID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete category: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// CreateBrand inserts a brand. It reports false when the tenant already has a brand with the name.
+func (r *CategoryRepository) CreateBrand(ctx context.Context, tx pgx.Tx, b *domain.Brand) (bool, error) {
+	err := tx.QueryRow(ctx, `
+		INSERT INTO brands (id, tenant_id, name, created_at, updated_at)
+		VALUES ($1, $2, $3, NOW(), NOW())
+		ON CONFLICT (tenant_id, name) DO NOTHING
+		RETURNING created_at, updated_at
+	`, b.ID, b.TenantID, b.Name).Scan(&b.CreatedAt, &b.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to create brand: %w", err)
+	}
+	return true, nil
+}
+
+// GetBrand returns a brand, or nil if not found.
+func (r *CategoryRepository) GetBrand(ctx context.Context, tenantID, brandID uuid.UUID) (*domain.Brand, error) {
+	var b domain.Brand
+	err := r.db.QueryRow(ctx, `
+		SELECT id, tenant_id, name, created_at, updated_at FROM brands WHERE tenant_id = $1 AND id = $2
+	`, tenantID, brandID).Scan(&b.ID, &b.TenantID, &b.Name, &b.CreatedAt, &b.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get brand: %w", err)
+	}
+	return &b, nil
+}
+
+// ListBrands returns the tenant's brands by name.
+func (r *CategoryRepository) ListBrands(ctx context.Context, tenantID uuid.UUID) ([]domain.Brand, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT id, tenant_id, name, created_at, updated_at FROM brands WHERE tenant_id = $1 ORDER BY name
+	`, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list brands: %w", err)
+	}
+	defer rows.Close()
+
+	brands := []domain.Brand{}
+	for rows.Next() {
+		var b domain.Brand
+		if err := rows.Scan(&b.ID, &b.TenantID, &b.Name, &b.CreatedAt, &b.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan brand: %w", err)
+		}
+		brands = append(brands, b)
+	}
+	return brands, rows.Err()
+}
+
+// AttributeCodeTaken reports whether the category, an ancestor or a descendant already
+// defines an attribute with the code, which would make the code ambiguous for a product.
+func (r *CategoryRepository) AttributeCodeTaken(ctx context.Context, tenantID, categoryID uuid.UUID, code string) (bool, error) {
+	var taken bool
+	err := r.db.QueryRow(ctx, categoryChain+`, subtree AS (
+			SELECT id FROM product_categories WHERE tenant_id = $1 AND id = $2
+			UNION ALL
+			SELECT c.id FROM product_categories c JOIN subtree st ON c.parent_id = st.id
+		)
+		SELECT EXISTS (
+			SELECT 1 FROM category_attributes
+			WHERE tenant_id = $1 AND code = $3
+			  AND (category_id IN (SELECT id FROM chain) OR category_id IN (SELECT id FROM subtree))
+		)
+	`, tenantID, categoryID, code).Scan(&taken)
+	if err != nil {
+		return false, fmt.Errorf("failed to check attribute code: %w", err)
+	}
+	return taken, nil
+}
+
+// CreateAttribute inserts a category attribute.
+func (r *CategoryRepository) CreateAttribute(ctx context.Context, tx pgx.Tx, a *domain.CategoryAttribute) error {
+	err := tx.QueryRow(ctx, `
+		INSERT INTO category_attributes (id, tenant_id, category_id, code, name, data_type, options, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW())
+		RETURNING created_at
+	`, a.ID, a.TenantID, a.CategoryID, a.Code, a.Name, a.DataType, a.Options).Scan(&a.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create attribute: %w", err)
+	}
+	return nil
+}
+
+// ListCategoryAttributes returns the attributes a category's products can have: its own and
+// its ancestors', from the root down.
+func (r *CategoryRepository) ListCategoryAttributes(ctx context.Context, tenantID, categoryID uuid.UUID) ([]domain.CategoryAttribute, error) {
+	return listCategoryAttributes(ctx, r.db, tenantID, categoryID)
+}
+
+// LockProductClassification locks a product and returns its category and brand. It reports
+// whether the product exists.
+func (r *CategoryRepository) LockProductClassification(ctx context.Context, tx pgx.Tx, tenantID, productID uuid.UUID) (categoryID, brandID *uuid.UUID, found bool, err error) {
+	err = tx.QueryRow(ctx, `
+		SELECT category_id, brand_id FROM products WHERE tenant_id = $1 AND id = $2 AND deleted_at IS NULL FOR UPDATE
+	`, tenantID, productID).Scan(&categoryID, &brandID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil, false, nil
+	}
+	if err != nil {
+		return nil, nil, false, fmt.Errorf("failed to lock product %s: %w", productID, err)
+	}
+	return categoryID, brandID, true, nil
+}
+
+// GetProductAttributeValues returns a product's attribute values by code.
+func (r *CategoryRepository) GetProductAttributeValues(ctx context.Context, tx pgx.Tx, tenantID, productID uuid.UUID) (map[string]string, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT a.code, v.value
+		FROM product_attribute_values v
+		JOIN category_attributes a ON a.id = v.attribute_id
+		WHERE v.tenant_id = $1 AND v.product_id = $2
+	`, tenantID, productID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product attributes: %w", err)
+	}
+	defer rows.Close()
+
+	values := make(map[string]string)
+	for rows.Next() {
+		var code, value string
+		if err := rows.Scan(&code, &value); err != nil {
+			return nil, fmt.Errorf("failed to scan product attribute: %w", err)
+		}
+		values[code] = value
+	}
+	return values, rows.Err()
+}
+
+// ListApplicableAttributes is ListCategoryAttributes inside a transaction.
+func (r *CategoryRepository) ListApplicableAttributes(ctx context.Context, tx pgx.Tx, tenantID, categoryID uuid.UUID) ([]domain.CategoryAttribute, error) {
+	return listCategoryAttributes(ctx, tx, tenantID, categoryID)
+}
+
+// ReplaceProductAttributes replaces all attribute values of a product.
+func (r *CategoryRepository) ReplaceProductAttributes(ctx context.Context, tx pgx.Tx, tenantID, productID uuid.UUID, values map[uuid.UUID]string) error {
+	if _, err := tx.Exec(ctx, `DELETE FROM product_attribute_values WHERE tenant_id = $1 AND product_id = $2`, tenantID, productID); err != nil {
+		return fmt.Errorf("failed to clear product attributes: %w", err)
+	}
+	for attributeID, value := range values {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO product_attribute_values (tenant_id, product_id, attribute_id, value)
+			VALUES ($1, $2, $3, $4)
+		`, tenantID, productID, attributeID, value)
+		if err != nil {
+			return fmt.Errorf("failed to set product attribute: %w", err)
+		}
+	}
+	return nil
+}
+
+// SetProductClassification sets a product's category and brand.
+func (r *CategoryRepository) SetProductClassification(ctx context.Context, tx pgx.Tx, tenantID, productID uuid.UUID, categoryID, brandID *uuid.UUID) error {
+	_, err := tx.Exec(ctx, `
+		UPDATE products SET category_id = $3, brand_id = $4, updated_at = NOW()
+		WHERE tenant_id = $1 AND id = $2
+	`, tenantID, productID, categoryID, brandID)
+	if err != nil {
+		return fmt.Errorf("failed to set product category: %w", err)
+	}
+	return nil
+}
+
+// PruneAttributeValues deletes the tenant's attribute values that no longer apply because
+// the product changed category or its category moved in the tree.
+func (r *CategoryRepository) PruneAttributeValues(ctx context.Context, tx pgx.Tx, tenantID uuid.UUID) error {
+	_, err := tx.Exec(ctx, `
+		WITH RECURSIVE ancestry AS (
+			SELECT id AS category_id, id AS ancestor_id, parent_id FROM product_categories WHERE tenant_id = $1
+			UNION ALL
+			SELECT a.category_id, c.id, c.parent_id FROM ancestry a JOIN product_categories c ON c.id = a.parent_id
+		)
+		DELETE FROM product_attribute_values v
+		USING products p
+		WHERE p.id = v.product_id AND v.tenant_id = $1
+		  AND NOT EXISTS (
+			SELECT 1 FROM category_attributes ca
+			JOIN ancestry an ON an.ancestor_id = ca.category_id
+			WHERE ca.id = v.attribute_id AND an.category_id = p.category_id
+		  )
+	`, tenantID)
+	if err != nil {
+		return fmt.Errorf("failed to prune attribute values: %w", err)
+	}
+	return nil
+}
+
+func scanCategory(row pgx.Row, c *domain.ProductCategory) error {
+	return row.Scan(&c.ID, &c.TenantID, &c.ParentID, &c.Name, &c.Path, &c.Depth, &c.CreatedAt, &c.UpdatedAt)
+}
+
+func listCategoryAttributes(ctx context.Context, q dbtx, tenantID, categoryID uuid.UUID) ([]domain.CategoryAttribute, error) {
+	rows, err := q.Query(ctx, categoryChain+`
+		SELECT a.id, a.tenant_id, a.category_id, a.code, a.name, a.data_type, a.options, a.created_at
+		FROM category_attributes a
+		JOIN chain ON chain.id = a.category_id
+		ORDER BY chain.depth DESC, a.code
+	`, tenantID, categoryID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list category attributes: %w", err)
+	}
+	defer rows.Close()
+
+	attributes := []domain.CategoryAttribute{}
+	for rows.Next() {
+		var a domain.CategoryAttribute
+		if err := rows.Scan(&a.ID, &a.TenantID, &a.CategoryID, &a.Code, &a.Name, &a.DataType, &a.Options, &a.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan category attribute: %w", err)
+		}
+		attributes = append(attributes, a)
+	}
+	return attributes, rows.Err()
+}
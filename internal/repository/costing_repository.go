@@ -0,0 +1,289 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"sancaksoft/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shopspring/decimal"
+)
+
+type CostingRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewCostingRepository(db *pgxpool.Pool) *CostingRepository {
+	return &CostingRepository{db: db}
+}
+
+// CreateAuditLog records a change to costing settings or costs.
+func (r *CostingRepository) CreateAuditLog(ctx context.Context, tx pgx.Tx, log *domain.AuditLog) error {
+	return createAuditLog(ctx, r.db, tx, log)
+}
+
+// GetCostingMethod returns the tenant's costing method.
+func (r *CostingRepository) GetCostingMethod(ctx context.Context, q dbtx, tenantID uuid.UUID) (domain.CostingMethod, error) {
+	var method domain.CostingMethod
+	err := q.QueryRow(ctx, `SELECT costing_method FROM tenants WHERE id = $1`, tenantID).Scan(&method)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to get costing method: %w", err)
+	}
+	return method, nil
+}
+
+// SetCostingMethod switches the tenant's method and clears every assigned cost so all
+// products are re-costed under the new method.
+func (r *CostingRepository) SetCostingMethod(ctx context.Context, tx pgx.Tx, tenantID uuid.UUID, method domain.CostingMethod) error {
+	if _, err := tx.Exec(ctx, `
+		UPDATE tenants SET costing_method = $2, updated_at = NOW() WHERE id = $1
+	`, tenantID, method); err != nil {
+		return fmt.Errorf("failed to set costing method: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `
+		UPDATE stock_movements SET assigned_cost = NULL WHERE tenant_id = $1
+	`, tenantID); err != nil {
+		return fmt.Errorf("failed to reset assigned costs: %w", err)
+	}
+	return nil
+}
+
+// ListUncostedProducts returns products that have movements the costing engine has not costed yet,
+// e.g. new movements or a back-dated purchase.
+func (r *CostingRepository) ListUncostedProducts(ctx context.Context, tenantID uuid.UUID) ([]uuid.UUID, error) {
+	return r.listProducts(ctx, `
+		SELECT DISTINCT product_id FROM stock_movements
+		WHERE tenant_id = $1 AND assigned_cost IS NULL
+	`, tenantID)
+}
+
+// ListStockedProducts returns every product that has at least one stock movement.
+func (r *CostingRepository) ListStockedProducts(ctx context.Context, tenantID uuid.UUID) ([]uuid.UUID, error) {
+	return r.listProducts(ctx, `
+		SELECT DISTINCT product_id FROM stock_movements WHERE tenant_id = $1
+	`, tenantID)
+}
+
+func (r *CostingRepository) listProducts(ctx context.Context, query string, tenantID uuid.UUID) ([]uuid.UUID, error) {
+	rows, err := r.db.Query(ctx, query, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list products for costing: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan product id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// LockProduct serialises costing runs (and stock writers) for one product.
+func (r *CostingRepository) LockProduct(ctx context.Context, tx pgx.Tx, tenantID, productID uuid.UUID) error {
+	var id uuid.UUID
+	err := tx.QueryRow(ctx, `
+		SELECT id FROM products WHERE tenant_id = $1 AND id = $2 FOR UPDATE
+	`, tenantID, productID).Scan(&id)
+	if err != nil {
+		return fmt.Errorf("failed to lock product %s: %w", productID, err)
+	}
+	return nil
+}
+
+// ListProductMovements returns all movements of a product in the order the costing engine replays them.
+func (r *CostingRepository) ListProductMovements(ctx context.Context, tx pgx.Tx, tenantID, productID uuid.UUID) ([]domain.StockMovement, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT id, tenant_id, product_id, warehouse_id, quantity, type, reference_id, reference_type, unit_cost, assigned_cost, created_at
+		FROM stock_movements
+		WHERE tenant_id = $1 AND product_id = $2
+		ORDER BY created_at, CASE WHEN quantity > 0 THEN 0 ELSE 1 END, id
+	`, tenantID, productID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list product movements: %w", err)
+	}
+	defer rows.Close()
+
+	var movements []domain.StockMovement
+	for rows.Next() {
+		var sm domain.StockMovement
+		if err := rows.Scan(
+			&sm.ID, &sm.TenantID, &sm.ProductID, &sm.WarehouseID, &sm.Quantity, &sm.Type, &sm.ReferenceID, &sm.ReferenceType, &sm.UnitCost, &sm.AssignedCost, &sm.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan stock movement: %w", err)
+		}
+		movements = append(movements, sm)
+	}
+	return movements, rows.Err()
+}
+
+// SetAssignedCosts writes the per-unit cost of each movement in one batch.
+func (r *CostingRepository) SetAssignedCosts(ctx context.Context, tx pgx.Tx, movements []domain.StockMovement) error {
+	batch := &pgx.Batch{}
+	for _, m := range movements {
+		batch.Queue(`UPDATE stock_movements SET assigned_cost = $3 WHERE tenant_id = $1 AND id = $2`, m.TenantID, m.ID, m.AssignedCost)
+	}
+	if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("failed to set assigned costs: %w", err)
+	}
+	return nil
+}
+
+// UpsertProductCost stores the result of a costing run.
+func (r *CostingRepository) UpsertProductCost(ctx context.Context, tx pgx.Tx, pc *domain.ProductCost) error {
+	err := tx.QueryRow(ctx, `
+		INSERT INTO product_costs (tenant_id, product_id, costing_method, on_hand, unit_cost, stock_value, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW())
+		ON CONFLICT (tenant_id, product_id) DO UPDATE
+		SET costing_method = EXCLUDED.costing_method,
+			on_hand = EXCLUDED.on_hand,
+			unit_cost = EXCLUDED.unit_cost,
+			stock_value = EXCLUDED.stock_value,
+			updated_at = NOW()
+		RETURNING updated_at
+	`, pc.TenantID, pc.ProductID, pc.Method, pc.OnHand, pc.UnitCost, pc.StockValue).Scan(&pc.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save product cost: %w", err)
+	}
+	return nil
+}
+
+// GetStockValuation values on-hand stock per product at the product's current unit cost,
+// optionally restricted to one warehouse.
+func (r *CostingRepository) GetStockValuation(ctx context.Context, tenantID uuid.UUID, warehouseID *uuid.UUID) ([]domain.StockValuationLine, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT p.id, p.name, p.sku, p.category_id, cat.name, p.brand_id, b.name, cs.quantity::int, COALESCE(pc.unit_cost, 0)
+		FROM current_stock cs
+		JOIN products p ON p.id = cs.product_id
+		LEFT JOIN product_categories cat ON cat.id = p.category_id
+		LEFT JOIN brands b ON b.id = p.brand_id
+		LEFT JOIN product_costs pc ON pc.tenant_id = cs.tenant_id AND pc.product_id = cs.product_id
+		WHERE cs.tenant_id = $1 AND ($2::uuid IS NULL OR cs.warehouse_id = $2) AND cs.quantity <> 0
+		ORDER BY p.name
+	`, tenantID, warehouseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stock valuation: %w", err)
+	}
+	defer rows.Close()
+
+	// current_stock is per warehouse; fold rows into one line per product
+	var lines []domain.StockValuationLine
+	index := make(map[uuid.UUID]int)
+	for rows.Next() {
+		var line domain.StockValuationLine
+		if err := rows.Scan(&line.ProductID, &line.ProductName, &line.SKU, &line.CategoryID, &line.CategoryName, &line.BrandID, &line.BrandName, &line.Quantity, &line.UnitCost); err != nil {
+			return nil, fmt.Errorf("failed to scan stock valuation: %w", err)
+		}
+		if i, ok := index[line.ProductID]; ok {
+			lines[i].Quantity += line.Quantity
+			continue
+		}
+		index[line.ProductID] = len(lines)
+		lines = append(lines, line)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	for i := range lines {
+		lines[i].Value = lines[i].UnitCost.Mul(decimal.NewFromInt(int64(lines[i].Quantity))).Round(2)
+	}
+	return lines, nil
+}
+
+// GetCostOfGoods sums the assigned cost of SALE and OUT movements per product in [from, to).
+func (r *CostingRepository) GetCostOfGoods(ctx context.Context, tenantID uuid.UUID, from, to *time.Time) ([]domain.CostOfGoodsLine, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT p.id, p.name, SUM(-sm.quantity)::int, COALESCE(SUM(-sm.quantity * sm.assigned_cost), 0)
+		FROM stock_movements sm
+		JOIN products p ON p.id = sm.product_id
+		WHERE sm.tenant_id = $1 AND sm.quantity < 0 AND sm.type IN ('SALE', 'OUT')
+			AND ($2::timestamp IS NULL OR sm.created_at >= $2)
+			AND ($3::timestamp IS NULL OR sm.created_at < $3)
+		GROUP BY p.id, p.name
+		ORDER BY p.name
+	`, tenantID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cost of goods sold: %w", err)
+	}
+	defer rows.Close()
+
+	lines := []domain.CostOfGoodsLine{}
+	for rows.Next() {
+		var line domain.CostOfGoodsLine
+		if err := rows.Scan(&line.ProductID, &line.ProductName, &line.Quantity, &line.Cost); err != nil {
+			return nil, fmt.Errorf("failed to scan cost of goods sold: %w", err)
+		}
+		line.Cost = line.Cost.Round(2)
+		lines = append(lines, line)
+	}
+	return lines, rows.Err()
+}
+
+// GetInvoiceProfit returns the invoice's lines with the average cost of the stock that shipped them:
+// SALE movements of the invoice itself, or of the delivery notes it billed. Kit lines cost the
+// average cost of their components' movements on the invoice.
+func (r *CostingRepository) GetInvoiceProfit(ctx context.Context, tenantID, invoiceID uuid.UUID) (*domain.InvoiceProfit, error) {
+	profit := &domain.InvoiceProfit{InvoiceID: invoiceID}
+	err := r.db.QueryRow(ctx, `
+		SELECT invoice_number FROM invoices WHERE tenant_id = $1 AND id = $2 AND deleted_at IS NULL
+	`, tenantID, invoiceID).Scan(&profit.InvoiceNumber)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get invoice: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT ii.id, ii.product_id, p.name, ii.quantity, `+netLineRevenue+`, COALESCE(costs.unit_cost, kit_costs.unit_cost, 0)
+		FROM invoice_items ii
+		JOIN products p ON p.id = ii.product_id
+		LEFT JOIN LATERAL (
+			SELECT SUM(-sm.quantity * sm.assigned_cost) / NULLIF(SUM(-sm.quantity), 0) AS unit_cost
+			FROM stock_movements sm
+			WHERE sm.tenant_id = ii.tenant_id AND sm.product_id = ii.product_id AND sm.quantity < 0
+				AND (
+					(sm.reference_type = 'INVOICE' AND sm.reference_id = ii.invoice_id)
+					OR (sm.reference_type = 'DELIVERY_NOTE' AND sm.reference_id IN (
+						SELECT dn.id FROM delivery_notes dn WHERE dn.tenant_id = ii.tenant_id AND dn.invoice_id = ii.invoice_id
+					))
+				)
+		) costs ON TRUE
+		LEFT JOIN LATERAL (
+			SELECT SUM(iic.quantity * (
+				SELECT SUM(-sm.quantity * sm.assigned_cost) / NULLIF(SUM(-sm.quantity), 0)
+				FROM stock_movements sm
+				WHERE sm.tenant_id = iic.tenant_id AND sm.product_id = iic.product_id AND sm.quantity < 0
+					AND sm.reference_type = 'INVOICE' AND sm.reference_id = ii.invoice_id
+			)) / ii.quantity AS unit_cost
+			FROM invoice_item_components iic
+			WHERE iic.tenant_id = ii.tenant_id AND iic.invoice_item_id = ii.id
+		) kit_costs ON TRUE
+		WHERE ii.tenant_id = $1 AND ii.invoice_id = $2
+		ORDER BY ii.created_at, ii.id
+	`, tenantID, invoiceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get invoice line costs: %w", err)
+	}
+	defer rows.Close()
+
+	profit.Lines = []domain.InvoiceLineProfit{}
+	for rows.Next() {
+		var line domain.InvoiceLineProfit
+		if err := rows.Scan(&line.ItemID, &line.ProductID, &line.ProductName, &line.Quantity, &line.Revenue, &line.UnitCost); err != nil {
+			return nil, fmt.Errorf("failed to scan invoice line cost: %w", err)
+		}
+		profit.Lines = append(profit.Lines, line)
+	}
+	return profit, rows.Err()
+}
@@ -4,10 +4,12 @@ import (
 	"context"
 	"fmt"
 	"sancaksoft/internal/domain"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shopspring/decimal"
 )
 
 type CustomerRepository struct {
@@ -18,35 +20,56 @@ func NewCustomerRepository(db *pgxpool.Pool) *CustomerRepository {
 	return &CustomerRepository{db: db}
 }
 
+// CreateAuditLog records a change to a customer or its payments.
+func (r *CustomerRepository) CreateAuditLog(ctx context.Context, tx pgx.Tx, log *domain.AuditLog) error {
+	return createAuditLog(ctx, r.db, tx, log)
+}
+
 // CreateCustomer inserts a new customer.
-func (r *CustomerRepository) CreateCustomer(ctx context.Context, c *domain.Customer) error {
+func (r *CustomerRepository) CreateCustomer(ctx context.Context, tx pgx.Tx, c *domain.Customer) error {
 	query := `
-		INSERT INTO customers (id, tenant_id, name, email, phone, address, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, NOW(), NOW())
+		INSERT INTO customers (id, tenant_id, name, email, phone, address, district, city, tax_number, tax_office, credit_limit, payment_term_days, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''), NULLIF($8, ''), NULLIF($9, ''), NULLIF($10, ''), $11, $12, NOW(), NOW())
 		RETURNING created_at, updated_at
 	`
-	return r.db.QueryRow(ctx, query,
+	return tx.QueryRow(ctx, query,
 		c.ID,
 		c.TenantID,
 		c.Name,
 		c.Email,
 		c.Phone,
 		c.Address,
+		c.District,
+		c.City,
+		c.TaxNumber,
+		c.TaxOffice,
+		c.CreditLimit,
+		c.PaymentTermDays,
 	).Scan(&c.CreatedAt, &c.UpdatedAt)
 }
 
+const customerByIDQuery = `
+	SELECT id, tenant_id, name, email, phone, address, COALESCE(district, ''), COALESCE(city, ''), COALESCE(tax_number, ''), COALESCE(tax_office, ''),
+		credit_limit, payment_term_days, created_at, updated_at
+	FROM customers
+	WHERE id = $1 AND tenant_id = $2
+`
+
 // GetCustomerByID retrieves a customer by ID and TenantID.
 func (r *CustomerRepository) GetCustomerByID(ctx context.Context, tenantID, customerID uuid.UUID) (*domain.Customer, error) {
-	query := `
-		SELECT id, tenant_id, name, email, phone, address, created_at, updated_at
-		FROM customers
-		WHERE id = $1 AND tenant_id = $2
-	`
-	row := r.db.QueryRow(ctx, query, customerID, tenantID)
+	return scanCustomer(r.db.QueryRow(ctx, customerByIDQuery, customerID, tenantID))
+}
+
+// LockCustomer locks a customer row for an update in tx and returns it; nil if it does
+// not exist.
+func (r *CustomerRepository) LockCustomer(ctx context.Context, tx pgx.Tx, tenantID, customerID uuid.UUID) (*domain.Customer, error) {
+	return scanCustomer(tx.QueryRow(ctx, customerByIDQuery+" FOR UPDATE", customerID, tenantID))
+}
 
+func scanCustomer(row pgx.Row) (*domain.Customer, error) {
 	var c domain.Customer
 	err := row.Scan(
-		&c.ID, &c.TenantID, &c.Name, &c.Email, &c.Phone, &c.Address, &c.CreatedAt, &c.UpdatedAt,
+		&c.ID, &c.TenantID, &c.Name, &c.Email, &c.Phone, &c.Address, &c.District, &c.City, &c.TaxNumber, &c.TaxOffice, &c.CreditLimit, &c.PaymentTermDays, &c.CreatedAt, &c.UpdatedAt,
 	)
 	if err != nil {
 		if err == pgx.ErrNoRows {
@@ -60,7 +83,8 @@ func (r *CustomerRepository) GetCustomerByID(ctx context.Context, tenantID, cust
 // ListCustomers retrieves a list of customers for a tenant.
 func (r *CustomerRepository) ListCustomers(ctx context.Context, tenantID uuid.UUID) ([]domain.Customer, error) {
 	query := `
-		SELECT id, tenant_id, name, email, phone, address, created_at, updated_at
+		SELECT id, tenant_id, name, email, phone, address, COALESCE(district, ''), COALESCE(city, ''), COALESCE(tax_number, ''), COALESCE(tax_office, ''),
+			credit_limit, payment_term_days, created_at, updated_at
 		FROM customers
 		WHERE tenant_id = $1
 		ORDER BY created_at DESC
@@ -76,7 +100,7 @@ func (r *CustomerRepository) ListCustomers(ctx context.Context, tenantID uuid.UU
 	for rows.Next() {
 		var c domain.Customer
 		if err := rows.Scan(
-			&c.ID, &c.TenantID, &c.Name, &c.Email, &c.Phone, &c.Address, &c.CreatedAt, &c.UpdatedAt,
+			&c.ID, &c.TenantID, &c.Name, &c.Email, &c.Phone, &c.Address, &c.District, &c.City, &c.TaxNumber, &c.TaxOffice, &c.CreditLimit, &c.PaymentTermDays, &c.CreatedAt, &c.UpdatedAt,
 		); err != nil {
 			return nil, fmt.Errorf("failed to scan customer: %w", err)
 		}
@@ -85,26 +109,138 @@ func (r *CustomerRepository) ListCustomers(ctx context.Context, tenantID uuid.UU
 	return customers, nil
 }
 
+// UpdateCreditTerms sets a customer's credit limit (nil for none) and payment term.
+func (r *CustomerRepository) UpdateCreditTerms(ctx context.Context, tx pgx.Tx, c *domain.Customer) error {
+	err := tx.QueryRow(ctx, `
+		UPDATE customers SET credit_limit = $3, payment_term_days = $4, updated_at = NOW()
+		WHERE tenant_id = $1 AND id = $2
+		RETURNING updated_at
+	`, c.TenantID, c.ID, c.CreditLimit, c.PaymentTermDays).Scan(&c.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update customer credit terms: %w", err)
+	}
+	return nil
+}
+
+// GetCustomerCredit returns the customer's credit position as of a date; nil if the
+// customer does not exist.
+func (r *CustomerRepository) GetCustomerCredit(ctx context.Context, tenantID, customerID uuid.UUID, asOf time.Time) (*domain.CustomerCredit, error) {
+	return getCustomerCredit(ctx, r.db, tenantID, customerID, asOf)
+}
+
+// CreateCustomerPayment records a payment received from a customer.
+func (r *CustomerRepository) CreateCustomerPayment(ctx context.Context, tx pgx.Tx, p *domain.CustomerPayment) error {
+	err := tx.QueryRow(ctx, `
+		INSERT INTO customer_payments (id, tenant_id, customer_id, amount, payment_date, method, reference, note, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''), NULLIF($8, ''), $9)
+		RETURNING created_at
+	`, p.ID, p.TenantID, p.CustomerID, p.Amount, p.PaymentDate, p.Method, p.Reference, p.Note, p.CreatedBy).Scan(&p.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create customer payment: %w", err)
+	}
+	return nil
+}
+
+// ListCustomerPayments returns a customer's payments, latest first.
+func (r *CustomerRepository) ListCustomerPayments(ctx context.Context, tenantID, customerID uuid.UUID) ([]domain.CustomerPayment, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT id, tenant_id, customer_id, amount, payment_date, method, COALESCE(reference, ''), COALESCE(note, ''), created_by, created_at
+		FROM customer_payments
+		WHERE tenant_id = $1 AND customer_id = $2
+		ORDER BY payment_date DESC, created_at DESC
+		LIMIT 100
+	`, tenantID, customerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list customer payments: %w", err)
+	}
+	defer rows.Close()
+
+	payments := []domain.CustomerPayment{}
+	for rows.Next() {
+		var p domain.CustomerPayment
+		if err := rows.Scan(&p.ID, &p.TenantID, &p.CustomerID, &p.Amount, &p.PaymentDate, &p.Method, &p.Reference, &p.Note, &p.CreatedBy, &p.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan customer payment: %w", err)
+		}
+		payments = append(payments, p)
+	}
+	return payments, rows.Err()
+}
+
+// customerCreditQuery returns a customer's credit terms, open balance and the part of it
+// overdue as of $3. Payments and received returns are not tied to invoices, so they settle
+// the invoices oldest due first; whatever is left of an invoice due before $3 is overdue.
+const customerCreditQuery = `
+	WITH credits AS (
+		SELECT COALESCE((SELECT SUM(amount) FROM customer_payments
+			WHERE tenant_id = $1 AND customer_id = $2 AND payment_date <= $3::date), 0)
+		     + COALESCE((SELECT SUM(cr.total) FROM customer_returns cr
+			WHERE cr.tenant_id = $1 AND cr.customer_id = $2 AND ` + creditedReturn + `
+				AND ` + returnCreditDate + `::date <= $3::date), 0) AS amount
+	), invoiced AS (
+		SELECT due_date, total_amount,
+		       SUM(total_amount) OVER (ORDER BY due_date, created_at, id) AS running
+		FROM invoices
+		WHERE tenant_id = $1 AND customer_id = $2 AND deleted_at IS NULL AND created_at::date <= $3::date
+	)
+	SELECT cu.credit_limit, cu.payment_term_days,
+	       COALESCE(SUM(i.total_amount), 0) - c.amount,
+	       COALESCE(SUM(LEAST(i.total_amount, GREATEST(i.running - c.amount, 0))) FILTER (WHERE i.due_date < $3::date), 0)
+	FROM customers cu
+	CROSS JOIN credits c
+	LEFT JOIN invoiced i ON TRUE
+	WHERE cu.tenant_id = $1 AND cu.id = $2
+	GROUP BY cu.id, c.amount
+`
+
+func getCustomerCredit(ctx context.Context, q dbtx, tenantID, customerID uuid.UUID, asOf time.Time) (*domain.CustomerCredit, error) {
+	credit := domain.CustomerCredit{CustomerID: customerID, AsOf: asOf}
+	err := q.QueryRow(ctx, customerCreditQuery, tenantID, customerID, asOf).Scan(
+		&credit.CreditLimit, &credit.PaymentTermDays, &credit.OpenBalance, &credit.OverdueAmount,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get customer credit: %w", err)
+	}
+	if credit.CreditLimit != nil {
+		available := credit.CreditLimit.Sub(credit.OpenBalance)
+		credit.Available = &available
+	}
+	return &credit, nil
+}
+
 func (r *CustomerRepository) ensureCustomerReturnsTable(ctx context.Context) error {
-	query := `
-		CREATE TABLE IF NOT EXISTS customer_returns (
-			id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
-			tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
-			customer_id UUID NOT NULL REFERENCES customers(id) ON DELETE RESTRICT,
-			product_id UUID NOT NULL REFERENCES products(id) ON DELETE RESTRICT,
-			warehouse_id UUID NOT NULL REFERENCES warehouses(id) ON DELETE RESTRICT,
-			quantity INTEGER NOT NULL CHECK (quantity > 0),
-			unit_price DECIMAL(10, 2) NOT NULL CHECK (unit_price >= 0),
-			total DECIMAL(15, 2) NOT NULL CHECK (total >= 0),
-			reason TEXT,
-			created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
-		)
-	`
-	_, err := r.db.Exec(ctx, query)
+	_, err := r.db.Exec(ctx, customerReturnsDDL)
 	return err
 }
 
+// customerMovements lists a customer's invoices (SALE), received returns (RETURN) and payments
+// (PAYMENT) with the amount each moved the balance by; $1 is the tenant, $2 the customer
+// or NULL for all customers. The ledger, the account statement and the ledger export are
+// read from it.
+const customerMovements = `(
+		SELECT i.customer_id, i.created_at AS occurred_at, 'SALE' AS movement_type, i.id AS document_id,
+			i.invoice_number AS document_number, '' AS description, i.due_date, i.total_amount AS amount
+		FROM invoices i
+		WHERE i.tenant_id = $1 AND ($2::uuid IS NULL OR i.customer_id = $2) AND i.deleted_at IS NULL
+
+		UNION ALL
+
+		SELECT cr.customer_id, ` + returnCreditDate + `, 'RETURN', cr.id, '', COALESCE(p.name, '') || ' x ' || cr.quantity, NULL::date, cr.total
+		FROM customer_returns cr
+		LEFT JOIN products p ON p.id = cr.product_id
+		WHERE cr.tenant_id = $1 AND ($2::uuid IS NULL OR cr.customer_id = $2) AND ` + creditedReturn + `
+
+		UNION ALL
+
+		SELECT cp.customer_id, cp.payment_date::timestamp, 'PAYMENT', cp.id, COALESCE(cp.reference, ''), cp.method, NULL::date, cp.amount
+		FROM customer_payments cp
+		WHERE cp.tenant_id = $1 AND ($2::uuid IS NULL OR cp.customer_id = $2)
+	) movements`
+
 // ListCustomerLedger returns aggregated customer movements by period: day|week|month.
+// Net amount is the balance change of the period: sales less returns and payments.
 func (r *CustomerRepository) ListCustomerLedger(ctx context.Context, tenantID, customerID uuid.UUID, period string) ([]domain.CustomerLedgerEntry, error) {
 	if err := r.ensureCustomerReturnsTable(ctx); err != nil {
 		return nil, fmt.Errorf("failed to ensure returns table: %w", err)
@@ -112,29 +248,14 @@ func (r *CustomerRepository) ListCustomerLedger(ctx context.Context, tenantID, c
 
 	query := `
 		SELECT 
-			bucket AS period_start,
+			date_trunc($3, occurred_at) AS period_start,
 			COALESCE(SUM(CASE WHEN movement_type = 'SALE' THEN amount END), 0) AS sales_amount,
 			COALESCE(SUM(CASE WHEN movement_type = 'RETURN' THEN amount END), 0) AS return_amount,
+			COALESCE(SUM(CASE WHEN movement_type = 'PAYMENT' THEN amount END), 0) AS payment_amount,
 			COALESCE(SUM(CASE WHEN movement_type = 'SALE' THEN amount ELSE -amount END), 0) AS net_amount
-		FROM (
-			SELECT 
-				date_trunc($3, i.created_at) AS bucket,
-				i.total_amount AS amount,
-				'SALE' AS movement_type
-			FROM invoices i
-			WHERE i.tenant_id = $1 AND i.customer_id = $2 AND i.deleted_at IS NULL
-
-			UNION ALL
-
-			SELECT
-				date_trunc($3, cr.created_at) AS bucket,
-				cr.total AS amount,
-				'RETURN' AS movement_type
-			FROM customer_returns cr
-			WHERE cr.tenant_id = $1 AND cr.customer_id = $2
-		) movements
-		GROUP BY bucket
-		ORDER BY bucket DESC
+		FROM ` + customerMovements + `
+		GROUP BY 1
+		ORDER BY 1 DESC
 		LIMIT 100
 	`
 
@@ -147,10 +268,66 @@ func (r *CustomerRepository) ListCustomerLedger(ctx context.Context, tenantID, c
 	var entries []domain.CustomerLedgerEntry
 	for rows.Next() {
 		var entry domain.CustomerLedgerEntry
-		if err := rows.Scan(&entry.PeriodStart, &entry.SalesAmount, &entry.ReturnAmount, &entry.NetAmount); err != nil {
+		if err := rows.Scan(&entry.PeriodStart, &entry.SalesAmount, &entry.ReturnAmount, &entry.PaymentAmount, &entry.NetAmount); err != nil {
 			return nil, fmt.Errorf("failed to scan customer ledger entry: %w", err)
 		}
 		entries = append(entries, entry)
 	}
 	return entries, nil
 }
+
+// GetBalanceBefore returns the customer's balance from all movements before a time.
+func (r *CustomerRepository) GetBalanceBefore(ctx context.Context, tenantID, customerID uuid.UUID, before time.Time) (decimal.Decimal, error) {
+	if err := r.ensureCustomerReturnsTable(ctx); err != nil {
+		return decimal.Zero, fmt.Errorf("failed to ensure returns table: %w", err)
+	}
+
+	var balance decimal.Decimal
+	err := r.db.QueryRow(ctx, `
+		SELECT COALESCE(SUM(CASE WHEN movement_type = 'SALE' THEN amount ELSE -amount END), 0)
+		FROM `+customerMovements+`
+		WHERE occurred_at < $3
+	`, tenantID, customerID, before).Scan(&balance)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("failed to get customer balance: %w", err)
+	}
+	return balance, nil
+}
+
+// ListStatementLines returns the customer's movements in [from, to) in the order they
+// happened; nil bounds are open. Balance is left to the caller.
+func (r *CustomerRepository) ListStatementLines(ctx context.Context, tenantID, customerID uuid.UUID, from, to *time.Time) ([]domain.StatementLine, error) {
+	if err := r.ensureCustomerReturnsTable(ctx); err != nil {
+		return nil, fmt.Errorf("failed to ensure returns table: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT occurred_at, movement_type, document_id, document_number, description, due_date,
+			CASE WHEN movement_type = 'SALE' THEN amount ELSE 0 END,
+			CASE WHEN movement_type = 'SALE' THEN 0 ELSE amount END
+		FROM `+customerMovements+`
+		WHERE ($3::timestamp IS NULL OR occurred_at >= $3)
+			AND ($4::timestamp IS NULL OR occurred_at < $4)
+		ORDER BY occurred_at, movement_type DESC, document_number
+	`, tenantID, customerID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list statement lines: %w", err)
+	}
+	defer rows.Close()
+
+	lines := []domain.StatementLine{}
+	for rows.Next() {
+		var line domain.StatementLine
+		if err := rows.Scan(&line.Date, &line.Type, &line.DocumentID, &line.DocumentNumber, &line.Description, &line.DueDate,
+			&line.Debit, &line.Credit); err != nil {
+			return nil, fmt.Errorf("failed to scan statement line: %w", err)
+		}
+		lines = append(lines, line)
+	}
+	return lines, rows.Err()
+}
+
+// GetTenantProfile returns the tenant's company details for document headers.
+func (r *CustomerRepository) GetTenantProfile(ctx context.Context, tenantID uuid.UUID) (*domain.TenantProfile, error) {
+	return getTenantProfile(ctx, r.db, tenantID)
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shopspring/decimal"
 )
 
 type DashboardRepository struct {
@@ -33,6 +34,22 @@ func (r *DashboardRepository) GetStats(ctx context.Context, tenantID uuid.UUID)
 		return nil, fmt.Errorf("failed to get invoice stats: %w", err)
 	}
 
+	// 1b. Net revenue, cost of sales & gross profit
+	err = r.db.QueryRow(ctx, `
+		SELECT COALESCE(SUM(`+netLineRevenue+`), 0), COALESCE(SUM(ii.quantity * ii.unit_cost), 0)
+		FROM invoice_items ii
+		JOIN invoices i ON i.id = ii.invoice_id AND i.tenant_id = ii.tenant_id
+		WHERE ii.tenant_id = $1 AND i.deleted_at IS NULL
+	`, tenantID).Scan(&stats.NetRevenue, &stats.TotalCost)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cost stats: %w", err)
+	}
+	stats.TotalCost = stats.TotalCost.Round(2)
+	stats.GrossProfit = stats.NetRevenue.Sub(stats.TotalCost)
+	if !stats.NetRevenue.IsZero() {
+		stats.GrossMarginPct = stats.GrossProfit.Div(stats.NetRevenue).Mul(decimal.NewFromInt(100)).Round(2)
+	}
+
 	// 2. Total Products
 	err = r.db.QueryRow(ctx, `
 		SELECT COUNT(*) FROM products WHERE tenant_id = $1 AND deleted_at IS NULL
@@ -41,19 +58,12 @@ func (r *DashboardRepository) GetStats(ctx context.Context, tenantID uuid.UUID)
 		return nil, fmt.Errorf("failed to get product stats: %w", err)
 	}
 
-	// 3. Low Stock Count (Example: Stock < 10)
-	// Note: accurate stock requires summing stock_movements group by product.
-	// For MVP performance, we might do a simpler query or rely on a materialized view later.
-	// Here is a slightly expensive aggregation query for real-time accuracy.
+	// 3. Low Stock Count: configured stock levels whose available quantity
+	// (on hand minus reservations) reached the reorder level.
 	err = r.db.QueryRow(ctx, `
-		SELECT COUNT(*) FROM (
-			SELECT product_id, SUM(quantity) as balance
-			FROM stock_movements
-			WHERE tenant_id = $1
-			GROUP BY product_id
-			HAVING SUM(quantity) < 10
-		) as low_stock
-	`, tenantID).Scan(&stats.LowStockCount)
+		SELECT COUNT(*) FROM (`+stockLevelStatusQuery+`) AS levels
+		WHERE levels.on_hand - levels.reserved <= levels.reorder_level
+	`, tenantID, nil, 0).Scan(&stats.LowStockCount)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get low stock stats: %w", err)
 	}
@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// dbtx is satisfied by both *pgxpool.Pool and pgx.Tx, so read helpers can be shared
+// between plain queries and queries running inside a service transaction.
+type dbtx interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// nextDocumentNumber atomically increments the tenant's counter for a document type
+// and formats it as PREFIX-YEAR-00001.
+func nextDocumentNumber(ctx context.Context, tx pgx.Tx, tenantID uuid.UUID, documentType, prefix string) (string, error) {
+	lastNumber, err := nextSequence(ctx, tx, tenantID, documentType)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s-%d-%05d", prefix, time.Now().Year(), lastNumber), nil
+}
+
+// nextSequence atomically increments and returns the tenant's counter for a document type.
+func nextSequence(ctx context.Context, tx pgx.Tx, tenantID uuid.UUID, documentType string) (int, error) {
+	var lastNumber int
+	err := tx.QueryRow(ctx, `
+		INSERT INTO document_sequences (tenant_id, document_type, last_number)
+		VALUES ($1, $2, 1)
+		ON CONFLICT (tenant_id, document_type) DO UPDATE
+		SET last_number = document_sequences.last_number + 1
+		RETURNING last_number
+	`, tenantID, documentType).Scan(&lastNumber)
+	if err != nil {
+		return 0, fmt.Errorf("failed to generate %s number: %w", documentType, err)
+	}
+	return lastNumber, nil
+}
@@ -0,0 +1,238 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"sancaksoft/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const deliveryNoteColumns = `
+	id, tenant_id, customer_id, warehouse_id, note_number, status, COALESCE(note, ''),
+	total_amount, invoice_id, shipped_at, created_at, updated_at
+`
+
+type DeliveryNoteRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewDeliveryNoteRepository(db *pgxpool.Pool) *DeliveryNoteRepository {
+	return &DeliveryNoteRepository{db: db}
+}
+
+// CreateAuditLog records a change to a delivery note.
+func (r *DeliveryNoteRepository) CreateAuditLog(ctx context.Context, tx pgx.Tx, log *domain.AuditLog) error {
+	return createAuditLog(ctx, r.db, tx, log)
+}
+
+func scanDeliveryNote(row pgx.Row, n *domain.DeliveryNote) error {
+	return row.Scan(
+		&n.ID, &n.TenantID, &n.CustomerID, &n.WarehouseID, &n.NoteNumber, &n.Status, &n.Note,
+		&n.TotalAmount, &n.InvoiceID, &n.ShippedAt, &n.CreatedAt, &n.UpdatedAt,
+	)
+}
+
+// GenerateNextNoteNumber generates the next sequential delivery note number atomically.
+func (r *DeliveryNoteRepository) GenerateNextNoteNumber(ctx context.Context, tx pgx.Tx, tenantID uuid.UUID) (string, error) {
+	return nextDocumentNumber(ctx, tx, tenantID, "DELIVERY_NOTE", "IRS")
+}
+
+// CreateDeliveryNote inserts the delivery note header.
+func (r *DeliveryNoteRepository) CreateDeliveryNote(ctx context.Context, tx pgx.Tx, n *domain.DeliveryNote) error {
+	query := `
+		INSERT INTO delivery_notes (id, tenant_id, customer_id, warehouse_id, note_number, status, note, total_amount, shipped_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW(), NOW(), NOW())
+		RETURNING shipped_at, created_at, updated_at
+	`
+	return tx.QueryRow(ctx, query,
+		n.ID,
+		n.TenantID,
+		n.CustomerID,
+		n.WarehouseID,
+		n.NoteNumber,
+		n.Status,
+		n.Note,
+		n.TotalAmount,
+	).Scan(&n.ShippedAt, &n.CreatedAt, &n.UpdatedAt)
+}
+
+// CreateDeliveryNoteItem inserts a delivery note line.
+func (r *DeliveryNoteRepository) CreateDeliveryNoteItem(ctx context.Context, tx pgx.Tx, item *domain.DeliveryNoteItem) error {
+	query := `
+		INSERT INTO delivery_note_items (id, tenant_id, delivery_note_id, product_id, quantity, unit_price, total, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW())
+		RETURNING created_at
+	`
+	return tx.QueryRow(ctx, query,
+		item.ID,
+		item.TenantID,
+		item.DeliveryNoteID,
+		item.ProductID,
+		item.Quantity,
+		item.UnitPrice,
+		item.Total,
+	).Scan(&item.CreatedAt)
+}
+
+// GetDeliveryNote returns the note with its lines, or nil if not found.
+func (r *DeliveryNoteRepository) GetDeliveryNote(ctx context.Context, tenantID, noteID uuid.UUID) (*domain.DeliveryNote, error) {
+	return r.getDeliveryNote(ctx, r.db, tenantID, noteID, false)
+}
+
+// GetDeliveryNoteForUpdate returns the note with its lines and locks the header row.
+func (r *DeliveryNoteRepository) GetDeliveryNoteForUpdate(ctx context.Context, tx pgx.Tx, tenantID, noteID uuid.UUID) (*domain.DeliveryNote, error) {
+	return r.getDeliveryNote(ctx, tx, tenantID, noteID, true)
+}
+
+func (r *DeliveryNoteRepository) getDeliveryNote(ctx context.Context, q dbtx, tenantID, noteID uuid.UUID, forUpdate bool) (*domain.DeliveryNote, error) {
+	query := `SELECT ` + deliveryNoteColumns + `
+		FROM delivery_notes
+		WHERE tenant_id = $1 AND id = $2 AND deleted_at IS NULL
+	`
+	if forUpdate {
+		query += ` FOR UPDATE`
+	}
+
+	var n domain.DeliveryNote
+	if err := scanDeliveryNote(q.QueryRow(ctx, query, tenantID, noteID), &n); err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get delivery note: %w", err)
+	}
+
+	rows, err := q.Query(ctx, `
+		SELECT id, tenant_id, delivery_note_id, product_id, quantity, unit_price, total, created_at
+		FROM delivery_note_items
+		WHERE tenant_id = $1 AND delivery_note_id = $2
+		ORDER BY created_at, id
+	`, tenantID, noteID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get delivery note items: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var it domain.DeliveryNoteItem
+		if err := rows.Scan(
+			&it.ID, &it.TenantID, &it.DeliveryNoteID, &it.ProductID, &it.Quantity, &it.UnitPrice, &it.Total, &it.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan delivery note item: %w", err)
+		}
+		n.Items = append(n.Items, it)
+	}
+	return &n, rows.Err()
+}
+
+// UpdateDeliveryNoteStatus persists status and the billing invoice link.
+func (r *DeliveryNoteRepository) UpdateDeliveryNoteStatus(ctx context.Context, tx pgx.Tx, n *domain.DeliveryNote) error {
+	query := `
+		UPDATE delivery_notes
+		SET status = $3, invoice_id = $4, updated_at = NOW()
+		WHERE tenant_id = $1 AND id = $2
+		RETURNING updated_at
+	`
+	return tx.QueryRow(ctx, query, n.TenantID, n.ID, n.Status, n.InvoiceID).Scan(&n.UpdatedAt)
+}
+
+// ListDeliveryNotes lists note headers, optionally filtered by customer and status.
+func (r *DeliveryNoteRepository) ListDeliveryNotes(ctx context.Context, tenantID uuid.UUID, customerID *uuid.UUID, status domain.DeliveryNoteStatus) ([]domain.DeliveryNote, error) {
+	query := `SELECT ` + deliveryNoteColumns + `
+		FROM delivery_notes
+		WHERE tenant_id = $1 AND deleted_at IS NULL
+		  AND ($2::uuid IS NULL OR customer_id = $2)
+		  AND ($3 = '' OR status = $3)
+		ORDER BY shipped_at DESC
+		LIMIT 100
+	`
+	rows, err := r.db.Query(ctx, query, tenantID, customerID, string(status))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list delivery notes: %w", err)
+	}
+	defer rows.Close()
+
+	notes := []domain.DeliveryNote{}
+	for rows.Next() {
+		var n domain.DeliveryNote
+		if err := scanDeliveryNote(rows, &n); err != nil {
+			return nil, fmt.Errorf("failed to scan delivery note: %w", err)
+		}
+		notes = append(notes, n)
+	}
+	return notes, rows.Err()
+}
+
+// ListNoteMovements returns the stock movements written for a delivery note with their
+// serial numbers, so a cancellation can put each lot and unit back where it came from.
+func (r *DeliveryNoteRepository) ListNoteMovements(ctx context.Context, tx pgx.Tx, tenantID, noteID uuid.UUID) ([]domain.StockMovement, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT sm.id, sm.tenant_id, sm.product_id, sm.warehouse_id, sm.quantity, sm.type, sm.reference_id, sm.reference_type, sm.lot_id, sm.location_id,
+		       ARRAY(
+		           SELECT sn.serial_number FROM stock_movement_serials sms
+		           JOIN serial_numbers sn ON sn.id = sms.serial_id
+		           WHERE sms.movement_id = sm.id ORDER BY sn.serial_number
+		       ),
+		       sm.created_at
+		FROM stock_movements sm
+		WHERE sm.tenant_id = $1 AND sm.reference_type = 'DELIVERY_NOTE' AND sm.reference_id = $2
+		ORDER BY sm.created_at, sm.id
+	`, tenantID, noteID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list delivery note movements: %w", err)
+	}
+	defer rows.Close()
+
+	var movements []domain.StockMovement
+	for rows.Next() {
+		var m domain.StockMovement
+		if err := rows.Scan(&m.ID, &m.TenantID, &m.ProductID, &m.WarehouseID, &m.Quantity, &m.Type,
+			&m.ReferenceID, &m.ReferenceType, &m.LotID, &m.LocationID, &m.SerialNumbers, &m.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan delivery note movement: %w", err)
+		}
+		movements = append(movements, m)
+	}
+	return movements, rows.Err()
+}
+
+// ListUninvoicedLines returns every line of SHIPPED notes, oldest first, optionally
+// filtered by customer and shipping date range (to is exclusive).
+func (r *DeliveryNoteRepository) ListUninvoicedLines(ctx context.Context, tenantID uuid.UUID, customerID *uuid.UUID, from, to *time.Time) ([]domain.UninvoicedDeliveryLine, error) {
+	query := `
+		SELECT dn.id, dn.note_number, dn.shipped_at, dn.customer_id, COALESCE(c.name, ''), dn.warehouse_id,
+		       dni.product_id, COALESCE(p.name, ''), dni.quantity, dni.unit_price, dni.total
+		FROM delivery_notes dn
+		JOIN delivery_note_items dni ON dni.delivery_note_id = dn.id AND dni.tenant_id = dn.tenant_id
+		LEFT JOIN customers c ON c.id = dn.customer_id AND c.tenant_id = dn.tenant_id
+		LEFT JOIN products p ON p.id = dni.product_id AND p.tenant_id = dn.tenant_id
+		WHERE dn.tenant_id = $1
+		  AND dn.status = 'SHIPPED'
+		  AND dn.deleted_at IS NULL
+		  AND ($2::uuid IS NULL OR dn.customer_id = $2)
+		  AND ($3::timestamp IS NULL OR dn.shipped_at >= $3)
+		  AND ($4::timestamp IS NULL OR dn.shipped_at < $4)
+		ORDER BY dn.shipped_at, dn.note_number, dni.created_at
+	`
+	rows, err := r.db.Query(ctx, query, tenantID, customerID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list uninvoiced delivery lines: %w", err)
+	}
+	defer rows.Close()
+
+	lines := []domain.UninvoicedDeliveryLine{}
+	for rows.Next() {
+		var l domain.UninvoicedDeliveryLine
+		if err := rows.Scan(
+			&l.DeliveryNoteID, &l.NoteNumber, &l.ShippedAt, &l.CustomerID, &l.CustomerName, &l.WarehouseID,
+			&l.ProductID, &l.ProductName, &l.Quantity, &l.UnitPrice, &l.Total,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan uninvoiced delivery line: %w", err)
+		}
+		lines = append(lines, l)
+	}
+	return lines, rows.Err()
+}
@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"sancaksoft/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const eInvoiceColumns = `
+	id, tenant_id, invoice_id, ettn, profile, document_number, xml, status,
+	COALESCE(provider_reference, ''), COALESCE(status_message, ''), sent_at, created_at, updated_at
+`
+
+type EInvoiceRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewEInvoiceRepository(db *pgxpool.Pool) *EInvoiceRepository {
+	return &EInvoiceRepository{db: db}
+}
+
+// CreateAuditLog records a change to an e-invoice.
+func (r *EInvoiceRepository) CreateAuditLog(ctx context.Context, tx pgx.Tx, log *domain.AuditLog) error {
+	return createAuditLog(ctx, r.db, tx, log)
+}
+
+// NextDocumentSequence returns the next number of a document series in a year; GİB
+// numbers restart every year.
+func (r *EInvoiceRepository) NextDocumentSequence(ctx context.Context, tx pgx.Tx, tenantID uuid.UUID, series string, year int) (int, error) {
+	return nextSequence(ctx, tx, tenantID, fmt.Sprintf("EINVOICE_%s_%d", series, year))
+}
+
+// GetInvoiceDocument reads an invoice document inside the transaction.
+func (r *EInvoiceRepository) GetInvoiceDocument(ctx context.Context, tx pgx.Tx, tenantID, invoiceID uuid.UUID) (*domain.InvoiceDocument, error) {
+	return getInvoiceDocument(ctx, tx, tenantID, invoiceID)
+}
+
+// GetEInvoice returns the e-invoice of an invoice, or nil if none was generated.
+func (r *EInvoiceRepository) GetEInvoice(ctx context.Context, tenantID, invoiceID uuid.UUID) (*domain.EInvoice, error) {
+	return getEInvoice(ctx, r.db, "invoice_id", tenantID, invoiceID, false)
+}
+
+// GetEInvoiceForUpdate returns the e-invoice of an invoice and locks it.
+func (r *EInvoiceRepository) GetEInvoiceForUpdate(ctx context.Context, tx pgx.Tx, tenantID, invoiceID uuid.UUID) (*domain.EInvoice, error) {
+	return getEInvoice(ctx, tx, "invoice_id", tenantID, invoiceID, true)
+}
+
+// GetEInvoiceByETTNForUpdate returns the e-invoice with the given ETTN and locks it.
+func (r *EInvoiceRepository) GetEInvoiceByETTNForUpdate(ctx context.Context, tx pgx.Tx, tenantID, ettn uuid.UUID) (*domain.EInvoice, error) {
+	return getEInvoice(ctx, tx, "ettn", tenantID, ettn, true)
+}
+
+// getEInvoice looks the e-invoice up by invoice_id or ettn.
+func getEInvoice(ctx context.Context, q dbtx, column string, tenantID, id uuid.UUID, forUpdate bool) (*domain.EInvoice, error) {
+	query := `SELECT ` + eInvoiceColumns + `
+		FROM e_invoices
+		WHERE tenant_id = $1 AND ` + column + ` = $2
+	`
+	if forUpdate {
+		query += ` FOR UPDATE`
+	}
+
+	var e domain.EInvoice
+	err := q.QueryRow(ctx, query, tenantID, id).Scan(
+		&e.ID, &e.TenantID, &e.InvoiceID, &e.ETTN, &e.Profile, &e.DocumentNumber, &e.XML, &e.Status,
+		&e.ProviderReference, &e.StatusMessage, &e.SentAt, &e.CreatedAt, &e.UpdatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get e-invoice: %w", err)
+	}
+	return &e, nil
+}
+
+// SaveEInvoice inserts the e-invoice of an invoice or replaces its document.
+func (r *EInvoiceRepository) SaveEInvoice(ctx context.Context, tx pgx.Tx, e *domain.EInvoice) error {
+	query := `
+		INSERT INTO e_invoices (id, tenant_id, invoice_id, ettn, profile, document_number, xml, status, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW(), NOW())
+		ON CONFLICT (invoice_id) DO UPDATE
+		SET profile = EXCLUDED.profile, document_number = EXCLUDED.document_number, xml = EXCLUDED.xml,
+		    status = EXCLUDED.status, provider_reference = NULL, status_message = NULL, sent_at = NULL, updated_at = NOW()
+		RETURNING id, created_at, updated_at
+	`
+	err := tx.QueryRow(ctx, query,
+		e.ID,
+		e.TenantID,
+		e.InvoiceID,
+		e.ETTN,
+		e.Profile,
+		e.DocumentNumber,
+		string(e.XML),
+		e.Status,
+	).Scan(&e.ID, &e.CreatedAt, &e.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save e-invoice: %w", err)
+	}
+	return nil
+}
+
+// UpdateEInvoiceStatus records the integrator's answer.
+func (r *EInvoiceRepository) UpdateEInvoiceStatus(ctx context.Context, tx pgx.Tx, e *domain.EInvoice) error {
+	query := `
+		UPDATE e_invoices
+		SET status = $3, provider_reference = NULLIF($4, ''), status_message = NULLIF($5, ''), sent_at = $6, updated_at = NOW()
+		WHERE tenant_id = $1 AND id = $2
+		RETURNING updated_at
+	`
+	err := tx.QueryRow(ctx, query, e.TenantID, e.ID, e.Status, e.ProviderReference, e.StatusMessage, e.SentAt).Scan(&e.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update e-invoice status: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"sancaksoft/internal/domain"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ExportRepository reads export rows one at a time. Each method calls fn for every row as
+// it arrives from the database and stops at the first error fn returns, so an export never
+// holds more than one row in memory.
+type ExportRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewExportRepository(db *pgxpool.Pool) *ExportRepository {
+	return &ExportRepository{db: db}
+}
+
+// EachInvoiceLine reads the lines of the invoices matching the filter in issue order.
+func (r *ExportRepository) EachInvoiceLine(ctx context.Context, f domain.InvoiceFilter, fn func(domain.InvoiceExportLine) error) error {
+	rows, err := r.db.Query(ctx, `
+		SELECT i.id, i.invoice_number, i.created_at, i.due_date, c.name, COALESCE(c.tax_number, ''), COALESCE(w.name, ''),
+		       COALESCE(p.sku, ''), COALESCE(p.name, ''), COALESCE(p.unit, 'adet'), ii.quantity, ii.unit_price,
+		       COALESCE(ii.vat_rate, p.vat_rate, 0), ii.total, i.total_amount
+		FROM invoices i
+		JOIN customers c ON c.id = i.customer_id AND c.tenant_id = i.tenant_id
+		LEFT JOIN warehouses w ON w.id = i.warehouse_id AND w.tenant_id = i.tenant_id
+		JOIN invoice_items ii ON ii.invoice_id = i.id AND ii.tenant_id = i.tenant_id
+		LEFT JOIN products p ON p.id = ii.product_id`+invoiceFilterWhere+`
+		ORDER BY i.created_at, i.invoice_number, ii.created_at, ii.id
+	`, invoiceFilterArgs(f)...)
+	if err != nil {
+		return fmt.Errorf("failed to export invoices: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var l domain.InvoiceExportLine
+		if err := rows.Scan(
+			&l.InvoiceID, &l.InvoiceNumber, &l.CreatedAt, &l.DueDate, &l.CustomerName, &l.CustomerTaxNumber, &l.WarehouseName,
+			&l.SKU, &l.ProductName, &l.Unit, &l.Quantity, &l.UnitPrice, &l.VATRate, &l.LineTotal, &l.InvoiceTotal,
+		); err != nil {
+			return fmt.Errorf("failed to scan invoice line: %w", err)
+		}
+		if err := fn(l); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// EachStockMovement reads the stock movements matching the filter in ledger order.
+func (r *ExportRepository) EachStockMovement(ctx context.Context, f domain.StockMovementFilter, fn func(domain.StockMovementExportLine) error) error {
+	rows, err := r.db.Query(ctx, `
+		SELECT sm.id, sm.tenant_id, sm.product_id, sm.warehouse_id, sm.quantity, sm.type, sm.reference_id, sm.reference_type,
+		       sm.unit_cost, sm.assigned_cost, sm.lot_id, l.lot_number, l.expiry_date, sm.location_id, sm.created_at,
+		       COALESCE(p.sku, ''), COALESCE(p.name, ''), COALESCE(w.name, ''),
+		       COALESCE(inv.invoice_number, dn.note_number, gr.receipt_number),
+		       COALESCE(ic.name, dc.name, rc.name, s.name)
+		FROM stock_movements sm
+		LEFT JOIN lots l ON l.id = sm.lot_id
+		LEFT JOIN products p ON p.id = sm.product_id
+		LEFT JOIN warehouses w ON w.id = sm.warehouse_id`+movementDocumentJoins+stockMovementFilterWhere+`
+		ORDER BY sm.created_at, CASE WHEN sm.quantity > 0 THEN 0 ELSE 1 END, sm.id
+	`, stockMovementFilterArgs(f)...)
+	if err != nil {
+		return fmt.Errorf("failed to export stock movements: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var m domain.StockMovementExportLine
+		if err := rows.Scan(
+			&m.ID, &m.TenantID, &m.ProductID, &m.WarehouseID, &m.Quantity, &m.Type, &m.ReferenceID, &m.ReferenceType,
+			&m.UnitCost, &m.AssignedCost, &m.LotID, &m.LotNumber, &m.ExpiryDate, &m.LocationID, &m.CreatedAt,
+			&m.SKU, &m.ProductName, &m.WarehouseName, &m.DocumentNumber, &m.PartyName,
+		); err != nil {
+			return fmt.Errorf("failed to scan stock movement: %w", err)
+		}
+		if err := fn(m); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// EachWarehouseStock reads the non-zero stock balances matching the filter by warehouse
+// and SKU.
+func (r *ExportRepository) EachWarehouseStock(ctx context.Context, f domain.WarehouseStockFilter, fn func(domain.WarehouseStock) error) error {
+	rows, err := r.db.Query(ctx, `
+		SELECT sb.warehouse_id, w.name, sb.product_id, p.sku, p.name, COALESCE(p.unit, 'adet'), sb.quantity
+		FROM stock_balances sb
+		JOIN warehouses w ON w.id = sb.warehouse_id AND w.tenant_id = sb.tenant_id
+		JOIN products p ON p.id = sb.product_id AND p.tenant_id = sb.tenant_id
+		WHERE sb.tenant_id = $1 AND sb.quantity <> 0
+			AND ($2::uuid IS NULL OR sb.product_id = $2)
+			AND ($3::uuid IS NULL OR sb.warehouse_id = $3)
+		ORDER BY w.name, sb.warehouse_id, p.sku
+	`, f.TenantID, f.ProductID, f.WarehouseID)
+	if err != nil {
+		return fmt.Errorf("failed to export stock: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var s domain.WarehouseStock
+		if err := rows.Scan(&s.WarehouseID, &s.WarehouseName, &s.ProductID, &s.SKU, &s.ProductName, &s.Unit, &s.Quantity); err != nil {
+			return fmt.Errorf("failed to scan stock balance: %w", err)
+		}
+		if err := fn(s); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// EachCustomerLedgerLine reads customer movements in [from, to) by customer name and in the
+// order they happened. The running balance is summed over every movement of the customer,
+// so the first exported line carries the balance brought forward.
+func (r *ExportRepository) EachCustomerLedgerLine(ctx context.Context, f domain.CustomerLedgerFilter, fn func(domain.CustomerLedgerLine) error) error {
+	if _, err := r.db.Exec(ctx, customerReturnsDDL); err != nil {
+		return fmt.Errorf("failed to ensure returns table: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT m.customer_id, c.name, COALESCE(c.tax_number, ''),
+			m.occurred_at, m.movement_type, m.document_id, m.document_number, m.description, m.due_date,
+			CASE WHEN m.movement_type = 'SALE' THEN m.amount ELSE 0 END,
+			CASE WHEN m.movement_type = 'SALE' THEN 0 ELSE m.amount END,
+			m.balance
+		FROM (
+			SELECT movements.*, SUM(CASE WHEN movement_type = 'SALE' THEN amount ELSE -amount END) OVER (
+				PARTITION BY customer_id
+				ORDER BY occurred_at, movement_type DESC, document_number, document_id
+				ROWS UNBOUNDED PRECEDING
+			) AS balance
+			FROM `+customerMovements+`
+		) m
+		JOIN customers c ON c.id = m.customer_id AND c.tenant_id = $1
+		WHERE ($3::timestamp IS NULL OR m.occurred_at >= $3)
+			AND ($4::timestamp IS NULL OR m.occurred_at < $4)
+		ORDER BY c.name, m.customer_id, m.occurred_at, m.movement_type DESC, m.document_number, m.document_id
+	`, f.TenantID, f.CustomerID, f.From, f.To)
+	if err != nil {
+		return fmt.Errorf("failed to export customer ledger: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var l domain.CustomerLedgerLine
+		if err := rows.Scan(&l.CustomerID, &l.CustomerName, &l.TaxNumber,
+			&l.Date, &l.Type, &l.DocumentID, &l.DocumentNumber, &l.Description, &l.DueDate,
+			&l.Debit, &l.Credit, &l.Balance); err != nil {
+			return fmt.Errorf("failed to scan customer ledger line: %w", err)
+		}
+		if err := fn(l); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
@@ -0,0 +1,170 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"sancaksoft/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ImportProduct is what an opening stock import needs to know about a product.
+type ImportProduct struct {
+	ID           uuid.UUID
+	SKU          string
+	TrackLots    bool
+	TrackSerials bool
+	HasVariants  bool
+	IsKit        bool
+}
+
+// ImportWarehouse is a warehouse an opening stock row can name.
+type ImportWarehouse struct {
+	ID           uuid.UUID
+	Name         string
+	IsQuarantine bool
+}
+
+type ImportRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewImportRepository(db *pgxpool.Pool) *ImportRepository {
+	return &ImportRepository{db: db}
+}
+
+// CreateAuditLog records a change to an imported record.
+func (r *ImportRepository) CreateAuditLog(ctx context.Context, tx pgx.Tx, log *domain.AuditLog) error {
+	return createAuditLog(ctx, r.db, tx, log)
+}
+
+// ListProducts returns the tenant's products by SKU. Deleted products keep their SKU.
+func (r *ImportRepository) ListProducts(ctx context.Context, tenantID uuid.UUID) (map[string]ImportProduct, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT p.id, p.sku, p.track_lots, p.track_serials, p.deleted_at IS NULL,
+		       EXISTS (SELECT 1 FROM product_variant_dimensions d WHERE d.tenant_id = p.tenant_id AND d.product_id = p.id),
+		       EXISTS (SELECT 1 FROM kit_components k WHERE k.tenant_id = p.tenant_id AND k.kit_id = p.id)
+		FROM products p
+		WHERE p.tenant_id = $1
+	`, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list products: %w", err)
+	}
+	defer rows.Close()
+
+	products := map[string]ImportProduct{}
+	for rows.Next() {
+		var p ImportProduct
+		var active bool
+		if err := rows.Scan(&p.ID, &p.SKU, &p.TrackLots, &p.TrackSerials, &active, &p.HasVariants, &p.IsKit); err != nil {
+			return nil, fmt.Errorf("failed to scan product: %w", err)
+		}
+		if !active {
+			// The SKU is taken but the product cannot receive stock
+			p.ID = uuid.Nil
+		}
+		products[p.SKU] = p
+	}
+	return products, rows.Err()
+}
+
+// ListCustomerEmails returns the lower-cased emails of the tenant's customers.
+func (r *ImportRepository) ListCustomerEmails(ctx context.Context, tenantID uuid.UUID) (map[string]bool, error) {
+	rows, err := r.db.Query(ctx, `SELECT LOWER(email) FROM customers WHERE tenant_id = $1 AND email IS NOT NULL`, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list customer emails: %w", err)
+	}
+	defer rows.Close()
+
+	emails := map[string]bool{}
+	for rows.Next() {
+		var email string
+		if err := rows.Scan(&email); err != nil {
+			return nil, fmt.Errorf("failed to scan customer email: %w", err)
+		}
+		emails[email] = true
+	}
+	return emails, rows.Err()
+}
+
+// ListWarehouses returns the tenant's warehouses by lower-cased name.
+func (r *ImportRepository) ListWarehouses(ctx context.Context, tenantID uuid.UUID) (map[string]ImportWarehouse, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT id, name, is_quarantine FROM warehouses WHERE tenant_id = $1 AND deleted_at IS NULL
+	`, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list warehouses: %w", err)
+	}
+	defer rows.Close()
+
+	warehouses := map[string]ImportWarehouse{}
+	for rows.Next() {
+		var w ImportWarehouse
+		if err := rows.Scan(&w.ID, &w.Name, &w.IsQuarantine); err != nil {
+			return nil, fmt.Errorf("failed to scan warehouse: %w", err)
+		}
+		warehouses[strings.ToLower(strings.TrimSpace(w.Name))] = w
+	}
+	return warehouses, rows.Err()
+}
+
+// ListStockedPairs returns the product and warehouse pairs that already have stock movements.
+func (r *ImportRepository) ListStockedPairs(ctx context.Context, tenantID uuid.UUID) (map[[2]uuid.UUID]bool, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT DISTINCT product_id, warehouse_id FROM stock_movements WHERE tenant_id = $1
+	`, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stocked products: %w", err)
+	}
+	defer rows.Close()
+
+	pairs := map[[2]uuid.UUID]bool{}
+	for rows.Next() {
+		var productID, warehouseID uuid.UUID
+		if err := rows.Scan(&productID, &warehouseID); err != nil {
+			return nil, fmt.Errorf("failed to scan stocked product: %w", err)
+		}
+		pairs[[2]uuid.UUID{productID, warehouseID}] = true
+	}
+	return pairs, rows.Err()
+}
+
+// CreateProduct inserts an imported product.
+func (r *ImportRepository) CreateProduct(ctx context.Context, tx pgx.Tx, p *domain.Product) error {
+	_, err := tx.Exec(ctx, `
+		INSERT INTO products (id, tenant_id, name, sku, barcode, unit, price, vat_rate, standard_cost, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW(), NOW())
+	`, p.ID, p.TenantID, p.Name, p.SKU, p.Barcode, p.Unit, p.Price, p.VATRate, p.StandardCost)
+	if err != nil {
+		return fmt.Errorf("failed to create product %s: %w", p.SKU, err)
+	}
+	return nil
+}
+
+// CreateCustomer inserts an imported customer.
+func (r *ImportRepository) CreateCustomer(ctx context.Context, tx pgx.Tx, c *domain.Customer) error {
+	_, err := tx.Exec(ctx, `
+		INSERT INTO customers (id, tenant_id, name, email, phone, address, district, city, tax_number, tax_office, credit_limit, payment_term_days, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''), NULLIF($8, ''), NULLIF($9, ''), NULLIF($10, ''), $11, $12, NOW(), NOW())
+	`, c.ID, c.TenantID, c.Name, c.Email, c.Phone, c.Address, c.District, c.City, c.TaxNumber, c.TaxOffice, c.CreditLimit, c.PaymentTermDays)
+	if err != nil {
+		return fmt.Errorf("failed to create customer %s: %w", c.Email, err)
+	}
+	return nil
+}
+
+// CreateOpeningBalance inserts the IN movement that opens a product's stock in a warehouse.
+func (r *ImportRepository) CreateOpeningBalance(ctx context.Context, tx pgx.Tx, m *domain.StockMovement) error {
+	_, err := tx.Exec(ctx, `
+		INSERT INTO stock_movements (id, tenant_id, product_id, warehouse_id, quantity, type, reference_type, unit_cost, created_at)
+		VALUES ($1, $2, $3, $4, $5, 'IN', 'OPENING_BALANCE', $6, NOW())
+	`, m.ID, m.TenantID, m.ProductID, m.WarehouseID, m.Quantity, m.UnitCost)
+	if err != nil {
+		return fmt.Errorf("failed to create opening balance: %w", err)
+	}
+	return nil
+}
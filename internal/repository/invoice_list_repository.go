@@ -8,6 +8,7 @@ import (
 	"sancaksoft/internal/domain"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -19,16 +20,30 @@ func NewInvoiceListRepository(db *pgxpool.Pool) *InvoiceListRepository {
 	return &InvoiceListRepository{db: db}
 }
 
-func (r *InvoiceListRepository) ListInvoices(ctx context.Context, tenantID uuid.UUID) ([]domain.Invoice, error) {
+// invoiceFilterWhere restricts invoices (i) to an InvoiceFilter passed by invoiceFilterArgs.
+// The invoice list and the invoice export share it.
+const invoiceFilterWhere = `
+	WHERE i.tenant_id = $1 AND i.deleted_at IS NULL
+		AND ($2::uuid IS NULL OR i.customer_id = $2)
+		AND ($3::uuid IS NULL OR i.warehouse_id = $3)
+		AND ($4::timestamp IS NULL OR i.created_at >= $4)
+		AND ($5::timestamp IS NULL OR i.created_at < $5)
+`
+
+func invoiceFilterArgs(f domain.InvoiceFilter) []any {
+	return []any{f.TenantID, f.CustomerID, f.WarehouseID, f.From, f.To}
+}
+
+// ListInvoices returns the latest 100 invoices matching the filter.
+func (r *InvoiceListRepository) ListInvoices(ctx context.Context, f domain.InvoiceFilter) ([]domain.Invoice, error) {
 	query := `
-		SELECT id, tenant_id, warehouse_id, customer_id, invoice_number, total_amount, created_at, updated_at
-		FROM invoices
-		WHERE tenant_id = $1 AND deleted_at IS NULL
-		ORDER BY created_at DESC
+		SELECT i.id, i.tenant_id, i.warehouse_id, i.customer_id, i.invoice_number, i.total_amount, i.sales_order_id, i.created_at, i.updated_at
+		FROM invoices i` + invoiceFilterWhere + `
+		ORDER BY i.created_at DESC
 		LIMIT 100
 	`
 
-	rows, err := r.db.Query(ctx, query, tenantID)
+	rows, err := r.db.Query(ctx, query, invoiceFilterArgs(f)...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list invoices: %w", err)
 	}
@@ -44,6 +59,7 @@ func (r *InvoiceListRepository) ListInvoices(ctx context.Context, tenantID uuid.
 			&inv.CustomerID,
 			&inv.InvoiceNumber,
 			&inv.TotalAmount,
+			&inv.SalesOrderID,
 			&inv.CreatedAt,
 			&inv.UpdatedAt,
 		); err != nil {
@@ -126,3 +142,75 @@ func (r *InvoiceListRepository) GetInvoiceDetail(ctx context.Context, tenantID,
 
 	return &detail, items, nil
 }
+
+// GetInvoiceDocument returns an invoice with its customer, lines, the tenant's company
+// details and invoice template; nil if the invoice does not exist. VAT totals are left
+// to the caller.
+func (r *InvoiceListRepository) GetInvoiceDocument(ctx context.Context, tenantID, invoiceID uuid.UUID) (*domain.InvoiceDocument, error) {
+	return getInvoiceDocument(ctx, r.db, tenantID, invoiceID)
+}
+
+func getInvoiceDocument(ctx context.Context, q dbtx, tenantID, invoiceID uuid.UUID) (*domain.InvoiceDocument, error) {
+	var doc domain.InvoiceDocument
+	inv, cu := &doc.Invoice, &doc.Customer
+	err := q.QueryRow(ctx, `
+		SELECT i.id, i.tenant_id, i.warehouse_id, i.customer_id, i.invoice_number, i.total_amount,
+		       i.sales_order_id, i.salesperson_id, i.due_date, i.created_at, i.updated_at,
+		       c.id, c.tenant_id, c.name, COALESCE(c.email, ''), COALESCE(c.phone, ''), COALESCE(c.address, ''),
+		       COALESCE(c.district, ''), COALESCE(c.city, ''), COALESCE(c.tax_number, ''), COALESCE(c.tax_office, ''), c.credit_limit, c.payment_term_days, c.created_at, c.updated_at
+		FROM invoices i
+		JOIN customers c ON c.id = i.customer_id AND c.tenant_id = i.tenant_id
+		WHERE i.tenant_id = $1 AND i.id = $2 AND i.deleted_at IS NULL
+	`, tenantID, invoiceID).Scan(
+		&inv.ID, &inv.TenantID, &inv.WarehouseID, &inv.CustomerID, &inv.InvoiceNumber, &inv.TotalAmount,
+		&inv.SalesOrderID, &inv.SalespersonID, &inv.DueDate, &inv.CreatedAt, &inv.UpdatedAt,
+		&cu.ID, &cu.TenantID, &cu.Name, &cu.Email, &cu.Phone, &cu.Address,
+		&cu.District, &cu.City, &cu.TaxNumber, &cu.TaxOffice, &cu.CreditLimit, &cu.PaymentTermDays, &cu.CreatedAt, &cu.UpdatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get invoice: %w", err)
+	}
+
+	// Lines invoiced before VAT rates were captured use the product's current rate
+	rows, err := q.Query(ctx, `
+		SELECT COALESCE(p.name, ''), COALESCE(p.sku, ''), ii.quantity, COALESCE(p.unit, 'adet'), ii.unit_price,
+		       COALESCE(ii.vat_rate, p.vat_rate, 0), ii.total
+		FROM invoice_items ii
+		LEFT JOIN products p ON p.id = ii.product_id
+		WHERE ii.tenant_id = $1 AND ii.invoice_id = $2
+		ORDER BY ii.created_at, ii.id
+	`, tenantID, invoiceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list invoice lines: %w", err)
+	}
+	defer rows.Close()
+
+	doc.Lines = []domain.InvoiceDocumentLine{}
+	for rows.Next() {
+		var l domain.InvoiceDocumentLine
+		if err := rows.Scan(&l.ProductName, &l.SKU, &l.Quantity, &l.Unit, &l.UnitPrice, &l.VATRate, &l.Total); err != nil {
+			return nil, fmt.Errorf("failed to scan invoice line: %w", err)
+		}
+		doc.Lines = append(doc.Lines, l)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list invoice lines: %w", err)
+	}
+
+	company, err := getTenantProfile(ctx, q, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if company != nil {
+		doc.Company = *company
+	}
+	template, err := getInvoiceTemplate(ctx, q, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	doc.Template = *template
+	return &doc, nil
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
 )
 
 // InvoiceRepository handles database operations for invoices and related entities.
@@ -52,8 +53,8 @@ func (r *InvoiceRepository) GenerateNextInvoiceNumber(ctx context.Context, tx pg
 // CreateInvoice inserts the invoice header.
 func (r *InvoiceRepository) CreateInvoice(ctx context.Context, tx pgx.Tx, invoice *domain.Invoice, idempotencyKey uuid.UUID) error {
 	query := `
-		INSERT INTO invoices (id, tenant_id, warehouse_id, customer_id, invoice_number, total_amount, idempotency_key, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW(), NOW())
+		INSERT INTO invoices (id, tenant_id, warehouse_id, customer_id, invoice_number, total_amount, idempotency_key, sales_order_id, salesperson_id, due_date, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NOW(), NOW())
 		RETURNING created_at
 	`
 	return tx.QueryRow(ctx, query,
@@ -64,9 +65,72 @@ func (r *InvoiceRepository) CreateInvoice(ctx context.Context, tx pgx.Tx, invoic
 		invoice.InvoiceNumber,
 		invoice.TotalAmount,
 		idempotencyKey,
+		invoice.SalesOrderID,
+		invoice.SalespersonID,
+		invoice.DueDate,
 	).Scan(&invoice.CreatedAt)
 }
 
+// LockCustomer locks the customer row so concurrent invoices are checked against its credit
+// one at a time, and returns its credit terms; nil if the customer does not exist.
+func (r *InvoiceRepository) LockCustomer(ctx context.Context, tx pgx.Tx, tenantID, customerID uuid.UUID) (*domain.Customer, error) {
+	c := domain.Customer{ID: customerID, TenantID: tenantID}
+	err := tx.QueryRow(ctx, `
+		SELECT name, credit_limit, payment_term_days FROM customers
+		WHERE tenant_id = $1 AND id = $2 AND deleted_at IS NULL
+		FOR UPDATE
+	`, tenantID, customerID).Scan(&c.Name, &c.CreditLimit, &c.PaymentTermDays)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to lock customer %s: %w", customerID, err)
+	}
+	return &c, nil
+}
+
+// GetCustomerCredit returns the customer's credit position as of a date.
+func (r *InvoiceRepository) GetCustomerCredit(ctx context.Context, tx pgx.Tx, tenantID, customerID uuid.UUID, asOf time.Time) (*domain.CustomerCredit, error) {
+	return getCustomerCredit(ctx, tx, tenantID, customerID, asOf)
+}
+
+// GetUninvoicedDeliveryTotal returns the total of the customer's shipped delivery notes that
+// have not been invoiced yet; the goods are out but not yet in the open balance.
+func (r *InvoiceRepository) GetUninvoicedDeliveryTotal(ctx context.Context, tx pgx.Tx, tenantID, customerID uuid.UUID) (decimal.Decimal, error) {
+	var total decimal.Decimal
+	err := tx.QueryRow(ctx, `
+		SELECT COALESCE(SUM(total_amount), 0) FROM delivery_notes
+		WHERE tenant_id = $1 AND customer_id = $2 AND status = $3
+	`, tenantID, customerID, domain.DeliveryNoteStatusShipped).Scan(&total)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("failed to get uninvoiced delivery total: %w", err)
+	}
+	return total, nil
+}
+
+// GetUserRole returns the role of a tenant's user; empty if the user does not exist.
+func (r *InvoiceRepository) GetUserRole(ctx context.Context, tx pgx.Tx, tenantID, userID uuid.UUID) (string, error) {
+	var role string
+	err := tx.QueryRow(ctx, `SELECT role FROM users WHERE tenant_id = $1 AND id = $2 AND deleted_at IS NULL`, tenantID, userID).Scan(&role)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to get user role: %w", err)
+	}
+	return role, nil
+}
+
+// IsQuarantineWarehouse reports whether the warehouse holds quarantined/scrapped stock.
+func (r *InvoiceRepository) IsQuarantineWarehouse(ctx context.Context, tx pgx.Tx, tenantID, warehouseID uuid.UUID) (bool, error) {
+	var isQuarantine bool
+	err := tx.QueryRow(ctx, `SELECT is_quarantine FROM warehouses WHERE id = $1 AND tenant_id = $2`, warehouseID, tenantID).Scan(&isQuarantine)
+	if err != nil {
+		return false, fmt.Errorf("failed to get warehouse %s: %w", warehouseID, err)
+	}
+	return isQuarantine, nil
+}
+
 // LockProduct locks a product row for update to prevent concurrent stock modifications.
 func (r *InvoiceRepository) LockProduct(ctx context.Context, tx pgx.Tx, tenantID, productID uuid.UUID) error {
 	var _lock int
@@ -80,23 +144,60 @@ func (r *InvoiceRepository) LockProduct(ctx context.Context, tx pgx.Tx, tenantID
 // GetStockBalance returns the current stock balance for a product in a warehouse.
 // Note: Assumes LockProduct has been called prior for safety.
 func (r *InvoiceRepository) GetStockBalance(ctx context.Context, tx pgx.Tx, tenantID, productID, warehouseID uuid.UUID) (int, error) {
-	var currentStock int
-	err := tx.QueryRow(ctx, `
-		SELECT COALESCE(SUM(quantity), 0)
-		FROM stock_movements
-		WHERE tenant_id = $1 AND product_id = $2 AND warehouse_id = $3
-	`, tenantID, productID, warehouseID).Scan(&currentStock)
+	return getStockBalance(ctx, tx, tenantID, productID, warehouseID)
+}
+
+// ProductTracksLots reports whether sales of the product are allocated per lot.
+func (r *InvoiceRepository) ProductTracksLots(ctx context.Context, tx pgx.Tx, tenantID, productID uuid.UUID) (bool, error) {
+	return productTracksLots(ctx, tx, tenantID, productID)
+}
+
+// ListAvailableLots returns the product's lots in stock in a warehouse, first to expire first.
+// Note: Assumes LockProduct has been called prior for safety.
+func (r *InvoiceRepository) ListAvailableLots(ctx context.Context, tx pgx.Tx, tenantID, productID, warehouseID uuid.UUID) ([]domain.LotBalance, error) {
+	return listAvailableLots(ctx, tx, tenantID, productID, warehouseID)
+}
+
+// ListProductLocations returns the locations holding the product in a warehouse, in path order.
+func (r *InvoiceRepository) ListProductLocations(ctx context.Context, tx pgx.Tx, tenantID, productID, warehouseID uuid.UUID) ([]domain.LocationBalance, error) {
+	return listProductLocations(ctx, tx, tenantID, productID, warehouseID)
+}
+
+// ProductTracksSerials reports whether every unit of the product moves under a serial number.
+func (r *InvoiceRepository) ProductTracksSerials(ctx context.Context, tx pgx.Tx, tenantID, productID uuid.UUID) (bool, error) {
+	return productTracksSerials(ctx, tx, tenantID, productID)
+}
+
+// ListKitComponents returns the components of a kit ordered by component id; empty if the
+// product is not a kit.
+func (r *InvoiceRepository) ListKitComponents(ctx context.Context, tx pgx.Tx, tenantID, productID uuid.UUID) ([]domain.KitComponent, error) {
+	return listKitComponents(ctx, tx, tenantID, productID)
+}
+
+// MoveSerials moves the serial numbers of an inserted movement and returns the first one
+// that is not where the movement needs it.
+func (r *InvoiceRepository) MoveSerials(ctx context.Context, tx pgx.Tx, movement *domain.StockMovement) (string, error) {
+	return moveSerials(ctx, tx, movement)
+}
+
+// GetReservedQuantity returns the quantity reserved by open sales orders for a product
+// in a warehouse, ignoring excludeOrderID (the order being invoiced) if set.
+func (r *InvoiceRepository) GetReservedQuantity(ctx context.Context, tx pgx.Tx, tenantID, productID, warehouseID uuid.UUID, excludeOrderID *uuid.UUID) (int, error) {
+	var reserved int
+	err := tx.QueryRow(ctx, reservedQuantityQuery, tenantID, productID, warehouseID, excludeOrderID).Scan(&reserved)
 	if err != nil {
-		return 0, fmt.Errorf("failed to get stock balance: %w", err)
+		return 0, fmt.Errorf("failed to get reserved quantity: %w", err)
 	}
-	return currentStock, nil
+	return reserved, nil
 }
 
-// CreateInvoiceItem inserts a line item.
+// CreateInvoiceItem inserts a line item with the product's current category, brand and VAT rate.
 func (r *InvoiceRepository) CreateInvoiceItem(ctx context.Context, tx pgx.Tx, item *domain.InvoiceItem) error {
 	query := `
-		INSERT INTO invoice_items (id, tenant_id, invoice_id, product_id, quantity, unit_price, total, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW())
+		INSERT INTO invoice_items (id, tenant_id, invoice_id, product_id, quantity, unit_price, total, unit_cost, category_id, brand_id, vat_rate, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8,
+			(SELECT category_id FROM products WHERE id = $4), (SELECT brand_id FROM products WHERE id = $4),
+			(SELECT vat_rate FROM products WHERE id = $4), NOW())
 	`
 	_, err := tx.Exec(ctx, query,
 		item.ID,
@@ -106,18 +207,50 @@ func (r *InvoiceRepository) CreateInvoiceItem(ctx context.Context, tx pgx.Tx, it
 		item.Quantity,
 		item.UnitPrice,
 		item.Total,
+		item.UnitCost,
 	)
 	return err
 }
 
+// CreateInvoiceItemComponent records a component shipped for a kit line with the component's
+// current category and brand.
+func (r *InvoiceRepository) CreateInvoiceItemComponent(ctx context.Context, tx pgx.Tx, c *domain.InvoiceItemComponent) error {
+	_, err := tx.Exec(ctx, `
+		INSERT INTO invoice_item_components (tenant_id, invoice_item_id, product_id, quantity, revenue, unit_cost, category_id, brand_id)
+		VALUES ($1, $2, $3, $4, $5, $6,
+			(SELECT category_id FROM products WHERE id = $3), (SELECT brand_id FROM products WHERE id = $3))
+	`, c.TenantID, c.InvoiceItemID, c.ProductID, c.Quantity, c.Revenue, c.UnitCost)
+	return err
+}
+
+// GetSaleUnitCost returns the cost to capture on a sale line: the product's standard cost,
+// else the unit cost of its latest costed receipt, else zero.
+func (r *InvoiceRepository) GetSaleUnitCost(ctx context.Context, tx pgx.Tx, tenantID, productID uuid.UUID) (decimal.Decimal, error) {
+	var cost decimal.Decimal
+	err := tx.QueryRow(ctx, `
+		SELECT COALESCE(p.standard_cost, (
+			SELECT sm.unit_cost FROM stock_movements sm
+			WHERE sm.tenant_id = p.tenant_id AND sm.product_id = p.id AND sm.quantity > 0 AND sm.unit_cost IS NOT NULL
+			ORDER BY sm.created_at DESC, sm.id DESC
+			LIMIT 1
+		), 0)
+		FROM products p
+		WHERE p.tenant_id = $1 AND p.id = $2
+	`, tenantID, productID).Scan(&cost)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("failed to get sale cost of product %s: %w", productID, err)
+	}
+	return cost, nil
+}
+
 // CreateStockMovement inserts a stock movement record.
 func (r *InvoiceRepository) CreateStockMovement(ctx context.Context, tx pgx.Tx, movement *domain.StockMovement) error {
 	query := `
 		INSERT INTO stock_movements (
 			id, tenant_id, product_id, warehouse_id, 
-			quantity, type, reference_id, reference_type, created_at
+			quantity, type, reference_id, reference_type, unit_cost, lot_id, location_id, created_at
 		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW())
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, NOW())
 	`
 	_, err := tx.Exec(ctx, query,
 		movement.ID,
@@ -128,23 +261,14 @@ func (r *InvoiceRepository) CreateStockMovement(ctx context.Context, tx pgx.Tx,
 		movement.Type,
 		movement.ReferenceID,
 		movement.ReferenceType,
+		movement.UnitCost,
+		movement.LotID,
+		movement.LocationID,
 	)
 	return err
 }
 
 // CreateAuditLog inserts an audit log entry.
 func (r *InvoiceRepository) CreateAuditLog(ctx context.Context, tx pgx.Tx, log *domain.AuditLog) error {
-	query := `
-		INSERT INTO audit_logs (id, tenant_id, user_id, entity_type, entity_id, action, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6, NOW())
-	`
-	_, err := tx.Exec(ctx, query,
-		log.ID,
-		log.TenantID,
-		log.UserID,
-		log.EntityType,
-		log.EntityID,
-		log.Action,
-	)
-	return err
+	return createAuditLog(ctx, nil, tx, log)
 }
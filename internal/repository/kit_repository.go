@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"sancaksoft/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type KitRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewKitRepository(db *pgxpool.Pool) *KitRepository {
+	return &KitRepository{db: db}
+}
+
+// CreateAuditLog records a change to a kit.
+func (r *KitRepository) CreateAuditLog(ctx context.Context, tx pgx.Tx, log *domain.AuditLog) error {
+	return createAuditLog(ctx, r.db, tx, log)
+}
+
+// GetProduct returns a product, or nil if not found.
+func (r *KitRepository) GetProduct(ctx context.Context, tenantID, productID uuid.UUID) (*domain.Product, error) {
+	return getProduct(ctx, r.db, tenantID, productID, "")
+}
+
+// LockProduct locks a product row so its components are changed one request at a time.
+// It returns nil if the product is not found.
+func (r *KitRepository) LockProduct(ctx context.Context, tx pgx.Tx, tenantID, productID uuid.UUID) (*domain.Product, error) {
+	return getProduct(ctx, tx, tenantID, productID, "FOR UPDATE")
+}
+
+// GetComponentProduct returns a product that is about to become a component, or nil if not found.
+func (r *KitRepository) GetComponentProduct(ctx context.Context, tx pgx.Tx, tenantID, productID uuid.UUID) (*domain.Product, error) {
+	return getProduct(ctx, tx, tenantID, productID, "")
+}
+
+// HasMovements reports whether the product ever moved stock.
+func (r *KitRepository) HasMovements(ctx context.Context, tx pgx.Tx, tenantID, productID uuid.UUID) (bool, error) {
+	var moved bool
+	err := tx.QueryRow(ctx, `
+		SELECT EXISTS (SELECT 1 FROM stock_movements WHERE tenant_id = $1 AND product_id = $2)
+	`, tenantID, productID).Scan(&moved)
+	if err != nil {
+		return false, fmt.Errorf("failed to check product movements: %w", err)
+	}
+	return moved, nil
+}
+
+// IsComponent reports whether the product is a component of some kit.
+func (r *KitRepository) IsComponent(ctx context.Context, tx pgx.Tx, tenantID, productID uuid.UUID) (bool, error) {
+	var component bool
+	err := tx.QueryRow(ctx, `
+		SELECT EXISTS (SELECT 1 FROM kit_components WHERE tenant_id = $1 AND component_id = $2)
+	`, tenantID, productID).Scan(&component)
+	if err != nil {
+		return false, fmt.Errorf("failed to check kits of product %s: %w", productID, err)
+	}
+	return component, nil
+}
+
+// ProductIsKit reports whether the product is sold as a kit of other products.
+func (r *KitRepository) ProductIsKit(ctx context.Context, tx pgx.Tx, tenantID, productID uuid.UUID) (bool, error) {
+	return productIsKit(ctx, tx, tenantID, productID)
+}
+
+// ProductIsVariantParent reports whether the product's stock is kept on its variants.
+func (r *KitRepository) ProductIsVariantParent(ctx context.Context, tx pgx.Tx, tenantID, productID uuid.UUID) (bool, error) {
+	return productIsVariantParent(ctx, tx, tenantID, productID)
+}
+
+// ProductTracksLots reports whether movements of the product need a lot number.
+func (r *KitRepository) ProductTracksLots(ctx context.Context, tx pgx.Tx, tenantID, productID uuid.UUID) (bool, error) {
+	return productTracksLots(ctx, tx, tenantID, productID)
+}
+
+// ProductTracksSerials reports whether every unit of the product moves under a serial number.
+func (r *KitRepository) ProductTracksSerials(ctx context.Context, tx pgx.Tx, tenantID, productID uuid.UUID) (bool, error) {
+	return productTracksSerials(ctx, tx, tenantID, productID)
+}
+
+// ListComponents returns the components of a kit; empty if the product is not a kit.
+func (r *KitRepository) ListComponents(ctx context.Context, tenantID, kitID uuid.UUID) ([]domain.KitComponent, error) {
+	return listKitComponents(ctx, r.db, tenantID, kitID)
+}
+
+// GetComponents is ListComponents inside a transaction.
+func (r *KitRepository) GetComponents(ctx context.Context, tx pgx.Tx, tenantID, kitID uuid.UUID) ([]domain.KitComponent, error) {
+	return listKitComponents(ctx, tx, tenantID, kitID)
+}
+
+// ReplaceComponents replaces all components of a kit.
+func (r *KitRepository) ReplaceComponents(ctx context.Context, tx pgx.Tx, tenantID, kitID uuid.UUID, components []domain.KitComponent) error {
+	if _, err := tx.Exec(ctx, `
+		DELETE FROM kit_components WHERE tenant_id = $1 AND kit_id = $2
+	`, tenantID, kitID); err != nil {
+		return fmt.Errorf("failed to clear kit components: %w", err)
+	}
+	for _, c := range components {
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO kit_components (tenant_id, kit_id, component_id, quantity)
+			VALUES ($1, $2, $3, $4)
+		`, tenantID, kitID, c.ComponentID, c.Quantity); err != nil {
+			return fmt.Errorf("failed to add kit component %s: %w", c.ComponentID, err)
+		}
+	}
+	return nil
+}
+
+// GetStockBalance returns the current stock balance for a product in a warehouse.
+func (r *KitRepository) GetStockBalance(ctx context.Context, tenantID, productID, warehouseID uuid.UUID) (int, error) {
+	return getStockBalance(ctx, r.db, tenantID, productID, warehouseID)
+}
+
+// GetReservedQuantity returns the quantity reserved by open sales orders for a product in a warehouse.
+func (r *KitRepository) GetReservedQuantity(ctx context.Context, tenantID, productID, warehouseID uuid.UUID) (int, error) {
+	var reserved int
+	err := r.db.QueryRow(ctx, reservedQuantityQuery, tenantID, productID, warehouseID, nil).Scan(&reserved)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get reserved quantity: %w", err)
+	}
+	return reserved, nil
+}
+
+// productIsKit reports whether the product has kit components; such a product holds no
+// stock of its own and ships its components instead.
+func productIsKit(ctx context.Context, q dbtx, tenantID, productID uuid.UUID) (bool, error) {
+	var kit bool
+	err := q.QueryRow(ctx, `
+		SELECT EXISTS (SELECT 1 FROM kit_components WHERE tenant_id = $1 AND kit_id = $2)
+	`, tenantID, productID).Scan(&kit)
+	if err != nil {
+		return false, fmt.Errorf("failed to check kit components of product %s: %w", productID, err)
+	}
+	return kit, nil
+}
+
+// listKitComponents returns the components of a kit ordered by component id, the order in
+// which sales lock them.
+func listKitComponents(ctx context.Context, q dbtx, tenantID, kitID uuid.UUID) ([]domain.KitComponent, error) {
+	rows, err := q.Query(ctx, `
+		SELECT kc.kit_id, kc.component_id, p.name, p.sku, p.price, kc.quantity
+		FROM kit_components kc
+		JOIN products p ON p.id = kc.component_id
+		WHERE kc.tenant_id = $1 AND kc.kit_id = $2
+		ORDER BY kc.component_id
+	`, tenantID, kitID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list kit components: %w", err)
+	}
+	defer rows.Close()
+
+	components := []domain.KitComponent{}
+	for rows.Next() {
+		var c domain.KitComponent
+		if err := rows.Scan(&c.KitID, &c.ComponentID, &c.ComponentName, &c.SKU, &c.Price, &c.Quantity); err != nil {
+			return nil, fmt.Errorf("failed to scan kit component: %w", err)
+		}
+		components = append(components, c)
+	}
+	return components, rows.Err()
+}
@@ -0,0 +1,355 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"sancaksoft/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const locationColumns = `id, tenant_id, warehouse_id, parent_id, code, path, is_active, created_at, updated_at`
+
+type LocationRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewLocationRepository(db *pgxpool.Pool) *LocationRepository {
+	return &LocationRepository{db: db}
+}
+
+// CreateAuditLog records a change to a location or its stock.
+func (r *LocationRepository) CreateAuditLog(ctx context.Context, tx pgx.Tx, log *domain.AuditLog) error {
+	return createAuditLog(ctx, r.db, tx, log)
+}
+
+// CreateLocation inserts a location. It reports false when the warehouse already has a
+// location with the same path.
+func (r *LocationRepository) CreateLocation(ctx context.Context, tx pgx.Tx, loc *domain.WarehouseLocation) (bool, error) {
+	err := tx.QueryRow(ctx, `
+		INSERT INTO warehouse_locations (id, tenant_id, warehouse_id, parent_id, code, path, is_active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW(), NOW())
+		ON CONFLICT (tenant_id, warehouse_id, path) DO NOTHING
+		RETURNING created_at, updated_at
+	`, loc.ID, loc.TenantID, loc.WarehouseID, loc.ParentID, loc.Code, loc.Path, loc.IsActive).Scan(&loc.CreatedAt, &loc.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to create location: %w", err)
+	}
+	return true, nil
+}
+
+// GetLocation returns a location, or nil if not found.
+func (r *LocationRepository) GetLocation(ctx context.Context, tenantID, locationID uuid.UUID) (*domain.WarehouseLocation, error) {
+	return getLocation(ctx, r.db, tenantID, locationID)
+}
+
+// GetLocationForUpdate locks a location row in tx and returns it, or nil if not found.
+func (r *LocationRepository) GetLocationForUpdate(ctx context.Context, tx pgx.Tx, tenantID, locationID uuid.UUID) (*domain.WarehouseLocation, error) {
+	var loc domain.WarehouseLocation
+	err := scanLocation(tx.QueryRow(ctx, `SELECT `+locationColumns+`
+		FROM warehouse_locations
+		WHERE tenant_id = $1 AND id = $2
+		FOR UPDATE
+	`, tenantID, locationID), &loc)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock location: %w", err)
+	}
+	return &loc, nil
+}
+
+// ListLocations returns the warehouse's locations in path order.
+func (r *LocationRepository) ListLocations(ctx context.Context, tenantID, warehouseID uuid.UUID) ([]domain.WarehouseLocation, error) {
+	rows, err := r.db.Query(ctx, `SELECT `+locationColumns+`
+		FROM warehouse_locations
+		WHERE tenant_id = $1 AND warehouse_id = $2
+		ORDER BY path
+	`, tenantID, warehouseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list locations: %w", err)
+	}
+	defer rows.Close()
+
+	locations := []domain.WarehouseLocation{}
+	for rows.Next() {
+		var loc domain.WarehouseLocation
+		if err := scanLocation(rows, &loc); err != nil {
+			return nil, fmt.Errorf("failed to scan location: %w", err)
+		}
+		locations = append(locations, loc)
+	}
+	return locations, rows.Err()
+}
+
+// SetLocationActive activates or deactivates a location. It reports whether the location exists.
+func (r *LocationRepository) SetLocationActive(ctx context.Context, tx pgx.Tx, tenantID, locationID uuid.UUID, active bool) (bool, error) {
+	tag, err := tx.Exec(ctx, `
+		UPDATE warehouse_locations SET is_active = $3, updated_at = NOW()
+		WHERE tenant_id = $1 AND id = $2
+	`, tenantID, locationID, active)
+	if err != nil {
+		return false, fmt.Errorf("failed to update location: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// ListLocationBalances returns non-zero stock per location and product of a warehouse in path
+// order, followed by each product's unlocated stock.
+func (r *LocationRepository) ListLocationBalances(ctx context.Context, tenantID, warehouseID uuid.UUID, productID *uuid.UUID) ([]domain.LocationBalance, error) {
+	rows, err := r.db.Query(ctx, `
+		WITH located AS (
+			SELECT slb.location_id, wl.path, slb.product_id, slb.quantity
+			FROM stock_location_balances slb
+			JOIN warehouse_locations wl ON wl.id = slb.location_id
+			WHERE slb.tenant_id = $1 AND wl.warehouse_id = $2 AND slb.quantity <> 0
+			  AND ($3::uuid IS NULL OR slb.product_id = $3)
+		), unlocated AS (
+			SELECT NULL::uuid AS location_id, NULL::varchar AS path, sb.product_id,
+			       (sb.quantity - COALESCE((SELECT SUM(l.quantity) FROM located l WHERE l.product_id = sb.product_id), 0))::int AS quantity
+			FROM stock_balances sb
+			WHERE sb.tenant_id = $1 AND sb.warehouse_id = $2 AND ($3::uuid IS NULL OR sb.product_id = $3)
+		)
+		SELECT b.location_id, b.path, b.product_id, p.name, p.sku, b.quantity
+		FROM (SELECT * FROM located UNION ALL SELECT * FROM unlocated WHERE quantity <> 0) b
+		JOIN products p ON p.id = b.product_id
+		ORDER BY b.path NULLS LAST, p.name
+	`, tenantID, warehouseID, productID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list location balances: %w", err)
+	}
+	return scanLocationBalances(rows)
+}
+
+// CreateLocationTransfer records a move between locations; the trigger updates the location balances.
+func (r *LocationRepository) CreateLocationTransfer(ctx context.Context, tx pgx.Tx, t *domain.LocationTransfer) error {
+	err := tx.QueryRow(ctx, `
+		INSERT INTO location_transfers (id, tenant_id, warehouse_id, product_id, from_location_id, to_location_id, quantity, user_id, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW())
+		RETURNING created_at
+	`, t.ID, t.TenantID, t.WarehouseID, t.ProductID, t.FromLocationID, t.ToLocationID, t.Quantity, t.UserID).Scan(&t.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create location transfer: %w", err)
+	}
+	return nil
+}
+
+// LockProduct locks a product row so concurrent writers see consistent location balances.
+// It reports whether the product exists.
+func (r *LocationRepository) LockProduct(ctx context.Context, tx pgx.Tx, tenantID, productID uuid.UUID) (bool, error) {
+	var found int
+	err := tx.QueryRow(ctx, `SELECT 1 FROM products WHERE id = $1 AND tenant_id = $2 FOR UPDATE`, productID, tenantID).Scan(&found)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to lock product %s: %w", productID, err)
+	}
+	return true, nil
+}
+
+// GetLocationQuantity returns the product's stock in a location of a warehouse; a nil
+// location returns the unlocated stock.
+func (r *LocationRepository) GetLocationQuantity(ctx context.Context, tx pgx.Tx, tenantID, productID, warehouseID uuid.UUID, locationID *uuid.UUID) (int, error) {
+	return getLocationQuantity(ctx, tx, tenantID, productID, warehouseID, locationID)
+}
+
+// SuggestPutaway proposes a location for a product in a warehouse: the active location
+// holding most of it, otherwise the first active bin (a location without children) that
+// holds nothing, in path order. It returns nil when there is neither.
+func (r *LocationRepository) SuggestPutaway(ctx context.Context, tenantID, warehouseID, productID uuid.UUID) (*domain.WarehouseLocation, domain.PutawayReason, error) {
+	var loc domain.WarehouseLocation
+	err := scanLocation(r.db.QueryRow(ctx, `SELECT `+prefixedLocationColumns+`
+		FROM warehouse_locations wl
+		JOIN stock_location_balances slb ON slb.location_id = wl.id AND slb.tenant_id = wl.tenant_id
+		WHERE wl.tenant_id = $1 AND wl.warehouse_id = $2 AND wl.is_active AND slb.product_id = $3 AND slb.quantity > 0
+		ORDER BY slb.quantity DESC, wl.path
+		LIMIT 1
+	`, tenantID, warehouseID, productID), &loc)
+	if err == nil {
+		return &loc, domain.PutawayReasonSameProduct, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, "", fmt.Errorf("failed to suggest putaway location: %w", err)
+	}
+
+	err = scanLocation(r.db.QueryRow(ctx, `SELECT `+prefixedLocationColumns+`
+		FROM warehouse_locations wl
+		WHERE wl.tenant_id = $1 AND wl.warehouse_id = $2 AND wl.is_active
+		  AND NOT EXISTS (SELECT 1 FROM warehouse_locations c WHERE c.parent_id = wl.id)
+		  AND NOT EXISTS (SELECT 1 FROM stock_location_balances slb WHERE slb.location_id = wl.id AND slb.quantity <> 0)
+		ORDER BY wl.path
+		LIMIT 1
+	`, tenantID, warehouseID), &loc)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, "", nil
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to suggest putaway location: %w", err)
+	}
+	return &loc, domain.PutawayReasonEmptyLocation, nil
+}
+
+// ListDocumentPicks returns what the outbound movements of a document took from each
+// location, per product and lot, in path order.
+func (r *LocationRepository) ListDocumentPicks(ctx context.Context, tenantID uuid.UUID, referenceType string, referenceID uuid.UUID) ([]domain.PickListLine, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT sm.location_id, wl.path, sm.product_id, p.name, p.sku, l.lot_number, (-SUM(sm.quantity))::int
+		FROM stock_movements sm
+		JOIN products p ON p.id = sm.product_id
+		LEFT JOIN warehouse_locations wl ON wl.id = sm.location_id
+		LEFT JOIN lots l ON l.id = sm.lot_id
+		WHERE sm.tenant_id = $1 AND sm.reference_type = $2 AND sm.reference_id = $3
+		GROUP BY sm.location_id, wl.path, sm.product_id, p.name, p.sku, l.lot_number
+		HAVING SUM(sm.quantity) < 0
+		ORDER BY wl.path NULLS LAST, p.name, l.lot_number
+	`, tenantID, referenceType, referenceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list document picks: %w", err)
+	}
+	defer rows.Close()
+
+	lines := []domain.PickListLine{}
+	for rows.Next() {
+		var line domain.PickListLine
+		if err := rows.Scan(&line.LocationID, &line.LocationPath, &line.ProductID, &line.ProductName, &line.SKU, &line.LotNumber, &line.Quantity); err != nil {
+			return nil, fmt.Errorf("failed to scan pick line: %w", err)
+		}
+		lines = append(lines, line)
+	}
+	return lines, rows.Err()
+}
+
+// GetInvoicePickList returns the header of an invoice's pick list without lines, or nil if
+// the invoice is not found.
+func (r *LocationRepository) GetInvoicePickList(ctx context.Context, tenantID, invoiceID uuid.UUID) (*domain.PickList, error) {
+	list := domain.PickList{DocumentType: "INVOICE"}
+	err := r.db.QueryRow(ctx, `
+		SELECT id, invoice_number, warehouse_id FROM invoices WHERE tenant_id = $1 AND id = $2
+	`, tenantID, invoiceID).Scan(&list.DocumentID, &list.DocumentNumber, &list.WarehouseID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get invoice: %w", err)
+	}
+	return &list, nil
+}
+
+// ListSalesOrderOpenProducts returns the undelivered quantity of a sales order per product.
+func (r *LocationRepository) ListSalesOrderOpenProducts(ctx context.Context, tenantID, orderID uuid.UUID) ([]domain.PickListLine, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT soi.product_id, p.name, p.sku, SUM(soi.quantity - soi.delivered_qty)::int
+		FROM sales_order_items soi
+		JOIN products p ON p.id = soi.product_id
+		WHERE soi.tenant_id = $1 AND soi.order_id = $2
+		GROUP BY soi.product_id, p.name, p.sku
+		HAVING SUM(soi.quantity - soi.delivered_qty) > 0
+		ORDER BY p.name
+	`, tenantID, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list open order products: %w", err)
+	}
+	defer rows.Close()
+
+	lines := []domain.PickListLine{}
+	for rows.Next() {
+		var line domain.PickListLine
+		if err := rows.Scan(&line.ProductID, &line.ProductName, &line.SKU, &line.Quantity); err != nil {
+			return nil, fmt.Errorf("failed to scan open order product: %w", err)
+		}
+		lines = append(lines, line)
+	}
+	return lines, rows.Err()
+}
+
+// ListProductLocations returns the locations holding the product in a warehouse, in path order.
+func (r *LocationRepository) ListProductLocations(ctx context.Context, tenantID, productID, warehouseID uuid.UUID) ([]domain.LocationBalance, error) {
+	return listProductLocations(ctx, r.db, tenantID, productID, warehouseID)
+}
+
+const prefixedLocationColumns = `wl.id, wl.tenant_id, wl.warehouse_id, wl.parent_id, wl.code, wl.path, wl.is_active, wl.created_at, wl.updated_at`
+
+func scanLocation(row pgx.Row, loc *domain.WarehouseLocation) error {
+	return row.Scan(&loc.ID, &loc.TenantID, &loc.WarehouseID, &loc.ParentID, &loc.Code, &loc.Path, &loc.IsActive, &loc.CreatedAt, &loc.UpdatedAt)
+}
+
+// getLocation returns a location, or nil if not found.
+func getLocation(ctx context.Context, q dbtx, tenantID, locationID uuid.UUID) (*domain.WarehouseLocation, error) {
+	var loc domain.WarehouseLocation
+	err := scanLocation(q.QueryRow(ctx, `SELECT `+locationColumns+`
+		FROM warehouse_locations
+		WHERE tenant_id = $1 AND id = $2
+	`, tenantID, locationID), &loc)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get location: %w", err)
+	}
+	return &loc, nil
+}
+
+// getLocationQuantity returns the product's stock in a location; a nil location returns the
+// warehouse balance minus everything in its locations.
+func getLocationQuantity(ctx context.Context, q dbtx, tenantID, productID, warehouseID uuid.UUID, locationID *uuid.UUID) (int, error) {
+	var quantity int
+	var err error
+	if locationID != nil {
+		err = q.QueryRow(ctx, `
+			SELECT COALESCE((SELECT quantity FROM stock_location_balances WHERE tenant_id = $1 AND location_id = $2 AND product_id = $3), 0)
+		`, tenantID, *locationID, productID).Scan(&quantity)
+	} else {
+		err = q.QueryRow(ctx, `
+			SELECT COALESCE((SELECT quantity FROM stock_balances WHERE tenant_id = $1 AND product_id = $2 AND warehouse_id = $3), 0)
+			     - COALESCE((
+				SELECT SUM(slb.quantity)::int
+				FROM stock_location_balances slb
+				JOIN warehouse_locations wl ON wl.id = slb.location_id
+				WHERE slb.tenant_id = $1 AND slb.product_id = $2 AND wl.warehouse_id = $3
+			), 0)
+		`, tenantID, productID, warehouseID).Scan(&quantity)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get location stock: %w", err)
+	}
+	return quantity, nil
+}
+
+// listProductLocations returns the locations holding the product in a warehouse, in path order.
+func listProductLocations(ctx context.Context, q dbtx, tenantID, productID, warehouseID uuid.UUID) ([]domain.LocationBalance, error) {
+	rows, err := q.Query(ctx, `
+		SELECT slb.location_id, wl.path, slb.product_id, p.name, p.sku, slb.quantity
+		FROM stock_location_balances slb
+		JOIN warehouse_locations wl ON wl.id = slb.location_id
+		JOIN products p ON p.id = slb.product_id
+		WHERE slb.tenant_id = $1 AND slb.product_id = $2 AND wl.warehouse_id = $3 AND slb.quantity > 0
+		ORDER BY wl.path
+	`, tenantID, productID, warehouseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list product locations: %w", err)
+	}
+	return scanLocationBalances(rows)
+}
+
+func scanLocationBalances(rows pgx.Rows) ([]domain.LocationBalance, error) {
+	defer rows.Close()
+
+	balances := []domain.LocationBalance{}
+	for rows.Next() {
+		var b domain.LocationBalance
+		if err := rows.Scan(&b.LocationID, &b.LocationPath, &b.ProductID, &b.ProductName, &b.SKU, &b.Quantity); err != nil {
+			return nil, fmt.Errorf("failed to scan location balance: %w", err)
+		}
+		balances = append(balances, b)
+	}
+	return balances, rows.Err()
+}
@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"sancaksoft/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// lotBalanceSelect reads the trigger-maintained lot balances of tenant $1 with lot,
+// product and warehouse details. Callers append further conditions and the ordering.
+const lotBalanceSelect = `
+	SELECT l.id, l.lot_number, l.expiry_date, l.expiry_date - CURRENT_DATE,
+	       l.product_id, p.name, p.sku, w.id, w.name, slb.quantity
+	FROM stock_lot_balances slb
+	JOIN lots l ON l.id = slb.lot_id AND l.tenant_id = slb.tenant_id
+	JOIN products p ON p.id = l.product_id
+	JOIN warehouses w ON w.id = slb.warehouse_id
+	WHERE slb.tenant_id = $1 AND slb.quantity <> 0
+`
+
+type LotRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewLotRepository(db *pgxpool.Pool) *LotRepository {
+	return &LotRepository{db: db}
+}
+
+// ListLotBalances returns non-zero lot balances, optionally for one product and/or warehouse.
+func (r *LotRepository) ListLotBalances(ctx context.Context, tenantID uuid.UUID, productID, warehouseID *uuid.UUID) ([]domain.LotBalance, error) {
+	rows, err := r.db.Query(ctx, lotBalanceSelect+`
+		  AND ($2::uuid IS NULL OR l.product_id = $2)
+		  AND ($3::uuid IS NULL OR slb.warehouse_id = $3)
+		ORDER BY p.name, l.expiry_date NULLS LAST, l.lot_number, w.name
+	`, tenantID, productID, warehouseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list lot balances: %w", err)
+	}
+	return scanLotBalances(rows)
+}
+
+// ListExpiringLots returns lots in stock whose expiry date is at most days from today,
+// including lots that have already expired.
+func (r *LotRepository) ListExpiringLots(ctx context.Context, tenantID uuid.UUID, warehouseID *uuid.UUID, days int) ([]domain.LotBalance, error) {
+	rows, err := r.db.Query(ctx, lotBalanceSelect+`
+		  AND slb.quantity > 0
+		  AND l.expiry_date IS NOT NULL AND l.expiry_date <= CURRENT_DATE + $3::int
+		  AND ($2::uuid IS NULL OR slb.warehouse_id = $2)
+		ORDER BY l.expiry_date, p.name, l.lot_number, w.name
+	`, tenantID, warehouseID, days)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list expiring lots: %w", err)
+	}
+	return scanLotBalances(rows)
+}
+
+// GetLotTrace returns a lot with every invoice and delivery note that shipped it, net of
+// cancellations. It returns nil when the lot does not exist.
+func (r *LotRepository) GetLotTrace(ctx context.Context, tenantID, lotID uuid.UUID) (*domain.LotTrace, error) {
+	var trace domain.LotTrace
+	err := r.db.QueryRow(ctx, `
+		SELECT l.id, l.tenant_id, l.product_id, l.lot_number, l.expiry_date, l.created_at, p.name, p.sku
+		FROM lots l
+		JOIN products p ON p.id = l.product_id
+		WHERE l.tenant_id = $1 AND l.id = $2
+	`, tenantID, lotID).Scan(
+		&trace.Lot.ID, &trace.Lot.TenantID, &trace.Lot.ProductID, &trace.Lot.LotNumber, &trace.Lot.ExpiryDate, &trace.Lot.CreatedAt,
+		&trace.ProductName, &trace.SKU,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get lot: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT c.id, c.name, sm.reference_type, sm.reference_id, COALESCE(i.invoice_number, dn.note_number),
+		       sm.warehouse_id, (-SUM(sm.quantity))::int, MIN(sm.created_at)
+		FROM stock_movements sm
+		LEFT JOIN invoices i ON sm.reference_type = 'INVOICE' AND i.id = sm.reference_id
+		LEFT JOIN delivery_notes dn ON sm.reference_type = 'DELIVERY_NOTE' AND dn.id = sm.reference_id
+		JOIN customers c ON c.id = COALESCE(i.customer_id, dn.customer_id)
+		WHERE sm.tenant_id = $1 AND sm.lot_id = $2 AND sm.reference_type IN ('INVOICE', 'DELIVERY_NOTE')
+		GROUP BY c.id, c.name, sm.reference_type, sm.reference_id, i.invoice_number, dn.note_number, sm.warehouse_id
+		HAVING SUM(sm.quantity) < 0
+		ORDER BY MIN(sm.created_at), 5
+	`, tenantID, lotID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to trace lot: %w", err)
+	}
+	defer rows.Close()
+
+	trace.Shipments = []domain.LotShipment{}
+	for rows.Next() {
+		var s domain.LotShipment
+		if err := rows.Scan(&s.CustomerID, &s.CustomerName, &s.DocumentType, &s.DocumentID, &s.DocumentNumber,
+			&s.WarehouseID, &s.Quantity, &s.ShippedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan lot shipment: %w", err)
+		}
+		trace.TotalShipped += s.Quantity
+		trace.Shipments = append(trace.Shipments, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to trace lot: %w", err)
+	}
+	return &trace, nil
+}
+
+// productTracksLots reports whether the product is lot-tracked; unknown products are not.
+func productTracksLots(ctx context.Context, q dbtx, tenantID, productID uuid.UUID) (bool, error) {
+	var tracked bool
+	err := q.QueryRow(ctx, `
+		SELECT COALESCE((SELECT track_lots FROM products WHERE tenant_id = $1 AND id = $2), FALSE)
+	`, tenantID, productID).Scan(&tracked)
+	if err != nil {
+		return false, fmt.Errorf("failed to check lot tracking of product %s: %w", productID, err)
+	}
+	return tracked, nil
+}
+
+// ensureLot returns the product's lot with the given number, creating it on first receipt.
+// An expiry date is only recorded if the lot does not have one yet.
+func ensureLot(ctx context.Context, q dbtx, tenantID, productID uuid.UUID, lotNumber string, expiryDate *time.Time) (*domain.Lot, error) {
+	lot := domain.Lot{TenantID: tenantID, ProductID: productID}
+	err := q.QueryRow(ctx, `
+		INSERT INTO lots (id, tenant_id, product_id, lot_number, expiry_date, created_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())
+		ON CONFLICT (tenant_id, product_id, lot_number) DO UPDATE
+		SET expiry_date = COALESCE(lots.expiry_date, EXCLUDED.expiry_date)
+		RETURNING id, lot_number, expiry_date, created_at
+	`, uuid.New(), tenantID, productID, lotNumber, expiryDate).Scan(&lot.ID, &lot.LotNumber, &lot.ExpiryDate, &lot.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record lot %s: %w", lotNumber, err)
+	}
+	return &lot, nil
+}
+
+// listAvailableLots returns the product's lots in stock in a warehouse in first-expiry-first-out
+// order; lots without an expiry date come last, oldest first.
+func listAvailableLots(ctx context.Context, q dbtx, tenantID, productID, warehouseID uuid.UUID) ([]domain.LotBalance, error) {
+	rows, err := q.Query(ctx, lotBalanceSelect+`
+		  AND l.product_id = $2 AND slb.warehouse_id = $3 AND slb.quantity > 0
+		ORDER BY l.expiry_date NULLS LAST, l.created_at, l.lot_number
+	`, tenantID, productID, warehouseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list available lots: %w", err)
+	}
+	return scanLotBalances(rows)
+}
+
+func scanLotBalances(rows pgx.Rows) ([]domain.LotBalance, error) {
+	defer rows.Close()
+
+	balances := []domain.LotBalance{}
+	for rows.Next() {
+		var b domain.LotBalance
+		if err := rows.Scan(&b.LotID, &b.LotNumber, &b.ExpiryDate, &b.DaysToExpiry,
+			&b.ProductID, &b.ProductName, &b.SKU, &b.WarehouseID, &b.WarehouseName, &b.Quantity); err != nil {
+			return nil, fmt.Errorf("failed to scan lot balance: %w", err)
+		}
+		balances = append(balances, b)
+	}
+	return balances, rows.Err()
+}
@@ -3,14 +3,25 @@ package repository
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
 
 	"sancaksoft/internal/domain"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shopspring/decimal"
 )
 
+const productColumns = `id, tenant_id, name, sku, barcode, unit, price, vat_rate, standard_cost, track_lots, track_serials, category_id, brand_id, parent_id, variant_key, created_at, updated_at`
+
+func scanProduct(row pgx.Row, p *domain.Product) error {
+	return row.Scan(
+		&p.ID, &p.TenantID, &p.Name, &p.SKU, &p.Barcode, &p.Unit, &p.Price, &p.VATRate, &p.StandardCost, &p.TrackLots, &p.TrackSerials, &p.CategoryID, &p.BrandID, &p.ParentID, &p.VariantKey, &p.CreatedAt, &p.UpdatedAt,
+	)
+}
+
 type ProductRepository struct {
 	db *pgxpool.Pool
 }
@@ -20,13 +31,13 @@ func NewProductRepository(db *pgxpool.Pool) *ProductRepository {
 }
 
 // CreateProduct inserts a new product.
-func (r *ProductRepository) CreateProduct(ctx context.Context, product *domain.Product) error {
+func (r *ProductRepository) CreateProduct(ctx context.Context, tx pgx.Tx, product *domain.Product) error {
 	query := `
-		INSERT INTO products (id, tenant_id, name, sku, barcode, unit, price, vat_rate, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW(), NOW())
+		INSERT INTO products (id, tenant_id, name, sku, barcode, unit, price, vat_rate, standard_cost, track_lots, track_serials, category_id, brand_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, NOW(), NOW())
 		RETURNING created_at, updated_at
 	`
-	return r.db.QueryRow(ctx, query,
+	return tx.QueryRow(ctx, query,
 		product.ID,
 		product.TenantID,
 		product.Name,
@@ -35,22 +46,28 @@ func (r *ProductRepository) CreateProduct(ctx context.Context, product *domain.P
 		product.Unit,
 		product.Price,
 		product.VATRate,
+		product.StandardCost,
+		product.TrackLots,
+		product.TrackSerials,
+		product.CategoryID,
+		product.BrandID,
 	).Scan(&product.CreatedAt, &product.UpdatedAt)
 }
 
+// CreateAuditLog records a change to a product.
+func (r *ProductRepository) CreateAuditLog(ctx context.Context, tx pgx.Tx, log *domain.AuditLog) error {
+	return createAuditLog(ctx, r.db, tx, log)
+}
+
 // GetProductByID retrieves a product by ID and TenantID.
 func (r *ProductRepository) GetProductByID(ctx context.Context, tenantID, productID uuid.UUID) (*domain.Product, error) {
 	query := `
-		SELECT id, tenant_id, name, sku, barcode, unit, price, vat_rate, created_at, updated_at
+		SELECT ` + productColumns + `
 		FROM products
 		WHERE id = $1 AND tenant_id = $2 AND deleted_at IS NULL
 	`
-	row := r.db.QueryRow(ctx, query, productID, tenantID)
-
 	var p domain.Product
-	err := row.Scan(
-		&p.ID, &p.TenantID, &p.Name, &p.SKU, &p.Barcode, &p.Unit, &p.Price, &p.VATRate, &p.CreatedAt, &p.UpdatedAt,
-	)
+	err := scanProduct(r.db.QueryRow(ctx, query, productID, tenantID), &p)
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return nil, nil // Not found
@@ -60,17 +77,65 @@ func (r *ProductRepository) GetProductByID(ctx context.Context, tenantID, produc
 	return &p, nil
 }
 
-// ListProducts retrieves a list of products for a tenant.
-// TODO: Add pagination and filtering.
-func (r *ProductRepository) ListProducts(ctx context.Context, tenantID uuid.UUID) ([]domain.Product, error) {
+// LockProduct locks a product row for an update in tx and returns it; nil if it does not
+// exist.
+func (r *ProductRepository) LockProduct(ctx context.Context, tx pgx.Tx, tenantID, productID uuid.UUID) (*domain.Product, error) {
 	query := `
-		SELECT id, tenant_id, name, sku, barcode, unit, price, vat_rate, created_at, updated_at
+		SELECT ` + productColumns + `
 		FROM products
-		WHERE tenant_id = $1 AND deleted_at IS NULL
-		ORDER BY created_at DESC
+		WHERE id = $1 AND tenant_id = $2 AND deleted_at IS NULL
+		FOR UPDATE
+	`
+	var p domain.Product
+	err := scanProduct(tx.QueryRow(ctx, query, productID, tenantID), &p)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to lock product: %w", err)
+	}
+	return &p, nil
+}
+
+// ListProducts retrieves a list of products for a tenant, narrowed by the filter.
+// TODO: Add pagination.
+func (r *ProductRepository) ListProducts(ctx context.Context, filter domain.ProductFilter) ([]domain.Product, error) {
+	args := []any{filter.TenantID, filter.CategoryID, filter.BrandID, filter.ParentID}
+	var attrConds strings.Builder
+	for _, code := range sortedKeys(filter.Attributes) {
+		// Number values are stored normalized, so "10.50" also finds 10.5
+		value := filter.Attributes[code]
+		number := value
+		if d, err := decimal.NewFromString(strings.TrimSpace(value)); err == nil {
+			number = d.String()
+		}
+		args = append(args, code, value, number)
+		fmt.Fprintf(&attrConds, `
+			AND EXISTS (
+				SELECT 1 FROM product_attribute_values v
+				JOIN category_attributes a ON a.id = v.attribute_id
+				WHERE v.product_id = p.id AND a.code = $%d
+					AND (lower(v.value) = lower($%d) OR (a.data_type = 'NUMBER' AND v.value = $%d))
+			)`, len(args)-2, len(args)-1, len(args))
+	}
+	query := `
+		SELECT p.id, p.tenant_id, p.name, p.sku, p.barcode, p.unit, p.price, p.vat_rate, p.standard_cost, p.track_lots, p.track_serials, p.category_id, p.brand_id, p.parent_id, p.variant_key, p.created_at, p.updated_at
+		FROM products p
+		WHERE p.tenant_id = $1 AND p.deleted_at IS NULL
+			AND ($2::uuid IS NULL OR p.category_id IN (
+				WITH RECURSIVE subtree AS (
+					SELECT id FROM product_categories WHERE tenant_id = $1 AND id = $2
+					UNION ALL
+					SELECT c.id FROM product_categories c JOIN subtree st ON c.parent_id = st.id
+				)
+				SELECT id FROM subtree
+			))
+			AND ($3::uuid IS NULL OR p.brand_id = $3)
+			AND ($4::uuid IS NULL OR p.parent_id = $4)` + attrConds.String() + `
+		ORDER BY p.created_at DESC
 		LIMIT 100
 	`
-	rows, err := r.db.Query(ctx, query, tenantID)
+	rows, err := r.db.Query(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list products: %w", err)
 	}
@@ -79,12 +144,108 @@ func (r *ProductRepository) ListProducts(ctx context.Context, tenantID uuid.UUID
 	var products []domain.Product
 	for rows.Next() {
 		var p domain.Product
-		if err := rows.Scan(
-			&p.ID, &p.TenantID, &p.Name, &p.SKU, &p.Barcode, &p.Unit, &p.Price, &p.VATRate, &p.CreatedAt, &p.UpdatedAt,
-		); err != nil {
+		if err := scanProduct(rows, &p); err != nil {
 			return nil, fmt.Errorf("failed to scan product: %w", err)
 		}
 		products = append(products, p)
 	}
 	return products, nil
 }
+
+// SetStandardCost sets or clears (nil) a product's standard cost. It reports whether the product exists.
+func (r *ProductRepository) SetStandardCost(ctx context.Context, tx pgx.Tx, tenantID, productID uuid.UUID, cost *decimal.Decimal) (bool, error) {
+	tag, err := tx.Exec(ctx, `
+		UPDATE products SET standard_cost = $3, updated_at = NOW()
+		WHERE tenant_id = $1 AND id = $2 AND deleted_at IS NULL
+	`, tenantID, productID, cost)
+	if err != nil {
+		return false, fmt.Errorf("failed to set standard cost: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// SetTrackLots turns lot tracking of a product on or off. It reports whether the product exists.
+func (r *ProductRepository) SetTrackLots(ctx context.Context, tx pgx.Tx, tenantID, productID uuid.UUID, trackLots bool) (bool, error) {
+	tag, err := tx.Exec(ctx, `
+		UPDATE products SET track_lots = $3, updated_at = NOW()
+		WHERE tenant_id = $1 AND id = $2 AND deleted_at IS NULL
+	`, tenantID, productID, trackLots)
+	if err != nil {
+		return false, fmt.Errorf("failed to set lot tracking: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// SetTrackSerials turns serial number tracking of a product on or off. It reports whether the product exists.
+func (r *ProductRepository) SetTrackSerials(ctx context.Context, tx pgx.Tx, tenantID, productID uuid.UUID, trackSerials bool) (bool, error) {
+	tag, err := tx.Exec(ctx, `
+		UPDATE products SET track_serials = $3, updated_at = NOW()
+		WHERE tenant_id = $1 AND id = $2 AND deleted_at IS NULL
+	`, tenantID, productID, trackSerials)
+	if err != nil {
+		return false, fmt.Errorf("failed to set serial tracking: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// HasStock reports whether the product has stock, positive or negative, in any warehouse.
+func (r *ProductRepository) HasStock(ctx context.Context, tx pgx.Tx, tenantID, productID uuid.UUID) (bool, error) {
+	var hasStock bool
+	err := tx.QueryRow(ctx, `
+		SELECT EXISTS (SELECT 1 FROM stock_balances WHERE tenant_id = $1 AND product_id = $2 AND quantity <> 0)
+	`, tenantID, productID).Scan(&hasStock)
+	if err != nil {
+		return false, fmt.Errorf("failed to check product stock: %w", err)
+	}
+	return hasStock, nil
+}
+
+// ListProductAttributes returns the attribute values of the given products by product and code.
+func (r *ProductRepository) ListProductAttributes(ctx context.Context, tenantID uuid.UUID, productIDs []uuid.UUID) (map[uuid.UUID]map[string]string, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT v.product_id, a.code, v.value
+		FROM product_attribute_values v
+		JOIN category_attributes a ON a.id = v.attribute_id
+		WHERE v.tenant_id = $1 AND v.product_id = ANY($2)
+	`, tenantID, productIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list product attributes: %w", err)
+	}
+	defer rows.Close()
+
+	values := make(map[uuid.UUID]map[string]string)
+	for rows.Next() {
+		var productID uuid.UUID
+		var code, value string
+		if err := rows.Scan(&productID, &code, &value); err != nil {
+			return nil, fmt.Errorf("failed to scan product attribute: %w", err)
+		}
+		if values[productID] == nil {
+			values[productID] = make(map[string]string)
+		}
+		values[productID][code] = value
+	}
+	return values, rows.Err()
+}
+
+// ClassificationExists reports whether the category and brand, each optional, exist for the tenant.
+func (r *ProductRepository) ClassificationExists(ctx context.Context, tenantID uuid.UUID, categoryID, brandID *uuid.UUID) (bool, error) {
+	var exists bool
+	err := r.db.QueryRow(ctx, `
+		SELECT ($2::uuid IS NULL OR EXISTS (SELECT 1 FROM product_categories WHERE tenant_id = $1 AND id = $2))
+		   AND ($3::uuid IS NULL OR EXISTS (SELECT 1 FROM brands WHERE tenant_id = $1 AND id = $3))
+	`, tenantID, categoryID, brandID).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check product category and brand: %w", err)
+	}
+	return exists, nil
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,429 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"sancaksoft/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const purchaseOrderColumns = `
+	id, tenant_id, supplier_id, warehouse_id, order_number, status, expected_date, COALESCE(note, ''),
+	total_amount, ordered_at, closed_at, created_at, updated_at
+`
+
+type PurchaseOrderRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewPurchaseOrderRepository(db *pgxpool.Pool) *PurchaseOrderRepository {
+	return &PurchaseOrderRepository{db: db}
+}
+
+// CreateAuditLog records a change to a purchase order, its receipts or invoices.
+func (r *PurchaseOrderRepository) CreateAuditLog(ctx context.Context, tx pgx.Tx, log *domain.AuditLog) error {
+	return createAuditLog(ctx, r.db, tx, log)
+}
+
+func scanPurchaseOrder(row pgx.Row, o *domain.PurchaseOrder) error {
+	return row.Scan(
+		&o.ID, &o.TenantID, &o.SupplierID, &o.WarehouseID, &o.OrderNumber, &o.Status, &o.ExpectedDate, &o.Note,
+		&o.TotalAmount, &o.OrderedAt, &o.ClosedAt, &o.CreatedAt, &o.UpdatedAt,
+	)
+}
+
+// GenerateNextOrderNumber generates the next sequential purchase order number atomically.
+func (r *PurchaseOrderRepository) GenerateNextOrderNumber(ctx context.Context, tx pgx.Tx, tenantID uuid.UUID) (string, error) {
+	return nextDocumentNumber(ctx, tx, tenantID, "PURCHASE_ORDER", "PO")
+}
+
+// GenerateNextReceiptNumber generates the next sequential goods receipt number atomically.
+func (r *PurchaseOrderRepository) GenerateNextReceiptNumber(ctx context.Context, tx pgx.Tx, tenantID uuid.UUID) (string, error) {
+	return nextDocumentNumber(ctx, tx, tenantID, "GOODS_RECEIPT", "GRN")
+}
+
+// SupplierExists reports whether the supplier belongs to the tenant.
+func (r *PurchaseOrderRepository) SupplierExists(ctx context.Context, tx pgx.Tx, tenantID, supplierID uuid.UUID) (bool, error) {
+	var exists bool
+	err := tx.QueryRow(ctx, `
+		SELECT EXISTS(SELECT 1 FROM suppliers WHERE tenant_id = $1 AND id = $2 AND deleted_at IS NULL)
+	`, tenantID, supplierID).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check supplier: %w", err)
+	}
+	return exists, nil
+}
+
+// ProductExists reports whether the product belongs to the tenant.
+func (r *PurchaseOrderRepository) ProductExists(ctx context.Context, tx pgx.Tx, tenantID, productID uuid.UUID) (bool, error) {
+	var exists bool
+	err := tx.QueryRow(ctx, `
+		SELECT EXISTS(SELECT 1 FROM products WHERE tenant_id = $1 AND id = $2 AND deleted_at IS NULL)
+	`, tenantID, productID).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check product: %w", err)
+	}
+	return exists, nil
+}
+
+// WarehouseExists reports whether the warehouse belongs to the tenant.
+func (r *PurchaseOrderRepository) WarehouseExists(ctx context.Context, tx pgx.Tx, tenantID, warehouseID uuid.UUID) (bool, error) {
+	var exists bool
+	err := tx.QueryRow(ctx, `
+		SELECT EXISTS(SELECT 1 FROM warehouses WHERE tenant_id = $1 AND id = $2 AND deleted_at IS NULL)
+	`, tenantID, warehouseID).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check warehouse: %w", err)
+	}
+	return exists, nil
+}
+
+// CreatePurchaseOrder inserts the order header.
+func (r *PurchaseOrderRepository) CreatePurchaseOrder(ctx context.Context, tx pgx.Tx, o *domain.PurchaseOrder) error {
+	query := `
+		INSERT INTO purchase_orders (id, tenant_id, supplier_id, warehouse_id, order_number, status, expected_date, note, total_amount, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW(), NOW())
+		RETURNING created_at, updated_at
+	`
+	return tx.QueryRow(ctx, query,
+		o.ID,
+		o.TenantID,
+		o.SupplierID,
+		o.WarehouseID,
+		o.OrderNumber,
+		o.Status,
+		o.ExpectedDate,
+		o.Note,
+		o.TotalAmount,
+	).Scan(&o.CreatedAt, &o.UpdatedAt)
+}
+
+// CreatePurchaseOrderItem inserts an order line.
+func (r *PurchaseOrderRepository) CreatePurchaseOrderItem(ctx context.Context, tx pgx.Tx, item *domain.PurchaseOrderItem) error {
+	query := `
+		INSERT INTO purchase_order_items (id, tenant_id, order_id, product_id, quantity, received_qty, unit_price, total, created_at)
+		VALUES ($1, $2, $3, $4, $5, 0, $6, $7, NOW())
+		RETURNING created_at
+	`
+	return tx.QueryRow(ctx, query,
+		item.ID,
+		item.TenantID,
+		item.OrderID,
+		item.ProductID,
+		item.Quantity,
+		item.UnitPrice,
+		item.Total,
+	).Scan(&item.CreatedAt)
+}
+
+// GetPurchaseOrder returns the order with its lines, or nil if not found.
+func (r *PurchaseOrderRepository) GetPurchaseOrder(ctx context.Context, tenantID, orderID uuid.UUID) (*domain.PurchaseOrder, error) {
+	return r.getPurchaseOrder(ctx, r.db, tenantID, orderID, false)
+}
+
+// GetPurchaseOrderForUpdate returns the order with its lines and locks the header row.
+func (r *PurchaseOrderRepository) GetPurchaseOrderForUpdate(ctx context.Context, tx pgx.Tx, tenantID, orderID uuid.UUID) (*domain.PurchaseOrder, error) {
+	return r.getPurchaseOrder(ctx, tx, tenantID, orderID, true)
+}
+
+func (r *PurchaseOrderRepository) getPurchaseOrder(ctx context.Context, q dbtx, tenantID, orderID uuid.UUID, forUpdate bool) (*domain.PurchaseOrder, error) {
+	query := `SELECT ` + purchaseOrderColumns + `
+		FROM purchase_orders
+		WHERE tenant_id = $1 AND id = $2 AND deleted_at IS NULL
+	`
+	if forUpdate {
+		query += ` FOR UPDATE`
+	}
+
+	var o domain.PurchaseOrder
+	if err := scanPurchaseOrder(q.QueryRow(ctx, query, tenantID, orderID), &o); err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get purchase order: %w", err)
+	}
+
+	rows, err := q.Query(ctx, `
+		SELECT id, tenant_id, order_id, product_id, quantity, received_qty, unit_price, total, created_at
+		FROM purchase_order_items
+		WHERE tenant_id = $1 AND order_id = $2
+		ORDER BY created_at, id
+	`, tenantID, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get purchase order items: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var it domain.PurchaseOrderItem
+		if err := rows.Scan(
+			&it.ID, &it.TenantID, &it.OrderID, &it.ProductID, &it.Quantity, &it.ReceivedQty, &it.UnitPrice, &it.Total, &it.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan purchase order item: %w", err)
+		}
+		o.Items = append(o.Items, it)
+	}
+	return &o, rows.Err()
+}
+
+// UpdatePurchaseOrderStatus persists status and lifecycle timestamps.
+func (r *PurchaseOrderRepository) UpdatePurchaseOrderStatus(ctx context.Context, tx pgx.Tx, o *domain.PurchaseOrder) error {
+	query := `
+		UPDATE purchase_orders
+		SET status = $3, ordered_at = $4, closed_at = $5, updated_at = NOW()
+		WHERE tenant_id = $1 AND id = $2
+		RETURNING updated_at
+	`
+	return tx.QueryRow(ctx, query, o.TenantID, o.ID, o.Status, o.OrderedAt, o.ClosedAt).Scan(&o.UpdatedAt)
+}
+
+// ListPurchaseOrders lists order headers, optionally filtered by supplier and status.
+func (r *PurchaseOrderRepository) ListPurchaseOrders(ctx context.Context, tenantID uuid.UUID, supplierID *uuid.UUID, status domain.PurchaseOrderStatus) ([]domain.PurchaseOrder, error) {
+	query := `SELECT ` + purchaseOrderColumns + `
+		FROM purchase_orders
+		WHERE tenant_id = $1 AND deleted_at IS NULL
+		  AND ($2::uuid IS NULL OR supplier_id = $2)
+		  AND ($3 = '' OR status = $3)
+		ORDER BY created_at DESC
+		LIMIT 100
+	`
+	rows, err := r.db.Query(ctx, query, tenantID, supplierID, string(status))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list purchase orders: %w", err)
+	}
+	defer rows.Close()
+
+	orders := []domain.PurchaseOrder{}
+	for rows.Next() {
+		var o domain.PurchaseOrder
+		if err := scanPurchaseOrder(rows, &o); err != nil {
+			return nil, fmt.Errorf("failed to scan purchase order: %w", err)
+		}
+		orders = append(orders, o)
+	}
+	return orders, rows.Err()
+}
+
+// CreateGoodsReceipt inserts the receipt header.
+func (r *PurchaseOrderRepository) CreateGoodsReceipt(ctx context.Context, tx pgx.Tx, gr *domain.GoodsReceipt) error {
+	query := `
+		INSERT INTO goods_receipts (id, tenant_id, order_id, warehouse_id, receipt_number, note, received_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW())
+		RETURNING received_at
+	`
+	return tx.QueryRow(ctx, query,
+		gr.ID,
+		gr.TenantID,
+		gr.OrderID,
+		gr.WarehouseID,
+		gr.ReceiptNumber,
+		gr.Note,
+	).Scan(&gr.ReceivedAt)
+}
+
+// CreateGoodsReceiptItem inserts a receipt line.
+func (r *PurchaseOrderRepository) CreateGoodsReceiptItem(ctx context.Context, tx pgx.Tx, item *domain.GoodsReceiptItem) error {
+	_, err := tx.Exec(ctx, `
+		INSERT INTO goods_receipt_items (id, tenant_id, receipt_id, order_item_id, product_id, quantity, unit_cost)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`, item.ID, item.TenantID, item.ReceiptID, item.OrderItemID, item.ProductID, item.Quantity, item.UnitCost)
+	return err
+}
+
+// AddReceivedQuantity increases the received quantity of an order line.
+func (r *PurchaseOrderRepository) AddReceivedQuantity(ctx context.Context, tx pgx.Tx, tenantID, itemID uuid.UUID, quantity int) error {
+	_, err := tx.Exec(ctx, `
+		UPDATE purchase_order_items
+		SET received_qty = received_qty + $3
+		WHERE tenant_id = $1 AND id = $2
+	`, tenantID, itemID, quantity)
+	return err
+}
+
+// ProductTracksLots reports whether receipts of the product need a lot number.
+func (r *PurchaseOrderRepository) ProductTracksLots(ctx context.Context, tx pgx.Tx, tenantID, productID uuid.UUID) (bool, error) {
+	return productTracksLots(ctx, tx, tenantID, productID)
+}
+
+// EnsureLot returns the product's lot with the given number, creating it on first receipt.
+func (r *PurchaseOrderRepository) EnsureLot(ctx context.Context, tx pgx.Tx, tenantID, productID uuid.UUID, lotNumber string, expiryDate *time.Time) (*domain.Lot, error) {
+	return ensureLot(ctx, tx, tenantID, productID, lotNumber, expiryDate)
+}
+
+// GetLocation returns a warehouse location, or nil if not found.
+func (r *PurchaseOrderRepository) GetLocation(ctx context.Context, tx pgx.Tx, tenantID, locationID uuid.UUID) (*domain.WarehouseLocation, error) {
+	return getLocation(ctx, tx, tenantID, locationID)
+}
+
+// ProductIsVariantParent reports whether the product's stock is kept on its variants.
+func (r *PurchaseOrderRepository) ProductIsVariantParent(ctx context.Context, tx pgx.Tx, tenantID, productID uuid.UUID) (bool, error) {
+	return productIsVariantParent(ctx, tx, tenantID, productID)
+}
+
+// ProductIsKit reports whether the product is sold as a kit of other products.
+func (r *PurchaseOrderRepository) ProductIsKit(ctx context.Context, tx pgx.Tx, tenantID, productID uuid.UUID) (bool, error) {
+	return productIsKit(ctx, tx, tenantID, productID)
+}
+
+// ProductTracksSerials reports whether every unit of the product moves under a serial number.
+func (r *PurchaseOrderRepository) ProductTracksSerials(ctx context.Context, tx pgx.Tx, tenantID, productID uuid.UUID) (bool, error) {
+	return productTracksSerials(ctx, tx, tenantID, productID)
+}
+
+// MoveSerials moves the serial numbers of an inserted movement and returns the first one
+// that is not where the movement needs it.
+func (r *PurchaseOrderRepository) MoveSerials(ctx context.Context, tx pgx.Tx, movement *domain.StockMovement) (string, error) {
+	return moveSerials(ctx, tx, movement)
+}
+
+// CreateStockMovement inserts a stock movement within a transaction.
+func (r *PurchaseOrderRepository) CreateStockMovement(ctx context.Context, tx pgx.Tx, movement *domain.StockMovement) error {
+	query := `
+		INSERT INTO stock_movements (
+			id, tenant_id, product_id, warehouse_id,
+			quantity, type, reference_id, reference_type, unit_cost, lot_id, location_id, created_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, NOW())
+	`
+	_, err := tx.Exec(ctx, query,
+		movement.ID,
+		movement.TenantID,
+		movement.ProductID,
+		movement.WarehouseID,
+		movement.Quantity,
+		movement.Type,
+		movement.ReferenceID,
+		movement.ReferenceType,
+		movement.UnitCost,
+		movement.LotID,
+		movement.LocationID,
+	)
+	return err
+}
+
+// CreatePurchaseInvoice inserts the supplier invoice header.
+func (r *PurchaseOrderRepository) CreatePurchaseInvoice(ctx context.Context, tx pgx.Tx, inv *domain.PurchaseInvoice) error {
+	query := `
+		INSERT INTO purchase_invoices (id, tenant_id, order_id, supplier_id, invoice_number, invoice_date, total_amount, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW())
+		RETURNING created_at
+	`
+	return tx.QueryRow(ctx, query,
+		inv.ID,
+		inv.TenantID,
+		inv.OrderID,
+		inv.SupplierID,
+		inv.InvoiceNumber,
+		inv.InvoiceDate,
+		inv.TotalAmount,
+	).Scan(&inv.CreatedAt)
+}
+
+// CreatePurchaseInvoiceItem inserts a supplier invoice line.
+func (r *PurchaseOrderRepository) CreatePurchaseInvoiceItem(ctx context.Context, tx pgx.Tx, item *domain.PurchaseInvoiceItem) error {
+	_, err := tx.Exec(ctx, `
+		INSERT INTO purchase_invoice_items (id, tenant_id, invoice_id, order_item_id, product_id, quantity, unit_price, total)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`, item.ID, item.TenantID, item.InvoiceID, item.OrderItemID, item.ProductID, item.Quantity, item.UnitPrice, item.Total)
+	return err
+}
+
+// PurchaseInvoiceNumberExists reports whether the supplier's invoice number was already recorded.
+func (r *PurchaseOrderRepository) PurchaseInvoiceNumberExists(ctx context.Context, tx pgx.Tx, tenantID, supplierID uuid.UUID, invoiceNumber string) (bool, error) {
+	var exists bool
+	err := tx.QueryRow(ctx, `
+		SELECT EXISTS(SELECT 1 FROM purchase_invoices WHERE tenant_id = $1 AND supplier_id = $2 AND invoice_number = $3)
+	`, tenantID, supplierID, invoiceNumber).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check purchase invoice number: %w", err)
+	}
+	return exists, nil
+}
+
+// ListMatchLines returns ordered, received and invoiced figures for every line of
+// non-draft, non-cancelled purchase orders, or of a single order when orderID is set.
+func (r *PurchaseOrderRepository) ListMatchLines(ctx context.Context, tenantID uuid.UUID, orderID *uuid.UUID) ([]domain.PurchaseMatchLine, error) {
+	query := `
+		SELECT po.id, po.order_number, poi.id, poi.product_id, COALESCE(p.name, ''),
+		       poi.quantity, poi.unit_price, poi.received_qty,
+		       COALESCE(inv.qty, 0)::int, COALESCE(inv.amount, 0), COALESCE(inv.min_price, 0), COALESCE(inv.max_price, 0)
+		FROM purchase_order_items poi
+		JOIN purchase_orders po ON po.id = poi.order_id AND po.tenant_id = poi.tenant_id
+		LEFT JOIN products p ON p.id = poi.product_id AND p.tenant_id = poi.tenant_id
+		LEFT JOIN LATERAL (
+			SELECT SUM(pii.quantity) AS qty, SUM(pii.total) AS amount,
+			       MIN(pii.unit_price) AS min_price, MAX(pii.unit_price) AS max_price
+			FROM purchase_invoice_items pii
+			WHERE pii.tenant_id = poi.tenant_id AND pii.order_item_id = poi.id
+		) inv ON TRUE
+		WHERE poi.tenant_id = $1
+		  AND po.deleted_at IS NULL
+		  AND po.status NOT IN ('DRAFT', 'CANCELLED')
+		  AND ($2::uuid IS NULL OR po.id = $2)
+		ORDER BY po.created_at, po.order_number, poi.created_at
+	`
+	rows, err := r.db.Query(ctx, query, tenantID, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list purchase match lines: %w", err)
+	}
+	defer rows.Close()
+
+	lines := []domain.PurchaseMatchLine{}
+	for rows.Next() {
+		var l domain.PurchaseMatchLine
+		if err := rows.Scan(
+			&l.OrderID, &l.OrderNumber, &l.OrderItemID, &l.ProductID, &l.ProductName,
+			&l.OrderedQty, &l.OrderedPrice, &l.ReceivedQty,
+			&l.InvoicedQty, &l.InvoicedAmount, &l.InvoicedMinPrice, &l.InvoicedMaxPrice,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan purchase match line: %w", err)
+		}
+		lines = append(lines, l)
+	}
+	return lines, rows.Err()
+}
+
+// ListOpenLines returns lines of ORDERED or PARTIALLY_RECEIVED purchase orders that still
+// expect goods, optionally filtered by product and warehouse.
+func (r *PurchaseOrderRepository) ListOpenLines(ctx context.Context, tenantID uuid.UUID, productID, warehouseID *uuid.UUID) ([]domain.OpenPurchaseLine, error) {
+	query := `
+		SELECT po.id, po.order_number, po.supplier_id, COALESCE(s.name, ''), po.warehouse_id,
+		       poi.product_id, COALESCE(p.name, ''), poi.quantity, poi.received_qty,
+		       poi.quantity - poi.received_qty, poi.unit_price, po.expected_date
+		FROM purchase_order_items poi
+		JOIN purchase_orders po ON po.id = poi.order_id AND po.tenant_id = poi.tenant_id
+		LEFT JOIN suppliers s ON s.id = po.supplier_id AND s.tenant_id = po.tenant_id
+		LEFT JOIN products p ON p.id = poi.product_id AND p.tenant_id = poi.tenant_id
+		WHERE poi.tenant_id = $1
+		  AND po.deleted_at IS NULL
+		  AND po.status IN ('ORDERED', 'PARTIALLY_RECEIVED')
+		  AND poi.quantity > poi.received_qty
+		  AND ($2::uuid IS NULL OR poi.product_id = $2)
+		  AND ($3::uuid IS NULL OR po.warehouse_id = $3)
+		ORDER BY p.name, po.expected_date NULLS LAST, po.order_number
+	`
+	rows, err := r.db.Query(ctx, query, tenantID, productID, warehouseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list open purchase lines: %w", err)
+	}
+	defer rows.Close()
+
+	lines := []domain.OpenPurchaseLine{}
+	for rows.Next() {
+		var l domain.OpenPurchaseLine
+		if err := rows.Scan(
+			&l.OrderID, &l.OrderNumber, &l.SupplierID, &l.SupplierName, &l.WarehouseID,
+			&l.ProductID, &l.ProductName, &l.OrderedQty, &l.ReceivedQty,
+			&l.RemainingQty, &l.UnitPrice, &l.ExpectedDate,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan open purchase line: %w", err)
+		}
+		lines = append(lines, l)
+	}
+	return lines, rows.Err()
+}
@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"sancaksoft/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const quotationColumns = `
+	id, tenant_id, customer_id, warehouse_id, quote_number, revision, status, valid_until,
+	COALESCE(note, ''), total_amount, sales_order_id, invoice_id, created_at, updated_at
+`
+
+type QuotationRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewQuotationRepository(db *pgxpool.Pool) *QuotationRepository {
+	return &QuotationRepository{db: db}
+}
+
+// CreateAuditLog records a change to a quotation.
+func (r *QuotationRepository) CreateAuditLog(ctx context.Context, tx pgx.Tx, log *domain.AuditLog) error {
+	return createAuditLog(ctx, r.db, tx, log)
+}
+
+func scanQuotation(row pgx.Row, q *domain.Quotation) error {
+	return row.Scan(
+		&q.ID, &q.TenantID, &q.CustomerID, &q.WarehouseID, &q.QuoteNumber, &q.Revision, &q.Status, &q.ValidUntil,
+		&q.Note, &q.TotalAmount, &q.SalesOrderID, &q.InvoiceID, &q.CreatedAt, &q.UpdatedAt,
+	)
+}
+
+// GenerateNextQuoteNumber generates the next sequential quote number atomically.
+func (r *QuotationRepository) GenerateNextQuoteNumber(ctx context.Context, tx pgx.Tx, tenantID uuid.UUID) (string, error) {
+	return nextDocumentNumber(ctx, tx, tenantID, "QUOTATION", "QT")
+}
+
+// ExpireQuotations marks SENT quotations whose validity date has passed as EXPIRED.
+func (r *QuotationRepository) ExpireQuotations(ctx context.Context, tenantID uuid.UUID) error {
+	_, err := r.db.Exec(ctx, `
+		UPDATE quotations
+		SET status = 'EXPIRED', updated_at = NOW()
+		WHERE tenant_id = $1 AND status = 'SENT' AND valid_until < CURRENT_DATE AND deleted_at IS NULL
+	`, tenantID)
+	if err != nil {
+		return fmt.Errorf("failed to expire quotations: %w", err)
+	}
+	return nil
+}
+
+// CreateQuotation inserts the quotation header.
+func (r *QuotationRepository) CreateQuotation(ctx context.Context, tx pgx.Tx, q *domain.Quotation) error {
+	query := `
+		INSERT INTO quotations (id, tenant_id, customer_id, warehouse_id, quote_number, revision, status, valid_until, note, total_amount, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NOW(), NOW())
+		RETURNING created_at, updated_at
+	`
+	return tx.QueryRow(ctx, query,
+		q.ID,
+		q.TenantID,
+		q.CustomerID,
+		q.WarehouseID,
+		q.QuoteNumber,
+		q.Revision,
+		q.Status,
+		q.ValidUntil,
+		q.Note,
+		q.TotalAmount,
+	).Scan(&q.CreatedAt, &q.UpdatedAt)
+}
+
+// CreateQuotationItem inserts a quotation line.
+func (r *QuotationRepository) CreateQuotationItem(ctx context.Context, tx pgx.Tx, item *domain.QuotationItem) error {
+	query := `
+		INSERT INTO quotation_items (id, tenant_id, quotation_id, product_id, quantity, unit_price, total, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW())
+		RETURNING created_at
+	`
+	return tx.QueryRow(ctx, query,
+		item.ID,
+		item.TenantID,
+		item.QuotationID,
+		item.ProductID,
+		item.Quantity,
+		item.UnitPrice,
+		item.Total,
+	).Scan(&item.CreatedAt)
+}
+
+// GetQuotation returns the quotation with its lines, or nil if not found.
+func (r *QuotationRepository) GetQuotation(ctx context.Context, tenantID, quotationID uuid.UUID) (*domain.Quotation, error) {
+	return r.getQuotation(ctx, r.db, tenantID, quotationID, false)
+}
+
+// GetQuotationForUpdate returns the quotation with its lines and locks the header row.
+func (r *QuotationRepository) GetQuotationForUpdate(ctx context.Context, tx pgx.Tx, tenantID, quotationID uuid.UUID) (*domain.Quotation, error) {
+	return r.getQuotation(ctx, tx, tenantID, quotationID, true)
+}
+
+func (r *QuotationRepository) getQuotation(ctx context.Context, q dbtx, tenantID, quotationID uuid.UUID, forUpdate bool) (*domain.Quotation, error) {
+	query := `SELECT ` + quotationColumns + `
+		FROM quotations
+		WHERE tenant_id = $1 AND id = $2 AND deleted_at IS NULL
+	`
+	if forUpdate {
+		query += ` FOR UPDATE`
+	}
+
+	var quote domain.Quotation
+	if err := scanQuotation(q.QueryRow(ctx, query, tenantID, quotationID), &quote); err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get quotation: %w", err)
+	}
+
+	rows, err := q.Query(ctx, `
+		SELECT id, tenant_id, quotation_id, product_id, quantity, unit_price, total, created_at
+		FROM quotation_items
+		WHERE tenant_id = $1 AND quotation_id = $2
+		ORDER BY created_at, id
+	`, tenantID, quotationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get quotation items: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var it domain.QuotationItem
+		if err := rows.Scan(
+			&it.ID, &it.TenantID, &it.QuotationID, &it.ProductID, &it.Quantity, &it.UnitPrice, &it.Total, &it.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan quotation item: %w", err)
+		}
+		quote.Items = append(quote.Items, it)
+	}
+	return &quote, rows.Err()
+}
+
+// UpdateQuotationStatus persists status and conversion links.
+func (r *QuotationRepository) UpdateQuotationStatus(ctx context.Context, tx pgx.Tx, q *domain.Quotation) error {
+	query := `
+		UPDATE quotations
+		SET status = $3, sales_order_id = $4, invoice_id = $5, updated_at = NOW()
+		WHERE tenant_id = $1 AND id = $2
+		RETURNING updated_at
+	`
+	return tx.QueryRow(ctx, query,
+		q.TenantID,
+		q.ID,
+		q.Status,
+		q.SalesOrderID,
+		q.InvoiceID,
+	).Scan(&q.UpdatedAt)
+}
+
+// ListQuotations lists quotation headers, optionally filtered by status.
+func (r *QuotationRepository) ListQuotations(ctx context.Context, tenantID uuid.UUID, status domain.QuotationStatus) ([]domain.Quotation, error) {
+	query := `SELECT ` + quotationColumns + `
+		FROM quotations
+		WHERE tenant_id = $1 AND deleted_at IS NULL AND ($2 = '' OR status = $2)
+		ORDER BY created_at DESC
+		LIMIT 100
+	`
+	rows, err := r.db.Query(ctx, query, tenantID, string(status))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list quotations: %w", err)
+	}
+	defer rows.Close()
+
+	quotes := []domain.Quotation{}
+	for rows.Next() {
+		var q domain.Quotation
+		if err := scanQuotation(rows, &q); err != nil {
+			return nil, fmt.Errorf("failed to scan quotation: %w", err)
+		}
+		quotes = append(quotes, q)
+	}
+	return quotes, rows.Err()
+}
@@ -0,0 +1,173 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"sancaksoft/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// stockLevelStatusQuery joins every configured stock level of tenant $1 with on-hand,
+// reserved and on-order quantities, SALE quantities of the last $3 days and the latest
+// purchase order price/supplier. $2 optionally restricts to one warehouse.
+const stockLevelStatusQuery = `
+	WITH balances AS (
+		SELECT product_id, warehouse_id, quantity AS on_hand
+		FROM stock_balances
+		WHERE tenant_id = $1
+	), reservations AS (
+		SELECT soi.product_id, so.warehouse_id, SUM(soi.quantity - soi.delivered_qty)::int AS reserved
+		FROM sales_order_items soi
+		JOIN sales_orders so ON so.id = soi.order_id AND so.tenant_id = soi.tenant_id
+		WHERE soi.tenant_id = $1 AND so.status IN ('CONFIRMED', 'PARTIALLY_DELIVERED') AND so.deleted_at IS NULL
+		GROUP BY soi.product_id, so.warehouse_id
+	), on_order AS (
+		SELECT poi.product_id, po.warehouse_id, SUM(poi.quantity - poi.received_qty)::int AS on_order
+		FROM purchase_order_items poi
+		JOIN purchase_orders po ON po.id = poi.order_id AND po.tenant_id = poi.tenant_id
+		WHERE poi.tenant_id = $1 AND po.status IN ('DRAFT', 'ORDERED', 'PARTIALLY_RECEIVED')
+		  AND po.deleted_at IS NULL AND poi.quantity > poi.received_qty
+		GROUP BY poi.product_id, po.warehouse_id
+	), sales AS (
+		SELECT product_id, warehouse_id, (-SUM(quantity))::int AS sold
+		FROM stock_movements
+		WHERE tenant_id = $1 AND type = 'SALE' AND created_at >= NOW() - make_interval(days => $3)
+		GROUP BY product_id, warehouse_id
+	)
+	SELECT sl.id, sl.tenant_id, sl.product_id, sl.warehouse_id, sl.min_level, sl.reorder_level, sl.max_level,
+	       sl.safety_stock, sl.lead_time_days, sl.supplier_id, sl.created_at, sl.updated_at,
+	       COALESCE(p.name, '') AS product_name, COALESCE(w.name, '') AS warehouse_name,
+	       COALESCE(b.on_hand, 0) AS on_hand, COALESCE(r.reserved, 0) AS reserved, COALESCE(o.on_order, 0) AS on_order, COALESCE(s.sold, 0) AS sold,
+	       lp.supplier_id AS last_supplier_id, COALESCE(lp.unit_price, 0) AS last_purchase_price
+	FROM stock_levels sl
+	LEFT JOIN products p ON p.id = sl.product_id AND p.tenant_id = sl.tenant_id
+	LEFT JOIN warehouses w ON w.id = sl.warehouse_id AND w.tenant_id = sl.tenant_id
+	LEFT JOIN balances b ON b.product_id = sl.product_id AND b.warehouse_id = sl.warehouse_id
+	LEFT JOIN reservations r ON r.product_id = sl.product_id AND r.warehouse_id = sl.warehouse_id
+	LEFT JOIN on_order o ON o.product_id = sl.product_id AND o.warehouse_id = sl.warehouse_id
+	LEFT JOIN sales s ON s.product_id = sl.product_id AND s.warehouse_id = sl.warehouse_id
+	LEFT JOIN LATERAL (
+		SELECT po.supplier_id, poi.unit_price
+		FROM purchase_order_items poi
+		JOIN purchase_orders po ON po.id = poi.order_id AND po.tenant_id = poi.tenant_id
+		WHERE poi.tenant_id = sl.tenant_id AND poi.product_id = sl.product_id
+		  AND po.status NOT IN ('DRAFT', 'CANCELLED') AND po.deleted_at IS NULL
+		ORDER BY po.created_at DESC
+		LIMIT 1
+	) lp ON TRUE
+	WHERE sl.tenant_id = $1
+	  AND ($2::uuid IS NULL OR sl.warehouse_id = $2)
+	  AND p.deleted_at IS NULL
+`
+
+type ReplenishmentRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewReplenishmentRepository(db *pgxpool.Pool) *ReplenishmentRepository {
+	return &ReplenishmentRepository{db: db}
+}
+
+// CreateAuditLog records a change to stock level settings.
+func (r *ReplenishmentRepository) CreateAuditLog(ctx context.Context, tx pgx.Tx, log *domain.AuditLog) error {
+	return createAuditLog(ctx, r.db, tx, log)
+}
+
+// UpsertStockLevel creates or replaces the settings of a product in a warehouse.
+func (r *ReplenishmentRepository) UpsertStockLevel(ctx context.Context, tx pgx.Tx, l *domain.StockLevel) error {
+	query := `
+		INSERT INTO stock_levels (id, tenant_id, product_id, warehouse_id, min_level, reorder_level, max_level, safety_stock, lead_time_days, supplier_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NOW(), NOW())
+		ON CONFLICT (tenant_id, product_id, warehouse_id)
+		DO UPDATE SET min_level = EXCLUDED.min_level,
+		              reorder_level = EXCLUDED.reorder_level,
+		              max_level = EXCLUDED.max_level,
+		              safety_stock = EXCLUDED.safety_stock,
+		              lead_time_days = EXCLUDED.lead_time_days,
+		              supplier_id = EXCLUDED.supplier_id,
+		              updated_at = NOW()
+		RETURNING id, created_at, updated_at
+	`
+	return tx.QueryRow(ctx, query,
+		l.ID,
+		l.TenantID,
+		l.ProductID,
+		l.WarehouseID,
+		l.MinLevel,
+		l.ReorderLevel,
+		l.MaxLevel,
+		l.SafetyStock,
+		l.LeadTimeDays,
+		l.SupplierID,
+	).Scan(&l.ID, &l.CreatedAt, &l.UpdatedAt)
+}
+
+const stockLevelColumns = `id, tenant_id, product_id, warehouse_id, min_level, reorder_level, max_level, safety_stock, lead_time_days, supplier_id, created_at, updated_at`
+
+func scanStockLevel(row pgx.Row) (*domain.StockLevel, error) {
+	var l domain.StockLevel
+	err := row.Scan(&l.ID, &l.TenantID, &l.ProductID, &l.WarehouseID, &l.MinLevel, &l.ReorderLevel, &l.MaxLevel,
+		&l.SafetyStock, &l.LeadTimeDays, &l.SupplierID, &l.CreatedAt, &l.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stock level: %w", err)
+	}
+	return &l, nil
+}
+
+// GetStockLevelForUpdate locks and returns a stock level, or nil if not found.
+func (r *ReplenishmentRepository) GetStockLevelForUpdate(ctx context.Context, tx pgx.Tx, tenantID, levelID uuid.UUID) (*domain.StockLevel, error) {
+	return scanStockLevel(tx.QueryRow(ctx, `
+		SELECT `+stockLevelColumns+` FROM stock_levels WHERE tenant_id = $1 AND id = $2 FOR UPDATE
+	`, tenantID, levelID))
+}
+
+// FindStockLevelForUpdate locks and returns the settings of a product in a warehouse, or nil
+// if there are none.
+func (r *ReplenishmentRepository) FindStockLevelForUpdate(ctx context.Context, tx pgx.Tx, tenantID, productID, warehouseID uuid.UUID) (*domain.StockLevel, error) {
+	return scanStockLevel(tx.QueryRow(ctx, `
+		SELECT `+stockLevelColumns+` FROM stock_levels WHERE tenant_id = $1 AND product_id = $2 AND warehouse_id = $3 FOR UPDATE
+	`, tenantID, productID, warehouseID))
+}
+
+// DeleteStockLevel removes the settings of a product in a warehouse.
+func (r *ReplenishmentRepository) DeleteStockLevel(ctx context.Context, tx pgx.Tx, tenantID, levelID uuid.UUID) (bool, error) {
+	tag, err := tx.Exec(ctx, `DELETE FROM stock_levels WHERE tenant_id = $1 AND id = $2`, tenantID, levelID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete stock level: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// ListStockLevelStatus returns every configured stock level with current figures.
+func (r *ReplenishmentRepository) ListStockLevelStatus(ctx context.Context, tenantID uuid.UUID, warehouseID *uuid.UUID, windowDays int) ([]domain.StockLevelStatus, error) {
+	rows, err := r.db.Query(ctx, stockLevelStatusQuery+` ORDER BY product_name, warehouse_name`, tenantID, warehouseID, windowDays)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stock levels: %w", err)
+	}
+	defer rows.Close()
+
+	result := []domain.StockLevelStatus{}
+	for rows.Next() {
+		var st domain.StockLevelStatus
+		if err := rows.Scan(
+			&st.ID, &st.TenantID, &st.ProductID, &st.WarehouseID, &st.MinLevel, &st.ReorderLevel, &st.MaxLevel,
+			&st.SafetyStock, &st.LeadTimeDays, &st.SupplierID, &st.CreatedAt, &st.UpdatedAt,
+			&st.ProductName, &st.WarehouseName,
+			&st.OnHand, &st.Reserved, &st.OnOrder, &st.SoldInWindow,
+			&st.LastSupplierID, &st.LastPurchasePrice,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan stock level: %w", err)
+		}
+		st.Available = st.OnHand - st.Reserved
+		result = append(result, st)
+	}
+	return result, rows.Err()
+}
@@ -0,0 +1,199 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"sancaksoft/internal/domain"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type ReportRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewReportRepository(db *pgxpool.Pool) *ReportRepository {
+	return &ReportRepository{db: db}
+}
+
+// profitGroupColumns maps each grouping to its key and label expressions. Only these fixed
+// expressions are ever interpolated into the query.
+var profitGroupColumns = map[domain.ProfitGroupBy][2]string{
+	domain.ProfitByProduct:     {"p.id::text", "p.name"},
+	domain.ProfitByCustomer:    {"c.id::text", "c.name"},
+	domain.ProfitByWarehouse:   {"w.id::text", "w.name"},
+	domain.ProfitBySalesperson: {"COALESCE(i.salesperson_id::text, '')", "COALESCE(u.email, 'Unknown')"},
+	domain.ProfitByCategory:    {"COALESCE(ii.category_id::text, '')", "COALESCE(cat.name, 'Uncategorized')"},
+	domain.ProfitByBrand:       {"COALESCE(ii.brand_id::text, '')", "COALESCE(b.name, 'No brand')"},
+}
+
+// explodedInvoiceLines stands in for invoice_items with kit lines replaced by their components.
+// Components carry the VAT rate of their kit line.
+const explodedInvoiceLines = `(
+		SELECT li.tenant_id, li.invoice_id, li.product_id, li.quantity, li.total, li.vat_rate, li.unit_cost, li.category_id, li.brand_id
+		FROM invoice_items li
+		WHERE NOT EXISTS (SELECT 1 FROM invoice_item_components iic WHERE iic.invoice_item_id = li.id)
+		UNION ALL
+		SELECT iic.tenant_id, li.invoice_id, iic.product_id, iic.quantity, iic.revenue, li.vat_rate, iic.unit_cost, iic.category_id, iic.brand_id
+		FROM invoice_item_components iic
+		JOIN invoice_items li ON li.id = iic.invoice_item_id
+	)`
+
+// netLineRevenue is the VAT-exclusive amount of an invoice line (ii), split from its
+// VAT-inclusive total the same way as on the invoice. Costs are net, so profit is too.
+const netLineRevenue = `ROUND(ii.total * 100 / (100 + COALESCE(ii.vat_rate, 0)), 2)`
+
+var reportPeriodUnits = map[domain.ReportPeriod]string{
+	domain.ReportPeriodDay:   "day",
+	domain.ReportPeriodWeek:  "week",
+	domain.ReportPeriodMonth: "month",
+}
+
+// GetGrossProfit sums net revenue and captured cost of invoice lines per group. Gross profit and
+// margin are left to the caller. With ExplodeKits, kit lines are replaced by their components
+// carrying the revenue allocated to them at sale time.
+func (r *ReportRepository) GetGrossProfit(ctx context.Context, req domain.GrossProfitReportRequest) ([]domain.GrossProfitLine, error) {
+	var keyExpr, labelExpr, orderBy string
+	if req.GroupBy == domain.ProfitByPeriod {
+		unit, ok := reportPeriodUnits[req.Period]
+		if !ok {
+			return nil, fmt.Errorf("unsupported report period %q", req.Period)
+		}
+		keyExpr = fmt.Sprintf("to_char(date_trunc('%s', i.created_at), 'YYYY-MM-DD')", unit)
+		labelExpr = keyExpr
+		orderBy = "key"
+	} else {
+		cols, ok := profitGroupColumns[req.GroupBy]
+		if !ok {
+			return nil, fmt.Errorf("unsupported report grouping %q", req.GroupBy)
+		}
+		keyExpr, labelExpr = cols[0], cols[1]
+		orderBy = "revenue DESC, label"
+	}
+
+	source := "invoice_items"
+	if req.ExplodeKits {
+		source = explodedInvoiceLines
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s AS key, %s AS label,
+			SUM(ii.quantity)::int,
+			SUM(`+netLineRevenue+`) AS revenue,
+			SUM(ii.quantity * ii.unit_cost)
+		FROM %s ii
+		JOIN invoices i ON i.id = ii.invoice_id AND i.tenant_id = ii.tenant_id
+		JOIN products p ON p.id = ii.product_id
+		JOIN customers c ON c.id = i.customer_id
+		JOIN warehouses w ON w.id = i.warehouse_id
+		LEFT JOIN users u ON u.id = i.salesperson_id
+		LEFT JOIN product_categories cat ON cat.id = ii.category_id
+		LEFT JOIN brands b ON b.id = ii.brand_id
+		WHERE ii.tenant_id = $1 AND i.deleted_at IS NULL
+			AND ($2::timestamp IS NULL OR i.created_at >= $2)
+			AND ($3::timestamp IS NULL OR i.created_at < $3)
+			AND ($4::uuid IS NULL OR i.customer_id = $4)
+			AND ($5::uuid IS NULL OR i.warehouse_id = $5)
+		GROUP BY 1, 2
+		ORDER BY %s
+	`, keyExpr, labelExpr, source, orderBy)
+
+	rows, err := r.db.Query(ctx, query, req.TenantID, req.From, req.To, req.CustomerID, req.WarehouseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get gross profit report: %w", err)
+	}
+	defer rows.Close()
+
+	lines := []domain.GrossProfitLine{}
+	for rows.Next() {
+		var line domain.GrossProfitLine
+		if err := rows.Scan(&line.Key, &line.Label, &line.Quantity, &line.Revenue, &line.Cost); err != nil {
+			return nil, fmt.Errorf("failed to scan gross profit line: %w", err)
+		}
+		lines = append(lines, line)
+	}
+	return lines, rows.Err()
+}
+
+// receivableCredits sums each customer's payments and received returns up to $2. Like the credit check
+// on invoicing, they are not tied to invoices and settle each customer's oldest due first.
+const receivableCredits = `
+	credits AS (
+		SELECT customer_id, SUM(amount) AS amount
+		FROM (
+			SELECT customer_id, amount FROM customer_payments
+			WHERE tenant_id = $1 AND payment_date <= $2::date
+			UNION ALL
+			SELECT cr.customer_id, cr.total FROM customer_returns cr
+			WHERE cr.tenant_id = $1 AND ` + creditedReturn + ` AND ` + returnCreditDate + `::date <= $2::date
+		) settled
+		WHERE ($3::uuid IS NULL OR customer_id = $3)
+		GROUP BY customer_id
+	), invoiced AS (
+		SELECT i.id, i.customer_id, i.invoice_number, i.created_at, i.due_date, i.total_amount,
+		       SUM(i.total_amount) OVER (PARTITION BY i.customer_id ORDER BY i.due_date, i.created_at, i.id) AS running
+		FROM invoices i
+		WHERE i.tenant_id = $1 AND i.deleted_at IS NULL AND i.created_at::date <= $2::date
+			AND ($3::uuid IS NULL OR i.customer_id = $3)
+	)`
+
+// ListOpenInvoices returns the invoices still (partly) open as of req.AsOf with the open
+// amount and days past due, by customer name and due date.
+func (r *ReportRepository) ListOpenInvoices(ctx context.Context, req domain.ReceivablesAgingRequest) ([]domain.AgingInvoice, error) {
+	rows, err := r.db.Query(ctx, `
+		WITH `+receivableCredits+`
+		SELECT * FROM (
+			SELECT i.id, i.customer_id, c.name, i.invoice_number, i.created_at, i.due_date, i.total_amount,
+			       LEAST(i.total_amount, GREATEST(i.running - COALESCE(cr.amount, 0), 0)) AS open_amount,
+			       GREATEST($2::date - i.due_date, 0) AS days_overdue
+			FROM invoiced i
+			JOIN customers c ON c.id = i.customer_id
+			LEFT JOIN credits cr ON cr.customer_id = i.customer_id
+		) open_invoices
+		WHERE open_amount > 0
+		ORDER BY name, customer_id, due_date, created_at, id
+	`, req.TenantID, req.AsOf, req.CustomerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list open invoices: %w", err)
+	}
+	defer rows.Close()
+
+	invoices := []domain.AgingInvoice{}
+	for rows.Next() {
+		var inv domain.AgingInvoice
+		if err := rows.Scan(&inv.InvoiceID, &inv.CustomerID, &inv.CustomerName, &inv.InvoiceNumber, &inv.InvoiceDate,
+			&inv.DueDate, &inv.TotalAmount, &inv.OpenAmount, &inv.DaysOverdue); err != nil {
+			return nil, fmt.Errorf("failed to scan open invoice: %w", err)
+		}
+		invoices = append(invoices, inv)
+	}
+	return invoices, rows.Err()
+}
+
+// ListUnappliedCredits returns, per customer, what payments and returns up to req.AsOf
+// exceed the customer's invoices by; customers without such a surplus are left out.
+func (r *ReportRepository) ListUnappliedCredits(ctx context.Context, req domain.ReceivablesAgingRequest) ([]domain.ReceivablesAgingLine, error) {
+	rows, err := r.db.Query(ctx, `
+		WITH `+receivableCredits+`
+		SELECT cr.customer_id, c.name, cr.amount - COALESCE((SELECT SUM(total_amount) FROM invoiced i WHERE i.customer_id = cr.customer_id), 0)
+		FROM credits cr
+		JOIN customers c ON c.id = cr.customer_id
+		WHERE cr.amount > COALESCE((SELECT SUM(total_amount) FROM invoiced i WHERE i.customer_id = cr.customer_id), 0)
+		ORDER BY c.name, cr.customer_id
+	`, req.TenantID, req.AsOf, req.CustomerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list unapplied credits: %w", err)
+	}
+	defer rows.Close()
+
+	lines := []domain.ReceivablesAgingLine{}
+	for rows.Next() {
+		var line domain.ReceivablesAgingLine
+		if err := rows.Scan(&line.CustomerID, &line.CustomerName, &line.UnappliedCredit); err != nil {
+			return nil, fmt.Errorf("failed to scan unapplied credit: %w", err)
+		}
+		lines = append(lines, line)
+	}
+	return lines, rows.Err()
+}
@@ -2,14 +2,85 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sancaksoft/internal/domain"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// customerReturnsDDL creates the returns tables on databases initialised before they
+// existed and adds the workflow columns to older customer_returns tables. Returns that
+// predate the workflow were restocked on creation, so they are backfilled as CLOSED;
+// afterwards the default becomes REQUESTED. The status default and CHECK are only
+// replaced when out of date, so the table is not re-validated on every call.
+const customerReturnsDDL = `
+	CREATE TABLE IF NOT EXISTS return_reasons (
+		id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+		tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+		code VARCHAR(50) NOT NULL,
+		name VARCHAR(255) NOT NULL,
+		is_active BOOLEAN NOT NULL DEFAULT TRUE,
+		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE(tenant_id, code)
+	);
+	CREATE TABLE IF NOT EXISTS customer_returns (
+		id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+		tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+		customer_id UUID NOT NULL REFERENCES customers(id) ON DELETE RESTRICT,
+		product_id UUID NOT NULL REFERENCES products(id) ON DELETE RESTRICT,
+		warehouse_id UUID NOT NULL REFERENCES warehouses(id) ON DELETE RESTRICT,
+		quantity INTEGER NOT NULL CHECK (quantity > 0),
+		unit_price DECIMAL(10, 2) NOT NULL CHECK (unit_price >= 0),
+		total DECIMAL(15, 2) NOT NULL CHECK (total >= 0),
+		reason TEXT,
+		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	);
+	ALTER TABLE customer_returns
+		ADD COLUMN IF NOT EXISTS reason_id UUID REFERENCES return_reasons(id) ON DELETE RESTRICT,
+		ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'CLOSED',
+		ADD COLUMN IF NOT EXISTS restock_qty INTEGER NOT NULL DEFAULT 0,
+		ADD COLUMN IF NOT EXISTS scrap_qty INTEGER NOT NULL DEFAULT 0,
+		ADD COLUMN IF NOT EXISTS supplier_qty INTEGER NOT NULL DEFAULT 0,
+		ADD COLUMN IF NOT EXISTS scrap_warehouse_id UUID REFERENCES warehouses(id) ON DELETE RESTRICT,
+		ADD COLUMN IF NOT EXISTS inspection_note TEXT,
+		ADD COLUMN IF NOT EXISTS received_at TIMESTAMP NULL,
+		ADD COLUMN IF NOT EXISTS inspected_at TIMESTAMP NULL,
+		ADD COLUMN IF NOT EXISTS closed_at TIMESTAMP NULL;
+	DO $$ BEGIN
+		IF (SELECT column_default FROM information_schema.columns
+			WHERE table_schema = current_schema() AND table_name = 'customer_returns'
+			AND column_name = 'status') NOT LIKE '''REQUESTED''%' THEN
+			ALTER TABLE customer_returns ALTER COLUMN status SET DEFAULT 'REQUESTED';
+		END IF;
+		IF NOT EXISTS (SELECT 1 FROM pg_constraint
+			WHERE conrelid = 'customer_returns'::regclass AND conname = 'customer_returns_status_check'
+			AND pg_get_constraintdef(oid) LIKE '%CANCELLED%') THEN
+			ALTER TABLE customer_returns DROP CONSTRAINT IF EXISTS customer_returns_status_check;
+			ALTER TABLE customer_returns ADD CONSTRAINT customer_returns_status_check
+				CHECK (status IN ('REQUESTED', 'RECEIVED', 'INSPECTED', 'CLOSED', 'REJECTED', 'CANCELLED'));
+		END IF;
+	END $$;
+`
+
+// creditedReturn filters customer_returns (aliased cr) to the returns that credit the
+// customer: received goods. Requests, rejected and cancelled returns are left out.
+const creditedReturn = `cr.status IN ('RECEIVED', 'INSPECTED', 'CLOSED')`
+
+// returnCreditDate is the day a credited return (cr) settles the customer's balance: the
+// day its goods were received. Returns from before the workflow fall back to created_at.
+const returnCreditDate = `COALESCE(cr.received_at, cr.created_at)`
+
+const customerReturnColumns = `
+	id, tenant_id, customer_id, product_id, warehouse_id, quantity, unit_price, total,
+	COALESCE(reason, ''), reason_id, status, restock_qty, scrap_qty, supplier_qty,
+	scrap_warehouse_id, COALESCE(inspection_note, ''), received_at, inspected_at, closed_at, created_at
+`
+
 type ReturnRepository struct {
 	db *pgxpool.Pool
 }
@@ -18,22 +89,13 @@ func NewReturnRepository(db *pgxpool.Pool) *ReturnRepository {
 	return &ReturnRepository{db: db}
 }
 
+// CreateAuditLog records a change to a customer return or return reason.
+func (r *ReturnRepository) CreateAuditLog(ctx context.Context, tx pgx.Tx, log *domain.AuditLog) error {
+	return createAuditLog(ctx, r.db, tx, log)
+}
+
 func (r *ReturnRepository) ensureCustomerReturnsTable(ctx context.Context) error {
-	query := `
-		CREATE TABLE IF NOT EXISTS customer_returns (
-			id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
-			tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
-			customer_id UUID NOT NULL REFERENCES customers(id) ON DELETE RESTRICT,
-			product_id UUID NOT NULL REFERENCES products(id) ON DELETE RESTRICT,
-			warehouse_id UUID NOT NULL REFERENCES warehouses(id) ON DELETE RESTRICT,
-			quantity INTEGER NOT NULL CHECK (quantity > 0),
-			unit_price DECIMAL(10, 2) NOT NULL CHECK (unit_price >= 0),
-			total DECIMAL(15, 2) NOT NULL CHECK (total >= 0),
-			reason TEXT,
-			created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
-		)
-	`
-	_, err := r.db.Exec(ctx, query)
+	_, err := r.db.Exec(ctx, customerReturnsDDL)
 	return err
 }
 
@@ -46,30 +108,26 @@ func (r *ReturnRepository) ensureProductUnitColumn(ctx context.Context) error {
 	return err
 }
 
+func scanCustomerReturn(row pgx.Row, ret *domain.CustomerReturn) error {
+	return row.Scan(
+		&ret.ID, &ret.TenantID, &ret.CustomerID, &ret.ProductID, &ret.WarehouseID,
+		&ret.Quantity, &ret.UnitPrice, &ret.Total, &ret.Reason, &ret.ReasonID, &ret.Status,
+		&ret.RestockQty, &ret.ScrapQty, &ret.SupplierQty, &ret.ScrapWarehouseID, &ret.InspectionNote,
+		&ret.ReceivedAt, &ret.InspectedAt, &ret.ClosedAt, &ret.CreatedAt,
+	)
+}
+
 func (r *ReturnRepository) CreateCustomerReturn(ctx context.Context, tx pgx.Tx, ret *domain.CustomerReturn) error {
-	if _, err := tx.Exec(ctx, `
-		CREATE TABLE IF NOT EXISTS customer_returns (
-			id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
-			tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
-			customer_id UUID NOT NULL REFERENCES customers(id) ON DELETE RESTRICT,
-			product_id UUID NOT NULL REFERENCES products(id) ON DELETE RESTRICT,
-			warehouse_id UUID NOT NULL REFERENCES warehouses(id) ON DELETE RESTRICT,
-			quantity INTEGER NOT NULL CHECK (quantity > 0),
-			unit_price DECIMAL(10, 2) NOT NULL CHECK (unit_price >= 0),
-			total DECIMAL(15, 2) NOT NULL CHECK (total >= 0),
-			reason TEXT,
-			created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
-		)
-	`); err != nil {
+	if _, err := tx.Exec(ctx, customerReturnsDDL); err != nil {
 		return fmt.Errorf("failed to ensure returns table: %w", err)
 	}
 
 	query := `
 		INSERT INTO customer_returns (
 			id, tenant_id, customer_id, product_id, warehouse_id,
-			quantity, unit_price, total, reason, created_at
+			quantity, unit_price, total, reason, reason_id, status, created_at
 		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW())
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, NOW())
 		RETURNING created_at
 	`
 	return tx.QueryRow(ctx, query,
@@ -82,15 +140,110 @@ func (r *ReturnRepository) CreateCustomerReturn(ctx context.Context, tx pgx.Tx,
 		ret.UnitPrice,
 		ret.Total,
 		ret.Reason,
+		ret.ReasonID,
+		ret.Status,
 	).Scan(&ret.CreatedAt)
 }
 
+// GetCustomerReturnForUpdate loads a return and locks it for the rest of the transaction.
+// Returns nil if the return does not exist.
+func (r *ReturnRepository) GetCustomerReturnForUpdate(ctx context.Context, tx pgx.Tx, tenantID, returnID uuid.UUID) (*domain.CustomerReturn, error) {
+	query := `SELECT ` + customerReturnColumns + `
+		FROM customer_returns
+		WHERE tenant_id = $1 AND id = $2
+		FOR UPDATE
+	`
+	var ret domain.CustomerReturn
+	if err := scanCustomerReturn(tx.QueryRow(ctx, query, tenantID, returnID), &ret); err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get return: %w", err)
+	}
+	return &ret, nil
+}
+
+// UpdateCustomerReturnWorkflow persists status, disposition and timestamp fields.
+func (r *ReturnRepository) UpdateCustomerReturnWorkflow(ctx context.Context, tx pgx.Tx, ret *domain.CustomerReturn) error {
+	query := `
+		UPDATE customer_returns
+		SET status = $3, restock_qty = $4, scrap_qty = $5, supplier_qty = $6,
+		    scrap_warehouse_id = $7, inspection_note = $8,
+		    received_at = $9, inspected_at = $10, closed_at = $11
+		WHERE tenant_id = $1 AND id = $2
+	`
+	_, err := tx.Exec(ctx, query,
+		ret.TenantID,
+		ret.ID,
+		ret.Status,
+		ret.RestockQty,
+		ret.ScrapQty,
+		ret.SupplierQty,
+		ret.ScrapWarehouseID,
+		ret.InspectionNote,
+		ret.ReceivedAt,
+		ret.InspectedAt,
+		ret.ClosedAt,
+	)
+	return err
+}
+
+// GetWarehouseQuarantineFlag returns whether the warehouse is a quarantine warehouse.
+// The boolean result is false and found is false if the warehouse does not exist.
+func (r *ReturnRepository) GetWarehouseQuarantineFlag(ctx context.Context, tx pgx.Tx, tenantID, warehouseID uuid.UUID) (isQuarantine, found bool, err error) {
+	err = tx.QueryRow(ctx, `
+		SELECT is_quarantine FROM warehouses
+		WHERE tenant_id = $1 AND id = $2 AND deleted_at IS NULL
+	`, tenantID, warehouseID).Scan(&isQuarantine)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return false, false, nil
+		}
+		return false, false, fmt.Errorf("failed to get warehouse: %w", err)
+	}
+	return isQuarantine, true, nil
+}
+
+// GetDefaultQuarantineWarehouse returns the oldest quarantine warehouse of the tenant, or nil.
+func (r *ReturnRepository) GetDefaultQuarantineWarehouse(ctx context.Context, tx pgx.Tx, tenantID uuid.UUID) (*uuid.UUID, error) {
+	var id uuid.UUID
+	err := tx.QueryRow(ctx, `
+		SELECT id FROM warehouses
+		WHERE tenant_id = $1 AND is_quarantine AND deleted_at IS NULL
+		ORDER BY created_at ASC
+		LIMIT 1
+	`, tenantID).Scan(&id)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get quarantine warehouse: %w", err)
+	}
+	return &id, nil
+}
+
+// ProductIsKit reports whether the product is sold as a kit of other products.
+func (r *ReturnRepository) ProductIsKit(ctx context.Context, tx pgx.Tx, tenantID, productID uuid.UUID) (bool, error) {
+	return productIsKit(ctx, tx, tenantID, productID)
+}
+
+// ProductTracksSerials reports whether every unit of the product moves under a serial number.
+func (r *ReturnRepository) ProductTracksSerials(ctx context.Context, tx pgx.Tx, tenantID, productID uuid.UUID) (bool, error) {
+	return productTracksSerials(ctx, tx, tenantID, productID)
+}
+
+// MoveSerials moves the serial numbers of an inserted movement and returns the first one
+// that is not where the movement needs it.
+func (r *ReturnRepository) MoveSerials(ctx context.Context, tx pgx.Tx, movement *domain.StockMovement) (string, error) {
+	return moveSerials(ctx, tx, movement)
+}
+
 func (r *ReturnRepository) CreateStockMovement(ctx context.Context, tx pgx.Tx, movement *domain.StockMovement) error {
 	query := `
 		INSERT INTO stock_movements (
-			id, tenant_id, product_id, warehouse_id, quantity, type, reference_id, reference_type, created_at
+			id, tenant_id, product_id, warehouse_id, quantity, type, reference_id, reference_type, unit_cost, created_at
 		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW())
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW())
 	`
 	_, err := tx.Exec(ctx, query,
 		movement.ID,
@@ -101,23 +254,23 @@ func (r *ReturnRepository) CreateStockMovement(ctx context.Context, tx pgx.Tx, m
 		movement.Type,
 		movement.ReferenceID,
 		movement.ReferenceType,
+		movement.UnitCost,
 	)
 	return err
 }
 
-func (r *ReturnRepository) ListCustomerReturns(ctx context.Context, tenantID uuid.UUID) ([]domain.CustomerReturn, error) {
+func (r *ReturnRepository) ListCustomerReturns(ctx context.Context, tenantID uuid.UUID, status domain.ReturnStatus) ([]domain.CustomerReturn, error) {
 	if err := r.ensureCustomerReturnsTable(ctx); err != nil {
 		return nil, fmt.Errorf("failed to ensure returns table: %w", err)
 	}
 
-	query := `
-		SELECT id, tenant_id, customer_id, product_id, warehouse_id, quantity, unit_price, total, reason, created_at
+	query := `SELECT ` + customerReturnColumns + `
 		FROM customer_returns
-		WHERE tenant_id = $1
+		WHERE tenant_id = $1 AND ($2 = '' OR status = $2)
 		ORDER BY created_at DESC
 		LIMIT 100
 	`
-	rows, err := r.db.Query(ctx, query, tenantID)
+	rows, err := r.db.Query(ctx, query, tenantID, string(status))
 	if err != nil {
 		return nil, fmt.Errorf("failed to list returns: %w", err)
 	}
@@ -126,10 +279,7 @@ func (r *ReturnRepository) ListCustomerReturns(ctx context.Context, tenantID uui
 	var returns []domain.CustomerReturn
 	for rows.Next() {
 		var ret domain.CustomerReturn
-		if err := rows.Scan(
-			&ret.ID, &ret.TenantID, &ret.CustomerID, &ret.ProductID, &ret.WarehouseID,
-			&ret.Quantity, &ret.UnitPrice, &ret.Total, &ret.Reason, &ret.CreatedAt,
-		); err != nil {
+		if err := scanCustomerReturn(rows, &ret); err != nil {
 			return nil, fmt.Errorf("failed to scan return: %w", err)
 		}
 		returns = append(returns, ret)
@@ -169,6 +319,7 @@ func (r *ReturnRepository) ListCustomerPurchaseSummaries(ctx context.Context, te
 			FROM customer_returns
 			WHERE tenant_id = $1
 			  AND customer_id = $2
+			  AND status NOT IN ('REJECTED', 'CANCELLED')
 			GROUP BY customer_id, product_id, warehouse_id
 		)
 		SELECT
@@ -219,3 +370,146 @@ func (r *ReturnRepository) ListCustomerPurchaseSummaries(ctx context.Context, te
 	}
 	return summaries, nil
 }
+
+// CreateReturnReason inserts a new tenant return reason code.
+func (r *ReturnRepository) CreateReturnReason(ctx context.Context, tx pgx.Tx, reason *domain.ReturnReason) error {
+	if _, err := tx.Exec(ctx, customerReturnsDDL); err != nil {
+		return fmt.Errorf("failed to ensure returns table: %w", err)
+	}
+
+	query := `
+		INSERT INTO return_reasons (id, tenant_id, code, name, is_active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, NOW(), NOW())
+		RETURNING created_at, updated_at
+	`
+	return tx.QueryRow(ctx, query,
+		reason.ID,
+		reason.TenantID,
+		reason.Code,
+		reason.Name,
+		reason.IsActive,
+	).Scan(&reason.CreatedAt, &reason.UpdatedAt)
+}
+
+// GetReturnReasonForUpdate locks and returns a return reason, or nil if not found.
+func (r *ReturnRepository) GetReturnReasonForUpdate(ctx context.Context, tx pgx.Tx, tenantID, reasonID uuid.UUID) (*domain.ReturnReason, error) {
+	var reason domain.ReturnReason
+	err := tx.QueryRow(ctx, `
+		SELECT id, tenant_id, code, name, is_active, created_at, updated_at
+		FROM return_reasons WHERE tenant_id = $1 AND id = $2
+		FOR UPDATE
+	`, tenantID, reasonID).Scan(&reason.ID, &reason.TenantID, &reason.Code, &reason.Name, &reason.IsActive, &reason.CreatedAt, &reason.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get return reason: %w", err)
+	}
+	return &reason, nil
+}
+
+// UpdateReturnReason updates name and active flag. Codes are immutable so reports stay comparable.
+func (r *ReturnRepository) UpdateReturnReason(ctx context.Context, tx pgx.Tx, reason *domain.ReturnReason) error {
+	query := `
+		UPDATE return_reasons
+		SET name = $3, is_active = $4, updated_at = NOW()
+		WHERE tenant_id = $1 AND id = $2
+		RETURNING code, created_at, updated_at
+	`
+	return tx.QueryRow(ctx, query,
+		reason.TenantID,
+		reason.ID,
+		reason.Name,
+		reason.IsActive,
+	).Scan(&reason.Code, &reason.CreatedAt, &reason.UpdatedAt)
+}
+
+// ListReturnReasons lists the tenant's reason codes, optionally only active ones.
+func (r *ReturnRepository) ListReturnReasons(ctx context.Context, tenantID uuid.UUID, activeOnly bool) ([]domain.ReturnReason, error) {
+	if err := r.ensureCustomerReturnsTable(ctx); err != nil {
+		return nil, fmt.Errorf("failed to ensure returns table: %w", err)
+	}
+
+	query := `
+		SELECT id, tenant_id, code, name, is_active, created_at, updated_at
+		FROM return_reasons
+		WHERE tenant_id = $1 AND (NOT $2 OR is_active)
+		ORDER BY code
+	`
+	rows, err := r.db.Query(ctx, query, tenantID, activeOnly)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list return reasons: %w", err)
+	}
+	defer rows.Close()
+
+	var reasons []domain.ReturnReason
+	for rows.Next() {
+		var rr domain.ReturnReason
+		if err := rows.Scan(&rr.ID, &rr.TenantID, &rr.Code, &rr.Name, &rr.IsActive, &rr.CreatedAt, &rr.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan return reason: %w", err)
+		}
+		reasons = append(reasons, rr)
+	}
+	return reasons, nil
+}
+
+// IsReturnReasonActive reports whether the reason exists for the tenant and is active.
+func (r *ReturnRepository) IsReturnReasonActive(ctx context.Context, tx pgx.Tx, tenantID, reasonID uuid.UUID) (bool, error) {
+	var active bool
+	err := tx.QueryRow(ctx, `
+		SELECT is_active FROM return_reasons WHERE tenant_id = $1 AND id = $2
+	`, tenantID, reasonID).Scan(&active)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to get return reason: %w", err)
+	}
+	return active, nil
+}
+
+// GetReturnReasonReport aggregates returns per reason code within [from, to).
+// Returns without a reason code are grouped under an empty code.
+func (r *ReturnRepository) GetReturnReasonReport(ctx context.Context, tenantID uuid.UUID, from, to *time.Time) ([]domain.ReturnReasonReportRow, error) {
+	if err := r.ensureCustomerReturnsTable(ctx); err != nil {
+		return nil, fmt.Errorf("failed to ensure returns table: %w", err)
+	}
+
+	query := `
+		SELECT
+			cr.reason_id,
+			COALESCE(rr.code, ''),
+			COALESCE(rr.name, ''),
+			COUNT(*)::int,
+			COALESCE(SUM(cr.quantity), 0)::int,
+			COALESCE(SUM(cr.total), 0),
+			COALESCE(SUM(cr.restock_qty), 0)::int,
+			COALESCE(SUM(cr.scrap_qty), 0)::int,
+			COALESCE(SUM(cr.supplier_qty), 0)::int
+		FROM customer_returns cr
+		LEFT JOIN return_reasons rr ON rr.id = cr.reason_id AND rr.tenant_id = cr.tenant_id
+		WHERE cr.tenant_id = $1
+		  AND ($2::timestamp IS NULL OR cr.created_at >= $2)
+		  AND ($3::timestamp IS NULL OR cr.created_at < $3)
+		GROUP BY cr.reason_id, rr.code, rr.name
+		ORDER BY SUM(cr.quantity) DESC
+	`
+	rows, err := r.db.Query(ctx, query, tenantID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get return reason report: %w", err)
+	}
+	defer rows.Close()
+
+	var report []domain.ReturnReasonReportRow
+	for rows.Next() {
+		var row domain.ReturnReasonReportRow
+		if err := rows.Scan(
+			&row.ReasonID, &row.Code, &row.Name, &row.ReturnCount, &row.Quantity,
+			&row.TotalAmount, &row.RestockQty, &row.ScrapQty, &row.SupplierQty,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan return reason report: %w", err)
+		}
+		report = append(report, row)
+	}
+	return report, nil
+}
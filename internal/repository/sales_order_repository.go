@@ -0,0 +1,205 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"sancaksoft/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// reservedQuantityQuery sums undelivered quantities of open sales orders for
+// ($1 tenant, $2 product, $3 warehouse), excluding order $4 when it is not NULL.
+const reservedQuantityQuery = `
+	SELECT COALESCE(SUM(soi.quantity - soi.delivered_qty), 0)::int
+	FROM sales_order_items soi
+	JOIN sales_orders so ON so.id = soi.order_id AND so.tenant_id = soi.tenant_id
+	WHERE soi.tenant_id = $1
+	  AND soi.product_id = $2
+	  AND so.warehouse_id = $3
+	  AND so.status IN ('CONFIRMED', 'PARTIALLY_DELIVERED')
+	  AND so.deleted_at IS NULL
+	  AND ($4::uuid IS NULL OR so.id <> $4)
+`
+
+const salesOrderColumns = `
+	id, tenant_id, customer_id, warehouse_id, order_number, status, COALESCE(note, ''),
+	total_amount, confirmed_at, closed_at, created_at, updated_at
+`
+
+type SalesOrderRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewSalesOrderRepository(db *pgxpool.Pool) *SalesOrderRepository {
+	return &SalesOrderRepository{db: db}
+}
+
+// CreateAuditLog records a change to a sales order.
+func (r *SalesOrderRepository) CreateAuditLog(ctx context.Context, tx pgx.Tx, log *domain.AuditLog) error {
+	return createAuditLog(ctx, r.db, tx, log)
+}
+
+// GenerateNextOrderNumber generates the next sequential order number atomically.
+func (r *SalesOrderRepository) GenerateNextOrderNumber(ctx context.Context, tx pgx.Tx, tenantID uuid.UUID) (string, error) {
+	return nextDocumentNumber(ctx, tx, tenantID, "SALES_ORDER", "SO")
+}
+
+// CreateSalesOrder inserts the order header.
+func (r *SalesOrderRepository) CreateSalesOrder(ctx context.Context, tx pgx.Tx, order *domain.SalesOrder) error {
+	query := `
+		INSERT INTO sales_orders (id, tenant_id, customer_id, warehouse_id, order_number, status, note, total_amount, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW(), NOW())
+		RETURNING created_at, updated_at
+	`
+	return tx.QueryRow(ctx, query,
+		order.ID,
+		order.TenantID,
+		order.CustomerID,
+		order.WarehouseID,
+		order.OrderNumber,
+		order.Status,
+		order.Note,
+		order.TotalAmount,
+	).Scan(&order.CreatedAt, &order.UpdatedAt)
+}
+
+// CreateSalesOrderItem inserts an order line.
+func (r *SalesOrderRepository) CreateSalesOrderItem(ctx context.Context, tx pgx.Tx, item *domain.SalesOrderItem) error {
+	query := `
+		INSERT INTO sales_order_items (id, tenant_id, order_id, product_id, quantity, delivered_qty, unit_price, total, created_at)
+		VALUES ($1, $2, $3, $4, $5, 0, $6, $7, NOW())
+		RETURNING created_at
+	`
+	return tx.QueryRow(ctx, query,
+		item.ID,
+		item.TenantID,
+		item.OrderID,
+		item.ProductID,
+		item.Quantity,
+		item.UnitPrice,
+		item.Total,
+	).Scan(&item.CreatedAt)
+}
+
+// GetSalesOrder returns the order with its lines, or nil if not found.
+func (r *SalesOrderRepository) GetSalesOrder(ctx context.Context, tenantID, orderID uuid.UUID) (*domain.SalesOrder, error) {
+	return r.getSalesOrder(ctx, r.db, tenantID, orderID, false)
+}
+
+// GetSalesOrderForUpdate returns the order with its lines and locks the header row.
+func (r *SalesOrderRepository) GetSalesOrderForUpdate(ctx context.Context, tx pgx.Tx, tenantID, orderID uuid.UUID) (*domain.SalesOrder, error) {
+	return r.getSalesOrder(ctx, tx, tenantID, orderID, true)
+}
+
+func (r *SalesOrderRepository) getSalesOrder(ctx context.Context, q dbtx, tenantID, orderID uuid.UUID, forUpdate bool) (*domain.SalesOrder, error) {
+	query := `SELECT ` + salesOrderColumns + `
+		FROM sales_orders
+		WHERE tenant_id = $1 AND id = $2 AND deleted_at IS NULL
+	`
+	if forUpdate {
+		query += ` FOR UPDATE`
+	}
+
+	var o domain.SalesOrder
+	err := q.QueryRow(ctx, query, tenantID, orderID).Scan(
+		&o.ID, &o.TenantID, &o.CustomerID, &o.WarehouseID, &o.OrderNumber, &o.Status, &o.Note,
+		&o.TotalAmount, &o.ConfirmedAt, &o.ClosedAt, &o.CreatedAt, &o.UpdatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get sales order: %w", err)
+	}
+
+	rows, err := q.Query(ctx, `
+		SELECT id, tenant_id, order_id, product_id, quantity, delivered_qty, unit_price, total, created_at
+		FROM sales_order_items
+		WHERE tenant_id = $1 AND order_id = $2
+		ORDER BY created_at, id
+	`, tenantID, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sales order items: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var it domain.SalesOrderItem
+		if err := rows.Scan(
+			&it.ID, &it.TenantID, &it.OrderID, &it.ProductID, &it.Quantity, &it.DeliveredQty, &it.UnitPrice, &it.Total, &it.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan sales order item: %w", err)
+		}
+		o.Items = append(o.Items, it)
+	}
+	return &o, rows.Err()
+}
+
+// UpdateSalesOrderStatus persists status and lifecycle timestamps.
+func (r *SalesOrderRepository) UpdateSalesOrderStatus(ctx context.Context, tx pgx.Tx, order *domain.SalesOrder) error {
+	query := `
+		UPDATE sales_orders
+		SET status = $3, confirmed_at = $4, closed_at = $5, updated_at = NOW()
+		WHERE tenant_id = $1 AND id = $2
+		RETURNING updated_at
+	`
+	return tx.QueryRow(ctx, query,
+		order.TenantID,
+		order.ID,
+		order.Status,
+		order.ConfirmedAt,
+		order.ClosedAt,
+	).Scan(&order.UpdatedAt)
+}
+
+// AddDeliveredQuantity increases the delivered quantity of a line, releasing its reservation.
+func (r *SalesOrderRepository) AddDeliveredQuantity(ctx context.Context, tx pgx.Tx, tenantID, itemID uuid.UUID, quantity int) error {
+	_, err := tx.Exec(ctx, `
+		UPDATE sales_order_items
+		SET delivered_qty = delivered_qty + $3
+		WHERE tenant_id = $1 AND id = $2
+	`, tenantID, itemID, quantity)
+	return err
+}
+
+// GetReservedQuantity returns the quantity reserved by open orders, excluding excludeOrderID if set.
+func (r *SalesOrderRepository) GetReservedQuantity(ctx context.Context, tx pgx.Tx, tenantID, productID, warehouseID uuid.UUID, excludeOrderID *uuid.UUID) (int, error) {
+	var reserved int
+	err := tx.QueryRow(ctx, reservedQuantityQuery, tenantID, productID, warehouseID, excludeOrderID).Scan(&reserved)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get reserved quantity: %w", err)
+	}
+	return reserved, nil
+}
+
+// ListSalesOrders lists order headers, optionally filtered by status.
+func (r *SalesOrderRepository) ListSalesOrders(ctx context.Context, tenantID uuid.UUID, status domain.SalesOrderStatus) ([]domain.SalesOrder, error) {
+	query := `SELECT ` + salesOrderColumns + `
+		FROM sales_orders
+		WHERE tenant_id = $1 AND deleted_at IS NULL AND ($2 = '' OR status = $2)
+		ORDER BY created_at DESC
+		LIMIT 100
+	`
+	rows, err := r.db.Query(ctx, query, tenantID, string(status))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sales orders: %w", err)
+	}
+	defer rows.Close()
+
+	orders := []domain.SalesOrder{}
+	for rows.Next() {
+		var o domain.SalesOrder
+		if err := rows.Scan(
+			&o.ID, &o.TenantID, &o.CustomerID, &o.WarehouseID, &o.OrderNumber, &o.Status, &o.Note,
+			&o.TotalAmount, &o.ConfirmedAt, &o.ClosedAt, &o.CreatedAt, &o.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan sales order: %w", err)
+		}
+		orders = append(orders, o)
+	}
+	return orders, nil
+}
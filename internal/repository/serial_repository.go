@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"sancaksoft/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type SerialRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewSerialRepository(db *pgxpool.Pool) *SerialRepository {
+	return &SerialRepository{db: db}
+}
+
+// ListInStockSerials returns the product's units currently in stock, optionally in one warehouse.
+func (r *SerialRepository) ListInStockSerials(ctx context.Context, tenantID, productID uuid.UUID, warehouseID *uuid.UUID) ([]domain.SerialNumber, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT id, tenant_id, product_id, serial_number, warehouse_id, created_at, updated_at
+		FROM serial_numbers
+		WHERE tenant_id = $1 AND product_id = $2 AND warehouse_id IS NOT NULL
+		  AND ($3::uuid IS NULL OR warehouse_id = $3)
+		ORDER BY serial_number
+	`, tenantID, productID, warehouseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list serial numbers: %w", err)
+	}
+	defer rows.Close()
+
+	serials := []domain.SerialNumber{}
+	for rows.Next() {
+		var sn domain.SerialNumber
+		if err := rows.Scan(&sn.ID, &sn.TenantID, &sn.ProductID, &sn.SerialNumber, &sn.WarehouseID, &sn.CreatedAt, &sn.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan serial number: %w", err)
+		}
+		serials = append(serials, sn)
+	}
+	return serials, rows.Err()
+}
+
+// GetSerialHistory returns every unit with the given serial number (one per product unless
+// productID narrows it down) with its movements, oldest first.
+func (r *SerialRepository) GetSerialHistory(ctx context.Context, tenantID uuid.UUID, serialNumber string, productID *uuid.UUID) ([]domain.SerialHistory, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT sn.id, sn.tenant_id, sn.product_id, sn.serial_number, sn.warehouse_id, sn.created_at, sn.updated_at,
+		       p.name, p.sku, w.name
+		FROM serial_numbers sn
+		JOIN products p ON p.id = sn.product_id
+		LEFT JOIN warehouses w ON w.id = sn.warehouse_id
+		WHERE sn.tenant_id = $1 AND sn.serial_number = $2 AND ($3::uuid IS NULL OR sn.product_id = $3)
+		ORDER BY p.name
+	`, tenantID, serialNumber, productID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get serial number: %w", err)
+	}
+	var histories []domain.SerialHistory
+	for rows.Next() {
+		var h domain.SerialHistory
+		if err := rows.Scan(&h.ID, &h.TenantID, &h.ProductID, &h.SerialNumber.SerialNumber, &h.WarehouseID, &h.CreatedAt, &h.UpdatedAt,
+			&h.ProductName, &h.SKU, &h.WarehouseName); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan serial number: %w", err)
+		}
+		histories = append(histories, h)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get serial number: %w", err)
+	}
+
+	for i := range histories {
+		events, err := r.listSerialEvents(ctx, tenantID, histories[i].ID)
+		if err != nil {
+			return nil, err
+		}
+		histories[i].Events = events
+	}
+	return histories, nil
+}
+
+func (r *SerialRepository) listSerialEvents(ctx context.Context, tenantID, serialID uuid.UUID) ([]domain.SerialEvent, error) {
+	rows, err := r.db.Query(ctx, movementDocumentSelect+`
+		JOIN stock_movement_serials sms ON sms.movement_id = sm.id
+		WHERE sm.tenant_id = $1 AND sms.serial_id = $2
+		ORDER BY sm.created_at, CASE WHEN sm.quantity > 0 THEN 0 ELSE 1 END, sm.id
+	`, tenantID, serialID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list serial number movements: %w", err)
+	}
+	defer rows.Close()
+
+	events := []domain.SerialEvent{}
+	for rows.Next() {
+		var e domain.SerialEvent
+		var quantity int
+		if err := rows.Scan(
+			&e.MovementID, &e.WarehouseID, &e.WarehouseName, &e.MovementType, &quantity, &e.ReferenceType, &e.ReferenceID,
+			&e.DocumentNumber, &e.PartyName, &e.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan serial number movement: %w", err)
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+// productTracksSerials reports whether the product is serialized; unknown products are not.
+func productTracksSerials(ctx context.Context, q dbtx, tenantID, productID uuid.UUID) (bool, error) {
+	var tracked bool
+	err := q.QueryRow(ctx, `
+		SELECT COALESCE((SELECT track_serials FROM products WHERE tenant_id = $1 AND id = $2), FALSE)
+	`, tenantID, productID).Scan(&tracked)
+	if err != nil {
+		return false, fmt.Errorf("failed to check serial tracking of product %s: %w", productID, err)
+	}
+	return tracked, nil
+}
+
+// moveSerials moves the units named by an inserted movement and links them to it. Inbound
+// units are put into the movement's warehouse (and created on first receipt); outbound
+// units are taken out of it. It returns the first serial number that is not where the
+// movement needs it (already in stock, or not in this warehouse) and stops there; the
+// caller is expected to roll back.
+func moveSerials(ctx context.Context, q dbtx, movement *domain.StockMovement) (string, error) {
+	for _, serial := range movement.SerialNumbers {
+		var serialID uuid.UUID
+		var err error
+		if movement.Quantity > 0 {
+			err = q.QueryRow(ctx, `
+				INSERT INTO serial_numbers (id, tenant_id, product_id, serial_number, warehouse_id, created_at, updated_at)
+				VALUES ($1, $2, $3, $4, $5, NOW(), NOW())
+				ON CONFLICT (tenant_id, product_id, serial_number) DO UPDATE
+				SET warehouse_id = EXCLUDED.warehouse_id, updated_at = NOW()
+				WHERE serial_numbers.warehouse_id IS NULL
+				RETURNING id
+			`, uuid.New(), movement.TenantID, movement.ProductID, serial, movement.WarehouseID).Scan(&serialID)
+		} else {
+			err = q.QueryRow(ctx, `
+				UPDATE serial_numbers SET warehouse_id = NULL, updated_at = NOW()
+				WHERE tenant_id = $1 AND product_id = $2 AND serial_number = $3 AND warehouse_id = $4
+				RETURNING id
+			`, movement.TenantID, movement.ProductID, serial, movement.WarehouseID).Scan(&serialID)
+		}
+		if errors.Is(err, pgx.ErrNoRows) {
+			return serial, nil
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to move serial number %s: %w", serial, err)
+		}
+
+		if _, err := q.Exec(ctx, `
+			INSERT INTO stock_movement_serials (tenant_id, movement_id, serial_id) VALUES ($1, $2, $3)
+		`, movement.TenantID, movement.ID, serialID); err != nil {
+			return "", fmt.Errorf("failed to link serial number %s: %w", serial, err)
+		}
+	}
+	return "", nil
+}
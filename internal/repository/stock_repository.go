@@ -4,8 +4,10 @@ import (
 	"context"
 	"fmt"
 	"sancaksoft/internal/domain"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -17,17 +19,37 @@ func NewStockRepository(db *pgxpool.Pool) *StockRepository {
 	return &StockRepository{db: db}
 }
 
-// ListStockMovements retrieves stock movements with optional filtering.
-// For now, it filters by tenantID. In production, we'd add productID/warehouseID filters.
-func (r *StockRepository) ListStockMovements(ctx context.Context, tenantID uuid.UUID) ([]domain.StockMovement, error) {
+// CreateAuditLog records a change to stock.
+func (r *StockRepository) CreateAuditLog(ctx context.Context, tx pgx.Tx, log *domain.AuditLog) error {
+	return createAuditLog(ctx, r.db, tx, log)
+}
+
+// stockMovementFilterWhere restricts stock movements (sm) to a StockMovementFilter passed by
+// stockMovementFilterArgs. The movement list and the movement export share it.
+const stockMovementFilterWhere = `
+	WHERE sm.tenant_id = $1
+		AND ($2::uuid IS NULL OR sm.product_id = $2)
+		AND ($3::uuid IS NULL OR sm.warehouse_id = $3)
+		AND ($4::text = '' OR sm.type::text = $4)
+		AND ($5::timestamp IS NULL OR sm.created_at >= $5)
+		AND ($6::timestamp IS NULL OR sm.created_at < $6)
+`
+
+func stockMovementFilterArgs(f domain.StockMovementFilter) []any {
+	return []any{f.TenantID, f.ProductID, f.WarehouseID, string(f.Type), f.From, f.To}
+}
+
+// ListStockMovements returns the latest 100 stock movements matching the filter.
+func (r *StockRepository) ListStockMovements(ctx context.Context, f domain.StockMovementFilter) ([]domain.StockMovement, error) {
 	query := `
-		SELECT id, tenant_id, product_id, warehouse_id, quantity, type, reference_id, reference_type, created_at
-		FROM stock_movements
-		WHERE tenant_id = $1
-		ORDER BY created_at DESC
+		SELECT sm.id, sm.tenant_id, sm.product_id, sm.warehouse_id, sm.quantity, sm.type, sm.reference_id, sm.reference_type,
+		       sm.unit_cost, sm.assigned_cost, sm.lot_id, l.lot_number, l.expiry_date, sm.location_id, sm.created_at
+		FROM stock_movements sm
+		LEFT JOIN lots l ON l.id = sm.lot_id` + stockMovementFilterWhere + `
+		ORDER BY sm.created_at DESC
 		LIMIT 100
 	`
-	rows, err := r.db.Query(ctx, query, tenantID)
+	rows, err := r.db.Query(ctx, query, stockMovementFilterArgs(f)...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list stock movements: %w", err)
 	}
@@ -37,7 +59,8 @@ func (r *StockRepository) ListStockMovements(ctx context.Context, tenantID uuid.
 	for rows.Next() {
 		var sm domain.StockMovement
 		if err := rows.Scan(
-			&sm.ID, &sm.TenantID, &sm.ProductID, &sm.WarehouseID, &sm.Quantity, &sm.Type, &sm.ReferenceID, &sm.ReferenceType, &sm.CreatedAt,
+			&sm.ID, &sm.TenantID, &sm.ProductID, &sm.WarehouseID, &sm.Quantity, &sm.Type, &sm.ReferenceID, &sm.ReferenceType, &sm.UnitCost, &sm.AssignedCost,
+			&sm.LotID, &sm.LotNumber, &sm.ExpiryDate, &sm.LocationID, &sm.CreatedAt,
 		); err != nil {
 			return nil, fmt.Errorf("failed to scan stock movement: %w", err)
 		}
@@ -48,11 +71,17 @@ func (r *StockRepository) ListStockMovements(ctx context.Context, tenantID uuid.
 
 // GetStockBalance returns the current stock balance for a product in a warehouse.
 func (r *StockRepository) GetStockBalance(ctx context.Context, tenantID, productID, warehouseID uuid.UUID) (int, error) {
+	return getStockBalance(ctx, r.db, tenantID, productID, warehouseID)
+}
+
+// getStockBalance reads the materialized balance kept in step with stock_movements by trigger.
+func getStockBalance(ctx context.Context, q dbtx, tenantID, productID, warehouseID uuid.UUID) (int, error) {
 	var currentStock int
-	err := r.db.QueryRow(ctx, `
-		SELECT COALESCE(SUM(quantity), 0)
-		FROM stock_movements
-		WHERE tenant_id = $1 AND product_id = $2 AND warehouse_id = $3
+	err := q.QueryRow(ctx, `
+		SELECT COALESCE((
+			SELECT quantity FROM stock_balances
+			WHERE tenant_id = $1 AND product_id = $2 AND warehouse_id = $3
+		), 0)
 	`, tenantID, productID, warehouseID).Scan(&currentStock)
 	if err != nil {
 		return 0, fmt.Errorf("failed to get stock balance: %w", err)
@@ -60,12 +89,22 @@ func (r *StockRepository) GetStockBalance(ctx context.Context, tenantID, product
 	return currentStock, nil
 }
 
+// GetReservedQuantity returns the quantity reserved by open sales orders for a product in a warehouse.
+func (r *StockRepository) GetReservedQuantity(ctx context.Context, tenantID, productID, warehouseID uuid.UUID) (int, error) {
+	var reserved int
+	err := r.db.QueryRow(ctx, reservedQuantityQuery, tenantID, productID, warehouseID, nil).Scan(&reserved)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get reserved quantity: %w", err)
+	}
+	return reserved, nil
+}
+
 // GetTotalStockBalance returns the total stock balance for a product across all warehouses.
 func (r *StockRepository) GetTotalStockBalance(ctx context.Context, tenantID, productID uuid.UUID) (int, error) {
 	var totalStock int
 	err := r.db.QueryRow(ctx, `
 		SELECT COALESCE(SUM(quantity), 0)
-		FROM stock_movements
+		FROM stock_balances
 		WHERE tenant_id = $1 AND product_id = $2
 	`, tenantID, productID).Scan(&totalStock)
 	if err != nil {
@@ -81,12 +120,11 @@ func (r *StockRepository) GetStockBalanceByWarehouse(ctx context.Context, tenant
 	Quantity      int
 }, error) {
 	query := `
-		SELECT w.id, w.name, COALESCE(SUM(sm.quantity), 0)::int as quantity
+		SELECT w.id, w.name, COALESCE(sb.quantity, 0) as quantity
 		FROM warehouses w
-		LEFT JOIN stock_movements sm ON sm.warehouse_id = w.id 
-			AND sm.tenant_id = $1 AND sm.product_id = $2
+		LEFT JOIN stock_balances sb ON sb.warehouse_id = w.id
+			AND sb.tenant_id = $1 AND sb.product_id = $2
 		WHERE w.tenant_id = $1
-		GROUP BY w.id, w.name
 		ORDER BY quantity DESC
 	`
 	rows, err := r.db.Query(ctx, query, tenantID, productID)
@@ -115,15 +153,21 @@ func (r *StockRepository) GetStockBalanceByWarehouse(ctx context.Context, tenant
 }
 
 // CreateStockMovement inserts a stock movement record.
-func (r *StockRepository) CreateStockMovement(ctx context.Context, movement *domain.StockMovement) error {
+func (r *StockRepository) CreateStockMovement(ctx context.Context, tx pgx.Tx, movement *domain.StockMovement) error {
 	query := `
 		INSERT INTO stock_movements (
 			id, tenant_id, product_id, warehouse_id, 
-			quantity, type, reference_id, reference_type, created_at
+			quantity, type, reference_id, reference_type, unit_cost, lot_id, location_id, created_at
 		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW())
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, COALESCE($12, NOW()))
+		RETURNING created_at
 	`
-	_, err := r.db.Exec(ctx, query,
+	// A zero CreatedAt means "now"; a past date back-dates the movement (costing replays it in order).
+	var createdAt *time.Time
+	if !movement.CreatedAt.IsZero() {
+		createdAt = &movement.CreatedAt
+	}
+	return tx.QueryRow(ctx, query,
 		movement.ID,
 		movement.TenantID,
 		movement.ProductID,
@@ -132,6 +176,437 @@ func (r *StockRepository) CreateStockMovement(ctx context.Context, movement *dom
 		movement.Type,
 		movement.ReferenceID,
 		movement.ReferenceType,
-	)
-	return err
-}
\ No newline at end of file
+		movement.UnitCost,
+		movement.LotID,
+		movement.LocationID,
+		createdAt,
+	).Scan(&movement.CreatedAt)
+}
+
+// ProductTracksLots reports whether movements of the product need a lot number.
+func (r *StockRepository) ProductTracksLots(ctx context.Context, tenantID, productID uuid.UUID) (bool, error) {
+	return productTracksLots(ctx, r.db, tenantID, productID)
+}
+
+// EnsureLot returns the product's lot with the given number, creating it on first receipt.
+func (r *StockRepository) EnsureLot(ctx context.Context, tenantID, productID uuid.UUID, lotNumber string, expiryDate *time.Time) (*domain.Lot, error) {
+	return ensureLot(ctx, r.db, tenantID, productID, lotNumber, expiryDate)
+}
+
+// ProductIsVariantParent reports whether the product's stock is kept on its variants.
+func (r *StockRepository) ProductIsVariantParent(ctx context.Context, tenantID, productID uuid.UUID) (bool, error) {
+	return productIsVariantParent(ctx, r.db, tenantID, productID)
+}
+
+// ProductIsKit reports whether the product is sold as a kit of other products.
+func (r *StockRepository) ProductIsKit(ctx context.Context, tenantID, productID uuid.UUID) (bool, error) {
+	return productIsKit(ctx, r.db, tenantID, productID)
+}
+
+// ProductTracksSerials reports whether every unit of the product moves under a serial number.
+func (r *StockRepository) ProductTracksSerials(ctx context.Context, tenantID, productID uuid.UUID) (bool, error) {
+	return productTracksSerials(ctx, r.db, tenantID, productID)
+}
+
+// MoveSerials moves the serial numbers of an inserted movement and returns the first one
+// that is not where the movement needs it.
+func (r *StockRepository) MoveSerials(ctx context.Context, tx pgx.Tx, movement *domain.StockMovement) (string, error) {
+	return moveSerials(ctx, tx, movement)
+}
+
+// GetLocation returns a warehouse location, or nil if not found.
+func (r *StockRepository) GetLocation(ctx context.Context, tenantID, locationID uuid.UUID) (*domain.WarehouseLocation, error) {
+	return getLocation(ctx, r.db, tenantID, locationID)
+}
+
+// GetLocationQuantity returns the product's stock in a location; a nil location returns the
+// warehouse's unlocated stock.
+func (r *StockRepository) GetLocationQuantity(ctx context.Context, tenantID, productID, warehouseID uuid.UUID, locationID *uuid.UUID) (int, error) {
+	return getLocationQuantity(ctx, r.db, tenantID, productID, warehouseID, locationID)
+}
+
+// ListAvailableLots returns the product's lots in stock in a warehouse, first to expire first.
+func (r *StockRepository) ListAvailableLots(ctx context.Context, tenantID, productID, warehouseID uuid.UUID) ([]domain.LotBalance, error) {
+	return listAvailableLots(ctx, r.db, tenantID, productID, warehouseID)
+}
+
+// ListBalanceDrift compares stock_balances, stock_lot_balances and stock_location_balances
+// with the movement ledger (and location transfers) and returns every row where they
+// disagree: per product and warehouse, the warehouse balance first, then its locations
+// and lots.
+func (r *StockRepository) ListBalanceDrift(ctx context.Context, q dbtx, tenantID uuid.UUID) ([]domain.StockBalanceDrift, error) {
+	rows, err := q.Query(ctx, `
+		WITH ledger AS (
+			SELECT product_id, warehouse_id, SUM(quantity)::int AS quantity
+			FROM stock_movements
+			WHERE tenant_id = $1
+			GROUP BY product_id, warehouse_id
+		), balances AS (
+			SELECT product_id, warehouse_id, quantity
+			FROM stock_balances
+			WHERE tenant_id = $1
+		), lot_ledger AS (
+			SELECT lot_id, warehouse_id, SUM(quantity)::int AS quantity
+			FROM stock_movements
+			WHERE tenant_id = $1 AND lot_id IS NOT NULL
+			GROUP BY lot_id, warehouse_id
+		), lot_balances AS (
+			SELECT lot_id, warehouse_id, quantity
+			FROM stock_lot_balances
+			WHERE tenant_id = $1
+		), location_ledger AS (
+			SELECT location_id, product_id, SUM(quantity)::int AS quantity
+			FROM (
+				SELECT location_id, product_id, quantity FROM stock_movements
+				WHERE tenant_id = $1 AND location_id IS NOT NULL
+				UNION ALL
+				SELECT from_location_id, product_id, -quantity FROM location_transfers
+				WHERE tenant_id = $1 AND from_location_id IS NOT NULL
+				UNION ALL
+				SELECT to_location_id, product_id, quantity FROM location_transfers
+				WHERE tenant_id = $1 AND to_location_id IS NOT NULL
+			) m
+			GROUP BY location_id, product_id
+		), location_balances AS (
+			SELECT location_id, product_id, quantity
+			FROM stock_location_balances
+			WHERE tenant_id = $1
+		)
+		SELECT COALESCE(l.product_id, b.product_id), COALESCE(l.warehouse_id, b.warehouse_id), NULL::uuid, NULL::uuid,
+		       COALESCE(b.quantity, 0), COALESCE(l.quantity, 0)
+		FROM ledger l
+		FULL OUTER JOIN balances b ON b.product_id = l.product_id AND b.warehouse_id = l.warehouse_id
+		WHERE COALESCE(b.quantity, 0) <> COALESCE(l.quantity, 0)
+		UNION ALL
+		SELECT lot.product_id, COALESCE(l.warehouse_id, b.warehouse_id), lot.id, NULL::uuid,
+		       COALESCE(b.quantity, 0), COALESCE(l.quantity, 0)
+		FROM lot_ledger l
+		FULL OUTER JOIN lot_balances b ON b.lot_id = l.lot_id AND b.warehouse_id = l.warehouse_id
+		JOIN lots lot ON lot.id = COALESCE(l.lot_id, b.lot_id)
+		WHERE COALESCE(b.quantity, 0) <> COALESCE(l.quantity, 0)
+		UNION ALL
+		SELECT COALESCE(l.product_id, b.product_id), wl.warehouse_id, NULL::uuid, wl.id,
+		       COALESCE(b.quantity, 0), COALESCE(l.quantity, 0)
+		FROM location_ledger l
+		FULL OUTER JOIN location_balances b ON b.location_id = l.location_id AND b.product_id = l.product_id
+		JOIN warehouse_locations wl ON wl.id = COALESCE(l.location_id, b.location_id)
+		WHERE COALESCE(b.quantity, 0) <> COALESCE(l.quantity, 0)
+		ORDER BY 1, 2, 3 NULLS FIRST, 4 NULLS FIRST
+	`, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check stock balances: %w", err)
+	}
+	defer rows.Close()
+
+	drift := []domain.StockBalanceDrift{}
+	for rows.Next() {
+		var d domain.StockBalanceDrift
+		if err := rows.Scan(&d.ProductID, &d.WarehouseID, &d.LotID, &d.LocationID, &d.BalanceQty, &d.LedgerQty); err != nil {
+			return nil, fmt.Errorf("failed to scan stock balance drift: %w", err)
+		}
+		drift = append(drift, d)
+	}
+	return drift, rows.Err()
+}
+
+// stockBalancesDDL creates the balance tables on databases initialised before them and
+// (re)installs the trigger functions that maintain them, matching database.sql.
+const stockBalancesDDL = `
+	CREATE TABLE IF NOT EXISTS stock_balances (
+		tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+		product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+		warehouse_id UUID NOT NULL REFERENCES warehouses(id) ON DELETE CASCADE,
+		quantity INTEGER NOT NULL DEFAULT 0,
+		updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (tenant_id, product_id, warehouse_id)
+	);
+
+	CREATE TABLE IF NOT EXISTS stock_lot_balances (
+		tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+		lot_id UUID NOT NULL REFERENCES lots(id) ON DELETE CASCADE,
+		warehouse_id UUID NOT NULL REFERENCES warehouses(id) ON DELETE CASCADE,
+		quantity INTEGER NOT NULL DEFAULT 0,
+		updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (tenant_id, lot_id, warehouse_id)
+	);
+
+	CREATE TABLE IF NOT EXISTS stock_location_balances (
+		tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+		location_id UUID NOT NULL REFERENCES warehouse_locations(id) ON DELETE CASCADE,
+		product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+		quantity INTEGER NOT NULL DEFAULT 0,
+		updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (tenant_id, location_id, product_id)
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_stock_balances_tenant_warehouse ON stock_balances(tenant_id, warehouse_id);
+
+	CREATE OR REPLACE FUNCTION lock_stock_balances(p_tenant_id UUID) RETURNS VOID AS $$
+	BEGIN
+		PERFORM pg_advisory_xact_lock_shared(hashtext('stock_balances'), hashtext(p_tenant_id::text));
+	END;
+	$$ LANGUAGE plpgsql;
+
+	CREATE OR REPLACE FUNCTION add_location_balance(p_tenant_id UUID, p_location_id UUID, p_product_id UUID, p_quantity INTEGER) RETURNS VOID AS $$
+	BEGIN
+		IF p_location_id IS NULL THEN
+			RETURN;
+		END IF;
+		INSERT INTO stock_location_balances (tenant_id, location_id, product_id, quantity, updated_at)
+		VALUES (p_tenant_id, p_location_id, p_product_id, p_quantity, NOW())
+		ON CONFLICT (tenant_id, location_id, product_id) DO UPDATE
+		SET quantity = stock_location_balances.quantity + EXCLUDED.quantity, updated_at = NOW();
+	END;
+	$$ LANGUAGE plpgsql;
+
+	CREATE OR REPLACE FUNCTION apply_location_transfer_balance() RETURNS TRIGGER AS $$
+	BEGIN
+		IF TG_OP = 'DELETE' THEN
+			PERFORM lock_stock_balances(OLD.tenant_id);
+			UPDATE stock_location_balances
+			SET quantity = quantity + OLD.quantity, updated_at = NOW()
+			WHERE tenant_id = OLD.tenant_id AND location_id = OLD.from_location_id AND product_id = OLD.product_id;
+			UPDATE stock_location_balances
+			SET quantity = quantity - OLD.quantity, updated_at = NOW()
+			WHERE tenant_id = OLD.tenant_id AND location_id = OLD.to_location_id AND product_id = OLD.product_id;
+			RETURN NULL;
+		END IF;
+		PERFORM lock_stock_balances(NEW.tenant_id);
+		PERFORM add_location_balance(NEW.tenant_id, NEW.from_location_id, NEW.product_id, -NEW.quantity);
+		PERFORM add_location_balance(NEW.tenant_id, NEW.to_location_id, NEW.product_id, NEW.quantity);
+		RETURN NULL;
+	END;
+	$$ LANGUAGE plpgsql;
+
+	CREATE OR REPLACE FUNCTION apply_stock_movement_balance() RETURNS TRIGGER AS $$
+	BEGIN
+		-- Removing a movement only ever decrements an existing row (tenant cascades may already be deleting it)
+		IF TG_OP IN ('UPDATE', 'DELETE') THEN
+			PERFORM lock_stock_balances(OLD.tenant_id);
+			UPDATE stock_balances
+			SET quantity = quantity - OLD.quantity, updated_at = NOW()
+			WHERE tenant_id = OLD.tenant_id AND product_id = OLD.product_id AND warehouse_id = OLD.warehouse_id;
+			IF OLD.lot_id IS NOT NULL THEN
+				UPDATE stock_lot_balances
+				SET quantity = quantity - OLD.quantity, updated_at = NOW()
+				WHERE tenant_id = OLD.tenant_id AND lot_id = OLD.lot_id AND warehouse_id = OLD.warehouse_id;
+			END IF;
+			IF OLD.location_id IS NOT NULL THEN
+				UPDATE stock_location_balances
+				SET quantity = quantity - OLD.quantity, updated_at = NOW()
+				WHERE tenant_id = OLD.tenant_id AND location_id = OLD.location_id AND product_id = OLD.product_id;
+			END IF;
+		END IF;
+		IF TG_OP IN ('INSERT', 'UPDATE') THEN
+			PERFORM lock_stock_balances(NEW.tenant_id);
+			INSERT INTO stock_balances (tenant_id, product_id, warehouse_id, quantity, updated_at)
+			VALUES (NEW.tenant_id, NEW.product_id, NEW.warehouse_id, NEW.quantity, NOW())
+			ON CONFLICT (tenant_id, product_id, warehouse_id) DO UPDATE
+			SET quantity = stock_balances.quantity + EXCLUDED.quantity, updated_at = NOW();
+			IF NEW.lot_id IS NOT NULL THEN
+				INSERT INTO stock_lot_balances (tenant_id, lot_id, warehouse_id, quantity, updated_at)
+				VALUES (NEW.tenant_id, NEW.lot_id, NEW.warehouse_id, NEW.quantity, NOW())
+				ON CONFLICT (tenant_id, lot_id, warehouse_id) DO UPDATE
+				SET quantity = stock_lot_balances.quantity + EXCLUDED.quantity, updated_at = NOW();
+			END IF;
+			PERFORM add_location_balance(NEW.tenant_id, NEW.location_id, NEW.product_id, NEW.quantity);
+		END IF;
+		RETURN NULL;
+	END;
+	$$ LANGUAGE plpgsql;
+
+	CREATE OR REPLACE TRIGGER trg_location_transfers_balance
+	AFTER INSERT OR DELETE ON location_transfers
+	FOR EACH ROW EXECUTE FUNCTION apply_location_transfer_balance();
+
+	CREATE OR REPLACE TRIGGER trg_stock_movements_balance
+	AFTER INSERT OR DELETE OR UPDATE OF tenant_id, product_id, warehouse_id, quantity, lot_id, location_id ON stock_movements
+	FOR EACH ROW EXECUTE FUNCTION apply_stock_movement_balance();
+`
+
+// EnsureBalanceTables applies stockBalancesDDL and rebuilds every tenant's balances from
+// the ledger if any balance table had to be created. Movements and location transfers
+// are locked out meanwhile so none is missed or counted twice.
+func (r *StockRepository) EnsureBalanceTables(ctx context.Context, tx pgx.Tx) error {
+	if _, err := tx.Exec(ctx, `LOCK TABLE stock_movements, location_transfers IN SHARE ROW EXCLUSIVE MODE`); err != nil {
+		return fmt.Errorf("failed to lock stock movements: %w", err)
+	}
+	var missing bool
+	err := tx.QueryRow(ctx, `
+		SELECT to_regclass('stock_balances') IS NULL
+		    OR to_regclass('stock_lot_balances') IS NULL
+		    OR to_regclass('stock_location_balances') IS NULL
+	`).Scan(&missing)
+	if err != nil {
+		return fmt.Errorf("failed to check stock balance tables: %w", err)
+	}
+	if _, err := tx.Exec(ctx, stockBalancesDDL); err != nil {
+		return fmt.Errorf("failed to create stock balance tables: %w", err)
+	}
+	if !missing {
+		return nil
+	}
+
+	rows, err := tx.Query(ctx, `SELECT id FROM tenants`)
+	if err != nil {
+		return fmt.Errorf("failed to list tenants: %w", err)
+	}
+	tenantIDs, err := pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+	if err != nil {
+		return fmt.Errorf("failed to scan tenant: %w", err)
+	}
+	for _, tenantID := range tenantIDs {
+		if err := r.RepairBalances(ctx, tx, tenantID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LockBalances waits for the tenant's open movements and location transfers (whose
+// triggers hold the lock shared) and blocks new ones until UnlockBalances. It is a
+// session lock taken before the repair transaction begins, so the transaction's snapshot
+// includes everything committed while waiting. Other tenants are not affected.
+func (r *StockRepository) LockBalances(ctx context.Context, conn *pgxpool.Conn, tenantID uuid.UUID) error {
+	if _, err := conn.Exec(ctx, `SELECT pg_advisory_lock(hashtext('stock_balances'), hashtext($1::text))`, tenantID); err != nil {
+		return fmt.Errorf("failed to lock stock balances: %w", err)
+	}
+	return nil
+}
+
+// UnlockBalances releases LockBalances. If that fails the connection is closed, which
+// releases the lock, rather than returned to the pool still holding it.
+func (r *StockRepository) UnlockBalances(conn *pgxpool.Conn, tenantID uuid.UUID) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := conn.Exec(ctx, `SELECT pg_advisory_unlock(hashtext('stock_balances'), hashtext($1::text))`, tenantID); err != nil {
+		_ = conn.Conn().Close(ctx)
+	}
+}
+
+// RepairBalances rebuilds the tenant's stock_balances, stock_lot_balances and
+// stock_location_balances from the movement ledger (and location transfers).
+func (r *StockRepository) RepairBalances(ctx context.Context, tx pgx.Tx, tenantID uuid.UUID) error {
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO stock_balances (tenant_id, product_id, warehouse_id, quantity, updated_at)
+		SELECT tenant_id, product_id, warehouse_id, SUM(quantity), NOW()
+		FROM stock_movements
+		WHERE tenant_id = $1
+		GROUP BY tenant_id, product_id, warehouse_id
+		ON CONFLICT (tenant_id, product_id, warehouse_id) DO UPDATE
+		SET quantity = EXCLUDED.quantity, updated_at = NOW()
+		WHERE stock_balances.quantity <> EXCLUDED.quantity
+	`, tenantID); err != nil {
+		return fmt.Errorf("failed to rebuild stock balances: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `
+		UPDATE stock_balances sb SET quantity = 0, updated_at = NOW()
+		WHERE sb.tenant_id = $1 AND sb.quantity <> 0
+		  AND NOT EXISTS (
+			SELECT 1 FROM stock_movements sm
+			WHERE sm.tenant_id = sb.tenant_id AND sm.product_id = sb.product_id AND sm.warehouse_id = sb.warehouse_id
+		  )
+	`, tenantID); err != nil {
+		return fmt.Errorf("failed to clear orphan stock balances: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM stock_lot_balances WHERE tenant_id = $1`, tenantID); err != nil {
+		return fmt.Errorf("failed to clear lot balances: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO stock_lot_balances (tenant_id, lot_id, warehouse_id, quantity, updated_at)
+		SELECT tenant_id, lot_id, warehouse_id, SUM(quantity), NOW()
+		FROM stock_movements
+		WHERE tenant_id = $1 AND lot_id IS NOT NULL
+		GROUP BY tenant_id, lot_id, warehouse_id
+	`, tenantID); err != nil {
+		return fmt.Errorf("failed to rebuild lot balances: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM stock_location_balances WHERE tenant_id = $1`, tenantID); err != nil {
+		return fmt.Errorf("failed to clear location balances: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO stock_location_balances (tenant_id, location_id, product_id, quantity, updated_at)
+		SELECT tenant_id, location_id, product_id, SUM(quantity), NOW()
+		FROM (
+			SELECT tenant_id, location_id, product_id, quantity FROM stock_movements
+			WHERE tenant_id = $1 AND location_id IS NOT NULL
+			UNION ALL
+			SELECT tenant_id, from_location_id, product_id, -quantity FROM location_transfers
+			WHERE tenant_id = $1 AND from_location_id IS NOT NULL
+			UNION ALL
+			SELECT tenant_id, to_location_id, product_id, quantity FROM location_transfers
+			WHERE tenant_id = $1 AND to_location_id IS NOT NULL
+		) m
+		GROUP BY tenant_id, location_id, product_id
+	`, tenantID); err != nil {
+		return fmt.Errorf("failed to rebuild location balances: %w", err)
+	}
+	return nil
+}
+
+// movementDocumentSelect reads stock movements (sm) with their warehouse name and the number
+// and customer/supplier of the document they reference. Callers append the WHERE clause.
+const movementDocumentSelect = `
+	SELECT sm.id, sm.warehouse_id, COALESCE(w.name, ''), sm.type, sm.quantity, sm.reference_type, sm.reference_id,
+	       COALESCE(inv.invoice_number, dn.note_number, gr.receipt_number),
+	       COALESCE(ic.name, dc.name, rc.name, s.name),
+	       sm.created_at
+	FROM stock_movements sm
+	LEFT JOIN warehouses w ON w.id = sm.warehouse_id` + movementDocumentJoins
+
+// movementDocumentJoins joins the document a stock movement (sm) references as inv, dn or gr
+// and its customer or supplier as ic, dc, rc or s.
+const movementDocumentJoins = `
+	LEFT JOIN invoices inv ON sm.reference_type = 'INVOICE' AND inv.id = sm.reference_id
+	LEFT JOIN customers ic ON ic.id = inv.customer_id
+	LEFT JOIN delivery_notes dn ON sm.reference_type = 'DELIVERY_NOTE' AND dn.id = sm.reference_id
+	LEFT JOIN customers dc ON dc.id = dn.customer_id
+	LEFT JOIN customer_returns cr ON sm.reference_type = 'RETURN' AND cr.id = sm.reference_id
+	LEFT JOIN customers rc ON rc.id = cr.customer_id
+	LEFT JOIN goods_receipts gr ON sm.reference_type = 'GOODS_RECEIPT' AND gr.id = sm.reference_id
+	LEFT JOIN purchase_orders po ON po.id = gr.order_id
+	LEFT JOIN suppliers s ON s.id = po.supplier_id
+`
+
+// GetStockBalanceAsOf sums the movement ledger before the given instant. A nil warehouseID
+// sums all warehouses. Back-dated movements are counted at their movement date.
+func (r *StockRepository) GetStockBalanceAsOf(ctx context.Context, tenantID, productID uuid.UUID, warehouseID *uuid.UUID, before time.Time) (int, error) {
+	var balance int
+	err := r.db.QueryRow(ctx, `
+		SELECT COALESCE(SUM(quantity), 0)
+		FROM stock_movements
+		WHERE tenant_id = $1 AND product_id = $2 AND ($3::uuid IS NULL OR warehouse_id = $3) AND created_at < $4
+	`, tenantID, productID, warehouseID, before).Scan(&balance)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get stock balance as of date: %w", err)
+	}
+	return balance, nil
+}
+
+// ListProductMovements returns a product's movements in [from, to) in ledger order with the
+// number and party of the document behind each one. RunningBalance is left to the caller.
+func (r *StockRepository) ListProductMovements(ctx context.Context, tenantID, productID uuid.UUID, warehouseID *uuid.UUID, from, to *time.Time) ([]domain.ProductMovementLine, error) {
+	rows, err := r.db.Query(ctx, movementDocumentSelect+`
+		WHERE sm.tenant_id = $1 AND sm.product_id = $2 AND ($3::uuid IS NULL OR sm.warehouse_id = $3)
+			AND ($4::timestamp IS NULL OR sm.created_at >= $4)
+			AND ($5::timestamp IS NULL OR sm.created_at < $5)
+		ORDER BY sm.created_at, CASE WHEN sm.quantity > 0 THEN 0 ELSE 1 END, sm.id
+	`, tenantID, productID, warehouseID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list product movements: %w", err)
+	}
+	defer rows.Close()
+
+	lines := []domain.ProductMovementLine{}
+	for rows.Next() {
+		var l domain.ProductMovementLine
+		if err := rows.Scan(
+			&l.ID, &l.WarehouseID, &l.WarehouseName, &l.Type, &l.Quantity, &l.ReferenceType, &l.ReferenceID,
+			&l.DocumentNumber, &l.PartyName, &l.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan product movement: %w", err)
+		}
+		lines = append(lines, l)
+	}
+	return lines, rows.Err()
+}
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"sancaksoft/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type SupplierRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewSupplierRepository(db *pgxpool.Pool) *SupplierRepository {
+	return &SupplierRepository{db: db}
+}
+
+// CreateAuditLog records a change to a supplier.
+func (r *SupplierRepository) CreateAuditLog(ctx context.Context, tx pgx.Tx, log *domain.AuditLog) error {
+	return createAuditLog(ctx, r.db, tx, log)
+}
+
+// CreateSupplier inserts a new supplier.
+func (r *SupplierRepository) CreateSupplier(ctx context.Context, tx pgx.Tx, s *domain.Supplier) error {
+	query := `
+		INSERT INTO suppliers (id, tenant_id, name, email, phone, address, tax_number, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW(), NOW())
+		RETURNING created_at, updated_at
+	`
+	return tx.QueryRow(ctx, query,
+		s.ID,
+		s.TenantID,
+		s.Name,
+		s.Email,
+		s.Phone,
+		s.Address,
+		s.TaxNumber,
+	).Scan(&s.CreatedAt, &s.UpdatedAt)
+}
+
+// GetSupplierByID retrieves a supplier by ID and TenantID.
+func (r *SupplierRepository) GetSupplierByID(ctx context.Context, tenantID, supplierID uuid.UUID) (*domain.Supplier, error) {
+	query := `
+		SELECT id, tenant_id, name, COALESCE(email, ''), COALESCE(phone, ''), COALESCE(address, ''), COALESCE(tax_number, ''), created_at, updated_at
+		FROM suppliers
+		WHERE id = $1 AND tenant_id = $2 AND deleted_at IS NULL
+	`
+	var s domain.Supplier
+	err := r.db.QueryRow(ctx, query, supplierID, tenantID).Scan(
+		&s.ID, &s.TenantID, &s.Name, &s.Email, &s.Phone, &s.Address, &s.TaxNumber, &s.CreatedAt, &s.UpdatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil // Not found
+		}
+		return nil, fmt.Errorf("failed to get supplier: %w", err)
+	}
+	return &s, nil
+}
+
+// ListSuppliers retrieves the suppliers of a tenant ordered by name.
+func (r *SupplierRepository) ListSuppliers(ctx context.Context, tenantID uuid.UUID) ([]domain.Supplier, error) {
+	query := `
+		SELECT id, tenant_id, name, COALESCE(email, ''), COALESCE(phone, ''), COALESCE(address, ''), COALESCE(tax_number, ''), created_at, updated_at
+		FROM suppliers
+		WHERE tenant_id = $1 AND deleted_at IS NULL
+		ORDER BY name
+	`
+	rows, err := r.db.Query(ctx, query, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list suppliers: %w", err)
+	}
+	defer rows.Close()
+
+	suppliers := []domain.Supplier{}
+	for rows.Next() {
+		var s domain.Supplier
+		if err := rows.Scan(
+			&s.ID, &s.TenantID, &s.Name, &s.Email, &s.Phone, &s.Address, &s.TaxNumber, &s.CreatedAt, &s.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan supplier: %w", err)
+		}
+		suppliers = append(suppliers, s)
+	}
+	return suppliers, rows.Err()
+}
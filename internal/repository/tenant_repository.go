@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"sancaksoft/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type TenantRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewTenantRepository(db *pgxpool.Pool) *TenantRepository {
+	return &TenantRepository{db: db}
+}
+
+// CreateAuditLog records a change to a tenant's settings.
+func (r *TenantRepository) CreateAuditLog(ctx context.Context, tx pgx.Tx, log *domain.AuditLog) error {
+	return createAuditLog(ctx, r.db, tx, log)
+}
+
+// GetTenantProfile returns the tenant's company details, or nil if the tenant does not exist.
+func (r *TenantRepository) GetTenantProfile(ctx context.Context, tenantID uuid.UUID) (*domain.TenantProfile, error) {
+	return getTenantProfile(ctx, r.db, tenantID)
+}
+
+// LockTenantProfile locks the tenant row for a settings change in tx and returns its
+// company details; nil if the tenant does not exist.
+func (r *TenantRepository) LockTenantProfile(ctx context.Context, tx pgx.Tx, tenantID uuid.UUID) (*domain.TenantProfile, error) {
+	if err := lockTenant(ctx, tx, tenantID); err != nil {
+		return nil, err
+	}
+	return getTenantProfile(ctx, tx, tenantID)
+}
+
+// UpdateTenantProfile replaces the tenant's company details.
+func (r *TenantRepository) UpdateTenantProfile(ctx context.Context, tx pgx.Tx, p *domain.TenantProfile) error {
+	err := tx.QueryRow(ctx, `
+		UPDATE tenants
+		SET name = $2, address = NULLIF($3, ''), district = NULLIF($4, ''), city = NULLIF($5, ''),
+			phone = NULLIF($6, ''), email = NULLIF($7, ''), tax_office = NULLIF($8, ''), tax_number = NULLIF($9, ''),
+			updated_at = NOW()
+		WHERE id = $1
+		RETURNING updated_at
+	`, p.ID, p.Name, p.Address, p.District, p.City, p.Phone, p.Email, p.TaxOffice, p.TaxNumber).Scan(&p.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update tenant profile: %w", err)
+	}
+	return nil
+}
+
+func getTenantProfile(ctx context.Context, q dbtx, tenantID uuid.UUID) (*domain.TenantProfile, error) {
+	var p domain.TenantProfile
+	err := q.QueryRow(ctx, `
+		SELECT id, name, COALESCE(address, ''), COALESCE(district, ''), COALESCE(city, ''), COALESCE(phone, ''),
+			COALESCE(email, ''), COALESCE(tax_office, ''), COALESCE(tax_number, ''), updated_at
+		FROM tenants
+		WHERE id = $1
+	`, tenantID).Scan(&p.ID, &p.Name, &p.Address, &p.District, &p.City, &p.Phone, &p.Email, &p.TaxOffice, &p.TaxNumber, &p.UpdatedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get tenant profile: %w", err)
+	}
+	return &p, nil
+}
+
+// GetInvoiceTemplate returns the tenant's invoice template, or the default one if the
+// tenant has not saved a template.
+func (r *TenantRepository) GetInvoiceTemplate(ctx context.Context, tenantID uuid.UUID) (*domain.InvoiceTemplate, error) {
+	return getInvoiceTemplate(ctx, r.db, tenantID)
+}
+
+// LockInvoiceTemplate locks the tenant row for a template change in tx and returns the
+// current template, or the default one.
+func (r *TenantRepository) LockInvoiceTemplate(ctx context.Context, tx pgx.Tx, tenantID uuid.UUID) (*domain.InvoiceTemplate, error) {
+	if err := lockTenant(ctx, tx, tenantID); err != nil {
+		return nil, err
+	}
+	return getInvoiceTemplate(ctx, tx, tenantID)
+}
+
+// SaveInvoiceTemplate creates or replaces the tenant's invoice template.
+func (r *TenantRepository) SaveInvoiceTemplate(ctx context.Context, tx pgx.Tx, t *domain.InvoiceTemplate) error {
+	err := tx.QueryRow(ctx, `
+		INSERT INTO invoice_templates (tenant_id, title, accent_color, show_sku, show_due_date, bank_details, footer_note, updated_at)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), NULLIF($7, ''), NOW())
+		ON CONFLICT (tenant_id) DO UPDATE
+		SET title = EXCLUDED.title, accent_color = EXCLUDED.accent_color, show_sku = EXCLUDED.show_sku,
+			show_due_date = EXCLUDED.show_due_date, bank_details = EXCLUDED.bank_details,
+			footer_note = EXCLUDED.footer_note, updated_at = NOW()
+		RETURNING updated_at
+	`, t.TenantID, t.Title, t.AccentColor, t.ShowSKU, t.ShowDueDate, t.BankDetails, t.FooterNote).Scan(&t.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save invoice template: %w", err)
+	}
+	return nil
+}
+
+func lockTenant(ctx context.Context, tx pgx.Tx, tenantID uuid.UUID) error {
+	if _, err := tx.Exec(ctx, `SELECT 1 FROM tenants WHERE id = $1 FOR UPDATE`, tenantID); err != nil {
+		return fmt.Errorf("failed to lock tenant: %w", err)
+	}
+	return nil
+}
+
+func getInvoiceTemplate(ctx context.Context, q dbtx, tenantID uuid.UUID) (*domain.InvoiceTemplate, error) {
+	t := domain.DefaultInvoiceTemplate(tenantID)
+	err := q.QueryRow(ctx, `
+		SELECT title, accent_color, show_sku, show_due_date, COALESCE(bank_details, ''), COALESCE(footer_note, ''), updated_at
+		FROM invoice_templates
+		WHERE tenant_id = $1
+	`, tenantID).Scan(&t.Title, &t.AccentColor, &t.ShowSKU, &t.ShowDueDate, &t.BankDetails, &t.FooterNote, &t.UpdatedAt)
+	if err != nil && err != pgx.ErrNoRows {
+		return nil, fmt.Errorf("failed to get invoice template: %w", err)
+	}
+	return &t, nil
+}
@@ -0,0 +1,279 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"sancaksoft/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shopspring/decimal"
+)
+
+type VariantRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewVariantRepository(db *pgxpool.Pool) *VariantRepository {
+	return &VariantRepository{db: db}
+}
+
+// CreateAuditLog records a change to a product's variants.
+func (r *VariantRepository) CreateAuditLog(ctx context.Context, tx pgx.Tx, log *domain.AuditLog) error {
+	return createAuditLog(ctx, r.db, tx, log)
+}
+
+// GetProduct returns a product, or nil if not found.
+func (r *VariantRepository) GetProduct(ctx context.Context, tenantID, productID uuid.UUID) (*domain.Product, error) {
+	return getProduct(ctx, r.db, tenantID, productID, "")
+}
+
+// LockProduct locks a product row so its variants are changed one request at a time.
+// It returns nil if the product is not found.
+func (r *VariantRepository) LockProduct(ctx context.Context, tx pgx.Tx, tenantID, productID uuid.UUID) (*domain.Product, error) {
+	return getProduct(ctx, tx, tenantID, productID, "FOR UPDATE")
+}
+
+// HasMovements reports whether the product ever moved stock.
+func (r *VariantRepository) HasMovements(ctx context.Context, tx pgx.Tx, tenantID, productID uuid.UUID) (bool, error) {
+	var moved bool
+	err := tx.QueryRow(ctx, `
+		SELECT EXISTS (SELECT 1 FROM stock_movements WHERE tenant_id = $1 AND product_id = $2)
+	`, tenantID, productID).Scan(&moved)
+	if err != nil {
+		return false, fmt.Errorf("failed to check product movements: %w", err)
+	}
+	return moved, nil
+}
+
+// ProductIsKit reports whether the product is sold as a kit of other products.
+func (r *VariantRepository) ProductIsKit(ctx context.Context, tx pgx.Tx, tenantID, productID uuid.UUID) (bool, error) {
+	return productIsKit(ctx, tx, tenantID, productID)
+}
+
+// ListDimensions returns the variant dimensions of a parent product in position order.
+func (r *VariantRepository) ListDimensions(ctx context.Context, tenantID, productID uuid.UUID) ([]domain.VariantDimension, error) {
+	return listVariantDimensions(ctx, r.db, tenantID, productID, "")
+}
+
+// LockDimensions lists and locks the variant dimensions of a product before changing them.
+func (r *VariantRepository) LockDimensions(ctx context.Context, tx pgx.Tx, tenantID, productID uuid.UUID) ([]domain.VariantDimension, error) {
+	return listVariantDimensions(ctx, tx, tenantID, productID, "FOR UPDATE")
+}
+
+// ReplaceDimensions replaces all variant dimensions of a product that has no variants yet.
+func (r *VariantRepository) ReplaceDimensions(ctx context.Context, tx pgx.Tx, tenantID, productID uuid.UUID, dimensions []domain.VariantDimension) error {
+	if _, err := tx.Exec(ctx, `
+		DELETE FROM product_variant_dimensions WHERE tenant_id = $1 AND product_id = $2
+	`, tenantID, productID); err != nil {
+		return fmt.Errorf("failed to clear variant dimensions: %w", err)
+	}
+	for _, d := range dimensions {
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO product_variant_dimensions (id, tenant_id, product_id, name, position, "values")
+			VALUES ($1, $2, $3, $4, $5, $6)
+		`, d.ID, tenantID, productID, d.Name, d.Position, d.Values); err != nil {
+			return fmt.Errorf("failed to create variant dimension: %w", err)
+		}
+	}
+	return nil
+}
+
+// SetDimensionValues replaces the values of a dimension.
+func (r *VariantRepository) SetDimensionValues(ctx context.Context, tx pgx.Tx, tenantID, dimensionID uuid.UUID, values []string) error {
+	_, err := tx.Exec(ctx, `
+		UPDATE product_variant_dimensions SET "values" = $3 WHERE tenant_id = $1 AND id = $2
+	`, tenantID, dimensionID, values)
+	if err != nil {
+		return fmt.Errorf("failed to update variant dimension: %w", err)
+	}
+	return nil
+}
+
+// ListVariantKeys returns the variant keys of a parent's variants, deleted ones included.
+func (r *VariantRepository) ListVariantKeys(ctx context.Context, tx pgx.Tx, tenantID, parentID uuid.UUID) ([]string, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT variant_key FROM products WHERE tenant_id = $1 AND parent_id = $2
+	`, tenantID, parentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list variants: %w", err)
+	}
+	defer rows.Close()
+
+	keys := []string{}
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, fmt.Errorf("failed to scan variant key: %w", err)
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+// CreateVariant inserts a variant with its dimension values. It reports false without
+// inserting if the SKU is already taken.
+func (r *VariantRepository) CreateVariant(ctx context.Context, tx pgx.Tx, v *domain.Product, values map[uuid.UUID]string) (bool, error) {
+	err := tx.QueryRow(ctx, `
+		INSERT INTO products (id, tenant_id, name, sku, barcode, unit, price, vat_rate, standard_cost, track_lots, track_serials, category_id, brand_id, parent_id, variant_key, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, NOW(), NOW())
+		ON CONFLICT (tenant_id, sku) DO NOTHING
+		RETURNING created_at, updated_at
+	`, v.ID, v.TenantID, v.Name, v.SKU, v.Barcode, v.Unit, v.Price, v.VATRate, v.StandardCost, v.TrackLots, v.TrackSerials,
+		v.CategoryID, v.BrandID, v.ParentID, v.VariantKey).Scan(&v.CreatedAt, &v.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to create variant: %w", err)
+	}
+	for dimensionID, value := range values {
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO product_variant_values (tenant_id, product_id, dimension_id, value)
+			VALUES ($1, $2, $3, $4)
+		`, v.TenantID, v.ID, dimensionID, value); err != nil {
+			return false, fmt.Errorf("failed to create variant value: %w", err)
+		}
+	}
+	return true, nil
+}
+
+// NextBarcodeNumber returns the tenant's next number for generated variant barcodes.
+func (r *VariantRepository) NextBarcodeNumber(ctx context.Context, tx pgx.Tx, tenantID uuid.UUID) (int, error) {
+	return nextSequence(ctx, tx, tenantID, "VARIANT_BARCODE")
+}
+
+// SetPrice sets a product's sale price. It reports whether the product exists.
+func (r *VariantRepository) SetPrice(ctx context.Context, tx pgx.Tx, tenantID, productID uuid.UUID, price decimal.Decimal) (bool, error) {
+	tag, err := tx.Exec(ctx, `
+		UPDATE products SET price = $3, updated_at = NOW()
+		WHERE tenant_id = $1 AND id = $2 AND deleted_at IS NULL
+	`, tenantID, productID, price)
+	if err != nil {
+		return false, fmt.Errorf("failed to set price: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// GetVariantSummary returns each variant of a parent with its stock and the quantity and
+// revenue invoiced in [from, to), optionally limited to one warehouse.
+func (r *VariantRepository) GetVariantSummary(ctx context.Context, tenantID, parentID uuid.UUID, warehouseID *uuid.UUID, from, to *time.Time) ([]domain.VariantSummaryLine, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT p.id, p.name, p.sku, COALESCE(p.barcode, ''), p.variant_key, p.price,
+			COALESCE((
+				SELECT SUM(sb.quantity) FROM stock_balances sb
+				WHERE sb.tenant_id = p.tenant_id AND sb.product_id = p.id
+					AND ($3::uuid IS NULL OR sb.warehouse_id = $3)
+			), 0)::int,
+			COALESCE(sales.quantity, 0)::int,
+			COALESCE(sales.revenue, 0)
+		FROM products p
+		LEFT JOIN LATERAL (
+			SELECT SUM(ii.quantity) AS quantity, SUM(ii.total) AS revenue
+			FROM invoice_items ii
+			JOIN invoices i ON i.id = ii.invoice_id AND i.tenant_id = ii.tenant_id
+			WHERE ii.tenant_id = p.tenant_id AND ii.product_id = p.id AND i.deleted_at IS NULL
+				AND ($3::uuid IS NULL OR i.warehouse_id = $3)
+				AND ($4::timestamp IS NULL OR i.created_at >= $4)
+				AND ($5::timestamp IS NULL OR i.created_at < $5)
+		) sales ON TRUE
+		WHERE p.tenant_id = $1 AND p.parent_id = $2 AND p.deleted_at IS NULL
+	`, tenantID, parentID, warehouseID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get variant summary: %w", err)
+	}
+	defer rows.Close()
+
+	lines := []domain.VariantSummaryLine{}
+	index := make(map[uuid.UUID]int)
+	for rows.Next() {
+		line := domain.VariantSummaryLine{Options: map[string]string{}}
+		if err := rows.Scan(&line.ProductID, &line.Name, &line.SKU, &line.Barcode, &line.VariantKey, &line.Price,
+			&line.Stock, &line.SoldQuantity, &line.Revenue); err != nil {
+			return nil, fmt.Errorf("failed to scan variant summary line: %w", err)
+		}
+		index[line.ProductID] = len(lines)
+		lines = append(lines, line)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get variant summary: %w", err)
+	}
+
+	rows, err = r.db.Query(ctx, `
+		SELECT v.product_id, d.name, v.value
+		FROM product_variant_values v
+		JOIN product_variant_dimensions d ON d.id = v.dimension_id
+		WHERE d.tenant_id = $1 AND d.product_id = $2
+	`, tenantID, parentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list variant values: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var productID uuid.UUID
+		var name, value string
+		if err := rows.Scan(&productID, &name, &value); err != nil {
+			return nil, fmt.Errorf("failed to scan variant value: %w", err)
+		}
+		if i, ok := index[productID]; ok {
+			lines[i].Options[name] = value
+		}
+	}
+	return lines, rows.Err()
+}
+
+// productIsVariantParent reports whether the product has variant dimensions; such a product
+// keeps its stock on its variants.
+func productIsVariantParent(ctx context.Context, q dbtx, tenantID, productID uuid.UUID) (bool, error) {
+	var parent bool
+	err := q.QueryRow(ctx, `
+		SELECT EXISTS (SELECT 1 FROM product_variant_dimensions WHERE tenant_id = $1 AND product_id = $2)
+	`, tenantID, productID).Scan(&parent)
+	if err != nil {
+		return false, fmt.Errorf("failed to check variants of product %s: %w", productID, err)
+	}
+	return parent, nil
+}
+
+func getProduct(ctx context.Context, q dbtx, tenantID, productID uuid.UUID, lock string) (*domain.Product, error) {
+	var p domain.Product
+	err := scanProduct(q.QueryRow(ctx, `
+		SELECT `+productColumns+`
+		FROM products
+		WHERE tenant_id = $1 AND id = $2 AND deleted_at IS NULL
+		`+lock, tenantID, productID), &p)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product: %w", err)
+	}
+	return &p, nil
+}
+
+func listVariantDimensions(ctx context.Context, q dbtx, tenantID, productID uuid.UUID, lock string) ([]domain.VariantDimension, error) {
+	rows, err := q.Query(ctx, `
+		SELECT id, tenant_id, product_id, name, position, "values"
+		FROM product_variant_dimensions
+		WHERE tenant_id = $1 AND product_id = $2
+		ORDER BY position
+		`+lock, tenantID, productID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list variant dimensions: %w", err)
+	}
+	defer rows.Close()
+
+	dimensions := []domain.VariantDimension{}
+	for rows.Next() {
+		var d domain.VariantDimension
+		if err := rows.Scan(&d.ID, &d.TenantID, &d.ProductID, &d.Name, &d.Position, &d.Values); err != nil {
+			return nil, fmt.Errorf("failed to scan variant dimension: %w", err)
+		}
+		dimensions = append(dimensions, d)
+	}
+	return dimensions, rows.Err()
+}
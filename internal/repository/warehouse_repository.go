@@ -18,23 +18,29 @@ func NewWarehouseRepository(db *pgxpool.Pool) *WarehouseRepository {
 	return &WarehouseRepository{db: db}
 }
 
-func (r *WarehouseRepository) CreateWarehouse(ctx context.Context, w *domain.Warehouse) error {
+// CreateAuditLog records a change to a warehouse.
+func (r *WarehouseRepository) CreateAuditLog(ctx context.Context, tx pgx.Tx, log *domain.AuditLog) error {
+	return createAuditLog(ctx, r.db, tx, log)
+}
+
+func (r *WarehouseRepository) CreateWarehouse(ctx context.Context, tx pgx.Tx, w *domain.Warehouse) error {
 	query := `
-		INSERT INTO warehouses (id, tenant_id, name, location, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, NOW(), NOW())
+		INSERT INTO warehouses (id, tenant_id, name, location, is_quarantine, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, NOW(), NOW())
 		RETURNING created_at, updated_at
 	`
-	return r.db.QueryRow(ctx, query,
+	return tx.QueryRow(ctx, query,
 		w.ID,
 		w.TenantID,
 		w.Name,
 		w.Location,
+		w.IsQuarantine,
 	).Scan(&w.CreatedAt, &w.UpdatedAt)
 }
 
 func (r *WarehouseRepository) ListWarehouses(ctx context.Context, tenantID uuid.UUID) ([]domain.Warehouse, error) {
 	query := `
-		SELECT id, tenant_id, name, location, created_at, updated_at
+		SELECT id, tenant_id, name, location, is_quarantine, created_at, updated_at
 		FROM warehouses
 		WHERE tenant_id = $1
 		ORDER BY created_at ASC
@@ -49,7 +55,7 @@ func (r *WarehouseRepository) ListWarehouses(ctx context.Context, tenantID uuid.
 	for rows.Next() {
 		var w domain.Warehouse
 		if err := rows.Scan(
-			&w.ID, &w.TenantID, &w.Name, &w.Location, &w.CreatedAt, &w.UpdatedAt,
+			&w.ID, &w.TenantID, &w.Name, &w.Location, &w.IsQuarantine, &w.CreatedAt, &w.UpdatedAt,
 		); err != nil {
 			return nil, fmt.Errorf("failed to scan warehouse: %w", err)
 		}
@@ -60,7 +66,7 @@ func (r *WarehouseRepository) ListWarehouses(ctx context.Context, tenantID uuid.
 
 func (r *WarehouseRepository) GetWarehouseByID(ctx context.Context, tenantID, warehouseID uuid.UUID) (*domain.Warehouse, error) {
 	query := `
-		SELECT id, tenant_id, name, location, created_at, updated_at
+		SELECT id, tenant_id, name, location, is_quarantine, created_at, updated_at
 		FROM warehouses
 		WHERE id = $1 AND tenant_id = $2
 	`
@@ -68,7 +74,7 @@ func (r *WarehouseRepository) GetWarehouseByID(ctx context.Context, tenantID, wa
 
 	var w domain.Warehouse
 	err := row.Scan(
-		&w.ID, &w.TenantID, &w.Name, &w.Location, &w.CreatedAt, &w.UpdatedAt,
+		&w.ID, &w.TenantID, &w.Name, &w.Location, &w.IsQuarantine, &w.CreatedAt, &w.UpdatedAt,
 	)
 	if err != nil {
 		if err == pgx.ErrNoRows {
@@ -0,0 +1,459 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"sancaksoft/internal/domain"
+	"sancaksoft/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shopspring/decimal"
+)
+
+// AssemblyService keeps bills of materials and runs assembly orders. Completing an order
+// consumes the components with OUT movements and produces the product with an IN movement
+// carrying the rolled-up component cost, all in one transaction.
+type AssemblyService struct {
+	db       *pgxpool.Pool
+	repo     *repository.AssemblyRepository
+	invoices *InvoiceService
+}
+
+func NewAssemblyService(db *pgxpool.Pool, repo *repository.AssemblyRepository, invoices *InvoiceService) *AssemblyService {
+	return &AssemblyService{db: db, repo: repo, invoices: invoices}
+}
+
+// GetBOM returns the bill of materials of a product.
+func (s *AssemblyService) GetBOM(ctx context.Context, tenantID, productID uuid.UUID) (*domain.BillOfMaterials, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	bom, err := s.repo.GetBOM(ctx, tenantID, productID)
+	if err != nil {
+		return nil, err
+	}
+	if bom == nil {
+		return nil, fmt.Errorf("bill of materials of product %s: %w", productID, ErrNotFound)
+	}
+	return bom, nil
+}
+
+// SetBOM creates or replaces the bill of materials of a product. Components must be
+// stocked, non-serialized products that do not (even indirectly) contain the product.
+func (s *AssemblyService) SetBOM(ctx context.Context, bom *domain.BillOfMaterials) (*domain.BillOfMaterials, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	if bom.OutputQuantity == 0 {
+		bom.OutputQuantity = 1
+	}
+	if bom.OutputQuantity < 0 {
+		return nil, fmt.Errorf("output quantity must be greater than zero: %w", ErrInvalidInput)
+	}
+	if len(bom.Components) == 0 {
+		return nil, fmt.Errorf("bill of materials must have at least one component: %w", ErrInvalidInput)
+	}
+	seen := make(map[uuid.UUID]bool, len(bom.Components))
+	for _, c := range bom.Components {
+		if c.Quantity <= 0 {
+			return nil, fmt.Errorf("component quantity must be greater than zero: %w", ErrInvalidInput)
+		}
+		if c.ComponentID == bom.ProductID {
+			return nil, fmt.Errorf("a product cannot be its own component: %w", ErrInvalidInput)
+		}
+		if seen[c.ComponentID] {
+			return nil, fmt.Errorf("component %s is listed twice: %w", c.ComponentID, ErrInvalidInput)
+		}
+		seen[c.ComponentID] = true
+	}
+
+	err := WithTransaction(ctx, s.db, func(tx pgx.Tx) error {
+		product, err := s.repo.LockProduct(ctx, tx, bom.TenantID, bom.ProductID)
+		if err != nil {
+			return err
+		}
+		if product == nil {
+			return fmt.Errorf("product %s: %w", bom.ProductID, ErrNotFound)
+		}
+		if err := s.checkStocked(ctx, tx, bom.TenantID, bom.ProductID); err != nil {
+			return err
+		}
+		for _, c := range bom.Components {
+			component, err := s.repo.GetProduct(ctx, tx, bom.TenantID, c.ComponentID)
+			if err != nil {
+				return err
+			}
+			if component == nil {
+				return fmt.Errorf("component product %s: %w", c.ComponentID, ErrNotFound)
+			}
+			if err := s.checkStocked(ctx, tx, bom.TenantID, c.ComponentID); err != nil {
+				return err
+			}
+			cycle, err := s.repo.BOMContains(ctx, tx, bom.TenantID, c.ComponentID, bom.ProductID)
+			if err != nil {
+				return err
+			}
+			if cycle {
+				return fmt.Errorf("component %s is itself made from product %s: %w", c.ComponentID, bom.ProductID, ErrInvalidInput)
+			}
+		}
+		current, err := s.repo.GetBOMForUpdate(ctx, tx, bom.TenantID, bom.ProductID)
+		if err != nil {
+			return err
+		}
+		if err := s.repo.SaveBOM(ctx, tx, bom); err != nil {
+			return err
+		}
+		saved, err := s.repo.GetBOMForUpdate(ctx, tx, bom.TenantID, bom.ProductID)
+		if err != nil {
+			return err
+		}
+		action := domain.AuditActionUpdate
+		if current == nil {
+			action = domain.AuditActionCreate
+		}
+		return recordAudit(ctx, s.repo, tx, domain.AuditLog{
+			TenantID: bom.TenantID, EntityType: domain.AuditEntityBOM, EntityID: bom.ProductID, Action: action,
+		}, current, saved)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.GetBOM(ctx, bom.TenantID, bom.ProductID)
+}
+
+// checkStocked rejects products whose stock cannot be consumed or produced by an assembly
+// order: kits and variant parents hold no stock, and serial numbers are not assigned here.
+func (s *AssemblyService) checkStocked(ctx context.Context, tx pgx.Tx, tenantID, productID uuid.UUID) error {
+	kit, err := s.repo.ProductIsKit(ctx, tx, tenantID, productID)
+	if err != nil {
+		return err
+	}
+	if kit {
+		return fmt.Errorf("product %s is a kit and holds no stock: %w", productID, ErrInvalidInput)
+	}
+	parent, err := s.repo.ProductIsVariantParent(ctx, tx, tenantID, productID)
+	if err != nil {
+		return err
+	}
+	if parent {
+		return fmt.Errorf("product %s has variants; use a variant instead: %w", productID, ErrInvalidInput)
+	}
+	serialized, err := s.repo.ProductTracksSerials(ctx, tx, tenantID, productID)
+	if err != nil {
+		return err
+	}
+	if serialized {
+		return fmt.Errorf("product %s is serialized and cannot be assembled: %w", productID, ErrInvalidInput)
+	}
+	return nil
+}
+
+// DeleteBOM removes the bill of materials of a product.
+func (s *AssemblyService) DeleteBOM(ctx context.Context, tenantID, productID uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	return WithTransaction(ctx, s.db, func(tx pgx.Tx) error {
+		bom, err := s.repo.GetBOMForUpdate(ctx, tx, tenantID, productID)
+		if err != nil {
+			return err
+		}
+		if bom == nil {
+			return fmt.Errorf("bill of materials of product %s: %w", productID, ErrNotFound)
+		}
+		if _, err := s.repo.DeleteBOM(ctx, tx, tenantID, productID); err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.repo, tx, domain.AuditLog{
+			TenantID: tenantID, EntityType: domain.AuditEntityBOM, EntityID: productID, Action: domain.AuditActionDelete,
+		}, bom, nil)
+	})
+}
+
+// CreateAssemblyOrder plans the production of a product. The bill of materials is scaled to
+// the planned quantity and copied onto the order, rounding each component up to whole units.
+// Planned orders reserve nothing.
+func (s *AssemblyService) CreateAssemblyOrder(ctx context.Context, req domain.CreateAssemblyOrderRequest) (*domain.AssemblyOrder, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	if req.Quantity <= 0 {
+		return nil, fmt.Errorf("quantity must be greater than zero: %w", ErrInvalidInput)
+	}
+
+	var created *domain.AssemblyOrder
+	err := WithTransaction(ctx, s.db, func(tx pgx.Tx) error {
+		isQuarantine, err := s.invoices.repo.IsQuarantineWarehouse(ctx, tx, req.TenantID, req.WarehouseID)
+		if err != nil {
+			return err
+		}
+		if isQuarantine {
+			return fmt.Errorf("cannot assemble in quarantine warehouse %s: %w", req.WarehouseID, ErrInvalidInput)
+		}
+		bom, err := s.repo.GetBOMForUpdate(ctx, tx, req.TenantID, req.ProductID)
+		if err != nil {
+			return err
+		}
+		if bom == nil {
+			return fmt.Errorf("product %s has no bill of materials: %w", req.ProductID, ErrInvalidState)
+		}
+
+		orderNumber, err := s.repo.GenerateNextOrderNumber(ctx, tx, req.TenantID)
+		if err != nil {
+			return err
+		}
+		order := &domain.AssemblyOrder{
+			ID:          uuid.New(),
+			TenantID:    req.TenantID,
+			ProductID:   req.ProductID,
+			WarehouseID: req.WarehouseID,
+			OrderNumber: orderNumber,
+			Status:      domain.AssemblyOrderStatusPlanned,
+			Quantity:    req.Quantity,
+			Note:        req.Note,
+		}
+		if err := s.repo.CreateAssemblyOrder(ctx, tx, order); err != nil {
+			return fmt.Errorf("failed to create assembly order: %w", err)
+		}
+		for _, c := range bom.Components {
+			component := domain.AssemblyOrderComponent{
+				ID:        uuid.New(),
+				TenantID:  req.TenantID,
+				OrderID:   order.ID,
+				ProductID: c.ComponentID,
+				Quantity:  scaleComponent(c.Quantity, req.Quantity, bom.OutputQuantity),
+			}
+			if err := s.repo.CreateAssemblyOrderComponent(ctx, tx, &component); err != nil {
+				return fmt.Errorf("failed to create assembly order component: %w", err)
+			}
+			order.Components = append(order.Components, component)
+		}
+		created = order
+		return recordAudit(ctx, s.repo, tx, domain.AuditLog{
+			TenantID: order.TenantID, EntityType: domain.AuditEntityAssemblyOrder, EntityID: order.ID, Action: domain.AuditActionCreate,
+		}, nil, order)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// scaleComponent returns the whole units of a component needed for quantity units of output
+// when perOutput units make outputQuantity, e.g. 1 sack per 25 bags needs 2 sacks for 40 bags.
+func scaleComponent(perOutput, quantity, outputQuantity int) int {
+	return (perOutput*quantity + outputQuantity - 1) / outputQuantity
+}
+
+// CompleteAssemblyOrder records the outcome of a planned order and moves stock: every
+// component is checked against its unreserved stock under lock and consumed (lot-tracked
+// components first to expire first), then the produced quantity is received at the cost of
+// all consumed components. Scrapped output still consumed its components, so it raises the
+// unit cost of the good output.
+func (s *AssemblyService) CompleteAssemblyOrder(ctx context.Context, req domain.CompleteAssemblyOrderRequest) (*domain.AssemblyOrder, error) {
+	if req.ProducedQty <= 0 {
+		return nil, fmt.Errorf("produced quantity must be greater than zero: %w", ErrInvalidInput)
+	}
+	if req.ScrapQty < 0 {
+		return nil, fmt.Errorf("scrap quantity cannot be negative: %w", ErrInvalidInput)
+	}
+
+	return s.withOrder(ctx, req.TenantID, req.OrderID, domain.AuditActionComplete, func(tx pgx.Tx, order *domain.AssemblyOrder) error {
+		if order.Status != domain.AssemblyOrderStatusPlanned {
+			return fmt.Errorf("assembly order is %s, expected %s: %w", order.Status, domain.AssemblyOrderStatusPlanned, ErrInvalidState)
+		}
+		if sum := req.ProducedQty + req.ScrapQty; sum != order.Quantity {
+			return fmt.Errorf("produced and scrapped quantity %d does not match planned quantity %d: %w", sum, order.Quantity, ErrInvalidInput)
+		}
+
+		// Lock the product and its components in id order so concurrent orders sharing
+		// components cannot deadlock.
+		locks := []uuid.UUID{order.ProductID}
+		for _, c := range order.Components {
+			locks = append(locks, c.ProductID)
+		}
+		sort.Slice(locks, func(i, j int) bool { return bytes.Compare(locks[i][:], locks[j][:]) < 0 })
+		for _, productID := range locks {
+			if err := s.invoices.repo.LockProduct(ctx, tx, order.TenantID, productID); err != nil {
+				return err
+			}
+		}
+		if err := s.checkStocked(ctx, tx, order.TenantID, order.ProductID); err != nil {
+			return err
+		}
+		lotID, err := s.receiveLot(ctx, tx, order.TenantID, order.ProductID, req.LotNumber, req.ExpiryDate)
+		if err != nil {
+			return err
+		}
+
+		refType := "ASSEMBLY_ORDER"
+		totalCost := decimal.Zero
+		for i := range order.Components {
+			c := &order.Components[i]
+			onHand, err := s.invoices.repo.GetStockBalance(ctx, tx, order.TenantID, c.ProductID, order.WarehouseID)
+			if err != nil {
+				return err
+			}
+			reserved, err := s.invoices.repo.GetReservedQuantity(ctx, tx, order.TenantID, c.ProductID, order.WarehouseID, nil)
+			if err != nil {
+				return err
+			}
+			if available := onHand - reserved; available < c.Quantity {
+				return fmt.Errorf("insufficient stock for component %s. Available: %d, Requested: %d: %w", c.ProductID, available, c.Quantity, ErrInvalidState)
+			}
+			if c.UnitCost, err = s.invoices.repo.GetSaleUnitCost(ctx, tx, order.TenantID, c.ProductID); err != nil {
+				return err
+			}
+			if err := s.repo.SetComponentUnitCost(ctx, tx, order.TenantID, c.ID, c.UnitCost); err != nil {
+				return fmt.Errorf("failed to record component cost: %w", err)
+			}
+			totalCost = totalCost.Add(c.UnitCost.Mul(decimal.NewFromInt(int64(c.Quantity))))
+
+			allocations, err := s.invoices.allocateSale(ctx, tx, order.TenantID, c.ProductID, order.WarehouseID, c.Quantity, "", nil)
+			if err != nil {
+				return err
+			}
+			for _, allocation := range allocations {
+				movement := &domain.StockMovement{
+					ID:            uuid.New(),
+					TenantID:      order.TenantID,
+					ProductID:     c.ProductID,
+					WarehouseID:   order.WarehouseID,
+					Quantity:      -allocation.quantity,
+					Type:          domain.StockMovementTypeOut,
+					ReferenceID:   &order.ID,
+					ReferenceType: &refType,
+					LotID:         allocation.lotID,
+					LocationID:    allocation.locationID,
+				}
+				if err := s.invoices.repo.CreateStockMovement(ctx, tx, movement); err != nil {
+					return fmt.Errorf("failed to create stock movement: %w", err)
+				}
+			}
+		}
+
+		unitCost := totalCost.Div(decimal.NewFromInt(int64(req.ProducedQty))).Round(4)
+		movement := &domain.StockMovement{
+			ID:            uuid.New(),
+			TenantID:      order.TenantID,
+			ProductID:     order.ProductID,
+			WarehouseID:   order.WarehouseID,
+			Quantity:      req.ProducedQty,
+			Type:          domain.StockMovementTypeIn,
+			ReferenceID:   &order.ID,
+			ReferenceType: &refType,
+			UnitCost:      &unitCost,
+			LotID:         lotID,
+		}
+		if err := s.invoices.repo.CreateStockMovement(ctx, tx, movement); err != nil {
+			return fmt.Errorf("failed to create stock movement: %w", err)
+		}
+
+		now := time.Now()
+		order.Status = domain.AssemblyOrderStatusCompleted
+		order.ProducedQty = req.ProducedQty
+		order.ScrapQty = req.ScrapQty
+		order.LotID = lotID
+		order.TotalCost = totalCost.Round(2)
+		order.UnitCost = unitCost
+		order.CompletedAt = &now
+		return s.repo.UpdateAssemblyOrder(ctx, tx, order)
+	})
+}
+
+// receiveLot returns the lot the produced goods go into; nil for products without lot tracking.
+func (s *AssemblyService) receiveLot(ctx context.Context, tx pgx.Tx, tenantID, productID uuid.UUID, lotNumber string, expiryDate *time.Time) (*uuid.UUID, error) {
+	lotNumber = strings.TrimSpace(lotNumber)
+	tracked, err := s.repo.ProductTracksLots(ctx, tx, tenantID, productID)
+	if err != nil {
+		return nil, err
+	}
+	if !tracked {
+		if lotNumber != "" || expiryDate != nil {
+			return nil, fmt.Errorf("product %s is not lot-tracked: %w", productID, ErrInvalidInput)
+		}
+		return nil, nil
+	}
+	if lotNumber == "" {
+		return nil, fmt.Errorf("lot number is required for lot-tracked product %s: %w", productID, ErrInvalidInput)
+	}
+	lot, err := s.repo.EnsureLot(ctx, tx, tenantID, productID, lotNumber, expiryDate)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkLotExpiry(lot, expiryDate); err != nil {
+		return nil, err
+	}
+	return &lot.ID, nil
+}
+
+// CancelAssemblyOrder cancels a planned order; nothing has moved yet.
+func (s *AssemblyService) CancelAssemblyOrder(ctx context.Context, tenantID, orderID uuid.UUID) (*domain.AssemblyOrder, error) {
+	return s.withOrder(ctx, tenantID, orderID, domain.AuditActionCancel, func(tx pgx.Tx, order *domain.AssemblyOrder) error {
+		if order.Status != domain.AssemblyOrderStatusPlanned {
+			return fmt.Errorf("assembly order is %s, expected %s: %w", order.Status, domain.AssemblyOrderStatusPlanned, ErrInvalidState)
+		}
+		order.Status = domain.AssemblyOrderStatusCancelled
+		return s.repo.UpdateAssemblyOrder(ctx, tx, order)
+	})
+}
+
+// withOrder runs fn on the locked order and audits the change under action.
+func (s *AssemblyService) withOrder(ctx context.Context, tenantID, orderID uuid.UUID, action string, fn func(tx pgx.Tx, order *domain.AssemblyOrder) error) (*domain.AssemblyOrder, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	var result *domain.AssemblyOrder
+	err := WithTransaction(ctx, s.db, func(tx pgx.Tx) error {
+		order, err := s.repo.GetAssemblyOrderForUpdate(ctx, tx, tenantID, orderID)
+		if err != nil {
+			return err
+		}
+		if order == nil {
+			return fmt.Errorf("assembly order %s: %w", orderID, ErrNotFound)
+		}
+		before := *order
+		before.Components = slices.Clone(order.Components)
+		if err := fn(tx, order); err != nil {
+			return err
+		}
+		result = order
+		return recordAudit(ctx, s.repo, tx, domain.AuditLog{
+			TenantID: tenantID, EntityType: domain.AuditEntityAssemblyOrder, EntityID: orderID, Action: action,
+		}, &before, order)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *AssemblyService) GetAssemblyOrder(ctx context.Context, tenantID, orderID uuid.UUID) (*domain.AssemblyOrder, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	order, err := s.repo.GetAssemblyOrder(ctx, tenantID, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order == nil {
+		return nil, fmt.Errorf("assembly order %s: %w", orderID, ErrNotFound)
+	}
+	return order, nil
+}
+
+func (s *AssemblyService) ListAssemblyOrders(ctx context.Context, tenantID uuid.UUID, productID *uuid.UUID, status domain.AssemblyOrderStatus) ([]domain.AssemblyOrder, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	return s.repo.ListAssemblyOrders(ctx, tenantID, productID, status)
+}
@@ -0,0 +1,135 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"sancaksoft/internal/domain"
+	"sancaksoft/internal/repository"
+	"sancaksoft/internal/service"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAssemblyOrders_Integration(t *testing.T) {
+	if testDBURL == "" {
+		t.Skip("Skipping integration test: TEST_DB_URL not set")
+	}
+
+	ctx := context.Background()
+	db, err := pgxpool.New(ctx, testDBURL)
+	require.NoError(t, err)
+	defer db.Close()
+
+	// 1. Setup Data: 25 kg sacks repacked into 1 kg bags, one film per bag
+	tenantID := uuid.New()
+	warehouseID := uuid.New()
+	sackID := uuid.New()
+	filmID := uuid.New()
+	bagID := uuid.New()
+
+	defer func() {
+		_, _ = db.Exec(ctx, "DELETE FROM tenants WHERE id = $1", tenantID)
+	}()
+
+	_, err = db.Exec(ctx, "INSERT INTO tenants (id, name) VALUES ($1, 'Assembly Test Tenant')", tenantID)
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, "INSERT INTO warehouses (id, tenant_id, name) VALUES ($1, $2, 'Main')", warehouseID, tenantID)
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, "INSERT INTO products (id, tenant_id, name, sku, price, standard_cost) VALUES ($1, $2, 'Rice 25 kg', $3, 80.00, 50.00)", sackID, tenantID, "SKU-"+uuid.New().String())
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, "INSERT INTO products (id, tenant_id, name, sku, price, standard_cost) VALUES ($1, $2, 'Bag Film', $3, 0.50, 0.20)", filmID, tenantID, "SKU-"+uuid.New().String())
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, "INSERT INTO products (id, tenant_id, name, sku, price) VALUES ($1, $2, 'Rice 1 kg', $3, 5.00)", bagID, tenantID, "SKU-"+uuid.New().String())
+	require.NoError(t, err)
+
+	invoiceService := service.NewInvoiceService(db, repository.NewInvoiceRepository())
+	assemblyService := service.NewAssemblyService(db, repository.NewAssemblyRepository(db), invoiceService)
+	stockService := service.NewStockService(db, repository.NewStockRepository(db))
+
+	for productID, quantity := range map[uuid.UUID]int{sackID: 3, filmID: 100} {
+		require.NoError(t, stockService.CreateStockMovement(ctx, tenantID, &domain.StockMovement{
+			ID: uuid.New(), ProductID: productID, WarehouseID: warehouseID, Quantity: quantity, Type: domain.StockMovementTypeIn,
+		}))
+	}
+
+	// 2. Bill of materials; cycles are rejected
+	bom, err := assemblyService.SetBOM(ctx, &domain.BillOfMaterials{
+		TenantID:       tenantID,
+		ProductID:      bagID,
+		OutputQuantity: 25,
+		Components: []domain.BOMComponent{
+			{ComponentID: sackID, Quantity: 1},
+			{ComponentID: filmID, Quantity: 25},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, bom.Components, 2)
+
+	_, err = assemblyService.SetBOM(ctx, &domain.BillOfMaterials{
+		TenantID:   tenantID,
+		ProductID:  sackID,
+		Components: []domain.BOMComponent{{ComponentID: bagID, Quantity: 25}},
+	})
+	assert.ErrorIs(t, err, service.ErrInvalidInput)
+
+	// 3. Planning 40 bags needs 2 sacks (rounded up) and 40 films
+	order, err := assemblyService.CreateAssemblyOrder(ctx, domain.CreateAssemblyOrderRequest{
+		TenantID: tenantID, ProductID: bagID, WarehouseID: warehouseID, Quantity: 40,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, domain.AssemblyOrderStatusPlanned, order.Status)
+	required := map[uuid.UUID]int{}
+	for _, c := range order.Components {
+		required[c.ProductID] = c.Quantity
+	}
+	assert.Equal(t, map[uuid.UUID]int{sackID: 2, filmID: 40}, required)
+
+	// 4. Completion must account for the planned quantity
+	_, err = assemblyService.CompleteAssemblyOrder(ctx, domain.CompleteAssemblyOrderRequest{
+		TenantID: tenantID, OrderID: order.ID, ProducedQty: 30,
+	})
+	assert.ErrorIs(t, err, service.ErrInvalidInput)
+
+	completed, err := assemblyService.CompleteAssemblyOrder(ctx, domain.CompleteAssemblyOrderRequest{
+		TenantID: tenantID, OrderID: order.ID, ProducedQty: 38, ScrapQty: 2,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, domain.AssemblyOrderStatusCompleted, completed.Status)
+	// 2 x 50 + 40 x 0.20 = 108 over 38 good bags
+	assert.True(t, completed.TotalCost.Equal(decimal.NewFromInt(108)))
+	assert.True(t, completed.UnitCost.Equal(decimal.RequireFromString("2.8421")))
+	assert.True(t, completed.YieldPct().Equal(decimal.NewFromInt(95)))
+
+	for productID, expected := range map[uuid.UUID]int{sackID: 1, filmID: 60, bagID: 38} {
+		balance, err := stockService.GetStockBalance(ctx, tenantID, productID, warehouseID)
+		require.NoError(t, err)
+		assert.Equal(t, expected, balance)
+	}
+
+	_, err = assemblyService.CompleteAssemblyOrder(ctx, domain.CompleteAssemblyOrderRequest{
+		TenantID: tenantID, OrderID: order.ID, ProducedQty: 40,
+	})
+	assert.ErrorIs(t, err, service.ErrInvalidState)
+
+	// 5. Not enough sacks left for another 40 bags; nothing moves
+	second, err := assemblyService.CreateAssemblyOrder(ctx, domain.CreateAssemblyOrderRequest{
+		TenantID: tenantID, ProductID: bagID, WarehouseID: warehouseID, Quantity: 40,
+	})
+	require.NoError(t, err)
+	_, err = assemblyService.CompleteAssemblyOrder(ctx, domain.CompleteAssemblyOrderRequest{
+		TenantID: tenantID, OrderID: second.ID, ProducedQty: 40,
+	})
+	assert.ErrorIs(t, err, service.ErrInvalidState)
+	films, err := stockService.GetStockBalance(ctx, tenantID, filmID, warehouseID)
+	require.NoError(t, err)
+	assert.Equal(t, 60, films)
+
+	cancelled, err := assemblyService.CancelAssemblyOrder(ctx, tenantID, second.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.AssemblyOrderStatusCancelled, cancelled.Status)
+}
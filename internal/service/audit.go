@@ -0,0 +1,46 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"sancaksoft/internal/audit"
+	"sancaksoft/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// auditLogger is implemented by the repositories of audited services.
+type auditLogger interface {
+	CreateAuditLog(ctx context.Context, tx pgx.Tx, log *domain.AuditLog) error
+}
+
+// recordAudit writes an audit entry for a change made by the actor in ctx. before and after
+// are snapshots of the entity, nil before a create or after a delete; the fields that differ
+// are stored in the details under "changes". tx is nil for changes made outside a
+// transaction.
+func recordAudit(ctx context.Context, l auditLogger, tx pgx.Tx, entry domain.AuditLog, before, after any) error {
+	changes, err := audit.Diff(before, after)
+	if err != nil {
+		return fmt.Errorf("failed to diff audit snapshots: %w", err)
+	}
+	actor := audit.FromContext(ctx)
+	entry.ID = uuid.New()
+	if entry.UserID == nil {
+		entry.UserID = actor.UserID
+	}
+	entry.IPAddress = actor.IP
+	entry.RequestID = actor.RequestID
+	if len(changes) > 0 {
+		details := map[string]any{"changes": changes}
+		for k, v := range entry.Details {
+			details[k] = v
+		}
+		entry.Details = details
+	}
+	if err := l.CreateAuditLog(ctx, tx, &entry); err != nil {
+		return fmt.Errorf("failed to create audit log: %w", err)
+	}
+	return nil
+}
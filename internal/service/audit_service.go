@@ -0,0 +1,33 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"sancaksoft/internal/domain"
+	"sancaksoft/internal/repository"
+)
+
+type AuditService struct {
+	repo *repository.AuditRepository
+}
+
+func NewAuditService(repo *repository.AuditRepository) *AuditService {
+	return &AuditService{repo: repo}
+}
+
+// ListAuditLogs returns the latest audit entries matching the filter, newest first.
+func (s *AuditService) ListAuditLogs(ctx context.Context, f domain.AuditLogFilter) ([]domain.AuditLog, error) {
+	if f.From != nil && f.To != nil && !f.From.Before(*f.To) {
+		return nil, fmt.Errorf("from must be before to: %w", ErrInvalidInput)
+	}
+	f.EntityType = strings.ToUpper(f.EntityType)
+	f.Action = strings.ToUpper(f.Action)
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	return s.repo.ListAuditLogs(ctx, f)
+}
@@ -0,0 +1,86 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"sancaksoft/internal/audit"
+	"sancaksoft/internal/domain"
+	"sancaksoft/internal/repository"
+	"sancaksoft/internal/service"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditLogs_Integration(t *testing.T) {
+	if testDBURL == "" {
+		t.Skip("Skipping integration test: TEST_DB_URL not set")
+	}
+
+	ctx := context.Background()
+	db, err := pgxpool.New(ctx, testDBURL)
+	require.NoError(t, err)
+	defer db.Close()
+
+	// 1. Setup Data
+	tenantID := uuid.New()
+	userID := uuid.New()
+
+	defer func() {
+		_, _ = db.Exec(ctx, "DELETE FROM tenants WHERE id = $1", tenantID)
+	}()
+
+	_, err = db.Exec(ctx, "INSERT INTO tenants (id, name) VALUES ($1, 'Audit Test Tenant')", tenantID)
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, "INSERT INTO users (id, tenant_id, email, password_hash, role) VALUES ($1, $2, $3, 'x', 'user')",
+		userID, tenantID, uuid.New().String()+"@audit.test")
+	require.NoError(t, err)
+
+	productService := service.NewProductService(db, repository.NewProductRepository(db))
+	auditService := service.NewAuditService(repository.NewAuditRepository(db))
+
+	userCtx := audit.WithActor(ctx, audit.Actor{UserID: &userID, IP: "10.0.0.7", RequestID: "req-audit-1"})
+
+	// 2. Create a product and change its standard cost
+	product := &domain.Product{TenantID: tenantID, Name: "Audited", SKU: "SKU-" + uuid.New().String(), Price: decimal.NewFromInt(20)}
+	require.NoError(t, productService.CreateProduct(userCtx, product))
+	cost := decimal.NewFromInt(12)
+	require.NoError(t, productService.SetStandardCost(userCtx, tenantID, product.ID, &cost))
+
+	// 3. Both changes are listed newest first with the actor
+	logs, err := auditService.ListAuditLogs(ctx, domain.AuditLogFilter{TenantID: tenantID, EntityType: "product", EntityID: &product.ID})
+	require.NoError(t, err)
+	require.Len(t, logs, 2)
+	assert.Equal(t, domain.AuditActionUpdate, logs[0].Action)
+	assert.Equal(t, domain.AuditActionCreate, logs[1].Action)
+	for _, l := range logs {
+		require.NotNil(t, l.UserID)
+		assert.Equal(t, userID, *l.UserID)
+		assert.Equal(t, "10.0.0.7", l.IPAddress)
+		assert.Equal(t, "req-audit-1", l.RequestID)
+	}
+
+	// 4. The update records only the changed field
+	changes, ok := logs[0].Details["changes"].(map[string]any)
+	require.True(t, ok, "details: %v", logs[0].Details)
+	assert.Equal(t, map[string]any{"standard_cost": map[string]any{"from": nil, "to": "12"}}, changes)
+
+	createChanges, ok := logs[1].Details["changes"].(map[string]any)
+	require.True(t, ok)
+	assert.Contains(t, createChanges, "name")
+
+	// 5. Filter by user and action
+	otherUser := uuid.New()
+	logs, err = auditService.ListAuditLogs(ctx, domain.AuditLogFilter{TenantID: tenantID, UserID: &otherUser})
+	require.NoError(t, err)
+	assert.Empty(t, logs)
+
+	logs, err = auditService.ListAuditLogs(ctx, domain.AuditLogFilter{TenantID: tenantID, UserID: &userID, Action: "update"})
+	require.NoError(t, err)
+	require.Len(t, logs, 1)
+	assert.Equal(t, product.ID, logs[0].EntityID)
+}
@@ -0,0 +1,423 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"sancaksoft/internal/domain"
+	"sancaksoft/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shopspring/decimal"
+)
+
+// attributeCodePattern keeps attribute codes usable as query parameters (?attr.<code>=).
+var attributeCodePattern = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+// Categories form a tree; a product's attributes are those defined on its category and the
+// category's ancestors. Invoice lines keep the category and brand the product had when it
+// was sold, so recategorizing a product does not move its past sales between report groups.
+type CategoryService struct {
+	db   *pgxpool.Pool
+	repo *repository.CategoryRepository
+}
+
+func NewCategoryService(db *pgxpool.Pool, repo *repository.CategoryRepository) *CategoryService {
+	return &CategoryService{db: db, repo: repo}
+}
+
+// CreateCategory adds a category, under a parent if given.
+func (s *CategoryService) CreateCategory(ctx context.Context, c *domain.ProductCategory) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	c.Name = strings.TrimSpace(c.Name)
+	if c.Name == "" {
+		return fmt.Errorf("category name is required: %w", ErrInvalidInput)
+	}
+	if err := s.checkCategoryPlacement(ctx, c); err != nil {
+		return err
+	}
+
+	c.ID = uuid.New()
+	return WithTransaction(ctx, s.db, func(tx pgx.Tx) error {
+		if err := s.repo.CreateCategory(ctx, tx, c); err != nil {
+			return err
+		}
+		created, err := s.repo.GetCategoryForUpdate(ctx, tx, c.TenantID, c.ID)
+		if err != nil {
+			return err
+		}
+		*c = *created
+		return recordAudit(ctx, s.repo, tx, domain.AuditLog{
+			TenantID: c.TenantID, EntityType: domain.AuditEntityCategory, EntityID: c.ID, Action: domain.AuditActionCreate,
+		}, nil, c)
+	})
+}
+
+// ListCategories returns the category tree in path order.
+func (s *CategoryService) ListCategories(ctx context.Context, tenantID uuid.UUID) ([]domain.ProductCategory, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	return s.repo.ListCategories(ctx, tenantID)
+}
+
+// UpdateCategory renames a category or moves it under another parent (nil for the top level).
+// Attribute values its products lose by a move are removed.
+func (s *CategoryService) UpdateCategory(ctx context.Context, c *domain.ProductCategory) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	c.Name = strings.TrimSpace(c.Name)
+	if c.Name == "" {
+		return fmt.Errorf("category name is required: %w", ErrInvalidInput)
+	}
+	current, err := s.repo.GetCategory(ctx, c.TenantID, c.ID)
+	if err != nil {
+		return err
+	}
+	if current == nil {
+		return fmt.Errorf("category %s: %w", c.ID, ErrNotFound)
+	}
+	if c.ParentID != nil {
+		cycle, err := s.repo.IsInSubtree(ctx, c.TenantID, c.ID, *c.ParentID)
+		if err != nil {
+			return err
+		}
+		if cycle {
+			return fmt.Errorf("category cannot be moved under itself or its subcategories: %w", ErrInvalidInput)
+		}
+	}
+	if err := s.checkCategoryPlacement(ctx, c); err != nil {
+		return err
+	}
+
+	return WithTransaction(ctx, s.db, func(tx pgx.Tx) error {
+		before, err := s.repo.GetCategoryForUpdate(ctx, tx, c.TenantID, c.ID)
+		if err != nil {
+			return err
+		}
+		if before == nil {
+			return fmt.Errorf("category %s: %w", c.ID, ErrNotFound)
+		}
+		if _, err := s.repo.UpdateCategory(ctx, tx, c); err != nil {
+			return err
+		}
+		if !sameUUID(before.ParentID, c.ParentID) {
+			if err := s.repo.PruneAttributeValues(ctx, tx, c.TenantID); err != nil {
+				return err
+			}
+		}
+		updated, err := s.repo.GetCategoryForUpdate(ctx, tx, c.TenantID, c.ID)
+		if err != nil {
+			return err
+		}
+		*c = *updated
+		return recordAudit(ctx, s.repo, tx, domain.AuditLog{
+			TenantID: c.TenantID, EntityType: domain.AuditEntityCategory, EntityID: c.ID, Action: domain.AuditActionUpdate,
+		}, before, c)
+	})
+}
+
+// DeleteCategory deletes a category that has no subcategories, products or sales.
+func (s *CategoryService) DeleteCategory(ctx context.Context, tenantID, categoryID uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	return WithTransaction(ctx, s.db, func(tx pgx.Tx) error {
+		category, err := s.repo.GetCategoryForUpdate(ctx, tx, tenantID, categoryID)
+		if err != nil {
+			return err
+		}
+		if category == nil {
+			return fmt.Errorf("category %s: %w", categoryID, ErrNotFound)
+		}
+		inUse, err := s.repo.CategoryInUse(ctx, tx, tenantID, categoryID)
+		if err != nil {
+			return err
+		}
+		if inUse {
+			return fmt.Errorf("category %s has subcategories, products or sales: %w", categoryID, ErrInvalidState)
+		}
+		if _, err := s.repo.DeleteCategory(ctx, tx, tenantID, categoryID); err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.repo, tx, domain.AuditLog{
+			TenantID: tenantID, EntityType: domain.AuditEntityCategory, EntityID: categoryID, Action: domain.AuditActionDelete,
+		}, category, nil)
+	})
+}
+
+// checkCategoryPlacement verifies the parent exists and no sibling has the same name.
+func (s *CategoryService) checkCategoryPlacement(ctx context.Context, c *domain.ProductCategory) error {
+	if c.ParentID != nil {
+		parent, err := s.repo.GetCategory(ctx, c.TenantID, *c.ParentID)
+		if err != nil {
+			return err
+		}
+		if parent == nil {
+			return fmt.Errorf("parent category %s not found: %w", *c.ParentID, ErrInvalidInput)
+		}
+	}
+	var exclude *uuid.UUID
+	if c.ID != uuid.Nil {
+		exclude = &c.ID
+	}
+	taken, err := s.repo.CategoryNameTaken(ctx, c.TenantID, c.ParentID, c.Name, exclude)
+	if err != nil {
+		return err
+	}
+	if taken {
+		return fmt.Errorf("category %s already exists: %w", c.Name, ErrInvalidState)
+	}
+	return nil
+}
+
+// CreateBrand adds a brand.
+func (s *CategoryService) CreateBrand(ctx context.Context, b *domain.Brand) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	b.Name = strings.TrimSpace(b.Name)
+	if b.Name == "" {
+		return fmt.Errorf("brand name is required: %w", ErrInvalidInput)
+	}
+	b.ID = uuid.New()
+	return WithTransaction(ctx, s.db, func(tx pgx.Tx) error {
+		created, err := s.repo.CreateBrand(ctx, tx, b)
+		if err != nil {
+			return err
+		}
+		if !created {
+			return fmt.Errorf("brand %s already exists: %w", b.Name, ErrInvalidState)
+		}
+		return recordAudit(ctx, s.repo, tx, domain.AuditLog{
+			TenantID: b.TenantID, EntityType: domain.AuditEntityBrand, EntityID: b.ID, Action: domain.AuditActionCreate,
+		}, nil, b)
+	})
+}
+
+func (s *CategoryService) ListBrands(ctx context.Context, tenantID uuid.UUID) ([]domain.Brand, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	return s.repo.ListBrands(ctx, tenantID)
+}
+
+// CreateAttribute defines an attribute on a category. Codes must be unique along the
+// category's ancestors and subcategories.
+func (s *CategoryService) CreateAttribute(ctx context.Context, a *domain.CategoryAttribute) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	a.Code = strings.ToLower(strings.TrimSpace(a.Code))
+	a.Name = strings.TrimSpace(a.Name)
+	if !attributeCodePattern.MatchString(a.Code) || a.Name == "" {
+		return fmt.Errorf("attribute needs a name and a code of lowercase letters, digits and underscores: %w", ErrInvalidInput)
+	}
+	switch a.DataType {
+	case domain.AttributeTypeText, domain.AttributeTypeNumber, domain.AttributeTypeBoolean:
+		if len(a.Options) > 0 {
+			return fmt.Errorf("options are only allowed on OPTION attributes: %w", ErrInvalidInput)
+		}
+		a.Options = []string{}
+	case domain.AttributeTypeOption:
+		options := make([]string, 0, len(a.Options))
+		for _, o := range a.Options {
+			if o = strings.TrimSpace(o); o != "" && !slices.Contains(options, o) {
+				options = append(options, o)
+			}
+		}
+		if len(options) == 0 {
+			return fmt.Errorf("OPTION attributes need at least one option: %w", ErrInvalidInput)
+		}
+		a.Options = options
+	default:
+		return fmt.Errorf("data_type must be TEXT, NUMBER, BOOLEAN or OPTION: %w", ErrInvalidInput)
+	}
+
+	category, err := s.repo.GetCategory(ctx, a.TenantID, a.CategoryID)
+	if err != nil {
+		return err
+	}
+	if category == nil {
+		return fmt.Errorf("category %s: %w", a.CategoryID, ErrNotFound)
+	}
+	taken, err := s.repo.AttributeCodeTaken(ctx, a.TenantID, a.CategoryID, a.Code)
+	if err != nil {
+		return err
+	}
+	if taken {
+		return fmt.Errorf("attribute %s is already defined in this branch of the category tree: %w", a.Code, ErrInvalidState)
+	}
+
+	a.ID = uuid.New()
+	return WithTransaction(ctx, s.db, func(tx pgx.Tx) error {
+		if err := s.repo.CreateAttribute(ctx, tx, a); err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.repo, tx, domain.AuditLog{
+			TenantID: a.TenantID, EntityType: domain.AuditEntityAttribute, EntityID: a.ID, Action: domain.AuditActionCreate,
+		}, nil, a)
+	})
+}
+
+// ListCategoryAttributes returns the attributes products of the category can have,
+// inherited ones first.
+func (s *CategoryService) ListCategoryAttributes(ctx context.Context, tenantID, categoryID uuid.UUID) ([]domain.CategoryAttribute, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	category, err := s.repo.GetCategory(ctx, tenantID, categoryID)
+	if err != nil {
+		return nil, err
+	}
+	if category == nil {
+		return nil, fmt.Errorf("category %s: %w", categoryID, ErrNotFound)
+	}
+	return s.repo.ListCategoryAttributes(ctx, tenantID, categoryID)
+}
+
+// SetProductClassification sets a product's category and brand; nil clears them. Attribute
+// values that do not apply to the new category are removed. Past invoice lines keep the
+// category and brand they were sold under.
+func (s *CategoryService) SetProductClassification(ctx context.Context, tenantID, productID uuid.UUID, categoryID, brandID *uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	if categoryID != nil {
+		category, err := s.repo.GetCategory(ctx, tenantID, *categoryID)
+		if err != nil {
+			return err
+		}
+		if category == nil {
+			return fmt.Errorf("category %s not found: %w", *categoryID, ErrInvalidInput)
+		}
+	}
+	if brandID != nil {
+		brand, err := s.repo.GetBrand(ctx, tenantID, *brandID)
+		if err != nil {
+			return err
+		}
+		if brand == nil {
+			return fmt.Errorf("brand %s not found: %w", *brandID, ErrInvalidInput)
+		}
+	}
+
+	return WithTransaction(ctx, s.db, func(tx pgx.Tx) error {
+		currentCategory, currentBrand, found, err := s.repo.LockProductClassification(ctx, tx, tenantID, productID)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return fmt.Errorf("product %s: %w", productID, ErrNotFound)
+		}
+		if err := s.repo.SetProductClassification(ctx, tx, tenantID, productID, categoryID, brandID); err != nil {
+			return err
+		}
+		if !sameUUID(currentCategory, categoryID) {
+			if err := s.repo.PruneAttributeValues(ctx, tx, tenantID); err != nil {
+				return err
+			}
+		}
+		return recordAudit(ctx, s.repo, tx, domain.AuditLog{
+			TenantID: tenantID, EntityType: domain.AuditEntityProduct, EntityID: productID, Action: domain.AuditActionUpdate,
+		}, map[string]*uuid.UUID{"category_id": currentCategory, "brand_id": currentBrand},
+			map[string]*uuid.UUID{"category_id": categoryID, "brand_id": brandID})
+	})
+}
+
+// SetProductAttributes replaces a product's attribute values, keyed by attribute code.
+// Values are checked against the attribute type and stored normalized.
+func (s *CategoryService) SetProductAttributes(ctx context.Context, tenantID, productID uuid.UUID, values map[string]string) (map[string]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	result := make(map[string]string, len(values))
+	err := WithTransaction(ctx, s.db, func(tx pgx.Tx) error {
+		categoryID, _, found, err := s.repo.LockProductClassification(ctx, tx, tenantID, productID)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return fmt.Errorf("product %s: %w", productID, ErrNotFound)
+		}
+		var attributes []domain.CategoryAttribute
+		if categoryID != nil {
+			if attributes, err = s.repo.ListApplicableAttributes(ctx, tx, tenantID, *categoryID); err != nil {
+				return err
+			}
+		}
+
+		byID := make(map[uuid.UUID]string, len(values))
+		for code, raw := range values {
+			i := slices.IndexFunc(attributes, func(a domain.CategoryAttribute) bool { return a.Code == code })
+			if i < 0 {
+				return fmt.Errorf("attribute %s does not apply to the product's category: %w", code, ErrInvalidInput)
+			}
+			value, err := normalizeAttributeValue(attributes[i], raw)
+			if err != nil {
+				return err
+			}
+			byID[attributes[i].ID] = value
+			result[code] = value
+		}
+		current, err := s.repo.GetProductAttributeValues(ctx, tx, tenantID, productID)
+		if err != nil {
+			return err
+		}
+		if err := s.repo.ReplaceProductAttributes(ctx, tx, tenantID, productID, byID); err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.repo, tx, domain.AuditLog{
+			TenantID: tenantID, EntityType: domain.AuditEntityProduct, EntityID: productID, Action: domain.AuditActionUpdate,
+		}, map[string]any{"attributes": current}, map[string]any{"attributes": result})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// normalizeAttributeValue checks a value against the attribute type and returns the form it
+// is stored and filtered in.
+func normalizeAttributeValue(a domain.CategoryAttribute, raw string) (string, error) {
+	value := strings.TrimSpace(raw)
+	if value == "" {
+		return "", fmt.Errorf("attribute %s needs a value: %w", a.Code, ErrInvalidInput)
+	}
+	switch a.DataType {
+	case domain.AttributeTypeNumber:
+		d, err := decimal.NewFromString(value)
+		if err != nil {
+			return "", fmt.Errorf("attribute %s must be a number: %w", a.Code, ErrInvalidInput)
+		}
+		return d.String(), nil
+	case domain.AttributeTypeBoolean:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return "", fmt.Errorf("attribute %s must be true or false: %w", a.Code, ErrInvalidInput)
+		}
+		return strconv.FormatBool(b), nil
+	case domain.AttributeTypeOption:
+		if !slices.Contains(a.Options, value) {
+			return "", fmt.Errorf("attribute %s must be one of %s: %w", a.Code, strings.Join(a.Options, ", "), ErrInvalidInput)
+		}
+	}
+	return value, nil
+}
+
+func sameUUID(a, b *uuid.UUID) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
@@ -0,0 +1,162 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"sancaksoft/internal/domain"
+	"sancaksoft/internal/repository"
+	"sancaksoft/internal/service"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCategoriesAttributesAndGrouping_Integration(t *testing.T) {
+	if testDBURL == "" {
+		t.Skip("Skipping integration test: TEST_DB_URL not set")
+	}
+
+	ctx := context.Background()
+	db, err := pgxpool.New(ctx, testDBURL)
+	require.NoError(t, err)
+	defer db.Close()
+
+	// 1. Setup Data
+	tenantID := uuid.New()
+	warehouseID := uuid.New()
+	customerID := uuid.New()
+	userID := uuid.New()
+
+	defer func() {
+		_, _ = db.Exec(ctx, "DELETE FROM tenants WHERE id = $1", tenantID)
+	}()
+
+	_, err = db.Exec(ctx, "INSERT INTO tenants (id, name) VALUES ($1, 'Category Test Tenant')", tenantID)
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, "INSERT INTO warehouses (id, tenant_id, name) VALUES ($1, $2, 'Main')", warehouseID, tenantID)
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, "INSERT INTO customers (id, tenant_id, name) VALUES ($1, $2, 'Retailer')", customerID, tenantID)
+	require.NoError(t, err)
+
+	categoryService := service.NewCategoryService(db, repository.NewCategoryRepository(db))
+	productService := service.NewProductService(db, repository.NewProductRepository(db))
+	invoiceService := service.NewInvoiceService(db, repository.NewInvoiceRepository())
+	reportService := service.NewReportService(repository.NewReportRepository(db))
+	costingService := service.NewCostingService(db, repository.NewCostingRepository(db))
+
+	// 2. Electronics > Phones, Electronics > Laptops
+	electronics := &domain.ProductCategory{TenantID: tenantID, Name: "Electronics"}
+	require.NoError(t, categoryService.CreateCategory(ctx, electronics))
+	phones := &domain.ProductCategory{TenantID: tenantID, Name: "Phones", ParentID: &electronics.ID}
+	require.NoError(t, categoryService.CreateCategory(ctx, phones))
+	laptops := &domain.ProductCategory{TenantID: tenantID, Name: "Laptops", ParentID: &electronics.ID}
+	require.NoError(t, categoryService.CreateCategory(ctx, laptops))
+	assert.Equal(t, "Electronics / Phones", phones.Path)
+	assert.Equal(t, 1, phones.Depth)
+
+	err = categoryService.UpdateCategory(ctx, &domain.ProductCategory{ID: electronics.ID, TenantID: tenantID, Name: "Electronics", ParentID: &phones.ID})
+	assert.ErrorIs(t, err, service.ErrInvalidInput)
+
+	// 3. color is inherited by every electronics product; storage_gb only by phones
+	color := &domain.CategoryAttribute{TenantID: tenantID, CategoryID: electronics.ID, Code: "color", Name: "Color", DataType: domain.AttributeTypeOption, Options: []string{"Black", "White"}}
+	require.NoError(t, categoryService.CreateAttribute(ctx, color))
+	storage := &domain.CategoryAttribute{TenantID: tenantID, CategoryID: phones.ID, Code: "storage_gb", Name: "Storage (GB)", DataType: domain.AttributeTypeNumber}
+	require.NoError(t, categoryService.CreateAttribute(ctx, storage))
+	err = categoryService.CreateAttribute(ctx, &domain.CategoryAttribute{TenantID: tenantID, CategoryID: laptops.ID, Code: "color", Name: "Colour", DataType: domain.AttributeTypeText})
+	assert.ErrorIs(t, err, service.ErrInvalidState)
+
+	attributes, err := categoryService.ListCategoryAttributes(ctx, tenantID, phones.ID)
+	require.NoError(t, err)
+	require.Len(t, attributes, 2)
+	assert.Equal(t, "color", attributes[0].Code)
+
+	// 4. Products with category and brand
+	acme := &domain.Brand{TenantID: tenantID, Name: "Acme"}
+	require.NoError(t, categoryService.CreateBrand(ctx, acme))
+	assert.ErrorIs(t, categoryService.CreateBrand(ctx, &domain.Brand{TenantID: tenantID, Name: "Acme"}), service.ErrInvalidState)
+
+	phone := &domain.Product{TenantID: tenantID, Name: "Phone", SKU: "SKU-" + uuid.New().String(), Price: decimal.NewFromInt(100), VATRate: decimal.NewFromInt(20), CategoryID: &phones.ID, BrandID: &acme.ID}
+	require.NoError(t, productService.CreateProduct(ctx, phone))
+	laptop := &domain.Product{TenantID: tenantID, Name: "Laptop", SKU: "SKU-" + uuid.New().String(), Price: decimal.NewFromInt(500), VATRate: decimal.NewFromInt(20), CategoryID: &laptops.ID}
+	require.NoError(t, productService.CreateProduct(ctx, laptop))
+	missing := uuid.New()
+	err = productService.CreateProduct(ctx, &domain.Product{TenantID: tenantID, Name: "Ghost", SKU: "SKU-" + uuid.New().String(), CategoryID: &missing})
+	assert.ErrorIs(t, err, service.ErrInvalidInput)
+
+	// 5. Values are type checked and stored normalized
+	values, err := categoryService.SetProductAttributes(ctx, tenantID, phone.ID, map[string]string{"color": "Black", "storage_gb": "128.0"})
+	require.NoError(t, err)
+	assert.Equal(t, "128", values["storage_gb"])
+	_, err = categoryService.SetProductAttributes(ctx, tenantID, phone.ID, map[string]string{"storage_gb": "lots"})
+	assert.ErrorIs(t, err, service.ErrInvalidInput)
+	_, err = categoryService.SetProductAttributes(ctx, tenantID, laptop.ID, map[string]string{"storage_gb": "512"})
+	assert.ErrorIs(t, err, service.ErrInvalidInput)
+	_, err = categoryService.SetProductAttributes(ctx, tenantID, laptop.ID, map[string]string{"color": "White"})
+	require.NoError(t, err)
+
+	// 6. Filters: category includes subcategories, attributes are case-insensitive
+	products, err := productService.ListProducts(ctx, domain.ProductFilter{TenantID: tenantID, CategoryID: &electronics.ID})
+	require.NoError(t, err)
+	assert.Len(t, products, 2)
+	products, err = productService.ListProducts(ctx, domain.ProductFilter{TenantID: tenantID, Attributes: map[string]string{"color": "black", "storage_gb": "128.00"}})
+	require.NoError(t, err)
+	require.Len(t, products, 1)
+	assert.Equal(t, phone.ID, products[0].ID)
+	assert.Equal(t, "Black", products[0].Attributes["color"])
+	products, err = productService.ListProducts(ctx, domain.ProductFilter{TenantID: tenantID, BrandID: &acme.ID})
+	require.NoError(t, err)
+	assert.Len(t, products, 1)
+
+	// 7. Sell the phone, then move it to laptops
+	_, err = db.Exec(ctx, `INSERT INTO stock_movements (tenant_id, product_id, warehouse_id, quantity, type, unit_cost) VALUES
+		($1, $2, $4, 10, 'IN', 60), ($1, $3, $4, 2, 'IN', 300)`, tenantID, phone.ID, laptop.ID, warehouseID)
+	require.NoError(t, err)
+	_, err = invoiceService.CreateInvoice(ctx, domain.CreateInvoiceRequest{
+		TenantID:    tenantID,
+		UserID:      userID,
+		CustomerID:  customerID,
+		WarehouseID: warehouseID,
+		Items:       []domain.InvoiceItemRequest{{ProductID: phone.ID, Quantity: 2, UnitPrice: decimal.NewFromInt(120)}},
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, categoryService.SetProductClassification(ctx, tenantID, phone.ID, &laptops.ID, &acme.ID))
+	moved, err := productService.GetProduct(ctx, tenantID, phone.ID)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"color": "Black"}, moved.Attributes)
+
+	// 8. Past sales stay under the category they were sold in; a rename only changes the label
+	require.NoError(t, categoryService.UpdateCategory(ctx, &domain.ProductCategory{ID: phones.ID, TenantID: tenantID, Name: "Mobile", ParentID: &electronics.ID}))
+	report, err := reportService.GetGrossProfit(ctx, domain.GrossProfitReportRequest{TenantID: tenantID, GroupBy: domain.ProfitByCategory})
+	require.NoError(t, err)
+	require.Len(t, report.Lines, 1)
+	assert.Equal(t, phones.ID.String(), report.Lines[0].Key)
+	assert.Equal(t, "Mobile", report.Lines[0].Label)
+	assert.True(t, report.Lines[0].GrossProfit.Equal(decimal.NewFromInt(80)))
+	report, err = reportService.GetGrossProfit(ctx, domain.GrossProfitReportRequest{TenantID: tenantID, GroupBy: domain.ProfitByBrand})
+	require.NoError(t, err)
+	require.Len(t, report.Lines, 1)
+	assert.Equal(t, "Acme", report.Lines[0].Label)
+
+	assert.ErrorIs(t, categoryService.DeleteCategory(ctx, tenantID, phones.ID), service.ErrInvalidState)
+
+	// 9. Stock valuation groups by the current classification
+	lines, err := costingService.GetStockValuation(ctx, tenantID, nil)
+	require.NoError(t, err)
+	groups, err := costingService.GroupStockValuation(lines, domain.StockByCategory)
+	require.NoError(t, err)
+	require.Len(t, groups, 1)
+	assert.Equal(t, laptops.ID.String(), groups[0].Key)
+	assert.Equal(t, 10, groups[0].Quantity)
+	assert.True(t, groups[0].Value.Equal(decimal.NewFromInt(1080)))
+	groups, err = costingService.GroupStockValuation(lines, domain.StockByBrand)
+	require.NoError(t, err)
+	require.Len(t, groups, 2)
+	assert.Equal(t, "No brand", groups[0].Label)
+	_, err = costingService.GroupStockValuation(lines, "color")
+	assert.ErrorIs(t, err, service.ErrInvalidInput)
+}
@@ -46,10 +46,10 @@ func TestComprehensiveERPFlow(t *testing.T) {
 	dashboardRepo := repository.NewDashboardRepository(db)
 
 	invoiceService := service.NewInvoiceService(db, invoiceRepo)
-	productService := service.NewProductService(productRepo)
-	customerService := service.NewCustomerService(customerRepo)
-	warehouseService := service.NewWarehouseService(warehouseRepo)
-	stockService := service.NewStockService(stockRepo)
+	productService := service.NewProductService(db, productRepo)
+	customerService := service.NewCustomerService(db, customerRepo)
+	warehouseService := service.NewWarehouseService(db, warehouseRepo)
+	stockService := service.NewStockService(db, stockRepo)
 	dashboardService := service.NewDashboardService(dashboardRepo)
 
 	// 3. Create Warehouse
@@ -141,7 +141,7 @@ func TestComprehensiveERPFlow(t *testing.T) {
 	assert.Equal(t, 40, getStock(prodB.ID)) // 50 - 10
 
 	// 10. Verify Stock Movement API (Service level)
-	movements, err := stockService.ListStockMovements(ctx, tenantID)
+	movements, err := stockService.ListStockMovements(ctx, domain.StockMovementFilter{TenantID: tenantID})
 	require.NoError(t, err)
 	// Should be 2 initial IN + 2 invoice OUT = 4
 	assert.GreaterOrEqual(t, len(movements), 4)
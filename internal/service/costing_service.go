@@ -0,0 +1,384 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"sancaksoft/internal/domain"
+	"sancaksoft/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shopspring/decimal"
+)
+
+// Movements are costed lazily: writers only record the entered unit_cost of inbound stock and
+// leave assigned_cost NULL. Before any cost figure is read, every product with an uncosted
+// movement is replayed from its first movement, so a back-dated purchase re-costs the sales after it.
+type CostingService struct {
+	db   *pgxpool.Pool
+	repo *repository.CostingRepository
+}
+
+func NewCostingService(db *pgxpool.Pool, repo *repository.CostingRepository) *CostingService {
+	return &CostingService{db: db, repo: repo}
+}
+
+func (s *CostingService) GetCostingMethod(ctx context.Context, tenantID uuid.UUID) (domain.CostingMethod, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	method, err := s.repo.GetCostingMethod(ctx, s.db, tenantID)
+	if err != nil {
+		return "", err
+	}
+	if method == "" {
+		return "", fmt.Errorf("tenant %s: %w", tenantID, ErrNotFound)
+	}
+	return method, nil
+}
+
+// SetCostingMethod switches the tenant between moving average and FIFO. All movements are
+// re-costed under the new method on the next read.
+func (s *CostingService) SetCostingMethod(ctx context.Context, tenantID uuid.UUID, method domain.CostingMethod) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	if method != domain.CostingMethodAverage && method != domain.CostingMethodFIFO {
+		return fmt.Errorf("costing method must be AVERAGE or FIFO: %w", ErrInvalidInput)
+	}
+	return WithTransaction(ctx, s.db, func(tx pgx.Tx) error {
+		current, err := s.repo.GetCostingMethod(ctx, tx, tenantID)
+		if err != nil {
+			return err
+		}
+		if current == "" {
+			return fmt.Errorf("tenant %s: %w", tenantID, ErrNotFound)
+		}
+		if current == method {
+			return nil
+		}
+		if err := s.repo.SetCostingMethod(ctx, tx, tenantID, method); err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.repo, tx, domain.AuditLog{
+			TenantID: tenantID, EntityType: domain.AuditEntityTenant, EntityID: tenantID, Action: domain.AuditActionUpdate,
+		}, map[string]any{"costing_method": current}, map[string]any{"costing_method": method})
+	})
+}
+
+// Recalculate re-costs one product, or every stocked product when productID is nil,
+// and returns how many products were processed.
+func (s *CostingService) Recalculate(ctx context.Context, tenantID uuid.UUID, productID *uuid.UUID) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, 60*time.Second)
+	defer cancel()
+
+	productIDs := []uuid.UUID{}
+	if productID != nil {
+		productIDs = append(productIDs, *productID)
+	} else {
+		ids, err := s.repo.ListStockedProducts(ctx, tenantID)
+		if err != nil {
+			return 0, err
+		}
+		productIDs = ids
+	}
+	entry := domain.AuditLog{
+		TenantID: tenantID, EntityType: domain.AuditEntityTenant, EntityID: tenantID, Action: domain.AuditActionRecalculate,
+		Details: map[string]any{"products": len(productIDs)},
+	}
+	if productID != nil {
+		entry.EntityType, entry.EntityID, entry.Details = domain.AuditEntityProduct, *productID, nil
+	}
+	audit := func(tx pgx.Tx) error {
+		return recordAudit(ctx, s.repo, tx, entry, nil, nil)
+	}
+	if len(productIDs) == 0 {
+		// Nothing was re-costed, so there is nothing for the entry to commit with
+		if err := audit(nil); err != nil {
+			return 0, err
+		}
+		return 0, nil
+	}
+	if err := s.recalculateProducts(ctx, tenantID, productIDs, audit); err != nil {
+		return 0, err
+	}
+	return len(productIDs), nil
+}
+
+// GetStockValuation values on-hand stock at current cost, optionally in one warehouse.
+func (s *CostingService) GetStockValuation(ctx context.Context, tenantID uuid.UUID, warehouseID *uuid.UUID) ([]domain.StockValuationLine, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	if err := s.ensureCosted(ctx, tenantID); err != nil {
+		return nil, err
+	}
+	lines, err := s.repo.GetStockValuation(ctx, tenantID, warehouseID)
+	if err != nil {
+		return nil, err
+	}
+	if lines == nil {
+		lines = []domain.StockValuationLine{}
+	}
+	return lines, nil
+}
+
+// GroupStockValuation sums valuation lines by the products' current category or brand,
+// largest value first; products without one form a group with an empty key.
+func (s *CostingService) GroupStockValuation(lines []domain.StockValuationLine, groupBy domain.StockGroupBy) ([]domain.StockValuationGroup, error) {
+	if groupBy != domain.StockByCategory && groupBy != domain.StockByBrand {
+		return nil, fmt.Errorf("group_by must be category or brand: %w", ErrInvalidInput)
+	}
+	groups := []domain.StockValuationGroup{}
+	index := make(map[string]int)
+	for _, line := range lines {
+		id, name, fallback := line.CategoryID, line.CategoryName, "Uncategorized"
+		if groupBy == domain.StockByBrand {
+			id, name, fallback = line.BrandID, line.BrandName, "No brand"
+		}
+		key, label := "", fallback
+		if id != nil {
+			key, label = id.String(), *name
+		}
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, domain.StockValuationGroup{Key: key, Label: label, Value: decimal.Zero})
+		}
+		groups[i].Quantity += line.Quantity
+		groups[i].Value = groups[i].Value.Add(line.Value)
+	}
+	sort.SliceStable(groups, func(i, j int) bool { return groups[i].Value.GreaterThan(groups[j].Value) })
+	return groups, nil
+}
+
+// GetCostOfGoods returns cost of goods sold per product in [from, to).
+func (s *CostingService) GetCostOfGoods(ctx context.Context, tenantID uuid.UUID, from, to *time.Time) ([]domain.CostOfGoodsLine, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	if err := s.ensureCosted(ctx, tenantID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetCostOfGoods(ctx, tenantID, from, to)
+}
+
+// GetInvoiceProfit returns revenue, cost and gross profit per invoice line.
+func (s *CostingService) GetInvoiceProfit(ctx context.Context, tenantID, invoiceID uuid.UUID) (*domain.InvoiceProfit, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	if err := s.ensureCosted(ctx, tenantID); err != nil {
+		return nil, err
+	}
+	profit, err := s.repo.GetInvoiceProfit(ctx, tenantID, invoiceID)
+	if err != nil {
+		return nil, err
+	}
+	if profit == nil {
+		return nil, fmt.Errorf("invoice %s: %w", invoiceID, ErrNotFound)
+	}
+
+	for i := range profit.Lines {
+		line := &profit.Lines[i]
+		line.UnitCost = line.UnitCost.Round(4)
+		line.Cost = line.UnitCost.Mul(decimal.NewFromInt(int64(line.Quantity))).Round(2)
+		line.GrossProfit = line.Revenue.Sub(line.Cost)
+		line.MarginPct = marginPct(line.GrossProfit, line.Revenue)
+		profit.Revenue = profit.Revenue.Add(line.Revenue)
+		profit.Cost = profit.Cost.Add(line.Cost)
+	}
+	profit.GrossProfit = profit.Revenue.Sub(profit.Cost)
+	profit.MarginPct = marginPct(profit.GrossProfit, profit.Revenue)
+	return profit, nil
+}
+
+// marginPct is gross profit as a percentage of revenue, rounded to two decimals.
+func marginPct(grossProfit, revenue decimal.Decimal) decimal.Decimal {
+	if revenue.IsZero() {
+		return decimal.Zero
+	}
+	return grossProfit.Div(revenue).Mul(decimal.NewFromInt(100)).Round(2)
+}
+
+// ensureCosted replays every product that has uncosted movements.
+func (s *CostingService) ensureCosted(ctx context.Context, tenantID uuid.UUID) error {
+	productIDs, err := s.repo.ListUncostedProducts(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	return s.recalculateProducts(ctx, tenantID, productIDs, nil)
+}
+
+// recalculateProducts re-costs the products one transaction each. done, if given, runs in
+// the transaction of the last product, so what it writes commits with the last costs;
+// re-costing is idempotent, so a run that fails part way can simply be repeated.
+func (s *CostingService) recalculateProducts(ctx context.Context, tenantID uuid.UUID, productIDs []uuid.UUID, done func(pgx.Tx) error) error {
+	if len(productIDs) == 0 {
+		return nil
+	}
+	method, err := s.repo.GetCostingMethod(ctx, s.db, tenantID)
+	if err != nil {
+		return err
+	}
+	if method == "" {
+		return fmt.Errorf("tenant %s: %w", tenantID, ErrNotFound)
+	}
+
+	// One transaction per product keeps lock time short on large tenants
+	for i, productID := range productIDs {
+		err := WithTransaction(ctx, s.db, func(tx pgx.Tx) error {
+			if err := s.repo.LockProduct(ctx, tx, tenantID, productID); err != nil {
+				return err
+			}
+			movements, err := s.repo.ListProductMovements(ctx, tx, tenantID, productID)
+			if err != nil {
+				return err
+			}
+
+			pc := costMovements(method, movements)
+			pc.TenantID = tenantID
+			pc.ProductID = productID
+
+			if err := s.repo.SetAssignedCosts(ctx, tx, movements); err != nil {
+				return err
+			}
+			if err := s.repo.UpsertProductCost(ctx, tx, &pc); err != nil {
+				return err
+			}
+			if done != nil && i == len(productIDs)-1 {
+				return done(tx)
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to recalculate cost of product %s: %w", productID, err)
+		}
+	}
+	return nil
+}
+
+// costLayer is a FIFO lot of units received at one cost.
+type costLayer struct {
+	qty  int
+	cost decimal.Decimal
+}
+
+// costMovements replays a product's movements in order, setting AssignedCost on each one,
+// and returns the resulting on-hand quantity and value.
+//
+// Inbound movements are valued at their entered unit cost; inbound movements without one
+// (customer returns, cancelled shipments) come back at the running cost. Outbound movements
+// take the moving average, or consume the oldest FIFO layers. Stock that goes negative is
+// costed at the last known cost and the shortfall is covered by the next receipt.
+func costMovements(method domain.CostingMethod, movements []domain.StockMovement) domain.ProductCost {
+	var (
+		onHand   int
+		value    decimal.Decimal // AVERAGE: value of on-hand units
+		avg      decimal.Decimal
+		layers   []costLayer // FIFO
+		short    int         // FIFO: units shipped without a layer to consume
+		lastCost decimal.Decimal
+	)
+
+	layerCost := func() decimal.Decimal {
+		var qty int
+		var total decimal.Decimal
+		for _, l := range layers {
+			qty += l.qty
+			total = total.Add(l.cost.Mul(decimal.NewFromInt(int64(l.qty))))
+		}
+		if qty == 0 {
+			return lastCost
+		}
+		return total.Div(decimal.NewFromInt(int64(qty)))
+	}
+
+	for i := range movements {
+		m := &movements[i]
+		var unitCost decimal.Decimal
+
+		if m.Quantity > 0 {
+			switch {
+			case m.UnitCost != nil:
+				unitCost = *m.UnitCost
+			case method == domain.CostingMethodFIFO:
+				unitCost = layerCost()
+			default:
+				unitCost = avg
+			}
+
+			if method == domain.CostingMethodFIFO {
+				qty := m.Quantity
+				covered := min(short, qty)
+				short -= covered
+				qty -= covered
+				if qty > 0 {
+					layers = append(layers, costLayer{qty: qty, cost: unitCost})
+				}
+			} else {
+				if onHand <= 0 {
+					// Nothing (or a deficit) on hand: the receipt sets the new average
+					value = unitCost.Mul(decimal.NewFromInt(int64(onHand + m.Quantity)))
+				} else {
+					value = value.Add(unitCost.Mul(decimal.NewFromInt(int64(m.Quantity))))
+				}
+				if onHand+m.Quantity > 0 {
+					avg = value.Div(decimal.NewFromInt(int64(onHand + m.Quantity)))
+				} else {
+					avg = unitCost
+				}
+			}
+			onHand += m.Quantity
+			lastCost = unitCost
+		} else {
+			out := -m.Quantity
+			if method == domain.CostingMethodFIFO {
+				need := out
+				var total decimal.Decimal
+				for need > 0 && len(layers) > 0 {
+					take := min(need, layers[0].qty)
+					total = total.Add(layers[0].cost.Mul(decimal.NewFromInt(int64(take))))
+					lastCost = layers[0].cost
+					layers[0].qty -= take
+					need -= take
+					if layers[0].qty == 0 {
+						layers = layers[1:]
+					}
+				}
+				if need > 0 {
+					total = total.Add(lastCost.Mul(decimal.NewFromInt(int64(need))))
+					short += need
+				}
+				unitCost = total.Div(decimal.NewFromInt(int64(out)))
+			} else {
+				unitCost = avg
+				value = value.Sub(avg.Mul(decimal.NewFromInt(int64(out))))
+			}
+			onHand -= out
+		}
+
+		assigned := unitCost.Round(4)
+		m.AssignedCost = &assigned
+	}
+
+	pc := domain.ProductCost{Method: method, OnHand: onHand}
+	if method == domain.CostingMethodFIFO {
+		pc.UnitCost = layerCost()
+		for _, l := range layers {
+			pc.StockValue = pc.StockValue.Add(l.cost.Mul(decimal.NewFromInt(int64(l.qty))))
+		}
+		pc.StockValue = pc.StockValue.Sub(lastCost.Mul(decimal.NewFromInt(int64(short))))
+	} else {
+		pc.UnitCost = avg
+		pc.StockValue = value
+	}
+	pc.UnitCost = pc.UnitCost.Round(4)
+	pc.StockValue = pc.StockValue.Round(2)
+	return pc
+}
@@ -0,0 +1,113 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"sancaksoft/internal/domain"
+	"sancaksoft/internal/repository"
+	"sancaksoft/internal/service"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInventoryCosting_Integration(t *testing.T) {
+	if testDBURL == "" {
+		t.Skip("Skipping integration test: TEST_DB_URL not set")
+	}
+
+	ctx := context.Background()
+	db, err := pgxpool.New(ctx, testDBURL)
+	require.NoError(t, err)
+	defer db.Close()
+
+	// 1. Setup Data
+	tenantID := uuid.New()
+	warehouseID := uuid.New()
+	productID := uuid.New()
+	customerID := uuid.New()
+	userID := uuid.New()
+
+	defer func() {
+		_, _ = db.Exec(ctx, "DELETE FROM tenants WHERE id = $1", tenantID)
+	}()
+
+	_, err = db.Exec(ctx, "INSERT INTO tenants (id, name) VALUES ($1, 'Costing Test Tenant')", tenantID)
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, "INSERT INTO warehouses (id, tenant_id, name) VALUES ($1, $2, 'Main')", warehouseID, tenantID)
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, "INSERT INTO products (id, tenant_id, name, sku, price, vat_rate) VALUES ($1, $2, 'Valve', $3, 36.00, 20)", productID, tenantID, "SKU-"+uuid.New().String())
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, "INSERT INTO customers (id, tenant_id, name) VALUES ($1, $2, 'Costing Customer')", customerID, tenantID)
+	require.NoError(t, err)
+
+	stockService := service.NewStockService(db, repository.NewStockRepository(db))
+	invoiceService := service.NewInvoiceService(db, repository.NewInvoiceRepository())
+	costingService := service.NewCostingService(db, repository.NewCostingRepository(db))
+
+	receive := func(qty int, cost int64, daysAgo int) {
+		unitCost := decimal.NewFromInt(cost)
+		require.NoError(t, stockService.CreateStockMovement(ctx, tenantID, &domain.StockMovement{
+			ID:          uuid.New(),
+			ProductID:   productID,
+			WarehouseID: warehouseID,
+			Quantity:    qty,
+			Type:        domain.StockMovementTypeIn,
+			UnitCost:    &unitCost,
+			CreatedAt:   time.Now().AddDate(0, 0, -daysAgo),
+		}))
+	}
+
+	// 2. Receive 10 @ 10, sell 8 @ 36 incl. 20% VAT (net 30)
+	receive(10, 10, 5)
+	invoice, err := invoiceService.CreateInvoice(ctx, domain.CreateInvoiceRequest{
+		TenantID:    tenantID,
+		UserID:      userID,
+		CustomerID:  customerID,
+		WarehouseID: warehouseID,
+		Items:       []domain.InvoiceItemRequest{{ProductID: productID, Quantity: 8, UnitPrice: decimal.NewFromInt(36)}},
+	})
+	require.NoError(t, err)
+
+	profit, err := costingService.GetInvoiceProfit(ctx, tenantID, invoice.ID)
+	require.NoError(t, err)
+	require.Len(t, profit.Lines, 1)
+	assert.True(t, profit.Cost.Equal(decimal.NewFromInt(80)), "cost %s", profit.Cost)
+	assert.True(t, profit.GrossProfit.Equal(decimal.NewFromInt(160)), "gross profit %s", profit.GrossProfit)
+
+	// 3. A back-dated purchase before the sale re-costs it at the new average (10*10 + 10*16) / 20 = 13
+	receive(10, 16, 3)
+
+	profit, err = costingService.GetInvoiceProfit(ctx, tenantID, invoice.ID)
+	require.NoError(t, err)
+	assert.True(t, profit.Cost.Equal(decimal.NewFromInt(104)), "cost %s", profit.Cost)
+
+	valuation, err := costingService.GetStockValuation(ctx, tenantID, nil)
+	require.NoError(t, err)
+	require.Len(t, valuation, 1)
+	assert.Equal(t, 12, valuation[0].Quantity)
+	assert.True(t, valuation[0].Value.Equal(decimal.NewFromInt(156)), "value %s", valuation[0].Value)
+
+	// 4. FIFO: the sale consumes the oldest layer, leaving 2 @ 10 + 10 @ 16
+	require.NoError(t, costingService.SetCostingMethod(ctx, tenantID, domain.CostingMethodFIFO))
+
+	profit, err = costingService.GetInvoiceProfit(ctx, tenantID, invoice.ID)
+	require.NoError(t, err)
+	assert.True(t, profit.Cost.Equal(decimal.NewFromInt(80)), "cost %s", profit.Cost)
+
+	valuation, err = costingService.GetStockValuation(ctx, tenantID, nil)
+	require.NoError(t, err)
+	require.Len(t, valuation, 1)
+	assert.True(t, valuation[0].Value.Equal(decimal.NewFromInt(180)), "value %s", valuation[0].Value)
+
+	cogs, err := costingService.GetCostOfGoods(ctx, tenantID, nil, nil)
+	require.NoError(t, err)
+	require.Len(t, cogs, 1)
+	assert.Equal(t, 8, cogs[0].Quantity)
+	assert.True(t, cogs[0].Cost.Equal(decimal.NewFromInt(80)))
+}
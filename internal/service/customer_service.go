@@ -10,14 +10,18 @@ import (
 	"sancaksoft/internal/repository"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shopspring/decimal"
 )
 
 type CustomerService struct {
+	db   *pgxpool.Pool
 	repo *repository.CustomerRepository
 }
 
-func NewCustomerService(repo *repository.CustomerRepository) *CustomerService {
-	return &CustomerService{repo: repo}
+func NewCustomerService(db *pgxpool.Pool, repo *repository.CustomerRepository) *CustomerService {
+	return &CustomerService{db: db, repo: repo}
 }
 
 func (s *CustomerService) CreateCustomer(ctx context.Context, c *domain.Customer) error {
@@ -27,9 +31,22 @@ func (s *CustomerService) CreateCustomer(ctx context.Context, c *domain.Customer
 	if c.Name == "" {
 		return errors.New("customer name is required")
 	}
+	if err := validateCreditTerms(c.CreditLimit, c.PaymentTermDays); err != nil {
+		return err
+	}
+	if c.TaxNumber != "" && !validTaxNumber(c.TaxNumber) {
+		return fmt.Errorf("tax number must be a 10-digit VKN or an 11-digit TCKN: %w", ErrInvalidInput)
+	}
 
 	c.ID = uuid.New()
-	return s.repo.CreateCustomer(ctx, c)
+	return WithTransaction(ctx, s.db, func(tx pgx.Tx) error {
+		if err := s.repo.CreateCustomer(ctx, tx, c); err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.repo, tx, domain.AuditLog{
+			TenantID: c.TenantID, EntityType: domain.AuditEntityCustomer, EntityID: c.ID, Action: domain.AuditActionCreate,
+		}, nil, c)
+	})
 }
 
 func (s *CustomerService) GetCustomer(ctx context.Context, tenantID, customerID uuid.UUID) (*domain.Customer, error) {
@@ -58,3 +75,171 @@ func (s *CustomerService) ListCustomerLedger(ctx context.Context, tenantID, cust
 
 	return s.repo.ListCustomerLedger(ctx, tenantID, customerID, period)
 }
+
+// validTaxNumber reports whether s looks like a VKN (10 digits, companies) or a TCKN
+// (11 digits, individuals).
+func validTaxNumber(s string) bool {
+	if len(s) != 10 && len(s) != 11 {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func validateCreditTerms(limit *decimal.Decimal, termDays int) error {
+	if limit != nil && limit.IsNegative() {
+		return fmt.Errorf("credit limit cannot be negative: %w", ErrInvalidInput)
+	}
+	if termDays < 0 {
+		return fmt.Errorf("payment term cannot be negative: %w", ErrInvalidInput)
+	}
+	return nil
+}
+
+// SetCreditTerms sets a customer's credit limit (nil removes it) and payment term in days.
+// The term applies to invoices issued from now on; existing due dates are kept.
+func (s *CustomerService) SetCreditTerms(ctx context.Context, tenantID, customerID uuid.UUID, limit *decimal.Decimal, termDays int) (*domain.Customer, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	if err := validateCreditTerms(limit, termDays); err != nil {
+		return nil, err
+	}
+	var customer *domain.Customer
+	err := WithTransaction(ctx, s.db, func(tx pgx.Tx) error {
+		var err error
+		customer, err = s.repo.LockCustomer(ctx, tx, tenantID, customerID)
+		if err != nil {
+			return err
+		}
+		if customer == nil {
+			return fmt.Errorf("customer %s: %w", customerID, ErrNotFound)
+		}
+		before := *customer
+		customer.CreditLimit = limit
+		customer.PaymentTermDays = termDays
+		if err := s.repo.UpdateCreditTerms(ctx, tx, customer); err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.repo, tx, domain.AuditLog{
+			TenantID: tenantID, EntityType: domain.AuditEntityCustomer, EntityID: customerID, Action: domain.AuditActionUpdate,
+		}, &before, customer)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return customer, nil
+}
+
+// GetCredit returns a customer's credit position as of now.
+func (s *CustomerService) GetCredit(ctx context.Context, tenantID, customerID uuid.UUID) (*domain.CustomerCredit, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	credit, err := s.repo.GetCustomerCredit(ctx, tenantID, customerID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if credit == nil {
+		return nil, fmt.Errorf("customer %s: %w", customerID, ErrNotFound)
+	}
+	return credit, nil
+}
+
+// RecordPayment records a payment received from a customer. Payments are not tied to
+// invoices; they settle the customer's oldest due invoices first.
+func (s *CustomerService) RecordPayment(ctx context.Context, p *domain.CustomerPayment) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	if !p.Amount.IsPositive() {
+		return fmt.Errorf("payment amount must be greater than zero: %w", ErrInvalidInput)
+	}
+	switch p.Method {
+	case domain.PaymentMethodCash, domain.PaymentMethodBankTransfer, domain.PaymentMethodCard, domain.PaymentMethodCheque:
+	default:
+		return fmt.Errorf("invalid payment method %q: %w", p.Method, ErrInvalidInput)
+	}
+	if p.PaymentDate.IsZero() {
+		p.PaymentDate = time.Now()
+	}
+	p.ID = uuid.New()
+	return WithTransaction(ctx, s.db, func(tx pgx.Tx) error {
+		customer, err := s.repo.LockCustomer(ctx, tx, p.TenantID, p.CustomerID)
+		if err != nil {
+			return err
+		}
+		if customer == nil {
+			return fmt.Errorf("customer %s: %w", p.CustomerID, ErrNotFound)
+		}
+		if err := s.repo.CreateCustomerPayment(ctx, tx, p); err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.repo, tx, domain.AuditLog{
+			TenantID: p.TenantID, EntityType: domain.AuditEntityPayment, EntityID: p.ID, Action: domain.AuditActionCreate,
+		}, nil, p)
+	})
+}
+
+func (s *CustomerService) ListPayments(ctx context.Context, tenantID, customerID uuid.UUID) ([]domain.CustomerPayment, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	return s.repo.ListCustomerPayments(ctx, tenantID, customerID)
+}
+
+// GetStatement builds a customer's account statement: the balance brought forward from
+// before From, every invoice, return and payment in the period with a running balance,
+// and the closing balance.
+func (s *CustomerService) GetStatement(ctx context.Context, req domain.CustomerStatementRequest) (*domain.CustomerStatement, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	if req.From != nil && req.To != nil && !req.From.Before(*req.To) {
+		return nil, fmt.Errorf("from must be before to: %w", ErrInvalidInput)
+	}
+	customer, err := s.repo.GetCustomerByID(ctx, req.TenantID, req.CustomerID)
+	if err != nil {
+		return nil, err
+	}
+	if customer == nil {
+		return nil, fmt.Errorf("customer %s: %w", req.CustomerID, ErrNotFound)
+	}
+	company, err := s.repo.GetTenantProfile(ctx, req.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	if company == nil {
+		return nil, fmt.Errorf("tenant %s: %w", req.TenantID, ErrNotFound)
+	}
+
+	statement := &domain.CustomerStatement{
+		Company:  *company,
+		Customer: *customer,
+		From:     req.From,
+		To:       req.To,
+	}
+	if req.From != nil {
+		if statement.OpeningBalance, err = s.repo.GetBalanceBefore(ctx, req.TenantID, req.CustomerID, *req.From); err != nil {
+			return nil, err
+		}
+	}
+	if statement.Lines, err = s.repo.ListStatementLines(ctx, req.TenantID, req.CustomerID, req.From, req.To); err != nil {
+		return nil, err
+	}
+
+	balance := statement.OpeningBalance
+	for i := range statement.Lines {
+		line := &statement.Lines[i]
+		balance = balance.Add(line.Debit).Sub(line.Credit)
+		line.Balance = balance
+		statement.TotalDebit = statement.TotalDebit.Add(line.Debit)
+		statement.TotalCredit = statement.TotalCredit.Add(line.Credit)
+	}
+	statement.ClosingBalance = balance
+	return statement, nil
+}
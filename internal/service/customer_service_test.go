@@ -0,0 +1,273 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"sancaksoft/internal/domain"
+	"sancaksoft/internal/repository"
+	"sancaksoft/internal/service"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCustomerCredit_Integration(t *testing.T) {
+	if testDBURL == "" {
+		t.Skip("Skipping integration test: TEST_DB_URL not set")
+	}
+
+	ctx := context.Background()
+	db, err := pgxpool.New(ctx, testDBURL)
+	require.NoError(t, err)
+	defer db.Close()
+
+	// 1. Setup Data: a customer with a 1000 limit on 30 days, a clerk and a manager
+	tenantID := uuid.New()
+	warehouseID := uuid.New()
+	customerID := uuid.New()
+	productID := uuid.New()
+	clerkID := uuid.New()
+	managerID := uuid.New()
+
+	defer func() {
+		_, _ = db.Exec(ctx, "DELETE FROM tenants WHERE id = $1", tenantID)
+	}()
+
+	_, err = db.Exec(ctx, "INSERT INTO tenants (id, name) VALUES ($1, 'Credit Test Tenant')", tenantID)
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, "INSERT INTO warehouses (id, tenant_id, name) VALUES ($1, $2, 'Main')", warehouseID, tenantID)
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, "INSERT INTO customers (id, tenant_id, name) VALUES ($1, $2, 'Slow Payer')", customerID, tenantID)
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, "INSERT INTO products (id, tenant_id, name, sku, price) VALUES ($1, $2, 'Widget', $3, 100.00)", productID, tenantID, "SKU-"+uuid.New().String())
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, "INSERT INTO stock_movements (tenant_id, product_id, warehouse_id, quantity, type) VALUES ($1, $2, $3, 100, 'IN')", tenantID, productID, warehouseID)
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, `INSERT INTO users (id, tenant_id, email, password_hash, role) VALUES
+		($1, $3, $4, 'x', 'user'), ($2, $3, $5, 'x', 'manager')`,
+		clerkID, managerID, tenantID, uuid.New().String()+"@clerk.test", uuid.New().String()+"@manager.test")
+	require.NoError(t, err)
+
+	customerService := service.NewCustomerService(db, repository.NewCustomerRepository(db))
+	invoiceService := service.NewInvoiceService(db, repository.NewInvoiceRepository())
+
+	limit := decimal.NewFromInt(1000)
+	_, err = customerService.SetCreditTerms(ctx, tenantID, customerID, &limit, -1)
+	assert.ErrorIs(t, err, service.ErrInvalidInput)
+	customer, err := customerService.SetCreditTerms(ctx, tenantID, customerID, &limit, 30)
+	require.NoError(t, err)
+	assert.Equal(t, 30, customer.PaymentTermDays)
+
+	invoice := func(userID uuid.UUID, quantity int, overrideReason string) (*domain.Invoice, error) {
+		return invoiceService.CreateInvoice(ctx, domain.CreateInvoiceRequest{
+			TenantID:    tenantID,
+			UserID:      userID,
+			CustomerID:  customerID,
+			WarehouseID: warehouseID,
+			Items:       []domain.InvoiceItemRequest{{ProductID: productID, Quantity: quantity, UnitPrice: decimal.NewFromInt(100)}},
+
+			CreditOverrideReason: overrideReason,
+		})
+	}
+
+	// 2. 800 fits the limit and falls due in 30 days
+	first, err := invoice(clerkID, 8, "")
+	require.NoError(t, err)
+	assert.Equal(t, time.Now().AddDate(0, 0, 30).Format("2006-01-02"), first.DueDate.Format("2006-01-02"))
+
+	// 3. Another 300 would exceed it; only a manager can override, and the override is audited
+	_, err = invoice(clerkID, 3, "")
+	assert.ErrorIs(t, err, service.ErrInvalidState)
+	_, err = invoice(clerkID, 3, "Long-standing customer")
+	assert.ErrorIs(t, err, service.ErrForbidden)
+	second, err := invoice(managerID, 3, "Long-standing customer")
+	require.NoError(t, err)
+
+	var reason, userID string
+	err = db.QueryRow(ctx, "SELECT details->>'reason', user_id::text FROM audit_logs WHERE tenant_id = $1 AND entity_id = $2 AND action = 'CREDIT_OVERRIDE'", tenantID, second.ID).Scan(&reason, &userID)
+	require.NoError(t, err)
+	assert.Equal(t, "Long-standing customer", reason)
+	assert.Equal(t, managerID.String(), userID)
+
+	credit, err := customerService.GetCredit(ctx, tenantID, customerID)
+	require.NoError(t, err)
+	assert.True(t, credit.OpenBalance.Equal(decimal.NewFromInt(1100)))
+	assert.True(t, credit.Available.Equal(decimal.NewFromInt(-100)))
+	assert.True(t, credit.OverdueAmount.IsZero())
+
+	// 4. Once the first invoice is past due, payments settle it first
+	_, err = db.Exec(ctx, "UPDATE invoices SET due_date = CURRENT_DATE - 1 WHERE id = $1", first.ID)
+	require.NoError(t, err)
+	require.NoError(t, customerService.RecordPayment(ctx, &domain.CustomerPayment{
+		TenantID: tenantID, CustomerID: customerID, Amount: decimal.NewFromInt(500), Method: domain.PaymentMethodBankTransfer,
+	}))
+
+	credit, err = customerService.GetCredit(ctx, tenantID, customerID)
+	require.NoError(t, err)
+	assert.True(t, credit.OpenBalance.Equal(decimal.NewFromInt(600)))
+	assert.True(t, credit.OverdueAmount.Equal(decimal.NewFromInt(300)))
+
+	// Within the limit, but still on hold while anything is overdue
+	_, err = invoice(clerkID, 1, "")
+	assert.ErrorIs(t, err, service.ErrInvalidState)
+
+	// Delivery notes are held the same way: the stock leaves when the note ships
+	deliveryNoteService := service.NewDeliveryNoteService(db, repository.NewDeliveryNoteRepository(db), invoiceService)
+	ship := func(userID uuid.UUID, overrideReason string) (*domain.DeliveryNote, error) {
+		return deliveryNoteService.CreateDeliveryNote(ctx, domain.CreateDeliveryNoteRequest{
+			TenantID:    tenantID,
+			UserID:      userID,
+			CustomerID:  customerID,
+			WarehouseID: warehouseID,
+			Items:       []domain.DeliveryNoteItemRequest{{ProductID: productID, Quantity: 1, UnitPrice: decimal.NewFromInt(100)}},
+
+			CreditOverrideReason: overrideReason,
+		})
+	}
+	_, err = ship(clerkID, "")
+	assert.ErrorIs(t, err, service.ErrInvalidState)
+	_, err = ship(clerkID, "Urgent delivery")
+	assert.ErrorIs(t, err, service.ErrForbidden)
+	note, err := ship(managerID, "Urgent delivery")
+	require.NoError(t, err)
+	err = db.QueryRow(ctx, "SELECT details->>'reason', user_id::text FROM audit_logs WHERE tenant_id = $1 AND entity_id = $2 AND action = 'CREDIT_OVERRIDE'", tenantID, note.ID).Scan(&reason, &userID)
+	require.NoError(t, err)
+	assert.Equal(t, "Urgent delivery", reason)
+	assert.Equal(t, managerID.String(), userID)
+
+	// Converting a quotation to an invoice is held and overridden the same way
+	orderService := service.NewSalesOrderService(db, repository.NewSalesOrderRepository(db), invoiceService)
+	quoteService := service.NewQuotationService(db, repository.NewQuotationRepository(db), orderService, invoiceService)
+	quote, err := quoteService.CreateQuotation(ctx, domain.CreateQuotationRequest{
+		TenantID:    tenantID,
+		UserID:      clerkID,
+		CustomerID:  customerID,
+		WarehouseID: warehouseID,
+		ValidUntil:  time.Now().AddDate(0, 0, 7),
+		Items:       []domain.QuotationItemRequest{{ProductID: productID, Quantity: 1, UnitPrice: decimal.NewFromInt(100)}},
+	})
+	require.NoError(t, err)
+	convert := func(userID uuid.UUID, overrideReason string) (*domain.Invoice, error) {
+		return quoteService.ConvertToInvoice(ctx, domain.ConvertQuotationRequest{
+			TenantID: tenantID, UserID: userID, QuotationID: quote.ID, IdempotencyKey: uuid.New(),
+
+			CreditOverrideReason: overrideReason,
+		})
+	}
+	_, err = convert(clerkID, "")
+	assert.ErrorIs(t, err, service.ErrInvalidState)
+	_, err = convert(clerkID, "Agreed price")
+	assert.ErrorIs(t, err, service.ErrForbidden)
+	converted, err := convert(managerID, "Agreed price")
+	require.NoError(t, err)
+	err = db.QueryRow(ctx, "SELECT details->>'reason', user_id::text FROM audit_logs WHERE tenant_id = $1 AND entity_id = $2 AND action = 'CREDIT_OVERRIDE'", tenantID, converted.ID).Scan(&reason, &userID)
+	require.NoError(t, err)
+	assert.Equal(t, "Agreed price", reason)
+	assert.Equal(t, managerID.String(), userID)
+
+	require.NoError(t, customerService.RecordPayment(ctx, &domain.CustomerPayment{
+		TenantID: tenantID, CustomerID: customerID, Amount: decimal.NewFromInt(300), Method: domain.PaymentMethodCash,
+	}))
+	_, err = invoice(clerkID, 1, "")
+	require.NoError(t, err)
+
+	err = customerService.RecordPayment(ctx, &domain.CustomerPayment{
+		TenantID: tenantID, CustomerID: customerID, Amount: decimal.NewFromInt(10), Method: "BARTER",
+	})
+	assert.ErrorIs(t, err, service.ErrInvalidInput)
+	payments, err := customerService.ListPayments(ctx, tenantID, customerID)
+	require.NoError(t, err)
+	assert.Len(t, payments, 2)
+}
+
+func TestCustomerStatement_Integration(t *testing.T) {
+	if testDBURL == "" {
+		t.Skip("Skipping integration test: TEST_DB_URL not set")
+	}
+
+	ctx := context.Background()
+	db, err := pgxpool.New(ctx, testDBURL)
+	require.NoError(t, err)
+	defer db.Close()
+
+	// 1. Setup Data: invoices in December and January, a January return and payment
+	tenantID := uuid.New()
+	warehouseID := uuid.New()
+	customerID := uuid.New()
+	productID := uuid.New()
+
+	defer func() {
+		_, _ = db.Exec(ctx, "DELETE FROM tenants WHERE id = $1", tenantID)
+	}()
+
+	_, err = db.Exec(ctx, "INSERT INTO tenants (id, name) VALUES ($1, 'Statement Test Tenant')", tenantID)
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, "INSERT INTO warehouses (id, tenant_id, name) VALUES ($1, $2, 'Main')", warehouseID, tenantID)
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, "INSERT INTO customers (id, tenant_id, name) VALUES ($1, $2, 'Statement Customer')", customerID, tenantID)
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, "INSERT INTO products (id, tenant_id, name, sku, price) VALUES ($1, $2, 'Widget', $3, 100.00)", productID, tenantID, "SKU-"+uuid.New().String())
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, `INSERT INTO invoices (tenant_id, warehouse_id, customer_id, invoice_number, total_amount, due_date, created_at) VALUES
+		($1, $2, $3, 'ST-1', 400, '2026-01-15', '2025-12-16 10:00'),
+		($1, $2, $3, 'ST-2', 1000, '2026-02-10', '2026-01-10 10:00'),
+		($1, $2, $3, 'ST-3', 250, '2026-03-05', '2026-02-03 10:00')`,
+		tenantID, warehouseID, customerID)
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, `INSERT INTO customer_returns (tenant_id, customer_id, product_id, warehouse_id, quantity, unit_price, total, status, received_at, created_at)
+		VALUES ($1, $2, $3, $4, 1, 100, 100, 'RECEIVED', '2026-01-12 09:00', '2026-01-08 09:00'),
+		       ($1, $2, $3, $4, 2, 100, 200, 'REQUESTED', NULL, '2026-01-13 09:00')`, tenantID, customerID, productID, warehouseID)
+	require.NoError(t, err)
+
+	customerService := service.NewCustomerService(db, repository.NewCustomerRepository(db))
+	require.NoError(t, customerService.RecordPayment(ctx, &domain.CustomerPayment{
+		TenantID: tenantID, CustomerID: customerID, Amount: decimal.NewFromInt(600), Method: domain.PaymentMethodCash,
+		PaymentDate: time.Date(2026, 1, 20, 0, 0, 0, 0, time.UTC), Reference: "MKB-7",
+	}))
+
+	// 2. January: December's invoice is brought forward, February's is left out
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+	statement, err := customerService.GetStatement(ctx, domain.CustomerStatementRequest{
+		TenantID: tenantID, CustomerID: customerID, From: &from, To: &to,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Statement Test Tenant", statement.Company.Name)
+	assert.True(t, statement.OpeningBalance.Equal(decimal.NewFromInt(400)))
+
+	require.Len(t, statement.Lines, 3)
+	assert.Equal(t, domain.StatementLineSale, statement.Lines[0].Type)
+	assert.Equal(t, "ST-2", statement.Lines[0].DocumentNumber)
+	assert.True(t, statement.Lines[0].Balance.Equal(decimal.NewFromInt(1400)))
+	assert.Equal(t, domain.StatementLineReturn, statement.Lines[1].Type)
+	assert.True(t, statement.Lines[1].Balance.Equal(decimal.NewFromInt(1300)))
+	assert.Equal(t, domain.StatementLinePayment, statement.Lines[2].Type)
+	assert.Equal(t, "MKB-7", statement.Lines[2].DocumentNumber)
+	assert.True(t, statement.Lines[2].Credit.Equal(decimal.NewFromInt(600)))
+
+	assert.True(t, statement.TotalDebit.Equal(decimal.NewFromInt(1000)))
+	assert.True(t, statement.TotalCredit.Equal(decimal.NewFromInt(700)))
+	assert.True(t, statement.ClosingBalance.Equal(decimal.NewFromInt(700)))
+
+	// 3. Without a range every movement is listed; the ledger agrees on the net change
+	all, err := customerService.GetStatement(ctx, domain.CustomerStatementRequest{TenantID: tenantID, CustomerID: customerID})
+	require.NoError(t, err)
+	assert.Len(t, all.Lines, 5)
+	assert.True(t, all.ClosingBalance.Equal(decimal.NewFromInt(950)))
+
+	ledger, err := customerService.ListCustomerLedger(ctx, tenantID, customerID, "month")
+	require.NoError(t, err)
+	net := decimal.Zero
+	for _, e := range ledger {
+		net = net.Add(e.NetAmount)
+	}
+	assert.True(t, net.Equal(all.ClosingBalance))
+
+	_, err = customerService.GetStatement(ctx, domain.CustomerStatementRequest{TenantID: tenantID, CustomerID: customerID, From: &to, To: &from})
+	assert.ErrorIs(t, err, service.ErrInvalidInput)
+}
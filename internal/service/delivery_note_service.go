@@ -0,0 +1,354 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"sancaksoft/internal/domain"
+	"sancaksoft/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shopspring/decimal"
+)
+
+type DeliveryNoteService struct {
+	db       *pgxpool.Pool
+	repo     *repository.DeliveryNoteRepository
+	invoices *InvoiceService
+}
+
+func NewDeliveryNoteService(db *pgxpool.Pool, repo *repository.DeliveryNoteRepository, invoices *InvoiceService) *DeliveryNoteService {
+	return &DeliveryNoteService{db: db, repo: repo, invoices: invoices}
+}
+
+// CreateDeliveryNote ships goods: every line writes a SALE movement out of the
+// warehouse after checking unreserved stock under the product lock. The stock leaves
+// here, so the customer's credit is checked here rather than when the note is invoiced.
+func (s *DeliveryNoteService) CreateDeliveryNote(ctx context.Context, req domain.CreateDeliveryNoteRequest) (*domain.DeliveryNote, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	if len(req.Items) == 0 {
+		return nil, fmt.Errorf("delivery note must have at least one item: %w", ErrInvalidInput)
+	}
+	for _, item := range req.Items {
+		if item.Quantity <= 0 {
+			return nil, fmt.Errorf("quantity must be greater than zero: %w", ErrInvalidInput)
+		}
+	}
+
+	var created *domain.DeliveryNote
+	err := WithTransaction(ctx, s.db, func(tx pgx.Tx) error {
+		isQuarantine, err := s.invoices.repo.IsQuarantineWarehouse(ctx, tx, req.TenantID, req.WarehouseID)
+		if err != nil {
+			return err
+		}
+		if isQuarantine {
+			return fmt.Errorf("cannot ship from quarantine warehouse %s: %w", req.WarehouseID, ErrInvalidInput)
+		}
+
+		// Locked so concurrent shipments are checked against the customer's credit one at a time
+		customer, err := s.invoices.repo.LockCustomer(ctx, tx, req.TenantID, req.CustomerID)
+		if err != nil {
+			return err
+		}
+		if customer == nil {
+			return fmt.Errorf("customer %s: %w", req.CustomerID, ErrNotFound)
+		}
+		var total decimal.Decimal
+		for _, item := range req.Items {
+			total = total.Add(item.UnitPrice.Mul(decimal.NewFromInt(int64(item.Quantity))))
+		}
+		creditOverride, err := s.invoices.checkCredit(ctx, tx, req.TenantID, req.UserID, req.CreditOverrideReason, customer, total)
+		if err != nil {
+			return err
+		}
+
+		required := make(map[uuid.UUID]int)
+		var products []uuid.UUID
+		for _, item := range req.Items {
+			if _, ok := required[item.ProductID]; !ok {
+				products = append(products, item.ProductID)
+			}
+			required[item.ProductID] += item.Quantity
+		}
+		for _, productID := range products {
+			if err := s.invoices.repo.LockProduct(ctx, tx, req.TenantID, productID); err != nil {
+				return err
+			}
+			components, err := s.invoices.repo.ListKitComponents(ctx, tx, req.TenantID, productID)
+			if err != nil {
+				return err
+			}
+			if len(components) > 0 {
+				return fmt.Errorf("product %s is a kit; kits are sold by invoice: %w", productID, ErrInvalidInput)
+			}
+			onHand, err := s.invoices.repo.GetStockBalance(ctx, tx, req.TenantID, productID, req.WarehouseID)
+			if err != nil {
+				return err
+			}
+			reserved, err := s.invoices.repo.GetReservedQuantity(ctx, tx, req.TenantID, productID, req.WarehouseID, nil)
+			if err != nil {
+				return err
+			}
+			if available := onHand - reserved; available < required[productID] {
+				return fmt.Errorf("insufficient stock for product %s. Available: %d, Requested: %d: %w", productID, available, required[productID], ErrInvalidState)
+			}
+		}
+
+		noteNumber, err := s.repo.GenerateNextNoteNumber(ctx, tx, req.TenantID)
+		if err != nil {
+			return err
+		}
+
+		note := &domain.DeliveryNote{
+			ID:          uuid.New(),
+			TenantID:    req.TenantID,
+			CustomerID:  req.CustomerID,
+			WarehouseID: req.WarehouseID,
+			NoteNumber:  noteNumber,
+			Status:      domain.DeliveryNoteStatusShipped,
+			Note:        req.Note,
+			TotalAmount: total,
+		}
+		if err := s.repo.CreateDeliveryNote(ctx, tx, note); err != nil {
+			return fmt.Errorf("failed to create delivery note: %w", err)
+		}
+
+		for _, itemReq := range req.Items {
+			item := domain.DeliveryNoteItem{
+				ID:             uuid.New(),
+				TenantID:       req.TenantID,
+				DeliveryNoteID: note.ID,
+				ProductID:      itemReq.ProductID,
+				Quantity:       itemReq.Quantity,
+				UnitPrice:      itemReq.UnitPrice,
+				Total:          itemReq.UnitPrice.Mul(decimal.NewFromInt(int64(itemReq.Quantity))),
+			}
+			if err := s.repo.CreateDeliveryNoteItem(ctx, tx, &item); err != nil {
+				return fmt.Errorf("failed to create delivery note item: %w", err)
+			}
+			note.Items = append(note.Items, item)
+
+			allocations, err := s.invoices.allocateSale(ctx, tx, req.TenantID, item.ProductID, req.WarehouseID, item.Quantity, itemReq.LotNumber, itemReq.SerialNumbers)
+			if err != nil {
+				return err
+			}
+			for _, allocation := range allocations {
+				if err := s.createMovement(ctx, tx, note, item.ProductID, -allocation.quantity, domain.StockMovementTypeSale, allocation); err != nil {
+					return err
+				}
+			}
+		}
+
+		created = note
+		entry := domain.AuditLog{
+			TenantID: note.TenantID, EntityType: domain.AuditEntityDeliveryNote, EntityID: note.ID, Action: domain.AuditActionCreate,
+		}
+		if req.UserID != uuid.Nil {
+			entry.UserID = &req.UserID
+		}
+		if err := recordAudit(ctx, s.repo, tx, entry, nil, note); err != nil {
+			return err
+		}
+		if creditOverride == nil {
+			return nil
+		}
+		entry.Action = domain.AuditActionCreditOverride
+		entry.Details = creditOverride
+		return recordAudit(ctx, s.repo, tx, entry, nil, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// CancelDeliveryNote cancels an uninvoiced note and puts its goods back into the warehouse.
+func (s *DeliveryNoteService) CancelDeliveryNote(ctx context.Context, tenantID, noteID uuid.UUID) (*domain.DeliveryNote, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	var result *domain.DeliveryNote
+	err := WithTransaction(ctx, s.db, func(tx pgx.Tx) error {
+		note, err := s.repo.GetDeliveryNoteForUpdate(ctx, tx, tenantID, noteID)
+		if err != nil {
+			return err
+		}
+		if note == nil {
+			return fmt.Errorf("delivery note %s: %w", noteID, ErrNotFound)
+		}
+		if note.Status != domain.DeliveryNoteStatusShipped {
+			return fmt.Errorf("delivery note is %s and cannot be cancelled: %w", note.Status, ErrInvalidState)
+		}
+
+		// Reverse the note's own movements so every lot, location and serial number gets back what it shipped
+		movements, err := s.repo.ListNoteMovements(ctx, tx, tenantID, note.ID)
+		if err != nil {
+			return err
+		}
+		for _, m := range movements {
+			reversal := stockAllocation{lotID: m.LotID, locationID: m.LocationID, serials: m.SerialNumbers}
+			if err := s.createMovement(ctx, tx, note, m.ProductID, -m.Quantity, domain.StockMovementTypeIn, reversal); err != nil {
+				return err
+			}
+		}
+
+		before := *note
+		note.Status = domain.DeliveryNoteStatusCancelled
+		if err := s.repo.UpdateDeliveryNoteStatus(ctx, tx, note); err != nil {
+			return fmt.Errorf("failed to update delivery note: %w", err)
+		}
+		result = note
+		return recordAudit(ctx, s.repo, tx, domain.AuditLog{
+			TenantID: tenantID, EntityType: domain.AuditEntityDeliveryNote, EntityID: note.ID, Action: domain.AuditActionCancel,
+		}, &before, note)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *DeliveryNoteService) createMovement(ctx context.Context, tx pgx.Tx, note *domain.DeliveryNote, productID uuid.UUID, quantity int, movementType domain.StockMovementType, part stockAllocation) error {
+	refType := "DELIVERY_NOTE"
+	movement := &domain.StockMovement{
+		ID:            uuid.New(),
+		TenantID:      note.TenantID,
+		ProductID:     productID,
+		WarehouseID:   note.WarehouseID,
+		Quantity:      quantity,
+		Type:          movementType,
+		ReferenceID:   &note.ID,
+		ReferenceType: &refType,
+		LotID:         part.lotID,
+		LocationID:    part.locationID,
+		SerialNumbers: part.serials,
+	}
+	if err := s.invoices.repo.CreateStockMovement(ctx, tx, movement); err != nil {
+		return fmt.Errorf("failed to create stock movement: %w", err)
+	}
+	return moveSerials(ctx, tx, s.invoices.repo, movement)
+}
+
+// InvoiceDeliveryNotes bills several shipped notes of one customer and warehouse on a
+// single invoice. Stock already left with the notes, so the invoice moves none.
+func (s *DeliveryNoteService) InvoiceDeliveryNotes(ctx context.Context, req domain.InvoiceDeliveryNotesRequest) (*domain.Invoice, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	if len(req.DeliveryNoteIDs) == 0 {
+		return nil, fmt.Errorf("at least one delivery note is required: %w", ErrInvalidInput)
+	}
+
+	// Lock notes in a stable order so concurrent billing runs cannot deadlock
+	ids := make([]uuid.UUID, 0, len(req.DeliveryNoteIDs))
+	seen := make(map[uuid.UUID]bool)
+	for _, id := range req.DeliveryNoteIDs {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return bytes.Compare(ids[i][:], ids[j][:]) < 0 })
+
+	var invoice *domain.Invoice
+	err := WithTransaction(ctx, s.db, func(tx pgx.Tx) error {
+		notes := make([]*domain.DeliveryNote, 0, len(ids))
+		for _, id := range ids {
+			note, err := s.repo.GetDeliveryNoteForUpdate(ctx, tx, req.TenantID, id)
+			if err != nil {
+				return err
+			}
+			if note == nil {
+				return fmt.Errorf("delivery note %s: %w", id, ErrNotFound)
+			}
+			if note.Status != domain.DeliveryNoteStatusShipped {
+				return fmt.Errorf("delivery note %s is %s and cannot be invoiced: %w", note.NoteNumber, note.Status, ErrInvalidState)
+			}
+			if note.CustomerID != req.CustomerID {
+				return fmt.Errorf("delivery note %s belongs to another customer: %w", note.NoteNumber, ErrInvalidInput)
+			}
+			if len(notes) > 0 && note.WarehouseID != notes[0].WarehouseID {
+				return fmt.Errorf("delivery notes must ship from the same warehouse: %w", ErrInvalidInput)
+			}
+			notes = append(notes, note)
+		}
+		sort.SliceStable(notes, func(i, j int) bool { return notes[i].ShippedAt.Before(notes[j].ShippedAt) })
+
+		invoiceReq := domain.CreateInvoiceRequest{
+			TenantID:        req.TenantID,
+			UserID:          req.UserID,
+			WarehouseID:     notes[0].WarehouseID,
+			CustomerID:      req.CustomerID,
+			IdempotencyKey:  req.IdempotencyKey,
+			DeliveryNoteIDs: ids,
+		}
+		for _, note := range notes {
+			for _, item := range note.Items {
+				invoiceReq.Items = append(invoiceReq.Items, domain.InvoiceItemRequest{
+					ProductID: item.ProductID,
+					Quantity:  item.Quantity,
+					UnitPrice: item.UnitPrice,
+				})
+			}
+		}
+
+		created, err := s.invoices.createInvoiceTx(ctx, tx, invoiceReq)
+		if err != nil {
+			return err
+		}
+
+		for _, note := range notes {
+			before := *note
+			note.Status = domain.DeliveryNoteStatusInvoiced
+			note.InvoiceID = &created.ID
+			if err := s.repo.UpdateDeliveryNoteStatus(ctx, tx, note); err != nil {
+				return fmt.Errorf("failed to update delivery note: %w", err)
+			}
+			if err := recordAudit(ctx, s.repo, tx, domain.AuditLog{
+				TenantID: req.TenantID, EntityType: domain.AuditEntityDeliveryNote, EntityID: note.ID, Action: domain.AuditActionInvoice,
+			}, &before, note); err != nil {
+				return err
+			}
+		}
+		invoice = created
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return invoice, nil
+}
+
+func (s *DeliveryNoteService) GetDeliveryNote(ctx context.Context, tenantID, noteID uuid.UUID) (*domain.DeliveryNote, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	note, err := s.repo.GetDeliveryNote(ctx, tenantID, noteID)
+	if err != nil {
+		return nil, err
+	}
+	if note == nil {
+		return nil, fmt.Errorf("delivery note %s: %w", noteID, ErrNotFound)
+	}
+	return note, nil
+}
+
+func (s *DeliveryNoteService) ListDeliveryNotes(ctx context.Context, tenantID uuid.UUID, customerID *uuid.UUID, status domain.DeliveryNoteStatus) ([]domain.DeliveryNote, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	return s.repo.ListDeliveryNotes(ctx, tenantID, customerID, status)
+}
+
+// GetUninvoicedReport lists shipped-but-uninvoiced delivery lines.
+func (s *DeliveryNoteService) GetUninvoicedReport(ctx context.Context, tenantID uuid.UUID, customerID *uuid.UUID, from, to *time.Time) ([]domain.UninvoicedDeliveryLine, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	return s.repo.ListUninvoicedLines(ctx, tenantID, customerID, from, to)
+}
@@ -0,0 +1,118 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"sancaksoft/internal/domain"
+	"sancaksoft/internal/repository"
+	"sancaksoft/internal/service"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeliveryNoteConsolidatedInvoice_Integration(t *testing.T) {
+	if testDBURL == "" {
+		t.Skip("Skipping integration test: TEST_DB_URL not set")
+	}
+
+	ctx := context.Background()
+	db, err := pgxpool.New(ctx, testDBURL)
+	require.NoError(t, err)
+	defer db.Close()
+
+	// 1. Setup Data: 30 units on hand
+	tenantID := uuid.New()
+	warehouseID := uuid.New()
+	productID := uuid.New()
+	customerID := uuid.New()
+	userID := uuid.New()
+
+	defer func() {
+		_, _ = db.Exec(ctx, "DELETE FROM tenants WHERE id = $1", tenantID)
+	}()
+
+	_, err = db.Exec(ctx, "INSERT INTO tenants (id, name) VALUES ($1, 'Delivery Test Tenant')", tenantID)
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, "INSERT INTO warehouses (id, tenant_id, name) VALUES ($1, $2, 'Main')", warehouseID, tenantID)
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, "INSERT INTO products (id, tenant_id, name, sku, price) VALUES ($1, $2, 'Sack', $3, 50.00)", productID, tenantID, "SKU-"+uuid.New().String())
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, "INSERT INTO customers (id, tenant_id, name) VALUES ($1, $2, 'Wholesale Customer')", customerID, tenantID)
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, `
+		INSERT INTO stock_movements (id, tenant_id, product_id, warehouse_id, quantity, type, created_at)
+		VALUES ($1, $2, $3, $4, 30, 'IN', NOW())
+	`, uuid.New(), tenantID, productID, warehouseID)
+	require.NoError(t, err)
+
+	invoiceService := service.NewInvoiceService(db, repository.NewInvoiceRepository())
+	noteService := service.NewDeliveryNoteService(db, repository.NewDeliveryNoteRepository(db), invoiceService)
+
+	getStock := func() int {
+		var q int
+		_ = db.QueryRow(ctx, "SELECT COALESCE(SUM(quantity), 0) FROM stock_movements WHERE tenant_id = $1 AND product_id = $2", tenantID, productID).Scan(&q)
+		return q
+	}
+	ship := func(qty int) *domain.DeliveryNote {
+		note, err := noteService.CreateDeliveryNote(ctx, domain.CreateDeliveryNoteRequest{
+			TenantID:    tenantID,
+			UserID:      userID,
+			CustomerID:  customerID,
+			WarehouseID: warehouseID,
+			Items:       []domain.DeliveryNoteItemRequest{{ProductID: productID, Quantity: qty, UnitPrice: decimal.NewFromInt(50)}},
+		})
+		require.NoError(t, err)
+		return note
+	}
+
+	// 2. Two shipments move stock immediately
+	first := ship(5)
+	second := ship(7)
+	assert.Equal(t, 18, getStock())
+
+	report, err := noteService.GetUninvoicedReport(ctx, tenantID, &customerID, nil, nil)
+	require.NoError(t, err)
+	assert.Len(t, report, 2)
+
+	// 3. One consolidated invoice bills both without moving stock again
+	invoice, err := noteService.InvoiceDeliveryNotes(ctx, domain.InvoiceDeliveryNotesRequest{
+		TenantID:        tenantID,
+		UserID:          userID,
+		CustomerID:      customerID,
+		DeliveryNoteIDs: []uuid.UUID{first.ID, second.ID},
+		IdempotencyKey:  uuid.New(),
+	})
+	require.NoError(t, err)
+	assert.True(t, decimal.NewFromInt(600).Equal(invoice.TotalAmount))
+	assert.Equal(t, 18, getStock())
+
+	billed, err := noteService.GetDeliveryNote(ctx, tenantID, first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.DeliveryNoteStatusInvoiced, billed.Status)
+	require.NotNil(t, billed.InvoiceID)
+	assert.Equal(t, invoice.ID, *billed.InvoiceID)
+
+	report, err = noteService.GetUninvoicedReport(ctx, tenantID, &customerID, nil, nil)
+	require.NoError(t, err)
+	assert.Empty(t, report)
+
+	// 4. Billed notes cannot be invoiced or cancelled again
+	_, err = noteService.InvoiceDeliveryNotes(ctx, domain.InvoiceDeliveryNotesRequest{
+		TenantID: tenantID, UserID: userID, CustomerID: customerID, DeliveryNoteIDs: []uuid.UUID{first.ID}, IdempotencyKey: uuid.New(),
+	})
+	assert.ErrorIs(t, err, service.ErrInvalidState)
+	_, err = noteService.CancelDeliveryNote(ctx, tenantID, second.ID)
+	assert.ErrorIs(t, err, service.ErrInvalidState)
+
+	// 5. Cancelling an uninvoiced note returns its goods
+	third := ship(3)
+	assert.Equal(t, 15, getStock())
+	_, err = noteService.CancelDeliveryNote(ctx, tenantID, third.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 18, getStock())
+}
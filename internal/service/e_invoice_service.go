@@ -0,0 +1,231 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"sancaksoft/internal/domain"
+	"sancaksoft/internal/einvoice"
+	"sancaksoft/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type EInvoiceService struct {
+	db       *pgxpool.Pool
+	repo     *repository.EInvoiceRepository
+	provider einvoice.Provider
+}
+
+func NewEInvoiceService(db *pgxpool.Pool, repo *repository.EInvoiceRepository, provider einvoice.Provider) *EInvoiceService {
+	return &EInvoiceService{db: db, repo: repo, provider: provider}
+}
+
+var eInvoiceProfiles = map[domain.EInvoiceProfile]bool{
+	domain.EInvoiceProfileBasic:      true,
+	domain.EInvoiceProfileCommercial: true,
+	domain.EInvoiceProfileArchive:    true,
+}
+
+// Generate builds and validates the UBL-TR document of an invoice and stores it as a
+// draft. A draft or rejected document can be regenerated; it keeps its ETTN, and its
+// number unless the series changes.
+func (s *EInvoiceService) Generate(ctx context.Context, req domain.GenerateEInvoiceRequest) (*domain.EInvoice, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	if req.Profile != "" && !eInvoiceProfiles[req.Profile] {
+		return nil, fmt.Errorf("unknown e-invoice profile %s: %w", req.Profile, ErrInvalidInput)
+	}
+
+	var e *domain.EInvoice
+	err := WithTransaction(ctx, s.db, func(tx pgx.Tx) error {
+		doc, err := s.repo.GetInvoiceDocument(ctx, tx, req.TenantID, req.InvoiceID)
+		if err != nil {
+			return err
+		}
+		if doc == nil {
+			return fmt.Errorf("invoice %s: %w", req.InvoiceID, ErrNotFound)
+		}
+		applyVAT(doc)
+
+		profile, err := s.profile(ctx, doc.Customer.TaxNumber, req.Profile)
+		if err != nil {
+			return err
+		}
+
+		e, err = s.repo.GetEInvoiceForUpdate(ctx, tx, req.TenantID, req.InvoiceID)
+		if err != nil {
+			return err
+		}
+		var before *domain.EInvoice
+		if e != nil {
+			current := *e
+			before = &current
+		}
+		if e == nil {
+			e = &domain.EInvoice{ID: uuid.New(), TenantID: req.TenantID, InvoiceID: req.InvoiceID, ETTN: uuid.New()}
+		} else if e.Status != domain.EInvoiceStatusDraft && e.Status != domain.EInvoiceStatusRejected {
+			return fmt.Errorf("e-invoice %s is %s and cannot be regenerated: %w", e.DocumentNumber, e.Status, ErrInvalidState)
+		}
+
+		series := einvoice.Series(profile)
+		if e.DocumentNumber == "" || einvoice.Series(e.Profile) != series {
+			year := einvoice.IssueYear(doc.Invoice)
+			seq, err := s.repo.NextDocumentSequence(ctx, tx, req.TenantID, series, year)
+			if err != nil {
+				return err
+			}
+			e.DocumentNumber = einvoice.DocumentNumber(series, year, seq)
+		}
+		e.Profile = profile
+		e.Status = domain.EInvoiceStatusDraft
+		e.ProviderReference, e.StatusMessage, e.SentAt = "", "", nil
+
+		e.XML, err = einvoice.Build(doc, e)
+		if err != nil {
+			return err
+		}
+		if err := einvoice.Validate(e.XML); err != nil {
+			return fmt.Errorf("%v: %w", err, ErrInvalidInput)
+		}
+		if err := s.repo.SaveEInvoice(ctx, tx, e); err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.repo, tx, domain.AuditLog{
+			TenantID: req.TenantID, EntityType: domain.AuditEntityEInvoice, EntityID: e.ID, Action: domain.AuditActionGenerate,
+		}, before, e)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// profile checks the requested profile against the customer's e-Fatura registration, or
+// picks one: TEMELFATURA for registered users, EARSIVFATURA for everyone else.
+func (s *EInvoiceService) profile(ctx context.Context, taxNumber string, requested domain.EInvoiceProfile) (domain.EInvoiceProfile, error) {
+	if requested == domain.EInvoiceProfileArchive {
+		return requested, nil
+	}
+	registered := false
+	if taxNumber != "" {
+		var err error
+		registered, _, err = s.provider.LookupRecipient(ctx, taxNumber)
+		if err != nil {
+			return "", fmt.Errorf("failed to look up e-Fatura registration: %w", err)
+		}
+	}
+	switch {
+	case requested == "" && registered:
+		return domain.EInvoiceProfileBasic, nil
+	case requested == "":
+		return domain.EInvoiceProfileArchive, nil
+	case !registered:
+		return "", fmt.Errorf("customer is not a registered e-Fatura user, use %s: %w", domain.EInvoiceProfileArchive, ErrInvalidInput)
+	}
+	return requested, nil
+}
+
+// Send submits a draft or rejected e-invoice to the integrator and records its answer. A
+// rejection is stored on the e-invoice rather than returned as an error.
+//
+// The document is marked SENDING and committed before the integrator is called, so no
+// lock is held while waiting for it, and the answer is recorded in a second transaction.
+// If the call fails or the answer is lost, the document stays SENDING and Send can be
+// called again: it resubmits under the same ETTN, which the integrator answers only once.
+func (s *EInvoiceService) Send(ctx context.Context, tenantID, invoiceID uuid.UUID) (*domain.EInvoice, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	var e *domain.EInvoice
+	var before domain.EInvoice
+	var receiver string
+	err := WithTransaction(ctx, s.db, func(tx pgx.Tx) error {
+		var err error
+		e, err = s.repo.GetEInvoiceForUpdate(ctx, tx, tenantID, invoiceID)
+		if err != nil {
+			return err
+		}
+		if e == nil {
+			return fmt.Errorf("no e-invoice for invoice %s: %w", invoiceID, ErrNotFound)
+		}
+		switch e.Status {
+		case domain.EInvoiceStatusDraft, domain.EInvoiceStatusRejected, domain.EInvoiceStatusSending:
+		default:
+			return fmt.Errorf("e-invoice %s is already %s: %w", e.DocumentNumber, e.Status, ErrInvalidState)
+		}
+		before = *e
+		receiver, err = einvoice.ReceiverTaxID(e.XML)
+		if err != nil {
+			return err
+		}
+		if e.Status == domain.EInvoiceStatusSending {
+			return nil
+		}
+		e.Status, e.ProviderReference, e.StatusMessage, e.SentAt = domain.EInvoiceStatusSending, "", "", nil
+		return s.repo.UpdateEInvoiceStatus(ctx, tx, e)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := s.provider.Send(ctx, einvoice.Submission{
+		ETTN:           e.ETTN,
+		Profile:        e.Profile,
+		DocumentNumber: e.DocumentNumber,
+		ReceiverTaxID:  receiver,
+		XML:            e.XML,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to send e-invoice %s, send it again: %w", e.DocumentNumber, err)
+	}
+
+	err = WithTransaction(ctx, s.db, func(tx pgx.Tx) error {
+		current, err := s.repo.GetEInvoiceByETTNForUpdate(ctx, tx, tenantID, e.ETTN)
+		if err != nil {
+			return err
+		}
+		if current == nil {
+			return fmt.Errorf("e-invoice %s: %w", e.ETTN, ErrNotFound)
+		}
+		e = current
+		if e.Status != domain.EInvoiceStatusSending {
+			// A concurrent send of the same ETTN already recorded the answer
+			return nil
+		}
+		e.Status, e.ProviderReference, e.StatusMessage = res.Status, res.Reference, res.Message
+		if res.Status != domain.EInvoiceStatusRejected {
+			now := time.Now()
+			e.SentAt = &now
+		}
+		if err := s.repo.UpdateEInvoiceStatus(ctx, tx, e); err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.repo, tx, domain.AuditLog{
+			TenantID: tenantID, EntityType: domain.AuditEntityEInvoice, EntityID: e.ID, Action: domain.AuditActionSend,
+		}, &before, e)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Get returns the e-invoice of an invoice, including its XML.
+func (s *EInvoiceService) Get(ctx context.Context, tenantID, invoiceID uuid.UUID) (*domain.EInvoice, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	e, err := s.repo.GetEInvoice(ctx, tenantID, invoiceID)
+	if err != nil {
+		return nil, err
+	}
+	if e == nil {
+		return nil, fmt.Errorf("no e-invoice for invoice %s: %w", invoiceID, ErrNotFound)
+	}
+	return e, nil
+}
@@ -0,0 +1,164 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"sancaksoft/internal/domain"
+	"sancaksoft/internal/einvoice"
+	"sancaksoft/internal/repository"
+	"sancaksoft/internal/service"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEInvoice_Integration(t *testing.T) {
+	if testDBURL == "" {
+		t.Skip("Skipping integration test: TEST_DB_URL not set")
+	}
+
+	ctx := context.Background()
+	db, err := pgxpool.New(ctx, testDBURL)
+	require.NoError(t, err)
+	defer db.Close()
+
+	// 1. Setup Data: a company customer registered for e-Fatura and a consumer without a tax ID
+	tenantID := uuid.New()
+	warehouseID := uuid.New()
+	productID := uuid.New()
+
+	defer func() {
+		_, _ = db.Exec(ctx, "DELETE FROM tenants WHERE id = $1", tenantID)
+	}()
+
+	_, err = db.Exec(ctx, "INSERT INTO tenants (id, name) VALUES ($1, 'E-Invoice Test Tenant')", tenantID)
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, "INSERT INTO warehouses (id, tenant_id, name) VALUES ($1, $2, 'Main')", warehouseID, tenantID)
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, "INSERT INTO products (id, tenant_id, name, sku, price, vat_rate) VALUES ($1, $2, 'Hammer', $3, 240.00, 20)", productID, tenantID, "SKU-"+uuid.New().String())
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, "INSERT INTO stock_movements (tenant_id, product_id, warehouse_id, quantity, type) VALUES ($1, $2, $3, 100, 'IN')", tenantID, productID, warehouseID)
+	require.NoError(t, err)
+
+	customerService := service.NewCustomerService(db, repository.NewCustomerRepository(db))
+	tenantService := service.NewTenantService(db, repository.NewTenantRepository(db))
+	invoiceService := service.NewInvoiceService(db, repository.NewInvoiceRepository())
+	provider := einvoice.NewMockProvider("1234567890")
+	eInvoiceService := service.NewEInvoiceService(db, repository.NewEInvoiceRepository(db), provider)
+
+	company := &domain.Customer{TenantID: tenantID, Name: "Bakery Ltd", Email: uuid.New().String() + "@bakery.test", TaxNumber: "1234567890", TaxOffice: "Kadıköy", District: "Kadıköy", City: "İstanbul"}
+	require.NoError(t, customerService.CreateCustomer(ctx, company))
+	consumer := &domain.Customer{TenantID: tenantID, Name: "Ayşe Yılmaz", Email: uuid.New().String() + "@mail.test", District: "Çankaya", City: "Ankara"}
+	require.NoError(t, customerService.CreateCustomer(ctx, consumer))
+
+	invoiceFor := func(customerID uuid.UUID) *domain.Invoice {
+		invoice, err := invoiceService.CreateInvoice(ctx, domain.CreateInvoiceRequest{
+			TenantID:    tenantID,
+			CustomerID:  customerID,
+			WarehouseID: warehouseID,
+			Items:       []domain.InvoiceItemRequest{{ProductID: productID, Quantity: 2, UnitPrice: decimal.NewFromInt(240)}},
+		})
+		require.NoError(t, err)
+		return invoice
+	}
+	companyInvoice := invoiceFor(company.ID)
+	consumerInvoice := invoiceFor(consumer.ID)
+
+	// 2. Without the company's VKN and address the document fails validation and nothing is stored
+	_, err = eInvoiceService.Generate(ctx, domain.GenerateEInvoiceRequest{TenantID: tenantID, InvoiceID: companyInvoice.ID})
+	assert.ErrorIs(t, err, service.ErrInvalidInput)
+	_, err = eInvoiceService.Get(ctx, tenantID, companyInvoice.ID)
+	assert.ErrorIs(t, err, service.ErrNotFound)
+
+	require.NoError(t, tenantService.UpdateProfile(ctx, &domain.TenantProfile{
+		ID: tenantID, Name: "Sancak Ticaret A.Ş.", Address: "Atatürk Bulvarı No: 12", District: "Çankaya", City: "Ankara",
+		TaxOffice: "Çankaya", TaxNumber: "9000068418",
+	}))
+
+	// 3. A registered customer gets an e-Fatura, a consumer an e-Arşiv invoice
+	year := einvoice.IssueYear(*companyInvoice)
+	e, err := eInvoiceService.Generate(ctx, domain.GenerateEInvoiceRequest{TenantID: tenantID, InvoiceID: companyInvoice.ID})
+	require.NoError(t, err)
+	assert.Equal(t, domain.EInvoiceProfileBasic, e.Profile)
+	assert.Equal(t, einvoice.DocumentNumber(einvoice.SeriesEInvoice, year, 1), e.DocumentNumber)
+	assert.Equal(t, domain.EInvoiceStatusDraft, e.Status)
+	assert.Contains(t, string(e.XML), strings.ToUpper(e.ETTN.String()))
+	assert.Contains(t, string(e.XML), `<cbc:TaxAmount currencyID="TRY">80.00</cbc:TaxAmount>`)
+
+	_, err = eInvoiceService.Generate(ctx, domain.GenerateEInvoiceRequest{TenantID: tenantID, InvoiceID: consumerInvoice.ID, Profile: domain.EInvoiceProfileBasic})
+	assert.ErrorIs(t, err, service.ErrInvalidInput)
+	archive, err := eInvoiceService.Generate(ctx, domain.GenerateEInvoiceRequest{TenantID: tenantID, InvoiceID: consumerInvoice.ID})
+	require.NoError(t, err)
+	assert.Equal(t, domain.EInvoiceProfileArchive, archive.Profile)
+	assert.Equal(t, einvoice.DocumentNumber(einvoice.SeriesEArchive, year, 1), archive.DocumentNumber)
+	assert.Contains(t, string(archive.XML), einvoice.ConsumerTCKN)
+
+	// 4. Regenerating a draft keeps its ETTN and, within the series, its number
+	regenerated, err := eInvoiceService.Generate(ctx, domain.GenerateEInvoiceRequest{TenantID: tenantID, InvoiceID: companyInvoice.ID, Profile: domain.EInvoiceProfileCommercial})
+	require.NoError(t, err)
+	assert.Equal(t, e.ETTN, regenerated.ETTN)
+	assert.Equal(t, e.DocumentNumber, regenerated.DocumentNumber)
+	assert.Equal(t, domain.EInvoiceProfileCommercial, regenerated.Profile)
+
+	// 5. Sending hands the stored XML to the provider; sent documents are final
+	sent, err := eInvoiceService.Send(ctx, tenantID, companyInvoice.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.EInvoiceStatusSent, sent.Status)
+	assert.NotEmpty(t, sent.ProviderReference)
+	assert.NotNil(t, sent.SentAt)
+	require.Len(t, provider.Submissions(), 1)
+	assert.Equal(t, "1234567890", provider.Submissions()[0].ReceiverTaxID)
+
+	_, err = eInvoiceService.Send(ctx, tenantID, companyInvoice.ID)
+	assert.ErrorIs(t, err, service.ErrInvalidState)
+	_, err = eInvoiceService.Generate(ctx, domain.GenerateEInvoiceRequest{TenantID: tenantID, InvoiceID: companyInvoice.ID})
+	assert.ErrorIs(t, err, service.ErrInvalidState)
+
+	// 6. If the integrator's answer is lost the document stays SENDING; sending it again
+	// resubmits the same ETTN and records the integrator's first answer
+	lossyService := service.NewEInvoiceService(db, repository.NewEInvoiceRepository(db), &lostAnswerProvider{provider})
+	_, err = lossyService.Send(ctx, tenantID, consumerInvoice.ID)
+	require.Error(t, err)
+	pending, err := eInvoiceService.Get(ctx, tenantID, consumerInvoice.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.EInvoiceStatusSending, pending.Status)
+	_, err = eInvoiceService.Generate(ctx, domain.GenerateEInvoiceRequest{TenantID: tenantID, InvoiceID: consumerInvoice.ID})
+	assert.ErrorIs(t, err, service.ErrInvalidState)
+
+	archive, err = eInvoiceService.Send(ctx, tenantID, consumerInvoice.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.EInvoiceStatusAccepted, archive.Status)
+	submissions := provider.Submissions()
+	require.Len(t, submissions, 3)
+	assert.Equal(t, archive.ETTN, submissions[1].ETTN)
+	assert.Equal(t, archive.ETTN, submissions[2].ETTN)
+
+	stored, err := eInvoiceService.Get(ctx, tenantID, consumerInvoice.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.EInvoiceStatusAccepted, stored.Status)
+	assert.NoError(t, einvoice.Validate(stored.XML))
+
+	// 7. Unknown invoices
+	_, err = eInvoiceService.Generate(ctx, domain.GenerateEInvoiceRequest{TenantID: tenantID, InvoiceID: uuid.New()})
+	assert.ErrorIs(t, err, service.ErrNotFound)
+	_, err = eInvoiceService.Send(ctx, tenantID, uuid.New())
+	assert.ErrorIs(t, err, service.ErrNotFound)
+}
+
+// lostAnswerProvider delivers every submission but fails as if the answer never arrived.
+type lostAnswerProvider struct {
+	*einvoice.MockProvider
+}
+
+func (p *lostAnswerProvider) Send(ctx context.Context, s einvoice.Submission) (einvoice.Result, error) {
+	if _, err := p.MockProvider.Send(ctx, s); err != nil {
+		return einvoice.Result{}, err
+	}
+	return einvoice.Result{}, errors.New("integrator timed out")
+}
@@ -0,0 +1,12 @@
+package service
+
+import "errors"
+
+// Sentinel errors wrapped by services so handlers can map them to HTTP status codes
+// without matching on error strings.
+var (
+	ErrNotFound     = errors.New("not found")
+	ErrInvalidInput = errors.New("invalid input")
+	ErrInvalidState = errors.New("invalid state")
+	ErrForbidden    = errors.New("forbidden")
+)
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"sancaksoft/internal/domain"
+	"sancaksoft/internal/repository"
+)
+
+// Exports stream for as long as the client keeps reading; the limit only stops runaway ones.
+const exportTimeout = 10 * time.Minute
+
+// ExportService prepares spreadsheet exports. Each method checks its filter and returns a
+// function that streams the rows, so callers can reject a bad request before they start
+// writing a response.
+type ExportService struct {
+	repo      *repository.ExportRepository
+	customers *repository.CustomerRepository
+}
+
+func NewExportService(repo *repository.ExportRepository, customers *repository.CustomerRepository) *ExportService {
+	return &ExportService{repo: repo, customers: customers}
+}
+
+// InvoiceLines exports the lines of the invoices the invoice list would show for the same
+// filter, without its limit. Lines are split into base and VAT as on the printed invoice.
+func (s *ExportService) InvoiceLines(f domain.InvoiceFilter) (func(ctx context.Context, fn func(domain.InvoiceExportLine) error) error, error) {
+	if err := validateInvoiceFilter(f); err != nil {
+		return nil, err
+	}
+	return func(ctx context.Context, fn func(domain.InvoiceExportLine) error) error {
+		ctx, cancel := context.WithTimeout(ctx, exportTimeout)
+		defer cancel()
+
+		return s.repo.EachInvoiceLine(ctx, f, func(l domain.InvoiceExportLine) error {
+			l.LineBase, l.LineVAT = splitVAT(l.LineTotal, l.VATRate)
+			return fn(l)
+		})
+	}, nil
+}
+
+// StockMovements exports the movements the movement list would show for the same filter,
+// without its limit.
+func (s *ExportService) StockMovements(f domain.StockMovementFilter) (func(ctx context.Context, fn func(domain.StockMovementExportLine) error) error, error) {
+	if err := validateStockMovementFilter(f); err != nil {
+		return nil, err
+	}
+	return func(ctx context.Context, fn func(domain.StockMovementExportLine) error) error {
+		ctx, cancel := context.WithTimeout(ctx, exportTimeout)
+		defer cancel()
+
+		return s.repo.EachStockMovement(ctx, f, fn)
+	}, nil
+}
+
+// WarehouseStock exports current on-hand quantities per warehouse.
+func (s *ExportService) WarehouseStock(f domain.WarehouseStockFilter) (func(ctx context.Context, fn func(domain.WarehouseStock) error) error, error) {
+	return func(ctx context.Context, fn func(domain.WarehouseStock) error) error {
+		ctx, cancel := context.WithTimeout(ctx, exportTimeout)
+		defer cancel()
+
+		return s.repo.EachWarehouseStock(ctx, f, fn)
+	}, nil
+}
+
+// CustomerLedger exports the movements of one or all customers with running balances, the
+// lines of their account statements side by side.
+func (s *ExportService) CustomerLedger(ctx context.Context, f domain.CustomerLedgerFilter) (func(ctx context.Context, fn func(domain.CustomerLedgerLine) error) error, error) {
+	if f.From != nil && f.To != nil && !f.From.Before(*f.To) {
+		return nil, fmt.Errorf("from must be before to: %w", ErrInvalidInput)
+	}
+	if f.CustomerID != nil {
+		ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+
+		customer, err := s.customers.GetCustomerByID(ctx, f.TenantID, *f.CustomerID)
+		if err != nil {
+			return nil, err
+		}
+		if customer == nil {
+			return nil, fmt.Errorf("customer %s: %w", *f.CustomerID, ErrNotFound)
+		}
+	}
+	return func(ctx context.Context, fn func(domain.CustomerLedgerLine) error) error {
+		ctx, cancel := context.WithTimeout(ctx, exportTimeout)
+		defer cancel()
+
+		return s.repo.EachCustomerLedgerLine(ctx, f, fn)
+	}, nil
+}
@@ -0,0 +1,174 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"sancaksoft/internal/domain"
+	"sancaksoft/internal/repository"
+	"sancaksoft/internal/service"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExport_Integration(t *testing.T) {
+	if testDBURL == "" {
+		t.Skip("Skipping integration test: TEST_DB_URL not set")
+	}
+
+	ctx := context.Background()
+	db, err := pgxpool.New(ctx, testDBURL)
+	require.NoError(t, err)
+	defer db.Close()
+
+	// 1. Setup Data: two warehouses, two customers with invoices, a payment and stock
+	tenantID := uuid.New()
+	mainID, branchID := uuid.New(), uuid.New()
+	alphaID, betaID := uuid.New(), uuid.New()
+	productID := uuid.New()
+
+	defer func() {
+		_, _ = db.Exec(ctx, "DELETE FROM tenants WHERE id = $1", tenantID)
+	}()
+
+	_, err = db.Exec(ctx, "INSERT INTO tenants (id, name) VALUES ($1, 'Export Test Tenant')", tenantID)
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, "INSERT INTO warehouses (id, tenant_id, name) VALUES ($1, $3, 'Merkez'), ($2, $3, 'Şube')", mainID, branchID, tenantID)
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, "INSERT INTO customers (id, tenant_id, name, tax_number) VALUES ($1, $3, 'Alfa Ltd', '1234567890'), ($2, $3, 'Beta AŞ', NULL)",
+		alphaID, betaID, tenantID)
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, "INSERT INTO products (id, tenant_id, name, sku, price, vat_rate) VALUES ($1, $2, 'Çay 1 kg', 'CAY-1', 120, 20)", productID, tenantID)
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, `INSERT INTO stock_movements (tenant_id, product_id, warehouse_id, quantity, type, unit_cost, created_at) VALUES
+		($1, $2, $3, 50, 'IN', 80, '2026-01-02 09:00'),
+		($1, $2, $4, 10, 'IN', 82, '2026-01-03 09:00'),
+		($1, $2, $3, -5, 'OUT', NULL, '2026-02-01 09:00')`, tenantID, productID, mainID, branchID)
+	require.NoError(t, err)
+
+	var invoice1, invoice2, invoice3 uuid.UUID
+	insertInvoice := func(id *uuid.UUID, customerID, warehouseID uuid.UUID, number string, total int, createdAt string) {
+		require.NoError(t, db.QueryRow(ctx, `INSERT INTO invoices (tenant_id, warehouse_id, customer_id, invoice_number, total_amount, due_date, created_at)
+			VALUES ($1, $2, $3, $4, $5, ($6::timestamp + INTERVAL '30 days')::date, $6) RETURNING id`,
+			tenantID, warehouseID, customerID, number, total, createdAt).Scan(id))
+	}
+	insertInvoice(&invoice1, alphaID, mainID, "EX-1", 360, "2026-01-10 10:00")
+	insertInvoice(&invoice2, betaID, branchID, "EX-2", 120, "2026-01-20 10:00")
+	insertInvoice(&invoice3, alphaID, mainID, "EX-3", 240, "2026-02-05 10:00")
+	_, err = db.Exec(ctx, `INSERT INTO invoice_items (tenant_id, invoice_id, product_id, quantity, unit_price, total, vat_rate, created_at) VALUES
+		($1, $2, $5, 2, 120, 240, 20, '2026-01-10 10:00'),
+		($1, $2, $5, 1, 120, 120, 20, '2026-01-10 10:01'),
+		($1, $3, $5, 1, 120, 120, NULL, '2026-01-20 10:00'),
+		($1, $4, $5, 2, 120, 240, 20, '2026-02-05 10:00')`, tenantID, invoice1, invoice2, invoice3, productID)
+	require.NoError(t, err)
+
+	customerService := service.NewCustomerService(db, repository.NewCustomerRepository(db))
+	require.NoError(t, customerService.RecordPayment(ctx, &domain.CustomerPayment{
+		TenantID: tenantID, CustomerID: alphaID, Amount: decimal.NewFromInt(300), Method: domain.PaymentMethodCash,
+		PaymentDate: time.Date(2026, 1, 25, 0, 0, 0, 0, time.UTC), Reference: "MKB-1",
+	}))
+
+	exportService := service.NewExportService(repository.NewExportRepository(db), repository.NewCustomerRepository(db))
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	// 2. Invoice lines honour the list filters and carry the VAT split of the printed invoice
+	invoices, err := exportService.InvoiceLines(domain.InvoiceFilter{TenantID: tenantID, From: &from, To: &to})
+	require.NoError(t, err)
+	var lines []domain.InvoiceExportLine
+	require.NoError(t, invoices(ctx, func(l domain.InvoiceExportLine) error {
+		lines = append(lines, l)
+		return nil
+	}))
+	require.Len(t, lines, 3)
+	assert.Equal(t, []string{"EX-1", "EX-1", "EX-2"}, []string{lines[0].InvoiceNumber, lines[1].InvoiceNumber, lines[2].InvoiceNumber})
+	assert.Equal(t, "1234567890", lines[0].CustomerTaxNumber)
+	assert.Equal(t, "Merkez", lines[0].WarehouseName)
+	assert.Equal(t, 2, lines[0].Quantity)
+	assert.True(t, lines[0].LineBase.Equal(decimal.NewFromInt(200)))
+	assert.True(t, lines[0].LineVAT.Equal(decimal.NewFromInt(40)))
+	assert.True(t, lines[0].InvoiceTotal.Equal(decimal.NewFromInt(360)))
+	assert.True(t, lines[2].VATRate.Equal(decimal.NewFromInt(20)), "product rate for lines without one")
+
+	list, err := service.NewInvoiceListService(repository.NewInvoiceListRepository(db)).ListInvoices(ctx,
+		domain.InvoiceFilter{TenantID: tenantID, CustomerID: &alphaID})
+	require.NoError(t, err)
+	assert.Len(t, list, 2)
+
+	// 3. Stock movements filtered by warehouse and type; current stock per warehouse
+	movements, err := exportService.StockMovements(domain.StockMovementFilter{TenantID: tenantID, WarehouseID: &mainID, Type: domain.StockMovementTypeIn})
+	require.NoError(t, err)
+	var moved []domain.StockMovementExportLine
+	require.NoError(t, movements(ctx, func(m domain.StockMovementExportLine) error {
+		moved = append(moved, m)
+		return nil
+	}))
+	require.Len(t, moved, 1)
+	assert.Equal(t, "CAY-1", moved[0].SKU)
+	assert.Equal(t, "Merkez", moved[0].WarehouseName)
+	assert.True(t, moved[0].UnitCost.Equal(decimal.NewFromInt(80)))
+
+	stock, err := exportService.WarehouseStock(domain.WarehouseStockFilter{TenantID: tenantID})
+	require.NoError(t, err)
+	var levels []domain.WarehouseStock
+	require.NoError(t, stock(ctx, func(s domain.WarehouseStock) error {
+		levels = append(levels, s)
+		return nil
+	}))
+	require.Len(t, levels, 2)
+	assert.Equal(t, "Merkez", levels[0].WarehouseName)
+	assert.Equal(t, 45, levels[0].Quantity)
+	assert.Equal(t, 10, levels[1].Quantity)
+
+	// 4. Ledger of all customers: February only, balances include January
+	feb := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	ledger, err := exportService.CustomerLedger(ctx, domain.CustomerLedgerFilter{TenantID: tenantID, From: &to, To: &feb})
+	require.NoError(t, err)
+	var entries []domain.CustomerLedgerLine
+	require.NoError(t, ledger(ctx, func(l domain.CustomerLedgerLine) error {
+		entries = append(entries, l)
+		return nil
+	}))
+	require.Len(t, entries, 1)
+	assert.Equal(t, "Alfa Ltd", entries[0].CustomerName)
+	assert.Equal(t, "EX-3", entries[0].DocumentNumber)
+	assert.True(t, entries[0].Balance.Equal(decimal.NewFromInt(300)), "360 - 300 + 240")
+
+	ledger, err = exportService.CustomerLedger(ctx, domain.CustomerLedgerFilter{TenantID: tenantID})
+	require.NoError(t, err)
+	entries = nil
+	require.NoError(t, ledger(ctx, func(l domain.CustomerLedgerLine) error {
+		entries = append(entries, l)
+		return nil
+	}))
+	require.Len(t, entries, 4)
+	assert.Equal(t, []string{"EX-1", "MKB-1", "EX-3", "EX-2"},
+		[]string{entries[0].DocumentNumber, entries[1].DocumentNumber, entries[2].DocumentNumber, entries[3].DocumentNumber})
+	assert.True(t, entries[1].Credit.Equal(decimal.NewFromInt(300)))
+	assert.True(t, entries[3].Balance.Equal(decimal.NewFromInt(120)))
+
+	// 5. The first error from the row callback stops the export
+	stop := errors.New("client went away")
+	rows := 0
+	err = ledger(ctx, func(domain.CustomerLedgerLine) error {
+		rows++
+		return stop
+	})
+	assert.ErrorIs(t, err, stop)
+	assert.Equal(t, 1, rows)
+
+	// 6. Bad filters are rejected before anything is streamed
+	_, err = exportService.InvoiceLines(domain.InvoiceFilter{TenantID: tenantID, From: &to, To: &from})
+	assert.ErrorIs(t, err, service.ErrInvalidInput)
+	_, err = exportService.StockMovements(domain.StockMovementFilter{TenantID: tenantID, Type: "LOST"})
+	assert.ErrorIs(t, err, service.ErrInvalidInput)
+	unknown := uuid.New()
+	_, err = exportService.CustomerLedger(ctx, domain.CustomerLedgerFilter{TenantID: tenantID, CustomerID: &unknown})
+	assert.ErrorIs(t, err, service.ErrNotFound)
+}
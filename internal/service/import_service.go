@@ -0,0 +1,458 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+
+	"sancaksoft/internal/domain"
+	"sancaksoft/internal/repository"
+	"sancaksoft/internal/spreadsheet"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shopspring/decimal"
+)
+
+type ImportService struct {
+	db   *pgxpool.Pool
+	repo *repository.ImportRepository
+}
+
+func NewImportService(db *pgxpool.Pool, repo *repository.ImportRepository) *ImportService {
+	return &ImportService{db: db, repo: repo}
+}
+
+// importColumns are the required and optional columns of each import kind.
+var importColumns = map[domain.ImportKind]struct {
+	required []string
+	optional []string
+}{
+	domain.ImportKindProducts:     {[]string{"sku", "name", "vat_rate"}, []string{"barcode", "unit", "price", "standard_cost"}},
+	domain.ImportKindCustomers:    {[]string{"name", "email"}, []string{"phone", "address", "district", "city", "tax_number", "tax_office", "credit_limit", "payment_term_days"}},
+	domain.ImportKindOpeningStock: {[]string{"sku", "warehouse", "quantity"}, []string{"unit_cost"}},
+}
+
+// KDV rates in use now or for documents still being migrated (8% and 18% until July 2023)
+var importVATRates = []decimal.Decimal{
+	decimal.NewFromInt(0), decimal.NewFromInt(1), decimal.NewFromInt(8),
+	decimal.NewFromInt(10), decimal.NewFromInt(18), decimal.NewFromInt(20),
+}
+
+// importRecord is a validated row and how to write it.
+type importRecord struct {
+	row   int
+	write func(ctx context.Context, tx pgx.Tx) error
+}
+
+// Import validates every row of a CSV or XLSX file and, unless it is a dry run or any row
+// has an error, writes them. Problems with the file itself (unreadable, missing columns)
+// are returned as errors; problems with rows are listed in the report.
+func (s *ImportService) Import(ctx context.Context, req domain.ImportRequest) (*domain.ImportReport, error) {
+	// Large files take longer than other writes; chunked imports can be resumed if they time out
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+	defer cancel()
+
+	columns, ok := importColumns[req.Kind]
+	if !ok {
+		return nil, fmt.Errorf("unknown import kind %s: %w", req.Kind, ErrInvalidInput)
+	}
+	if req.ChunkSize < 0 || req.StartRow < 0 {
+		return nil, fmt.Errorf("chunk size and start row cannot be negative: %w", ErrInvalidInput)
+	}
+
+	rows, err := spreadsheet.Read(req.FileName, req.Data)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", err, ErrInvalidInput)
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("file is empty: %w", ErrInvalidInput)
+	}
+	cols, err := importHeader(rows[0], columns.required, columns.optional)
+	if err != nil {
+		return nil, err
+	}
+	var data []spreadsheet.Row
+	for _, row := range rows[1:] {
+		if row.Number >= req.StartRow {
+			data = append(data, row)
+		}
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("file has no rows to import: %w", ErrInvalidInput)
+	}
+
+	report := &domain.ImportReport{Kind: req.Kind, DryRun: req.DryRun, TotalRows: len(data), Errors: []domain.ImportRowError{}}
+	var records []importRecord
+	switch req.Kind {
+	case domain.ImportKindProducts:
+		records, err = s.parseProducts(ctx, req.TenantID, data, cols, report)
+	case domain.ImportKindCustomers:
+		records, err = s.parseCustomers(ctx, req.TenantID, data, cols, report)
+	case domain.ImportKindOpeningStock:
+		records, err = s.parseOpeningStock(ctx, req.TenantID, data, cols, report)
+	}
+	if err != nil {
+		return nil, err
+	}
+	report.ValidRows = len(records)
+	if req.DryRun || len(report.Errors) > 0 {
+		return report, nil
+	}
+
+	chunk := req.ChunkSize
+	if chunk == 0 {
+		chunk = len(records)
+	}
+	for start := 0; start < len(records); start += chunk {
+		batch := records[start:min(start+chunk, len(records))]
+		err := WithTransaction(ctx, s.db, func(tx pgx.Tx) error {
+			for _, r := range batch {
+				if err := r.write(ctx, tx); err != nil {
+					return fmt.Errorf("row %d: %w", r.row, err)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			if req.ChunkSize == 0 {
+				return nil, err
+			}
+			// Earlier chunks stay imported; the failed chunk was rolled back
+			report.NextRow = batch[0].row
+			report.Errors = append(report.Errors, domain.ImportRowError{
+				Row:     batch[0].row,
+				Message: fmt.Sprintf("import stopped, resume from row %d: %v", batch[0].row, err),
+			})
+			return report, nil
+		}
+		report.Imported += len(batch)
+	}
+	return report, nil
+}
+
+// auditImport records the creation of an imported record, marked as coming from an import.
+func (s *ImportService) auditImport(ctx context.Context, tx pgx.Tx, entityType string, tenantID, entityID uuid.UUID, record any) error {
+	return recordAudit(ctx, s.repo, tx, domain.AuditLog{
+		TenantID: tenantID, EntityType: entityType, EntityID: entityID, Action: domain.AuditActionCreate,
+		Details: map[string]any{"source": "import"},
+	}, nil, record)
+}
+
+// importHeader maps column names to their index. Names are matched case-insensitively,
+// with spaces and hyphens read as underscores; unknown columns are ignored.
+func importHeader(header spreadsheet.Row, required, optional []string) (map[string]int, error) {
+	known := map[string]bool{}
+	for _, name := range append(append([]string{}, required...), optional...) {
+		known[name] = true
+	}
+	cols := map[string]int{}
+	for i := range header.Cells {
+		name := strings.ToLower(header.Cell(i))
+		name = strings.NewReplacer(" ", "_", "-", "_").Replace(name)
+		if !known[name] {
+			continue
+		}
+		if _, dup := cols[name]; dup {
+			return nil, fmt.Errorf("column %s appears more than once: %w", name, ErrInvalidInput)
+		}
+		cols[name] = i
+	}
+	var missing []string
+	for _, name := range required {
+		if _, ok := cols[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("missing required columns: %s: %w", strings.Join(missing, ", "), ErrInvalidInput)
+	}
+	return cols, nil
+}
+
+// importRow reads the cells of one row and collects its errors in the report.
+type importRow struct {
+	spreadsheet.Row
+	cols   map[string]int
+	report *domain.ImportReport
+	failed bool
+}
+
+func (r *importRow) text(col string) string {
+	i, ok := r.cols[col]
+	if !ok {
+		return ""
+	}
+	return r.Cell(i)
+}
+
+func (r *importRow) fail(col, format string, args ...any) {
+	r.failed = true
+	r.report.Errors = append(r.report.Errors, domain.ImportRowError{Row: r.Number, Column: col, Message: fmt.Sprintf(format, args...)})
+}
+
+// required returns the cell, or records an error if it is empty or longer than maxLen.
+func (r *importRow) required(col string, maxLen int) string {
+	v := r.text(col)
+	if v == "" {
+		r.fail(col, "%s is required", col)
+	}
+	r.checkLength(col, v, maxLen)
+	return v
+}
+
+func (r *importRow) checkLength(col, v string, maxLen int) {
+	if n := len([]rune(v)); n > maxLen {
+		r.fail(col, "%s is %d characters long; at most %d are allowed", col, n, maxLen)
+	}
+}
+
+// amount parses a non-negative amount with at most places decimals; nil if the cell is empty.
+func (r *importRow) amount(col string, places int32) *decimal.Decimal {
+	v := r.text(col)
+	if v == "" {
+		return nil
+	}
+	d, err := parseImportDecimal(v)
+	switch {
+	case err != nil:
+		r.fail(col, "%q is not a number", v)
+		return nil
+	case d.IsNegative():
+		r.fail(col, "%s cannot be negative", col)
+		return nil
+	case d.Exponent() < -places && !d.Equal(d.Round(places)):
+		r.fail(col, "%s can have at most %d decimals", col, places)
+		return nil
+	}
+	return &d
+}
+
+// count parses a whole number no less than least; ok is false if the cell is empty or invalid.
+func (r *importRow) count(col string, least int) (int, bool) {
+	v := r.text(col)
+	if v == "" {
+		return 0, false
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		r.fail(col, "%q is not a whole number", v)
+		return 0, false
+	}
+	if n < least {
+		r.fail(col, "%s must be at least %d", col, least)
+		return 0, false
+	}
+	return n, true
+}
+
+// parseImportDecimal reads numbers as typed in Turkish or English spreadsheets: the last
+// of "," and "." is the decimal separator, and the other groups thousands.
+func parseImportDecimal(s string) (decimal.Decimal, error) {
+	s = strings.ReplaceAll(s, " ", "")
+	comma, dot := strings.LastIndex(s, ","), strings.LastIndex(s, ".")
+	switch {
+	case comma >= 0 && dot >= 0 && comma > dot:
+		s = strings.ReplaceAll(strings.ReplaceAll(s, ".", ""), ",", ".")
+	case comma >= 0 && dot >= 0:
+		s = strings.ReplaceAll(s, ",", "")
+	case strings.Count(s, ",") == 1:
+		s = strings.Replace(s, ",", ".", 1)
+	case strings.Count(s, ",") > 1:
+		s = strings.ReplaceAll(s, ",", "")
+	case strings.Count(s, ".") > 1:
+		s = strings.ReplaceAll(s, ".", "")
+	}
+	return decimal.NewFromString(s)
+}
+
+func (s *ImportService) parseProducts(ctx context.Context, tenantID uuid.UUID, data []spreadsheet.Row, cols map[string]int, report *domain.ImportReport) ([]importRecord, error) {
+	existing, err := s.repo.ListProducts(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	seen := map[string]int{}
+
+	var records []importRecord
+	for _, row := range data {
+		r := &importRow{Row: row, cols: cols, report: report}
+		p := &domain.Product{ID: uuid.New(), TenantID: tenantID, Unit: domain.ProductUnitPiece}
+
+		p.SKU = r.required("sku", 100)
+		if p.SKU != "" {
+			if first, dup := seen[p.SKU]; dup {
+				r.fail("sku", "SKU %s is also on row %d", p.SKU, first)
+			} else if _, taken := existing[p.SKU]; taken {
+				r.fail("sku", "SKU %s already exists", p.SKU)
+			} else {
+				seen[p.SKU] = row.Number
+			}
+		}
+		p.Name = r.required("name", 255)
+		p.Barcode = r.text("barcode")
+		r.checkLength("barcode", p.Barcode, 100)
+		if unit := strings.ToLower(r.text("unit")); unit != "" {
+			p.Unit = domain.ProductUnit(unit)
+			if p.Unit != domain.ProductUnitPiece && p.Unit != domain.ProductUnitKg {
+				r.fail("unit", "unit must be 'adet' or 'kg'")
+			}
+		}
+		if price := r.amount("price", 2); price != nil {
+			p.Price = *price
+		}
+		if rate := r.amount("vat_rate", 2); rate != nil {
+			p.VATRate = *rate
+			valid := false
+			for _, v := range importVATRates {
+				valid = valid || v.Equal(*rate)
+			}
+			if !valid {
+				r.fail("vat_rate", "VAT rate %s%% is not a KDV rate (0, 1, 8, 10, 18 or 20)", rate)
+			}
+		} else if r.text("vat_rate") == "" {
+			r.fail("vat_rate", "vat_rate is required")
+		}
+		p.StandardCost = r.amount("standard_cost", 4)
+
+		if !r.failed {
+			records = append(records, importRecord{row: row.Number, write: func(ctx context.Context, tx pgx.Tx) error {
+				if err := s.repo.CreateProduct(ctx, tx, p); err != nil {
+					return err
+				}
+				return s.auditImport(ctx, tx, domain.AuditEntityProduct, p.TenantID, p.ID, p)
+			}})
+		}
+	}
+	return records, nil
+}
+
+func (s *ImportService) parseCustomers(ctx context.Context, tenantID uuid.UUID, data []spreadsheet.Row, cols map[string]int, report *domain.ImportReport) ([]importRecord, error) {
+	existing, err := s.repo.ListCustomerEmails(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	seen := map[string]int{}
+
+	var records []importRecord
+	for _, row := range data {
+		r := &importRow{Row: row, cols: cols, report: report}
+		c := &domain.Customer{ID: uuid.New(), TenantID: tenantID}
+
+		c.Name = r.required("name", 255)
+		// Customers are told apart by email
+		c.Email = r.required("email", 255)
+		if c.Email != "" {
+			key := strings.ToLower(c.Email)
+			if addr, err := mail.ParseAddress(c.Email); err != nil || addr.Address != c.Email {
+				r.fail("email", "%q is not an email address", c.Email)
+			} else if first, dup := seen[key]; dup {
+				r.fail("email", "email %s is also on row %d", c.Email, first)
+			} else if existing[key] {
+				r.fail("email", "a customer with email %s already exists", c.Email)
+			} else {
+				seen[key] = row.Number
+			}
+		}
+		c.Phone = r.text("phone")
+		r.checkLength("phone", c.Phone, 50)
+		c.Address = r.text("address")
+		c.District = r.text("district")
+		r.checkLength("district", c.District, 100)
+		c.City = r.text("city")
+		r.checkLength("city", c.City, 100)
+		c.TaxNumber = r.text("tax_number")
+		if c.TaxNumber != "" && !validTaxNumber(c.TaxNumber) {
+			r.fail("tax_number", "tax number must be a 10-digit VKN or an 11-digit TCKN")
+		}
+		c.TaxOffice = r.text("tax_office")
+		r.checkLength("tax_office", c.TaxOffice, 100)
+		c.CreditLimit = r.amount("credit_limit", 2)
+		c.PaymentTermDays, _ = r.count("payment_term_days", 0)
+
+		if !r.failed {
+			records = append(records, importRecord{row: row.Number, write: func(ctx context.Context, tx pgx.Tx) error {
+				if err := s.repo.CreateCustomer(ctx, tx, c); err != nil {
+					return err
+				}
+				return s.auditImport(ctx, tx, domain.AuditEntityCustomer, c.TenantID, c.ID, c)
+			}})
+		}
+	}
+	return records, nil
+}
+
+func (s *ImportService) parseOpeningStock(ctx context.Context, tenantID uuid.UUID, data []spreadsheet.Row, cols map[string]int, report *domain.ImportReport) ([]importRecord, error) {
+	products, err := s.repo.ListProducts(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	warehouses, err := s.repo.ListWarehouses(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	stocked, err := s.repo.ListStockedPairs(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	seen := map[[2]uuid.UUID]int{}
+
+	var records []importRecord
+	for _, row := range data {
+		r := &importRow{Row: row, cols: cols, report: report}
+		m := &domain.StockMovement{ID: uuid.New(), TenantID: tenantID, Type: domain.StockMovementTypeIn}
+
+		var product repository.ImportProduct
+		if sku := r.required("sku", 100); sku != "" {
+			var ok bool
+			product, ok = products[sku]
+			switch {
+			case !ok || product.ID == uuid.Nil:
+				r.fail("sku", "product %s not found", sku)
+			case product.HasVariants:
+				r.fail("sku", "product %s has variants; stock is kept on the variants", sku)
+			case product.IsKit:
+				r.fail("sku", "product %s is a kit; stock is kept on its components", sku)
+			case product.TrackLots || product.TrackSerials:
+				r.fail("sku", "product %s tracks lots or serial numbers; receive it with a goods receipt or stock movement", sku)
+			}
+		}
+		var warehouse repository.ImportWarehouse
+		if name := r.required("warehouse", 255); name != "" {
+			var ok bool
+			if warehouse, ok = warehouses[strings.ToLower(name)]; !ok {
+				r.fail("warehouse", "warehouse %q not found", name)
+			}
+		}
+		if product.ID != uuid.Nil && warehouse.ID != uuid.Nil {
+			pair := [2]uuid.UUID{product.ID, warehouse.ID}
+			if first, dup := seen[pair]; dup {
+				r.fail("sku", "%s in %s is also on row %d", product.SKU, warehouse.Name, first)
+			} else if stocked[pair] {
+				r.fail("sku", "%s already has stock movements in %s", product.SKU, warehouse.Name)
+			} else {
+				seen[pair] = row.Number
+			}
+		}
+		if qty, ok := r.count("quantity", 1); ok {
+			m.Quantity = qty
+		} else if r.text("quantity") == "" {
+			r.fail("quantity", "quantity is required")
+		}
+		m.UnitCost = r.amount("unit_cost", 4)
+
+		if !r.failed {
+			m.ProductID, m.WarehouseID = product.ID, warehouse.ID
+			records = append(records, importRecord{row: row.Number, write: func(ctx context.Context, tx pgx.Tx) error {
+				if err := s.repo.CreateOpeningBalance(ctx, tx, m); err != nil {
+					return err
+				}
+				return s.auditImport(ctx, tx, domain.AuditEntityStockMovement, m.TenantID, m.ID, m)
+			}})
+		}
+	}
+	return records, nil
+}
@@ -0,0 +1,145 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"sancaksoft/internal/domain"
+	"sancaksoft/internal/repository"
+	"sancaksoft/internal/service"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImport_Integration(t *testing.T) {
+	if testDBURL == "" {
+		t.Skip("Skipping integration test: TEST_DB_URL not set")
+	}
+
+	ctx := context.Background()
+	db, err := pgxpool.New(ctx, testDBURL)
+	require.NoError(t, err)
+	defer db.Close()
+
+	// 1. Setup Data: a warehouse and a product that already exists
+	tenantID := uuid.New()
+	warehouseID := uuid.New()
+
+	defer func() {
+		_, _ = db.Exec(ctx, "DELETE FROM tenants WHERE id = $1", tenantID)
+	}()
+
+	_, err = db.Exec(ctx, "INSERT INTO tenants (id, name) VALUES ($1, 'Import Test Tenant')", tenantID)
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, "INSERT INTO warehouses (id, tenant_id, name) VALUES ($1, $2, 'Ana Depo')", warehouseID, tenantID)
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, "INSERT INTO products (tenant_id, name, sku, price, vat_rate) VALUES ($1, 'Existing', 'EXIST', 10, 20)", tenantID)
+	require.NoError(t, err)
+
+	importService := service.NewImportService(db, repository.NewImportRepository(db))
+	run := func(kind domain.ImportKind, file string, dryRun bool, chunkSize, startRow int) (*domain.ImportReport, error) {
+		return importService.Import(ctx, domain.ImportRequest{
+			TenantID: tenantID, Kind: kind, FileName: "import.csv", Data: []byte(file),
+			DryRun: dryRun, ChunkSize: chunkSize, StartRow: startRow,
+		})
+	}
+	count := func(query string) int {
+		var n int
+		require.NoError(t, db.QueryRow(ctx, query, tenantID).Scan(&n))
+		return n
+	}
+
+	// 2. Every row is checked and reported; nothing is written while any row fails
+	products := "SKU;Name;Unit;Price;VAT Rate\n" +
+		"CAY-1;Çay 1 kg;kg;12,50;1\n" +
+		"CAY-1;Çay tekrar;adet;10;1\n" +
+		"EXIST;Existing again;adet;10;20\n" +
+		"KAHVE;;litre;1.234,5;19\n" +
+		"SEKER;Şeker;;-3;\n"
+	report, err := run(domain.ImportKindProducts, products, true, 0, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 5, report.TotalRows)
+	assert.Equal(t, 1, report.ValidRows)
+	assert.Equal(t, []domain.ImportRowError{
+		{Row: 3, Column: "sku", Message: "SKU CAY-1 is also on row 2"},
+		{Row: 4, Column: "sku", Message: "SKU EXIST already exists"},
+		{Row: 5, Column: "name", Message: "name is required"},
+		{Row: 5, Column: "unit", Message: "unit must be 'adet' or 'kg'"},
+		{Row: 5, Column: "vat_rate", Message: "VAT rate 19% is not a KDV rate (0, 1, 8, 10, 18 or 20)"},
+		{Row: 6, Column: "price", Message: "price cannot be negative"},
+		{Row: 6, Column: "vat_rate", Message: "vat_rate is required"},
+	}, report.Errors)
+
+	report, err = run(domain.ImportKindProducts, products, false, 0, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 0, report.Imported)
+	assert.Equal(t, 1, count("SELECT COUNT(*) FROM products WHERE tenant_id = $1"))
+
+	_, err = run(domain.ImportKindProducts, "sku,price\nA,1\n", false, 0, 0)
+	assert.ErrorIs(t, err, service.ErrInvalidInput)
+	assert.ErrorContains(t, err, "missing required columns: name, vat_rate")
+
+	// 3. A clean file is written in one transaction
+	report, err = run(domain.ImportKindProducts, "sku;name;unit;price;vat_rate;barcode\nCAY-1;Çay 1 kg;kg;12,50;1;869000\nKAHVE;Kahve;;1.234,5;20;\nSEKER;Şeker;adet;30;10;\n", false, 0, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 3, report.Imported)
+	var price, vat decimal.Decimal
+	var unit string
+	require.NoError(t, db.QueryRow(ctx, "SELECT price, vat_rate, unit FROM products WHERE tenant_id = $1 AND sku = 'CAY-1'", tenantID).Scan(&price, &vat, &unit))
+	assert.True(t, price.Equal(decimal.RequireFromString("12.50")))
+	assert.True(t, vat.Equal(decimal.NewFromInt(1)))
+	assert.Equal(t, "kg", unit)
+	require.NoError(t, db.QueryRow(ctx, "SELECT price FROM products WHERE tenant_id = $1 AND sku = 'KAHVE'", tenantID).Scan(&price))
+	assert.True(t, price.Equal(decimal.RequireFromString("1234.5")))
+
+	// 4. Customers: emails are unique, tax numbers checked
+	email := uuid.New().String() + "@example.com"
+	customers := "name,email,tax_number,city,payment_term_days\n" +
+		"Bakkal Ali," + email + ",1234567890,Ankara,30\n" +
+		"Bakkal Veli," + email + ",123,İzmir,x\n" +
+		"Market,not-an-email,,,\n"
+	report, err = run(domain.ImportKindCustomers, customers, false, 0, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 0, report.Imported)
+	assert.Equal(t, []domain.ImportRowError{
+		{Row: 3, Column: "email", Message: "email " + email + " is also on row 2"},
+		{Row: 3, Column: "tax_number", Message: "tax number must be a 10-digit VKN or an 11-digit TCKN"},
+		{Row: 3, Column: "payment_term_days", Message: `"x" is not a whole number`},
+		{Row: 4, Column: "email", Message: `"not-an-email" is not an email address`},
+	}, report.Errors)
+
+	report, err = run(domain.ImportKindCustomers, "name,email,tax_number,city,payment_term_days\nBakkal Ali,"+email+",1234567890,Ankara,30\n", false, 0, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Imported)
+	assert.Equal(t, 30, count("SELECT payment_term_days FROM customers WHERE tenant_id = $1"))
+
+	// 5. Opening stock in chunks; rows before start_row are skipped
+	stock := "sku,warehouse,quantity,unit_cost\n" +
+		"CAY-1,ana depo,40,9.5\n" +
+		"KAHVE,Ana Depo,10,\n" +
+		"SEKER,Ana Depo,25,20\n" +
+		"EXIST,Ana Depo,5,\n"
+	report, err = run(domain.ImportKindOpeningStock, stock, false, 2, 3)
+	require.NoError(t, err)
+	assert.Equal(t, 3, report.TotalRows)
+	assert.Equal(t, 3, report.Imported)
+	assert.Zero(t, report.NextRow)
+	assert.Equal(t, 40, count("SELECT COALESCE(SUM(quantity), 0) FROM stock_movements WHERE tenant_id = $1 AND reference_type = 'OPENING_BALANCE'"))
+
+	report, err = run(domain.ImportKindOpeningStock, stock+"CAY-1,Ana Depo,1,\nYOK,Depo 2,0,\n", true, 0, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.ValidRows)
+	assert.Equal(t, []domain.ImportRowError{
+		{Row: 3, Column: "sku", Message: "KAHVE already has stock movements in Ana Depo"},
+		{Row: 4, Column: "sku", Message: "SEKER already has stock movements in Ana Depo"},
+		{Row: 5, Column: "sku", Message: "EXIST already has stock movements in Ana Depo"},
+		{Row: 6, Column: "sku", Message: "CAY-1 in Ana Depo is also on row 2"},
+		{Row: 7, Column: "sku", Message: "product YOK not found"},
+		{Row: 7, Column: "warehouse", Message: `warehouse "Depo 2" not found`},
+		{Row: 7, Column: "quantity", Message: "quantity must be at least 1"},
+	}, report.Errors)
+}
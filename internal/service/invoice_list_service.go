@@ -2,12 +2,15 @@ package service
 
 import (
 	"context"
+	"fmt"
+	"sort"
 	"time"
 
 	"sancaksoft/internal/domain"
 	"sancaksoft/internal/repository"
 
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 )
 
 type InvoiceListService struct {
@@ -18,11 +21,23 @@ func NewInvoiceListService(repo *repository.InvoiceListRepository) *InvoiceListS
 	return &InvoiceListService{repo: repo}
 }
 
-func (s *InvoiceListService) ListInvoices(ctx context.Context, tenantID uuid.UUID) ([]domain.Invoice, error) {
+// ListInvoices returns the latest invoices matching the filter.
+func (s *InvoiceListService) ListInvoices(ctx context.Context, f domain.InvoiceFilter) ([]domain.Invoice, error) {
+	if err := validateInvoiceFilter(f); err != nil {
+		return nil, err
+	}
+
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	return s.repo.ListInvoices(ctx, tenantID)
+	return s.repo.ListInvoices(ctx, f)
+}
+
+func validateInvoiceFilter(f domain.InvoiceFilter) error {
+	if f.From != nil && f.To != nil && !f.From.Before(*f.To) {
+		return fmt.Errorf("from must be before to: %w", ErrInvalidInput)
+	}
+	return nil
 }
 
 func (s *InvoiceListService) GetInvoiceDetail(ctx context.Context, tenantID, invoiceID uuid.UUID) (*repository.InvoiceDetail, []repository.InvoiceDetailItem, error) {
@@ -31,3 +46,55 @@ func (s *InvoiceListService) GetInvoiceDetail(ctx context.Context, tenantID, inv
 
 	return s.repo.GetInvoiceDetail(ctx, tenantID, invoiceID)
 }
+
+// GetInvoiceDocument returns everything needed to print an invoice, with the VAT
+// breakdown worked out from the VAT-inclusive line totals.
+func (s *InvoiceListService) GetInvoiceDocument(ctx context.Context, tenantID, invoiceID uuid.UUID) (*domain.InvoiceDocument, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	doc, err := s.repo.GetInvoiceDocument(ctx, tenantID, invoiceID)
+	if err != nil {
+		return nil, err
+	}
+	if doc == nil {
+		return nil, fmt.Errorf("invoice %s: %w", invoiceID, ErrNotFound)
+	}
+	applyVAT(doc)
+	return doc, nil
+}
+
+var hundred = decimal.NewFromInt(100)
+
+// applyVAT splits each line's total into base and VAT and sums them per rate. Rounding is
+// per line, so the e-invoice lines add up to its totals and base plus VAT is always the
+// invoiced amount.
+func applyVAT(doc *domain.InvoiceDocument) {
+	totals := map[string]*domain.VATSubtotal{}
+	doc.VAT = []domain.VATSubtotal{}
+	doc.Subtotal, doc.VATTotal, doc.Total = decimal.Zero, decimal.Zero, decimal.Zero
+	for i := range doc.Lines {
+		l := &doc.Lines[i]
+		l.Base, l.VAT = splitVAT(l.Total, l.VATRate)
+
+		key := l.VATRate.String()
+		if totals[key] == nil {
+			totals[key] = &domain.VATSubtotal{Rate: l.VATRate}
+		}
+		totals[key].Base = totals[key].Base.Add(l.Base)
+		totals[key].Amount = totals[key].Amount.Add(l.VAT)
+		doc.Subtotal = doc.Subtotal.Add(l.Base)
+		doc.VATTotal = doc.VATTotal.Add(l.VAT)
+		doc.Total = doc.Total.Add(l.Total)
+	}
+	for _, t := range totals {
+		doc.VAT = append(doc.VAT, *t)
+	}
+	sort.Slice(doc.VAT, func(i, j int) bool { return doc.VAT[i].Rate.LessThan(doc.VAT[j].Rate) })
+}
+
+// splitVAT splits a VAT-inclusive line total into base and VAT, rounding the base.
+func splitVAT(total, rate decimal.Decimal) (base, vat decimal.Decimal) {
+	base = total.Mul(hundred).Div(hundred.Add(rate)).Round(2)
+	return base, total.Sub(base)
+}
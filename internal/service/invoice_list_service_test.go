@@ -0,0 +1,106 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"sancaksoft/internal/domain"
+	"sancaksoft/internal/repository"
+	"sancaksoft/internal/service"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInvoiceDocument_Integration(t *testing.T) {
+	if testDBURL == "" {
+		t.Skip("Skipping integration test: TEST_DB_URL not set")
+	}
+
+	ctx := context.Background()
+	db, err := pgxpool.New(ctx, testDBURL)
+	require.NoError(t, err)
+	defer db.Close()
+
+	// 1. Setup Data: a food item at 1% VAT and a tool at 20%, prices include VAT
+	tenantID := uuid.New()
+	warehouseID := uuid.New()
+	breadID := uuid.New()
+	toolID := uuid.New()
+
+	defer func() {
+		_, _ = db.Exec(ctx, "DELETE FROM tenants WHERE id = $1", tenantID)
+	}()
+
+	_, err = db.Exec(ctx, "INSERT INTO tenants (id, name) VALUES ($1, 'Document Test Tenant')", tenantID)
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, "INSERT INTO warehouses (id, tenant_id, name) VALUES ($1, $2, 'Main')", warehouseID, tenantID)
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, "INSERT INTO products (id, tenant_id, name, sku, unit, price, vat_rate) VALUES ($1, $2, 'Bread', $3, 'kg', 10.10, 1)", breadID, tenantID, "SKU-"+uuid.New().String())
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, "INSERT INTO products (id, tenant_id, name, sku, price, vat_rate) VALUES ($1, $2, 'Hammer', $3, 240.00, 20)", toolID, tenantID, "SKU-"+uuid.New().String())
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, "INSERT INTO stock_movements (tenant_id, product_id, warehouse_id, quantity, type) VALUES ($1, $2, $4, 100, 'IN'), ($1, $3, $4, 100, 'IN')", tenantID, breadID, toolID, warehouseID)
+	require.NoError(t, err)
+
+	customerService := service.NewCustomerService(db, repository.NewCustomerRepository(db))
+	tenantService := service.NewTenantService(db, repository.NewTenantRepository(db))
+	invoiceService := service.NewInvoiceService(db, repository.NewInvoiceRepository())
+	listService := service.NewInvoiceListService(repository.NewInvoiceListRepository(db))
+
+	// 2. Customers carry a VKN or TCKN
+	err = customerService.CreateCustomer(ctx, &domain.Customer{TenantID: tenantID, Name: "Bad Tax", TaxNumber: "12AB"})
+	assert.ErrorIs(t, err, service.ErrInvalidInput)
+	customer := &domain.Customer{TenantID: tenantID, Name: "Bakery Ltd", Email: uuid.New().String() + "@bakery.test", TaxNumber: "1234567890", TaxOffice: "Kadıköy"}
+	require.NoError(t, customerService.CreateCustomer(ctx, customer))
+
+	invoice, err := invoiceService.CreateInvoice(ctx, domain.CreateInvoiceRequest{
+		TenantID:    tenantID,
+		CustomerID:  customer.ID,
+		WarehouseID: warehouseID,
+		Items: []domain.InvoiceItemRequest{
+			{ProductID: breadID, Quantity: 3, UnitPrice: decimal.RequireFromString("10.10")},
+			{ProductID: toolID, Quantity: 2, UnitPrice: decimal.NewFromInt(240)},
+		},
+	})
+	require.NoError(t, err)
+
+	// 3. The rate is captured on the line; later price list changes do not alter the invoice
+	_, err = db.Exec(ctx, "UPDATE products SET vat_rate = 10 WHERE id = $1", toolID)
+	require.NoError(t, err)
+
+	doc, err := listService.GetInvoiceDocument(ctx, tenantID, invoice.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "1234567890", doc.Customer.TaxNumber)
+	assert.Equal(t, "Kadıköy", doc.Customer.TaxOffice)
+	require.Len(t, doc.Lines, 2)
+	assert.Equal(t, "kg", doc.Lines[0].Unit)
+
+	// 30.30 at 1%: 30.00 + 0.30; 480 at 20%: 400 + 80
+	require.Len(t, doc.VAT, 2)
+	assert.True(t, doc.VAT[0].Rate.Equal(decimal.NewFromInt(1)))
+	assert.True(t, doc.VAT[0].Base.Equal(decimal.NewFromInt(30)))
+	assert.True(t, doc.VAT[0].Amount.Equal(decimal.RequireFromString("0.30")))
+	assert.True(t, doc.VAT[1].Rate.Equal(decimal.NewFromInt(20)))
+	assert.True(t, doc.VAT[1].Amount.Equal(decimal.NewFromInt(80)))
+	assert.True(t, doc.Subtotal.Equal(decimal.NewFromInt(430)))
+	assert.True(t, doc.Total.Equal(invoice.TotalAmount))
+
+	// 4. Without a saved template the defaults apply; a saved one is used from then on
+	assert.Equal(t, "FATURA", doc.Template.Title)
+	err = tenantService.SaveInvoiceTemplate(ctx, &domain.InvoiceTemplate{TenantID: tenantID, AccentColor: "blue"})
+	assert.ErrorIs(t, err, service.ErrInvalidInput)
+	require.NoError(t, tenantService.SaveInvoiceTemplate(ctx, &domain.InvoiceTemplate{
+		TenantID: tenantID, Title: "SATIŞ FATURASI", AccentColor: "#AA0000", BankDetails: "TR00 0000",
+	}))
+	doc, err = listService.GetInvoiceDocument(ctx, tenantID, invoice.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "SATIŞ FATURASI", doc.Template.Title)
+	assert.False(t, doc.Template.ShowSKU)
+
+	_, err = listService.GetInvoiceDocument(ctx, tenantID, uuid.New())
+	assert.ErrorIs(t, err, service.ErrNotFound)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"sancaksoft/internal/domain"
@@ -37,110 +38,383 @@ func (s *InvoiceService) CreateInvoice(ctx context.Context, req domain.CreateInv
 
 	// Transaction Wrapper
 	err := WithTransaction(ctx, s.db, func(tx pgx.Tx) error {
-		// 1. Idempotency
-		if req.IdempotencyKey != uuid.Nil {
-			if err := s.repo.CheckIdempotency(ctx, tx, req.TenantID, req.IdempotencyKey); err != nil {
-				return err
-			}
-		}
-
-		// 2. Invoice Number
-		invoiceNumber, err := s.repo.GenerateNextInvoiceNumber(ctx, tx, req.TenantID)
+		invoice, err := s.createInvoiceTx(ctx, tx, req)
 		if err != nil {
 			return err
 		}
+		createdInvoice = invoice
+		return nil
+	})
 
-		// 3. Calc Total
-		var totalAmount decimal.Decimal
-		for _, item := range req.Items {
-			lineTotal := item.UnitPrice.Mul(decimal.NewFromInt(int64(item.Quantity)))
-			totalAmount = totalAmount.Add(lineTotal)
-		}
+	if err != nil {
+		return nil, err
+	}
 
-		// 4. Create Invoice Object
-		invoiceID := uuid.New()
-		invoice := &domain.Invoice{
-			ID:            invoiceID,
-			TenantID:      req.TenantID,
-			WarehouseID:   req.WarehouseID,
-			CustomerID:    req.CustomerID,
-			InvoiceNumber: invoiceNumber,
-			TotalAmount:   totalAmount,
+	return createdInvoice, nil
+}
+
+// createInvoiceTx creates an invoice, its items and SALE movements inside an existing
+// transaction so other documents (e.g. sales orders) can convert into invoices atomically.
+func (s *InvoiceService) createInvoiceTx(ctx context.Context, tx pgx.Tx, req domain.CreateInvoiceRequest) (*domain.Invoice, error) {
+	// 1. Idempotency
+	if req.IdempotencyKey != uuid.Nil {
+		if err := s.repo.CheckIdempotency(ctx, tx, req.TenantID, req.IdempotencyKey); err != nil {
+			return nil, err
 		}
+	}
 
-		// 5. Save Invoice
-		if err := s.repo.CreateInvoice(ctx, tx, invoice, req.IdempotencyKey); err != nil {
-			return fmt.Errorf("failed to create invoice: %w", err)
+	// Quarantined (damaged/returned) stock must never be resold
+	isQuarantine, err := s.repo.IsQuarantineWarehouse(ctx, tx, req.TenantID, req.WarehouseID)
+	if err != nil {
+		return nil, err
+	}
+	if isQuarantine {
+		return nil, fmt.Errorf("cannot invoice from quarantine warehouse %s", req.WarehouseID)
+	}
+
+	movesStock := len(req.DeliveryNoteIDs) == 0
+
+	// Locked so concurrent invoices are checked against the customer's credit one at a time
+	customer, err := s.repo.LockCustomer(ctx, tx, req.TenantID, req.CustomerID)
+	if err != nil {
+		return nil, err
+	}
+	if customer == nil {
+		return nil, fmt.Errorf("customer %s: %w", req.CustomerID, ErrNotFound)
+	}
+
+	// 2. Invoice Number
+	invoiceNumber, err := s.repo.GenerateNextInvoiceNumber(ctx, tx, req.TenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. Calc Total
+	var totalAmount decimal.Decimal
+	for _, item := range req.Items {
+		lineTotal := item.UnitPrice.Mul(decimal.NewFromInt(int64(item.Quantity)))
+		totalAmount = totalAmount.Add(lineTotal)
+	}
+
+	// Delivery notes were checked when they shipped
+	var creditOverride map[string]any
+	if movesStock {
+		if creditOverride, err = s.checkCredit(ctx, tx, req.TenantID, req.UserID, req.CreditOverrideReason, customer, totalAmount); err != nil {
+			return nil, err
 		}
+	}
+
+	// 4. Create Invoice Object
+	invoiceID := uuid.New()
+	invoice := &domain.Invoice{
+		ID:            invoiceID,
+		TenantID:      req.TenantID,
+		WarehouseID:   req.WarehouseID,
+		CustomerID:    req.CustomerID,
+		InvoiceNumber: invoiceNumber,
+		TotalAmount:   totalAmount,
+		SalesOrderID:  req.SalesOrderID,
+	}
+	now := time.Now()
+	year, month, day := now.Date()
+	invoice.DueDate = time.Date(year, month, day+customer.PaymentTermDays, 0, 0, 0, 0, now.Location())
+	if req.UserID != uuid.Nil {
+		invoice.SalespersonID = &req.UserID
+	}
+
+	// 5. Save Invoice
+	if err := s.repo.CreateInvoice(ctx, tx, invoice, req.IdempotencyKey); err != nil {
+		return nil, fmt.Errorf("failed to create invoice: %w", err)
+	}
 
-		// 6. Process Items
-		for _, itemReq := range req.Items {
-			// A. Lock Product
-			if err := s.repo.LockProduct(ctx, tx, req.TenantID, itemReq.ProductID); err != nil {
-				return err
+	// 6. Process Items
+	for _, itemReq := range req.Items {
+		// A. Lock Product
+		if err := s.repo.LockProduct(ctx, tx, req.TenantID, itemReq.ProductID); err != nil {
+			return nil, err
+		}
+		components, err := s.repo.ListKitComponents(ctx, tx, req.TenantID, itemReq.ProductID)
+		if err != nil {
+			return nil, err
+		}
+		if len(components) > 0 {
+			if !movesStock {
+				return nil, fmt.Errorf("product %s is a kit; kits are not shipped by delivery notes: %w", itemReq.ProductID, ErrInvalidInput)
+			}
+			if err := s.sellKit(ctx, tx, req, invoiceID, itemReq, components); err != nil {
+				return nil, err
 			}
+			continue
+		}
 
-			// B. Check Stock
+		// B. Check Stock (on hand minus what other confirmed orders have reserved)
+		if movesStock {
 			currentStock, err := s.repo.GetStockBalance(ctx, tx, req.TenantID, itemReq.ProductID, req.WarehouseID)
 			if err != nil {
-				return err
-			}
-			if currentStock < itemReq.Quantity {
-				return fmt.Errorf("insufficient stock for product %s. Available: %d, Requested: %d", itemReq.ProductID, currentStock, itemReq.Quantity)
+				return nil, err
 			}
-
-			// C. Create Invoice Item
-			lineTotal := itemReq.UnitPrice.Mul(decimal.NewFromInt(int64(itemReq.Quantity)))
-			item := &domain.InvoiceItem{
-				ID:        uuid.New(),
-				TenantID:  req.TenantID,
-				InvoiceID: invoiceID,
-				ProductID: itemReq.ProductID,
-				Quantity:  itemReq.Quantity,
-				UnitPrice: itemReq.UnitPrice,
-				Total:     lineTotal,
+			reserved, err := s.repo.GetReservedQuantity(ctx, tx, req.TenantID, itemReq.ProductID, req.WarehouseID, req.SalesOrderID)
+			if err != nil {
+				return nil, err
 			}
-			if err := s.repo.CreateInvoiceItem(ctx, tx, item); err != nil {
-				return fmt.Errorf("failed to create invoice item: %w", err)
+			if available := currentStock - reserved; available < itemReq.Quantity {
+				return nil, fmt.Errorf("insufficient stock for product %s. Available: %d, Requested: %d", itemReq.ProductID, available, itemReq.Quantity)
 			}
+		}
 
-			// D. Stock Movement
-			refType := "INVOICE"
+		// C. Create Invoice Item, capturing the cost the margin is measured against
+		unitCost, err := s.repo.GetSaleUnitCost(ctx, tx, req.TenantID, itemReq.ProductID)
+		if err != nil {
+			return nil, err
+		}
+		lineTotal := itemReq.UnitPrice.Mul(decimal.NewFromInt(int64(itemReq.Quantity)))
+		item := &domain.InvoiceItem{
+			ID:        uuid.New(),
+			TenantID:  req.TenantID,
+			InvoiceID: invoiceID,
+			ProductID: itemReq.ProductID,
+			Quantity:  itemReq.Quantity,
+			UnitPrice: itemReq.UnitPrice,
+			Total:     lineTotal,
+			UnitCost:  unitCost,
+		}
+		if err := s.repo.CreateInvoiceItem(ctx, tx, item); err != nil {
+			return nil, fmt.Errorf("failed to create invoice item: %w", err)
+		}
+
+		// D. Stock Movement (already written by the delivery notes being billed)
+		if !movesStock {
+			continue
+		}
+		allocations, err := s.allocateSale(ctx, tx, req.TenantID, itemReq.ProductID, req.WarehouseID, itemReq.Quantity, itemReq.LotNumber, itemReq.SerialNumbers)
+		if err != nil {
+			return nil, err
+		}
+		refType := "INVOICE"
+		for _, allocation := range allocations {
 			movement := &domain.StockMovement{
 				ID:            uuid.New(),
 				TenantID:      req.TenantID,
 				ProductID:     itemReq.ProductID,
 				WarehouseID:   req.WarehouseID,
-				Quantity:      -itemReq.Quantity, // Negative!
+				Quantity:      -allocation.quantity, // Negative!
 				Type:          domain.StockMovementTypeSale,
 				ReferenceID:   &invoiceID,
 				ReferenceType: &refType,
+				LotID:         allocation.lotID,
+				LocationID:    allocation.locationID,
+				SerialNumbers: allocation.serials,
 			}
 			if err := s.repo.CreateStockMovement(ctx, tx, movement); err != nil {
-				return fmt.Errorf("failed to create stock movement: %w", err)
+				return nil, fmt.Errorf("failed to create stock movement: %w", err)
+			}
+			if err := moveSerials(ctx, tx, s.repo, movement); err != nil {
+				return nil, err
 			}
 		}
+	}
+
+	// 7. Audit Log
+	entry := domain.AuditLog{
+		TenantID:   req.TenantID,
+		EntityType: domain.AuditEntityInvoice,
+		EntityID:   invoiceID,
+		Action:     domain.AuditActionCreate,
+	}
+	if req.UserID != uuid.Nil {
+		entry.UserID = &req.UserID
+	}
+	if err := recordAudit(ctx, s.repo, tx, entry, nil, invoice); err != nil {
+		return nil, err
+	}
+	if creditOverride != nil {
+		entry.Action = domain.AuditActionCreditOverride
+		entry.Details = creditOverride
+		if err := recordAudit(ctx, s.repo, tx, entry, nil, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	return invoice, nil
+}
+
+// creditOverrideRoles are the user roles allowed to ship to or invoice a customer on credit hold.
+var creditOverrideRoles = map[string]bool{"admin": true, "manager": true}
+
+// checkCredit holds a sale (an invoice or a delivery note) that would take the customer's
+// open balance and uninvoiced deliveries over its credit limit, or any sale while the
+// customer has overdue invoices. A manager can override the hold by giving a reason; the
+// returned audit details describe it (nil when nothing was held). The customer must be
+// locked by the caller.
+func (s *InvoiceService) checkCredit(ctx context.Context, tx pgx.Tx, tenantID, userID uuid.UUID, overrideReason string, customer *domain.Customer, total decimal.Decimal) (map[string]any, error) {
+	credit, err := s.repo.GetCustomerCredit(ctx, tx, tenantID, customer.ID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	uninvoiced, err := s.repo.GetUninvoicedDeliveryTotal(ctx, tx, tenantID, customer.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var holds []string
+	exposure := credit.OpenBalance.Add(uninvoiced)
+	if customer.CreditLimit != nil && exposure.Add(total).GreaterThan(*customer.CreditLimit) {
+		holds = append(holds, fmt.Sprintf("open balance %s and uninvoiced deliveries %s plus this sale exceed the credit limit %s",
+			credit.OpenBalance.StringFixed(2), uninvoiced.StringFixed(2), customer.CreditLimit.StringFixed(2)))
+	}
+	if credit.OverdueAmount.IsPositive() {
+		holds = append(holds, fmt.Sprintf("%s is overdue", credit.OverdueAmount.StringFixed(2)))
+	}
+	if len(holds) == 0 {
+		return nil, nil
+	}
+	hold := strings.Join(holds, "; ")
+
+	if strings.TrimSpace(overrideReason) == "" {
+		return nil, fmt.Errorf("customer %s is on credit hold (%s); a manager override is required: %w", customer.Name, hold, ErrInvalidState)
+	}
+	role, err := s.repo.GetUserRole(ctx, tx, tenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !creditOverrideRoles[role] {
+		return nil, fmt.Errorf("only managers can override a credit hold: %w", ErrForbidden)
+	}
+	return map[string]any{
+		"reason":                overrideReason,
+		"hold":                  hold,
+		"credit_limit":          customer.CreditLimit,
+		"open_balance":          credit.OpenBalance,
+		"uninvoiced_deliveries": uninvoiced,
+		"overdue_amount":        credit.OverdueAmount,
+		"total":                 total,
+	}, nil
+}
+
+// sellKit invoices a kit line: the kit holds no stock, so every component is checked under
+// its own lock and shipped with its own SALE movements. The line's cost is the sum of the
+// component costs, and its revenue is split across the components for reporting.
+func (s *InvoiceService) sellKit(ctx context.Context, tx pgx.Tx, req domain.CreateInvoiceRequest, invoiceID uuid.UUID, itemReq domain.InvoiceItemRequest, components []domain.KitComponent) error {
+	if itemReq.LotNumber != "" || len(itemReq.SerialNumbers) > 0 {
+		return fmt.Errorf("product %s is a kit; its components are allocated automatically: %w", itemReq.ProductID, ErrInvalidInput)
+	}
 
-		// 7. Audit Log
-		auditLog := &domain.AuditLog{
-			ID:         uuid.New(),
-			TenantID:   req.TenantID,
-			UserID:     req.UserID,
-			EntityType: "INVOICE",
-			EntityID:   invoiceID,
-			Action:     "CREATE",
+	unitCost := decimal.Zero
+	costs := make([]decimal.Decimal, len(components))
+	for i, c := range components {
+		if err := s.repo.LockProduct(ctx, tx, req.TenantID, c.ComponentID); err != nil {
+			return err
 		}
-		if err := s.repo.CreateAuditLog(ctx, tx, auditLog); err != nil {
-			return fmt.Errorf("failed to create audit log: %w", err)
+		required := c.Quantity * itemReq.Quantity
+		onHand, err := s.repo.GetStockBalance(ctx, tx, req.TenantID, c.ComponentID, req.WarehouseID)
+		if err != nil {
+			return err
+		}
+		reserved, err := s.repo.GetReservedQuantity(ctx, tx, req.TenantID, c.ComponentID, req.WarehouseID, req.SalesOrderID)
+		if err != nil {
+			return err
+		}
+		if available := onHand - reserved; available < required {
+			return fmt.Errorf("insufficient stock for component %s of kit %s. Available: %d, Requested: %d", c.ComponentID, itemReq.ProductID, available, required)
+		}
+		if costs[i], err = s.repo.GetSaleUnitCost(ctx, tx, req.TenantID, c.ComponentID); err != nil {
+			return err
 		}
+		unitCost = unitCost.Add(costs[i].Mul(decimal.NewFromInt(int64(c.Quantity))))
+	}
 
-		createdInvoice = invoice
-		return nil
-	})
+	lineTotal := itemReq.UnitPrice.Mul(decimal.NewFromInt(int64(itemReq.Quantity)))
+	item := &domain.InvoiceItem{
+		ID:        uuid.New(),
+		TenantID:  req.TenantID,
+		InvoiceID: invoiceID,
+		ProductID: itemReq.ProductID,
+		Quantity:  itemReq.Quantity,
+		UnitPrice: itemReq.UnitPrice,
+		Total:     lineTotal,
+		UnitCost:  unitCost,
+	}
+	if err := s.repo.CreateInvoiceItem(ctx, tx, item); err != nil {
+		return fmt.Errorf("failed to create invoice item: %w", err)
+	}
+
+	revenues := allocateKitRevenue(lineTotal, components)
+	refType := "INVOICE"
+	for i, c := range components {
+		quantity := c.Quantity * itemReq.Quantity
+		allocations, err := s.allocateSale(ctx, tx, req.TenantID, c.ComponentID, req.WarehouseID, quantity, "", nil)
+		if err != nil {
+			return err
+		}
+		for _, allocation := range allocations {
+			movement := &domain.StockMovement{
+				ID:            uuid.New(),
+				TenantID:      req.TenantID,
+				ProductID:     c.ComponentID,
+				WarehouseID:   req.WarehouseID,
+				Quantity:      -allocation.quantity,
+				Type:          domain.StockMovementTypeSale,
+				ReferenceID:   &invoiceID,
+				ReferenceType: &refType,
+				LotID:         allocation.lotID,
+				LocationID:    allocation.locationID,
+			}
+			if err := s.repo.CreateStockMovement(ctx, tx, movement); err != nil {
+				return fmt.Errorf("failed to create stock movement: %w", err)
+			}
+		}
+		if err := s.repo.CreateInvoiceItemComponent(ctx, tx, &domain.InvoiceItemComponent{
+			TenantID:      req.TenantID,
+			InvoiceItemID: item.ID,
+			ProductID:     c.ComponentID,
+			Quantity:      quantity,
+			Revenue:       revenues[i],
+			UnitCost:      costs[i],
+		}); err != nil {
+			return fmt.Errorf("failed to record kit component: %w", err)
+		}
+	}
+	return nil
+}
 
+// allocateSale splits a sale into one movement per lot for lot-tracked products, carrying
+// the serial numbers of serialized products (a product is never both), and then per
+// location in picking order. The product must already be locked.
+func (s *InvoiceService) allocateSale(ctx context.Context, tx pgx.Tx, tenantID, productID, warehouseID uuid.UUID, quantity int, lotNumber string, serialNumbers []string) ([]stockAllocation, error) {
+	serialized, err := s.repo.ProductTracksSerials(ctx, tx, tenantID, productID)
 	if err != nil {
 		return nil, err
 	}
+	serials, err := checkSerials(serialized, productID, quantity, serialNumbers)
+	if err != nil {
+		return nil, err
+	}
+	tracked, err := s.repo.ProductTracksLots(ctx, tx, tenantID, productID)
+	if err != nil {
+		return nil, err
+	}
+	allocations := []stockAllocation{{quantity: quantity, serials: serials}}
+	if !tracked {
+		if lotNumber != "" {
+			return nil, fmt.Errorf("product %s is not lot-tracked: %w", productID, ErrInvalidInput)
+		}
+	} else {
+		lots, err := s.repo.ListAvailableLots(ctx, tx, tenantID, productID, warehouseID)
+		if err != nil {
+			return nil, err
+		}
+		onHand, err := s.repo.GetStockBalance(ctx, tx, tenantID, productID, warehouseID)
+		if err != nil {
+			return nil, err
+		}
+		if allocations, err = allocateLots(lots, onHand, quantity, lotNumber); err != nil {
+			return nil, fmt.Errorf("product %s: %w", productID, err)
+		}
+	}
 
-	return createdInvoice, nil
+	locations, err := s.repo.ListProductLocations(ctx, tx, tenantID, productID, warehouseID)
+	if err != nil {
+		return nil, err
+	}
+	return allocateLocations(locations, allocations), nil
 }
@@ -0,0 +1,253 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"sancaksoft/internal/domain"
+	"sancaksoft/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shopspring/decimal"
+)
+
+// A kit is a product made of fixed quantities of other products. It holds no stock: selling
+// it on an invoice ships its components, and how many kits a warehouse can sell follows
+// from the unreserved stock of the components.
+type KitService struct {
+	db   *pgxpool.Pool
+	repo *repository.KitRepository
+}
+
+func NewKitService(db *pgxpool.Pool, repo *repository.KitRepository) *KitService {
+	return &KitService{db: db, repo: repo}
+}
+
+// SetComponents replaces the components of a kit; an empty list turns the kit back into an
+// ordinary product. Components must be stocked products that are neither kits, variant
+// parents nor serialized, since kit sales allocate them automatically.
+func (s *KitService) SetComponents(ctx context.Context, tenantID, kitID uuid.UUID, components []domain.KitComponent) ([]domain.KitComponent, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	seen := make(map[uuid.UUID]bool, len(components))
+	for _, c := range components {
+		if c.Quantity <= 0 {
+			return nil, fmt.Errorf("component quantity must be greater than zero: %w", ErrInvalidInput)
+		}
+		if c.ComponentID == kitID {
+			return nil, fmt.Errorf("a kit cannot contain itself: %w", ErrInvalidInput)
+		}
+		if seen[c.ComponentID] {
+			return nil, fmt.Errorf("component %s is listed twice: %w", c.ComponentID, ErrInvalidInput)
+		}
+		seen[c.ComponentID] = true
+	}
+
+	err := WithTransaction(ctx, s.db, func(tx pgx.Tx) error {
+		kit, err := s.repo.LockProduct(ctx, tx, tenantID, kitID)
+		if err != nil {
+			return err
+		}
+		if kit == nil {
+			return fmt.Errorf("product %s: %w", kitID, ErrNotFound)
+		}
+		if len(components) > 0 {
+			if err := s.checkKit(ctx, tx, tenantID, kitID); err != nil {
+				return err
+			}
+		}
+		for _, c := range components {
+			if err := s.checkComponent(ctx, tx, tenantID, c.ComponentID); err != nil {
+				return err
+			}
+		}
+		current, err := s.repo.GetComponents(ctx, tx, tenantID, kitID)
+		if err != nil {
+			return err
+		}
+		if err := s.repo.ReplaceComponents(ctx, tx, tenantID, kitID, components); err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.repo, tx, domain.AuditLog{
+			TenantID: tenantID, EntityType: domain.AuditEntityProduct, EntityID: kitID, Action: domain.AuditActionUpdate,
+		}, map[string]any{"components": componentQuantities(current)}, map[string]any{"components": componentQuantities(components)})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.ListComponents(ctx, tenantID, kitID)
+}
+
+// componentQuantities maps a kit's components to their quantities, the form kit changes are
+// audited in.
+func componentQuantities(components []domain.KitComponent) map[uuid.UUID]int {
+	quantities := make(map[uuid.UUID]int, len(components))
+	for _, c := range components {
+		quantities[c.ComponentID] = c.Quantity
+	}
+	return quantities
+}
+
+// checkKit rejects products that cannot be kits: anything already holding stock of its own.
+func (s *KitService) checkKit(ctx context.Context, tx pgx.Tx, tenantID, kitID uuid.UUID) error {
+	moved, err := s.repo.HasMovements(ctx, tx, tenantID, kitID)
+	if err != nil {
+		return err
+	}
+	if moved {
+		return fmt.Errorf("product %s has stock movements; kits need a new product: %w", kitID, ErrInvalidState)
+	}
+	parent, err := s.repo.ProductIsVariantParent(ctx, tx, tenantID, kitID)
+	if err != nil {
+		return err
+	}
+	if parent {
+		return fmt.Errorf("product %s has variants and cannot be a kit: %w", kitID, ErrInvalidInput)
+	}
+	component, err := s.repo.IsComponent(ctx, tx, tenantID, kitID)
+	if err != nil {
+		return err
+	}
+	if component {
+		return fmt.Errorf("product %s is a component of another kit: %w", kitID, ErrInvalidInput)
+	}
+	lots, err := s.repo.ProductTracksLots(ctx, tx, tenantID, kitID)
+	if err != nil {
+		return err
+	}
+	serials, err := s.repo.ProductTracksSerials(ctx, tx, tenantID, kitID)
+	if err != nil {
+		return err
+	}
+	if lots || serials {
+		return fmt.Errorf("product %s tracks lots or serial numbers and cannot be a kit: %w", kitID, ErrInvalidInput)
+	}
+	return nil
+}
+
+func (s *KitService) checkComponent(ctx context.Context, tx pgx.Tx, tenantID, componentID uuid.UUID) error {
+	product, err := s.repo.GetComponentProduct(ctx, tx, tenantID, componentID)
+	if err != nil {
+		return err
+	}
+	if product == nil {
+		return fmt.Errorf("component product %s: %w", componentID, ErrNotFound)
+	}
+	kit, err := s.repo.ProductIsKit(ctx, tx, tenantID, componentID)
+	if err != nil {
+		return err
+	}
+	if kit {
+		return fmt.Errorf("component %s is itself a kit: %w", componentID, ErrInvalidInput)
+	}
+	parent, err := s.repo.ProductIsVariantParent(ctx, tx, tenantID, componentID)
+	if err != nil {
+		return err
+	}
+	if parent {
+		return fmt.Errorf("component %s has variants; use a variant instead: %w", componentID, ErrInvalidInput)
+	}
+	serialized, err := s.repo.ProductTracksSerials(ctx, tx, tenantID, componentID)
+	if err != nil {
+		return err
+	}
+	if serialized {
+		return fmt.Errorf("component %s is serialized and cannot be allocated automatically: %w", componentID, ErrInvalidInput)
+	}
+	return nil
+}
+
+// GetComponents returns the components of a kit; empty if the product is not a kit.
+func (s *KitService) GetComponents(ctx context.Context, tenantID, kitID uuid.UUID) ([]domain.KitComponent, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	return s.repo.ListComponents(ctx, tenantID, kitID)
+}
+
+// GetAvailability returns how many kits a warehouse can ship: the smallest number of kits
+// any component's unreserved stock covers.
+func (s *KitService) GetAvailability(ctx context.Context, tenantID, kitID, warehouseID uuid.UUID) (*domain.KitAvailability, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	components, err := s.repo.ListComponents(ctx, tenantID, kitID)
+	if err != nil {
+		return nil, err
+	}
+	if len(components) == 0 {
+		kit, err := s.repo.GetProduct(ctx, tenantID, kitID)
+		if err != nil {
+			return nil, err
+		}
+		if kit == nil {
+			return nil, fmt.Errorf("product %s: %w", kitID, ErrNotFound)
+		}
+		return nil, fmt.Errorf("product %s is not a kit: %w", kitID, ErrInvalidInput)
+	}
+
+	availability := &domain.KitAvailability{
+		KitID:       kitID,
+		WarehouseID: warehouseID,
+		Components:  make([]domain.KitComponentAvailability, 0, len(components)),
+	}
+	for i, c := range components {
+		onHand, err := s.repo.GetStockBalance(ctx, tenantID, c.ComponentID, warehouseID)
+		if err != nil {
+			return nil, err
+		}
+		reserved, err := s.repo.GetReservedQuantity(ctx, tenantID, c.ComponentID, warehouseID)
+		if err != nil {
+			return nil, err
+		}
+		line := domain.KitComponentAvailability{
+			ComponentID:    c.ComponentID,
+			ComponentName:  c.ComponentName,
+			QuantityPerKit: c.Quantity,
+			Available:      onHand - reserved,
+		}
+		if line.Available > 0 {
+			line.KitsPossible = line.Available / c.Quantity
+		}
+		if i == 0 || line.KitsPossible < availability.Available {
+			availability.Available = line.KitsPossible
+		}
+		availability.Components = append(availability.Components, line)
+	}
+	return availability, nil
+}
+
+// allocateKitRevenue splits a kit line's revenue across its components in proportion to
+// their list price times quantity (by quantity alone when no component has a price). Shares
+// are rounded to cents and the last component takes the rounding difference.
+func allocateKitRevenue(total decimal.Decimal, components []domain.KitComponent) []decimal.Decimal {
+	weights := make([]decimal.Decimal, len(components))
+	sum := decimal.Zero
+	for i, c := range components {
+		weights[i] = c.Price.Mul(decimal.NewFromInt(int64(c.Quantity)))
+		sum = sum.Add(weights[i])
+	}
+	if !sum.IsPositive() {
+		sum = decimal.Zero
+		for i, c := range components {
+			weights[i] = decimal.NewFromInt(int64(c.Quantity))
+			sum = sum.Add(weights[i])
+		}
+	}
+
+	shares := make([]decimal.Decimal, len(components))
+	remaining := total
+	for i := range components {
+		if i == len(components)-1 {
+			shares[i] = remaining
+			break
+		}
+		shares[i] = total.Mul(weights[i]).Div(sum).Round(2)
+		remaining = remaining.Sub(shares[i])
+	}
+	return shares
+}
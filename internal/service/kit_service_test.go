@@ -0,0 +1,147 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"sancaksoft/internal/domain"
+	"sancaksoft/internal/repository"
+	"sancaksoft/internal/service"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProductKits_Integration(t *testing.T) {
+	if testDBURL == "" {
+		t.Skip("Skipping integration test: TEST_DB_URL not set")
+	}
+
+	ctx := context.Background()
+	db, err := pgxpool.New(ctx, testDBURL)
+	require.NoError(t, err)
+	defer db.Close()
+
+	// 1. Setup Data: a gift box of two mugs and a candle
+	tenantID := uuid.New()
+	warehouseID := uuid.New()
+	customerID := uuid.New()
+	userID := uuid.New()
+	kitID := uuid.New()
+	mugID := uuid.New()
+	candleID := uuid.New()
+
+	defer func() {
+		_, _ = db.Exec(ctx, "DELETE FROM tenants WHERE id = $1", tenantID)
+	}()
+
+	_, err = db.Exec(ctx, "INSERT INTO tenants (id, name) VALUES ($1, 'Kit Test Tenant')", tenantID)
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, "INSERT INTO warehouses (id, tenant_id, name) VALUES ($1, $2, 'Main')", warehouseID, tenantID)
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, "INSERT INTO customers (id, tenant_id, name) VALUES ($1, $2, 'Gift Shop')", customerID, tenantID)
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, "INSERT INTO products (id, tenant_id, name, sku, price) VALUES ($1, $2, 'Gift Box', $3, 35.00)", kitID, tenantID, "SKU-"+uuid.New().String())
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, "INSERT INTO products (id, tenant_id, name, sku, price, standard_cost) VALUES ($1, $2, 'Mug', $3, 10.00, 4.00)", mugID, tenantID, "SKU-"+uuid.New().String())
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, "INSERT INTO products (id, tenant_id, name, sku, price, standard_cost) VALUES ($1, $2, 'Candle', $3, 20.00, 6.00)", candleID, tenantID, "SKU-"+uuid.New().String())
+	require.NoError(t, err)
+
+	kitService := service.NewKitService(db, repository.NewKitRepository(db))
+	stockService := service.NewStockService(db, repository.NewStockRepository(db))
+	invoiceService := service.NewInvoiceService(db, repository.NewInvoiceRepository())
+	reportService := service.NewReportService(repository.NewReportRepository(db))
+	variantService := service.NewVariantService(db, repository.NewVariantRepository(db))
+
+	for productID, quantity := range map[uuid.UUID]int{mugID: 10, candleID: 3} {
+		require.NoError(t, stockService.CreateStockMovement(ctx, tenantID, &domain.StockMovement{
+			ID: uuid.New(), ProductID: productID, WarehouseID: warehouseID, Quantity: quantity, Type: domain.StockMovementTypeIn,
+		}))
+	}
+
+	// 2. Define the kit; invalid component lists are rejected
+	_, err = kitService.SetComponents(ctx, tenantID, kitID, []domain.KitComponent{{ComponentID: kitID, Quantity: 1}})
+	assert.ErrorIs(t, err, service.ErrInvalidInput)
+	_, err = kitService.SetComponents(ctx, tenantID, kitID, []domain.KitComponent{{ComponentID: mugID, Quantity: 0}})
+	assert.ErrorIs(t, err, service.ErrInvalidInput)
+	_, err = kitService.SetComponents(ctx, tenantID, mugID, []domain.KitComponent{{ComponentID: candleID, Quantity: 1}})
+	assert.ErrorIs(t, err, service.ErrInvalidState) // the mug already holds stock
+
+	components, err := kitService.SetComponents(ctx, tenantID, kitID, []domain.KitComponent{
+		{ComponentID: mugID, Quantity: 2},
+		{ComponentID: candleID, Quantity: 1},
+	})
+	require.NoError(t, err)
+	require.Len(t, components, 2)
+
+	_, err = variantService.SetDimensions(ctx, tenantID, kitID, []domain.VariantDimension{{Name: "Size", Values: []string{"S"}}})
+	assert.ErrorIs(t, err, service.ErrInvalidInput)
+	err = stockService.CreateStockMovement(ctx, tenantID, &domain.StockMovement{
+		ID: uuid.New(), ProductID: kitID, WarehouseID: warehouseID, Quantity: 5, Type: domain.StockMovementTypeIn,
+	})
+	assert.ErrorIs(t, err, service.ErrInvalidInput)
+
+	// 3. Availability: 10 mugs make 5 kits, 3 candles make 3
+	availability, err := kitService.GetAvailability(ctx, tenantID, kitID, warehouseID)
+	require.NoError(t, err)
+	assert.Equal(t, 3, availability.Available)
+	_, err = kitService.GetAvailability(ctx, tenantID, mugID, warehouseID)
+	assert.ErrorIs(t, err, service.ErrInvalidInput)
+
+	// 4. Selling two kits ships four mugs and two candles
+	invoice, err := invoiceService.CreateInvoice(ctx, domain.CreateInvoiceRequest{
+		TenantID:    tenantID,
+		UserID:      userID,
+		CustomerID:  customerID,
+		WarehouseID: warehouseID,
+		Items:       []domain.InvoiceItemRequest{{ProductID: kitID, Quantity: 2, UnitPrice: decimal.NewFromInt(35)}},
+	})
+	require.NoError(t, err)
+	assert.True(t, invoice.TotalAmount.Equal(decimal.NewFromInt(70)))
+
+	mugStock, err := stockService.GetStockBalance(ctx, tenantID, mugID, warehouseID)
+	require.NoError(t, err)
+	assert.Equal(t, 6, mugStock)
+	candleStock, err := stockService.GetStockBalance(ctx, tenantID, candleID, warehouseID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, candleStock)
+
+	availability, err = kitService.GetAvailability(ctx, tenantID, kitID, warehouseID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, availability.Available)
+
+	_, err = invoiceService.CreateInvoice(ctx, domain.CreateInvoiceRequest{
+		TenantID:    tenantID,
+		UserID:      userID,
+		CustomerID:  customerID,
+		WarehouseID: warehouseID,
+		Items:       []domain.InvoiceItemRequest{{ProductID: kitID, Quantity: 2, UnitPrice: decimal.NewFromInt(35)}},
+	})
+	require.Error(t, err)
+
+	// 5. The kit line costs 2 x 4 + 6; its revenue splits 35 / 35 by list price, 29.66 each
+	// without the kit's 18% VAT
+	report, err := reportService.GetGrossProfit(ctx, domain.GrossProfitReportRequest{TenantID: tenantID, GroupBy: domain.ProfitByProduct})
+	require.NoError(t, err)
+	require.Len(t, report.Lines, 1)
+	assert.Equal(t, kitID.String(), report.Lines[0].Key)
+	assert.True(t, report.Lines[0].Cost.Equal(decimal.NewFromInt(28)))
+
+	report, err = reportService.GetGrossProfit(ctx, domain.GrossProfitReportRequest{TenantID: tenantID, GroupBy: domain.ProfitByProduct, ExplodeKits: true})
+	require.NoError(t, err)
+	require.Len(t, report.Lines, 2)
+	byProduct := map[string]domain.GrossProfitLine{}
+	for _, line := range report.Lines {
+		byProduct[line.Key] = line
+	}
+	assert.Equal(t, 4, byProduct[mugID.String()].Quantity)
+	assert.True(t, byProduct[mugID.String()].Revenue.Equal(decimal.RequireFromString("29.66")))
+	assert.True(t, byProduct[mugID.String()].Cost.Equal(decimal.NewFromInt(16)))
+	assert.Equal(t, 2, byProduct[candleID.String()].Quantity)
+	assert.True(t, byProduct[candleID.String()].Cost.Equal(decimal.NewFromInt(12)))
+	assert.True(t, report.Total.Revenue.Equal(decimal.RequireFromString("59.32")))
+}
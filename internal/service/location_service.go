@@ -0,0 +1,341 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"sancaksoft/internal/domain"
+	"sancaksoft/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Locations are optional. Stock moved without one is "unlocated" (the warehouse balance minus
+// what its locations hold); sales take from locations in path order first, then from
+// unlocated stock. Location balances are per product and do not split lots.
+type LocationService struct {
+	db        *pgxpool.Pool
+	repo      *repository.LocationRepository
+	purchases *PurchaseOrderService
+	orders    *SalesOrderService
+}
+
+func NewLocationService(db *pgxpool.Pool, repo *repository.LocationRepository, purchases *PurchaseOrderService, orders *SalesOrderService) *LocationService {
+	return &LocationService{db: db, repo: repo, purchases: purchases, orders: orders}
+}
+
+// CreateLocation adds a location to a warehouse, under a parent location if given.
+func (s *LocationService) CreateLocation(ctx context.Context, loc *domain.WarehouseLocation) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	loc.Code = strings.TrimSpace(loc.Code)
+	if loc.Code == "" || strings.Contains(loc.Code, "/") {
+		return fmt.Errorf("location code is required and cannot contain '/': %w", ErrInvalidInput)
+	}
+	loc.Path = loc.Code
+	if loc.ParentID != nil {
+		parent, err := s.repo.GetLocation(ctx, loc.TenantID, *loc.ParentID)
+		if err != nil {
+			return err
+		}
+		if parent == nil || parent.WarehouseID != loc.WarehouseID {
+			return fmt.Errorf("parent location %s is not in warehouse %s: %w", *loc.ParentID, loc.WarehouseID, ErrInvalidInput)
+		}
+		loc.Path = parent.Path + "/" + loc.Code
+	}
+
+	loc.ID = uuid.New()
+	loc.IsActive = true
+	return WithTransaction(ctx, s.db, func(tx pgx.Tx) error {
+		created, err := s.repo.CreateLocation(ctx, tx, loc)
+		if err != nil {
+			return err
+		}
+		if !created {
+			return fmt.Errorf("location %s already exists: %w", loc.Path, ErrInvalidState)
+		}
+		return recordAudit(ctx, s.repo, tx, domain.AuditLog{
+			TenantID: loc.TenantID, EntityType: domain.AuditEntityLocation, EntityID: loc.ID, Action: domain.AuditActionCreate,
+		}, nil, loc)
+	})
+}
+
+// ListLocations returns the warehouse's locations in path order.
+func (s *LocationService) ListLocations(ctx context.Context, tenantID, warehouseID uuid.UUID) ([]domain.WarehouseLocation, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	return s.repo.ListLocations(ctx, tenantID, warehouseID)
+}
+
+// SetLocationActive activates or deactivates a location. Inactive locations take no new
+// stock but can still be picked from.
+func (s *LocationService) SetLocationActive(ctx context.Context, tenantID, locationID uuid.UUID, active bool) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	return WithTransaction(ctx, s.db, func(tx pgx.Tx) error {
+		loc, err := s.repo.GetLocationForUpdate(ctx, tx, tenantID, locationID)
+		if err != nil {
+			return err
+		}
+		if loc == nil {
+			return fmt.Errorf("location %s: %w", locationID, ErrNotFound)
+		}
+		if _, err := s.repo.SetLocationActive(ctx, tx, tenantID, locationID, active); err != nil {
+			return err
+		}
+		updated := *loc
+		updated.IsActive = active
+		return recordAudit(ctx, s.repo, tx, domain.AuditLog{
+			TenantID: tenantID, EntityType: domain.AuditEntityLocation, EntityID: locationID, Action: domain.AuditActionUpdate,
+		}, loc, &updated)
+	})
+}
+
+// ListLocationBalances returns stock per location of a warehouse, optionally for one product.
+func (s *LocationService) ListLocationBalances(ctx context.Context, tenantID, warehouseID uuid.UUID, productID *uuid.UUID) ([]domain.LocationBalance, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	return s.repo.ListLocationBalances(ctx, tenantID, warehouseID, productID)
+}
+
+// TransferStock moves stock between two locations of a warehouse, or between a location and
+// the unlocated stock. The warehouse balance and the costing ledger are not affected.
+func (s *LocationService) TransferStock(ctx context.Context, t *domain.LocationTransfer) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	if t.Quantity <= 0 {
+		return fmt.Errorf("quantity must be greater than zero: %w", ErrInvalidInput)
+	}
+	if t.FromLocationID == nil && t.ToLocationID == nil {
+		return fmt.Errorf("from_location_id or to_location_id is required: %w", ErrInvalidInput)
+	}
+	if t.FromLocationID != nil && t.ToLocationID != nil && *t.FromLocationID == *t.ToLocationID {
+		return fmt.Errorf("source and destination locations are the same: %w", ErrInvalidInput)
+	}
+	if err := s.checkLocation(ctx, t.TenantID, t.WarehouseID, t.FromLocationID, false); err != nil {
+		return err
+	}
+	if err := s.checkLocation(ctx, t.TenantID, t.WarehouseID, t.ToLocationID, true); err != nil {
+		return err
+	}
+
+	t.ID = uuid.New()
+	return WithTransaction(ctx, s.db, func(tx pgx.Tx) error {
+		found, err := s.repo.LockProduct(ctx, tx, t.TenantID, t.ProductID)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return fmt.Errorf("product %s: %w", t.ProductID, ErrNotFound)
+		}
+		available, err := s.repo.GetLocationQuantity(ctx, tx, t.TenantID, t.ProductID, t.WarehouseID, t.FromLocationID)
+		if err != nil {
+			return err
+		}
+		if available < t.Quantity {
+			return fmt.Errorf("insufficient stock in source location. Available: %d, Requested: %d: %w", available, t.Quantity, ErrInvalidState)
+		}
+		if err := s.repo.CreateLocationTransfer(ctx, tx, t); err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.repo, tx, domain.AuditLog{
+			TenantID: t.TenantID, EntityType: domain.AuditEntityLocationTransfer, EntityID: t.ID, Action: domain.AuditActionCreate,
+		}, nil, t)
+	})
+}
+
+// checkLocation verifies that a location belongs to the warehouse and, when stock is put
+// into it, that it is active. A nil location (unlocated stock) is always valid.
+func (s *LocationService) checkLocation(ctx context.Context, tenantID, warehouseID uuid.UUID, locationID *uuid.UUID, inbound bool) error {
+	if locationID == nil {
+		return nil
+	}
+	loc, err := s.repo.GetLocation(ctx, tenantID, *locationID)
+	if err != nil {
+		return err
+	}
+	return checkLocationUse(loc, *locationID, warehouseID, inbound)
+}
+
+// SuggestPutaway proposes a location for the open quantity of every line of a purchase order,
+// in the order's warehouse unless warehouseID is given.
+func (s *LocationService) SuggestPutaway(ctx context.Context, tenantID, orderID uuid.UUID, warehouseID *uuid.UUID) ([]domain.PutawaySuggestion, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	order, err := s.purchases.GetPurchaseOrder(ctx, tenantID, orderID)
+	if err != nil {
+		return nil, err
+	}
+	target := order.WarehouseID
+	if warehouseID != nil {
+		target = *warehouseID
+	}
+
+	suggestions := []domain.PutawaySuggestion{}
+	for _, item := range order.Items {
+		if item.RemainingQty() == 0 {
+			continue
+		}
+		suggestion := domain.PutawaySuggestion{
+			OrderItemID: item.ID,
+			ProductID:   item.ProductID,
+			Quantity:    item.RemainingQty(),
+		}
+		loc, reason, err := s.repo.SuggestPutaway(ctx, tenantID, target, item.ProductID)
+		if err != nil {
+			return nil, err
+		}
+		if loc != nil {
+			suggestion.LocationID = &loc.ID
+			suggestion.LocationPath = &loc.Path
+			suggestion.Reason = reason
+		}
+		suggestions = append(suggestions, suggestion)
+	}
+	return suggestions, nil
+}
+
+// GetInvoicePickList lists what the invoice's sale movements took from each location.
+// Invoices billed from delivery notes move no stock and have an empty pick list.
+func (s *LocationService) GetInvoicePickList(ctx context.Context, tenantID, invoiceID uuid.UUID) (*domain.PickList, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	list, err := s.repo.GetInvoicePickList(ctx, tenantID, invoiceID)
+	if err != nil {
+		return nil, err
+	}
+	if list == nil {
+		return nil, fmt.Errorf("invoice %s: %w", invoiceID, ErrNotFound)
+	}
+	if list.Lines, err = s.repo.ListDocumentPicks(ctx, tenantID, "INVOICE", invoiceID); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// GetSalesOrderPickList plans the picking of an order's undelivered quantity from the
+// locations that hold it, in path order; what they do not cover comes from unlocated stock.
+func (s *LocationService) GetSalesOrderPickList(ctx context.Context, tenantID, orderID uuid.UUID) (*domain.PickList, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	order, err := s.orders.GetSalesOrder(ctx, tenantID, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order.Status != domain.SalesOrderStatusConfirmed && order.Status != domain.SalesOrderStatusPartiallyDelivered {
+		return nil, fmt.Errorf("order %s is %s, only confirmed orders are picked: %w", order.OrderNumber, order.Status, ErrInvalidState)
+	}
+
+	products, err := s.repo.ListSalesOrderOpenProducts(ctx, tenantID, orderID)
+	if err != nil {
+		return nil, err
+	}
+	lines := []domain.PickListLine{}
+	for _, product := range products {
+		locations, err := s.repo.ListProductLocations(ctx, tenantID, product.ProductID, order.WarehouseID)
+		if err != nil {
+			return nil, err
+		}
+		paths := make(map[uuid.UUID]*string, len(locations))
+		for _, loc := range locations {
+			paths[*loc.LocationID] = loc.LocationPath
+		}
+		for _, part := range allocateLocations(locations, []stockAllocation{{quantity: product.Quantity}}) {
+			line := product
+			line.LocationID = part.locationID
+			if part.locationID != nil {
+				line.LocationPath = paths[*part.locationID]
+			}
+			line.Quantity = part.quantity
+			lines = append(lines, line)
+		}
+	}
+	sortPickLines(lines)
+
+	return &domain.PickList{
+		DocumentType:   "SALES_ORDER",
+		DocumentID:     order.ID,
+		DocumentNumber: order.OrderNumber,
+		WarehouseID:    order.WarehouseID,
+		Lines:          lines,
+	}, nil
+}
+
+// allocateLocations splits allocations over the locations holding the product, in path order,
+// so a picker walks the warehouse once; what the locations do not cover comes from unlocated
+// stock. Serial numbers are handed to the parts in the order given.
+func allocateLocations(locations []domain.LocationBalance, allocations []stockAllocation) []stockAllocation {
+	if len(locations) == 0 {
+		return allocations
+	}
+	left := make([]int, len(locations))
+	for i, loc := range locations {
+		left[i] = loc.Quantity
+	}
+
+	var parts []stockAllocation
+	next := 0
+	for _, a := range allocations {
+		need, taken := a.quantity, 0
+		for need > 0 && next < len(locations) {
+			if left[next] <= 0 {
+				next++
+				continue
+			}
+			take := min(need, left[next])
+			parts = append(parts, a.part(locations[next].LocationID, taken, take))
+			left[next] -= take
+			taken += take
+			need -= take
+		}
+		if need > 0 {
+			parts = append(parts, a.part(nil, taken, need))
+		}
+	}
+	return parts
+}
+
+// part takes quantity units of the allocation, starting at offset, from a location.
+func (a stockAllocation) part(locationID *uuid.UUID, offset, quantity int) stockAllocation {
+	p := stockAllocation{lotID: a.lotID, locationID: locationID, quantity: quantity}
+	if a.serials != nil {
+		p.serials = a.serials[offset : offset+quantity]
+	}
+	return p
+}
+
+// checkLocationUse verifies that loc (looked up by locationID) is in the warehouse and, for
+// inbound stock, active.
+func checkLocationUse(loc *domain.WarehouseLocation, locationID, warehouseID uuid.UUID, inbound bool) error {
+	if loc == nil || loc.WarehouseID != warehouseID {
+		return fmt.Errorf("location %s is not in warehouse %s: %w", locationID, warehouseID, ErrInvalidInput)
+	}
+	if inbound && !loc.IsActive {
+		return fmt.Errorf("location %s is inactive: %w", loc.Path, ErrInvalidState)
+	}
+	return nil
+}
+
+// sortPickLines orders pick lines by location path, unlocated stock last.
+func sortPickLines(lines []domain.PickListLine) {
+	sort.SliceStable(lines, func(i, j int) bool {
+		a, b := lines[i].LocationPath, lines[j].LocationPath
+		if a == nil || b == nil {
+			return a != nil && b == nil
+		}
+		return *a < *b
+	})
+}
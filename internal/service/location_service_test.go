@@ -0,0 +1,166 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"sancaksoft/internal/domain"
+	"sancaksoft/internal/repository"
+	"sancaksoft/internal/service"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocationsPutawayAndPickLists_Integration(t *testing.T) {
+	if testDBURL == "" {
+		t.Skip("Skipping integration test: TEST_DB_URL not set")
+	}
+
+	ctx := context.Background()
+	db, err := pgxpool.New(ctx, testDBURL)
+	require.NoError(t, err)
+	defer db.Close()
+
+	// 1. Setup Data
+	tenantID := uuid.New()
+	warehouseID := uuid.New()
+	productID := uuid.New()
+	customerID := uuid.New()
+	supplierID := uuid.New()
+	userID := uuid.New()
+
+	defer func() {
+		_, _ = db.Exec(ctx, "DELETE FROM tenants WHERE id = $1", tenantID)
+	}()
+
+	_, err = db.Exec(ctx, "INSERT INTO tenants (id, name) VALUES ($1, 'Location Test Tenant')", tenantID)
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, "INSERT INTO warehouses (id, tenant_id, name) VALUES ($1, $2, 'Main')", warehouseID, tenantID)
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, "INSERT INTO products (id, tenant_id, name, sku, price) VALUES ($1, $2, 'Bolt', $3, 2.00)", productID, tenantID, "SKU-"+uuid.New().String())
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, "INSERT INTO customers (id, tenant_id, name) VALUES ($1, $2, 'Workshop')", customerID, tenantID)
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, "INSERT INTO suppliers (id, tenant_id, name) VALUES ($1, $2, 'Fasteners Inc')", supplierID, tenantID)
+	require.NoError(t, err)
+
+	stockService := service.NewStockService(db, repository.NewStockRepository(db))
+	invoiceService := service.NewInvoiceService(db, repository.NewInvoiceRepository())
+	orderService := service.NewSalesOrderService(db, repository.NewSalesOrderRepository(db), invoiceService)
+	purchaseService := service.NewPurchaseOrderService(db, repository.NewPurchaseOrderRepository(db))
+	locationService := service.NewLocationService(db, repository.NewLocationRepository(db), purchaseService, orderService)
+
+	// 2. Location tree: aisle A with bins A/01 and A/02
+	aisle := &domain.WarehouseLocation{TenantID: tenantID, WarehouseID: warehouseID, Code: "A"}
+	require.NoError(t, locationService.CreateLocation(ctx, aisle))
+	bin1 := &domain.WarehouseLocation{TenantID: tenantID, WarehouseID: warehouseID, Code: "01", ParentID: &aisle.ID}
+	require.NoError(t, locationService.CreateLocation(ctx, bin1))
+	bin2 := &domain.WarehouseLocation{TenantID: tenantID, WarehouseID: warehouseID, Code: "02", ParentID: &aisle.ID}
+	require.NoError(t, locationService.CreateLocation(ctx, bin2))
+	assert.Equal(t, "A/02", bin2.Path)
+	err = locationService.CreateLocation(ctx, &domain.WarehouseLocation{TenantID: tenantID, WarehouseID: warehouseID, Code: "01", ParentID: &aisle.ID})
+	assert.ErrorIs(t, err, service.ErrInvalidState)
+
+	// 3. An empty warehouse suggests the first empty bin
+	po, err := purchaseService.CreatePurchaseOrder(ctx, domain.CreatePurchaseOrderRequest{
+		TenantID:    tenantID,
+		UserID:      userID,
+		SupplierID:  supplierID,
+		WarehouseID: warehouseID,
+		Items:       []domain.PurchaseOrderItemRequest{{ProductID: productID, Quantity: 20, UnitPrice: decimal.NewFromInt(1)}},
+	})
+	require.NoError(t, err)
+	suggestions, err := locationService.SuggestPutaway(ctx, tenantID, po.ID, nil)
+	require.NoError(t, err)
+	require.Len(t, suggestions, 1)
+	assert.Equal(t, bin1.ID, *suggestions[0].LocationID)
+	assert.Equal(t, domain.PutawayReasonEmptyLocation, suggestions[0].Reason)
+
+	// 4. Receipts into bins and unlocated stock; inactive bins take nothing
+	receive := func(qty int, location *uuid.UUID) error {
+		return stockService.CreateStockMovement(ctx, tenantID, &domain.StockMovement{
+			ID: uuid.New(), ProductID: productID, WarehouseID: warehouseID, Quantity: qty, Type: domain.StockMovementTypeIn, LocationID: location,
+		})
+	}
+	require.NoError(t, receive(3, &bin1.ID))
+	require.NoError(t, receive(10, &bin2.ID))
+	require.NoError(t, receive(5, nil))
+	require.NoError(t, locationService.SetLocationActive(ctx, tenantID, bin1.ID, false))
+	assert.ErrorIs(t, receive(1, &bin1.ID), service.ErrInvalidState)
+	require.NoError(t, locationService.SetLocationActive(ctx, tenantID, bin1.ID, true))
+
+	suggestions, err = locationService.SuggestPutaway(ctx, tenantID, po.ID, nil)
+	require.NoError(t, err)
+	assert.Equal(t, bin2.ID, *suggestions[0].LocationID)
+	assert.Equal(t, domain.PutawayReasonSameProduct, suggestions[0].Reason)
+
+	// 5. Unlocated OUT is limited to unlocated stock; transfers move stock between bins
+	err = stockService.CreateStockMovement(ctx, tenantID, &domain.StockMovement{
+		ID: uuid.New(), ProductID: productID, WarehouseID: warehouseID, Quantity: -6, Type: domain.StockMovementTypeOut,
+	})
+	assert.ErrorIs(t, err, service.ErrInvalidState)
+	require.NoError(t, locationService.TransferStock(ctx, &domain.LocationTransfer{
+		TenantID: tenantID, WarehouseID: warehouseID, ProductID: productID, FromLocationID: &bin2.ID, ToLocationID: &bin1.ID, Quantity: 2, UserID: userID,
+	}))
+	err = locationService.TransferStock(ctx, &domain.LocationTransfer{
+		TenantID: tenantID, WarehouseID: warehouseID, ProductID: productID, FromLocationID: &bin1.ID, Quantity: 6, UserID: userID,
+	})
+	assert.ErrorIs(t, err, service.ErrInvalidState)
+
+	balances, err := locationService.ListLocationBalances(ctx, tenantID, warehouseID, &productID)
+	require.NoError(t, err)
+	require.Len(t, balances, 3)
+	assert.Equal(t, "A/01", *balances[0].LocationPath)
+	assert.Equal(t, 5, balances[0].Quantity)
+	assert.Equal(t, 8, balances[1].Quantity)
+	assert.Nil(t, balances[2].LocationID)
+	assert.Equal(t, 5, balances[2].Quantity)
+
+	// 6. A sales order plans picking in path order, unlocated stock last
+	order, err := orderService.CreateSalesOrder(ctx, domain.CreateSalesOrderRequest{
+		TenantID:    tenantID,
+		UserID:      userID,
+		CustomerID:  customerID,
+		WarehouseID: warehouseID,
+		Items:       []domain.SalesOrderItemRequest{{ProductID: productID, Quantity: 15, UnitPrice: decimal.NewFromInt(2)}},
+	})
+	require.NoError(t, err)
+	_, err = orderService.ConfirmSalesOrder(ctx, tenantID, order.ID)
+	require.NoError(t, err)
+	plan, err := locationService.GetSalesOrderPickList(ctx, tenantID, order.ID)
+	require.NoError(t, err)
+	require.Len(t, plan.Lines, 3)
+	assert.Equal(t, 5, plan.Lines[0].Quantity)
+	assert.Equal(t, "A/02", *plan.Lines[1].LocationPath)
+	assert.Equal(t, 8, plan.Lines[1].Quantity)
+	assert.Nil(t, plan.Lines[2].LocationID)
+	assert.Equal(t, 2, plan.Lines[2].Quantity)
+
+	// 7. Invoicing the order takes stock the same way and its pick list shows where from
+	invoice, err := orderService.InvoiceSalesOrder(ctx, domain.InvoiceSalesOrderRequest{
+		TenantID:       tenantID,
+		UserID:         userID,
+		OrderID:        order.ID,
+		IdempotencyKey: uuid.New(),
+		Lines:          []domain.SalesOrderDeliveryLine{{OrderItemID: order.Items[0].ID, Quantity: 7}},
+	})
+	require.NoError(t, err)
+	picks, err := locationService.GetInvoicePickList(ctx, tenantID, invoice.ID)
+	require.NoError(t, err)
+	assert.Equal(t, invoice.InvoiceNumber, picks.DocumentNumber)
+	require.Len(t, picks.Lines, 2)
+	assert.Equal(t, bin1.ID, *picks.Lines[0].LocationID)
+	assert.Equal(t, 5, picks.Lines[0].Quantity)
+	assert.Equal(t, bin2.ID, *picks.Lines[1].LocationID)
+	assert.Equal(t, 2, picks.Lines[1].Quantity)
+
+	plan, err = locationService.GetSalesOrderPickList(ctx, tenantID, order.ID)
+	require.NoError(t, err)
+	require.Len(t, plan.Lines, 2)
+	assert.Equal(t, 6, plan.Lines[0].Quantity)
+	assert.Equal(t, 2, plan.Lines[1].Quantity)
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"sancaksoft/internal/domain"
+	"sancaksoft/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// Lot balances are kept per lot and warehouse by the same trigger that maintains
+// stock_balances. Stock of a lot-tracked product that has no lot (received before tracking
+// was enabled, or restocked by a customer return) only shows in the product balance.
+type LotService struct {
+	repo *repository.LotRepository
+}
+
+func NewLotService(repo *repository.LotRepository) *LotService {
+	return &LotService{repo: repo}
+}
+
+// ListLotBalances returns on-hand stock per lot and warehouse.
+func (s *LotService) ListLotBalances(ctx context.Context, tenantID uuid.UUID, productID, warehouseID *uuid.UUID) ([]domain.LotBalance, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	return s.repo.ListLotBalances(ctx, tenantID, productID, warehouseID)
+}
+
+// GetExpiryReport returns lots in stock that have expired or expire within days,
+// optionally only those with the given status.
+func (s *LotService) GetExpiryReport(ctx context.Context, tenantID uuid.UUID, warehouseID *uuid.UUID, days int, status domain.LotExpiryStatus) ([]domain.LotExpiryLine, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	if days < 0 {
+		return nil, fmt.Errorf("days cannot be negative: %w", ErrInvalidInput)
+	}
+	if status != "" && status != domain.LotExpiryStatusExpired && status != domain.LotExpiryStatusNearExpiry {
+		return nil, fmt.Errorf("status must be EXPIRED or NEAR_EXPIRY: %w", ErrInvalidInput)
+	}
+
+	balances, err := s.repo.ListExpiringLots(ctx, tenantID, warehouseID, days)
+	if err != nil {
+		return nil, err
+	}
+	lines := []domain.LotExpiryLine{}
+	for _, b := range balances {
+		line := domain.LotExpiryLine{LotBalance: b, Status: domain.LotExpiryStatusNearExpiry}
+		if b.Expired() {
+			line.Status = domain.LotExpiryStatusExpired
+		}
+		if status != "" && line.Status != status {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, nil
+}
+
+// TraceLot lists the customers that received a lot, for recalls.
+func (s *LotService) TraceLot(ctx context.Context, tenantID, lotID uuid.UUID) (*domain.LotTrace, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	trace, err := s.repo.GetLotTrace(ctx, tenantID, lotID)
+	if err != nil {
+		return nil, err
+	}
+	if trace == nil {
+		return nil, fmt.Errorf("lot %s: %w", lotID, ErrNotFound)
+	}
+	return trace, nil
+}
+
+// stockAllocation is the part of an outbound quantity taken from one lot and location. A nil
+// lotID takes stock of the product that has no lot, a nil locationID unlocated stock.
+// Serialized products, which are never lot-tracked, name the units of each part.
+type stockAllocation struct {
+	lotID      *uuid.UUID
+	locationID *uuid.UUID
+	quantity   int
+	serials    []string
+}
+
+// allocateLots splits an outbound quantity of a lot-tracked product. A named lot must cover
+// the whole quantity and must not have expired. Otherwise unexpired lots are consumed first
+// expiry first out (lots is expected in that order), followed by stock without a lot.
+// onHand is the product's balance in the warehouse, lots included.
+func allocateLots(lots []domain.LotBalance, onHand, quantity int, lotNumber string) ([]stockAllocation, error) {
+	if lotNumber != "" {
+		for _, lot := range lots {
+			if lot.LotNumber != lotNumber {
+				continue
+			}
+			if lot.Expired() {
+				return nil, fmt.Errorf("lot %s expired on %s: %w", lotNumber, lot.ExpiryDate.Format("2006-01-02"), ErrInvalidState)
+			}
+			if lot.Quantity < quantity {
+				return nil, fmt.Errorf("insufficient stock in lot %s. Available: %d, Requested: %d: %w", lotNumber, lot.Quantity, quantity, ErrInvalidState)
+			}
+			return []stockAllocation{{lotID: &lot.LotID, quantity: quantity}}, nil
+		}
+		return nil, fmt.Errorf("insufficient stock in lot %s. Available: 0, Requested: %d: %w", lotNumber, quantity, ErrInvalidState)
+	}
+
+	var allocations []stockAllocation
+	need := quantity
+	unassigned := onHand
+	for _, lot := range lots {
+		unassigned -= lot.Quantity
+		if need == 0 || lot.Expired() {
+			continue
+		}
+		take := min(need, lot.Quantity)
+		allocations = append(allocations, stockAllocation{lotID: &lot.LotID, quantity: take})
+		need -= take
+	}
+	if need > 0 && unassigned > 0 {
+		take := min(need, unassigned)
+		allocations = append(allocations, stockAllocation{quantity: take})
+		need -= take
+	}
+	if need > 0 {
+		return nil, fmt.Errorf("insufficient unexpired lot stock. Available: %d, Requested: %d: %w", quantity-need, quantity, ErrInvalidState)
+	}
+	return allocations, nil
+}
+
+// checkLotExpiry rejects a receipt whose expiry date contradicts the one recorded on the lot.
+func checkLotExpiry(lot *domain.Lot, expiryDate *time.Time) error {
+	if expiryDate == nil || lot.ExpiryDate == nil || lot.ExpiryDate.Equal(*expiryDate) {
+		return nil
+	}
+	return fmt.Errorf("lot %s already has expiry date %s: %w", lot.LotNumber, lot.ExpiryDate.Format("2006-01-02"), ErrInvalidInput)
+}
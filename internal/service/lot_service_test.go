@@ -0,0 +1,142 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"sancaksoft/internal/domain"
+	"sancaksoft/internal/repository"
+	"sancaksoft/internal/service"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLotTrackingFEFOAndTrace_Integration(t *testing.T) {
+	if testDBURL == "" {
+		t.Skip("Skipping integration test: TEST_DB_URL not set")
+	}
+
+	ctx := context.Background()
+	db, err := pgxpool.New(ctx, testDBURL)
+	require.NoError(t, err)
+	defer db.Close()
+
+	// 1. Setup Data
+	tenantID := uuid.New()
+	warehouseID := uuid.New()
+	productID := uuid.New()
+	customerID := uuid.New()
+	userID := uuid.New()
+
+	defer func() {
+		_, _ = db.Exec(ctx, "DELETE FROM tenants WHERE id = $1", tenantID)
+	}()
+
+	_, err = db.Exec(ctx, "INSERT INTO tenants (id, name) VALUES ($1, 'Lot Test Tenant')", tenantID)
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, "INSERT INTO warehouses (id, tenant_id, name) VALUES ($1, $2, 'Main')", warehouseID, tenantID)
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, "INSERT INTO products (id, tenant_id, name, sku, price, track_lots) VALUES ($1, $2, 'Yoghurt', $3, 4.00, TRUE)", productID, tenantID, "SKU-"+uuid.New().String())
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, "INSERT INTO customers (id, tenant_id, name) VALUES ($1, $2, 'Market')", customerID, tenantID)
+	require.NoError(t, err)
+
+	stockService := service.NewStockService(db, repository.NewStockRepository(db))
+	invoiceService := service.NewInvoiceService(db, repository.NewInvoiceRepository())
+	noteService := service.NewDeliveryNoteService(db, repository.NewDeliveryNoteRepository(db), invoiceService)
+	lotService := service.NewLotService(repository.NewLotRepository(db))
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	receive := func(lotNumber string, expiry time.Time, qty int) {
+		require.NoError(t, stockService.CreateStockMovement(ctx, tenantID, &domain.StockMovement{
+			ID: uuid.New(), ProductID: productID, WarehouseID: warehouseID, Quantity: qty, Type: domain.StockMovementTypeIn,
+			LotNumber: &lotNumber, ExpiryDate: &expiry,
+		}))
+	}
+
+	// 2. Lot numbers are required; one expired and two good lots
+	err = stockService.CreateStockMovement(ctx, tenantID, &domain.StockMovement{
+		ID: uuid.New(), ProductID: productID, WarehouseID: warehouseID, Quantity: 1, Type: domain.StockMovementTypeIn,
+	})
+	assert.ErrorIs(t, err, service.ErrInvalidInput)
+	receive("L-OLD", today.AddDate(0, 0, -1), 4)
+	receive("L-SOON", today.AddDate(0, 0, 10), 8)
+	receive("L-LATE", today.AddDate(0, 0, 60), 10)
+
+	// 3. FEFO skips the expired lot and splits the sale over the next two
+	invoice, err := invoiceService.CreateInvoice(ctx, domain.CreateInvoiceRequest{
+		TenantID:    tenantID,
+		UserID:      userID,
+		CustomerID:  customerID,
+		WarehouseID: warehouseID,
+		Items:       []domain.InvoiceItemRequest{{ProductID: productID, Quantity: 11, UnitPrice: decimal.NewFromInt(4)}},
+	})
+	require.NoError(t, err)
+
+	balances, err := lotService.ListLotBalances(ctx, tenantID, &productID, nil)
+	require.NoError(t, err)
+	byLot := map[string]domain.LotBalance{}
+	for _, b := range balances {
+		byLot[b.LotNumber] = b
+	}
+	assert.Equal(t, 4, byLot["L-OLD"].Quantity)
+	assert.NotContains(t, byLot, "L-SOON")
+	assert.Equal(t, 7, byLot["L-LATE"].Quantity)
+
+	// 4. An expired lot cannot be sold by name
+	_, err = invoiceService.CreateInvoice(ctx, domain.CreateInvoiceRequest{
+		TenantID:    tenantID,
+		UserID:      userID,
+		CustomerID:  customerID,
+		WarehouseID: warehouseID,
+		Items:       []domain.InvoiceItemRequest{{ProductID: productID, Quantity: 1, UnitPrice: decimal.NewFromInt(4), LotNumber: "L-OLD"}},
+	})
+	assert.ErrorIs(t, err, service.ErrInvalidState)
+
+	// 5. Expiry report
+	report, err := lotService.GetExpiryReport(ctx, tenantID, nil, 30, "")
+	require.NoError(t, err)
+	require.Len(t, report, 1)
+	assert.Equal(t, "L-OLD", report[0].LotNumber)
+	assert.Equal(t, domain.LotExpiryStatusExpired, report[0].Status)
+
+	// 6. Recall trace follows invoices and delivery notes, net of cancellations
+	note, err := noteService.CreateDeliveryNote(ctx, domain.CreateDeliveryNoteRequest{
+		TenantID:    tenantID,
+		UserID:      userID,
+		CustomerID:  customerID,
+		WarehouseID: warehouseID,
+		Items:       []domain.DeliveryNoteItemRequest{{ProductID: productID, Quantity: 2, UnitPrice: decimal.NewFromInt(4), LotNumber: "L-LATE"}},
+	})
+	require.NoError(t, err)
+
+	lateID := byLot["L-LATE"].LotID
+	trace, err := lotService.TraceLot(ctx, tenantID, lateID)
+	require.NoError(t, err)
+	assert.Equal(t, 5, trace.TotalShipped)
+	require.Len(t, trace.Shipments, 2)
+	assert.Equal(t, invoice.InvoiceNumber, trace.Shipments[0].DocumentNumber)
+	assert.Equal(t, 3, trace.Shipments[0].Quantity)
+	assert.Equal(t, "Market", trace.Shipments[1].CustomerName)
+
+	_, err = noteService.CancelDeliveryNote(ctx, tenantID, note.ID)
+	require.NoError(t, err)
+	trace, err = lotService.TraceLot(ctx, tenantID, lateID)
+	require.NoError(t, err)
+	assert.Equal(t, 3, trace.TotalShipped)
+	require.Len(t, trace.Shipments, 1)
+
+	// 7. The expired lot is written off with a named OUT movement
+	oldLot := "L-OLD"
+	require.NoError(t, stockService.CreateStockMovement(ctx, tenantID, &domain.StockMovement{
+		ID: uuid.New(), ProductID: productID, WarehouseID: warehouseID, Quantity: -4, Type: domain.StockMovementTypeOut, LotNumber: &oldLot,
+	}))
+	report, err = lotService.GetExpiryReport(ctx, tenantID, nil, 30, domain.LotExpiryStatusExpired)
+	require.NoError(t, err)
+	assert.Empty(t, report)
+}
@@ -3,20 +3,25 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
 	"time"
 
 	"sancaksoft/internal/domain"
 	"sancaksoft/internal/repository"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shopspring/decimal"
 )
 
 type ProductService struct {
+	db   *pgxpool.Pool
 	repo *repository.ProductRepository
 }
 
-func NewProductService(repo *repository.ProductRepository) *ProductService {
-	return &ProductService{repo: repo}
+func NewProductService(db *pgxpool.Pool, repo *repository.ProductRepository) *ProductService {
+	return &ProductService{db: db, repo: repo}
 }
 
 func (s *ProductService) CreateProduct(ctx context.Context, p *domain.Product) error {
@@ -32,21 +37,159 @@ func (s *ProductService) CreateProduct(ctx context.Context, p *domain.Product) e
 	if p.Unit != domain.ProductUnitPiece && p.Unit != domain.ProductUnitKg {
 		return errors.New("unit must be 'adet' or 'kg'")
 	}
+	if p.StandardCost != nil && p.StandardCost.IsNegative() {
+		return fmt.Errorf("standard cost cannot be negative: %w", ErrInvalidInput)
+	}
+	if p.TrackLots && p.TrackSerials {
+		return fmt.Errorf("a product cannot track both lots and serial numbers: %w", ErrInvalidInput)
+	}
+	if p.CategoryID != nil || p.BrandID != nil {
+		exists, err := s.repo.ClassificationExists(ctx, p.TenantID, p.CategoryID, p.BrandID)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return fmt.Errorf("category or brand not found: %w", ErrInvalidInput)
+		}
+	}
 
 	p.ID = uuid.New()
-	return s.repo.CreateProduct(ctx, p)
+	return WithTransaction(ctx, s.db, func(tx pgx.Tx) error {
+		if err := s.repo.CreateProduct(ctx, tx, p); err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.repo, tx, domain.AuditLog{
+			TenantID: p.TenantID, EntityType: domain.AuditEntityProduct, EntityID: p.ID, Action: domain.AuditActionCreate,
+		}, nil, p)
+	})
 }
 
 func (s *ProductService) GetProduct(ctx context.Context, tenantID, productID uuid.UUID) (*domain.Product, error) {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	return s.repo.GetProductByID(ctx, tenantID, productID)
+	product, err := s.repo.GetProductByID(ctx, tenantID, productID)
+	if err != nil || product == nil {
+		return product, err
+	}
+	products := []domain.Product{*product}
+	if err := s.loadAttributes(ctx, tenantID, products); err != nil {
+		return nil, err
+	}
+	return &products[0], nil
+}
+
+// ListProducts returns products narrowed by category (subcategories included), brand and
+// attribute values, each with its attribute values.
+func (s *ProductService) ListProducts(ctx context.Context, filter domain.ProductFilter) ([]domain.Product, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	products, err := s.repo.ListProducts(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.loadAttributes(ctx, filter.TenantID, products); err != nil {
+		return nil, err
+	}
+	return products, nil
+}
+
+func (s *ProductService) loadAttributes(ctx context.Context, tenantID uuid.UUID, products []domain.Product) error {
+	if len(products) == 0 {
+		return nil
+	}
+	ids := make([]uuid.UUID, len(products))
+	for i, p := range products {
+		ids[i] = p.ID
+	}
+	values, err := s.repo.ListProductAttributes(ctx, tenantID, ids)
+	if err != nil {
+		return err
+	}
+	for i := range products {
+		products[i].Attributes = values[products[i].ID]
+	}
+	return nil
+}
+
+// SetStandardCost sets the cost captured on future sales of the product; nil falls back to
+// the last purchase cost. Lines already invoiced keep the cost they were sold at.
+func (s *ProductService) SetStandardCost(ctx context.Context, tenantID, productID uuid.UUID, cost *decimal.Decimal) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if cost != nil && cost.IsNegative() {
+		return fmt.Errorf("standard cost cannot be negative: %w", ErrInvalidInput)
+	}
+	return s.updateProduct(ctx, tenantID, productID, func(tx pgx.Tx, product *domain.Product) (bool, error) {
+		product.StandardCost = cost
+		return s.repo.SetStandardCost(ctx, tx, tenantID, productID, cost)
+	})
+}
+
+// updateProduct runs fn on the locked product and audits the fields it changed with the
+// change itself. fn applies the change to the product and writes it, reporting whether the
+// product was still there.
+func (s *ProductService) updateProduct(ctx context.Context, tenantID, productID uuid.UUID, fn func(tx pgx.Tx, product *domain.Product) (bool, error)) error {
+	return WithTransaction(ctx, s.db, func(tx pgx.Tx) error {
+		product, err := s.repo.LockProduct(ctx, tx, tenantID, productID)
+		if err != nil {
+			return err
+		}
+		if product == nil {
+			return fmt.Errorf("product %s: %w", productID, ErrNotFound)
+		}
+		before := *product
+		found, err := fn(tx, product)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return fmt.Errorf("product %s: %w", productID, ErrNotFound)
+		}
+		return recordAudit(ctx, s.repo, tx, domain.AuditLog{
+			TenantID: tenantID, EntityType: domain.AuditEntityProduct, EntityID: productID, Action: domain.AuditActionUpdate,
+		}, &before, product)
+	})
+}
+
+// SetLotTracking turns lot tracking of a product on or off. Stock received before tracking
+// was enabled stays unassigned and is sold after the product's unexpired lots.
+func (s *ProductService) SetLotTracking(ctx context.Context, tenantID, productID uuid.UUID, trackLots bool) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	return s.updateProduct(ctx, tenantID, productID, func(tx pgx.Tx, product *domain.Product) (bool, error) {
+		if trackLots && product.TrackSerials {
+			return false, fmt.Errorf("product %s tracks serial numbers: %w", productID, ErrInvalidState)
+		}
+		product.TrackLots = trackLots
+		return s.repo.SetTrackLots(ctx, tx, tenantID, productID, trackLots)
+	})
 }
 
-func (s *ProductService) ListProducts(ctx context.Context, tenantID uuid.UUID) ([]domain.Product, error) {
+// SetSerialTracking turns serial number tracking of a product on or off. It can only be
+// turned on while the product has no stock, since units already on hand have no serial
+// numbers to sell them by, and not for lot-tracked products.
+func (s *ProductService) SetSerialTracking(ctx context.Context, tenantID, productID uuid.UUID, trackSerials bool) error {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	return s.repo.ListProducts(ctx, tenantID)
+	return s.updateProduct(ctx, tenantID, productID, func(tx pgx.Tx, product *domain.Product) (bool, error) {
+		if trackSerials {
+			if product.TrackLots {
+				return false, fmt.Errorf("product %s tracks lots: %w", productID, ErrInvalidState)
+			}
+			hasStock, err := s.repo.HasStock(ctx, tx, tenantID, productID)
+			if err != nil {
+				return false, err
+			}
+			if hasStock {
+				return false, fmt.Errorf("product %s has stock without serial numbers: %w", productID, ErrInvalidState)
+			}
+		}
+		product.TrackSerials = trackSerials
+		return s.repo.SetTrackSerials(ctx, tx, tenantID, productID, trackSerials)
+	})
 }
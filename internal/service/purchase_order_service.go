@@ -0,0 +1,585 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"sancaksoft/internal/domain"
+	"sancaksoft/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shopspring/decimal"
+)
+
+type PurchaseOrderService struct {
+	db   *pgxpool.Pool
+	repo *repository.PurchaseOrderRepository
+}
+
+func NewPurchaseOrderService(db *pgxpool.Pool, repo *repository.PurchaseOrderRepository) *PurchaseOrderService {
+	return &PurchaseOrderService{db: db, repo: repo}
+}
+
+// CreatePurchaseOrder saves a DRAFT purchase order. Drafts already count as on order so
+// replenishment does not suggest the same quantity twice.
+func (s *PurchaseOrderService) CreatePurchaseOrder(ctx context.Context, req domain.CreatePurchaseOrderRequest) (*domain.PurchaseOrder, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	var created *domain.PurchaseOrder
+	err := WithTransaction(ctx, s.db, func(tx pgx.Tx) error {
+		order, err := s.createPurchaseOrderTx(ctx, tx, req)
+		if err != nil {
+			return err
+		}
+		created = order
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+func (s *PurchaseOrderService) createPurchaseOrderTx(ctx context.Context, tx pgx.Tx, req domain.CreatePurchaseOrderRequest) (*domain.PurchaseOrder, error) {
+	if len(req.Items) == 0 {
+		return nil, fmt.Errorf("purchase order must have at least one item: %w", ErrInvalidInput)
+	}
+	for _, item := range req.Items {
+		if item.Quantity <= 0 {
+			return nil, fmt.Errorf("quantity must be greater than zero: %w", ErrInvalidInput)
+		}
+		if item.UnitPrice.IsNegative() {
+			return nil, fmt.Errorf("unit price cannot be negative: %w", ErrInvalidInput)
+		}
+	}
+
+	exists, err := s.repo.SupplierExists(ctx, tx, req.TenantID, req.SupplierID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, fmt.Errorf("supplier %s: %w", req.SupplierID, ErrNotFound)
+	}
+	if err := s.checkWarehouse(ctx, tx, req.TenantID, req.WarehouseID); err != nil {
+		return nil, err
+	}
+	for _, item := range req.Items {
+		if err := s.checkOrderProduct(ctx, tx, req.TenantID, item.ProductID); err != nil {
+			return nil, err
+		}
+	}
+
+	orderNumber, err := s.repo.GenerateNextOrderNumber(ctx, tx, req.TenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	order := &domain.PurchaseOrder{
+		ID:           uuid.New(),
+		TenantID:     req.TenantID,
+		SupplierID:   req.SupplierID,
+		WarehouseID:  req.WarehouseID,
+		OrderNumber:  orderNumber,
+		Status:       domain.PurchaseOrderStatusDraft,
+		ExpectedDate: req.ExpectedDate,
+		Note:         req.Note,
+	}
+	for _, itemReq := range req.Items {
+		order.TotalAmount = order.TotalAmount.Add(itemReq.UnitPrice.Mul(decimal.NewFromInt(int64(itemReq.Quantity))))
+	}
+	if err := s.repo.CreatePurchaseOrder(ctx, tx, order); err != nil {
+		return nil, fmt.Errorf("failed to create purchase order: %w", err)
+	}
+
+	for _, itemReq := range req.Items {
+		item := domain.PurchaseOrderItem{
+			ID:        uuid.New(),
+			TenantID:  req.TenantID,
+			OrderID:   order.ID,
+			ProductID: itemReq.ProductID,
+			Quantity:  itemReq.Quantity,
+			UnitPrice: itemReq.UnitPrice,
+			Total:     itemReq.UnitPrice.Mul(decimal.NewFromInt(int64(itemReq.Quantity))),
+		}
+		if err := s.repo.CreatePurchaseOrderItem(ctx, tx, &item); err != nil {
+			return nil, fmt.Errorf("failed to create purchase order item: %w", err)
+		}
+		order.Items = append(order.Items, item)
+	}
+	if err := recordAudit(ctx, s.repo, tx, domain.AuditLog{
+		TenantID: order.TenantID, EntityType: domain.AuditEntityPurchaseOrder, EntityID: order.ID, Action: domain.AuditActionCreate,
+	}, nil, order); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+func (s *PurchaseOrderService) checkWarehouse(ctx context.Context, tx pgx.Tx, tenantID, warehouseID uuid.UUID) error {
+	exists, err := s.repo.WarehouseExists(ctx, tx, tenantID, warehouseID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("warehouse %s: %w", warehouseID, ErrNotFound)
+	}
+	return nil
+}
+
+// checkOrderProduct accepts only products of the tenant that hold their own stock.
+func (s *PurchaseOrderService) checkOrderProduct(ctx context.Context, tx pgx.Tx, tenantID, productID uuid.UUID) error {
+	exists, err := s.repo.ProductExists(ctx, tx, tenantID, productID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("product %s: %w", productID, ErrNotFound)
+	}
+	parent, err := s.repo.ProductIsVariantParent(ctx, tx, tenantID, productID)
+	if err != nil {
+		return err
+	}
+	if parent {
+		return fmt.Errorf("product %s has variants; order the variants instead: %w", productID, ErrInvalidInput)
+	}
+	kit, err := s.repo.ProductIsKit(ctx, tx, tenantID, productID)
+	if err != nil {
+		return err
+	}
+	if kit {
+		return fmt.Errorf("product %s is a kit; order its components instead: %w", productID, ErrInvalidInput)
+	}
+	return nil
+}
+
+// ConfirmPurchaseOrder marks a draft as sent to the supplier; its lines can now be received.
+func (s *PurchaseOrderService) ConfirmPurchaseOrder(ctx context.Context, tenantID, orderID uuid.UUID) (*domain.PurchaseOrder, error) {
+	return s.withOrder(ctx, tenantID, orderID, domain.AuditActionConfirm, func(tx pgx.Tx, order *domain.PurchaseOrder) error {
+		if order.Status != domain.PurchaseOrderStatusDraft {
+			return fmt.Errorf("purchase order is %s, expected %s: %w", order.Status, domain.PurchaseOrderStatusDraft, ErrInvalidState)
+		}
+		now := time.Now()
+		order.Status = domain.PurchaseOrderStatusOrdered
+		order.OrderedAt = &now
+		return s.repo.UpdatePurchaseOrderStatus(ctx, tx, order)
+	})
+}
+
+// ClosePurchaseOrder short-closes an order; nothing more is expected from the supplier.
+func (s *PurchaseOrderService) ClosePurchaseOrder(ctx context.Context, tenantID, orderID uuid.UUID) (*domain.PurchaseOrder, error) {
+	return s.withOrder(ctx, tenantID, orderID, domain.AuditActionClose, func(tx pgx.Tx, order *domain.PurchaseOrder) error {
+		if order.Status != domain.PurchaseOrderStatusOrdered && order.Status != domain.PurchaseOrderStatusPartiallyReceived {
+			return fmt.Errorf("purchase order is %s and cannot be closed: %w", order.Status, ErrInvalidState)
+		}
+		now := time.Now()
+		order.Status = domain.PurchaseOrderStatusClosed
+		order.ClosedAt = &now
+		return s.repo.UpdatePurchaseOrderStatus(ctx, tx, order)
+	})
+}
+
+// CancelPurchaseOrder cancels an order that has received nothing yet.
+func (s *PurchaseOrderService) CancelPurchaseOrder(ctx context.Context, tenantID, orderID uuid.UUID) (*domain.PurchaseOrder, error) {
+	return s.withOrder(ctx, tenantID, orderID, domain.AuditActionCancel, func(tx pgx.Tx, order *domain.PurchaseOrder) error {
+		if order.Status != domain.PurchaseOrderStatusDraft && order.Status != domain.PurchaseOrderStatusOrdered {
+			return fmt.Errorf("purchase order is %s and cannot be cancelled: %w", order.Status, ErrInvalidState)
+		}
+		now := time.Now()
+		order.Status = domain.PurchaseOrderStatusCancelled
+		order.ClosedAt = &now
+		return s.repo.UpdatePurchaseOrderStatus(ctx, tx, order)
+	})
+}
+
+// ReceiveGoods records a goods receipt against the order. Each line writes an IN
+// movement at the order price; over-deliveries are accepted and reported by matching.
+func (s *PurchaseOrderService) ReceiveGoods(ctx context.Context, req domain.CreateGoodsReceiptRequest) (*domain.GoodsReceipt, error) {
+	if len(req.Lines) == 0 {
+		return nil, fmt.Errorf("goods receipt must have at least one line: %w", ErrInvalidInput)
+	}
+
+	var receipt *domain.GoodsReceipt
+	_, err := s.withOrder(ctx, req.TenantID, req.OrderID, domain.AuditActionReceive, func(tx pgx.Tx, order *domain.PurchaseOrder) error {
+		if order.Status != domain.PurchaseOrderStatusOrdered && order.Status != domain.PurchaseOrderStatusPartiallyReceived {
+			return fmt.Errorf("purchase order is %s and cannot be received: %w", order.Status, ErrInvalidState)
+		}
+
+		itemsByID := make(map[uuid.UUID]*domain.PurchaseOrderItem, len(order.Items))
+		for i := range order.Items {
+			itemsByID[order.Items[i].ID] = &order.Items[i]
+		}
+
+		receiptNumber, err := s.repo.GenerateNextReceiptNumber(ctx, tx, req.TenantID)
+		if err != nil {
+			return err
+		}
+		gr := &domain.GoodsReceipt{
+			ID:            uuid.New(),
+			TenantID:      req.TenantID,
+			OrderID:       order.ID,
+			WarehouseID:   order.WarehouseID,
+			ReceiptNumber: receiptNumber,
+			Note:          req.Note,
+		}
+		if req.WarehouseID != nil && *req.WarehouseID != order.WarehouseID {
+			if err := s.checkWarehouse(ctx, tx, req.TenantID, *req.WarehouseID); err != nil {
+				return err
+			}
+			gr.WarehouseID = *req.WarehouseID
+		}
+		if err := s.repo.CreateGoodsReceipt(ctx, tx, gr); err != nil {
+			return fmt.Errorf("failed to create goods receipt: %w", err)
+		}
+
+		for _, line := range req.Lines {
+			item, ok := itemsByID[line.OrderItemID]
+			if !ok {
+				return fmt.Errorf("purchase order item %s: %w", line.OrderItemID, ErrNotFound)
+			}
+			if line.Quantity <= 0 {
+				return fmt.Errorf("quantity must be greater than zero: %w", ErrInvalidInput)
+			}
+
+			grItem := domain.GoodsReceiptItem{
+				ID:          uuid.New(),
+				TenantID:    req.TenantID,
+				ReceiptID:   gr.ID,
+				OrderItemID: item.ID,
+				ProductID:   item.ProductID,
+				Quantity:    line.Quantity,
+				UnitCost:    item.UnitPrice,
+			}
+			if err := s.repo.CreateGoodsReceiptItem(ctx, tx, &grItem); err != nil {
+				return fmt.Errorf("failed to create goods receipt item: %w", err)
+			}
+			if err := s.repo.AddReceivedQuantity(ctx, tx, req.TenantID, item.ID, line.Quantity); err != nil {
+				return fmt.Errorf("failed to update received quantity: %w", err)
+			}
+			item.ReceivedQty += line.Quantity
+
+			parent, err := s.repo.ProductIsVariantParent(ctx, tx, req.TenantID, item.ProductID)
+			if err != nil {
+				return err
+			}
+			if parent {
+				return fmt.Errorf("product %s has variants; receive the variants instead: %w", item.ProductID, ErrInvalidInput)
+			}
+			kit, err := s.repo.ProductIsKit(ctx, tx, req.TenantID, item.ProductID)
+			if err != nil {
+				return err
+			}
+			if kit {
+				return fmt.Errorf("product %s is a kit; receive its components instead: %w", item.ProductID, ErrInvalidInput)
+			}
+			lotID, err := s.receiveLot(ctx, tx, req.TenantID, item.ProductID, line)
+			if err != nil {
+				return err
+			}
+			serialized, err := s.repo.ProductTracksSerials(ctx, tx, req.TenantID, item.ProductID)
+			if err != nil {
+				return err
+			}
+			serials, err := checkSerials(serialized, item.ProductID, line.Quantity, line.SerialNumbers)
+			if err != nil {
+				return err
+			}
+			if line.LocationID != nil {
+				loc, err := s.repo.GetLocation(ctx, tx, req.TenantID, *line.LocationID)
+				if err != nil {
+					return err
+				}
+				if err := checkLocationUse(loc, *line.LocationID, gr.WarehouseID, true); err != nil {
+					return err
+				}
+			}
+
+			refType := "GOODS_RECEIPT"
+			movement := &domain.StockMovement{
+				ID:            uuid.New(),
+				TenantID:      req.TenantID,
+				ProductID:     item.ProductID,
+				WarehouseID:   gr.WarehouseID,
+				Quantity:      line.Quantity,
+				Type:          domain.StockMovementTypeIn,
+				ReferenceID:   &gr.ID,
+				ReferenceType: &refType,
+				UnitCost:      &grItem.UnitCost,
+				LotID:         lotID,
+				LocationID:    line.LocationID,
+				SerialNumbers: serials,
+			}
+			if err := s.repo.CreateStockMovement(ctx, tx, movement); err != nil {
+				return fmt.Errorf("failed to create stock movement: %w", err)
+			}
+			if err := moveSerials(ctx, tx, s.repo, movement); err != nil {
+				return err
+			}
+			gr.Items = append(gr.Items, grItem)
+		}
+
+		order.Status = domain.PurchaseOrderStatusReceived
+		for _, item := range order.Items {
+			if item.RemainingQty() > 0 {
+				order.Status = domain.PurchaseOrderStatusPartiallyReceived
+				break
+			}
+		}
+		if order.Status == domain.PurchaseOrderStatusReceived {
+			now := time.Now()
+			order.ClosedAt = &now
+		}
+		if err := s.repo.UpdatePurchaseOrderStatus(ctx, tx, order); err != nil {
+			return fmt.Errorf("failed to update purchase order: %w", err)
+		}
+
+		receipt = gr
+		return recordAudit(ctx, s.repo, tx, domain.AuditLog{
+			TenantID: req.TenantID, EntityType: domain.AuditEntityGoodsReceipt, EntityID: gr.ID, Action: domain.AuditActionCreate,
+		}, nil, gr)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return receipt, nil
+}
+
+// receiveLot returns the lot a receipt line goes into; nil for products without lot tracking.
+func (s *PurchaseOrderService) receiveLot(ctx context.Context, tx pgx.Tx, tenantID, productID uuid.UUID, line domain.PurchaseOrderQuantityLine) (*uuid.UUID, error) {
+	lotNumber := strings.TrimSpace(line.LotNumber)
+	tracked, err := s.repo.ProductTracksLots(ctx, tx, tenantID, productID)
+	if err != nil {
+		return nil, err
+	}
+	if !tracked {
+		if lotNumber != "" || line.ExpiryDate != nil {
+			return nil, fmt.Errorf("product %s is not lot-tracked: %w", productID, ErrInvalidInput)
+		}
+		return nil, nil
+	}
+	if lotNumber == "" {
+		return nil, fmt.Errorf("lot number is required for lot-tracked product %s: %w", productID, ErrInvalidInput)
+	}
+	lot, err := s.repo.EnsureLot(ctx, tx, tenantID, productID, lotNumber, line.ExpiryDate)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkLotExpiry(lot, line.ExpiryDate); err != nil {
+		return nil, err
+	}
+	return &lot.ID, nil
+}
+
+// RecordPurchaseInvoice stores the supplier's invoice for the order. It moves no stock;
+// quantities and prices are compared with the order and receipts by matching.
+func (s *PurchaseOrderService) RecordPurchaseInvoice(ctx context.Context, req domain.CreatePurchaseInvoiceRequest) (*domain.PurchaseInvoice, error) {
+	req.InvoiceNumber = strings.TrimSpace(req.InvoiceNumber)
+	if req.InvoiceNumber == "" {
+		return nil, fmt.Errorf("invoice number is required: %w", ErrInvalidInput)
+	}
+	if len(req.Lines) == 0 {
+		return nil, fmt.Errorf("purchase invoice must have at least one line: %w", ErrInvalidInput)
+	}
+
+	var invoice *domain.PurchaseInvoice
+	_, err := s.withOrder(ctx, req.TenantID, req.OrderID, "", func(tx pgx.Tx, order *domain.PurchaseOrder) error {
+		if order.Status == domain.PurchaseOrderStatusDraft || order.Status == domain.PurchaseOrderStatusCancelled {
+			return fmt.Errorf("purchase order is %s and cannot be invoiced: %w", order.Status, ErrInvalidState)
+		}
+
+		exists, err := s.repo.PurchaseInvoiceNumberExists(ctx, tx, req.TenantID, order.SupplierID, req.InvoiceNumber)
+		if err != nil {
+			return err
+		}
+		if exists {
+			return fmt.Errorf("supplier invoice %s is already recorded: %w", req.InvoiceNumber, ErrInvalidInput)
+		}
+
+		itemsByID := make(map[uuid.UUID]*domain.PurchaseOrderItem, len(order.Items))
+		for i := range order.Items {
+			itemsByID[order.Items[i].ID] = &order.Items[i]
+		}
+
+		inv := &domain.PurchaseInvoice{
+			ID:            uuid.New(),
+			TenantID:      req.TenantID,
+			OrderID:       order.ID,
+			SupplierID:    order.SupplierID,
+			InvoiceNumber: req.InvoiceNumber,
+			InvoiceDate:   req.InvoiceDate,
+		}
+		if inv.InvoiceDate.IsZero() {
+			inv.InvoiceDate = time.Now()
+		}
+		for _, line := range req.Lines {
+			item, ok := itemsByID[line.OrderItemID]
+			if !ok {
+				return fmt.Errorf("purchase order item %s: %w", line.OrderItemID, ErrNotFound)
+			}
+			if line.Quantity <= 0 {
+				return fmt.Errorf("quantity must be greater than zero: %w", ErrInvalidInput)
+			}
+			if line.UnitPrice.IsNegative() {
+				return fmt.Errorf("unit price cannot be negative: %w", ErrInvalidInput)
+			}
+			total := line.UnitPrice.Mul(decimal.NewFromInt(int64(line.Quantity)))
+			inv.TotalAmount = inv.TotalAmount.Add(total)
+			inv.Items = append(inv.Items, domain.PurchaseInvoiceItem{
+				ID:          uuid.New(),
+				TenantID:    req.TenantID,
+				InvoiceID:   inv.ID,
+				OrderItemID: item.ID,
+				ProductID:   item.ProductID,
+				Quantity:    line.Quantity,
+				UnitPrice:   line.UnitPrice,
+				Total:       total,
+			})
+		}
+
+		if err := s.repo.CreatePurchaseInvoice(ctx, tx, inv); err != nil {
+			return fmt.Errorf("failed to create purchase invoice: %w", err)
+		}
+		for i := range inv.Items {
+			if err := s.repo.CreatePurchaseInvoiceItem(ctx, tx, &inv.Items[i]); err != nil {
+				return fmt.Errorf("failed to create purchase invoice item: %w", err)
+			}
+		}
+
+		invoice = inv
+		return recordAudit(ctx, s.repo, tx, domain.AuditLog{
+			TenantID: req.TenantID, EntityType: domain.AuditEntityPurchaseInvoice, EntityID: inv.ID, Action: domain.AuditActionCreate,
+		}, nil, inv)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return invoice, nil
+}
+
+// GetPurchaseOrderMatch returns the three-way match of one order, line by line.
+func (s *PurchaseOrderService) GetPurchaseOrderMatch(ctx context.Context, tenantID, orderID uuid.UUID) ([]domain.PurchaseMatchLine, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	order, err := s.repo.GetPurchaseOrder(ctx, tenantID, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order == nil {
+		return nil, fmt.Errorf("purchase order %s: %w", orderID, ErrNotFound)
+	}
+
+	lines, err := s.repo.ListMatchLines(ctx, tenantID, &orderID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range lines {
+		lines[i].Discrepancies = matchDiscrepancies(lines[i])
+	}
+	return lines, nil
+}
+
+// ListPurchaseDiscrepancies returns every purchase order line that does not match.
+func (s *PurchaseOrderService) ListPurchaseDiscrepancies(ctx context.Context, tenantID uuid.UUID) ([]domain.PurchaseMatchLine, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	lines, err := s.repo.ListMatchLines(ctx, tenantID, nil)
+	if err != nil {
+		return nil, err
+	}
+	result := []domain.PurchaseMatchLine{}
+	for _, line := range lines {
+		line.Discrepancies = matchDiscrepancies(line)
+		if len(line.Discrepancies) > 0 {
+			result = append(result, line)
+		}
+	}
+	return result, nil
+}
+
+// matchDiscrepancies compares ordered, received and invoiced figures of one line.
+func matchDiscrepancies(l domain.PurchaseMatchLine) []string {
+	codes := []string{}
+	if l.ReceivedQty > l.OrderedQty {
+		codes = append(codes, domain.MatchOverReceived)
+	}
+	if l.InvoicedQty == 0 {
+		if l.ReceivedQty > 0 {
+			codes = append(codes, domain.MatchNotInvoiced)
+		}
+		return codes
+	}
+	if l.InvoicedQty != l.ReceivedQty {
+		codes = append(codes, domain.MatchInvoicedQty)
+	}
+	if !l.InvoicedMinPrice.Equal(l.OrderedPrice) || !l.InvoicedMaxPrice.Equal(l.OrderedPrice) {
+		codes = append(codes, domain.MatchPrice)
+	}
+	return codes
+}
+
+// ListOpenPurchaseLines lists what is still on order, optionally per product and warehouse.
+func (s *PurchaseOrderService) ListOpenPurchaseLines(ctx context.Context, tenantID uuid.UUID, productID, warehouseID *uuid.UUID) ([]domain.OpenPurchaseLine, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	return s.repo.ListOpenLines(ctx, tenantID, productID, warehouseID)
+}
+
+// withOrder runs fn in a transaction with the purchase order locked and audits the change to
+// the order under action; an empty action leaves the order unaudited.
+func (s *PurchaseOrderService) withOrder(ctx context.Context, tenantID, orderID uuid.UUID, action string, fn func(tx pgx.Tx, order *domain.PurchaseOrder) error) (*domain.PurchaseOrder, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	var result *domain.PurchaseOrder
+	err := WithTransaction(ctx, s.db, func(tx pgx.Tx) error {
+		order, err := s.repo.GetPurchaseOrderForUpdate(ctx, tx, tenantID, orderID)
+		if err != nil {
+			return err
+		}
+		if order == nil {
+			return fmt.Errorf("purchase order %s: %w", orderID, ErrNotFound)
+		}
+		before := *order
+		before.Items = slices.Clone(order.Items)
+		if err := fn(tx, order); err != nil {
+			return err
+		}
+		result = order
+		if action == "" {
+			return nil
+		}
+		return recordAudit(ctx, s.repo, tx, domain.AuditLog{
+			TenantID: tenantID, EntityType: domain.AuditEntityPurchaseOrder, EntityID: orderID, Action: action,
+		}, &before, order)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *PurchaseOrderService) GetPurchaseOrder(ctx context.Context, tenantID, orderID uuid.UUID) (*domain.PurchaseOrder, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	order, err := s.repo.GetPurchaseOrder(ctx, tenantID, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order == nil {
+		return nil, fmt.Errorf("purchase order %s: %w", orderID, ErrNotFound)
+	}
+	return order, nil
+}
+
+func (s *PurchaseOrderService) ListPurchaseOrders(ctx context.Context, tenantID uuid.UUID, supplierID *uuid.UUID, status domain.PurchaseOrderStatus) ([]domain.PurchaseOrder, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	return s.repo.ListPurchaseOrders(ctx, tenantID, supplierID, status)
+}
@@ -0,0 +1,148 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"sancaksoft/internal/domain"
+	"sancaksoft/internal/repository"
+	"sancaksoft/internal/service"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPurchaseOrderReceiptAndMatch_Integration(t *testing.T) {
+	if testDBURL == "" {
+		t.Skip("Skipping integration test: TEST_DB_URL not set")
+	}
+
+	ctx := context.Background()
+	db, err := pgxpool.New(ctx, testDBURL)
+	require.NoError(t, err)
+	defer db.Close()
+
+	// 1. Setup Data
+	tenantID := uuid.New()
+	warehouseID := uuid.New()
+	productID := uuid.New()
+	userID := uuid.New()
+
+	defer func() {
+		_, _ = db.Exec(ctx, "DELETE FROM tenants WHERE id = $1", tenantID)
+	}()
+
+	_, err = db.Exec(ctx, "INSERT INTO tenants (id, name) VALUES ($1, 'Purchase Test Tenant')", tenantID)
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, "INSERT INTO warehouses (id, tenant_id, name) VALUES ($1, $2, 'Main')", warehouseID, tenantID)
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, "INSERT INTO products (id, tenant_id, name, sku, price) VALUES ($1, $2, 'Bolt', $3, 30.00)", productID, tenantID, "SKU-"+uuid.New().String())
+	require.NoError(t, err)
+
+	supplierService := service.NewSupplierService(db, repository.NewSupplierRepository(db))
+	poService := service.NewPurchaseOrderService(db, repository.NewPurchaseOrderRepository(db))
+
+	supplier := &domain.Supplier{TenantID: tenantID, Name: "Bolt Supplier"}
+	require.NoError(t, supplierService.CreateSupplier(ctx, supplier))
+
+	getStock := func() int {
+		var q int
+		_ = db.QueryRow(ctx, "SELECT COALESCE(SUM(quantity), 0) FROM stock_movements WHERE tenant_id = $1 AND product_id = $2", tenantID, productID).Scan(&q)
+		return q
+	}
+
+	// 2. Order 10 units at 20
+	order, err := poService.CreatePurchaseOrder(ctx, domain.CreatePurchaseOrderRequest{
+		TenantID:    tenantID,
+		UserID:      userID,
+		SupplierID:  supplier.ID,
+		WarehouseID: warehouseID,
+		Items:       []domain.PurchaseOrderItemRequest{{ProductID: productID, Quantity: 10, UnitPrice: decimal.NewFromInt(20)}},
+	})
+	require.NoError(t, err)
+	_, err = poService.ConfirmPurchaseOrder(ctx, tenantID, order.ID)
+	require.NoError(t, err)
+	itemID := order.Items[0].ID
+
+	// Products and warehouses of another tenant are rejected
+	otherTenantID := uuid.New()
+	otherWarehouseID := uuid.New()
+	otherProductID := uuid.New()
+	defer func() {
+		_, _ = db.Exec(ctx, "DELETE FROM tenants WHERE id = $1", otherTenantID)
+	}()
+	_, err = db.Exec(ctx, "INSERT INTO tenants (id, name) VALUES ($1, 'Other Purchase Tenant')", otherTenantID)
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, "INSERT INTO warehouses (id, tenant_id, name) VALUES ($1, $2, 'Other')", otherWarehouseID, otherTenantID)
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, "INSERT INTO products (id, tenant_id, name, sku, price) VALUES ($1, $2, 'Other Bolt', $3, 30.00)", otherProductID, otherTenantID, "SKU-"+uuid.New().String())
+	require.NoError(t, err)
+
+	_, err = poService.CreatePurchaseOrder(ctx, domain.CreatePurchaseOrderRequest{
+		TenantID:    tenantID,
+		UserID:      userID,
+		SupplierID:  supplier.ID,
+		WarehouseID: warehouseID,
+		Items:       []domain.PurchaseOrderItemRequest{{ProductID: otherProductID, Quantity: 1, UnitPrice: decimal.NewFromInt(20)}},
+	})
+	assert.ErrorIs(t, err, service.ErrNotFound)
+	_, err = poService.CreatePurchaseOrder(ctx, domain.CreatePurchaseOrderRequest{
+		TenantID:    tenantID,
+		UserID:      userID,
+		SupplierID:  supplier.ID,
+		WarehouseID: otherWarehouseID,
+		Items:       []domain.PurchaseOrderItemRequest{{ProductID: productID, Quantity: 1, UnitPrice: decimal.NewFromInt(20)}},
+	})
+	assert.ErrorIs(t, err, service.ErrNotFound)
+	_, err = poService.ReceiveGoods(ctx, domain.CreateGoodsReceiptRequest{
+		TenantID: tenantID, UserID: userID, OrderID: order.ID, WarehouseID: &otherWarehouseID,
+		Lines: []domain.PurchaseOrderQuantityLine{{OrderItemID: itemID, Quantity: 1}},
+	})
+	assert.ErrorIs(t, err, service.ErrNotFound)
+
+	// 3. Partial receipt moves stock and leaves the rest on order
+	_, err = poService.ReceiveGoods(ctx, domain.CreateGoodsReceiptRequest{
+		TenantID: tenantID, UserID: userID, OrderID: order.ID,
+		Lines: []domain.PurchaseOrderQuantityLine{{OrderItemID: itemID, Quantity: 6}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 6, getStock())
+
+	open, err := poService.ListOpenPurchaseLines(ctx, tenantID, &productID, nil)
+	require.NoError(t, err)
+	require.Len(t, open, 1)
+	assert.Equal(t, 4, open[0].RemainingQty)
+
+	_, err = poService.ReceiveGoods(ctx, domain.CreateGoodsReceiptRequest{
+		TenantID: tenantID, UserID: userID, OrderID: order.ID,
+		Lines: []domain.PurchaseOrderQuantityLine{{OrderItemID: itemID, Quantity: 4}},
+	})
+	require.NoError(t, err)
+
+	received, err := poService.GetPurchaseOrder(ctx, tenantID, order.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.PurchaseOrderStatusReceived, received.Status)
+
+	// 4. Supplier invoices 10 units at 21: price discrepancy
+	_, err = poService.RecordPurchaseInvoice(ctx, domain.CreatePurchaseInvoiceRequest{
+		TenantID: tenantID, UserID: userID, OrderID: order.ID,
+		InvoiceNumber: "SUP-001", InvoiceDate: time.Now(),
+		Lines: []domain.PurchaseOrderQuantityLine{{OrderItemID: itemID, Quantity: 10, UnitPrice: decimal.NewFromInt(21)}},
+	})
+	require.NoError(t, err)
+
+	match, err := poService.GetPurchaseOrderMatch(ctx, tenantID, order.ID)
+	require.NoError(t, err)
+	require.Len(t, match, 1)
+	assert.Equal(t, 10, match[0].ReceivedQty)
+	assert.Equal(t, 10, match[0].InvoicedQty)
+	assert.Equal(t, []string{domain.MatchPrice}, match[0].Discrepancies)
+
+	discrepancies, err := poService.ListPurchaseDiscrepancies(ctx, tenantID)
+	require.NoError(t, err)
+	assert.Len(t, discrepancies, 1)
+}
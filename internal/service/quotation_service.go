@@ -0,0 +1,344 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"sancaksoft/internal/domain"
+	"sancaksoft/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shopspring/decimal"
+)
+
+type QuotationService struct {
+	db       *pgxpool.Pool
+	repo     *repository.QuotationRepository
+	orders   *SalesOrderService
+	invoices *InvoiceService
+}
+
+func NewQuotationService(db *pgxpool.Pool, repo *repository.QuotationRepository, orders *SalesOrderService, invoices *InvoiceService) *QuotationService {
+	return &QuotationService{db: db, repo: repo, orders: orders, invoices: invoices}
+}
+
+// CreateQuotation saves a new quotation as revision 1 in SENT status.
+func (s *QuotationService) CreateQuotation(ctx context.Context, req domain.CreateQuotationRequest) (*domain.Quotation, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	var created *domain.Quotation
+	err := WithTransaction(ctx, s.db, func(tx pgx.Tx) error {
+		quoteNumber, err := s.repo.GenerateNextQuoteNumber(ctx, tx, req.TenantID)
+		if err != nil {
+			return err
+		}
+		quote := &domain.Quotation{
+			TenantID:    req.TenantID,
+			CustomerID:  req.CustomerID,
+			WarehouseID: req.WarehouseID,
+			QuoteNumber: quoteNumber,
+			Revision:    1,
+			ValidUntil:  req.ValidUntil,
+			Note:        req.Note,
+		}
+		if err := s.createQuotationTx(ctx, tx, quote, req.Items); err != nil {
+			return err
+		}
+		created = quote
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// ReviseQuotation creates a new revision with the given lines and validity date and
+// supersedes the current one. Accepted or converted quotations cannot be revised.
+func (s *QuotationService) ReviseQuotation(ctx context.Context, req domain.ReviseQuotationRequest) (*domain.Quotation, error) {
+	var revised *domain.Quotation
+	_, err := s.withQuotation(ctx, req.TenantID, req.QuotationID, domain.AuditActionRevise, func(tx pgx.Tx, current *domain.Quotation) error {
+		switch current.Status {
+		case domain.QuotationStatusSent, domain.QuotationStatusExpired, domain.QuotationStatusRejected:
+		default:
+			return fmt.Errorf("quotation is %s and cannot be revised: %w", current.Status, ErrInvalidState)
+		}
+
+		quote := &domain.Quotation{
+			TenantID:    current.TenantID,
+			CustomerID:  current.CustomerID,
+			WarehouseID: current.WarehouseID,
+			QuoteNumber: current.QuoteNumber,
+			Revision:    current.Revision + 1,
+			ValidUntil:  req.ValidUntil,
+			Note:        req.Note,
+		}
+		if err := s.createQuotationTx(ctx, tx, quote, req.Items); err != nil {
+			return err
+		}
+
+		current.Status = domain.QuotationStatusSuperseded
+		if err := s.repo.UpdateQuotationStatus(ctx, tx, current); err != nil {
+			return fmt.Errorf("failed to supersede quotation: %w", err)
+		}
+		revised = quote
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return revised, nil
+}
+
+func (s *QuotationService) createQuotationTx(ctx context.Context, tx pgx.Tx, quote *domain.Quotation, items []domain.QuotationItemRequest) error {
+	if len(items) == 0 {
+		return fmt.Errorf("quotation must have at least one item: %w", ErrInvalidInput)
+	}
+	if quote.ValidUntil.IsZero() {
+		return fmt.Errorf("valid_until is required: %w", ErrInvalidInput)
+	}
+	if quotationExpired(quote.ValidUntil, time.Now()) {
+		return fmt.Errorf("valid_until cannot be in the past: %w", ErrInvalidInput)
+	}
+	for _, item := range items {
+		if item.Quantity <= 0 {
+			return fmt.Errorf("quantity must be greater than zero: %w", ErrInvalidInput)
+		}
+		if item.UnitPrice.IsNegative() {
+			return fmt.Errorf("unit price cannot be negative: %w", ErrInvalidInput)
+		}
+	}
+
+	quote.ID = uuid.New()
+	quote.Status = domain.QuotationStatusSent
+	quote.TotalAmount = decimal.Zero
+	for _, itemReq := range items {
+		quote.TotalAmount = quote.TotalAmount.Add(itemReq.UnitPrice.Mul(decimal.NewFromInt(int64(itemReq.Quantity))))
+	}
+
+	if err := s.repo.CreateQuotation(ctx, tx, quote); err != nil {
+		return fmt.Errorf("failed to create quotation: %w", err)
+	}
+
+	for _, itemReq := range items {
+		item := domain.QuotationItem{
+			ID:          uuid.New(),
+			TenantID:    quote.TenantID,
+			QuotationID: quote.ID,
+			ProductID:   itemReq.ProductID,
+			Quantity:    itemReq.Quantity,
+			UnitPrice:   itemReq.UnitPrice,
+			Total:       itemReq.UnitPrice.Mul(decimal.NewFromInt(int64(itemReq.Quantity))),
+		}
+		if err := s.repo.CreateQuotationItem(ctx, tx, &item); err != nil {
+			return fmt.Errorf("failed to create quotation item: %w", err)
+		}
+		quote.Items = append(quote.Items, item)
+	}
+	return recordAudit(ctx, s.repo, tx, domain.AuditLog{
+		TenantID: quote.TenantID, EntityType: domain.AuditEntityQuotation, EntityID: quote.ID, Action: domain.AuditActionCreate,
+	}, nil, quote)
+}
+
+// AcceptQuotation records the customer's acceptance of a SENT quotation.
+func (s *QuotationService) AcceptQuotation(ctx context.Context, tenantID, quotationID uuid.UUID) (*domain.Quotation, error) {
+	return s.withQuotation(ctx, tenantID, quotationID, domain.AuditActionAccept, func(tx pgx.Tx, quote *domain.Quotation) error {
+		if err := checkQuotationOpen(quote, false); err != nil {
+			return err
+		}
+		if quote.Status != domain.QuotationStatusSent {
+			return fmt.Errorf("quotation is %s, expected %s: %w", quote.Status, domain.QuotationStatusSent, ErrInvalidState)
+		}
+		quote.Status = domain.QuotationStatusAccepted
+		return s.repo.UpdateQuotationStatus(ctx, tx, quote)
+	})
+}
+
+// RejectQuotation records that the customer declined the quotation.
+func (s *QuotationService) RejectQuotation(ctx context.Context, tenantID, quotationID uuid.UUID) (*domain.Quotation, error) {
+	return s.withQuotation(ctx, tenantID, quotationID, domain.AuditActionReject, func(tx pgx.Tx, quote *domain.Quotation) error {
+		if quote.IsConverted() {
+			return fmt.Errorf("quotation %s has already been converted: %w", quote.QuoteNumber, ErrInvalidState)
+		}
+		if quote.Status != domain.QuotationStatusSent && quote.Status != domain.QuotationStatusAccepted {
+			return fmt.Errorf("quotation is %s and cannot be rejected: %w", quote.Status, ErrInvalidState)
+		}
+		quote.Status = domain.QuotationStatusRejected
+		return s.repo.UpdateQuotationStatus(ctx, tx, quote)
+	})
+}
+
+// ConvertToSalesOrder creates a DRAFT sales order from the quotation lines and links it.
+func (s *QuotationService) ConvertToSalesOrder(ctx context.Context, req domain.ConvertQuotationRequest) (*domain.SalesOrder, error) {
+	var order *domain.SalesOrder
+	_, err := s.withQuotation(ctx, req.TenantID, req.QuotationID, domain.AuditActionConvert, func(tx pgx.Tx, quote *domain.Quotation) error {
+		if err := checkQuotationOpen(quote, true); err != nil {
+			return err
+		}
+
+		orderReq := domain.CreateSalesOrderRequest{
+			TenantID:    req.TenantID,
+			UserID:      req.UserID,
+			CustomerID:  quote.CustomerID,
+			WarehouseID: quote.WarehouseID,
+			Note:        fmt.Sprintf("%s rev.%d", quote.QuoteNumber, quote.Revision),
+		}
+		for _, item := range quote.Items {
+			orderReq.Items = append(orderReq.Items, domain.SalesOrderItemRequest{
+				ProductID: item.ProductID,
+				Quantity:  item.Quantity,
+				UnitPrice: item.UnitPrice,
+			})
+		}
+
+		created, err := s.orders.createSalesOrderTx(ctx, tx, orderReq)
+		if err != nil {
+			return err
+		}
+
+		quote.Status = domain.QuotationStatusAccepted
+		quote.SalesOrderID = &created.ID
+		if err := s.repo.UpdateQuotationStatus(ctx, tx, quote); err != nil {
+			return fmt.Errorf("failed to link quotation: %w", err)
+		}
+		order = created
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// ConvertToInvoice invoices the quotation lines directly, moving stock through the
+// regular invoice path, and links the invoice.
+func (s *QuotationService) ConvertToInvoice(ctx context.Context, req domain.ConvertQuotationRequest) (*domain.Invoice, error) {
+	var invoice *domain.Invoice
+	_, err := s.withQuotation(ctx, req.TenantID, req.QuotationID, domain.AuditActionConvert, func(tx pgx.Tx, quote *domain.Quotation) error {
+		if err := checkQuotationOpen(quote, true); err != nil {
+			return err
+		}
+
+		invoiceReq := domain.CreateInvoiceRequest{
+			TenantID:       req.TenantID,
+			UserID:         req.UserID,
+			WarehouseID:    quote.WarehouseID,
+			CustomerID:     quote.CustomerID,
+			IdempotencyKey: req.IdempotencyKey,
+
+			CreditOverrideReason: req.CreditOverrideReason,
+		}
+		for _, item := range quote.Items {
+			invoiceReq.Items = append(invoiceReq.Items, domain.InvoiceItemRequest{
+				ProductID: item.ProductID,
+				Quantity:  item.Quantity,
+				UnitPrice: item.UnitPrice,
+			})
+		}
+
+		created, err := s.invoices.createInvoiceTx(ctx, tx, invoiceReq)
+		if err != nil {
+			return err
+		}
+
+		quote.Status = domain.QuotationStatusAccepted
+		quote.InvoiceID = &created.ID
+		if err := s.repo.UpdateQuotationStatus(ctx, tx, quote); err != nil {
+			return fmt.Errorf("failed to link quotation: %w", err)
+		}
+		invoice = created
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return invoice, nil
+}
+
+// checkQuotationOpen verifies the quotation is still valid and, for conversions,
+// that it is SENT or ACCEPTED and has not been converted before.
+func checkQuotationOpen(quote *domain.Quotation, converting bool) error {
+	if quote.Status == domain.QuotationStatusSent && quotationExpired(quote.ValidUntil, time.Now()) {
+		return fmt.Errorf("quotation %s expired on %s: %w", quote.QuoteNumber, quote.ValidUntil.Format("2006-01-02"), ErrInvalidState)
+	}
+	if !converting {
+		return nil
+	}
+	if quote.IsConverted() {
+		return fmt.Errorf("quotation %s has already been converted: %w", quote.QuoteNumber, ErrInvalidState)
+	}
+	if quote.Status != domain.QuotationStatusSent && quote.Status != domain.QuotationStatusAccepted {
+		return fmt.Errorf("quotation is %s and cannot be converted: %w", quote.Status, ErrInvalidState)
+	}
+	return nil
+}
+
+// quotationExpired reports whether validUntil (a calendar date) lies before today.
+func quotationExpired(validUntil, now time.Time) bool {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	day := time.Date(validUntil.Year(), validUntil.Month(), validUntil.Day(), 0, 0, 0, 0, time.UTC)
+	return day.Before(today)
+}
+
+// withQuotation runs fn in a transaction with the quotation locked and audits the change to
+// it under action.
+func (s *QuotationService) withQuotation(ctx context.Context, tenantID, quotationID uuid.UUID, action string, fn func(tx pgx.Tx, quote *domain.Quotation) error) (*domain.Quotation, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	var result *domain.Quotation
+	err := WithTransaction(ctx, s.db, func(tx pgx.Tx) error {
+		quote, err := s.repo.GetQuotationForUpdate(ctx, tx, tenantID, quotationID)
+		if err != nil {
+			return err
+		}
+		if quote == nil {
+			return fmt.Errorf("quotation %s: %w", quotationID, ErrNotFound)
+		}
+		before := *quote
+		if err := fn(tx, quote); err != nil {
+			return err
+		}
+		result = quote
+		return recordAudit(ctx, s.repo, tx, domain.AuditLog{
+			TenantID: tenantID, EntityType: domain.AuditEntityQuotation, EntityID: quotationID, Action: action,
+		}, &before, quote)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetQuotation returns a quotation, expiring overdue ones first.
+func (s *QuotationService) GetQuotation(ctx context.Context, tenantID, quotationID uuid.UUID) (*domain.Quotation, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if err := s.repo.ExpireQuotations(ctx, tenantID); err != nil {
+		return nil, err
+	}
+	quote, err := s.repo.GetQuotation(ctx, tenantID, quotationID)
+	if err != nil {
+		return nil, err
+	}
+	if quote == nil {
+		return nil, fmt.Errorf("quotation %s: %w", quotationID, ErrNotFound)
+	}
+	return quote, nil
+}
+
+// ListQuotations lists quotations, expiring overdue ones first.
+func (s *QuotationService) ListQuotations(ctx context.Context, tenantID uuid.UUID, status domain.QuotationStatus) ([]domain.Quotation, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if err := s.repo.ExpireQuotations(ctx, tenantID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListQuotations(ctx, tenantID, status)
+}
@@ -0,0 +1,125 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"sancaksoft/internal/domain"
+	"sancaksoft/internal/repository"
+	"sancaksoft/internal/service"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuotationLifecycle_Integration(t *testing.T) {
+	if testDBURL == "" {
+		t.Skip("Skipping integration test: TEST_DB_URL not set")
+	}
+
+	ctx := context.Background()
+	db, err := pgxpool.New(ctx, testDBURL)
+	require.NoError(t, err)
+	defer db.Close()
+
+	// 1. Setup Data
+	tenantID := uuid.New()
+	warehouseID := uuid.New()
+	productID := uuid.New()
+	customerID := uuid.New()
+	userID := uuid.New()
+
+	defer func() {
+		_, _ = db.Exec(ctx, "DELETE FROM tenants WHERE id = $1", tenantID)
+	}()
+
+	_, err = db.Exec(ctx, "INSERT INTO tenants (id, name) VALUES ($1, 'Quote Test Tenant')", tenantID)
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, "INSERT INTO warehouses (id, tenant_id, name) VALUES ($1, $2, 'Main')", warehouseID, tenantID)
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, "INSERT INTO products (id, tenant_id, name, sku, price) VALUES ($1, $2, 'Widget', $3, 100.00)", productID, tenantID, "SKU-"+uuid.New().String())
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, "INSERT INTO customers (id, tenant_id, name) VALUES ($1, $2, 'Quote Customer')", customerID, tenantID)
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, `
+		INSERT INTO stock_movements (id, tenant_id, product_id, warehouse_id, quantity, type, created_at)
+		VALUES ($1, $2, $3, $4, 20, 'IN', NOW())
+	`, uuid.New(), tenantID, productID, warehouseID)
+	require.NoError(t, err)
+
+	invoiceService := service.NewInvoiceService(db, repository.NewInvoiceRepository())
+	orderService := service.NewSalesOrderService(db, repository.NewSalesOrderRepository(db), invoiceService)
+	quoteService := service.NewQuotationService(db, repository.NewQuotationRepository(db), orderService, invoiceService)
+
+	newQuote := func() *domain.Quotation {
+		quote, err := quoteService.CreateQuotation(ctx, domain.CreateQuotationRequest{
+			TenantID:    tenantID,
+			UserID:      userID,
+			CustomerID:  customerID,
+			WarehouseID: warehouseID,
+			ValidUntil:  time.Now().AddDate(0, 0, 7),
+			Items:       []domain.QuotationItemRequest{{ProductID: productID, Quantity: 4, UnitPrice: decimal.NewFromInt(90)}},
+		})
+		require.NoError(t, err)
+		return quote
+	}
+
+	// 2. Revising supersedes the previous revision
+	first := newQuote()
+	assert.Equal(t, domain.QuotationStatusSent, first.Status)
+	revised, err := quoteService.ReviseQuotation(ctx, domain.ReviseQuotationRequest{
+		TenantID:    tenantID,
+		UserID:      userID,
+		QuotationID: first.ID,
+		ValidUntil:  time.Now().AddDate(0, 0, 14),
+		Items:       []domain.QuotationItemRequest{{ProductID: productID, Quantity: 5, UnitPrice: decimal.NewFromInt(85)}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, first.QuoteNumber, revised.QuoteNumber)
+	assert.Equal(t, 2, revised.Revision)
+	assert.True(t, decimal.NewFromInt(425).Equal(revised.TotalAmount))
+
+	old, err := quoteService.GetQuotation(ctx, tenantID, first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.QuotationStatusSuperseded, old.Status)
+
+	// 3. Convert the revision directly into an invoice
+	invoice, err := quoteService.ConvertToInvoice(ctx, domain.ConvertQuotationRequest{
+		TenantID: tenantID, UserID: userID, QuotationID: revised.ID, IdempotencyKey: uuid.New(),
+	})
+	require.NoError(t, err)
+	assert.True(t, decimal.NewFromInt(425).Equal(invoice.TotalAmount))
+
+	converted, err := quoteService.GetQuotation(ctx, tenantID, revised.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.QuotationStatusAccepted, converted.Status)
+	require.NotNil(t, converted.InvoiceID)
+	assert.Equal(t, invoice.ID, *converted.InvoiceID)
+
+	_, err = quoteService.ConvertToSalesOrder(ctx, domain.ConvertQuotationRequest{TenantID: tenantID, UserID: userID, QuotationID: revised.ID})
+	assert.ErrorIs(t, err, service.ErrInvalidState)
+
+	// 4. Convert another quotation into a draft sales order
+	second := newQuote()
+	order, err := quoteService.ConvertToSalesOrder(ctx, domain.ConvertQuotationRequest{TenantID: tenantID, UserID: userID, QuotationID: second.ID})
+	require.NoError(t, err)
+	assert.Equal(t, domain.SalesOrderStatusDraft, order.Status)
+	require.Len(t, order.Items, 1)
+	assert.Equal(t, 4, order.Items[0].Quantity)
+
+	// 5. Quotations past their validity date expire automatically
+	third := newQuote()
+	_, err = db.Exec(ctx, "UPDATE quotations SET valid_until = CURRENT_DATE - 1 WHERE id = $1", third.ID)
+	require.NoError(t, err)
+
+	expired, err := quoteService.GetQuotation(ctx, tenantID, third.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.QuotationStatusExpired, expired.Status)
+
+	_, err = quoteService.AcceptQuotation(ctx, tenantID, third.ID)
+	assert.ErrorIs(t, err, service.ErrInvalidState)
+}
@@ -0,0 +1,247 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"sancaksoft/internal/domain"
+	"sancaksoft/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	defaultSalesWindowDays = 30
+	maxSalesWindowDays     = 365
+)
+
+type ReplenishmentService struct {
+	db        *pgxpool.Pool
+	repo      *repository.ReplenishmentRepository
+	purchases *PurchaseOrderService
+}
+
+func NewReplenishmentService(db *pgxpool.Pool, repo *repository.ReplenishmentRepository, purchases *PurchaseOrderService) *ReplenishmentService {
+	return &ReplenishmentService{db: db, repo: repo, purchases: purchases}
+}
+
+// SetStockLevel creates or replaces the reorder settings of a product in a warehouse.
+func (s *ReplenishmentService) SetStockLevel(ctx context.Context, level *domain.StockLevel) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if level.MinLevel < 0 || level.ReorderLevel < 0 || level.MaxLevel < 0 || level.SafetyStock < 0 || level.LeadTimeDays < 0 {
+		return fmt.Errorf("stock level values cannot be negative: %w", ErrInvalidInput)
+	}
+	if level.ReorderLevel < level.MinLevel {
+		return fmt.Errorf("reorder level cannot be below minimum level: %w", ErrInvalidInput)
+	}
+	if level.MaxLevel > 0 && level.MaxLevel < level.ReorderLevel {
+		return fmt.Errorf("maximum level cannot be below reorder level: %w", ErrInvalidInput)
+	}
+
+	return WithTransaction(ctx, s.db, func(tx pgx.Tx) error {
+		current, err := s.repo.FindStockLevelForUpdate(ctx, tx, level.TenantID, level.ProductID, level.WarehouseID)
+		if err != nil {
+			return err
+		}
+		level.ID = uuid.New()
+		if err := s.repo.UpsertStockLevel(ctx, tx, level); err != nil {
+			return err
+		}
+		action := domain.AuditActionUpdate
+		if current == nil {
+			action = domain.AuditActionCreate
+		}
+		return recordAudit(ctx, s.repo, tx, domain.AuditLog{
+			TenantID: level.TenantID, EntityType: domain.AuditEntityStockLevel, EntityID: level.ID, Action: action,
+		}, current, level)
+	})
+}
+
+func (s *ReplenishmentService) DeleteStockLevel(ctx context.Context, tenantID, levelID uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	return WithTransaction(ctx, s.db, func(tx pgx.Tx) error {
+		level, err := s.repo.GetStockLevelForUpdate(ctx, tx, tenantID, levelID)
+		if err != nil {
+			return err
+		}
+		if level == nil {
+			return fmt.Errorf("stock level %s: %w", levelID, ErrNotFound)
+		}
+		if _, err := s.repo.DeleteStockLevel(ctx, tx, tenantID, levelID); err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.repo, tx, domain.AuditLog{
+			TenantID: tenantID, EntityType: domain.AuditEntityStockLevel, EntityID: levelID, Action: domain.AuditActionDelete,
+		}, level, nil)
+	})
+}
+
+// ListStockLevels returns configured levels with current on-hand, reserved and on-order figures.
+func (s *ReplenishmentService) ListStockLevels(ctx context.Context, tenantID uuid.UUID, warehouseID *uuid.UUID) ([]domain.StockLevelStatus, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	return s.repo.ListStockLevelStatus(ctx, tenantID, warehouseID, defaultSalesWindowDays)
+}
+
+// GetLowStockReport lists stock levels whose available quantity reached the reorder level.
+func (s *ReplenishmentService) GetLowStockReport(ctx context.Context, tenantID uuid.UUID, warehouseID *uuid.UUID) ([]domain.LowStockItem, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	levels, err := s.repo.ListStockLevelStatus(ctx, tenantID, warehouseID, defaultSalesWindowDays)
+	if err != nil {
+		return nil, err
+	}
+
+	items := []domain.LowStockItem{}
+	for _, st := range levels {
+		switch {
+		case st.Available < st.MinLevel:
+			items = append(items, domain.LowStockItem{StockLevelStatus: st, Severity: domain.LowStockBelowMin})
+		case st.Available <= st.ReorderLevel:
+			items = append(items, domain.LowStockItem{StockLevelStatus: st, Severity: domain.LowStockAtReorder})
+		}
+	}
+	return items, nil
+}
+
+// GetReplenishmentSuggestions proposes purchase quantities from average daily sales over
+// the last windowDays, lead time and safety stock. Quantities already on order count
+// towards the target.
+func (s *ReplenishmentService) GetReplenishmentSuggestions(ctx context.Context, tenantID uuid.UUID, warehouseID *uuid.UUID, windowDays int) ([]domain.ReplenishmentSuggestion, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	return s.suggestions(ctx, tenantID, warehouseID, windowDays)
+}
+
+func (s *ReplenishmentService) suggestions(ctx context.Context, tenantID uuid.UUID, warehouseID *uuid.UUID, windowDays int) ([]domain.ReplenishmentSuggestion, error) {
+	if windowDays == 0 {
+		windowDays = defaultSalesWindowDays
+	}
+	if windowDays < 1 || windowDays > maxSalesWindowDays {
+		return nil, fmt.Errorf("window must be between 1 and %d days: %w", maxSalesWindowDays, ErrInvalidInput)
+	}
+
+	levels, err := s.repo.ListStockLevelStatus(ctx, tenantID, warehouseID, windowDays)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []domain.ReplenishmentSuggestion{}
+	for _, st := range levels {
+		sold := st.SoldInWindow
+		if sold < 0 {
+			sold = 0
+		}
+		// ceil(sold / window * lead time) without floating point
+		leadDemand := (sold*st.LeadTimeDays + windowDays - 1) / windowDays
+
+		reorderPoint := max(st.ReorderLevel, leadDemand+st.SafetyStock)
+		target := max(st.MaxLevel, reorderPoint)
+		projected := st.Available + st.OnOrder
+		if projected > reorderPoint || target-projected <= 0 {
+			continue
+		}
+
+		supplierID := st.SupplierID
+		if supplierID == nil {
+			supplierID = st.LastSupplierID
+		}
+		result = append(result, domain.ReplenishmentSuggestion{
+			ProductID:     st.ProductID,
+			ProductName:   st.ProductName,
+			WarehouseID:   st.WarehouseID,
+			WarehouseName: st.WarehouseName,
+			SupplierID:    supplierID,
+			Available:     st.Available,
+			OnOrder:       st.OnOrder,
+			AvgDailySales: decimal.NewFromInt(int64(sold)).Div(decimal.NewFromInt(int64(windowDays))).Round(2),
+			LeadTimeDays:  st.LeadTimeDays,
+			SafetyStock:   st.SafetyStock,
+			ReorderPoint:  reorderPoint,
+			TargetLevel:   target,
+			SuggestedQty:  target - projected,
+			UnitPrice:     st.LastPurchasePrice,
+		})
+	}
+	return result, nil
+}
+
+// CreateReplenishmentOrders turns the current suggestions into DRAFT purchase orders,
+// one per supplier and warehouse, in a single transaction. productIDs optionally limits
+// which suggestions are ordered. Suggestions without a known supplier are returned as skipped.
+func (s *ReplenishmentService) CreateReplenishmentOrders(ctx context.Context, tenantID, userID uuid.UUID, warehouseID *uuid.UUID, windowDays int, productIDs []uuid.UUID) (*domain.ReplenishmentOrdersResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	suggestions, err := s.suggestions(ctx, tenantID, warehouseID, windowDays)
+	if err != nil {
+		return nil, err
+	}
+
+	wanted := make(map[uuid.UUID]bool, len(productIDs))
+	for _, id := range productIDs {
+		wanted[id] = true
+	}
+
+	type orderKey struct{ supplierID, warehouseID uuid.UUID }
+	var keys []orderKey
+	requests := make(map[orderKey]*domain.CreatePurchaseOrderRequest)
+	result := &domain.ReplenishmentOrdersResult{Orders: []domain.PurchaseOrder{}, Skipped: []domain.ReplenishmentSuggestion{}}
+
+	for _, sg := range suggestions {
+		if len(wanted) > 0 && !wanted[sg.ProductID] {
+			continue
+		}
+		if sg.SupplierID == nil {
+			result.Skipped = append(result.Skipped, sg)
+			continue
+		}
+		key := orderKey{*sg.SupplierID, sg.WarehouseID}
+		req, ok := requests[key]
+		if !ok {
+			req = &domain.CreatePurchaseOrderRequest{
+				TenantID:    tenantID,
+				UserID:      userID,
+				SupplierID:  key.supplierID,
+				WarehouseID: key.warehouseID,
+				Note:        "Replenishment suggestion",
+			}
+			requests[key] = req
+			keys = append(keys, key)
+		}
+		req.Items = append(req.Items, domain.PurchaseOrderItemRequest{
+			ProductID: sg.ProductID,
+			Quantity:  sg.SuggestedQty,
+			UnitPrice: sg.UnitPrice,
+		})
+	}
+
+	if len(keys) == 0 {
+		return result, nil
+	}
+
+	err = WithTransaction(ctx, s.db, func(tx pgx.Tx) error {
+		result.Orders = result.Orders[:0]
+		for _, key := range keys {
+			order, err := s.purchases.createPurchaseOrderTx(ctx, tx, *requests[key])
+			if err != nil {
+				return err
+			}
+			result.Orders = append(result.Orders, *order)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,106 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"sancaksoft/internal/domain"
+	"sancaksoft/internal/repository"
+	"sancaksoft/internal/service"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplenishmentSuggestions_Integration(t *testing.T) {
+	if testDBURL == "" {
+		t.Skip("Skipping integration test: TEST_DB_URL not set")
+	}
+
+	ctx := context.Background()
+	db, err := pgxpool.New(ctx, testDBURL)
+	require.NoError(t, err)
+	defer db.Close()
+
+	// 1. Setup Data
+	tenantID := uuid.New()
+	warehouseID := uuid.New()
+	productID := uuid.New()
+	userID := uuid.New()
+
+	defer func() {
+		_, _ = db.Exec(ctx, "DELETE FROM tenants WHERE id = $1", tenantID)
+	}()
+
+	_, err = db.Exec(ctx, "INSERT INTO tenants (id, name) VALUES ($1, 'Replenishment Test Tenant')", tenantID)
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, "INSERT INTO warehouses (id, tenant_id, name) VALUES ($1, $2, 'Main')", warehouseID, tenantID)
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, "INSERT INTO products (id, tenant_id, name, sku, price) VALUES ($1, $2, 'Nut', $3, 5.00)", productID, tenantID, "SKU-"+uuid.New().String())
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, "INSERT INTO stock_movements (tenant_id, product_id, warehouse_id, quantity, type) VALUES ($1, $2, $3, 5, 'IN')", tenantID, productID, warehouseID)
+	require.NoError(t, err)
+
+	supplierService := service.NewSupplierService(db, repository.NewSupplierRepository(db))
+	poService := service.NewPurchaseOrderService(db, repository.NewPurchaseOrderRepository(db))
+	replenishmentService := service.NewReplenishmentService(db, repository.NewReplenishmentRepository(db), poService)
+
+	supplier := &domain.Supplier{TenantID: tenantID, Name: "Nut Supplier"}
+	require.NoError(t, supplierService.CreateSupplier(ctx, supplier))
+
+	// 2. Reorder at 10, fill up to 50
+	require.NoError(t, replenishmentService.SetStockLevel(ctx, &domain.StockLevel{
+		TenantID:     tenantID,
+		ProductID:    productID,
+		WarehouseID:  warehouseID,
+		MinLevel:     5,
+		ReorderLevel: 10,
+		MaxLevel:     50,
+		SupplierID:   &supplier.ID,
+	}))
+
+	low, err := replenishmentService.GetLowStockReport(ctx, tenantID, nil)
+	require.NoError(t, err)
+	require.Len(t, low, 1)
+	assert.Equal(t, domain.LowStockBelowMin, low[0].Severity)
+	assert.Equal(t, 5, low[0].Available)
+
+	// 3. Suggest the gap to max level
+	suggestions, err := replenishmentService.GetReplenishmentSuggestions(ctx, tenantID, nil, 0)
+	require.NoError(t, err)
+	require.Len(t, suggestions, 1)
+	assert.Equal(t, 45, suggestions[0].SuggestedQty)
+	require.NotNil(t, suggestions[0].SupplierID)
+	assert.Equal(t, supplier.ID, *suggestions[0].SupplierID)
+
+	// 4. Draft PO from suggestions; stock on order stops further suggestions
+	result, err := replenishmentService.CreateReplenishmentOrders(ctx, tenantID, userID, nil, 0, nil)
+	require.NoError(t, err)
+	require.Len(t, result.Orders, 1)
+	assert.Empty(t, result.Skipped)
+	assert.Equal(t, domain.PurchaseOrderStatusDraft, result.Orders[0].Status)
+	require.Len(t, result.Orders[0].Items, 1)
+	assert.Equal(t, 45, result.Orders[0].Items[0].Quantity)
+
+	// 5. Running it again does not duplicate the draft
+	again, err := replenishmentService.CreateReplenishmentOrders(ctx, tenantID, userID, nil, 0, nil)
+	require.NoError(t, err)
+	assert.Empty(t, again.Orders)
+
+	var drafts int
+	require.NoError(t, db.QueryRow(ctx, "SELECT COUNT(*) FROM purchase_orders WHERE tenant_id = $1", tenantID).Scan(&drafts))
+	assert.Equal(t, 1, drafts)
+
+	suggestions, err = replenishmentService.GetReplenishmentSuggestions(ctx, tenantID, nil, 0)
+	require.NoError(t, err)
+	assert.Empty(t, suggestions)
+
+	_, err = poService.ConfirmPurchaseOrder(ctx, tenantID, result.Orders[0].ID)
+	require.NoError(t, err)
+
+	suggestions, err = replenishmentService.GetReplenishmentSuggestions(ctx, tenantID, nil, 0)
+	require.NoError(t, err)
+	assert.Empty(t, suggestions)
+}
@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"sancaksoft/internal/domain"
+	"sancaksoft/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+type ReportService struct {
+	repo *repository.ReportRepository
+}
+
+func NewReportService(repo *repository.ReportRepository) *ReportService {
+	return &ReportService{repo: repo}
+}
+
+// GetGrossProfit returns revenue, cost, gross profit and margin % per group plus a total row.
+func (s *ReportService) GetGrossProfit(ctx context.Context, req domain.GrossProfitReportRequest) (*domain.GrossProfitReport, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	if req.GroupBy == "" {
+		req.GroupBy = domain.ProfitByProduct
+	}
+	switch req.GroupBy {
+	case domain.ProfitByProduct, domain.ProfitByCustomer, domain.ProfitByWarehouse, domain.ProfitBySalesperson, domain.ProfitByCategory, domain.ProfitByBrand:
+		req.Period = ""
+	case domain.ProfitByPeriod:
+		if req.Period == "" {
+			req.Period = domain.ReportPeriodMonth
+		}
+		if req.Period != domain.ReportPeriodDay && req.Period != domain.ReportPeriodWeek && req.Period != domain.ReportPeriodMonth {
+			return nil, fmt.Errorf("period must be day, week or month: %w", ErrInvalidInput)
+		}
+	default:
+		return nil, fmt.Errorf("group_by must be product, customer, warehouse, salesperson, category, brand or period: %w", ErrInvalidInput)
+	}
+	if req.From != nil && req.To != nil && !req.From.Before(*req.To) {
+		return nil, fmt.Errorf("from must be before to: %w", ErrInvalidInput)
+	}
+
+	lines, err := s.repo.GetGrossProfit(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &domain.GrossProfitReport{
+		GroupBy: req.GroupBy,
+		Period:  req.Period,
+		Lines:   lines,
+		Total:   domain.GrossProfitLine{Key: "TOTAL", Label: "Total"},
+	}
+	for i := range report.Lines {
+		line := &report.Lines[i]
+		line.Cost = line.Cost.Round(2)
+		line.GrossProfit = line.Revenue.Sub(line.Cost)
+		line.MarginPct = marginPct(line.GrossProfit, line.Revenue)
+
+		report.Total.Quantity += line.Quantity
+		report.Total.Revenue = report.Total.Revenue.Add(line.Revenue)
+		report.Total.Cost = report.Total.Cost.Add(line.Cost)
+	}
+	report.Total.GrossProfit = report.Total.Revenue.Sub(report.Total.Cost)
+	report.Total.MarginPct = marginPct(report.Total.GrossProfit, report.Total.Revenue)
+	return report, nil
+}
+
+// GetReceivablesAging buckets each customer's open invoices by days past due as of
+// req.AsOf (today if zero): current, 1-30, 31-60, 61-90 and 90+. Payments and returns are
+// applied to the oldest due invoices first, as in the credit check on invoicing.
+func (s *ReportService) GetReceivablesAging(ctx context.Context, req domain.ReceivablesAgingRequest) (*domain.ReceivablesAgingReport, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	if req.AsOf.IsZero() {
+		req.AsOf = time.Now()
+	}
+	year, month, day := req.AsOf.Date()
+	req.AsOf = time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+
+	invoices, err := s.repo.ListOpenInvoices(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	credits, err := s.repo.ListUnappliedCredits(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &domain.ReceivablesAgingReport{
+		AsOf:     req.AsOf,
+		Lines:    []domain.ReceivablesAgingLine{},
+		Total:    domain.ReceivablesAgingLine{CustomerName: "Total"},
+		Invoices: invoices,
+	}
+	byCustomer := make(map[uuid.UUID]int)
+	lineFor := func(customerID uuid.UUID, name string) *domain.ReceivablesAgingLine {
+		i, ok := byCustomer[customerID]
+		if !ok {
+			i = len(report.Lines)
+			byCustomer[customerID] = i
+			report.Lines = append(report.Lines, domain.ReceivablesAgingLine{CustomerID: customerID, CustomerName: name})
+		}
+		return &report.Lines[i]
+	}
+
+	for i := range report.Invoices {
+		inv := &report.Invoices[i]
+		inv.Bucket = agingBucket(inv.DaysOverdue)
+		addAging(lineFor(inv.CustomerID, inv.CustomerName), inv.Bucket, inv.OpenAmount)
+		addAging(&report.Total, inv.Bucket, inv.OpenAmount)
+	}
+	for _, c := range credits {
+		lineFor(c.CustomerID, c.CustomerName).UnappliedCredit = c.UnappliedCredit
+		report.Total.UnappliedCredit = report.Total.UnappliedCredit.Add(c.UnappliedCredit)
+	}
+
+	sort.SliceStable(report.Lines, func(i, j int) bool { return report.Lines[i].CustomerName < report.Lines[j].CustomerName })
+	for i := range report.Lines {
+		line := &report.Lines[i]
+		line.Balance = line.Total.Sub(line.UnappliedCredit)
+	}
+	report.Total.Balance = report.Total.Total.Sub(report.Total.UnappliedCredit)
+	return report, nil
+}
+
+func agingBucket(daysOverdue int) domain.AgingBucket {
+	switch {
+	case daysOverdue <= 0:
+		return domain.AgingCurrent
+	case daysOverdue <= 30:
+		return domain.Aging1To30
+	case daysOverdue <= 60:
+		return domain.Aging31To60
+	case daysOverdue <= 90:
+		return domain.Aging61To90
+	default:
+		return domain.AgingOver90
+	}
+}
+
+func addAging(line *domain.ReceivablesAgingLine, bucket domain.AgingBucket, amount decimal.Decimal) {
+	switch bucket {
+	case domain.AgingCurrent:
+		line.Current = line.Current.Add(amount)
+	case domain.Aging1To30:
+		line.Days1To30 = line.Days1To30.Add(amount)
+	case domain.Aging31To60:
+		line.Days31To60 = line.Days31To60.Add(amount)
+	case domain.Aging61To90:
+		line.Days61To90 = line.Days61To90.Add(amount)
+	default:
+		line.Over90 = line.Over90.Add(amount)
+	}
+	line.Total = line.Total.Add(amount)
+}
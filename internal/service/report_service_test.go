@@ -0,0 +1,198 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"sancaksoft/internal/domain"
+	"sancaksoft/internal/repository"
+	"sancaksoft/internal/service"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGrossProfitReport_Integration(t *testing.T) {
+	if testDBURL == "" {
+		t.Skip("Skipping integration test: TEST_DB_URL not set")
+	}
+
+	ctx := context.Background()
+	db, err := pgxpool.New(ctx, testDBURL)
+	require.NoError(t, err)
+	defer db.Close()
+
+	// 1. Setup Data
+	tenantID := uuid.New()
+	warehouseID := uuid.New()
+	standardID := uuid.New()
+	purchasedID := uuid.New()
+	customerID := uuid.New()
+	userID := uuid.New()
+
+	defer func() {
+		_, _ = db.Exec(ctx, "DELETE FROM tenants WHERE id = $1", tenantID)
+	}()
+
+	_, err = db.Exec(ctx, "INSERT INTO tenants (id, name) VALUES ($1, 'Profit Test Tenant')", tenantID)
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, "INSERT INTO warehouses (id, tenant_id, name) VALUES ($1, $2, 'Main')", warehouseID, tenantID)
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, "INSERT INTO products (id, tenant_id, name, sku, price, standard_cost, vat_rate) VALUES ($1, $2, 'Standard', $3, 24.00, 12.00, 20)", standardID, tenantID, "SKU-"+uuid.New().String())
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, "INSERT INTO products (id, tenant_id, name, sku, price, vat_rate) VALUES ($1, $2, 'Purchased', $3, 12.00, 20)", purchasedID, tenantID, "SKU-"+uuid.New().String())
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, "INSERT INTO customers (id, tenant_id, name) VALUES ($1, $2, 'Profit Customer')", customerID, tenantID)
+	require.NoError(t, err)
+	// The purchased product's last receipt cost (7) wins over the older one (5)
+	_, err = db.Exec(ctx, `INSERT INTO stock_movements (tenant_id, product_id, warehouse_id, quantity, type, unit_cost, created_at) VALUES
+		($1, $2, $4, 20, 'IN', NULL, NOW()),
+		($1, $3, $4, 20, 'IN', 5, NOW() - INTERVAL '2 days'),
+		($1, $3, $4, 20, 'IN', 7, NOW() - INTERVAL '1 day')`, tenantID, standardID, purchasedID, warehouseID)
+	require.NoError(t, err)
+
+	invoiceService := service.NewInvoiceService(db, repository.NewInvoiceRepository())
+	reportService := service.NewReportService(repository.NewReportRepository(db))
+
+	// 2. Sell 5 @ 24 incl. 20% VAT (net 20, cost 12) and 10 @ 12 (net 10, cost 7)
+	_, err = invoiceService.CreateInvoice(ctx, domain.CreateInvoiceRequest{
+		TenantID:    tenantID,
+		UserID:      userID,
+		CustomerID:  customerID,
+		WarehouseID: warehouseID,
+		Items: []domain.InvoiceItemRequest{
+			{ProductID: standardID, Quantity: 5, UnitPrice: decimal.NewFromInt(24)},
+			{ProductID: purchasedID, Quantity: 10, UnitPrice: decimal.NewFromInt(12)},
+		},
+	})
+	require.NoError(t, err)
+
+	// 3. By product
+	report, err := reportService.GetGrossProfit(ctx, domain.GrossProfitReportRequest{TenantID: tenantID, GroupBy: domain.ProfitByProduct})
+	require.NoError(t, err)
+	require.Len(t, report.Lines, 2)
+	byKey := map[string]domain.GrossProfitLine{}
+	for _, l := range report.Lines {
+		byKey[l.Key] = l
+	}
+	assert.True(t, byKey[standardID.String()].Revenue.Equal(decimal.NewFromInt(100)))
+	assert.True(t, byKey[standardID.String()].Cost.Equal(decimal.NewFromInt(60)))
+	assert.True(t, byKey[standardID.String()].MarginPct.Equal(decimal.NewFromInt(40)))
+	assert.True(t, byKey[purchasedID.String()].Cost.Equal(decimal.NewFromInt(70)))
+	assert.True(t, byKey[purchasedID.String()].GrossProfit.Equal(decimal.NewFromInt(30)))
+
+	// 4. By salesperson and by period collapse into one line with the same total
+	for _, groupBy := range []domain.ProfitGroupBy{domain.ProfitBySalesperson, domain.ProfitByPeriod, domain.ProfitByCustomer} {
+		report, err = reportService.GetGrossProfit(ctx, domain.GrossProfitReportRequest{TenantID: tenantID, GroupBy: groupBy})
+		require.NoError(t, err)
+		require.Len(t, report.Lines, 1, groupBy)
+		assert.True(t, report.Total.Revenue.Equal(decimal.NewFromInt(200)))
+		assert.True(t, report.Total.GrossProfit.Equal(decimal.NewFromInt(70)))
+		assert.True(t, report.Total.MarginPct.Equal(decimal.NewFromInt(35)))
+		if groupBy == domain.ProfitBySalesperson {
+			assert.Equal(t, userID.String(), report.Lines[0].Key)
+		}
+	}
+
+	_, err = reportService.GetGrossProfit(ctx, domain.GrossProfitReportRequest{TenantID: tenantID, GroupBy: "color"})
+	assert.ErrorIs(t, err, service.ErrInvalidInput)
+
+	// 5. Dashboard carries the same figures
+	stats, err := service.NewDashboardService(repository.NewDashboardRepository(db)).GetDashboardStats(ctx, tenantID)
+	require.NoError(t, err)
+	assert.True(t, stats.TotalRevenue.Equal(decimal.NewFromInt(240)))
+	assert.True(t, stats.NetRevenue.Equal(decimal.NewFromInt(200)))
+	assert.True(t, stats.GrossProfit.Equal(decimal.NewFromInt(70)))
+	assert.True(t, stats.GrossMarginPct.Equal(decimal.NewFromInt(35)))
+}
+
+func TestReceivablesAging_Integration(t *testing.T) {
+	if testDBURL == "" {
+		t.Skip("Skipping integration test: TEST_DB_URL not set")
+	}
+
+	ctx := context.Background()
+	db, err := pgxpool.New(ctx, testDBURL)
+	require.NoError(t, err)
+	defer db.Close()
+
+	// 1. Setup Data: invoices aged against 2026-06-30
+	tenantID := uuid.New()
+	warehouseID := uuid.New()
+	alphaID := uuid.New()
+	betaID := uuid.New()
+	gammaID := uuid.New()
+
+	defer func() {
+		_, _ = db.Exec(ctx, "DELETE FROM tenants WHERE id = $1", tenantID)
+	}()
+
+	_, err = db.Exec(ctx, "INSERT INTO tenants (id, name) VALUES ($1, 'Aging Test Tenant')", tenantID)
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, "INSERT INTO warehouses (id, tenant_id, name) VALUES ($1, $2, 'Main')", warehouseID, tenantID)
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, "INSERT INTO customers (id, tenant_id, name) VALUES ($1, $4, 'Alpha'), ($2, $4, 'Beta'), ($3, $4, 'Gamma')", alphaID, betaID, gammaID, tenantID)
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, `INSERT INTO invoices (tenant_id, warehouse_id, customer_id, invoice_number, total_amount, due_date, created_at) VALUES
+		($1, $2, $3, 'AG-1', 400, '2026-03-01', '2026-02-01'),
+		($1, $2, $3, 'AG-2', 50, '2026-04-15', '2026-03-16'),
+		($1, $2, $3, 'AG-3', 300, '2026-05-01', '2026-04-01'),
+		($1, $2, $3, 'AG-4', 200, '2026-06-10', '2026-05-11'),
+		($1, $2, $3, 'AG-5', 100, '2026-06-30', '2026-05-31'),
+		($1, $2, $3, 'AG-6', 999, '2026-08-01', '2026-07-02'),
+		($1, $2, $4, 'AG-7', 100, '2026-01-01', '2026-01-01'),
+		($1, $2, $5, 'AG-8', 100, '2026-02-01', '2026-01-02')`, tenantID, warehouseID, alphaID, betaID, gammaID)
+	require.NoError(t, err)
+	// Alpha's 400 settles its oldest invoice; the payment after the report date is ignored.
+	// Beta paid 150 more than it was invoiced.
+	_, err = db.Exec(ctx, `INSERT INTO customer_payments (tenant_id, customer_id, amount, payment_date, method) VALUES
+		($1, $2, 400, '2026-06-01', 'BANK_TRANSFER'),
+		($1, $2, 1000, '2026-07-05', 'BANK_TRANSFER'),
+		($1, $3, 250, '2026-02-01', 'CASH')`, tenantID, alphaID, betaID)
+	require.NoError(t, err)
+
+	reportService := service.NewReportService(repository.NewReportRepository(db))
+	asOf := time.Date(2026, 6, 30, 0, 0, 0, 0, time.UTC)
+
+	// 2. Tenant-wide
+	report, err := reportService.GetReceivablesAging(ctx, domain.ReceivablesAgingRequest{TenantID: tenantID, AsOf: asOf})
+	require.NoError(t, err)
+	require.Len(t, report.Lines, 3)
+	byCustomer := map[uuid.UUID]domain.ReceivablesAgingLine{}
+	for _, line := range report.Lines {
+		byCustomer[line.CustomerID] = line
+	}
+
+	alpha := byCustomer[alphaID]
+	assert.True(t, alpha.Current.Equal(decimal.NewFromInt(100)))
+	assert.True(t, alpha.Days1To30.Equal(decimal.NewFromInt(200)))
+	assert.True(t, alpha.Days31To60.Equal(decimal.NewFromInt(300)))
+	assert.True(t, alpha.Days61To90.Equal(decimal.NewFromInt(50)))
+	assert.True(t, alpha.Over90.IsZero())
+	assert.True(t, alpha.Total.Equal(decimal.NewFromInt(650)))
+
+	beta := byCustomer[betaID]
+	assert.True(t, beta.Total.IsZero())
+	assert.True(t, beta.UnappliedCredit.Equal(decimal.NewFromInt(150)))
+	assert.True(t, beta.Balance.Equal(decimal.NewFromInt(-150)))
+
+	assert.True(t, byCustomer[gammaID].Over90.Equal(decimal.NewFromInt(100)))
+
+	assert.True(t, report.Total.Total.Equal(decimal.NewFromInt(750)))
+	assert.True(t, report.Total.Balance.Equal(decimal.NewFromInt(600)))
+	assert.Len(t, report.Invoices, 5)
+
+	// 3. One customer
+	report, err = reportService.GetReceivablesAging(ctx, domain.ReceivablesAgingRequest{TenantID: tenantID, AsOf: asOf, CustomerID: &alphaID})
+	require.NoError(t, err)
+	require.Len(t, report.Lines, 1)
+	require.Len(t, report.Invoices, 4)
+	assert.Equal(t, "AG-2", report.Invoices[0].InvoiceNumber)
+	assert.Equal(t, 76, report.Invoices[0].DaysOverdue)
+	assert.Equal(t, domain.Aging61To90, report.Invoices[0].Bucket)
+	assert.Equal(t, domain.AgingCurrent, report.Invoices[3].Bucket)
+}
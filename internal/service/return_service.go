@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"sancaksoft/internal/domain"
@@ -24,6 +25,8 @@ func NewReturnService(db *pgxpool.Pool, repo *repository.ReturnRepository) *Retu
 	return &ReturnService{db: db, repo: repo}
 }
 
+// CreateCustomerReturn registers a return request. No stock is moved until the
+// goods are received and inspected.
 func (s *ReturnService) CreateCustomerReturn(ctx context.Context, req domain.CreateCustomerReturnRequest) (*domain.CustomerReturn, error) {
 	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
@@ -34,6 +37,23 @@ func (s *ReturnService) CreateCustomerReturn(ctx context.Context, req domain.Cre
 
 	var createdReturn *domain.CustomerReturn
 	err := WithTransaction(ctx, s.db, func(tx pgx.Tx) error {
+		kit, err := s.repo.ProductIsKit(ctx, tx, req.TenantID, req.ProductID)
+		if err != nil {
+			return err
+		}
+		if kit {
+			return fmt.Errorf("product %s is a kit; kits are returned per component: %w", req.ProductID, ErrInvalidInput)
+		}
+		if req.ReasonID != nil {
+			active, err := s.repo.IsReturnReasonActive(ctx, tx, req.TenantID, *req.ReasonID)
+			if err != nil {
+				return err
+			}
+			if !active {
+				return fmt.Errorf("return reason %s is unknown or inactive: %w", *req.ReasonID, ErrInvalidInput)
+			}
+		}
+
 		total := req.UnitPrice.Mul(decimal.NewFromInt(int64(req.Quantity)))
 		ret := &domain.CustomerReturn{
 			ID:          uuid.New(),
@@ -45,41 +65,221 @@ func (s *ReturnService) CreateCustomerReturn(ctx context.Context, req domain.Cre
 			UnitPrice:   req.UnitPrice,
 			Total:       total,
 			Reason:      req.Reason,
+			ReasonID:    req.ReasonID,
+			Status:      domain.ReturnStatusRequested,
 		}
 
 		if err := s.repo.CreateCustomerReturn(ctx, tx, ret); err != nil {
 			return fmt.Errorf("failed to create return: %w", err)
 		}
 
-		// Returned products are added back to stock.
+		createdReturn = ret
+		return recordAudit(ctx, s.repo, tx, domain.AuditLog{
+			TenantID: ret.TenantID, EntityType: domain.AuditEntityReturn, EntityID: ret.ID, Action: domain.AuditActionCreate,
+		}, nil, ret)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return createdReturn, nil
+}
+
+// ReceiveCustomerReturn marks the returned goods as physically received.
+func (s *ReturnService) ReceiveCustomerReturn(ctx context.Context, tenantID, returnID uuid.UUID) (*domain.CustomerReturn, error) {
+	return s.transition(ctx, tenantID, returnID, domain.ReturnStatusRequested, domain.AuditActionReceive, func(tx pgx.Tx, ret *domain.CustomerReturn, now time.Time) error {
+		ret.Status = domain.ReturnStatusReceived
+		ret.ReceivedAt = &now
+		return nil
+	})
+}
+
+// InspectCustomerReturn records the disposition of a received return and moves stock:
+// restocked quantity goes back into the return warehouse, scrapped quantity into a
+// quarantine warehouse. Quantity sent back to the supplier creates no movement.
+// Serialized products name the restocked and scrapped units.
+func (s *ReturnService) InspectCustomerReturn(ctx context.Context, req domain.InspectCustomerReturnRequest) (*domain.CustomerReturn, error) {
+	if req.RestockQty < 0 || req.ScrapQty < 0 || req.SupplierQty < 0 {
+		return nil, fmt.Errorf("disposition quantities cannot be negative: %w", ErrInvalidInput)
+	}
+
+	return s.transition(ctx, req.TenantID, req.ReturnID, domain.ReturnStatusReceived, domain.AuditActionInspect, func(tx pgx.Tx, ret *domain.CustomerReturn, now time.Time) error {
+		if sum := req.RestockQty + req.ScrapQty + req.SupplierQty; sum != ret.Quantity {
+			return fmt.Errorf("dispositioned quantity %d does not match returned quantity %d: %w", sum, ret.Quantity, ErrInvalidInput)
+		}
+
+		scrapWarehouseID, err := s.resolveScrapWarehouse(ctx, tx, req)
+		if err != nil {
+			return err
+		}
+		serialized, err := s.repo.ProductTracksSerials(ctx, tx, ret.TenantID, ret.ProductID)
+		if err != nil {
+			return err
+		}
+		restockSerials, err := checkSerials(serialized, ret.ProductID, req.RestockQty, req.RestockSerials)
+		if err != nil {
+			return err
+		}
+		scrapSerials, err := checkSerials(serialized, ret.ProductID, req.ScrapQty, req.ScrapSerials)
+		if err != nil {
+			return err
+		}
+
 		refType := "RETURN"
-		movement := &domain.StockMovement{
-			ID:            uuid.New(),
-			TenantID:      req.TenantID,
-			ProductID:     req.ProductID,
-			WarehouseID:   req.WarehouseID,
-			Quantity:      req.Quantity,
-			Type:          domain.StockMovementTypeIn,
-			ReferenceID:   &ret.ID,
-			ReferenceType: &refType,
+		if req.RestockQty > 0 {
+			movement := &domain.StockMovement{
+				ID:            uuid.New(),
+				TenantID:      ret.TenantID,
+				ProductID:     ret.ProductID,
+				WarehouseID:   ret.WarehouseID,
+				Quantity:      req.RestockQty,
+				Type:          domain.StockMovementTypeIn,
+				ReferenceID:   &ret.ID,
+				ReferenceType: &refType,
+				SerialNumbers: restockSerials,
+			}
+			if err := s.repo.CreateStockMovement(ctx, tx, movement); err != nil {
+				return fmt.Errorf("failed to create restock movement: %w", err)
+			}
+			if err := moveSerials(ctx, tx, s.repo, movement); err != nil {
+				return err
+			}
 		}
-		if err := s.repo.CreateStockMovement(ctx, tx, movement); err != nil {
-			return fmt.Errorf("failed to create return stock movement: %w", err)
+		if req.ScrapQty > 0 {
+			movement := &domain.StockMovement{
+				ID:            uuid.New(),
+				TenantID:      ret.TenantID,
+				ProductID:     ret.ProductID,
+				WarehouseID:   *scrapWarehouseID,
+				Quantity:      req.ScrapQty,
+				Type:          domain.StockMovementTypeIn,
+				ReferenceID:   &ret.ID,
+				ReferenceType: &refType,
+				SerialNumbers: scrapSerials,
+			}
+			if err := s.repo.CreateStockMovement(ctx, tx, movement); err != nil {
+				return fmt.Errorf("failed to create scrap movement: %w", err)
+			}
+			if err := moveSerials(ctx, tx, s.repo, movement); err != nil {
+				return err
+			}
 		}
 
-		createdReturn = ret
+		ret.Status = domain.ReturnStatusInspected
+		ret.RestockQty = req.RestockQty
+		ret.ScrapQty = req.ScrapQty
+		ret.SupplierQty = req.SupplierQty
+		ret.ScrapWarehouseID = scrapWarehouseID
+		ret.InspectionNote = req.Note
+		ret.InspectedAt = &now
+		return nil
+	})
+}
+
+// CloseCustomerReturn finishes an inspected return.
+func (s *ReturnService) CloseCustomerReturn(ctx context.Context, tenantID, returnID uuid.UUID) (*domain.CustomerReturn, error) {
+	return s.transition(ctx, tenantID, returnID, domain.ReturnStatusInspected, domain.AuditActionClose, func(tx pgx.Tx, ret *domain.CustomerReturn, now time.Time) error {
+		ret.Status = domain.ReturnStatusClosed
+		ret.ClosedAt = &now
 		return nil
 	})
+}
+
+// CancelCustomerReturn withdraws a request whose goods have not arrived.
+func (s *ReturnService) CancelCustomerReturn(ctx context.Context, tenantID, returnID uuid.UUID) (*domain.CustomerReturn, error) {
+	return s.transition(ctx, tenantID, returnID, domain.ReturnStatusRequested, domain.AuditActionCancel, func(tx pgx.Tx, ret *domain.CustomerReturn, now time.Time) error {
+		ret.Status = domain.ReturnStatusCancelled
+		ret.ClosedAt = &now
+		return nil
+	})
+}
+
+// RejectCustomerReturn refuses received goods; they are sent back to the customer, so no
+// stock moves and the customer is not credited.
+func (s *ReturnService) RejectCustomerReturn(ctx context.Context, tenantID, returnID uuid.UUID) (*domain.CustomerReturn, error) {
+	return s.transition(ctx, tenantID, returnID, domain.ReturnStatusReceived, domain.AuditActionReject, func(tx pgx.Tx, ret *domain.CustomerReturn, now time.Time) error {
+		ret.Status = domain.ReturnStatusRejected
+		ret.ClosedAt = &now
+		return nil
+	})
+}
+
+// transition locks the return, checks it is in the expected status, applies fn and audits
+// the change under action.
+func (s *ReturnService) transition(ctx context.Context, tenantID, returnID uuid.UUID, from domain.ReturnStatus, action string, fn func(tx pgx.Tx, ret *domain.CustomerReturn, now time.Time) error) (*domain.CustomerReturn, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	var updated *domain.CustomerReturn
+	err := WithTransaction(ctx, s.db, func(tx pgx.Tx) error {
+		ret, err := s.repo.GetCustomerReturnForUpdate(ctx, tx, tenantID, returnID)
+		if err != nil {
+			return err
+		}
+		if ret == nil {
+			return fmt.Errorf("return %s: %w", returnID, ErrNotFound)
+		}
+		if ret.Status != from {
+			return fmt.Errorf("return is %s, expected %s: %w", ret.Status, from, ErrInvalidState)
+		}
+
+		before := *ret
+		if err := fn(tx, ret, time.Now()); err != nil {
+			return err
+		}
+		if err := s.repo.UpdateCustomerReturnWorkflow(ctx, tx, ret); err != nil {
+			return fmt.Errorf("failed to update return: %w", err)
+		}
+
+		updated = ret
+		return recordAudit(ctx, s.repo, tx, domain.AuditLog{
+			TenantID: tenantID, EntityType: domain.AuditEntityReturn, EntityID: returnID, Action: action,
+		}, &before, ret)
+	})
 	if err != nil {
 		return nil, err
 	}
-	return createdReturn, nil
+	return updated, nil
+}
+
+func (s *ReturnService) resolveScrapWarehouse(ctx context.Context, tx pgx.Tx, req domain.InspectCustomerReturnRequest) (*uuid.UUID, error) {
+	if req.ScrapQty == 0 {
+		return nil, nil
+	}
+	if req.ScrapWarehouseID == nil {
+		id, err := s.repo.GetDefaultQuarantineWarehouse(ctx, tx, req.TenantID)
+		if err != nil {
+			return nil, err
+		}
+		if id == nil {
+			return nil, fmt.Errorf("no quarantine warehouse configured for scrapped goods: %w", ErrInvalidInput)
+		}
+		return id, nil
+	}
+
+	isQuarantine, found, err := s.repo.GetWarehouseQuarantineFlag(ctx, tx, req.TenantID, *req.ScrapWarehouseID)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("scrap warehouse %s: %w", *req.ScrapWarehouseID, ErrNotFound)
+	}
+	if !isQuarantine {
+		return nil, fmt.Errorf("warehouse %s is not a quarantine warehouse: %w", *req.ScrapWarehouseID, ErrInvalidInput)
+	}
+	return req.ScrapWarehouseID, nil
 }
 
-func (s *ReturnService) ListCustomerReturns(ctx context.Context, tenantID uuid.UUID) ([]domain.CustomerReturn, error) {
+func (s *ReturnService) ListCustomerReturns(ctx context.Context, tenantID uuid.UUID, status domain.ReturnStatus) ([]domain.CustomerReturn, error) {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
-	return s.repo.ListCustomerReturns(ctx, tenantID)
+
+	switch status {
+	case "", domain.ReturnStatusRequested, domain.ReturnStatusReceived, domain.ReturnStatusInspected,
+		domain.ReturnStatusClosed, domain.ReturnStatusRejected, domain.ReturnStatusCancelled:
+	default:
+		return nil, fmt.Errorf("unknown return status %q: %w", status, ErrInvalidInput)
+	}
+	return s.repo.ListCustomerReturns(ctx, tenantID, status)
 }
 
 func (s *ReturnService) ListCustomerPurchaseSummaries(ctx context.Context, tenantID, customerID uuid.UUID) ([]domain.CustomerPurchaseSummary, error) {
@@ -87,3 +287,63 @@ func (s *ReturnService) ListCustomerPurchaseSummaries(ctx context.Context, tenan
 	defer cancel()
 	return s.repo.ListCustomerPurchaseSummaries(ctx, tenantID, customerID)
 }
+
+func (s *ReturnService) CreateReturnReason(ctx context.Context, reason *domain.ReturnReason) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	reason.Code = strings.ToUpper(strings.TrimSpace(reason.Code))
+	if reason.Code == "" || reason.Name == "" {
+		return fmt.Errorf("code and name are required: %w", ErrInvalidInput)
+	}
+
+	reason.ID = uuid.New()
+	reason.IsActive = true
+	return WithTransaction(ctx, s.db, func(tx pgx.Tx) error {
+		if err := s.repo.CreateReturnReason(ctx, tx, reason); err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.repo, tx, domain.AuditLog{
+			TenantID: reason.TenantID, EntityType: domain.AuditEntityReturnReason, EntityID: reason.ID, Action: domain.AuditActionCreate,
+		}, nil, reason)
+	})
+}
+
+func (s *ReturnService) UpdateReturnReason(ctx context.Context, reason *domain.ReturnReason) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if reason.Name == "" {
+		return fmt.Errorf("name is required: %w", ErrInvalidInput)
+	}
+	return WithTransaction(ctx, s.db, func(tx pgx.Tx) error {
+		current, err := s.repo.GetReturnReasonForUpdate(ctx, tx, reason.TenantID, reason.ID)
+		if err != nil {
+			return err
+		}
+		if current == nil {
+			return fmt.Errorf("return reason %s: %w", reason.ID, ErrNotFound)
+		}
+		if err := s.repo.UpdateReturnReason(ctx, tx, reason); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return fmt.Errorf("return reason %s: %w", reason.ID, ErrNotFound)
+			}
+			return fmt.Errorf("failed to update return reason: %w", err)
+		}
+		return recordAudit(ctx, s.repo, tx, domain.AuditLog{
+			TenantID: reason.TenantID, EntityType: domain.AuditEntityReturnReason, EntityID: reason.ID, Action: domain.AuditActionUpdate,
+		}, current, reason)
+	})
+}
+
+func (s *ReturnService) ListReturnReasons(ctx context.Context, tenantID uuid.UUID, activeOnly bool) ([]domain.ReturnReason, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	return s.repo.ListReturnReasons(ctx, tenantID, activeOnly)
+}
+
+func (s *ReturnService) GetReturnReasonReport(ctx context.Context, tenantID uuid.UUID, from, to *time.Time) ([]domain.ReturnReasonReportRow, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	return s.repo.GetReturnReasonReport(ctx, tenantID, from, to)
+}
@@ -0,0 +1,163 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"sancaksoft/internal/domain"
+	"sancaksoft/internal/repository"
+	"sancaksoft/internal/service"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReturnWorkflow_Integration(t *testing.T) {
+	if testDBURL == "" {
+		t.Skip("Skipping integration test: TEST_DB_URL not set")
+	}
+
+	ctx := context.Background()
+	db, err := pgxpool.New(ctx, testDBURL)
+	require.NoError(t, err)
+	defer db.Close()
+
+	// 1. Setup Data
+	tenantID := uuid.New()
+	warehouseID := uuid.New()
+	scrapWarehouseID := uuid.New()
+	productID := uuid.New()
+	customerID := uuid.New()
+
+	defer func() {
+		_, _ = db.Exec(ctx, "DELETE FROM tenants WHERE id = $1", tenantID)
+	}()
+
+	_, err = db.Exec(ctx, "INSERT INTO tenants (id, name) VALUES ($1, 'Return Test Tenant')", tenantID)
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, "INSERT INTO warehouses (id, tenant_id, name) VALUES ($1, $2, 'Main')", warehouseID, tenantID)
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, "INSERT INTO warehouses (id, tenant_id, name, is_quarantine) VALUES ($1, $2, 'Scrap', TRUE)", scrapWarehouseID, tenantID)
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, "INSERT INTO products (id, tenant_id, name, sku, price) VALUES ($1, $2, 'Glass Jar', $3, 10.00)", productID, tenantID, "SKU-"+uuid.New().String())
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, "INSERT INTO customers (id, tenant_id, name) VALUES ($1, $2, 'Return Customer')", customerID, tenantID)
+	require.NoError(t, err)
+
+	svc := service.NewReturnService(db, repository.NewReturnRepository(db))
+
+	reason := &domain.ReturnReason{TenantID: tenantID, Code: "damaged", Name: "Damaged in transit"}
+	require.NoError(t, svc.CreateReturnReason(ctx, reason))
+	assert.Equal(t, "DAMAGED", reason.Code)
+
+	getStock := func(wID uuid.UUID) int {
+		var q int
+		_ = db.QueryRow(ctx, "SELECT COALESCE(SUM(quantity), 0) FROM stock_movements WHERE tenant_id=$1 AND product_id=$2 AND warehouse_id=$3", tenantID, productID, wID).Scan(&q)
+		return q
+	}
+
+	// 2. Request: no stock movement yet
+	ret, err := svc.CreateCustomerReturn(ctx, domain.CreateCustomerReturnRequest{
+		TenantID:    tenantID,
+		CustomerID:  customerID,
+		ProductID:   productID,
+		WarehouseID: warehouseID,
+		Quantity:    10,
+		UnitPrice:   decimal.NewFromInt(10),
+		ReasonID:    &reason.ID,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, domain.ReturnStatusRequested, ret.Status)
+	assert.Equal(t, 0, getStock(warehouseID))
+
+	// 3. Inspecting before receipt is rejected
+	_, err = svc.InspectCustomerReturn(ctx, domain.InspectCustomerReturnRequest{TenantID: tenantID, ReturnID: ret.ID, RestockQty: 10})
+	assert.ErrorIs(t, err, service.ErrInvalidState)
+
+	_, err = svc.ReceiveCustomerReturn(ctx, tenantID, ret.ID)
+	require.NoError(t, err)
+
+	// 4. Dispositions must add up to the returned quantity
+	_, err = svc.InspectCustomerReturn(ctx, domain.InspectCustomerReturnRequest{TenantID: tenantID, ReturnID: ret.ID, RestockQty: 5})
+	assert.ErrorIs(t, err, service.ErrInvalidInput)
+
+	// 5. Restock 6, scrap 3 (default quarantine warehouse), 1 back to supplier
+	inspected, err := svc.InspectCustomerReturn(ctx, domain.InspectCustomerReturnRequest{
+		TenantID:    tenantID,
+		ReturnID:    ret.ID,
+		RestockQty:  6,
+		ScrapQty:    3,
+		SupplierQty: 1,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, domain.ReturnStatusInspected, inspected.Status)
+	require.NotNil(t, inspected.ScrapWarehouseID)
+	assert.Equal(t, scrapWarehouseID, *inspected.ScrapWarehouseID)
+	assert.Equal(t, 6, getStock(warehouseID))
+	assert.Equal(t, 3, getStock(scrapWarehouseID))
+
+	closed, err := svc.CloseCustomerReturn(ctx, tenantID, ret.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.ReturnStatusClosed, closed.Status)
+
+	// 6. Reason report
+	report, err := svc.GetReturnReasonReport(ctx, tenantID, nil, nil)
+	require.NoError(t, err)
+	require.Len(t, report, 1)
+	assert.Equal(t, "DAMAGED", report[0].Code)
+	assert.Equal(t, 3, report[0].ScrapQty)
+
+	// 7. Only received goods credit the customer: a cancelled request and rejected goods do not
+	customerService := service.NewCustomerService(db, repository.NewCustomerRepository(db))
+	openBalance := func() decimal.Decimal {
+		credit, err := customerService.GetCredit(ctx, tenantID, customerID)
+		require.NoError(t, err)
+		return credit.OpenBalance
+	}
+	assert.True(t, openBalance().Equal(decimal.NewFromInt(-100)))
+
+	request := func() *domain.CustomerReturn {
+		r, err := svc.CreateCustomerReturn(ctx, domain.CreateCustomerReturnRequest{
+			TenantID: tenantID, CustomerID: customerID, ProductID: productID, WarehouseID: warehouseID,
+			Quantity: 2, UnitPrice: decimal.NewFromInt(10),
+		})
+		require.NoError(t, err)
+		return r
+	}
+	cancelled := request()
+	assert.True(t, openBalance().Equal(decimal.NewFromInt(-100)))
+	_, err = svc.RejectCustomerReturn(ctx, tenantID, cancelled.ID)
+	assert.ErrorIs(t, err, service.ErrInvalidState)
+	cancelled, err = svc.CancelCustomerReturn(ctx, tenantID, cancelled.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.ReturnStatusCancelled, cancelled.Status)
+	assert.NotNil(t, cancelled.ClosedAt)
+
+	rejected := request()
+	_, err = svc.ReceiveCustomerReturn(ctx, tenantID, rejected.ID)
+	require.NoError(t, err)
+	assert.True(t, openBalance().Equal(decimal.NewFromInt(-120)))
+	_, err = svc.CancelCustomerReturn(ctx, tenantID, rejected.ID)
+	assert.ErrorIs(t, err, service.ErrInvalidState)
+	rejected, err = svc.RejectCustomerReturn(ctx, tenantID, rejected.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.ReturnStatusRejected, rejected.Status)
+	assert.True(t, openBalance().Equal(decimal.NewFromInt(-100)))
+	assert.Equal(t, 6, getStock(warehouseID))
+
+	// 8. Listing filters by status; unknown statuses are rejected
+	listed, err := svc.ListCustomerReturns(ctx, tenantID, domain.ReturnStatusRejected)
+	require.NoError(t, err)
+	require.Len(t, listed, 1)
+	assert.Equal(t, rejected.ID, listed[0].ID)
+	_, err = svc.ListCustomerReturns(ctx, tenantID, "SHIPPED")
+	assert.ErrorIs(t, err, service.ErrInvalidInput)
+
+	var statusDefault string
+	require.NoError(t, db.QueryRow(ctx, `SELECT column_default FROM information_schema.columns
+		WHERE table_schema = current_schema() AND table_name = 'customer_returns' AND column_name = 'status'`).Scan(&statusDefault))
+	assert.Contains(t, statusDefault, "'REQUESTED'")
+}
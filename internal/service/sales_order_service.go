@@ -0,0 +1,332 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"sancaksoft/internal/domain"
+	"sancaksoft/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shopspring/decimal"
+)
+
+type SalesOrderService struct {
+	db       *pgxpool.Pool
+	repo     *repository.SalesOrderRepository
+	invoices *InvoiceService
+}
+
+func NewSalesOrderService(db *pgxpool.Pool, repo *repository.SalesOrderRepository, invoices *InvoiceService) *SalesOrderService {
+	return &SalesOrderService{db: db, repo: repo, invoices: invoices}
+}
+
+// CreateSalesOrder saves a DRAFT order. Drafts do not reserve stock.
+func (s *SalesOrderService) CreateSalesOrder(ctx context.Context, req domain.CreateSalesOrderRequest) (*domain.SalesOrder, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	var created *domain.SalesOrder
+	err := WithTransaction(ctx, s.db, func(tx pgx.Tx) error {
+		order, err := s.createSalesOrderTx(ctx, tx, req)
+		if err != nil {
+			return err
+		}
+		created = order
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+func (s *SalesOrderService) createSalesOrderTx(ctx context.Context, tx pgx.Tx, req domain.CreateSalesOrderRequest) (*domain.SalesOrder, error) {
+	if len(req.Items) == 0 {
+		return nil, fmt.Errorf("order must have at least one item: %w", ErrInvalidInput)
+	}
+	for _, item := range req.Items {
+		if item.Quantity <= 0 {
+			return nil, fmt.Errorf("quantity must be greater than zero: %w", ErrInvalidInput)
+		}
+	}
+
+	orderNumber, err := s.repo.GenerateNextOrderNumber(ctx, tx, req.TenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	order := &domain.SalesOrder{
+		ID:          uuid.New(),
+		TenantID:    req.TenantID,
+		CustomerID:  req.CustomerID,
+		WarehouseID: req.WarehouseID,
+		OrderNumber: orderNumber,
+		Status:      domain.SalesOrderStatusDraft,
+		Note:        req.Note,
+	}
+	for _, itemReq := range req.Items {
+		order.TotalAmount = order.TotalAmount.Add(itemReq.UnitPrice.Mul(decimal.NewFromInt(int64(itemReq.Quantity))))
+	}
+
+	if err := s.repo.CreateSalesOrder(ctx, tx, order); err != nil {
+		return nil, fmt.Errorf("failed to create sales order: %w", err)
+	}
+
+	for _, itemReq := range req.Items {
+		item := domain.SalesOrderItem{
+			ID:        uuid.New(),
+			TenantID:  req.TenantID,
+			OrderID:   order.ID,
+			ProductID: itemReq.ProductID,
+			Quantity:  itemReq.Quantity,
+			UnitPrice: itemReq.UnitPrice,
+			Total:     itemReq.UnitPrice.Mul(decimal.NewFromInt(int64(itemReq.Quantity))),
+		}
+		if err := s.repo.CreateSalesOrderItem(ctx, tx, &item); err != nil {
+			return nil, fmt.Errorf("failed to create sales order item: %w", err)
+		}
+		order.Items = append(order.Items, item)
+	}
+
+	if err := recordAudit(ctx, s.repo, tx, domain.AuditLog{
+		TenantID: order.TenantID, EntityType: domain.AuditEntitySalesOrder, EntityID: order.ID, Action: domain.AuditActionCreate,
+	}, nil, order); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// ConfirmSalesOrder reserves the order quantities. Each product is locked and its
+// available-to-promise (on hand minus other reservations) must cover the order.
+func (s *SalesOrderService) ConfirmSalesOrder(ctx context.Context, tenantID, orderID uuid.UUID) (*domain.SalesOrder, error) {
+	return s.withOrder(ctx, tenantID, orderID, domain.AuditActionConfirm, func(tx pgx.Tx, order *domain.SalesOrder) error {
+		if order.Status != domain.SalesOrderStatusDraft {
+			return fmt.Errorf("order is %s, expected %s: %w", order.Status, domain.SalesOrderStatusDraft, ErrInvalidState)
+		}
+
+		isQuarantine, err := s.invoices.repo.IsQuarantineWarehouse(ctx, tx, tenantID, order.WarehouseID)
+		if err != nil {
+			return err
+		}
+		if isQuarantine {
+			return fmt.Errorf("cannot reserve stock in quarantine warehouse %s: %w", order.WarehouseID, ErrInvalidInput)
+		}
+
+		required := make(map[uuid.UUID]int)
+		var products []uuid.UUID
+		for _, item := range order.Items {
+			if _, ok := required[item.ProductID]; !ok {
+				products = append(products, item.ProductID)
+			}
+			required[item.ProductID] += item.Quantity
+		}
+
+		for _, productID := range products {
+			if err := s.invoices.repo.LockProduct(ctx, tx, tenantID, productID); err != nil {
+				return err
+			}
+			components, err := s.invoices.repo.ListKitComponents(ctx, tx, tenantID, productID)
+			if err != nil {
+				return err
+			}
+			if len(components) > 0 {
+				return fmt.Errorf("product %s is a kit; kits cannot be reserved by sales orders: %w", productID, ErrInvalidInput)
+			}
+			onHand, err := s.invoices.repo.GetStockBalance(ctx, tx, tenantID, productID, order.WarehouseID)
+			if err != nil {
+				return err
+			}
+			reserved, err := s.repo.GetReservedQuantity(ctx, tx, tenantID, productID, order.WarehouseID, nil)
+			if err != nil {
+				return err
+			}
+			if available := onHand - reserved; available < required[productID] {
+				return fmt.Errorf("insufficient stock for product %s. Available: %d, Requested: %d: %w", productID, available, required[productID], ErrInvalidState)
+			}
+		}
+
+		now := time.Now()
+		order.Status = domain.SalesOrderStatusConfirmed
+		order.ConfirmedAt = &now
+		return s.repo.UpdateSalesOrderStatus(ctx, tx, order)
+	})
+}
+
+// InvoiceSalesOrder converts remaining order quantities (all, or the requested lines)
+// into an invoice and releases the matching reservations in the same transaction.
+func (s *SalesOrderService) InvoiceSalesOrder(ctx context.Context, req domain.InvoiceSalesOrderRequest) (*domain.Invoice, error) {
+	var invoice *domain.Invoice
+	_, err := s.withOrder(ctx, req.TenantID, req.OrderID, domain.AuditActionInvoice, func(tx pgx.Tx, order *domain.SalesOrder) error {
+		inv, err := s.invoiceSalesOrderTx(ctx, tx, order, req)
+		if err != nil {
+			return err
+		}
+		invoice = inv
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return invoice, nil
+}
+
+func (s *SalesOrderService) invoiceSalesOrderTx(ctx context.Context, tx pgx.Tx, order *domain.SalesOrder, req domain.InvoiceSalesOrderRequest) (*domain.Invoice, error) {
+	if order.Status != domain.SalesOrderStatusConfirmed && order.Status != domain.SalesOrderStatusPartiallyDelivered {
+		return nil, fmt.Errorf("order is %s and cannot be invoiced: %w", order.Status, ErrInvalidState)
+	}
+
+	lines := req.Lines
+	if len(lines) == 0 {
+		for _, item := range order.Items {
+			if item.RemainingQty() > 0 {
+				lines = append(lines, domain.SalesOrderDeliveryLine{OrderItemID: item.ID, Quantity: item.RemainingQty()})
+			}
+		}
+	}
+
+	itemsByID := make(map[uuid.UUID]*domain.SalesOrderItem, len(order.Items))
+	for i := range order.Items {
+		itemsByID[order.Items[i].ID] = &order.Items[i]
+	}
+
+	invoiceReq := domain.CreateInvoiceRequest{
+		TenantID:       req.TenantID,
+		UserID:         req.UserID,
+		WarehouseID:    order.WarehouseID,
+		CustomerID:     order.CustomerID,
+		IdempotencyKey: req.IdempotencyKey,
+		SalesOrderID:   &order.ID,
+
+		CreditOverrideReason: req.CreditOverrideReason,
+	}
+	for _, line := range lines {
+		item, ok := itemsByID[line.OrderItemID]
+		if !ok {
+			return nil, fmt.Errorf("order item %s: %w", line.OrderItemID, ErrNotFound)
+		}
+		if line.Quantity <= 0 || line.Quantity > item.RemainingQty() {
+			return nil, fmt.Errorf("quantity %d for order item %s must be between 1 and %d: %w", line.Quantity, item.ID, item.RemainingQty(), ErrInvalidInput)
+		}
+		invoiceReq.Items = append(invoiceReq.Items, domain.InvoiceItemRequest{
+			ProductID:     item.ProductID,
+			Quantity:      line.Quantity,
+			UnitPrice:     item.UnitPrice,
+			SerialNumbers: line.SerialNumbers,
+		})
+		item.DeliveredQty += line.Quantity
+	}
+	if len(invoiceReq.Items) == 0 {
+		return nil, fmt.Errorf("nothing left to invoice on order %s: %w", order.OrderNumber, ErrInvalidState)
+	}
+
+	invoice, err := s.invoices.createInvoiceTx(ctx, tx, invoiceReq)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, line := range lines {
+		if err := s.repo.AddDeliveredQuantity(ctx, tx, req.TenantID, line.OrderItemID, line.Quantity); err != nil {
+			return nil, fmt.Errorf("failed to update delivered quantity: %w", err)
+		}
+	}
+
+	order.Status = domain.SalesOrderStatusClosed
+	for _, item := range order.Items {
+		if item.RemainingQty() > 0 {
+			order.Status = domain.SalesOrderStatusPartiallyDelivered
+			break
+		}
+	}
+	if order.Status == domain.SalesOrderStatusClosed {
+		now := time.Now()
+		order.ClosedAt = &now
+	}
+	if err := s.repo.UpdateSalesOrderStatus(ctx, tx, order); err != nil {
+		return nil, fmt.Errorf("failed to update sales order: %w", err)
+	}
+
+	return invoice, nil
+}
+
+// CloseSalesOrder short-closes a confirmed or partially delivered order, releasing
+// any remaining reservation.
+func (s *SalesOrderService) CloseSalesOrder(ctx context.Context, tenantID, orderID uuid.UUID) (*domain.SalesOrder, error) {
+	return s.withOrder(ctx, tenantID, orderID, domain.AuditActionClose, func(tx pgx.Tx, order *domain.SalesOrder) error {
+		if order.Status != domain.SalesOrderStatusConfirmed && order.Status != domain.SalesOrderStatusPartiallyDelivered {
+			return fmt.Errorf("order is %s and cannot be closed: %w", order.Status, ErrInvalidState)
+		}
+		now := time.Now()
+		order.Status = domain.SalesOrderStatusClosed
+		order.ClosedAt = &now
+		return s.repo.UpdateSalesOrderStatus(ctx, tx, order)
+	})
+}
+
+// CancelSalesOrder cancels an order that has not delivered anything yet.
+func (s *SalesOrderService) CancelSalesOrder(ctx context.Context, tenantID, orderID uuid.UUID) (*domain.SalesOrder, error) {
+	return s.withOrder(ctx, tenantID, orderID, domain.AuditActionCancel, func(tx pgx.Tx, order *domain.SalesOrder) error {
+		if order.Status != domain.SalesOrderStatusDraft && order.Status != domain.SalesOrderStatusConfirmed {
+			return fmt.Errorf("order is %s and cannot be cancelled: %w", order.Status, ErrInvalidState)
+		}
+		now := time.Now()
+		order.Status = domain.SalesOrderStatusCancelled
+		order.ClosedAt = &now
+		return s.repo.UpdateSalesOrderStatus(ctx, tx, order)
+	})
+}
+
+// withOrder runs fn in a transaction with the order locked and audits the change to the
+// order under action.
+func (s *SalesOrderService) withOrder(ctx context.Context, tenantID, orderID uuid.UUID, action string, fn func(tx pgx.Tx, order *domain.SalesOrder) error) (*domain.SalesOrder, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	var result *domain.SalesOrder
+	err := WithTransaction(ctx, s.db, func(tx pgx.Tx) error {
+		order, err := s.repo.GetSalesOrderForUpdate(ctx, tx, tenantID, orderID)
+		if err != nil {
+			return err
+		}
+		if order == nil {
+			return fmt.Errorf("sales order %s: %w", orderID, ErrNotFound)
+		}
+		before := *order
+		before.Items = slices.Clone(order.Items)
+		if err := fn(tx, order); err != nil {
+			return err
+		}
+		result = order
+		return recordAudit(ctx, s.repo, tx, domain.AuditLog{
+			TenantID: tenantID, EntityType: domain.AuditEntitySalesOrder, EntityID: orderID, Action: action,
+		}, &before, order)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *SalesOrderService) GetSalesOrder(ctx context.Context, tenantID, orderID uuid.UUID) (*domain.SalesOrder, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	order, err := s.repo.GetSalesOrder(ctx, tenantID, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order == nil {
+		return nil, fmt.Errorf("sales order %s: %w", orderID, ErrNotFound)
+	}
+	return order, nil
+}
+
+func (s *SalesOrderService) ListSalesOrders(ctx context.Context, tenantID uuid.UUID, status domain.SalesOrderStatus) ([]domain.SalesOrder, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	return s.repo.ListSalesOrders(ctx, tenantID, status)
+}
@@ -0,0 +1,132 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"sancaksoft/internal/domain"
+	"sancaksoft/internal/repository"
+	"sancaksoft/internal/service"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSalesOrderReservation_Integration(t *testing.T) {
+	if testDBURL == "" {
+		t.Skip("Skipping integration test: TEST_DB_URL not set")
+	}
+
+	ctx := context.Background()
+	db, err := pgxpool.New(ctx, testDBURL)
+	require.NoError(t, err)
+	defer db.Close()
+
+	// 1. Setup Data: 10 units on hand
+	tenantID := uuid.New()
+	warehouseID := uuid.New()
+	productID := uuid.New()
+	customerID := uuid.New()
+	userID := uuid.New()
+
+	defer func() {
+		_, _ = db.Exec(ctx, "DELETE FROM tenants WHERE id = $1", tenantID)
+	}()
+
+	_, err = db.Exec(ctx, "INSERT INTO tenants (id, name) VALUES ($1, 'Order Test Tenant')", tenantID)
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, "INSERT INTO warehouses (id, tenant_id, name) VALUES ($1, $2, 'Main')", warehouseID, tenantID)
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, "INSERT INTO products (id, tenant_id, name, sku, price) VALUES ($1, $2, 'Pallet', $3, 100.00)", productID, tenantID, "SKU-"+uuid.New().String())
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, "INSERT INTO customers (id, tenant_id, name) VALUES ($1, $2, 'Order Customer')", customerID, tenantID)
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, `
+		INSERT INTO stock_movements (id, tenant_id, product_id, warehouse_id, quantity, type, created_at)
+		VALUES ($1, $2, $3, $4, 10, 'IN', NOW())
+	`, uuid.New(), tenantID, productID, warehouseID)
+	require.NoError(t, err)
+
+	invoiceService := service.NewInvoiceService(db, repository.NewInvoiceRepository())
+	orderService := service.NewSalesOrderService(db, repository.NewSalesOrderRepository(db), invoiceService)
+	stockService := service.NewStockService(db, repository.NewStockRepository(db))
+
+	newOrder := func(qty int) *domain.SalesOrder {
+		order, err := orderService.CreateSalesOrder(ctx, domain.CreateSalesOrderRequest{
+			TenantID:    tenantID,
+			UserID:      userID,
+			CustomerID:  customerID,
+			WarehouseID: warehouseID,
+			Items:       []domain.SalesOrderItemRequest{{ProductID: productID, Quantity: qty, UnitPrice: decimal.NewFromInt(100)}},
+		})
+		require.NoError(t, err)
+		return order
+	}
+
+	// 2. Confirm an order for 8: ATP drops to 2
+	first := newOrder(8)
+	_, err = orderService.ConfirmSalesOrder(ctx, tenantID, first.ID)
+	require.NoError(t, err)
+
+	atp, err := stockService.GetStockAvailability(ctx, tenantID, productID, warehouseID)
+	require.NoError(t, err)
+	assert.Equal(t, 10, atp.OnHand)
+	assert.Equal(t, 8, atp.Reserved)
+	assert.Equal(t, 2, atp.Available)
+
+	// 3. The same stock cannot be promised twice, nor sold on a direct invoice
+	second := newOrder(5)
+	_, err = orderService.ConfirmSalesOrder(ctx, tenantID, second.ID)
+	assert.ErrorIs(t, err, service.ErrInvalidState)
+
+	_, err = invoiceService.CreateInvoice(ctx, domain.CreateInvoiceRequest{
+		TenantID:       tenantID,
+		UserID:         userID,
+		WarehouseID:    warehouseID,
+		CustomerID:     customerID,
+		IdempotencyKey: uuid.New(),
+		Items:          []domain.InvoiceItemRequest{{ProductID: productID, Quantity: 5, UnitPrice: decimal.NewFromInt(100)}},
+	})
+	assert.Error(t, err)
+
+	// 4. Partial conversion releases only the invoiced quantity
+	_, err = orderService.InvoiceSalesOrder(ctx, domain.InvoiceSalesOrderRequest{
+		TenantID:       tenantID,
+		UserID:         userID,
+		OrderID:        first.ID,
+		IdempotencyKey: uuid.New(),
+		Lines:          []domain.SalesOrderDeliveryLine{{OrderItemID: first.Items[0].ID, Quantity: 3}},
+	})
+	require.NoError(t, err)
+
+	order, err := orderService.GetSalesOrder(ctx, tenantID, first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.SalesOrderStatusPartiallyDelivered, order.Status)
+
+	atp, err = stockService.GetStockAvailability(ctx, tenantID, productID, warehouseID)
+	require.NoError(t, err)
+	assert.Equal(t, 7, atp.OnHand)
+	assert.Equal(t, 5, atp.Reserved)
+
+	// 5. Invoicing the rest closes the order
+	invoice, err := orderService.InvoiceSalesOrder(ctx, domain.InvoiceSalesOrderRequest{
+		TenantID:       tenantID,
+		UserID:         userID,
+		OrderID:        first.ID,
+		IdempotencyKey: uuid.New(),
+	})
+	require.NoError(t, err)
+	assert.True(t, invoice.TotalAmount.Equal(decimal.NewFromInt(500)))
+
+	order, err = orderService.GetSalesOrder(ctx, tenantID, first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.SalesOrderStatusClosed, order.Status)
+
+	atp, err = stockService.GetStockAvailability(ctx, tenantID, productID, warehouseID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, atp.OnHand)
+	assert.Equal(t, 0, atp.Reserved)
+}
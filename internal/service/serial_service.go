@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"sancaksoft/internal/domain"
+	"sancaksoft/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// Every movement of a serialized product names one serial number per unit. A unit is in
+// stock while serial_numbers.warehouse_id is set; stock_movement_serials links each unit
+// to the movements that moved it, which is its history.
+type SerialService struct {
+	repo *repository.SerialRepository
+}
+
+func NewSerialService(repo *repository.SerialRepository) *SerialService {
+	return &SerialService{repo: repo}
+}
+
+// ListInStockSerials returns the product's units in stock, optionally in one warehouse.
+func (s *SerialService) ListInStockSerials(ctx context.Context, tenantID, productID uuid.UUID, warehouseID *uuid.UUID) ([]domain.SerialNumber, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	return s.repo.ListInStockSerials(ctx, tenantID, productID, warehouseID)
+}
+
+// GetSerialHistory looks a serial number up and returns where the unit is and every movement
+// of it: received, sold (to which customer on which document), returned.
+func (s *SerialService) GetSerialHistory(ctx context.Context, tenantID uuid.UUID, serialNumber string, productID *uuid.UUID) ([]domain.SerialHistory, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	serialNumber = strings.TrimSpace(serialNumber)
+	if serialNumber == "" {
+		return nil, fmt.Errorf("serial number is required: %w", ErrInvalidInput)
+	}
+	histories, err := s.repo.GetSerialHistory(ctx, tenantID, serialNumber, productID)
+	if err != nil {
+		return nil, err
+	}
+	if len(histories) == 0 {
+		return nil, fmt.Errorf("serial number %s: %w", serialNumber, ErrNotFound)
+	}
+	for i := range histories {
+		for j := range histories[i].Events {
+			histories[i].Events[j].Event = serialEventType(histories[i].Events[j])
+		}
+	}
+	return histories, nil
+}
+
+func serialEventType(e domain.SerialEvent) domain.SerialEventType {
+	switch {
+	case e.MovementType == domain.StockMovementTypeSale:
+		return domain.SerialEventSold
+	case e.MovementType == domain.StockMovementTypeIn && e.ReferenceType != nil && *e.ReferenceType == "RETURN":
+		return domain.SerialEventReturned
+	case e.MovementType == domain.StockMovementTypeIn && e.ReferenceType != nil && *e.ReferenceType == "DELIVERY_NOTE":
+		return domain.SerialEventShipmentCancelled
+	case e.MovementType == domain.StockMovementTypeIn:
+		return domain.SerialEventReceived
+	default:
+		return domain.SerialEventIssued
+	}
+}
+
+// checkSerials validates the serial numbers given for quantity units of a product and
+// returns them trimmed. Serialized products need exactly one distinct serial per unit;
+// other products take none.
+func checkSerials(tracked bool, productID uuid.UUID, quantity int, serials []string) ([]string, error) {
+	if !tracked {
+		if len(serials) > 0 {
+			return nil, fmt.Errorf("product %s is not serialized: %w", productID, ErrInvalidInput)
+		}
+		return nil, nil
+	}
+	if len(serials) != quantity {
+		return nil, fmt.Errorf("product %s needs %d serial numbers, got %d: %w", productID, quantity, len(serials), ErrInvalidInput)
+	}
+
+	result := make([]string, len(serials))
+	seen := make(map[string]bool, len(serials))
+	for i, serial := range serials {
+		serial = strings.TrimSpace(serial)
+		if serial == "" {
+			return nil, fmt.Errorf("serial numbers cannot be empty: %w", ErrInvalidInput)
+		}
+		if seen[serial] {
+			return nil, fmt.Errorf("serial number %s is given twice: %w", serial, ErrInvalidInput)
+		}
+		seen[serial] = true
+		result[i] = serial
+	}
+	return result, nil
+}
+
+// serialMover is implemented by the repositories that write stock movements.
+type serialMover interface {
+	MoveSerials(ctx context.Context, tx pgx.Tx, movement *domain.StockMovement) (string, error)
+}
+
+// moveSerials moves the units of an inserted movement, failing on the first serial number
+// that is already in stock (inbound) or not in the movement's warehouse (outbound).
+func moveSerials(ctx context.Context, tx pgx.Tx, repo serialMover, movement *domain.StockMovement) error {
+	if len(movement.SerialNumbers) == 0 {
+		return nil
+	}
+	rejected, err := repo.MoveSerials(ctx, tx, movement)
+	if err != nil {
+		return err
+	}
+	if rejected == "" {
+		return nil
+	}
+	if movement.Quantity > 0 {
+		return fmt.Errorf("serial number %s is already in stock: %w", rejected, ErrInvalidState)
+	}
+	return fmt.Errorf("serial number %s is not in stock in warehouse %s: %w", rejected, movement.WarehouseID, ErrInvalidState)
+}
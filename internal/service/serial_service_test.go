@@ -0,0 +1,146 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"sancaksoft/internal/domain"
+	"sancaksoft/internal/repository"
+	"sancaksoft/internal/service"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSerialTrackingAndHistory_Integration(t *testing.T) {
+	if testDBURL == "" {
+		t.Skip("Skipping integration test: TEST_DB_URL not set")
+	}
+
+	ctx := context.Background()
+	db, err := pgxpool.New(ctx, testDBURL)
+	require.NoError(t, err)
+	defer db.Close()
+
+	// 1. Setup Data
+	tenantID := uuid.New()
+	warehouseID := uuid.New()
+	otherWarehouseID := uuid.New()
+	productID := uuid.New()
+	customerID := uuid.New()
+	userID := uuid.New()
+
+	defer func() {
+		_, _ = db.Exec(ctx, "DELETE FROM tenants WHERE id = $1", tenantID)
+	}()
+
+	_, err = db.Exec(ctx, "INSERT INTO tenants (id, name) VALUES ($1, 'Serial Test Tenant')", tenantID)
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, "INSERT INTO warehouses (id, tenant_id, name) VALUES ($1, $2, 'Main'), ($3, $2, 'Branch')", warehouseID, tenantID, otherWarehouseID)
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, "INSERT INTO products (id, tenant_id, name, sku, price) VALUES ($1, $2, 'Laptop', $3, 900.00)", productID, tenantID, "SKU-"+uuid.New().String())
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, "INSERT INTO customers (id, tenant_id, name) VALUES ($1, $2, 'Office Ltd')", customerID, tenantID)
+	require.NoError(t, err)
+
+	productService := service.NewProductService(db, repository.NewProductRepository(db))
+	stockService := service.NewStockService(db, repository.NewStockRepository(db))
+	invoiceService := service.NewInvoiceService(db, repository.NewInvoiceRepository())
+	returnService := service.NewReturnService(db, repository.NewReturnRepository(db))
+	serialService := service.NewSerialService(repository.NewSerialRepository(db))
+
+	require.NoError(t, productService.SetSerialTracking(ctx, tenantID, productID, true))
+	assert.ErrorIs(t, productService.SetLotTracking(ctx, tenantID, productID, true), service.ErrInvalidState)
+
+	// 2. Receipts need one serial per unit
+	receipt := func(serials ...string) *domain.StockMovement {
+		return &domain.StockMovement{
+			ID: uuid.New(), ProductID: productID, WarehouseID: warehouseID, Quantity: 3, Type: domain.StockMovementTypeIn,
+			SerialNumbers: serials,
+		}
+	}
+	assert.ErrorIs(t, stockService.CreateStockMovement(ctx, tenantID, receipt("SN-1", "SN-2")), service.ErrInvalidInput)
+	assert.ErrorIs(t, stockService.CreateStockMovement(ctx, tenantID, receipt("SN-1", "SN-1", "SN-2")), service.ErrInvalidInput)
+	require.NoError(t, stockService.CreateStockMovement(ctx, tenantID, receipt("SN-1", "SN-2", "SN-3")))
+	assert.ErrorIs(t, stockService.CreateStockMovement(ctx, tenantID, receipt("SN-3", "SN-4", "SN-5")), service.ErrInvalidState)
+
+	inStock, err := serialService.ListInStockSerials(ctx, tenantID, productID, &warehouseID)
+	require.NoError(t, err)
+	assert.Len(t, inStock, 3)
+
+	// 3. A sale must name units that are in stock in the selling warehouse
+	sell := func(warehouse uuid.UUID, serials ...string) (*domain.Invoice, error) {
+		return invoiceService.CreateInvoice(ctx, domain.CreateInvoiceRequest{
+			TenantID:    tenantID,
+			UserID:      userID,
+			CustomerID:  customerID,
+			WarehouseID: warehouse,
+			Items: []domain.InvoiceItemRequest{{
+				ProductID: productID, Quantity: len(serials), UnitPrice: decimal.NewFromInt(900), SerialNumbers: serials,
+			}},
+		})
+	}
+	_, err = invoiceService.CreateInvoice(ctx, domain.CreateInvoiceRequest{
+		TenantID:    tenantID,
+		UserID:      userID,
+		CustomerID:  customerID,
+		WarehouseID: warehouseID,
+		Items:       []domain.InvoiceItemRequest{{ProductID: productID, Quantity: 1, UnitPrice: decimal.NewFromInt(900)}},
+	})
+	assert.ErrorIs(t, err, service.ErrInvalidInput)
+	_, err = sell(warehouseID, "SN-9")
+	assert.ErrorIs(t, err, service.ErrInvalidState)
+	require.NoError(t, stockService.CreateStockMovement(ctx, tenantID, &domain.StockMovement{
+		ID: uuid.New(), ProductID: productID, WarehouseID: otherWarehouseID, Quantity: 1, Type: domain.StockMovementTypeIn,
+		SerialNumbers: []string{"SN-7"},
+	}))
+	_, err = sell(otherWarehouseID, "SN-1")
+	assert.ErrorIs(t, err, service.ErrInvalidState)
+
+	invoice, err := sell(warehouseID, "SN-2")
+	require.NoError(t, err)
+	_, err = sell(warehouseID, "SN-2")
+	assert.ErrorIs(t, err, service.ErrInvalidState)
+
+	// 4. The unit comes back through a customer return
+	ret, err := returnService.CreateCustomerReturn(ctx, domain.CreateCustomerReturnRequest{
+		TenantID: tenantID, UserID: userID, CustomerID: customerID, ProductID: productID, WarehouseID: warehouseID,
+		Quantity: 1, UnitPrice: decimal.NewFromInt(900),
+	})
+	require.NoError(t, err)
+	_, err = returnService.ReceiveCustomerReturn(ctx, tenantID, ret.ID)
+	require.NoError(t, err)
+	_, err = returnService.InspectCustomerReturn(ctx, domain.InspectCustomerReturnRequest{TenantID: tenantID, ReturnID: ret.ID, RestockQty: 1})
+	assert.ErrorIs(t, err, service.ErrInvalidInput)
+	_, err = returnService.InspectCustomerReturn(ctx, domain.InspectCustomerReturnRequest{
+		TenantID: tenantID, ReturnID: ret.ID, RestockQty: 1, RestockSerials: []string{"SN-2"},
+	})
+	require.NoError(t, err)
+
+	// 5. History: received, sold to the customer on the invoice, returned
+	histories, err := serialService.GetSerialHistory(ctx, tenantID, " SN-2 ", nil)
+	require.NoError(t, err)
+	require.Len(t, histories, 1)
+	history := histories[0]
+	assert.Equal(t, "Laptop", history.ProductName)
+	require.NotNil(t, history.WarehouseID)
+	assert.Equal(t, warehouseID, *history.WarehouseID)
+	require.Len(t, history.Events, 3)
+	assert.Equal(t, domain.SerialEventReceived, history.Events[0].Event)
+	assert.Equal(t, domain.SerialEventSold, history.Events[1].Event)
+	require.NotNil(t, history.Events[1].DocumentNumber)
+	assert.Equal(t, invoice.InvoiceNumber, *history.Events[1].DocumentNumber)
+	require.NotNil(t, history.Events[1].PartyName)
+	assert.Equal(t, "Office Ltd", *history.Events[1].PartyName)
+	assert.Equal(t, domain.SerialEventReturned, history.Events[2].Event)
+
+	_, err = serialService.GetSerialHistory(ctx, tenantID, "SN-404", nil)
+	assert.ErrorIs(t, err, service.ErrNotFound)
+
+	// 6. Serial tracking cannot be switched on for a product that already has stock
+	require.NoError(t, productService.SetSerialTracking(ctx, tenantID, productID, false))
+	assert.ErrorIs(t, productService.SetSerialTracking(ctx, tenantID, productID, true), service.ErrInvalidState)
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"sancaksoft/internal/domain"
+	"sancaksoft/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// StockBalanceService verifies the trigger-maintained stock_balances, stock_lot_balances
+// and stock_location_balances tables against the stock movement ledger, which stays the
+// source of truth.
+type StockBalanceService struct {
+	db   *pgxpool.Pool
+	repo *repository.StockRepository
+}
+
+func NewStockBalanceService(db *pgxpool.Pool, repo *repository.StockRepository) *StockBalanceService {
+	return &StockBalanceService{db: db, repo: repo}
+}
+
+// EnsureBalanceTables creates the balance tables and their triggers on a database
+// initialised before them and fills them from the ledger. It runs once at startup.
+func (s *StockBalanceService) EnsureBalanceTables(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+
+	return WithTransaction(ctx, s.db, func(tx pgx.Tx) error {
+		return s.repo.EnsureBalanceTables(ctx, tx)
+	})
+}
+
+// CheckBalances lists balances that differ from the ledger without changing anything.
+func (s *StockBalanceService) CheckBalances(ctx context.Context, tenantID uuid.UUID) ([]domain.StockBalanceDrift, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	return s.repo.ListBalanceDrift(ctx, s.db, tenantID)
+}
+
+// RepairBalances rebuilds drifted balances from the ledger and returns what was corrected.
+func (s *StockBalanceService) RepairBalances(ctx context.Context, tenantID uuid.UUID) ([]domain.StockBalanceDrift, error) {
+	ctx, cancel := context.WithTimeout(ctx, 60*time.Second)
+	defer cancel()
+
+	conn, err := s.db.Acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+	if err := s.repo.LockBalances(ctx, conn, tenantID); err != nil {
+		return nil, err
+	}
+	defer s.repo.UnlockBalances(conn, tenantID)
+
+	var drift []domain.StockBalanceDrift
+	err = WithTransaction(ctx, conn, func(tx pgx.Tx) error {
+		found, err := s.repo.ListBalanceDrift(ctx, tx, tenantID)
+		if err != nil {
+			return err
+		}
+		drift = found
+		if len(drift) == 0 {
+			return nil
+		}
+		if err := s.repo.RepairBalances(ctx, tx, tenantID); err != nil {
+			return err
+		}
+		// Repairs span the tenant's balances, so they are logged against the tenant
+		return recordAudit(ctx, s.repo, tx, domain.AuditLog{
+			TenantID: tenantID, EntityType: domain.AuditEntityStockBalance, EntityID: tenantID, Action: domain.AuditActionRepair,
+			Details: map[string]any{"corrected": drift},
+		}, nil, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return drift, nil
+}
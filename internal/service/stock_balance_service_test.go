@@ -0,0 +1,160 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"sancaksoft/internal/domain"
+	"sancaksoft/internal/repository"
+	"sancaksoft/internal/service"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStockBalancesCheckAndRepair_Integration(t *testing.T) {
+	if testDBURL == "" {
+		t.Skip("Skipping integration test: TEST_DB_URL not set")
+	}
+
+	ctx := context.Background()
+	db, err := pgxpool.New(ctx, testDBURL)
+	require.NoError(t, err)
+	defer db.Close()
+
+	// 1. Setup Data
+	tenantID := uuid.New()
+	warehouseID := uuid.New()
+	productID := uuid.New()
+
+	defer func() {
+		_, _ = db.Exec(ctx, "DELETE FROM tenants WHERE id = $1", tenantID)
+	}()
+
+	_, err = db.Exec(ctx, "INSERT INTO tenants (id, name) VALUES ($1, 'Balance Test Tenant')", tenantID)
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, "INSERT INTO warehouses (id, tenant_id, name) VALUES ($1, $2, 'Main')", warehouseID, tenantID)
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, "INSERT INTO products (id, tenant_id, name, sku, price) VALUES ($1, $2, 'Gasket', $3, 3.00)", productID, tenantID, "SKU-"+uuid.New().String())
+	require.NoError(t, err)
+
+	stockRepo := repository.NewStockRepository(db)
+	stockService := service.NewStockService(db, stockRepo)
+	balanceService := service.NewStockBalanceService(db, stockRepo)
+
+	// 2. Movements keep the materialized balance in step
+	for _, m := range []struct {
+		qty int
+		typ domain.StockMovementType
+	}{{20, domain.StockMovementTypeIn}, {-7, domain.StockMovementTypeOut}} {
+		require.NoError(t, stockService.CreateStockMovement(ctx, tenantID, &domain.StockMovement{
+			ID: uuid.New(), ProductID: productID, WarehouseID: warehouseID, Quantity: m.qty, Type: m.typ,
+		}))
+	}
+	balance, err := stockService.GetStockBalance(ctx, tenantID, productID, warehouseID)
+	require.NoError(t, err)
+	assert.Equal(t, 13, balance)
+
+	drift, err := balanceService.CheckBalances(ctx, tenantID)
+	require.NoError(t, err)
+	assert.Empty(t, drift)
+
+	// 3. Drift is detected and repaired from the ledger
+	_, err = db.Exec(ctx, "UPDATE stock_balances SET quantity = 99 WHERE tenant_id = $1", tenantID)
+	require.NoError(t, err)
+
+	drift, err = balanceService.CheckBalances(ctx, tenantID)
+	require.NoError(t, err)
+	require.Len(t, drift, 1)
+	assert.Equal(t, 99, drift[0].BalanceQty)
+	assert.Equal(t, 13, drift[0].LedgerQty)
+
+	repaired, err := balanceService.RepairBalances(ctx, tenantID)
+	require.NoError(t, err)
+	assert.Len(t, repaired, 1)
+
+	balance, err = stockService.GetStockBalance(ctx, tenantID, productID, warehouseID)
+	require.NoError(t, err)
+	assert.Equal(t, 13, balance)
+
+	drift, err = balanceService.CheckBalances(ctx, tenantID)
+	require.NoError(t, err)
+	assert.Empty(t, drift)
+
+	// 4. Lot and location balances are checked and repaired too
+	lotID := uuid.New()
+	locationID := uuid.New()
+	_, err = db.Exec(ctx, "INSERT INTO lots (id, tenant_id, product_id, lot_number) VALUES ($1, $2, $3, 'L-1')", lotID, tenantID, productID)
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, "INSERT INTO warehouse_locations (id, tenant_id, warehouse_id, code, path) VALUES ($1, $2, $3, 'A1', 'A1')", locationID, tenantID, warehouseID)
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, "INSERT INTO stock_movements (tenant_id, product_id, warehouse_id, quantity, type, lot_id, location_id) VALUES ($1, $2, $3, 5, 'IN', $4, $5)",
+		tenantID, productID, warehouseID, lotID, locationID)
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, "UPDATE stock_lot_balances SET quantity = 1 WHERE tenant_id = $1", tenantID)
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, "DELETE FROM stock_location_balances WHERE tenant_id = $1", tenantID)
+	require.NoError(t, err)
+
+	drift, err = balanceService.CheckBalances(ctx, tenantID)
+	require.NoError(t, err)
+	require.Len(t, drift, 2)
+	require.NotNil(t, drift[0].LocationID)
+	assert.Equal(t, locationID, *drift[0].LocationID)
+	assert.Equal(t, warehouseID, drift[0].WarehouseID)
+	assert.Equal(t, 0, drift[0].BalanceQty)
+	assert.Equal(t, 5, drift[0].LedgerQty)
+	require.NotNil(t, drift[1].LotID)
+	assert.Equal(t, lotID, *drift[1].LotID)
+	assert.Equal(t, 1, drift[1].BalanceQty)
+	assert.Equal(t, 5, drift[1].LedgerQty)
+
+	repaired, err = balanceService.RepairBalances(ctx, tenantID)
+	require.NoError(t, err)
+	assert.Len(t, repaired, 2)
+
+	drift, err = balanceService.CheckBalances(ctx, tenantID)
+	require.NoError(t, err)
+	assert.Empty(t, drift)
+
+	// 5. A repair only waits for the tenant's own movements
+	otherTenantID := uuid.New()
+	otherWarehouseID := uuid.New()
+	otherProductID := uuid.New()
+	defer func() {
+		_, _ = db.Exec(ctx, "DELETE FROM tenants WHERE id = $1", otherTenantID)
+	}()
+	_, err = db.Exec(ctx, "INSERT INTO tenants (id, name) VALUES ($1, 'Other Balance Tenant')", otherTenantID)
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, "INSERT INTO warehouses (id, tenant_id, name) VALUES ($1, $2, 'Other')", otherWarehouseID, otherTenantID)
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, "INSERT INTO products (id, tenant_id, name, sku, price) VALUES ($1, $2, 'Other Gasket', $3, 3.00)", otherProductID, otherTenantID, "SKU-"+uuid.New().String())
+	require.NoError(t, err)
+
+	pending, err := db.Begin(ctx)
+	require.NoError(t, err)
+	defer func() { _ = pending.Rollback(ctx) }()
+	_, err = pending.Exec(ctx, "INSERT INTO stock_movements (tenant_id, product_id, warehouse_id, quantity, type) VALUES ($1, $2, $3, 4, 'IN')", otherTenantID, otherProductID, otherWarehouseID)
+	require.NoError(t, err)
+
+	_, err = db.Exec(ctx, "UPDATE stock_balances SET quantity = 99 WHERE tenant_id = $1", tenantID)
+	require.NoError(t, err)
+	repairCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	repaired, err = balanceService.RepairBalances(repairCtx, tenantID)
+	require.NoError(t, err)
+	assert.Len(t, repaired, 1)
+	require.NoError(t, pending.Commit(ctx))
+
+	// 6. Ensuring the tables on an up-to-date database changes nothing
+	require.NoError(t, balanceService.EnsureBalanceTables(ctx))
+	drift, err = balanceService.CheckBalances(ctx, tenantID)
+	require.NoError(t, err)
+	assert.Empty(t, drift)
+	drift, err = balanceService.CheckBalances(ctx, otherTenantID)
+	require.NoError(t, err)
+	assert.Empty(t, drift)
+}
@@ -3,27 +3,49 @@ package service
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"sancaksoft/internal/domain"
 	"sancaksoft/internal/repository"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type StockService struct {
+	db   *pgxpool.Pool
 	repo *repository.StockRepository
 }
 
-func NewStockService(repo *repository.StockRepository) *StockService {
-	return &StockService{repo: repo}
+func NewStockService(db *pgxpool.Pool, repo *repository.StockRepository) *StockService {
+	return &StockService{db: db, repo: repo}
 }
 
-func (s *StockService) ListStockMovements(ctx context.Context, tenantID uuid.UUID) ([]domain.StockMovement, error) {
+// ListStockMovements returns the latest stock movements matching the filter.
+func (s *StockService) ListStockMovements(ctx context.Context, f domain.StockMovementFilter) ([]domain.StockMovement, error) {
+	if err := validateStockMovementFilter(f); err != nil {
+		return nil, err
+	}
+
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	return s.repo.ListStockMovements(ctx, tenantID)
+	return s.repo.ListStockMovements(ctx, f)
+}
+
+func validateStockMovementFilter(f domain.StockMovementFilter) error {
+	switch f.Type {
+	case "", domain.StockMovementTypeIn, domain.StockMovementTypeOut, domain.StockMovementTypeSale,
+		domain.StockMovementTypeTransfer, domain.StockMovementTypeAdjustment:
+	default:
+		return fmt.Errorf("unknown movement type %q: %w", f.Type, ErrInvalidInput)
+	}
+	if f.From != nil && f.To != nil && !f.From.Before(*f.To) {
+		return fmt.Errorf("from must be before to: %w", ErrInvalidInput)
+	}
+	return nil
 }
 
 func (s *StockService) GetStockBalance(ctx context.Context, tenantID, productID, warehouseID uuid.UUID) (int, error) {
@@ -33,25 +55,196 @@ func (s *StockService) GetStockBalance(ctx context.Context, tenantID, productID,
 	return s.repo.GetStockBalance(ctx, tenantID, productID, warehouseID)
 }
 
+// GetStockAvailability returns on-hand, reserved and available-to-promise quantities.
+func (s *StockService) GetStockAvailability(ctx context.Context, tenantID, productID, warehouseID uuid.UUID) (*domain.StockAvailability, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	onHand, err := s.repo.GetStockBalance(ctx, tenantID, productID, warehouseID)
+	if err != nil {
+		return nil, err
+	}
+	reserved, err := s.repo.GetReservedQuantity(ctx, tenantID, productID, warehouseID)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.StockAvailability{
+		ProductID:   productID,
+		WarehouseID: warehouseID,
+		OnHand:      onHand,
+		Reserved:    reserved,
+		Available:   onHand - reserved,
+	}, nil
+}
+
 func (s *StockService) CreateStockMovement(ctx context.Context, tenantID uuid.UUID, movement *domain.StockMovement) error {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
 	movement.TenantID = tenantID
 
-	// For OUT movements, validate sufficient stock in warehouse
+	if movement.UnitCost != nil {
+		if movement.Type != domain.StockMovementTypeIn {
+			return fmt.Errorf("unit cost can only be set on IN movements: %w", ErrInvalidInput)
+		}
+		if movement.UnitCost.IsNegative() {
+			return fmt.Errorf("unit cost cannot be negative: %w", ErrInvalidInput)
+		}
+	}
+	// Back-dated movements are allowed (e.g. a late purchase entry); costing replays them in date order
+	if movement.CreatedAt.After(time.Now()) {
+		return fmt.Errorf("movement date cannot be in the future: %w", ErrInvalidInput)
+	}
+	parent, err := s.repo.ProductIsVariantParent(ctx, tenantID, movement.ProductID)
+	if err != nil {
+		return err
+	}
+	if parent {
+		return fmt.Errorf("product %s has variants; stock is kept on the variants: %w", movement.ProductID, ErrInvalidInput)
+	}
+	kit, err := s.repo.ProductIsKit(ctx, tenantID, movement.ProductID)
+	if err != nil {
+		return err
+	}
+	if kit {
+		return fmt.Errorf("product %s is a kit; stock is kept on its components: %w", movement.ProductID, ErrInvalidInput)
+	}
+
+	// For OUT movements, validate sufficient unreserved stock in warehouse
 	if movement.Type == domain.StockMovementTypeOut {
 		currentStock, err := s.repo.GetStockBalance(ctx, tenantID, movement.ProductID, movement.WarehouseID)
 		if err != nil {
 			return err
 		}
+		reserved, err := s.repo.GetReservedQuantity(ctx, tenantID, movement.ProductID, movement.WarehouseID)
+		if err != nil {
+			return err
+		}
 		deductQty := -movement.Quantity
-		if currentStock < deductQty {
-			return fmt.Errorf("insufficient stock in warehouse. Available: %d, Requested: %d", currentStock, deductQty)
+		if available := currentStock - reserved; available < deductQty {
+			return fmt.Errorf("insufficient stock in warehouse. Available: %d, Requested: %d", available, deductQty)
 		}
 	}
 
-	return s.repo.CreateStockMovement(ctx, movement)
+	if err := s.resolveLot(ctx, tenantID, movement); err != nil {
+		return err
+	}
+	if err := s.checkMovementLocation(ctx, tenantID, movement); err != nil {
+		return err
+	}
+	serialized, err := s.repo.ProductTracksSerials(ctx, tenantID, movement.ProductID)
+	if err != nil {
+		return err
+	}
+	units := movement.Quantity
+	if units < 0 {
+		units = -units
+	}
+	if movement.SerialNumbers, err = checkSerials(serialized, movement.ProductID, units, movement.SerialNumbers); err != nil {
+		return err
+	}
+
+	// The movement and its serial numbers are written together
+	return WithTransaction(ctx, s.db, func(tx pgx.Tx) error {
+		if err := s.repo.CreateStockMovement(ctx, tx, movement); err != nil {
+			return err
+		}
+		if err := moveSerials(ctx, tx, s.repo, movement); err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.repo, tx, domain.AuditLog{
+			TenantID: tenantID, EntityType: domain.AuditEntityStockMovement, EntityID: movement.ID, Action: domain.AuditActionCreate,
+		}, nil, movement)
+	})
+}
+
+// resolveLot sets LotID of a manual movement. Lot-tracked products need a lot number on both
+// directions: IN creates the lot on first receipt, OUT (e.g. writing off an expired lot) must
+// be covered by that lot's balance.
+func (s *StockService) resolveLot(ctx context.Context, tenantID uuid.UUID, movement *domain.StockMovement) error {
+	var lotNumber string
+	if movement.LotNumber != nil {
+		lotNumber = strings.TrimSpace(*movement.LotNumber)
+	}
+	tracked, err := s.repo.ProductTracksLots(ctx, tenantID, movement.ProductID)
+	if err != nil {
+		return err
+	}
+	if !tracked {
+		if lotNumber != "" || movement.ExpiryDate != nil {
+			return fmt.Errorf("product %s is not lot-tracked: %w", movement.ProductID, ErrInvalidInput)
+		}
+		return nil
+	}
+	if lotNumber == "" {
+		return fmt.Errorf("lot number is required for lot-tracked product %s: %w", movement.ProductID, ErrInvalidInput)
+	}
+
+	if movement.Type == domain.StockMovementTypeIn {
+		lot, err := s.repo.EnsureLot(ctx, tenantID, movement.ProductID, lotNumber, movement.ExpiryDate)
+		if err != nil {
+			return err
+		}
+		if err := checkLotExpiry(lot, movement.ExpiryDate); err != nil {
+			return err
+		}
+		movement.LotID = &lot.ID
+		movement.LotNumber = &lot.LotNumber
+		movement.ExpiryDate = lot.ExpiryDate
+		return nil
+	}
+
+	if movement.ExpiryDate != nil {
+		return fmt.Errorf("expiry date can only be set on IN movements: %w", ErrInvalidInput)
+	}
+	lots, err := s.repo.ListAvailableLots(ctx, tenantID, movement.ProductID, movement.WarehouseID)
+	if err != nil {
+		return err
+	}
+	for _, lot := range lots {
+		if lot.LotNumber != lotNumber {
+			continue
+		}
+		if lot.Quantity < -movement.Quantity {
+			break
+		}
+		movement.LotID = &lot.LotID
+		movement.LotNumber = &lot.LotNumber
+		movement.ExpiryDate = lot.ExpiryDate
+		return nil
+	}
+	return fmt.Errorf("insufficient stock in lot %s: %w", lotNumber, ErrInvalidState)
+}
+
+// checkMovementLocation validates the location of a manual movement. IN needs an active
+// location of the movement's warehouse; OUT takes from the named location, or from unlocated
+// stock when none is named.
+func (s *StockService) checkMovementLocation(ctx context.Context, tenantID uuid.UUID, movement *domain.StockMovement) error {
+	inbound := movement.Type == domain.StockMovementTypeIn
+	if movement.LocationID != nil {
+		loc, err := s.repo.GetLocation(ctx, tenantID, *movement.LocationID)
+		if err != nil {
+			return err
+		}
+		if err := checkLocationUse(loc, *movement.LocationID, movement.WarehouseID, inbound); err != nil {
+			return err
+		}
+	}
+	if movement.Type != domain.StockMovementTypeOut {
+		return nil
+	}
+
+	available, err := s.repo.GetLocationQuantity(ctx, tenantID, movement.ProductID, movement.WarehouseID, movement.LocationID)
+	if err != nil {
+		return err
+	}
+	if available >= -movement.Quantity {
+		return nil
+	}
+	if movement.LocationID == nil {
+		return fmt.Errorf("insufficient unlocated stock, name a location. Available: %d, Requested: %d: %w", available, -movement.Quantity, ErrInvalidState)
+	}
+	return fmt.Errorf("insufficient stock in location. Available: %d, Requested: %d: %w", available, -movement.Quantity, ErrInvalidState)
 }
 
 func (s *StockService) GetTotalStockBalance(ctx context.Context, tenantID, productID uuid.UUID) (int, error) {
@@ -85,4 +278,46 @@ func (s *StockService) GetStockBalanceByWarehouse(ctx context.Context, tenantID,
 		}
 	}
 	return result, nil
-}
\ No newline at end of file
+}
+
+// GetStockBalanceAsOf returns the balance at the end of the given day. A nil warehouseID
+// means all warehouses.
+func (s *StockService) GetStockBalanceAsOf(ctx context.Context, tenantID, productID uuid.UUID, warehouseID *uuid.UUID, asOf time.Time) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	return s.repo.GetStockBalanceAsOf(ctx, tenantID, productID, warehouseID, asOf.AddDate(0, 0, 1))
+}
+
+// GetProductMovementCard lists a product's movements in [from, to) with a running balance
+// that starts from the balance before from.
+func (s *StockService) GetProductMovementCard(ctx context.Context, tenantID, productID uuid.UUID, warehouseID *uuid.UUID, from, to *time.Time) (*domain.ProductMovementCard, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	if from != nil && to != nil && !from.Before(*to) {
+		return nil, fmt.Errorf("from must be before to: %w", ErrInvalidInput)
+	}
+
+	card := &domain.ProductMovementCard{ProductID: productID, WarehouseID: warehouseID}
+	if from != nil {
+		opening, err := s.repo.GetStockBalanceAsOf(ctx, tenantID, productID, warehouseID, *from)
+		if err != nil {
+			return nil, err
+		}
+		card.OpeningBalance = opening
+	}
+
+	lines, err := s.repo.ListProductMovements(ctx, tenantID, productID, warehouseID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	balance := card.OpeningBalance
+	for i := range lines {
+		balance += lines[i].Quantity
+		lines[i].RunningBalance = balance
+	}
+	card.ClosingBalance = balance
+	card.Lines = lines
+	return card, nil
+}
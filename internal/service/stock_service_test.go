@@ -0,0 +1,95 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"sancaksoft/internal/domain"
+	"sancaksoft/internal/repository"
+	"sancaksoft/internal/service"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStockAsOfAndMovementCard_Integration(t *testing.T) {
+	if testDBURL == "" {
+		t.Skip("Skipping integration test: TEST_DB_URL not set")
+	}
+
+	ctx := context.Background()
+	db, err := pgxpool.New(ctx, testDBURL)
+	require.NoError(t, err)
+	defer db.Close()
+
+	// 1. Setup Data
+	tenantID := uuid.New()
+	warehouseID := uuid.New()
+	productID := uuid.New()
+	customerID := uuid.New()
+	userID := uuid.New()
+
+	defer func() {
+		_, _ = db.Exec(ctx, "DELETE FROM tenants WHERE id = $1", tenantID)
+	}()
+
+	_, err = db.Exec(ctx, "INSERT INTO tenants (id, name) VALUES ($1, 'Card Test Tenant')", tenantID)
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, "INSERT INTO warehouses (id, tenant_id, name) VALUES ($1, $2, 'Main')", warehouseID, tenantID)
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, "INSERT INTO products (id, tenant_id, name, sku, price) VALUES ($1, $2, 'Hinge', $3, 8.00)", productID, tenantID, "SKU-"+uuid.New().String())
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, "INSERT INTO customers (id, tenant_id, name) VALUES ($1, $2, 'Card Customer')", customerID, tenantID)
+	require.NoError(t, err)
+
+	stockService := service.NewStockService(db, repository.NewStockRepository(db))
+	invoiceService := service.NewInvoiceService(db, repository.NewInvoiceRepository())
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	day := func(offset int) time.Time { return today.AddDate(0, 0, offset) }
+
+	// 2. +10 five days ago, -3 three days ago, -2 invoiced today
+	require.NoError(t, stockService.CreateStockMovement(ctx, tenantID, &domain.StockMovement{
+		ID: uuid.New(), ProductID: productID, WarehouseID: warehouseID, Quantity: 10, Type: domain.StockMovementTypeIn, CreatedAt: day(-5).Add(time.Hour),
+	}))
+	require.NoError(t, stockService.CreateStockMovement(ctx, tenantID, &domain.StockMovement{
+		ID: uuid.New(), ProductID: productID, WarehouseID: warehouseID, Quantity: -3, Type: domain.StockMovementTypeOut, CreatedAt: day(-3).Add(time.Hour),
+	}))
+	invoice, err := invoiceService.CreateInvoice(ctx, domain.CreateInvoiceRequest{
+		TenantID:    tenantID,
+		UserID:      userID,
+		CustomerID:  customerID,
+		WarehouseID: warehouseID,
+		Items:       []domain.InvoiceItemRequest{{ProductID: productID, Quantity: 2, UnitPrice: decimal.NewFromInt(8)}},
+	})
+	require.NoError(t, err)
+
+	// 3. Balance at the end of past days
+	asOf, err := stockService.GetStockBalanceAsOf(ctx, tenantID, productID, &warehouseID, day(-4))
+	require.NoError(t, err)
+	assert.Equal(t, 10, asOf)
+	asOf, err = stockService.GetStockBalanceAsOf(ctx, tenantID, productID, nil, day(-3))
+	require.NoError(t, err)
+	assert.Equal(t, 7, asOf)
+
+	// 4. Movement card from four days ago
+	from := day(-4)
+	card, err := stockService.GetProductMovementCard(ctx, tenantID, productID, nil, &from, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 10, card.OpeningBalance)
+	assert.Equal(t, 5, card.ClosingBalance)
+	require.Len(t, card.Lines, 2)
+	assert.Equal(t, 7, card.Lines[0].RunningBalance)
+	assert.Nil(t, card.Lines[0].DocumentNumber)
+
+	sale := card.Lines[1]
+	assert.Equal(t, 5, sale.RunningBalance)
+	require.NotNil(t, sale.DocumentNumber)
+	assert.Equal(t, invoice.InvoiceNumber, *sale.DocumentNumber)
+	require.NotNil(t, sale.PartyName)
+	assert.Equal(t, "Card Customer", *sale.PartyName)
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"sancaksoft/internal/domain"
+	"sancaksoft/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type SupplierService struct {
+	db   *pgxpool.Pool
+	repo *repository.SupplierRepository
+}
+
+func NewSupplierService(db *pgxpool.Pool, repo *repository.SupplierRepository) *SupplierService {
+	return &SupplierService{db: db, repo: repo}
+}
+
+func (s *SupplierService) CreateSupplier(ctx context.Context, supplier *domain.Supplier) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	supplier.Name = strings.TrimSpace(supplier.Name)
+	if supplier.Name == "" {
+		return fmt.Errorf("supplier name is required: %w", ErrInvalidInput)
+	}
+
+	supplier.ID = uuid.New()
+	return WithTransaction(ctx, s.db, func(tx pgx.Tx) error {
+		if err := s.repo.CreateSupplier(ctx, tx, supplier); err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.repo, tx, domain.AuditLog{
+			TenantID: supplier.TenantID, EntityType: domain.AuditEntitySupplier, EntityID: supplier.ID, Action: domain.AuditActionCreate,
+		}, nil, supplier)
+	})
+}
+
+func (s *SupplierService) GetSupplier(ctx context.Context, tenantID, supplierID uuid.UUID) (*domain.Supplier, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	supplier, err := s.repo.GetSupplierByID(ctx, tenantID, supplierID)
+	if err != nil {
+		return nil, err
+	}
+	if supplier == nil {
+		return nil, fmt.Errorf("supplier %s: %w", supplierID, ErrNotFound)
+	}
+	return supplier, nil
+}
+
+func (s *SupplierService) ListSuppliers(ctx context.Context, tenantID uuid.UUID) ([]domain.Supplier, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	return s.repo.ListSuppliers(ctx, tenantID)
+}
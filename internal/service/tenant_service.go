@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"sancaksoft/internal/domain"
+	"sancaksoft/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type TenantService struct {
+	db   *pgxpool.Pool
+	repo *repository.TenantRepository
+}
+
+func NewTenantService(db *pgxpool.Pool, repo *repository.TenantRepository) *TenantService {
+	return &TenantService{db: db, repo: repo}
+}
+
+// GetProfile returns the company details printed on the tenant's documents.
+func (s *TenantService) GetProfile(ctx context.Context, tenantID uuid.UUID) (*domain.TenantProfile, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	profile, err := s.repo.GetTenantProfile(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if profile == nil {
+		return nil, fmt.Errorf("tenant %s: %w", tenantID, ErrNotFound)
+	}
+	return profile, nil
+}
+
+// UpdateProfile replaces the tenant's company details.
+func (s *TenantService) UpdateProfile(ctx context.Context, p *domain.TenantProfile) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	p.Name = strings.TrimSpace(p.Name)
+	if p.Name == "" {
+		return fmt.Errorf("company name is required: %w", ErrInvalidInput)
+	}
+	return WithTransaction(ctx, s.db, func(tx pgx.Tx) error {
+		current, err := s.repo.LockTenantProfile(ctx, tx, p.ID)
+		if err != nil {
+			return err
+		}
+		if current == nil {
+			return fmt.Errorf("tenant %s: %w", p.ID, ErrNotFound)
+		}
+		if err := s.repo.UpdateTenantProfile(ctx, tx, p); err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.repo, tx, domain.AuditLog{
+			TenantID: p.ID, EntityType: domain.AuditEntityTenant, EntityID: p.ID, Action: domain.AuditActionUpdate,
+		}, current, p)
+	})
+}
+
+var hexColor = regexp.MustCompile(`^#[0-9A-Fa-f]{6}$`)
+
+// GetInvoiceTemplate returns the tenant's invoice layout settings.
+func (s *TenantService) GetInvoiceTemplate(ctx context.Context, tenantID uuid.UUID) (*domain.InvoiceTemplate, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	return s.repo.GetInvoiceTemplate(ctx, tenantID)
+}
+
+// SaveInvoiceTemplate replaces the tenant's invoice layout settings. An empty title or
+// color falls back to the default.
+func (s *TenantService) SaveInvoiceTemplate(ctx context.Context, t *domain.InvoiceTemplate) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	defaults := domain.DefaultInvoiceTemplate(t.TenantID)
+	t.Title = strings.TrimSpace(t.Title)
+	if t.Title == "" {
+		t.Title = defaults.Title
+	}
+	if t.AccentColor == "" {
+		t.AccentColor = defaults.AccentColor
+	}
+	if len([]rune(t.Title)) > 50 {
+		return fmt.Errorf("title cannot be longer than 50 characters: %w", ErrInvalidInput)
+	}
+	if !hexColor.MatchString(t.AccentColor) {
+		return fmt.Errorf("accent color must be #RRGGBB: %w", ErrInvalidInput)
+	}
+	return WithTransaction(ctx, s.db, func(tx pgx.Tx) error {
+		current, err := s.repo.LockInvoiceTemplate(ctx, tx, t.TenantID)
+		if err != nil {
+			return err
+		}
+		if err := s.repo.SaveInvoiceTemplate(ctx, tx, t); err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.repo, tx, domain.AuditLog{
+			TenantID: t.TenantID, EntityType: domain.AuditEntityInvoiceTemplate, EntityID: t.TenantID, Action: domain.AuditActionUpdate,
+		}, current, t)
+	})
+}
@@ -5,12 +5,17 @@ import (
 	"fmt"
 
 	"github.com/jackc/pgx/v5"
-	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// txBeginner is the pool, or a connection acquired from it when the transaction must
+// run on the connection that holds a session lock.
+type txBeginner interface {
+	BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error)
+}
+
 // WithTransaction executes a function within a transaction with panic recovery.
 // It uses Serializable isolation level for maximum safety in ERP operations.
-func WithTransaction(ctx context.Context, db *pgxpool.Pool, fn func(pgx.Tx) error) error {
+func WithTransaction(ctx context.Context, db txBeginner, fn func(pgx.Tx) error) error {
 	tx, err := db.BeginTx(ctx, pgx.TxOptions{
 		IsoLevel: pgx.Serializable, // Enterprise standard for financial accuracy
 	})
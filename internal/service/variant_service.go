@@ -0,0 +1,397 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"sancaksoft/internal/domain"
+	"sancaksoft/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	maxVariantDimensions = 3
+	maxVariants          = 500
+)
+
+// A parent product defines variant dimensions (e.g. Size and Colour); every combination of
+// their values becomes a variant: an ordinary product with parent_id set, its own SKU,
+// barcode, price and stock. The parent itself never holds stock.
+type VariantService struct {
+	db   *pgxpool.Pool
+	repo *repository.VariantRepository
+}
+
+func NewVariantService(db *pgxpool.Pool, repo *repository.VariantRepository) *VariantService {
+	return &VariantService{db: db, repo: repo}
+}
+
+// SetDimensions defines the variant dimensions of a product. Until variants are generated
+// the dimensions can be replaced freely; afterwards only new values can be added.
+func (s *VariantService) SetDimensions(ctx context.Context, tenantID, productID uuid.UUID, dimensions []domain.VariantDimension) ([]domain.VariantDimension, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	if err := normalizeDimensions(dimensions); err != nil {
+		return nil, err
+	}
+
+	err := WithTransaction(ctx, s.db, func(tx pgx.Tx) error {
+		product, err := s.repo.LockProduct(ctx, tx, tenantID, productID)
+		if err != nil {
+			return err
+		}
+		if product == nil {
+			return fmt.Errorf("product %s: %w", productID, ErrNotFound)
+		}
+		if product.ParentID != nil {
+			return fmt.Errorf("product %s is a variant and cannot have variants: %w", productID, ErrInvalidInput)
+		}
+		kit, err := s.repo.ProductIsKit(ctx, tx, tenantID, productID)
+		if err != nil {
+			return err
+		}
+		if kit {
+			return fmt.Errorf("product %s is a kit and cannot have variants: %w", productID, ErrInvalidInput)
+		}
+		keys, err := s.repo.ListVariantKeys(ctx, tx, tenantID, productID)
+		if err != nil {
+			return err
+		}
+		current, err := s.repo.LockDimensions(ctx, tx, tenantID, productID)
+		if err != nil {
+			return err
+		}
+
+		if len(keys) == 0 {
+			moved, err := s.repo.HasMovements(ctx, tx, tenantID, productID)
+			if err != nil {
+				return err
+			}
+			if moved {
+				return fmt.Errorf("product %s has stock movements; variants need a new parent product: %w", productID, ErrInvalidState)
+			}
+			for i := range dimensions {
+				dimensions[i].ID = uuid.New()
+				dimensions[i].TenantID = tenantID
+				dimensions[i].ProductID = productID
+				dimensions[i].Position = i + 1
+			}
+			if err := s.repo.ReplaceDimensions(ctx, tx, tenantID, productID, dimensions); err != nil {
+				return err
+			}
+			return s.auditDimensions(ctx, tx, tenantID, productID, current, dimensions)
+		}
+
+		// Variants exist: names and existing values are fixed, new values may be added
+		if len(current) != len(dimensions) {
+			return fmt.Errorf("product %s has variants; its dimensions cannot be added or removed: %w", productID, ErrInvalidState)
+		}
+		for i, d := range current {
+			if dimensions[i].Name != d.Name {
+				return fmt.Errorf("product %s has variants; dimension %s cannot be renamed: %w", productID, d.Name, ErrInvalidState)
+			}
+			for _, v := range d.Values {
+				if !slices.Contains(dimensions[i].Values, v) {
+					return fmt.Errorf("product %s has variants; value %s of %s cannot be removed: %w", productID, v, d.Name, ErrInvalidState)
+				}
+			}
+			dimensions[i].ID = d.ID
+			dimensions[i].TenantID = tenantID
+			dimensions[i].ProductID = productID
+			dimensions[i].Position = d.Position
+			if err := s.repo.SetDimensionValues(ctx, tx, tenantID, d.ID, dimensions[i].Values); err != nil {
+				return err
+			}
+		}
+		return s.auditDimensions(ctx, tx, tenantID, productID, current, dimensions)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return dimensions, nil
+}
+
+func (s *VariantService) auditDimensions(ctx context.Context, tx pgx.Tx, tenantID, productID uuid.UUID, before, after []domain.VariantDimension) error {
+	return recordAudit(ctx, s.repo, tx, domain.AuditLog{
+		TenantID: tenantID, EntityType: domain.AuditEntityProduct, EntityID: productID, Action: domain.AuditActionUpdate,
+	}, map[string]any{"variant_dimensions": before}, map[string]any{"variant_dimensions": after})
+}
+
+// ListDimensions returns the variant dimensions of a product.
+func (s *VariantService) ListDimensions(ctx context.Context, tenantID, productID uuid.UUID) ([]domain.VariantDimension, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	return s.repo.ListDimensions(ctx, tenantID, productID)
+}
+
+// GenerateVariants creates the variants missing for the product's dimension combinations and
+// returns them. Variants copy the parent's unit, price, VAT, costing, tracking and
+// classification; SKUs extend the parent SKU with the values and barcodes are internal EAN-13s.
+func (s *VariantService) GenerateVariants(ctx context.Context, tenantID, productID uuid.UUID) ([]domain.Product, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	created := []domain.Product{}
+	err := WithTransaction(ctx, s.db, func(tx pgx.Tx) error {
+		parent, err := s.repo.LockProduct(ctx, tx, tenantID, productID)
+		if err != nil {
+			return err
+		}
+		if parent == nil {
+			return fmt.Errorf("product %s: %w", productID, ErrNotFound)
+		}
+		dimensions, err := s.repo.LockDimensions(ctx, tx, tenantID, productID)
+		if err != nil {
+			return err
+		}
+		if len(dimensions) == 0 {
+			return fmt.Errorf("product %s has no variant dimensions: %w", productID, ErrInvalidState)
+		}
+		keys, err := s.repo.ListVariantKeys(ctx, tx, tenantID, productID)
+		if err != nil {
+			return err
+		}
+
+		for _, combination := range variantCombinations(dimensions) {
+			key := strings.Join(combination, " / ")
+			if slices.Contains(keys, key) {
+				continue
+			}
+			number, err := s.repo.NextBarcodeNumber(ctx, tx, tenantID)
+			if err != nil {
+				return err
+			}
+			skuParts := make([]string, len(combination))
+			values := make(map[uuid.UUID]string, len(combination))
+			for i, v := range combination {
+				skuParts[i] = variantSKUPart(v)
+				values[dimensions[i].ID] = v
+			}
+			variant := domain.Product{
+				ID:           uuid.New(),
+				TenantID:     tenantID,
+				Name:         parent.Name + " - " + key,
+				SKU:          parent.SKU + "-" + strings.Join(skuParts, "-"),
+				Barcode:      internalEAN13(number),
+				Unit:         parent.Unit,
+				Price:        parent.Price,
+				VATRate:      parent.VATRate,
+				StandardCost: parent.StandardCost,
+				TrackLots:    parent.TrackLots,
+				TrackSerials: parent.TrackSerials,
+				CategoryID:   parent.CategoryID,
+				BrandID:      parent.BrandID,
+				ParentID:     &parent.ID,
+				VariantKey:   key,
+			}
+			ok, err := s.repo.CreateVariant(ctx, tx, &variant, values)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return fmt.Errorf("SKU %s already exists: %w", variant.SKU, ErrInvalidState)
+			}
+			if err := recordAudit(ctx, s.repo, tx, domain.AuditLog{
+				TenantID: tenantID, EntityType: domain.AuditEntityProduct, EntityID: variant.ID, Action: domain.AuditActionCreate,
+			}, nil, &variant); err != nil {
+				return err
+			}
+			created = append(created, variant)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// SetVariantPrice overrides the sale price of a variant; nil resets it to the parent's price.
+func (s *VariantService) SetVariantPrice(ctx context.Context, tenantID, variantID uuid.UUID, price *decimal.Decimal) (decimal.Decimal, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	err := WithTransaction(ctx, s.db, func(tx pgx.Tx) error {
+		variant, err := s.repo.LockProduct(ctx, tx, tenantID, variantID)
+		if err != nil {
+			return err
+		}
+		if variant == nil {
+			return fmt.Errorf("product %s: %w", variantID, ErrNotFound)
+		}
+		if variant.ParentID == nil {
+			return fmt.Errorf("product %s is not a variant: %w", variantID, ErrInvalidInput)
+		}
+		if price == nil {
+			parent, err := s.repo.GetProduct(ctx, tenantID, *variant.ParentID)
+			if err != nil {
+				return err
+			}
+			if parent == nil {
+				return fmt.Errorf("parent product %s: %w", *variant.ParentID, ErrNotFound)
+			}
+			price = &parent.Price
+		}
+		if price.IsNegative() {
+			return fmt.Errorf("price cannot be negative: %w", ErrInvalidInput)
+		}
+		if _, err := s.repo.SetPrice(ctx, tx, tenantID, variantID, *price); err != nil {
+			return err
+		}
+		updated := *variant
+		updated.Price = *price
+		return recordAudit(ctx, s.repo, tx, domain.AuditLog{
+			TenantID: tenantID, EntityType: domain.AuditEntityProduct, EntityID: variantID, Action: domain.AuditActionUpdate,
+		}, variant, &updated)
+	})
+	if err != nil {
+		return decimal.Zero, err
+	}
+	return *price, nil
+}
+
+// GetVariantSummary returns the variants of a parent in dimension order with their stock and
+// sales in [from, to), and the totals at the parent level.
+func (s *VariantService) GetVariantSummary(ctx context.Context, tenantID, parentID uuid.UUID, warehouseID *uuid.UUID, from, to *time.Time) (*domain.VariantSummary, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	if from != nil && to != nil && !from.Before(*to) {
+		return nil, fmt.Errorf("from must be before to: %w", ErrInvalidInput)
+	}
+	parent, err := s.repo.GetProduct(ctx, tenantID, parentID)
+	if err != nil {
+		return nil, err
+	}
+	if parent == nil {
+		return nil, fmt.Errorf("product %s: %w", parentID, ErrNotFound)
+	}
+	dimensions, err := s.repo.ListDimensions(ctx, tenantID, parentID)
+	if err != nil {
+		return nil, err
+	}
+	lines, err := s.repo.GetVariantSummary(ctx, tenantID, parentID, warehouseID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	// Order variants the way they were generated: by value position in each dimension
+	rank := func(line domain.VariantSummaryLine) []int {
+		r := make([]int, len(dimensions))
+		for i, d := range dimensions {
+			r[i] = slices.Index(d.Values, line.Options[d.Name])
+		}
+		return r
+	}
+	sort.SliceStable(lines, func(i, j int) bool {
+		return slices.Compare(rank(lines[i]), rank(lines[j])) < 0
+	})
+
+	summary := &domain.VariantSummary{
+		ParentID:     parentID,
+		Dimensions:   dimensions,
+		Variants:     lines,
+		TotalRevenue: decimal.Zero,
+	}
+	for i := range summary.Variants {
+		line := &summary.Variants[i]
+		line.PriceOverridden = !line.Price.Equal(parent.Price)
+		summary.TotalStock += line.Stock
+		summary.TotalSold += line.SoldQuantity
+		summary.TotalRevenue = summary.TotalRevenue.Add(line.Revenue)
+	}
+	return summary, nil
+}
+
+// normalizeDimensions trims names and values, drops duplicate values and checks the limits.
+func normalizeDimensions(dimensions []domain.VariantDimension) error {
+	if len(dimensions) == 0 || len(dimensions) > maxVariantDimensions {
+		return fmt.Errorf("a product needs 1 to %d variant dimensions: %w", maxVariantDimensions, ErrInvalidInput)
+	}
+	combinations := 1
+	names := make(map[string]bool, len(dimensions))
+	for i := range dimensions {
+		d := &dimensions[i]
+		d.Name = strings.TrimSpace(d.Name)
+		if d.Name == "" {
+			return fmt.Errorf("variant dimension name is required: %w", ErrInvalidInput)
+		}
+		if names[strings.ToLower(d.Name)] {
+			return fmt.Errorf("variant dimension %s is given twice: %w", d.Name, ErrInvalidInput)
+		}
+		names[strings.ToLower(d.Name)] = true
+
+		values := make([]string, 0, len(d.Values))
+		for _, v := range d.Values {
+			v = strings.TrimSpace(v)
+			if variantSKUPart(v) == "" {
+				return fmt.Errorf("values of %s need a letter or digit: %w", d.Name, ErrInvalidInput)
+			}
+			if !slices.Contains(values, v) {
+				values = append(values, v)
+			}
+		}
+		if len(values) == 0 {
+			return fmt.Errorf("variant dimension %s needs at least one value: %w", d.Name, ErrInvalidInput)
+		}
+		d.Values = values
+		combinations *= len(values)
+	}
+	if combinations > maxVariants {
+		return fmt.Errorf("dimensions give %d variants, at most %d are allowed: %w", combinations, maxVariants, ErrInvalidInput)
+	}
+	return nil
+}
+
+// variantCombinations lists every combination of dimension values, the last dimension
+// changing fastest.
+func variantCombinations(dimensions []domain.VariantDimension) [][]string {
+	combinations := [][]string{{}}
+	for _, d := range dimensions {
+		next := make([][]string, 0, len(combinations)*len(d.Values))
+		for _, c := range combinations {
+			for _, v := range d.Values {
+				next = append(next, append(slices.Clone(c), v))
+			}
+		}
+		combinations = next
+	}
+	return combinations
+}
+
+// variantSKUPart is a dimension value as used in variant SKUs: upper case letters and digits.
+func variantSKUPart(value string) string {
+	var b strings.Builder
+	for _, r := range strings.ToUpper(value) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// internalEAN13 formats a sequence number as an EAN-13 in the 2xx in-store range, which is
+// never assigned to manufacturers' products.
+func internalEAN13(number int) string {
+	digits := fmt.Sprintf("2%011d", number)
+	sum := 0
+	for i, r := range digits {
+		d := int(r - '0')
+		if i%2 == 1 {
+			d *= 3
+		}
+		sum += d
+	}
+	return fmt.Sprintf("%s%d", digits, (10-sum%10)%10)
+}
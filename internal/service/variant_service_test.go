@@ -0,0 +1,144 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"sancaksoft/internal/domain"
+	"sancaksoft/internal/repository"
+	"sancaksoft/internal/service"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProductVariants_Integration(t *testing.T) {
+	if testDBURL == "" {
+		t.Skip("Skipping integration test: TEST_DB_URL not set")
+	}
+
+	ctx := context.Background()
+	db, err := pgxpool.New(ctx, testDBURL)
+	require.NoError(t, err)
+	defer db.Close()
+
+	// 1. Setup Data
+	tenantID := uuid.New()
+	warehouseID := uuid.New()
+	parentID := uuid.New()
+	customerID := uuid.New()
+	userID := uuid.New()
+	parentSKU := "TS-" + uuid.New().String()[:8]
+
+	defer func() {
+		_, _ = db.Exec(ctx, "DELETE FROM tenants WHERE id = $1", tenantID)
+	}()
+
+	_, err = db.Exec(ctx, "INSERT INTO tenants (id, name) VALUES ($1, 'Variant Test Tenant')", tenantID)
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, "INSERT INTO warehouses (id, tenant_id, name) VALUES ($1, $2, 'Main')", warehouseID, tenantID)
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, "INSERT INTO products (id, tenant_id, name, sku, barcode, price) VALUES ($1, $2, 'T-Shirt', $3, '', 10.00)", parentID, tenantID, parentSKU)
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, "INSERT INTO customers (id, tenant_id, name) VALUES ($1, $2, 'Boutique')", customerID, tenantID)
+	require.NoError(t, err)
+
+	variantService := service.NewVariantService(db, repository.NewVariantRepository(db))
+	stockService := service.NewStockService(db, repository.NewStockRepository(db))
+	invoiceService := service.NewInvoiceService(db, repository.NewInvoiceRepository())
+	productService := service.NewProductService(db, repository.NewProductRepository(db))
+
+	// 2. Size x Colour gives four variants
+	_, err = variantService.SetDimensions(ctx, tenantID, parentID, []domain.VariantDimension{
+		{Name: "Size", Values: []string{"S", " M ", "S"}},
+		{Name: "Colour", Values: []string{"Red", "Blue"}},
+	})
+	require.NoError(t, err)
+	variants, err := variantService.GenerateVariants(ctx, tenantID, parentID)
+	require.NoError(t, err)
+	require.Len(t, variants, 4)
+	assert.Equal(t, "T-Shirt - S / Red", variants[0].Name)
+	assert.Equal(t, parentSKU+"-S-RED", variants[0].SKU)
+	assert.Equal(t, parentID, *variants[0].ParentID)
+	assert.True(t, variants[0].Price.Equal(decimal.NewFromInt(10)))
+	// Internal EAN-13s from the tenant's sequence: 200000000001 + check digit 5
+	assert.Equal(t, "2000000000015", variants[0].Barcode)
+	assert.Equal(t, "2000000000022", variants[1].Barcode)
+
+	again, err := variantService.GenerateVariants(ctx, tenantID, parentID)
+	require.NoError(t, err)
+	assert.Empty(t, again)
+
+	// 3. Once variants exist, dimensions only take new values
+	_, err = variantService.SetDimensions(ctx, tenantID, parentID, []domain.VariantDimension{
+		{Name: "Size", Values: []string{"S"}},
+		{Name: "Colour", Values: []string{"Red", "Blue"}},
+	})
+	assert.ErrorIs(t, err, service.ErrInvalidState)
+	_, err = variantService.SetDimensions(ctx, tenantID, parentID, []domain.VariantDimension{
+		{Name: "Size", Values: []string{"S", "M", "L"}},
+		{Name: "Colour", Values: []string{"Red", "Blue"}},
+	})
+	require.NoError(t, err)
+	added, err := variantService.GenerateVariants(ctx, tenantID, parentID)
+	require.NoError(t, err)
+	require.Len(t, added, 2)
+
+	_, err = variantService.SetDimensions(ctx, tenantID, variants[0].ID, []domain.VariantDimension{{Name: "Fit", Values: []string{"Slim"}}})
+	assert.ErrorIs(t, err, service.ErrInvalidInput)
+
+	// 4. The parent holds no stock; variants do
+	err = stockService.CreateStockMovement(ctx, tenantID, &domain.StockMovement{
+		ID: uuid.New(), ProductID: parentID, WarehouseID: warehouseID, Quantity: 5, Type: domain.StockMovementTypeIn,
+	})
+	assert.ErrorIs(t, err, service.ErrInvalidInput)
+	for _, v := range variants[:2] {
+		require.NoError(t, stockService.CreateStockMovement(ctx, tenantID, &domain.StockMovement{
+			ID: uuid.New(), ProductID: v.ID, WarehouseID: warehouseID, Quantity: 5, Type: domain.StockMovementTypeIn,
+		}))
+	}
+	_, err = invoiceService.CreateInvoice(ctx, domain.CreateInvoiceRequest{
+		TenantID:    tenantID,
+		UserID:      userID,
+		CustomerID:  customerID,
+		WarehouseID: warehouseID,
+		Items:       []domain.InvoiceItemRequest{{ProductID: variants[1].ID, Quantity: 2, UnitPrice: decimal.NewFromInt(12)}},
+	})
+	require.NoError(t, err)
+
+	// 5. Price overrides and reset
+	override := decimal.NewFromInt(12)
+	price, err := variantService.SetVariantPrice(ctx, tenantID, variants[1].ID, &override)
+	require.NoError(t, err)
+	assert.True(t, price.Equal(decimal.NewFromInt(12)))
+	_, err = variantService.SetVariantPrice(ctx, tenantID, parentID, &override)
+	assert.ErrorIs(t, err, service.ErrInvalidInput)
+
+	// 6. Summary in dimension order with parent totals
+	summary, err := variantService.GetVariantSummary(ctx, tenantID, parentID, nil, nil, nil)
+	require.NoError(t, err)
+	require.Len(t, summary.Variants, 6)
+	keys := make([]string, len(summary.Variants))
+	for i, v := range summary.Variants {
+		keys[i] = v.VariantKey
+	}
+	assert.Equal(t, []string{"S / Red", "S / Blue", "M / Red", "M / Blue", "L / Red", "L / Blue"}, keys)
+	assert.Equal(t, map[string]string{"Size": "S", "Colour": "Blue"}, summary.Variants[1].Options)
+	assert.True(t, summary.Variants[1].PriceOverridden)
+	assert.False(t, summary.Variants[0].PriceOverridden)
+	assert.Equal(t, 3, summary.Variants[1].Stock)
+	assert.Equal(t, 8, summary.TotalStock)
+	assert.Equal(t, 2, summary.TotalSold)
+	assert.True(t, summary.TotalRevenue.Equal(decimal.NewFromInt(24)))
+
+	price, err = variantService.SetVariantPrice(ctx, tenantID, variants[1].ID, nil)
+	require.NoError(t, err)
+	assert.True(t, price.Equal(decimal.NewFromInt(10)))
+
+	children, err := productService.ListProducts(ctx, domain.ProductFilter{TenantID: tenantID, ParentID: &parentID})
+	require.NoError(t, err)
+	assert.Len(t, children, 6)
+}
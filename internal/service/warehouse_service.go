@@ -9,14 +9,17 @@ import (
 	"sancaksoft/internal/repository"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type WarehouseService struct {
+	db   *pgxpool.Pool
 	repo *repository.WarehouseRepository
 }
 
-func NewWarehouseService(repo *repository.WarehouseRepository) *WarehouseService {
-	return &WarehouseService{repo: repo}
+func NewWarehouseService(db *pgxpool.Pool, repo *repository.WarehouseRepository) *WarehouseService {
+	return &WarehouseService{db: db, repo: repo}
 }
 
 func (s *WarehouseService) CreateWarehouse(ctx context.Context, w *domain.Warehouse) error {
@@ -28,7 +31,14 @@ func (s *WarehouseService) CreateWarehouse(ctx context.Context, w *domain.Wareho
 	}
 
 	w.ID = uuid.New()
-	return s.repo.CreateWarehouse(ctx, w)
+	return WithTransaction(ctx, s.db, func(tx pgx.Tx) error {
+		if err := s.repo.CreateWarehouse(ctx, tx, w); err != nil {
+			return err
+		}
+		return recordAudit(ctx, s.repo, tx, domain.AuditLog{
+			TenantID: w.TenantID, EntityType: domain.AuditEntityWarehouse, EntityID: w.ID, Action: domain.AuditActionCreate,
+		}, nil, w)
+	})
 }
 
 func (s *WarehouseService) ListWarehouses(ctx context.Context, tenantID uuid.UUID) ([]domain.Warehouse, error) {
@@ -0,0 +1,94 @@
+// Package spreadsheet reads the first sheet of CSV and XLSX files as rows of text, using
+// only the standard library. Rows keep the numbers a spreadsheet program shows, so errors
+// can point the user at the right line. Writers stream rows back out in either format.
+package spreadsheet
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// Row is a non-empty row; Number is its 1-based line (CSV) or row number (XLSX).
+type Row struct {
+	Number int
+	Cells  []string
+}
+
+// Cell returns the trimmed cell at index i, or "" past the end of the row.
+func (r Row) Cell(i int) string {
+	if i < 0 || i >= len(r.Cells) {
+		return ""
+	}
+	return strings.TrimSpace(r.Cells[i])
+}
+
+// ErrUnsupportedFormat is returned for files that are neither CSV nor XLSX.
+var ErrUnsupportedFormat = errors.New("unsupported file format, use CSV or XLSX")
+
+// Read parses a CSV or XLSX file; the format is taken from the file name, falling back to
+// the content for files without an extension. Blank rows are skipped.
+func Read(filename string, data []byte) ([]Row, error) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv", ".txt":
+		return ReadCSV(data)
+	case ".xlsx":
+		return ReadXLSX(data)
+	case "":
+		if bytes.HasPrefix(data, []byte("PK\x03\x04")) {
+			return ReadXLSX(data)
+		}
+		return ReadCSV(data)
+	}
+	return nil, ErrUnsupportedFormat
+}
+
+// ReadCSV parses comma or semicolon separated text; Excel uses semicolons where the
+// comma is the decimal separator, as in Turkish locales. A UTF-8 byte order mark is
+// ignored.
+func ReadCSV(data []byte) ([]Row, error) {
+	data = bytes.TrimPrefix(data, []byte("\xEF\xBB\xBF"))
+
+	r := csv.NewReader(bytes.NewReader(data))
+	r.Comma = delimiter(data)
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+
+	var rows []Row
+	for {
+		cells, err := r.Read()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("failed to read CSV: %w", err)
+		}
+		line, _ := r.FieldPos(0)
+		if !blank(cells) {
+			rows = append(rows, Row{Number: line, Cells: cells})
+		}
+	}
+	return rows, nil
+}
+
+// delimiter guesses the separator from the first line.
+func delimiter(data []byte) rune {
+	first, _, _ := bytes.Cut(data, []byte("\n"))
+	if bytes.Count(first, []byte(";")) > bytes.Count(first, []byte(",")) {
+		return ';'
+	}
+	return ','
+}
+
+func blank(cells []string) bool {
+	for _, c := range cells {
+		if strings.TrimSpace(c) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,141 @@
+package spreadsheet
+
+import (
+	"archive/zip"
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadCSV(t *testing.T) {
+	data := []byte("\xEF\xBB\xBFsku;name;price\nCAY-1;Çay 1 kg;\"12,50\"\n\n;;\nKAHVE;\"Kahve\nTürk\";80\n")
+	rows, err := Read("urunler.csv", data)
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+	assert.Equal(t, Row{Number: 1, Cells: []string{"sku", "name", "price"}}, rows[0])
+	assert.Equal(t, Row{Number: 2, Cells: []string{"CAY-1", "Çay 1 kg", "12,50"}}, rows[1])
+	assert.Equal(t, 5, rows[2].Number)
+	assert.Equal(t, "Kahve\nTürk", rows[2].Cell(1))
+	assert.Equal(t, "", rows[2].Cell(7))
+
+	rows, err = ReadCSV([]byte("a,b\n1,2"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1", "2"}, rows[1].Cells)
+
+	_, err = Read("urunler.pdf", data)
+	assert.ErrorIs(t, err, ErrUnsupportedFormat)
+}
+
+func xlsxFile(t *testing.T, parts map[string]string) []byte {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range parts {
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+func TestReadXLSX(t *testing.T) {
+	data := xlsxFile(t, map[string]string{
+		"xl/workbook.xml": `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+			<sheets><sheet name="Ürünler" sheetId="1" r:id="rId3"/><sheet name="Notlar" sheetId="2" r:id="rId1"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+			<Relationship Id="rId1" Target="worksheets/sheet1.xml"/><Relationship Id="rId3" Target="worksheets/sheet2.xml"/></Relationships>`,
+		"xl/sharedStrings.xml": `<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+			<si><t>sku</t></si><si><t>price</t></si><si><r><t>Çay </t></r><r><t>1 kg</t></r></si></sst>`,
+		"xl/worksheets/sheet1.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData><row r="1"><c r="A1" t="inlineStr"><is><t>wrong sheet</t></is></c></row></sheetData></worksheet>`,
+		"xl/worksheets/sheet2.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>
+			<row r="1"><c r="A1" t="s"><v>0</v></c><c r="C1" t="s"><v>1</v></c></row>
+			<row r="2"><c r="A2"/></row>
+			<row r="4"><c r="A4" t="s"><v>2</v></c><c r="B4" t="b"><v>1</v></c><c r="C4"><v>12.300000000000001</v></c><c r="AA4" t="inlineStr"><is><t>x</t></is></c></row>
+			<row r="5"><c r="A5"><v>1.5E-2</v></c><c r="B5" t="str"><v>=A5</v></c></row>
+		</sheetData></worksheet>`,
+	})
+
+	rows, err := Read("urunler.xlsx", data)
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+	assert.Equal(t, Row{Number: 1, Cells: []string{"sku", "", "price"}}, rows[0])
+	assert.Equal(t, 4, rows[1].Number)
+	assert.Equal(t, "Çay 1 kg", rows[1].Cell(0))
+	assert.Equal(t, "TRUE", rows[1].Cell(1))
+	assert.Equal(t, "12.3", rows[1].Cell(2))
+	assert.Equal(t, "x", rows[1].Cell(26))
+	assert.Equal(t, []string{"0.015", "=A5"}, rows[2].Cells)
+
+	// Detected by content without an extension
+	rows, err = Read("upload", data)
+	require.NoError(t, err)
+	assert.Len(t, rows, 3)
+
+	_, err = ReadXLSX(xlsxFile(t, map[string]string{"xl/styles.xml": "<styleSheet/>"}))
+	assert.ErrorContains(t, err, "xl/workbook.xml is missing")
+}
+
+func TestColumnIndex(t *testing.T) {
+	for ref, want := range map[string]int{"A1": 0, "Z9": 25, "AA10": 26, "AB3": 27, "BA1": 52} {
+		got, err := columnIndex(ref)
+		require.NoError(t, err)
+		assert.Equal(t, want, got, ref)
+	}
+	_, err := columnIndex("12")
+	assert.Error(t, err)
+}
+
+func TestCSVWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(FormatCSV, &buf, "Faturalar")
+	require.NoError(t, err)
+	require.NoError(t, w.WriteRow("invoice_number", "quantity", "total"))
+	require.NoError(t, w.WriteRow("INV-1", 3, Number("12.50")))
+	require.NoError(t, w.WriteRow("Çay, 1 kg", 1, Number("-4.00")))
+	require.NoError(t, w.Close())
+	assert.Equal(t, "invoice_number,quantity,total\nINV-1,3,12.50\n\"Çay, 1 kg\",1,-4.00\n", buf.String())
+
+	_, err = NewWriter("pdf", &buf, "")
+	assert.ErrorIs(t, err, ErrUnsupportedFormat)
+}
+
+func TestXLSXWriterRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(FormatXLSX, &buf, "Cari/Ekstre: 2026")
+	require.NoError(t, err)
+	require.NoError(t, w.WriteRow("sku", "name", "quantity", "price"))
+	require.NoError(t, w.WriteRow("CAY-1", "Çay <1 kg> & \"şeker\"", 3, Number("12.50")))
+	require.NoError(t, w.WriteRow("", "", 0, Number("-0.10")))
+	require.NoError(t, w.Close())
+
+	rows, err := ReadXLSX(buf.Bytes())
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+	assert.Equal(t, []string{"sku", "name", "quantity", "price"}, rows[0].Cells)
+	assert.Equal(t, []string{"CAY-1", "Çay <1 kg> & \"şeker\"", "3", "12.5"}, rows[1].Cells)
+	assert.Equal(t, Row{Number: 3, Cells: []string{"", "", "0", "-0.1"}}, rows[2])
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	var wb xlsxWorkbook
+	files := map[string]*zip.File{}
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+	require.NoError(t, readPart(files, "xl/workbook.xml", &wb))
+	assert.Equal(t, "Cari-Ekstre- 2026", wb.Sheets[0].Name)
+	assert.Contains(t, files, "[Content_Types].xml")
+	assert.Contains(t, files, "xl/styles.xml")
+}
+
+func TestColumnName(t *testing.T) {
+	for col := 0; col < 800; col++ {
+		got, err := columnIndex(columnName(col) + "1")
+		require.NoError(t, err)
+		assert.Equal(t, col, got)
+	}
+	assert.Equal(t, "AA", columnName(26))
+}
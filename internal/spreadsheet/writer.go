@@ -0,0 +1,206 @@
+package spreadsheet
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Number is a cell value written as a number: a plain decimal such as "12.50". CSV keeps
+// it as text; XLSX stores it as a numeric cell so totals can be summed.
+type Number string
+
+// Writer streams rows to a CSV or XLSX file. Cells are strings, Numbers or ints; nothing
+// is buffered beyond the current row, so exports of any size use constant memory.
+type Writer interface {
+	WriteRow(cells ...any) error
+	// Close finishes the file; the underlying writer is not closed.
+	Close() error
+}
+
+// Export formats accepted by NewWriter.
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+)
+
+// NewWriter returns a writer for format; sheet names the XLSX worksheet.
+func NewWriter(format string, w io.Writer, sheet string) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return NewCSVWriter(w), nil
+	case FormatXLSX:
+		return NewXLSXWriter(w, sheet)
+	}
+	return nil, ErrUnsupportedFormat
+}
+
+// ContentType returns the MIME type of format.
+func ContentType(format string) string {
+	if format == FormatXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+type csvWriter struct {
+	w *csv.Writer
+}
+
+// NewCSVWriter writes comma separated rows.
+func NewCSVWriter(w io.Writer) Writer {
+	return &csvWriter{w: csv.NewWriter(w)}
+}
+
+func (cw *csvWriter) WriteRow(cells ...any) error {
+	record := make([]string, len(cells))
+	for i, c := range cells {
+		switch v := c.(type) {
+		case string:
+			record[i] = v
+		case Number:
+			record[i] = string(v)
+		default:
+			record[i] = fmt.Sprint(v)
+		}
+	}
+	// csv.Writer buffers; errors surface on a later write or on Close
+	if err := cw.w.Write(record); err != nil {
+		return err
+	}
+	return cw.w.Error()
+}
+
+func (cw *csvWriter) Close() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+type xlsxWriter struct {
+	zw    *zip.Writer
+	sheet *bufio.Writer
+	row   int
+}
+
+const (
+	xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/><Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/></Types>`
+	xlsxRootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`
+	xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/><Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/></Relationships>`
+	xlsxStyles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><fonts count="1"><font><sz val="11"/><name val="Calibri"/></font></fonts><fills count="1"><fill><patternFill patternType="none"/></fill></fills><borders count="1"><border/></borders><cellStyleXfs count="1"><xf/></cellStyleXfs><cellXfs count="1"><xf/></cellXfs></styleSheet>`
+	xlsxWorkbookFormat = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="%s" sheetId="1" r:id="rId1"/></sheets></workbook>`
+	xlsxSheetStart = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`
+	xlsxSheetEnd = `</sheetData></worksheet>`
+)
+
+// NewXLSXWriter writes a single-sheet workbook. The fixed parts are written up front and
+// the worksheet last, so rows go straight into the compressed stream. Text is stored as
+// inline strings to avoid collecting a shared string table.
+func NewXLSXWriter(w io.Writer, sheet string) (Writer, error) {
+	var name strings.Builder
+	if err := xml.EscapeText(&name, []byte(sheetName(sheet))); err != nil {
+		return nil, err
+	}
+	zw := zip.NewWriter(w)
+	parts := []struct{ name, content string }{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRootRels},
+		{"xl/workbook.xml", fmt.Sprintf(xlsxWorkbookFormat, name.String())},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+		{"xl/styles.xml", xlsxStyles},
+	}
+	for _, p := range parts {
+		pw, err := zw.Create(p.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(pw, p.content); err != nil {
+			return nil, err
+		}
+	}
+	sw, err := zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	xw := &xlsxWriter{zw: zw, sheet: bufio.NewWriter(sw)}
+	if _, err := xw.sheet.WriteString(xlsxSheetStart); err != nil {
+		return nil, err
+	}
+	return xw, nil
+}
+
+// sheetName trims a name to what Excel accepts: at most 31 characters, none of []:*?/\.
+func sheetName(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return '-'
+		}
+		return r
+	}, s)
+	if r := []rune(s); len(r) > 31 {
+		s = string(r[:31])
+	}
+	if s == "" {
+		return "Sheet1"
+	}
+	return s
+}
+
+func (xw *xlsxWriter) WriteRow(cells ...any) error {
+	xw.row++
+	b := xw.sheet
+	fmt.Fprintf(b, `<row r="%d">`, xw.row)
+	for i, c := range cells {
+		ref := columnName(i) + strconv.Itoa(xw.row)
+		switch v := c.(type) {
+		case Number:
+			fmt.Fprintf(b, `<c r="%s"><v>%s</v></c>`, ref, v)
+		case int:
+			fmt.Fprintf(b, `<c r="%s"><v>%d</v></c>`, ref, v)
+		default:
+			s, ok := v.(string)
+			if !ok {
+				s = fmt.Sprint(v)
+			}
+			if s == "" {
+				continue
+			}
+			fmt.Fprintf(b, `<c r="%s" t="inlineStr"><is><t xml:space="preserve">`, ref)
+			if err := xml.EscapeText(b, []byte(s)); err != nil {
+				return err
+			}
+			b.WriteString(`</t></is></c>`)
+		}
+	}
+	_, err := b.WriteString(`</row>`)
+	return err
+}
+
+func (xw *xlsxWriter) Close() error {
+	if _, err := xw.sheet.WriteString(xlsxSheetEnd); err != nil {
+		return err
+	}
+	if err := xw.sheet.Flush(); err != nil {
+		return err
+	}
+	return xw.zw.Close()
+}
+
+// columnName returns the letters of a 0-based column, the inverse of columnIndex.
+func columnName(col int) string {
+	name := ""
+	for col++; col > 0; col = (col - 1) / 26 {
+		name = string(rune('A'+(col-1)%26)) + name
+	}
+	return name
+}
@@ -0,0 +1,207 @@
+package spreadsheet
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// Workbooks unpack to far more than their compressed size; cap what is read from a part.
+const maxPartSize = 64 << 20
+
+type xlsxWorkbook struct {
+	Sheets []struct {
+		Name string `xml:"name,attr"`
+		RID  string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type xlsxRelationships struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+// xlsxText is rich or plain text: <t> directly, or runs of <r><t>.
+type xlsxText struct {
+	T    string `xml:"t"`
+	Runs []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t xlsxText) String() string {
+	var b strings.Builder
+	b.WriteString(t.T)
+	for _, r := range t.Runs {
+		b.WriteString(r.T)
+	}
+	return b.String()
+}
+
+type xlsxSharedStrings struct {
+	Items []xlsxText `xml:"si"`
+}
+
+type xlsxSheet struct {
+	Rows []struct {
+		R     int `xml:"r,attr"`
+		Cells []struct {
+			R      string    `xml:"r,attr"`
+			T      string    `xml:"t,attr"`
+			V      string    `xml:"v"`
+			Inline *xlsxText `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// ReadXLSX reads the first worksheet of an Office Open XML workbook. Cells are returned
+// as displayed without number formats: shared and inline strings as text, numbers in
+// plain decimal notation and booleans as TRUE or FALSE.
+func ReadXLSX(data []byte) ([]Row, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open XLSX: %w", err)
+	}
+	files := map[string]*zip.File{}
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	var wb xlsxWorkbook
+	if err := readPart(files, "xl/workbook.xml", &wb); err != nil {
+		return nil, err
+	}
+	if len(wb.Sheets) == 0 {
+		return nil, fmt.Errorf("workbook has no sheets")
+	}
+	sheetPath := "xl/worksheets/sheet1.xml"
+	var rels xlsxRelationships
+	if err := readPart(files, "xl/_rels/workbook.xml.rels", &rels); err == nil {
+		for _, rel := range rels.Relationships {
+			if rel.ID == wb.Sheets[0].RID {
+				if strings.HasPrefix(rel.Target, "/") {
+					sheetPath = strings.TrimPrefix(rel.Target, "/")
+				} else {
+					sheetPath = path.Join("xl", rel.Target)
+				}
+			}
+		}
+	}
+
+	var shared xlsxSharedStrings
+	if _, ok := files["xl/sharedStrings.xml"]; ok {
+		if err := readPart(files, "xl/sharedStrings.xml", &shared); err != nil {
+			return nil, err
+		}
+	}
+
+	var sheet xlsxSheet
+	if err := readPart(files, sheetPath, &sheet); err != nil {
+		return nil, err
+	}
+
+	var rows []Row
+	for i, sr := range sheet.Rows {
+		row := Row{Number: sr.R}
+		if row.Number == 0 {
+			row.Number = i + 1
+		}
+		for j, c := range sr.Cells {
+			col := j
+			if c.R != "" {
+				if col, err = columnIndex(c.R); err != nil {
+					return nil, err
+				}
+			}
+			value, err := cellValue(c.T, c.V, c.Inline, shared.Items)
+			if err != nil {
+				return nil, fmt.Errorf("cell %s: %w", c.R, err)
+			}
+			for len(row.Cells) <= col {
+				row.Cells = append(row.Cells, "")
+			}
+			row.Cells[col] = value
+		}
+		if !blank(row.Cells) {
+			rows = append(rows, row)
+		}
+	}
+	return rows, nil
+}
+
+func readPart(files map[string]*zip.File, name string, v any) error {
+	f, ok := files[name]
+	if !ok {
+		return fmt.Errorf("invalid XLSX: %s is missing", name)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return fmt.Errorf("invalid XLSX: %w", err)
+	}
+	defer rc.Close()
+	if err := xml.NewDecoder(io.LimitReader(rc, maxPartSize)).Decode(v); err != nil {
+		return fmt.Errorf("invalid XLSX: %s: %w", name, err)
+	}
+	return nil
+}
+
+func cellValue(typ, v string, inline *xlsxText, shared []xlsxText) (string, error) {
+	switch typ {
+	case "s":
+		i, err := strconv.Atoi(v)
+		if err != nil || i < 0 || i >= len(shared) {
+			return "", fmt.Errorf("invalid shared string %q", v)
+		}
+		return shared[i].String(), nil
+	case "inlineStr":
+		if inline == nil {
+			return "", nil
+		}
+		return inline.String(), nil
+	case "b":
+		if v == "1" {
+			return "TRUE", nil
+		}
+		return "FALSE", nil
+	case "str", "e":
+		return v, nil
+	}
+	if v == "" {
+		return "", nil
+	}
+	return number(v), nil
+}
+
+// number writes a stored double as a spreadsheet shows it: 15 significant digits, which
+// drops binary artifacts such as 12.300000000000001, and no exponent.
+func number(v string) string {
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return v
+	}
+	f, _ = strconv.ParseFloat(strconv.FormatFloat(f, 'g', 15, 64), 64)
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// columnIndex returns the 0-based column of a cell reference such as "AB12".
+func columnIndex(ref string) (int, error) {
+	col := 0
+	for i, r := range ref {
+		if r >= 'A' && r <= 'Z' {
+			col = col*26 + int(r-'A') + 1
+			continue
+		}
+		if i == 0 {
+			break
+		}
+		return col - 1, nil
+	}
+	return 0, fmt.Errorf("invalid cell reference %q", ref)
+}